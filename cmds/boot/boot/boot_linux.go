@@ -10,7 +10,7 @@
 //
 // Synopsis:
 //
-//	boot [-v][-no-load][-no-exec]
+//	boot [-v][-no-load][-no-exec][-state STORE][-trust FILES [-verify-policy POLICY]]
 //
 // Description:
 //
@@ -19,6 +19,13 @@
 //	-v prints messages
 //	-no-load prints the boot image paths it was going to load, but doesn't load + exec them
 //	-no-exec loads the boot image, but doesn't exec it
+//	-state orders boot entries by the boot-once and trial state in STORE
+//	       (grubenv:PATH, efivarfs[:GUID] or vpd:IMAGE), and records the
+//	       entry chosen in the menu there, see bootstate
+//	-trust requires kernels and initramfs to be signed by one of the
+//	       certificates or keys in the comma separated FILES
+//	-verify-policy is what to do with images that are not: refuse, warn or
+//	       measure
 //
 // Notes:
 //
@@ -34,17 +41,24 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"path/filepath"
 	"strings"
 
 	"github.com/u-root/u-root/pkg/boot"
+	"github.com/u-root/u-root/pkg/boot/bls"
 	"github.com/u-root/u-root/pkg/boot/bootcmd"
+	"github.com/u-root/u-root/pkg/boot/bootstate"
 	"github.com/u-root/u-root/pkg/boot/localboot"
 	"github.com/u-root/u-root/pkg/boot/menu"
+	"github.com/u-root/u-root/pkg/boot/verify"
 	"github.com/u-root/u-root/pkg/cmdline"
+	"github.com/u-root/u-root/pkg/curl"
 	"github.com/u-root/u-root/pkg/mount"
 	"github.com/u-root/u-root/pkg/mount/block"
 	"github.com/u-root/u-root/pkg/ulog"
+	"golang.org/x/sys/unix"
 )
 
 var (
@@ -56,6 +70,9 @@ var (
 	reuseCmdlineItem  = flag.String("reuse", "console", "comma separated list of kernel params value to reuse from current kernel (default to console)")
 	appendCmdline     = flag.String("append", "", "Additional kernel params")
 	blockList         = flag.String("block", "", "comma separated list of pci vendor and device ids to ignore (format vendor:device). E.g. 0x8086:0x1234,0x8086:0xabcd")
+	stateStore        = flag.String("state", "", "boot state store to choose the entry from (grubenv:PATH, efivarfs[:GUID] or vpd:IMAGE)")
+
+	verifyFlags verify.Flags
 )
 
 // updateBootCmdline get the kernel command line parameters and filter it:
@@ -66,7 +83,61 @@ func cmdlineModifier(li *boot.LinuxImage) {
 	li.Cmdline = f.Update(cmdline.NewCmdLine(), li.Cmdline)
 }
 
+// orderByState puts the entry chosen by the boot state in s first. On
+// error, the images are returned unchanged.
+func orderByState(s bootstate.Store, images []boot.OSImage) []boot.OSImage {
+	ordered, err := bootstate.Order(s, images)
+	if err != nil {
+		log.Printf("Ignoring boot state: %v", err)
+		return images
+	}
+	return ordered
+}
+
+// recordingEntry is a menu entry that records the boot of its image in the
+// boot state when it is loaded, i.e. once it was chosen in the menu.
+type recordingEntry struct {
+	menu.Entry
+	img   boot.OSImage
+	store bootstate.Store
+	pool  *mount.Pool
+}
+
+func (e recordingEntry) String() string {
+	return menu.ExtendedLabel(e.Entry)
+}
+
+// Load records the boot before loading, so that a boot that never comes
+// back counts as an attempt.
+func (e recordingEntry) Load() error {
+	// Counting the boot of a BLS entry renames it, on a file system
+	// mounted read-only.
+	if li, ok := e.img.(*boot.LinuxImage); ok && bls.HasBootCounter(li.BLSEntry) {
+		if err := remountWritable(e.pool, li.BLSEntry); err != nil {
+			log.Printf("Could not remount %s read-write: %v", li.BLSEntry, err)
+		}
+	}
+	if err := bootstate.Record(e.store, e.img); err != nil {
+		log.Printf("Could not record boot state: %v", err)
+	}
+	// Nothing syncs file systems before kexec.
+	unix.Sync()
+	return e.Entry.Load()
+}
+
+// remountWritable remounts the file system in pool that path is on
+// read-write.
+func remountWritable(pool *mount.Pool, path string) error {
+	for _, m := range pool.MountPoints {
+		if rel, err := filepath.Rel(m.Path, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return unix.Mount(m.Device, m.Path, m.FSType, m.Flags&^unix.MS_RDONLY|unix.MS_REMOUNT, m.Data)
+		}
+	}
+	return fmt.Errorf("%s is not on a mounted file system", path)
+}
+
 func main() {
+	verifyFlags.Register(flag.CommandLine)
 	flag.Parse()
 
 	if *verbose {
@@ -102,7 +173,26 @@ func main() {
 	// Make changes to the kernel command line based on our cmdline.
 	boot.ApplyLinuxModifiers(images, cmdlineModifier)
 
-	menuEntries := menu.OSImages(*verbose, images...)
+	var store bootstate.Store
+	if *stateStore != "" {
+		if store, err = bootstate.Open(*stateStore); err != nil {
+			log.Printf("Ignoring boot state: %v", err)
+		} else {
+			images = orderByState(store, images)
+		}
+	}
+
+	opts, err := verifyFlags.LoadOptions(ulog.Log, curl.DefaultSchemes)
+	if err != nil {
+		log.Fatalf("Could not load trusted keys: %v", err)
+	}
+
+	menuEntries := menu.OSImagesWithOpts(*verbose, opts, images...)
+	if store != nil {
+		for i, e := range menuEntries {
+			menuEntries[i] = recordingEntry{Entry: e, img: images[i], store: store, pool: mountPool}
+		}
+	}
 	menuEntries = append(menuEntries, menu.Reboot{})
 	menuEntries = append(menuEntries, menu.StartShell{})
 
@@ -19,6 +19,21 @@
 //
 //   - a pxelinux.0, in which case we will ignore the pxelinux and try to parse
 //     pxelinux.cfg/<files>
+//
+// HTTPS is supported if configured with -https-config or the uroot.https
+// kernel command line flags, see curl.HTTPSConfigFromCmdline.
+//
+// With -cache, downloaded files are kept in a directory, such as on a tmpfs,
+// so that booting again after a failed kexec does not download them again.
+// Files found again by their URL are not downloaded again while the HTTP
+// server's ETag or Last-Modified date stays the same; it is only asked for
+// again with -cache-revalidate. -prefetch downloads the files of all boot
+// entries into the -cache at once, before one is chosen.
+//
+// With -trust, kernels and initramfs must be signed by one of the given
+// certificates or keys, either with an Authenticode signature or with a
+// detached signature next to them, e.g. vmlinuz.sig. -verify-policy decides
+// what happens to images that are not.
 package main
 
 import (
@@ -34,6 +49,8 @@ import (
 	"github.com/u-root/u-root/pkg/boot/bootcmd"
 	"github.com/u-root/u-root/pkg/boot/menu"
 	"github.com/u-root/u-root/pkg/boot/netboot"
+	"github.com/u-root/u-root/pkg/boot/verify"
+	"github.com/u-root/u-root/pkg/cmdline"
 	"github.com/u-root/u-root/pkg/curl"
 	"github.com/u-root/u-root/pkg/dhclient"
 	"github.com/u-root/u-root/pkg/sh"
@@ -54,6 +71,13 @@ var (
 	cmdAppend   = flag.String("cmd", "", "Kernel command to append for each image")
 	bootfile    = flag.String("file", "", "Boot file name (default tftp) or full URI to use instead of DHCP.")
 	server      = flag.String("server", "0.0.0.0", "Server IP (Requires -file for effect)")
+	httpsConfig = flag.String("https-config", "", "JSON file with the CA bundle, client certificate and pinned keys for HTTPS, see curl.LoadHTTPSConfig")
+	cacheDir    = flag.String("cache", "", "directory to cache downloaded files in, shared across boot attempts")
+	cacheSize   = flag.Int64("cache-size", 1024, "maximum size of the -cache directory in MiB")
+	revalidate  = flag.Bool("cache-revalidate", false, "ask the HTTP server whether files in the -cache changed before using them")
+	prefetch    = flag.Int("prefetch", 0, "download the files of all boot entries into the -cache, this many at a time, before showing the menu")
+
+	verifyFlags verify.Flags
 )
 
 const (
@@ -63,7 +87,7 @@ const (
 
 // NetbootImages requests DHCP on every ifaceNames interface, and parses
 // netboot images from the DHCP leases. Returns bootable OSes.
-func NetbootImages(ifaceNames string) ([]boot.OSImage, error) {
+func NetbootImages(ifaceNames string, s curl.Schemes) ([]boot.OSImage, error) {
 	filteredIfs, err := dhclient.Interfaces(ifaceNames)
 	if err != nil {
 		return nil, err
@@ -111,7 +135,7 @@ func NetbootImages(ifaceNames string) ([]boot.OSImage, error) {
 			}
 
 			// Don't use the other context, as it's for the DHCP timeout.
-			imgs, err := netboot.BootImages(context.Background(), ulog.Log, curl.DefaultSchemes, result.Lease)
+			imgs, err := netboot.BootImages(context.Background(), ulog.Log, s, result.Lease)
 			if err != nil {
 				log.Printf("Failed to boot lease %v: %v", result.Lease, err)
 				continue
@@ -139,6 +163,27 @@ func newManualLease() (dhclient.Lease, error) {
 	return dhclient.NewPacket4(filteredIfs[0], d), nil
 }
 
+// registerHTTPS adds HTTPS to the default schemes if it is configured by flag
+// or on the kernel command line.
+func registerHTTPS() error {
+	var c *curl.HTTPSConfig
+	var err error
+	if *httpsConfig != "" {
+		c, err = curl.LoadHTTPSConfig(*httpsConfig)
+	} else {
+		c, err = curl.HTTPSConfigFromCmdline(cmdline.NewCmdLine())
+	}
+	if err != nil || c == nil {
+		return err
+	}
+	h, err := curl.NewHTTPSClient(c)
+	if err != nil {
+		return err
+	}
+	curl.RegisterScheme("https", h)
+	return nil
+}
+
 func dumpNetDebugInfo() {
 	log.Println("Dump debug info of network status")
 	commands := []string{"ip link", "ip addr", "ip route show table all", "ip -6 route show table all", "ip neigh"}
@@ -151,6 +196,7 @@ func dumpNetDebugInfo() {
 }
 
 func main() {
+	verifyFlags.Register(flag.CommandLine)
 	flag.Parse()
 	if len(flag.Args()) > 1 {
 		log.Fatalf("Only one regexp-style argument is allowed, e.g.: %s", ifName)
@@ -158,11 +204,30 @@ func main() {
 	if len(flag.Args()) > 0 {
 		ifName = flag.Args()[0]
 	}
+	if *prefetch > 0 && *cacheDir == "" {
+		log.Fatalf("-prefetch requires -cache")
+	}
+	if err := registerHTTPS(); err != nil {
+		log.Fatalf("Could not configure HTTPS: %v", err)
+	}
+
+	schemes := curl.DefaultSchemes
+	if *cacheDir != "" {
+		var opts []curl.CacheOpt
+		if *revalidate {
+			opts = append(opts, curl.WithRevalidation())
+		}
+		c, err := curl.NewCache(*cacheDir, *cacheSize<<20, opts...)
+		if err != nil {
+			log.Fatalf("Could not create cache: %v", err)
+		}
+		schemes = c.Schemes(schemes)
+	}
 
 	var images []boot.OSImage
 	var err error
 	if *bootfile == "" {
-		images, err = NetbootImages(ifName)
+		images, err = NetbootImages(ifName, schemes)
 		if err != nil {
 			dumpNetDebugInfo()
 		}
@@ -171,7 +236,7 @@ func main() {
 		var l dhclient.Lease
 		l, err = newManualLease()
 		if err == nil {
-			images, err = netboot.BootImages(context.Background(), ulog.Log, curl.DefaultSchemes, l)
+			images, err = netboot.BootImages(context.Background(), ulog.Log, schemes, l)
 		}
 	}
 
@@ -185,7 +250,18 @@ func main() {
 		})
 	}
 
-	menuEntries := menu.OSImages(*verbose, images...)
+	if *prefetch > 0 && !*noLoad {
+		if err := boot.Prefetch(context.Background(), ulog.Log, *prefetch, images...); err != nil {
+			log.Printf("Prefetching boot files failed: %v", err)
+		}
+	}
+
+	opts, err := verifyFlags.LoadOptions(ulog.Log, schemes)
+	if err != nil {
+		log.Fatalf("Could not load trusted keys: %v", err)
+	}
+
+	menuEntries := menu.OSImagesWithOpts(*verbose, opts, images...)
 	menuEntries = append(menuEntries, menu.Reboot{})
 	menuEntries = append(menuEntries, menu.StartShell{})
 
@@ -22,6 +22,9 @@ func run(getBlock func() (block.BlockDevices, error), out io.Writer) error {
 
 	for _, device := range devices {
 		fmt.Fprint(out, device.DevicePath())
+		if device.FSLabel != "" {
+			fmt.Fprintf(out, " LABEL=%q", device.FSLabel)
+		}
 		if device.FsUUID != "" {
 			fmt.Fprintf(out, " UUID=%q", device.FsUUID)
 		}
@@ -62,6 +62,22 @@ func TestBlkid(t *testing.T) {
 			wantString: "/dev/nvme0n1p1 UUID=\"51820b9c-d640-4c8c-8597-188689253e69\" TYPE=\"Ext4\"\n/dev/sda UUID=\"4c8c-8597\"\n",
 			want:       nil,
 		},
+		{
+			name: "Got FS Label",
+			BlockDevices: []*block.BlockDev{
+				{
+					Name:    "sda1",
+					FSType:  "btrfs",
+					FsUUID:  "51820b9c-d640-4c8c-8597-188689253e69",
+					FSLabel: "fedora",
+				}, {
+					Name:   "sr0",
+					FSType: "squashfs",
+				},
+			},
+			wantString: "/dev/sda1 LABEL=\"fedora\" UUID=\"51820b9c-d640-4c8c-8597-188689253e69\" TYPE=\"btrfs\"\n/dev/sr0 TYPE=\"squashfs\"\n",
+			want:       nil,
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			blockGetBlockDevices := func() (block.BlockDevices, error) {
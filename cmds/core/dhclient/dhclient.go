@@ -8,13 +8,28 @@
 //
 // Synopsis:
 //
-//	dhclient [OPTIONS...]
+//	dhclient [OPTIONS...] [IFACE-REGEXP]
+//
+// Description:
+//
+//	With -d, dhclient keeps running after configuring the interfaces: it
+//	renews leases at T1, rebinds them at T2, obtains new ones when they
+//	expire, and releases them when it gets SIGTERM or SIGINT. The state of
+//	all leases is kept as JSON in the lease file.
+//
+//	The -hook program is run on every change of a lease, with the
+//	environment variables REASON (bound, renewing, rebinding, expired or
+//	released), INTERFACE, PROTOCOL (IPv4 or IPv6), OLD_ADDRESS and
+//	NEW_ADDRESS.
 //
 // Options:
 //
-//	-timeout:  lease timeout in seconds
-//	-renewals: number of DHCP renewals before exiting
-//	-verbose:  verbose output
+//	-timeout:    lease timeout in seconds
+//	-v, -vv:     verbose output
+//	-d:          keep leases alive until terminated
+//	-lease-file: where to keep the state of leases with -d
+//	-hook:       program to run on every change of a lease with -d
+//	-release:    release leases when terminated with -d
 package main
 
 import (
@@ -22,6 +37,10 @@ import (
 	"flag"
 	"log"
 	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/insomniacslk/dhcp/dhcpv4"
@@ -44,8 +63,15 @@ var (
 	v6Server = flag.String("v6-server", "ff02::1:2", "DHCPv6 server address to send to (multicast or unicast)")
 
 	v4Port = flag.Int("v4-port", dhcpv4.ServerPort, "DHCPv4 server port to send to")
+
+	daemon    = flag.Bool("d", false, "Keep leases alive (renew, rebind, release) until terminated")
+	leaseFile = flag.String("lease-file", "/run/dhclient/leases.json", "Where to keep the state of leases with -d")
+	hook      = flag.String("hook", "", "Program to run on every change of a lease with -d")
+	release   = flag.Bool("release", true, "Release leases when terminated with -d")
 )
 
+const linkUpTimeout = 30 * time.Second
+
 func main() {
 	flag.Parse()
 	if len(flag.Args()) > 1 {
@@ -85,7 +111,11 @@ func configureAll(ifs []netlink.Link) {
 	if *vverbose {
 		c.LogLevel = dhclient.LogDebug
 	}
-	r := dhclient.SendRequests(context.Background(), ifs, *ipv4, *ipv6, c, 30*time.Second)
+	if *daemon {
+		manage(ifs, c)
+		return
+	}
+	r := dhclient.SendRequests(context.Background(), ifs, *ipv4, *ipv6, c, linkUpTimeout)
 
 	for result := range r {
 		if result.Err != nil {
@@ -100,3 +130,46 @@ func configureAll(ifs []netlink.Link) {
 	}
 	log.Printf("Finished trying to configure all interfaces.")
 }
+
+// manage configures ifs and keeps their leases until dhclient is terminated.
+func manage(ifs []netlink.Link, c dhclient.Config) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	m := &dhclient.LeaseManager{
+		Config:        c,
+		LinkUpTimeout: linkUpTimeout,
+		DryRun:        *dryRun,
+		LeaseFile:     *leaseFile,
+		Release:       *release,
+	}
+	if *hook != "" {
+		m.Hooks = append(m.Hooks, runHook(*hook))
+	}
+	m.Manage(ctx, dhclient.SendRequests(ctx, ifs, *ipv4, *ipv6, c, linkUpTimeout))
+}
+
+// runHook returns a hook that runs prog with the event in its environment.
+func runHook(prog string) dhclient.LeaseHook {
+	return func(e dhclient.LeaseEvent) {
+		var oldAddr, newAddr string
+		if e.Old != nil {
+			oldAddr = dhclient.LeaseAddr(e.Old).String()
+		}
+		if e.New != nil {
+			newAddr = dhclient.LeaseAddr(e.New).String()
+		}
+		cmd := exec.Command(prog)
+		cmd.Env = append(os.Environ(),
+			"REASON="+e.State.String(),
+			"INTERFACE="+e.Interface,
+			"PROTOCOL="+e.Protocol.String(),
+			"OLD_ADDRESS="+oldAddr,
+			"NEW_ADDRESS="+newAddr,
+		)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			log.Printf("Hook %s for %s on %s: %v", prog, e.State, e.Interface, err)
+		}
+	}
+}
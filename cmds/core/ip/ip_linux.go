@@ -52,7 +52,8 @@ type flags struct {
 
 const ipHelp = `Usage: ip [ OPTIONS ] OBJECT { COMMAND | help }
 where  OBJECT := { address |  help | link | monitor | neighbor | neighbour |
-				   route | tap | tcpmetrics | tunnel | tuntap | vrf | xfrm }
+				   netns | nexthop | route | rule | tap | tcpmetrics |
+				   tunnel | tuntap | vrf | xfrm }
        OPTIONS := { -s[tatistics] | -d[etails] | -r[esolve] |
                     -h[uman-readable] | -iec | -j[son] | -p[retty] |
                     -f[amily] { inet | inet6 | mpls | bridge | link } |
@@ -297,6 +298,15 @@ func (cmd *cmd) batchCmds() error {
 	return nil
 }
 
+// objectAbbrevs resolves abbreviations that are prefixes of several objects
+// the way iproute2 does.
+var objectAbbrevs = map[string]string{
+	"r":  "route",
+	"n":  "neigh",
+	"ne": "neigh",
+	"nh": "nexthop",
+}
+
 func (cmd *cmd) runSubCommand() error {
 	cmd.Cursor = -1
 
@@ -304,15 +314,25 @@ func (cmd *cmd) runSubCommand() error {
 		fmt.Fprint(cmd.Out, ipHelp)
 	}
 
-	switch c := cmd.findPrefix("address", "route", "link", "monitor", "neigh", "tunnel", "tuntap", "tap", "tcp_metrics", "tcpmetrics", "vrf", "xfrm", "help"); c {
+	c := cmd.findPrefix("address", "route", "rule", "link", "monitor", "neigh", "netns", "nexthop", "tunnel", "tuntap", "tap", "tcp_metrics", "tcpmetrics", "vrf", "xfrm", "help")
+	if c == "" {
+		c = objectAbbrevs[cmd.currentToken()]
+	}
+	switch c {
 	case "address":
 		return cmd.address()
 	case "link":
 		return cmd.link()
 	case "route":
 		return cmd.route()
+	case "rule":
+		return cmd.rule()
 	case "neigh":
 		return cmd.neigh()
+	case "netns":
+		return cmd.netns()
+	case "nexthop":
+		return cmd.nexthop()
 	case "monitor":
 		return cmd.monitor()
 	case "tunnel":
@@ -291,6 +291,56 @@ func TestRunSubCommand(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "rule",
+			cmd: cmd{
+				Cursor: 0,
+				Args:   []string{"rule", "help"},
+				Out:    new(bytes.Buffer),
+			},
+		},
+		{
+			name: "rule invalid",
+			cmd: cmd{
+				Cursor: 0,
+				Args:   []string{"rule", "abc"},
+				Out:    new(bytes.Buffer),
+			},
+			wantErr: true,
+		},
+		{
+			name: "netns",
+			cmd: cmd{
+				Cursor: 0,
+				Args:   []string{"netns", "help"},
+				Out:    new(bytes.Buffer),
+			},
+		},
+		{
+			name: "nexthop",
+			cmd: cmd{
+				Cursor: 0,
+				Args:   []string{"nexthop", "help"},
+				Out:    new(bytes.Buffer),
+			},
+		},
+		{
+			name: "nexthop abbreviation",
+			cmd: cmd{
+				Cursor: 0,
+				Args:   []string{"nh", "help"},
+				Out:    new(bytes.Buffer),
+			},
+		},
+		{
+			name: "nexthop invalid",
+			cmd: cmd{
+				Cursor: 0,
+				Args:   []string{"nexthop", "abc"},
+				Out:    new(bytes.Buffer),
+			},
+			wantErr: true,
+		},
 		{
 			name: "VRF",
 			cmd: cmd{
@@ -0,0 +1,247 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+const netnsHelp = `Usage: ip netns list
+       ip netns add NAME
+       ip netns { delete | del } NAME
+       ip netns identify [ PID ]
+       ip netns exec NAME cmd ...
+       ip netns help
+
+Named network namespaces are bind mounts of /proc/PID/ns/net in /run/netns.
+`
+
+// netnsRunDir is where named network namespaces are bind mounted, as in
+// iproute2.
+var netnsRunDir = "/run/netns"
+
+// netns is the entry point for 'ip netns' command.
+func (cmd *cmd) netns() error {
+	if !cmd.tokenRemains() {
+		return cmd.netnsList()
+	}
+
+	switch cmd.findPrefix("list", "show", "add", "delete", "del", "identify", "exec", "help") {
+	case "list", "show":
+		return cmd.netnsList()
+	case "add":
+		return netnsAdd(cmd.nextToken("NAME"))
+	case "delete", "del":
+		return netnsDelete(cmd.nextToken("NAME"))
+	case "identify":
+		pid := "self"
+		if cmd.tokenRemains() {
+			pid = cmd.nextToken("PID")
+		}
+		return cmd.netnsIdentify(pid)
+	case "exec":
+		name := cmd.nextToken("NAME")
+		if !cmd.tokenRemains() {
+			return fmt.Errorf("no command to run in network namespace %q", name)
+		}
+		return cmd.netnsExec(name, cmd.Args[cmd.Cursor+1:])
+	case "help":
+		fmt.Fprint(cmd.Out, netnsHelp)
+		return nil
+	default:
+		return cmd.usage()
+	}
+}
+
+// netnsPath returns the path of the named network namespace name.
+func netnsPath(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid network namespace name %q", name)
+	}
+	return filepath.Join(netnsRunDir, name), nil
+}
+
+// netnsNames returns the names of all named network namespaces.
+func netnsNames() ([]string, error) {
+	entries, err := os.ReadDir(netnsRunDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// NetnsJSON represents a named network namespace for JSON output format.
+type NetnsJSON struct {
+	Name string `json:"name"`
+}
+
+func (cmd *cmd) netnsList() error {
+	names, err := netnsNames()
+	if err != nil {
+		return err
+	}
+
+	if cmd.Opts.JSON {
+		obj := make([]NetnsJSON, 0, len(names))
+		for _, name := range names {
+			obj = append(obj, NetnsJSON{Name: name})
+		}
+		return printJSON(*cmd, obj)
+	}
+
+	for _, name := range names {
+		fmt.Fprintln(cmd.Out, name)
+	}
+	return nil
+}
+
+// netnsAdd creates a network namespace and bind mounts it at
+// /run/netns/NAME, so that it stays around without any process in it.
+func netnsAdd(name string) error {
+	path, err := netnsPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(netnsRunDir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE|os.O_EXCL, 0)
+	if err != nil {
+		return fmt.Errorf("creating network namespace %q: %w", name, err)
+	}
+	f.Close()
+
+	if err := bindNewNetns(path); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("creating network namespace %q: %w", name, err)
+	}
+	return nil
+}
+
+// bindNewNetns creates a network namespace on a locked thread, bind mounts
+// it at path, and moves the thread back to the original namespace.
+func bindNewNetns(path string) error {
+	runtime.LockOSThread()
+
+	orig, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	defer orig.Close()
+
+	if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	mountErr := unix.Mount(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()), path, "none", unix.MS_BIND, "")
+
+	// If the thread cannot go back, leave it locked so that it exits with
+	// the goroutine instead of running others in the wrong namespace.
+	if err := netns.Set(orig); err != nil {
+		return errors.Join(mountErr, err)
+	}
+	runtime.UnlockOSThread()
+	return mountErr
+}
+
+// netnsDelete unmounts and removes the named network namespace. The
+// namespace itself goes away once no process uses it any more.
+func netnsDelete(name string) error {
+	path, err := netnsPath(name)
+	if err != nil {
+		return err
+	}
+	if err := unix.Unmount(path, unix.MNT_DETACH); err != nil && !errors.Is(err, unix.EINVAL) {
+		return fmt.Errorf("unmounting network namespace %q: %w", name, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing network namespace %q: %w", name, err)
+	}
+	return nil
+}
+
+// netnsIdentify prints the names of the network namespace of process pid.
+func (cmd *cmd) netnsIdentify(pid string) error {
+	if pid != "self" {
+		if _, err := strconv.ParseUint(pid, 10, 32); err != nil {
+			return fmt.Errorf("invalid pid %q", pid)
+		}
+	}
+	var want unix.Stat_t
+	if err := unix.Stat(filepath.Join("/proc", pid, "ns/net"), &want); err != nil {
+		return fmt.Errorf("network namespace of process %s: %w", pid, err)
+	}
+
+	names, err := netnsNames()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		var st unix.Stat_t
+		if err := unix.Stat(filepath.Join(netnsRunDir, name), &st); err != nil {
+			continue
+		}
+		if st.Dev == want.Dev && st.Ino == want.Ino {
+			fmt.Fprintln(cmd.Out, name)
+		}
+	}
+	return nil
+}
+
+// netnsExec runs args in the named network namespace name.
+func (cmd *cmd) netnsExec(name string, args []string) error {
+	path, err := netnsPath(name)
+	if err != nil {
+		return err
+	}
+	ns, err := netns.GetFromPath(path)
+	if err != nil {
+		return fmt.Errorf("opening network namespace %q: %w", name, err)
+	}
+	defer ns.Close()
+
+	// The child is forked from a thread in the namespace. That thread is
+	// never unlocked, so it exits with its goroutine and is not reused.
+	errc := make(chan error)
+	go func() {
+		runtime.LockOSThread()
+		if err := netns.Set(ns); err != nil {
+			errc <- fmt.Errorf("entering network namespace %q: %w", name, err)
+			return
+		}
+
+		c := exec.Command(args[0], args[1:]...)
+		c.Stdin, c.Stdout, c.Stderr = os.Stdin, cmd.Out, os.Stderr
+		if err := c.Run(); err != nil {
+			errc <- fmt.Errorf("running %q in network namespace %q: %w", args[0], name, err)
+			return
+		}
+		errc <- nil
+	}()
+	return <-errc
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNetnsList(t *testing.T) {
+	dir := t.TempDir()
+	defer func(old string) { netnsRunDir = old }(netnsRunDir)
+	netnsRunDir = dir
+
+	for _, name := range []string{"red", "blue"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o444); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "notans"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		opts flags
+		want string
+	}{
+		{
+			name: "default",
+			want: "blue\nred\n",
+		},
+		{
+			name: "list",
+			args: []string{"list"},
+			want: "blue\nred\n",
+		},
+		{
+			name: "json",
+			args: []string{"show"},
+			opts: flags{JSON: true},
+			want: `[{"name":"blue"},{"name":"red"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := cmd{Cursor: -1, Args: tt.args, Out: &out, Opts: tt.opts}
+			if err := cmd.netns(); err != nil {
+				t.Fatalf("netns() = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("netns() got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	netnsRunDir = filepath.Join(dir, "missing")
+	var out bytes.Buffer
+	cmd := cmd{Cursor: -1, Out: &out}
+	if err := cmd.netns(); err != nil || out.Len() != 0 {
+		t.Errorf("netns() without %s = %v, %q, want no error and no output", netnsRunDir, err, out.String())
+	}
+}
+
+func TestNetns(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{
+			name: "help",
+			args: []string{"help"},
+		},
+		{
+			name:    "invalid",
+			args:    []string{"xyz"},
+			wantErr: true,
+		},
+		{
+			name:    "add bad name",
+			args:    []string{"add", "../foo"},
+			wantErr: true,
+		},
+		{
+			name:    "delete bad name",
+			args:    []string{"delete", ".."},
+			wantErr: true,
+		},
+		{
+			name:    "exec without command",
+			args:    []string{"exec", "red"},
+			wantErr: true,
+		},
+		{
+			name:    "identify bad pid",
+			args:    []string{"identify", "abc"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := cmd{Cursor: -1, Args: tt.args, Out: new(bytes.Buffer)}
+			if err := cmd.netns(); (err != nil) != tt.wantErr {
+				t.Errorf("netns() = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
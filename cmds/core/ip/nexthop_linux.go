@@ -0,0 +1,561 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/vishvananda/netlink/nl"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+const nexthopHelp = `Usage: ip nexthop { list | flush } [ SELECTOR ]
+       ip nexthop { add | replace } id ID NH [ proto RTPROTO ]
+       ip nexthop { get | del } id ID
+       ip nexthop help
+
+SELECTOR := [ id ID ] [ dev DEV ] [ groups ] [ fdb ]
+
+NH := { blackhole | [ via ADDRESS ] [ dev DEV ] [ onlink ] [ fdb ] |
+        group GROUP [ type { mpath | resilient } ] [ fdb ] }
+
+GROUP := ID[,WEIGHT]/ID[,WEIGHT]/...
+`
+
+// Nexthop attributes that x/sys/unix does not define.
+const (
+	nhaFDB = 0xb
+
+	nexthopGrpTypeMpath     = 0
+	nexthopGrpTypeResilient = 1
+
+	// sizeofNhmsg is the size of struct nhmsg.
+	sizeofNhmsg = 8
+	// sizeofNexthopGrp is the size of struct nexthop_grp.
+	sizeofNexthopGrp = 8
+)
+
+// nexthopGroupEntry is a member of a nexthop group.
+type nexthopGroupEntry struct {
+	ID     uint32
+	Weight uint16
+}
+
+// nexthop is a nexthop object, see include/uapi/linux/nexthop.h.
+type nexthop struct {
+	ID        uint32
+	Family    uint8
+	Scope     uint8
+	Protocol  uint8
+	Flags     uint32
+	Gateway   net.IP
+	OIF       int
+	Blackhole bool
+	FDB       bool
+	Group     []nexthopGroupEntry
+	GroupType uint16
+}
+
+// nexthop is the entry point for 'ip nexthop' command.
+func (cmd *cmd) nexthop() error {
+	if !cmd.tokenRemains() {
+		return cmd.nexthopShow(false)
+	}
+
+	switch cmd.findPrefix("show", "list", "add", "replace", "del", "delete", "get", "flush", "help") {
+	case "show", "list":
+		return cmd.nexthopShow(false)
+	case "flush":
+		return cmd.nexthopShow(true)
+	case "add":
+		return cmd.nexthopAdd(unix.NLM_F_CREATE | unix.NLM_F_EXCL)
+	case "replace":
+		return cmd.nexthopAdd(unix.NLM_F_CREATE | unix.NLM_F_REPLACE)
+	case "del", "delete":
+		id, err := cmd.parseNexthopID()
+		if err != nil {
+			return err
+		}
+		return cmd.nexthopDel(id)
+	case "get":
+		id, err := cmd.parseNexthopID()
+		if err != nil {
+			return err
+		}
+		nhs, err := cmd.nexthopRequest(unix.RTM_GETNEXTHOP, 0, &nexthop{ID: id})
+		if err != nil {
+			return fmt.Errorf("getting nexthop %d: %w", id, err)
+		}
+		return cmd.printNexthops(nhs, cmd.linkNames(nhs))
+	case "help":
+		fmt.Fprint(cmd.Out, nexthopHelp)
+		return nil
+	default:
+		return cmd.usage()
+	}
+}
+
+func (cmd *cmd) parseNexthopID() (uint32, error) {
+	if cmd.nextToken("id") != "id" {
+		return 0, cmd.usage()
+	}
+	id, err := strconv.ParseUint(cmd.nextToken("ID"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid nexthop id %q", cmd.currentToken())
+	}
+	return uint32(id), nil
+}
+
+func (cmd *cmd) nexthopAdd(flags int) error {
+	nh, err := cmd.parseNexthop(defaultLinkIdxResolver)
+	if err != nil {
+		return err
+	}
+	if _, err := cmd.nexthopRequest(unix.RTM_NEWNEXTHOP, flags|unix.NLM_F_ACK, nh); err != nil {
+		return fmt.Errorf("adding nexthop %d: %w", nh.ID, err)
+	}
+	return nil
+}
+
+func (cmd *cmd) nexthopDel(id uint32) error {
+	if _, err := cmd.nexthopRequest(unix.RTM_DELNEXTHOP, unix.NLM_F_ACK, &nexthop{ID: id}); err != nil {
+		return fmt.Errorf("deleting nexthop %d: %w", id, err)
+	}
+	return nil
+}
+
+// nexthopShow lists the nexthops matching the selector, and deletes them if
+// flush is set.
+func (cmd *cmd) nexthopShow(flush bool) error {
+	filter, groups, err := cmd.parseNexthopSelector(defaultLinkIdxResolver)
+	if err != nil {
+		return err
+	}
+	nhs, err := cmd.nexthopRequest(unix.RTM_GETNEXTHOP, unix.NLM_F_DUMP, nil)
+	if err != nil {
+		return fmt.Errorf("listing nexthops: %w", err)
+	}
+	nhs = filterNexthops(nhs, filter, groups)
+
+	if !flush {
+		return cmd.printNexthops(nhs, cmd.linkNames(nhs))
+	}
+	// Delete groups first, their members cannot go while they are used.
+	for _, group := range []bool{true, false} {
+		for _, nh := range nhs {
+			if (len(nh.Group) > 0) != group {
+				continue
+			}
+			if err := cmd.nexthopDel(nh.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseNexthop parses 'id ID NH' of 'ip nexthop add'.
+func (cmd *cmd) parseNexthop(resolveLinkIdxFn func(string) (int, error)) (*nexthop, error) {
+	id, err := cmd.parseNexthopID()
+	if err != nil {
+		return nil, err
+	}
+	nh := &nexthop{ID: id, Protocol: unix.RTPROT_BOOT}
+	switch cmd.Family {
+	case unix.AF_INET, unix.AF_INET6:
+		nh.Family = uint8(cmd.Family)
+	}
+
+	for cmd.tokenRemains() {
+		switch cmd.nextToken("blackhole", "via", "dev", "onlink", "group", "type", "fdb", "proto") {
+		case "blackhole":
+			nh.Blackhole = true
+		case "via":
+			token := cmd.nextToken("ADDRESS")
+			if nh.Gateway = net.ParseIP(token); nh.Gateway == nil {
+				return nil, fmt.Errorf("invalid gateway address %q", token)
+			}
+			if ip4 := nh.Gateway.To4(); ip4 != nil {
+				nh.Gateway = ip4
+				nh.Family = unix.AF_INET
+			} else {
+				nh.Family = unix.AF_INET6
+			}
+		case "dev":
+			if nh.OIF, err = resolveLinkIdxFn(cmd.nextToken("DEV")); err != nil {
+				return nil, err
+			}
+		case "onlink":
+			nh.Flags |= unix.RTNH_F_ONLINK
+		case "group":
+			if nh.Group, err = parseNexthopGroup(cmd.nextToken("GROUP")); err != nil {
+				return nil, err
+			}
+		case "type":
+			switch cmd.nextToken("mpath", "resilient") {
+			case "mpath":
+				nh.GroupType = nexthopGrpTypeMpath
+			case "resilient":
+				nh.GroupType = nexthopGrpTypeResilient
+			default:
+				return nil, cmd.usage()
+			}
+		case "fdb":
+			nh.FDB = true
+		case "proto":
+			proto, err := parseProto(cmd.nextToken("RTPROTO"))
+			if err != nil {
+				return nil, err
+			}
+			nh.Protocol = uint8(proto)
+		default:
+			return nil, cmd.usage()
+		}
+	}
+
+	switch {
+	case nh.Blackhole && (nh.Gateway != nil || nh.OIF != 0 || nh.Group != nil):
+		return nil, fmt.Errorf("blackhole nexthop cannot have a gateway, device or group")
+	case nh.Group != nil && (nh.Gateway != nil || nh.OIF != 0):
+		return nil, fmt.Errorf("nexthop group cannot have a gateway or device")
+	case !nh.Blackhole && nh.Group == nil && nh.Gateway == nil && nh.OIF == 0:
+		return nil, fmt.Errorf("nexthop %d needs a gateway, device, group or blackhole", nh.ID)
+	}
+	return nh, nil
+}
+
+// parseNexthopGroup parses ID[,WEIGHT]/ID[,WEIGHT]/...
+func parseNexthopGroup(token string) ([]nexthopGroupEntry, error) {
+	var group []nexthopGroupEntry
+	for _, member := range strings.Split(token, "/") {
+		idStr, weightStr, hasWeight := strings.Cut(member, ",")
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("invalid nexthop group member %q", member)
+		}
+		e := nexthopGroupEntry{ID: uint32(id), Weight: 1}
+		if hasWeight {
+			w, err := strconv.ParseUint(weightStr, 10, 16)
+			if err != nil || w == 0 || w > 256 {
+				return nil, fmt.Errorf("invalid nexthop weight %q", weightStr)
+			}
+			e.Weight = uint16(w)
+		}
+		group = append(group, e)
+	}
+	return group, nil
+}
+
+// parseNexthopSelector parses the SELECTOR of 'ip nexthop list' and 'ip
+// nexthop flush'.
+func (cmd *cmd) parseNexthopSelector(resolveLinkIdxFn func(string) (int, error)) (*nexthop, bool, error) {
+	filter := &nexthop{}
+	var groups bool
+	for cmd.tokenRemains() {
+		switch cmd.nextToken("id", "dev", "groups", "fdb") {
+		case "id":
+			id, err := strconv.ParseUint(cmd.nextToken("ID"), 10, 32)
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid nexthop id %q", cmd.currentToken())
+			}
+			filter.ID = uint32(id)
+		case "dev":
+			idx, err := resolveLinkIdxFn(cmd.nextToken("DEV"))
+			if err != nil {
+				return nil, false, err
+			}
+			filter.OIF = idx
+		case "groups":
+			groups = true
+		case "fdb":
+			filter.FDB = true
+		default:
+			return nil, false, cmd.usage()
+		}
+	}
+	return filter, groups, nil
+}
+
+func filterNexthops(nhs []nexthop, filter *nexthop, groups bool) []nexthop {
+	var res []nexthop
+	for _, nh := range nhs {
+		switch {
+		case filter.ID != 0 && nh.ID != filter.ID:
+		case filter.OIF != 0 && nh.OIF != filter.OIF:
+		case filter.FDB && !nh.FDB:
+		case groups && len(nh.Group) == 0:
+		default:
+			res = append(res, nh)
+		}
+	}
+	return res
+}
+
+// attrs returns the nhmsg and attributes of nh.
+func (nh *nexthop) attrs() []nl.NetlinkRequestData {
+	msg := make([]byte, sizeofNhmsg)
+	msg[0] = nh.Family
+	msg[1] = nh.Scope
+	msg[2] = nh.Protocol
+	nl.NativeEndian().PutUint32(msg[4:], nh.Flags)
+	data := []nl.NetlinkRequestData{rawData(msg)}
+
+	if nh.ID != 0 {
+		data = append(data, nl.NewRtAttr(unix.NHA_ID, nl.Uint32Attr(nh.ID)))
+	}
+	if nh.Blackhole {
+		data = append(data, nl.NewRtAttr(unix.NHA_BLACKHOLE, nil))
+	}
+	if nh.OIF != 0 {
+		data = append(data, nl.NewRtAttr(unix.NHA_OIF, nl.Uint32Attr(uint32(nh.OIF))))
+	}
+	if nh.Gateway != nil {
+		data = append(data, nl.NewRtAttr(unix.NHA_GATEWAY, nh.Gateway))
+	}
+	if len(nh.Group) > 0 {
+		b := make([]byte, 0, sizeofNexthopGrp*len(nh.Group))
+		for _, e := range nh.Group {
+			// The kernel stores the weight minus one.
+			b = append(b, nl.Uint32Attr(e.ID)...)
+			b = append(b, uint8(e.Weight-1), 0, 0, 0)
+		}
+		data = append(data, nl.NewRtAttr(unix.NHA_GROUP, b))
+		data = append(data, nl.NewRtAttr(unix.NHA_GROUP_TYPE, nl.Uint16Attr(nh.GroupType)))
+	}
+	if nh.FDB {
+		data = append(data, nl.NewRtAttr(nhaFDB, nil))
+	}
+	return data
+}
+
+// parseNexthopMsg parses an RTM_NEWNEXTHOP message.
+func parseNexthopMsg(b []byte) (nexthop, error) {
+	if len(b) < sizeofNhmsg {
+		return nexthop{}, fmt.Errorf("nexthop message too short: %d bytes", len(b))
+	}
+	nh := nexthop{
+		Family:   b[0],
+		Scope:    b[1],
+		Protocol: b[2],
+		Flags:    nl.NativeEndian().Uint32(b[4:8]),
+	}
+	attrs, err := nl.ParseRouteAttr(b[sizeofNhmsg:])
+	if err != nil {
+		return nexthop{}, err
+	}
+	for _, a := range attrs {
+		switch a.Attr.Type {
+		case unix.NHA_ID:
+			nh.ID = nl.NativeEndian().Uint32(a.Value)
+		case unix.NHA_BLACKHOLE:
+			nh.Blackhole = true
+		case unix.NHA_OIF:
+			nh.OIF = int(nl.NativeEndian().Uint32(a.Value))
+		case unix.NHA_GATEWAY:
+			nh.Gateway = net.IP(a.Value)
+		case unix.NHA_GROUP:
+			for v := a.Value; len(v) >= sizeofNexthopGrp; v = v[sizeofNexthopGrp:] {
+				nh.Group = append(nh.Group, nexthopGroupEntry{
+					ID:     nl.NativeEndian().Uint32(v),
+					Weight: uint16(v[4]) + 1,
+				})
+			}
+		case unix.NHA_GROUP_TYPE:
+			nh.GroupType = nl.NativeEndian().Uint16(a.Value)
+		case nhaFDB:
+			nh.FDB = true
+		}
+	}
+	return nh, nil
+}
+
+// rawData is request data that is sent as is.
+type rawData []byte
+
+func (r rawData) Len() int          { return len(r) }
+func (r rawData) Serialize() []byte { return r }
+
+// nexthopRequest sends a nexthop request of type typ, in the network
+// namespace given by -netns, and returns the nexthops in the reply.
+func (cmd *cmd) nexthopRequest(typ, flags int, nh *nexthop) ([]nexthop, error) {
+	req := nl.NewNetlinkRequest(typ, flags)
+	if nh == nil {
+		nh = &nexthop{}
+	}
+	for _, d := range nh.attrs() {
+		req.AddData(d)
+	}
+
+	if cmd.Opts.Netns != "" {
+		ns, err := netns.GetFromName(cmd.Opts.Netns)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find network namespace %q: %w", cmd.Opts.Netns, err)
+		}
+		defer ns.Close()
+		cur, err := netns.Get()
+		if err != nil {
+			return nil, err
+		}
+		defer cur.Close()
+		s, err := nl.GetNetlinkSocketAt(ns, cur, unix.NETLINK_ROUTE)
+		if err != nil {
+			return nil, err
+		}
+		defer s.Close()
+		req.Sockets = map[int]*nl.SocketHandle{unix.NETLINK_ROUTE: {Socket: s}}
+	}
+
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWNEXTHOP)
+	if err != nil {
+		return nil, err
+	}
+	var nhs []nexthop
+	for _, m := range msgs {
+		nh, err := parseNexthopMsg(m)
+		if err != nil {
+			return nil, err
+		}
+		nhs = append(nhs, nh)
+	}
+	return nhs, nil
+}
+
+// linkNames returns the names of the devices of nhs by index.
+func (cmd *cmd) linkNames(nhs []nexthop) map[int]string {
+	names := make(map[int]string)
+	for _, nh := range nhs {
+		if nh.OIF == 0 || names[nh.OIF] != "" {
+			continue
+		}
+		if l, err := cmd.handle.LinkByIndex(nh.OIF); err == nil {
+			names[nh.OIF] = l.Attrs().Name
+		} else {
+			names[nh.OIF] = fmt.Sprintf("if%d", nh.OIF)
+		}
+	}
+	return names
+}
+
+// NexthopGroupJSON represents a member of a nexthop group for JSON output
+// format.
+type NexthopGroupJSON struct {
+	ID     uint32 `json:"id"`
+	Weight uint16 `json:"weight,omitempty"`
+}
+
+// NexthopJSON represents a nexthop for JSON output format.
+type NexthopJSON struct {
+	ID        uint32             `json:"id"`
+	Group     []NexthopGroupJSON `json:"group,omitempty"`
+	Type      string             `json:"type,omitempty"`
+	Gateway   string             `json:"gateway,omitempty"`
+	Dev       string             `json:"dev,omitempty"`
+	Blackhole bool               `json:"blackhole,omitempty"`
+	Scope     string             `json:"scope,omitempty"`
+	Protocol  string             `json:"protocol,omitempty"`
+	Flags     []string           `json:"flags"`
+	FDB       bool               `json:"fdb,omitempty"`
+}
+
+func nexthopJSON(nh nexthop, names map[int]string) NexthopJSON {
+	j := NexthopJSON{
+		ID:        nh.ID,
+		Dev:       names[nh.OIF],
+		Blackhole: nh.Blackhole,
+		FDB:       nh.FDB,
+		Flags:     []string{},
+	}
+	for _, e := range nh.Group {
+		g := NexthopGroupJSON{ID: e.ID}
+		if e.Weight > 1 {
+			g.Weight = e.Weight
+		}
+		j.Group = append(j.Group, g)
+	}
+	if nh.GroupType == nexthopGrpTypeResilient {
+		j.Type = "resilient"
+	}
+	if nh.Gateway != nil {
+		j.Gateway = nh.Gateway.String()
+	}
+	if nh.Scope == unix.RT_SCOPE_LINK {
+		j.Scope = "link"
+	}
+	if nh.Protocol != unix.RTPROT_UNSPEC {
+		j.Protocol = routeProtoStr(int(nh.Protocol))
+	}
+	for _, f := range []struct {
+		flag uint32
+		name string
+	}{
+		{unix.RTNH_F_DEAD, "dead"},
+		{unix.RTNH_F_ONLINK, "onlink"},
+		{unix.RTNH_F_LINKDOWN, "linkdown"},
+	} {
+		if nh.Flags&f.flag != 0 {
+			j.Flags = append(j.Flags, f.name)
+		}
+	}
+	return j
+}
+
+// printNexthops prints nexthops the way 'ip nexthop list' does.
+func (cmd *cmd) printNexthops(nhs []nexthop, names map[int]string) error {
+	if cmd.Opts.JSON {
+		obj := make([]NexthopJSON, 0, len(nhs))
+		for _, nh := range nhs {
+			obj = append(obj, nexthopJSON(nh, names))
+		}
+		return printJSON(*cmd, obj)
+	}
+
+	for _, nh := range nhs {
+		j := nexthopJSON(nh, names)
+		var b strings.Builder
+		fmt.Fprintf(&b, "id %d", j.ID)
+		if len(j.Group) > 0 {
+			members := make([]string, 0, len(j.Group))
+			for _, g := range j.Group {
+				if g.Weight > 1 {
+					members = append(members, fmt.Sprintf("%d,%d", g.ID, g.Weight))
+				} else {
+					members = append(members, strconv.Itoa(int(g.ID)))
+				}
+			}
+			fmt.Fprintf(&b, " group %s", strings.Join(members, "/"))
+		}
+		if j.Type != "" {
+			fmt.Fprintf(&b, " type %s", j.Type)
+		}
+		if j.Blackhole {
+			b.WriteString(" blackhole")
+		}
+		if j.Gateway != "" {
+			fmt.Fprintf(&b, " via %s", j.Gateway)
+		}
+		if j.Dev != "" {
+			fmt.Fprintf(&b, " dev %s", j.Dev)
+		}
+		if j.Scope != "" {
+			fmt.Fprintf(&b, " scope %s", j.Scope)
+		}
+		if j.Protocol != "" {
+			fmt.Fprintf(&b, " proto %s", j.Protocol)
+		}
+		for _, f := range j.Flags {
+			fmt.Fprintf(&b, " %s", f)
+		}
+		if j.FDB {
+			b.WriteString(" fdb")
+		}
+		fmt.Fprintln(cmd.Out, b.String())
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseNexthop(t *testing.T) {
+	linkToIdx := map[string]int{
+		"lo":   1,
+		"eth0": 2,
+	}
+	stubLinkIdxResolver := func(name string) (int, error) {
+		if idx, ok := linkToIdx[name]; ok {
+			return idx, nil
+		}
+		return 0, fmt.Errorf("test error: link %s not found", name)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    *nexthop
+		wantErr bool
+	}{
+		{
+			name: "gateway",
+			args: []string{"id", "1", "via", "10.0.0.1", "dev", "eth0"},
+			want: &nexthop{ID: 1, Family: unix.AF_INET, Protocol: unix.RTPROT_BOOT, Gateway: net.IPv4(10, 0, 0, 1).To4(), OIF: 2},
+		},
+		{
+			name: "inet6 onlink",
+			args: []string{"id", "2", "via", "fe80::1", "dev", "eth0", "onlink", "proto", "static"},
+			want: &nexthop{ID: 2, Family: unix.AF_INET6, Protocol: unix.RTPROT_STATIC, Gateway: net.ParseIP("fe80::1"), OIF: 2, Flags: unix.RTNH_F_ONLINK},
+		},
+		{
+			name: "device",
+			args: []string{"id", "3", "dev", "lo"},
+			want: &nexthop{ID: 3, Protocol: unix.RTPROT_BOOT, OIF: 1},
+		},
+		{
+			name: "blackhole",
+			args: []string{"id", "4", "blackhole"},
+			want: &nexthop{ID: 4, Protocol: unix.RTPROT_BOOT, Blackhole: true},
+		},
+		{
+			name: "group",
+			args: []string{"id", "10", "group", "1/2,3", "type", "resilient"},
+			want: &nexthop{
+				ID:        10,
+				Protocol:  unix.RTPROT_BOOT,
+				Group:     []nexthopGroupEntry{{ID: 1, Weight: 1}, {ID: 2, Weight: 3}},
+				GroupType: nexthopGrpTypeResilient,
+			},
+		},
+		{
+			name:    "no id",
+			args:    []string{"via", "10.0.0.1"},
+			wantErr: true,
+		},
+		{
+			name:    "id zero",
+			args:    []string{"id", "0", "blackhole"},
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			args:    []string{"id", "1"},
+			wantErr: true,
+		},
+		{
+			name:    "blackhole with gateway",
+			args:    []string{"id", "1", "blackhole", "via", "10.0.0.1"},
+			wantErr: true,
+		},
+		{
+			name:    "group with device",
+			args:    []string{"id", "1", "group", "2/3", "dev", "eth0"},
+			wantErr: true,
+		},
+		{
+			name:    "bad weight",
+			args:    []string{"id", "1", "group", "2,0/3"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown device",
+			args:    []string{"id", "1", "dev", "eth9"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := cmd{Cursor: -1, Args: tt.args}
+			got, err := cmd.parseNexthop(stubLinkIdxResolver)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseNexthop() = %v, want error %t", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseNexthop() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNexthopMsg(t *testing.T) {
+	for _, nh := range []nexthop{
+		{ID: 1, Family: unix.AF_INET, Protocol: unix.RTPROT_BOOT, Gateway: net.IPv4(10, 0, 0, 1).To4(), OIF: 2, Flags: unix.RTNH_F_ONLINK},
+		{ID: 4, Protocol: unix.RTPROT_STATIC, Blackhole: true},
+		{ID: 10, Group: []nexthopGroupEntry{{ID: 1, Weight: 1}, {ID: 2, Weight: 256}}, GroupType: nexthopGrpTypeResilient, FDB: true},
+	} {
+		t.Run(fmt.Sprint(nh.ID), func(t *testing.T) {
+			var b []byte
+			for _, d := range nh.attrs() {
+				b = append(b, d.Serialize()...)
+			}
+			got, err := parseNexthopMsg(b)
+			if err != nil {
+				t.Fatalf("parseNexthopMsg() = %v", err)
+			}
+			if diff := cmp.Diff(nh, got); diff != "" {
+				t.Errorf("nexthop message round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := parseNexthopMsg([]byte{1, 2}); err == nil {
+		t.Errorf("parseNexthopMsg(short) = nil, want error")
+	}
+}
+
+func TestFilterNexthops(t *testing.T) {
+	nhs := []nexthop{
+		{ID: 1, OIF: 2},
+		{ID: 2, OIF: 3, FDB: true},
+		{ID: 10, Group: []nexthopGroupEntry{{ID: 1, Weight: 1}}},
+	}
+	for _, tt := range []struct {
+		name   string
+		filter nexthop
+		groups bool
+		want   []uint32
+	}{
+		{name: "all", want: []uint32{1, 2, 10}},
+		{name: "id", filter: nexthop{ID: 2}, want: []uint32{2}},
+		{name: "dev", filter: nexthop{OIF: 2}, want: []uint32{1}},
+		{name: "fdb", filter: nexthop{FDB: true}, want: []uint32{2}},
+		{name: "groups", groups: true, want: []uint32{10}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []uint32
+			for _, nh := range filterNexthops(nhs, &tt.filter, tt.groups) {
+				got = append(got, nh.ID)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("filterNexthops() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrintNexthops(t *testing.T) {
+	nhs := []nexthop{
+		{ID: 1, Family: unix.AF_INET, Scope: unix.RT_SCOPE_LINK, Protocol: unix.RTPROT_BOOT, Gateway: net.IPv4(10, 0, 0, 1).To4(), OIF: 2},
+		{ID: 4, Protocol: unix.RTPROT_STATIC, Blackhole: true},
+		{ID: 10, Protocol: unix.RTPROT_BOOT, Group: []nexthopGroupEntry{{ID: 1, Weight: 1}, {ID: 4, Weight: 3}}},
+	}
+	names := map[int]string{2: "eth0"}
+
+	tests := []struct {
+		name string
+		opts flags
+		want string
+	}{
+		{
+			name: "text",
+			want: "id 1 via 10.0.0.1 dev eth0 scope link proto boot\n" +
+				"id 4 blackhole proto static\n" +
+				"id 10 group 1/4,3 proto boot\n",
+		},
+		{
+			name: "json",
+			opts: flags{JSON: true},
+			want: `[{"id":1,"gateway":"10.0.0.1","dev":"eth0","scope":"link","protocol":"boot","flags":[]},` +
+				`{"id":4,"blackhole":true,"protocol":"static","flags":[]},` +
+				`{"id":10,"group":[{"id":1},{"id":4,"weight":3}],"protocol":"boot","flags":[]}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := cmd{Out: &out, Opts: tt.opts}
+			if err := cmd.printNexthops(nhs, names); err != nil {
+				t.Fatalf("printNexthops() = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("printNexthops() got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
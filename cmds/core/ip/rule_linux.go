@@ -0,0 +1,511 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+const ruleHelp = `Usage: ip rule [ list [ SELECTOR ]]
+       ip rule { add | del } SELECTOR ACTION
+       ip rule flush [ SELECTOR ]
+       ip rule help
+
+SELECTOR := [ not ] [ from PREFIX ] [ to PREFIX ] [ tos TOS ]
+            [ fwmark FWMARK[/MASK] ] [ iif STRING ] [ oif STRING ]
+            [ pref NUMBER ] [ uidrange NUMBER-NUMBER ]
+            [ ipproto PROTOCOL ] [ sport [ NUMBER | NUMBER-NUMBER ] ]
+            [ dport [ NUMBER | NUMBER-NUMBER ] ]
+
+ACTION := [ table TABLE_ID ] [ protocol RTPROTO ] [ goto NUMBER ]
+          [ suppress_prefixlength NUMBER ] [ suppress_ifgroup NUMBER ]
+          [ TYPE ]
+
+TYPE := { unicast | blackhole | unreachable | prohibit | nop }
+
+TABLE_ID := [ local | main | default | NUMBER ]
+`
+
+var ruleTypes = map[string]uint8{
+	"unicast":     nl.FR_ACT_TO_TBL,
+	"nop":         nl.FR_ACT_NOP,
+	"blackhole":   nl.FR_ACT_BLACKHOLE,
+	"unreachable": nl.FR_ACT_UNREACHABLE,
+	"prohibit":    nl.FR_ACT_PROHIBIT,
+}
+
+var ipProtos = map[string]int{
+	"icmp":   unix.IPPROTO_ICMP,
+	"tcp":    unix.IPPROTO_TCP,
+	"udp":    unix.IPPROTO_UDP,
+	"sctp":   unix.IPPROTO_SCTP,
+	"icmpv6": unix.IPPROTO_ICMPV6,
+}
+
+// rule is the entry point for 'ip rule' command.
+func (cmd *cmd) rule() error {
+	if !cmd.tokenRemains() {
+		return cmd.ruleShow()
+	}
+
+	switch cmd.findPrefix("show", "list", "add", "del", "delete", "flush", "help") {
+	case "show", "list":
+		return cmd.ruleShow()
+	case "add":
+		return cmd.ruleAdd()
+	case "del", "delete":
+		return cmd.ruleDel()
+	case "flush":
+		return cmd.ruleFlush()
+	case "help":
+		fmt.Fprint(cmd.Out, ruleHelp)
+		return nil
+	default:
+		return cmd.usage()
+	}
+}
+
+// ruleFamily returns the family 'ip rule' works on: rules are listed for
+// IPv4 unless -6 is given, like iproute2 does.
+func (cmd *cmd) ruleFamily() int {
+	if cmd.Family == netlink.FAMILY_ALL {
+		return netlink.FAMILY_V4
+	}
+	return cmd.Family
+}
+
+func (cmd *cmd) ruleAdd() error {
+	rule, err := cmd.parseRule()
+	if err != nil {
+		return err
+	}
+	// Without an action, iproute2 looks up the main table.
+	if rule.Table == 0 && rule.Goto < 0 && rule.Type == 0 {
+		rule.Table = unix.RT_TABLE_MAIN
+	}
+
+	if err := cmd.handle.RuleAdd(rule); err != nil {
+		return fmt.Errorf("adding rule: %w", err)
+	}
+	return nil
+}
+
+func (cmd *cmd) ruleDel() error {
+	rule, err := cmd.parseRule()
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.handle.RuleDel(rule); err != nil {
+		return fmt.Errorf("deleting rule: %w", err)
+	}
+	return nil
+}
+
+func (cmd *cmd) ruleShow() error {
+	filter, err := cmd.parseRule()
+	if err != nil {
+		return err
+	}
+
+	rules, err := cmd.handle.RuleList(cmd.ruleFamily())
+	if err != nil {
+		return fmt.Errorf("listing rules: %w", err)
+	}
+
+	return cmd.printRules(filterRules(rules, filter))
+}
+
+// ruleFlush deletes all rules matching the selector, except the rule with
+// priority 0, which cannot be deleted.
+func (cmd *cmd) ruleFlush() error {
+	filter, err := cmd.parseRule()
+	if err != nil {
+		return err
+	}
+
+	rules, err := cmd.handle.RuleList(cmd.ruleFamily())
+	if err != nil {
+		return fmt.Errorf("listing rules: %w", err)
+	}
+
+	for _, r := range filterRules(rules, filter) {
+		if r.Priority == 0 {
+			continue
+		}
+		if err := cmd.handle.RuleDel(&r); err != nil {
+			return fmt.Errorf("deleting rule %d: %w", r.Priority, err)
+		}
+	}
+	return nil
+}
+
+// parseRule parses the SELECTOR and ACTION of a rule.
+func (cmd *cmd) parseRule() (*netlink.Rule, error) {
+	rule := netlink.NewRule()
+	if cmd.Family == netlink.FAMILY_V6 {
+		rule.Family = netlink.FAMILY_V6
+	}
+
+	for cmd.tokenRemains() {
+		token := cmd.nextToken("not", "from", "to", "tos", "fwmark", "iif", "oif", "pref", "uidrange",
+			"ipproto", "sport", "dport", "table", "protocol", "goto", "suppress_prefixlength", "suppress_ifgroup", "TYPE")
+		if t, ok := ruleTypes[token]; ok {
+			rule.Type = t
+			continue
+		}
+
+		var err error
+		switch token {
+		case "not":
+			rule.Invert = true
+		case "from":
+			rule.Src, err = parseRulePrefix(cmd.nextToken("PREFIX", "all"))
+		case "to":
+			rule.Dst, err = parseRulePrefix(cmd.nextToken("PREFIX", "all"))
+		case "tos", "dsfield":
+			var tos int
+			tos, err = parseTOS(cmd.nextToken("TOS"))
+			rule.Tos = uint(tos)
+		case "fwmark":
+			rule.Mark, rule.Mask, err = parseFwmark(cmd.nextToken("FWMARK[/MASK]"))
+		case "iif", "dev":
+			rule.IifName = cmd.nextToken("STRING")
+		case "oif":
+			rule.OifName = cmd.nextToken("STRING")
+		case "pref", "priority", "order":
+			var pref uint64
+			pref, err = strconv.ParseUint(cmd.nextToken("NUMBER"), 10, 32)
+			rule.Priority = int(pref)
+		case "uidrange":
+			var start, end uint64
+			start, end, err = parseRange(cmd.nextToken("NUMBER-NUMBER"), 32)
+			rule.UIDRange = netlink.NewRuleUIDRange(uint32(start), uint32(end))
+		case "ipproto":
+			rule.IPProto, err = parseIPProto(cmd.nextToken("PROTOCOL"))
+		case "sport", "dport":
+			var start, end uint64
+			start, end, err = parseRange(cmd.nextToken("NUMBER", "NUMBER-NUMBER"), 16)
+			r := netlink.NewRulePortRange(uint16(start), uint16(end))
+			if token == "sport" {
+				rule.Sport = r
+			} else {
+				rule.Dport = r
+			}
+		case "table", "lookup":
+			rule.Table, err = parseTable(cmd.nextToken("TABLE_ID"))
+		case "protocol", "proto":
+			var proto netlink.RouteProtocol
+			proto, err = parseProto(cmd.nextToken("RTPROTO"))
+			rule.Protocol = uint8(proto)
+		case "goto":
+			rule.Goto, err = cmd.parseInt("NUMBER")
+		case "suppress_prefixlength":
+			rule.SuppressPrefixlen, err = cmd.parseInt("NUMBER")
+		case "suppress_ifgroup":
+			rule.SuppressIfgroup, err = cmd.parseInt("NUMBER")
+		default:
+			return nil, cmd.usage()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rule, nil
+}
+
+// parseRulePrefix parses a prefix or address, where "all" matches any
+// address.
+func parseRulePrefix(token string) (*net.IPNet, error) {
+	if token == "all" || token == "default" {
+		return nil, nil
+	}
+	if strings.Contains(token, "/") {
+		_, ipNet, err := net.ParseCIDR(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix %q: %w", token, err)
+		}
+		return ipNet, nil
+	}
+	ip := net.ParseIP(token)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid prefix %q", token)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// parseFwmark parses FWMARK[/MASK], where both are decimal or hexadecimal
+// with a 0x prefix.
+func parseFwmark(token string) (uint32, *uint32, error) {
+	markStr, maskStr, hasMask := strings.Cut(token, "/")
+	mark, err := strconv.ParseUint(markStr, 0, 32)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid fwmark %q: %w", token, err)
+	}
+	if !hasMask {
+		return uint32(mark), nil, nil
+	}
+	mask, err := strconv.ParseUint(maskStr, 0, 32)
+	if err != nil {
+		return 0, nil, fmt.Errorf("invalid fwmark mask %q: %w", token, err)
+	}
+	m := uint32(mask)
+	return uint32(mark), &m, nil
+}
+
+// parseRange parses NUMBER or NUMBER-NUMBER.
+func parseRange(token string, bitSize int) (uint64, uint64, error) {
+	startStr, endStr, isRange := strings.Cut(token, "-")
+	start, err := strconv.ParseUint(startStr, 10, bitSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", token, err)
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err := strconv.ParseUint(endStr, 10, bitSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid range %q: %w", token, err)
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid range %q: end before start", token)
+	}
+	return start, end, nil
+}
+
+func parseIPProto(token string) (int, error) {
+	if p, ok := ipProtos[token]; ok {
+		return p, nil
+	}
+	p, err := strconv.ParseUint(token, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ip protocol %q: %w", token, err)
+	}
+	return int(p), nil
+}
+
+// filterRules returns the rules that match all fields set in filter.
+func filterRules(rules []netlink.Rule, filter *netlink.Rule) []netlink.Rule {
+	var res []netlink.Rule
+	for _, r := range rules {
+		switch {
+		case filter.Priority >= 0 && r.Priority != filter.Priority:
+		case filter.Table != 0 && r.Table != filter.Table:
+		case filter.Src != nil && (r.Src == nil || r.Src.String() != filter.Src.String()):
+		case filter.Dst != nil && (r.Dst == nil || r.Dst.String() != filter.Dst.String()):
+		case filter.IifName != "" && r.IifName != filter.IifName:
+		case filter.OifName != "" && r.OifName != filter.OifName:
+		case filter.Mark != 0 && r.Mark != filter.Mark:
+		case filter.Invert && !r.Invert:
+		default:
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func tableStr(table int) string {
+	switch table {
+	case unix.RT_TABLE_LOCAL:
+		return "local"
+	case unix.RT_TABLE_MAIN:
+		return "main"
+	case unix.RT_TABLE_DEFAULT:
+		return "default"
+	}
+	return strconv.Itoa(table)
+}
+
+func ipProtoStr(proto int) string {
+	for name, p := range ipProtos {
+		if p == proto {
+			return name
+		}
+	}
+	return strconv.Itoa(proto)
+}
+
+func portRangeStr(r *netlink.RulePortRange) string {
+	if r.Start == r.End {
+		return strconv.Itoa(int(r.Start))
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// RuleJSON represents a rule for JSON output format.
+type RuleJSON struct {
+	Priority          int    `json:"priority"`
+	Not               bool   `json:"not,omitempty"`
+	Src               string `json:"src"`
+	SrcLen            int    `json:"srclen,omitempty"`
+	Dst               string `json:"dst,omitempty"`
+	DstLen            int    `json:"dstlen,omitempty"`
+	Tos               string `json:"tos,omitempty"`
+	FwMark            string `json:"fwmark,omitempty"`
+	FwMask            string `json:"fwmask,omitempty"`
+	Iif               string `json:"iif,omitempty"`
+	Oif               string `json:"oif,omitempty"`
+	UIDRange          string `json:"uidrange,omitempty"`
+	IPProto           string `json:"ipproto,omitempty"`
+	Sport             string `json:"sport,omitempty"`
+	Dport             string `json:"dport,omitempty"`
+	Table             string `json:"table,omitempty"`
+	Target            int    `json:"target,omitempty"`
+	Action            string `json:"action,omitempty"`
+	SuppressPrefixLen *int   `json:"suppress_prefixlen,omitempty"`
+	SuppressIfgroup   *int   `json:"suppress_ifgroup,omitempty"`
+	Protocol          string `json:"protocol,omitempty"`
+}
+
+func ruleJSON(r netlink.Rule) RuleJSON {
+	j := RuleJSON{
+		Priority: r.Priority,
+		Not:      r.Invert,
+		Src:      "all",
+		Iif:      r.IifName,
+		Oif:      r.OifName,
+	}
+	if r.Src != nil {
+		j.Src = r.Src.IP.String()
+		j.SrcLen, _ = r.Src.Mask.Size()
+	}
+	if r.Dst != nil {
+		j.Dst = r.Dst.IP.String()
+		j.DstLen, _ = r.Dst.Mask.Size()
+	}
+	if r.Tos != 0 {
+		j.Tos = fmt.Sprintf("%#x", r.Tos)
+	}
+	if r.Mark != 0 || r.Mask != nil {
+		j.FwMark = fmt.Sprintf("%#x", r.Mark)
+		if r.Mask != nil {
+			j.FwMask = fmt.Sprintf("%#x", *r.Mask)
+		}
+	}
+	if r.UIDRange != nil {
+		j.UIDRange = fmt.Sprintf("%d-%d", r.UIDRange.Start, r.UIDRange.End)
+	}
+	if r.IPProto != 0 {
+		j.IPProto = ipProtoStr(r.IPProto)
+	}
+	if r.Sport != nil {
+		j.Sport = portRangeStr(r.Sport)
+	}
+	if r.Dport != nil {
+		j.Dport = portRangeStr(r.Dport)
+	}
+	switch {
+	case r.Goto >= 0:
+		j.Action = "goto"
+		j.Target = r.Goto
+	case r.Table != 0:
+		j.Table = tableStr(r.Table)
+	}
+	for name, t := range ruleTypes {
+		if t == r.Type && t != nl.FR_ACT_TO_TBL {
+			j.Action = name
+		}
+	}
+	if r.SuppressPrefixlen >= 0 {
+		n := r.SuppressPrefixlen
+		j.SuppressPrefixLen = &n
+	}
+	if r.SuppressIfgroup >= 0 {
+		n := r.SuppressIfgroup
+		j.SuppressIfgroup = &n
+	}
+	if r.Protocol != 0 {
+		j.Protocol = routeProtoStr(int(r.Protocol))
+	}
+	return j
+}
+
+func routeProtoStr(proto int) string {
+	if s, ok := rtProto[proto]; ok {
+		return s
+	}
+	return strconv.Itoa(proto)
+}
+
+// printRules prints rules the way 'ip rule list' does.
+func (cmd *cmd) printRules(rules []netlink.Rule) error {
+	if cmd.Opts.JSON {
+		obj := make([]RuleJSON, 0, len(rules))
+		for _, r := range rules {
+			obj = append(obj, ruleJSON(r))
+		}
+		return printJSON(*cmd, obj)
+	}
+
+	for _, r := range rules {
+		j := ruleJSON(r)
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d:\t", j.Priority)
+		if j.Not {
+			b.WriteString("not ")
+		}
+		b.WriteString("from ")
+		if r.Src != nil {
+			b.WriteString(r.Src.String())
+		} else {
+			b.WriteString("all")
+		}
+		if r.Dst != nil {
+			fmt.Fprintf(&b, " to %s", r.Dst)
+		}
+		for _, f := range []struct{ name, val string }{
+			{"tos", j.Tos},
+			{"iif", j.Iif},
+			{"oif", j.Oif},
+			{"uidrange", j.UIDRange},
+			{"ipproto", j.IPProto},
+			{"sport", j.Sport},
+			{"dport", j.Dport},
+		} {
+			if f.val != "" {
+				fmt.Fprintf(&b, " %s %s", f.name, f.val)
+			}
+		}
+		if j.FwMark != "" {
+			fmt.Fprintf(&b, " fwmark %s", j.FwMark)
+			if j.FwMask != "" {
+				fmt.Fprintf(&b, "/%s", j.FwMask)
+			}
+		}
+		if j.Table != "" {
+			fmt.Fprintf(&b, " lookup %s", j.Table)
+		}
+		if j.SuppressPrefixLen != nil {
+			fmt.Fprintf(&b, " suppress_prefixlength %d", *j.SuppressPrefixLen)
+		}
+		if j.SuppressIfgroup != nil {
+			fmt.Fprintf(&b, " suppress_ifgroup %d", *j.SuppressIfgroup)
+		}
+		switch j.Action {
+		case "":
+		case "goto":
+			fmt.Fprintf(&b, " goto %d", j.Target)
+		default:
+			fmt.Fprintf(&b, " %s", j.Action)
+		}
+		if j.Protocol != "" {
+			fmt.Fprintf(&b, " proto %s", j.Protocol)
+		}
+		fmt.Fprintln(cmd.Out, b.String())
+	}
+	return nil
+}
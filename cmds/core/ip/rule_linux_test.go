@@ -0,0 +1,217 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+func TestParseRule(t *testing.T) {
+	mask := uint32(0xff)
+	withRule := func(f func(r *netlink.Rule)) *netlink.Rule {
+		r := netlink.NewRule()
+		f(r)
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		family  int
+		want    *netlink.Rule
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			want: netlink.NewRule(),
+		},
+		{
+			name: "source routing",
+			args: []string{"from", "10.0.1.0/24", "table", "100", "pref", "1000"},
+			want: withRule(func(r *netlink.Rule) {
+				r.Src = &net.IPNet{IP: net.IPv4(10, 0, 1, 0).To4(), Mask: net.CIDRMask(24, 32)}
+				r.Table = 100
+				r.Priority = 1000
+			}),
+		},
+		{
+			name: "address and names",
+			args: []string{"not", "to", "10.0.0.1", "iif", "eth0", "oif", "eth1", "lookup", "main"},
+			want: withRule(func(r *netlink.Rule) {
+				r.Invert = true
+				r.Dst = &net.IPNet{IP: net.IPv4(10, 0, 0, 1).To4(), Mask: net.CIDRMask(32, 32)}
+				r.IifName = "eth0"
+				r.OifName = "eth1"
+				r.Table = unix.RT_TABLE_MAIN
+			}),
+		},
+		{
+			name: "selectors",
+			args: []string{
+				"from", "all", "fwmark", "0x10/0xff", "tos", "10", "uidrange", "1000-2000",
+				"ipproto", "tcp", "sport", "80", "dport", "1024-2048", "proto", "static",
+			},
+			want: withRule(func(r *netlink.Rule) {
+				r.Mark = 0x10
+				r.Mask = &mask
+				r.Tos = 0x10
+				r.UIDRange = netlink.NewRuleUIDRange(1000, 2000)
+				r.IPProto = unix.IPPROTO_TCP
+				r.Sport = netlink.NewRulePortRange(80, 80)
+				r.Dport = netlink.NewRulePortRange(1024, 2048)
+				r.Protocol = unix.RTPROT_STATIC
+			}),
+		},
+		{
+			name: "goto and suppress",
+			args: []string{"goto", "200", "suppress_prefixlength", "0"},
+			want: withRule(func(r *netlink.Rule) {
+				r.Goto = 200
+				r.SuppressPrefixlen = 0
+			}),
+		},
+		{
+			name: "blackhole",
+			args: []string{"from", "192.168.0.0/16", "blackhole"},
+			want: withRule(func(r *netlink.Rule) {
+				r.Src = &net.IPNet{IP: net.IPv4(192, 168, 0, 0).To4(), Mask: net.CIDRMask(16, 32)}
+				r.Type = nl.FR_ACT_BLACKHOLE
+			}),
+		},
+		{
+			name:   "inet6",
+			args:   []string{"from", "fd00::/64", "table", "10"},
+			family: netlink.FAMILY_V6,
+			want: withRule(func(r *netlink.Rule) {
+				r.Family = netlink.FAMILY_V6
+				r.Src = &net.IPNet{IP: net.ParseIP("fd00::"), Mask: net.CIDRMask(64, 128)}
+				r.Table = 10
+			}),
+		},
+		{
+			name:    "bad prefix",
+			args:    []string{"from", "10.0.0.300"},
+			wantErr: true,
+		},
+		{
+			name:    "bad range",
+			args:    []string{"dport", "2000-1000"},
+			wantErr: true,
+		},
+		{
+			name:    "bad fwmark",
+			args:    []string{"fwmark", "0x10/xyz"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown selector",
+			args:    []string{"foo"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := cmd{Cursor: -1, Args: tt.args, Family: tt.family}
+			got, err := cmd.parseRule()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRule() = %v, want error %t", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseRule() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterRules(t *testing.T) {
+	rules := []netlink.Rule{
+		{Priority: 0, Table: unix.RT_TABLE_LOCAL},
+		{Priority: 100, Table: 100, Src: &net.IPNet{IP: net.IPv4(10, 0, 1, 0).To4(), Mask: net.CIDRMask(24, 32)}},
+		{Priority: 32766, Table: unix.RT_TABLE_MAIN},
+	}
+
+	filter := netlink.NewRule()
+	filter.Table = 100
+	if got := filterRules(rules, filter); len(got) != 1 || got[0].Priority != 100 {
+		t.Errorf("filterRules(table 100) = %v, want rule 100", got)
+	}
+
+	filter = netlink.NewRule()
+	filter.Priority = 32766
+	if got := filterRules(rules, filter); len(got) != 1 || got[0].Table != unix.RT_TABLE_MAIN {
+		t.Errorf("filterRules(pref 32766) = %v, want main rule", got)
+	}
+
+	if got := filterRules(rules, netlink.NewRule()); len(got) != len(rules) {
+		t.Errorf("filterRules(all) = %v, want all rules", got)
+	}
+}
+
+func TestPrintRules(t *testing.T) {
+	mask := uint32(0xff)
+	lookup100 := netlink.NewRule()
+	lookup100.Priority = 100
+	lookup100.Src = &net.IPNet{IP: net.IPv4(10, 0, 1, 0).To4(), Mask: net.CIDRMask(24, 32)}
+	lookup100.Mark = 1
+	lookup100.Mask = &mask
+	lookup100.IifName = "eth0"
+	lookup100.Dport = netlink.NewRulePortRange(80, 80)
+	lookup100.Table = 100
+
+	goto200 := netlink.NewRule()
+	goto200.Priority = 150
+	goto200.Invert = true
+	goto200.Dst = &net.IPNet{IP: net.IPv4(192, 168, 0, 0).To4(), Mask: net.CIDRMask(16, 32)}
+	goto200.Goto = 200
+
+	main := netlink.NewRule()
+	main.Priority = 32766
+	main.Table = unix.RT_TABLE_MAIN
+	main.SuppressPrefixlen = 0
+
+	rules := []netlink.Rule{*lookup100, *goto200, *main}
+
+	tests := []struct {
+		name string
+		opts flags
+		want string
+	}{
+		{
+			name: "text",
+			want: "100:\tfrom 10.0.1.0/24 iif eth0 dport 80 fwmark 0x1/0xff lookup 100\n" +
+				"150:\tnot from all to 192.168.0.0/16 goto 200\n" +
+				"32766:\tfrom all lookup main suppress_prefixlength 0\n",
+		},
+		{
+			name: "json",
+			opts: flags{JSON: true},
+			want: `[{"priority":100,"src":"10.0.1.0","srclen":24,"fwmark":"0x1","fwmask":"0xff","iif":"eth0","dport":"80","table":"100"},` +
+				`{"priority":150,"not":true,"src":"all","dst":"192.168.0.0","dstlen":16,"target":200,"action":"goto"},` +
+				`{"priority":32766,"src":"all","table":"main","suppress_prefixlen":0}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := cmd{Out: &out, Opts: tt.opts}
+			if err := cmd.printRules(rules); err != nil {
+				t.Fatalf("printRules() = %v", err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("printRules() got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,7 +11,7 @@ import (
 )
 
 type Printable interface {
-	LinkJSON | []LinkJSON | VrfJSON | []VrfJSON | NeighJSON | []NeighJSON | RouteJSON | []RouteJSON | Tunnel | []Tunnel | Tuntap | []Tuntap
+	LinkJSON | []LinkJSON | VrfJSON | []VrfJSON | NeighJSON | []NeighJSON | RouteJSON | []RouteJSON | Tunnel | []Tunnel | Tuntap | []Tuntap | []RuleJSON | []NetnsJSON | []NexthopJSON
 }
 
 func printJSON[T Printable](cmd cmd, data T) error {
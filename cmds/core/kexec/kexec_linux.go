@@ -21,6 +21,7 @@
 //  -i, --initrd string        Use file as the kernel's initial ramdisk
 //  -l, --load                 Load the new kernel into the current kernel
 //  -L, --loadsyscall          Use the kexec load syscall (not file_load) (default true)
+//  -P, --load-panic           Load the new kernel as the crash kernel, booted on panic
 //      --module stringArray   Load multiboot module with command line args (e.g --module="mod arg1")
 //  -p, --purgatory string     pick a purgatory, use '-p xyz' to get a list (default "default")
 //      --reuse-cmdline        Use the kernel command line from running system
@@ -58,6 +59,7 @@ type options struct {
 	extra         string
 	initramfs     string
 	load          bool
+	loadPanic     bool
 	loadSyscall   bool
 	modules       []string
 	purgatory     string
@@ -96,6 +98,9 @@ func (o *options) parseCmdline(args []string, f *flag.FlagSet) {
 	f.StringVar(&loadFlagPath, "load", "", "Load the new kernel into the current kernel")
 	f.StringVar(&loadFlagPath, "l", "", "Load the new kernel into the current kernel (shorthand)")
 
+	f.BoolVar(&o.loadPanic, "load-panic", false, "Load the new kernel as the crash kernel, booted when the running kernel panics")
+	f.BoolVar(&o.loadPanic, "P", false, "Load the new kernel as the crash kernel, booted when the running kernel panics (shorthand)")
+
 	f.BoolVar(&o.loadSyscall, "loadsyscall", false, "Use the kexec_load syscall (not kexec_file_load)")
 	f.BoolVar(&o.loadSyscall, "L", false, "Use the kexec_load syscall (not kexec_file_load) (shorthand)")
 
@@ -191,9 +196,13 @@ func run(args []string) error {
 		return fmt.Errorf("--reuse-cmdline and other command line options are mutually exclusive")
 	}
 
+	if opts.loadPanic && opts.exec {
+		return fmt.Errorf("crash kernels cannot be executed, they are booted when the running kernel panics")
+	}
+
 	if !opts.load && !opts.exec {
 		opts.load = true
-		opts.exec = true
+		opts.exec = !opts.loadPanic
 	}
 
 	newCmdline := opts.cmdline
@@ -256,7 +265,7 @@ func run(args []string) error {
 				DTB:         dtb,
 			}
 		}
-		if err := image.Load(boot.WithVerbose(opts.debug)); err != nil {
+		if err := image.Load(boot.WithVerbose(opts.debug), boot.WithCrash(opts.loadPanic)); err != nil {
 			return err
 		}
 	}
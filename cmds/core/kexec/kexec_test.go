@@ -107,6 +107,24 @@ func TestParseCmdline(t *testing.T) {
 				kernelpath: "/path/to/kernel",
 			},
 		},
+		{
+			name: "Test load panic",
+			args: []string{"kexec", "-P", "-L", "/path/to/kernel"},
+			expected: options{
+				loadPanic:   true,
+				loadSyscall: true,
+				kernelpath:  "/path/to/kernel",
+			},
+		},
+		{
+			name: "Test long load panic",
+			args: []string{"kexec", "-l", "--load-panic", "/path/to/kernel"},
+			expected: options{
+				load:       true,
+				loadPanic:  true,
+				kernelpath: "/path/to/kernel",
+			},
+		},
 		{
 			name: "Test command line",
 			args: []string{"kexec", "-l", "-c", "${CMDLINE}", "/path/to/kernel"},
@@ -137,6 +137,7 @@ func (c *cmd) run() error {
 	if err != nil {
 		return fmt.Errorf("failed to download %v: %w", c.url, err)
 	}
+	defer reader.Close()
 
 	if err := uio.ReadIntoFile(reader, c.outputPath); err != nil {
 		return err
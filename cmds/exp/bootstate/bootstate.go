@@ -0,0 +1,115 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command bootstate shows and changes the boot attempt state used by boot
+// -state, e.g. to mark a boot good once the system is healthy.
+//
+// Synopsis:
+//
+//	bootstate [-store STORE] [show]
+//	bootstate [-store STORE] [-bls DIR] mark-good
+//	bootstate [-store STORE] next ENTRY
+//	bootstate [-store STORE] default ENTRY
+//	bootstate [-store STORE] try ENTRY TRIES
+//	bootstate [-store STORE] cancel
+//
+// Description:
+//
+//	Entries are identified by their label, as shown by the boot menu.
+//
+//	show       print the state
+//	mark-good  mark the current boot good; a trial entry becomes the default,
+//	           and the boot counter of a counted BLS entry in DIR is removed
+//	next       boot ENTRY once on the next boot
+//	default    boot ENTRY by default
+//	try        boot ENTRY at most TRIES times, until a boot is marked good
+//	cancel     stop trying the trial entry
+//
+// Options:
+//
+//	-store: grubenv:PATH, efivarfs[:GUID] or vpd:IMAGE (default grubenv:/boot/grub/grubenv)
+//	-bls:   directory of the BLS entry booted, e.g. /boot/loader/entries
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/u-root/u-root/pkg/boot/bls"
+	"github.com/u-root/u-root/pkg/boot/bootstate"
+)
+
+var errUsage = errors.New("usage: bootstate [-store STORE] [-bls DIR] [show|mark-good|next ENTRY|default ENTRY|try ENTRY TRIES|cancel]")
+
+func run(out io.Writer, s bootstate.Store, blsDir string, args []string) error {
+	st, err := bootstate.Load(s)
+	if err != nil {
+		return err
+	}
+	cmd := "show"
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+	switch {
+	case cmd == "show" && len(args) == 0:
+		fmt.Fprintf(out, "default: %q\n", st.Default)
+		fmt.Fprintf(out, "next: %q\n", st.Next)
+		fmt.Fprintf(out, "trial: %q", st.Trial)
+		if st.Trial != "" {
+			fmt.Fprintf(out, " (%d tries left", st.TriesLeft)
+			if st.Failed() {
+				fmt.Fprint(out, ", failed")
+			}
+			fmt.Fprint(out, ")")
+		}
+		fmt.Fprintf(out, "\ncurrent: %q (good: %t)\n", st.Current, st.Good)
+		if st.BLSEntry != "" {
+			fmt.Fprintf(out, "BLS entry: %q\n", st.BLSEntry)
+		}
+		return nil
+	case cmd == "mark-good" && len(args) == 0:
+		st.MarkGood()
+		if blsDir != "" && st.BLSEntry != "" {
+			if _, err := bls.MarkGood(filepath.Join(blsDir, st.BLSEntry)); err != nil {
+				return err
+			}
+			st.BLSEntry = ""
+		}
+	case cmd == "next" && len(args) == 1:
+		st.Next = args[0]
+	case cmd == "default" && len(args) == 1:
+		st.Default = args[0]
+	case cmd == "try" && len(args) == 2:
+		tries, err := strconv.Atoi(args[1])
+		if err != nil || tries < 1 {
+			return fmt.Errorf("invalid number of tries %q", args[1])
+		}
+		st.Try(args[0], tries)
+	case cmd == "cancel" && len(args) == 0:
+		st.Try("", 0)
+	default:
+		return errUsage
+	}
+	return st.Save(s)
+}
+
+func main() {
+	store := flag.String("store", "grubenv:/boot/grub/grubenv", "boot state store: grubenv:PATH, efivarfs[:GUID] or vpd:IMAGE")
+	blsDir := flag.String("bls", "", "directory of the BLS entry booted, whose boot counter mark-good removes")
+	flag.Parse()
+
+	s, err := bootstate.Open(*store)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := run(os.Stdout, s, *blsDir, flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/boot/bootstate"
+)
+
+func TestRun(t *testing.T) {
+	s := &bootstate.GrubEnv{Path: filepath.Join(t.TempDir(), "grubenv")}
+	for _, tt := range []struct {
+		args []string
+		want string
+		err  error
+	}{
+		{args: []string{"default", "old"}},
+		{args: []string{"try", "new", "3"}},
+		{args: []string{"next", "rescue"}},
+		{
+			want: "default: \"old\"\nnext: \"rescue\"\ntrial: \"new\" (3 tries left)\ncurrent: \"\" (good: false)\n",
+		},
+		{args: []string{"mark-good"}},
+		{args: []string{"cancel"}},
+		{
+			args: []string{"show"},
+			want: "default: \"old\"\nnext: \"rescue\"\ntrial: \"\"\ncurrent: \"\" (good: true)\n",
+		},
+		{args: []string{"try", "new", "0"}, err: errors.New("")},
+		{args: []string{"next"}, err: errUsage},
+		{args: []string{"reboot"}, err: errUsage},
+	} {
+		var out bytes.Buffer
+		err := run(&out, s, "", tt.args)
+		if (err != nil) != (tt.err != nil) || (errors.Is(tt.err, errUsage) && !errors.Is(err, errUsage)) {
+			t.Errorf("run(%q) = %v, want %v", tt.args, err, tt.err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("run(%q) printed %q, want %q", tt.args, out.String(), tt.want)
+		}
+	}
+}
+
+func TestMarkGoodBLS(t *testing.T) {
+	dir := t.TempDir()
+	entry := filepath.Join(dir, "new+2-1.conf")
+	if err := os.WriteFile(entry, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := &bootstate.GrubEnv{Path: filepath.Join(dir, "grubenv")}
+	st := &bootstate.State{Current: "new", BLSEntry: "new+2-1.conf"}
+	if err := st.Save(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := run(io.Discard, s, dir, []string{"mark-good"}); err != nil {
+		t.Fatalf("run(mark-good) = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.conf")); err != nil {
+		t.Errorf("entry not marked good: %v", err)
+	}
+	if st, err := bootstate.Load(s); err != nil || st.BLSEntry != "" {
+		t.Errorf("Load() = %+v, %v, want no BLS entry", st, err)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// ipmi queries the BMC through the local IPMI interface.
+//
+// Synopsis:
+//
+//	ipmi [-H host [-U user] [-P password]] [-json] sensor [list]
+//	ipmi [-H host [-U user] [-P password]] [-json] sel [list|clear]
+//
+// Description:
+//
+//	sensor list: read all sensors in the SDR repository and print, for
+//	each, its name, reading, unit, status and thresholds. Values that
+//	cannot be read are printed as na.
+//	sel list: print the decoded entries of the System Event Log.
+//	sel clear: erase all entries of the System Event Log.
+//
+// Options:
+//
+//	-H:    address of a remote BMC to query over an RMCP+ session
+//	       instead of the local interface
+//	-U:    remote user name
+//	-P:    remote password; defaults to $IPMI_PASSWORD
+//	-json: print SEL entries as JSON
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"iter"
+	"log"
+	"os"
+
+	"github.com/u-root/u-root/pkg/ipmi"
+)
+
+const usage = "ipmi [-H host [-U user] [-P password]] [-json] sensor [list] | sel [list|clear]"
+
+var errUsage = errors.New("usage: " + usage)
+
+type bmc interface {
+	io.Closer
+	GetSDRs() ([]*ipmi.SDR, error)
+	GetSensorReading(number byte) (*ipmi.SensorReading, error)
+	SELEntries() iter.Seq2[*ipmi.Event, error]
+	ClearSEL() error
+}
+
+// open opens the local IPMI interface, or a session with the BMC at host if
+// it is not empty.
+var open = func(host string, cfg ipmi.LANConfig) (bmc, error) {
+	if host != "" {
+		return ipmi.OpenLAN(host, cfg)
+	}
+	return ipmi.Open(0)
+}
+
+func run(args []string, stdout io.Writer) error {
+	f := flag.NewFlagSet("ipmi", flag.ContinueOnError)
+	f.SetOutput(io.Discard)
+	jsonOut := f.Bool("json", false, "print SEL entries as JSON")
+	host := f.String("H", "", "remote BMC address")
+	user := f.String("U", "", "remote user name")
+	password := f.String("P", os.Getenv("IPMI_PASSWORD"), "remote password")
+	if err := f.Parse(args); err != nil {
+		return errUsage
+	}
+	args = f.Args()
+	if len(args) < 1 || len(args) > 2 {
+		return errUsage
+	}
+	op := "list"
+	if len(args) == 2 {
+		op = args[1]
+	}
+
+	var cmd func(b bmc) error
+	switch {
+	case args[0] == "sensor" && op == "list":
+		cmd = func(b bmc) error { return sensorList(b, stdout) }
+	case args[0] == "sel" && op == "list":
+		cmd = func(b bmc) error { return selList(b, stdout, *jsonOut) }
+	case args[0] == "sel" && op == "clear":
+		cmd = func(b bmc) error { return b.ClearSEL() }
+	default:
+		return errUsage
+	}
+
+	b, err := open(*host, ipmi.LANConfig{Username: *user, Password: *password})
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+	return cmd(b)
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		log.Fatalf("ipmi: %v", err)
+	}
+}
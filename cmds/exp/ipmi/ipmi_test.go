@@ -0,0 +1,238 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/ipmi"
+)
+
+type fakeBMC struct {
+	sdrs     []*ipmi.SDR
+	readings map[byte]*ipmi.SensorReading
+	events   []*ipmi.Event
+	closed   bool
+}
+
+func (f *fakeBMC) GetSDRs() ([]*ipmi.SDR, error) {
+	return f.sdrs, nil
+}
+
+func (f *fakeBMC) GetSensorReading(number byte) (*ipmi.SensorReading, error) {
+	r, ok := f.readings[number]
+	if !ok {
+		return nil, ipmi.CompletionError(ipmi.IPMI_CC_REQ_DATA_NOT_PRESENT)
+	}
+	return r, nil
+}
+
+func (f *fakeBMC) SELEntries() iter.Seq2[*ipmi.Event, error] {
+	return func(yield func(*ipmi.Event, error) bool) {
+		for _, e := range f.events {
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (f *fakeBMC) ClearSEL() error {
+	f.events = nil
+	return nil
+}
+
+func (f *fakeBMC) Close() error {
+	f.closed = true
+	return nil
+}
+
+// fullRecord returns a Full Sensor Record for a temperature sensor reading
+// whole degrees, with readable upper critical threshold uc.
+func fullRecord(number byte, name string, uc byte) *ipmi.SDR {
+	b := make([]byte, 43)
+	b[0] = 0x20
+	b[2] = number
+	b[7] = 0x01 // temperature
+	b[8] = ipmi.EVENT_READING_THRESHOLD
+	mask := uint16(1) << ipmi.UPPER_CRITICAL
+	b[13] = byte(mask)
+	b[14] = byte(mask >> 8)
+	b[16] = 1 // degrees C
+	b[19] = 1 // M
+	b[32] = uc
+	b[42] = 0xc0 | byte(len(name))
+	return &ipmi.SDR{
+		SDRHeader: ipmi.SDRHeader{Type: ipmi.SDR_FULL_SENSOR, Length: byte(len(b) + len(name))},
+		Body:      append(b, name...),
+	}
+}
+
+func compactRecord(number byte, name string) *ipmi.SDR {
+	b := make([]byte, 27)
+	b[0] = 0x20
+	b[2] = number
+	b[7] = 0x08 // power supply
+	b[8] = 0x6f
+	b[26] = 0xc0 | byte(len(name))
+	return &ipmi.SDR{
+		SDRHeader: ipmi.SDRHeader{Type: ipmi.SDR_COMPACT_SENSOR, Length: byte(len(b) + len(name))},
+		Body:      append(b, name...),
+	}
+}
+
+func TestSensorList(t *testing.T) {
+	f := &fakeBMC{
+		sdrs: []*ipmi.SDR{
+			fullRecord(0x30, "CPU Temp", 95),
+			{SDRHeader: ipmi.SDRHeader{Type: ipmi.SDR_FRU_LOCATOR}},
+			fullRecord(0x31, "Inlet Temp", 45),
+			compactRecord(0x40, "PS1 Status"),
+		},
+		readings: map[byte]*ipmi.SensorReading{
+			0x30: {Raw: 42, Flags: ipmi.SENSOR_SCANNING_ENABLED},
+			0x40: {Flags: ipmi.SENSOR_SCANNING_ENABLED, States: 0x01},
+		},
+	}
+	open = func(string, ipmi.LANConfig) (bmc, error) { return f, nil }
+
+	var out bytes.Buffer
+	if err := run([]string{"sensor", "list"}, &out); err != nil {
+		t.Fatalf(`run("sensor list") = %v`, err)
+	}
+	if !f.closed {
+		t.Errorf("IPMI interface was not closed")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), out.String())
+	}
+	for i, want := range [][]string{
+		{"CPU Temp", "42.000", "degrees C", "ok", "na", "na", "na", "na", "95.000", "na"},
+		{"Inlet Temp", "na", "degrees C", "na", "na", "na", "na", "na", "45.000", "na"},
+		{"PS1 Status", "0x0001", "discrete", "0x00", "na", "na", "na", "na", "na", "na"},
+	} {
+		var got []string
+		for _, field := range strings.Split(lines[i], "|") {
+			got = append(got, strings.TrimSpace(field))
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("line %d = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	open = func(string, ipmi.LANConfig) (bmc, error) { return &fakeBMC{}, nil }
+	for _, args := range [][]string{
+		nil,
+		{"chassis"},
+		{"sensor", "get"},
+		{"sensor", "list", "extra"},
+		{"sel", "delete"},
+		{"-x", "sel"},
+	} {
+		if err := run(args, &bytes.Buffer{}); !errors.Is(err, errUsage) {
+			t.Errorf("run(%q) = %v, want %v", args, err, errUsage)
+		}
+	}
+}
+
+func TestSEL(t *testing.T) {
+	f := &fakeBMC{
+		events: []*ipmi.Event{
+			{
+				RecordID:   1,
+				RecordType: ipmi.SEL_SYSTEM_EVENT_RECORD,
+				StandardEvent: ipmi.StandardEvent{
+					Timestamp:    1790000000,
+					GenID:        0x20,
+					SensorType:   0x01,
+					SensorNum:    0x30,
+					EventTypeDir: ipmi.EVENT_READING_THRESHOLD,
+					EventData:    [3]uint8{0x59, 96, 95},
+				},
+			},
+			{
+				RecordID:   2,
+				RecordType: ipmi.SEL_SYSTEM_EVENT_RECORD,
+				StandardEvent: ipmi.StandardEvent{
+					Timestamp:    0x100,
+					SensorType:   0x0C,
+					SensorNum:    0x60,
+					EventTypeDir: 0x80 | ipmi.EVENT_READING_SENSOR_SPECIFIC,
+					EventData:    [3]uint8{0x01},
+				},
+			},
+			{
+				RecordID:   3,
+				RecordType: 0xC1,
+				OEMTsEvent: ipmi.OEMTsEvent{
+					Timestamp:        1790000001,
+					ManfID:           [3]uint8{0x57, 0x01},
+					OEMTsDefinedData: [6]uint8{1, 2, 3, 4, 5, 6},
+				},
+			},
+		},
+	}
+	open = func(string, ipmi.LANConfig) (bmc, error) { return f, nil }
+
+	var out bytes.Buffer
+	if err := run([]string{"sel"}, &out); err != nil {
+		t.Fatalf(`run("sel") = %v`, err)
+	}
+	want := `   1 | 2026-09-21 14:13:20 | Temperature #0x30 | Upper Critical going high | Asserted
+   2 | Pre-Init 0x00000100 | Memory #0x60 | Uncorrectable ECC | Deasserted
+   3 | 2026-09-21 14:13:21 | OEM timestamped | manufacturer 0x000157 | 01 02 03 04 05 06
+`
+	if out.String() != want {
+		t.Errorf(`run("sel") = %q, want %q`, out.String(), want)
+	}
+
+	out.Reset()
+	if err := run([]string{"-json", "sel", "list"}, &out); err != nil {
+		t.Fatalf(`run("-json sel list") = %v`, err)
+	}
+	var records []ipmi.SELRecord
+	if err := json.Unmarshal(out.Bytes(), &records); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out.String())
+	}
+	if len(records) != 3 || records[0].Description != "Upper Critical going high" || records[1].Asserted || records[2].ManufacturerID != 0x157 {
+		t.Errorf(`run("-json sel list") = %+v`, records)
+	}
+
+	if err := run([]string{"sel", "clear"}, &out); err != nil {
+		t.Fatalf(`run("sel clear") = %v`, err)
+	}
+	out.Reset()
+	if err := run([]string{"-json", "sel"}, &out); err != nil {
+		t.Fatalf(`run("-json sel") = %v`, err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "[]" {
+		t.Errorf(`run("-json sel") after clearing = %q, want "[]"`, got)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	t.Setenv("IPMI_PASSWORD", "hunter2")
+	var host string
+	var cfg ipmi.LANConfig
+	open = func(h string, c ipmi.LANConfig) (bmc, error) {
+		host, cfg = h, c
+		return &fakeBMC{}, nil
+	}
+	if err := run([]string{"-H", "bmc.example.com", "-U", "admin", "sel"}, &bytes.Buffer{}); err != nil {
+		t.Fatalf(`run("-H bmc.example.com -U admin sel") = %v`, err)
+	}
+	if host != "bmc.example.com" || cfg.Username != "admin" || cfg.Password != "hunter2" {
+		t.Errorf("opened %q with %+v, want bmc.example.com with admin and $IPMI_PASSWORD", host, cfg)
+	}
+}
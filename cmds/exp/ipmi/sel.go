@@ -0,0 +1,58 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/u-root/u-root/pkg/ipmi"
+)
+
+func selList(b bmc, w io.Writer, jsonOut bool) error {
+	// Print an empty list rather than null.
+	records := []*ipmi.SELRecord{}
+	for e, err := range b.SELEntries() {
+		if err != nil {
+			return err
+		}
+		r := e.Decode()
+		if !jsonOut {
+			fmt.Fprintln(w, selLine(r))
+			continue
+		}
+		records = append(records, r)
+	}
+	if !jsonOut {
+		return nil
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// selLine formats a decoded SEL entry the way ipmitool sel elist does.
+func selLine(r *ipmi.SELRecord) string {
+	var when string
+	switch {
+	case !r.Time.IsZero():
+		when = r.Time.Format(time.DateTime)
+	case r.Timestamp != 0:
+		when = fmt.Sprintf("Pre-Init 0x%08x", r.Timestamp)
+	default:
+		when = "-"
+	}
+
+	if r.SensorType == "" {
+		return fmt.Sprintf("%4x | %-19s | %s | manufacturer 0x%06x | % x", r.RecordID, when, r.RecordType, r.ManufacturerID, r.OEMData)
+	}
+	dir := "Asserted"
+	if !r.Asserted {
+		dir = "Deasserted"
+	}
+	return fmt.Sprintf("%4x | %-19s | %s #0x%02x | %s | %s", r.RecordID, when, r.SensorType, r.SensorNumber, r.Description, dir)
+}
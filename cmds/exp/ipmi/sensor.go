@@ -0,0 +1,83 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/u-root/u-root/pkg/ipmi"
+)
+
+// bmcOwner is the slave address of the BMC, the only owner whose sensors
+// can be read through the system interface.
+const bmcOwner = 0x20
+
+var thresholds = []ipmi.Threshold{
+	ipmi.LOWER_NON_RECOVERABLE,
+	ipmi.LOWER_CRITICAL,
+	ipmi.LOWER_NON_CRITICAL,
+	ipmi.UPPER_NON_CRITICAL,
+	ipmi.UPPER_CRITICAL,
+	ipmi.UPPER_NON_RECOVERABLE,
+}
+
+func sensorList(r bmc, w io.Writer) error {
+	sdrs, err := r.GetSDRs()
+	if err != nil {
+		return err
+	}
+	for _, sdr := range sdrs {
+		if sdr.Type != ipmi.SDR_FULL_SENSOR && sdr.Type != ipmi.SDR_COMPACT_SENSOR {
+			continue
+		}
+		s, err := ipmi.ParseSensorRecord(sdr)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, sensorLine(r, s))
+	}
+	return nil
+}
+
+// sensorLine formats a sensor the way ipmitool sensor list does.
+func sensorLine(r bmc, s *ipmi.SensorRecord) string {
+	value, unit, status := "na", "discrete", "na"
+	if s.IsThreshold() {
+		unit = s.Unit()
+	}
+
+	var reading *ipmi.SensorReading
+	if s.OwnerID == bmcOwner && s.OwnerLUN == 0 {
+		var err error
+		if reading, err = r.GetSensorReading(s.Number); err != nil || !reading.Available() {
+			reading = nil
+		}
+	}
+	switch {
+	case reading == nil:
+	case s.IsThreshold():
+		if v, err := s.Convert(reading.Raw); err == nil {
+			value = fmt.Sprintf("%.3f", v)
+			status = reading.Status.String()
+		}
+	default:
+		value = fmt.Sprintf("0x%04x", reading.States)
+		status = "0x00"
+	}
+
+	fields := []string{fmt.Sprintf("%-16s", s.Name), fmt.Sprintf("%-10s", value), fmt.Sprintf("%-10s", unit), fmt.Sprintf("%-5s", status)}
+	for _, t := range thresholds {
+		th := "na"
+		if s.IsThreshold() {
+			if v, ok := s.Threshold(t); ok {
+				th = fmt.Sprintf("%.3f", v)
+			}
+		}
+		fields = append(fields, fmt.Sprintf("%-10s", th))
+	}
+	return strings.TrimRight(strings.Join(fields, " | "), " ")
+}
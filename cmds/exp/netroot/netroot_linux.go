@@ -0,0 +1,72 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command netroot mounts the NFS or iSCSI root file system given on the
+// kernel command line and switches into it.
+//
+// Synopsis:
+//
+//	netroot [-dir DIR] [-no-switch]
+//
+// Description:
+//
+//	The network is configured as given by ip=, e.g. ip=dhcp or
+//	ip=10.0.0.5::10.0.0.254:255.255.255.0:node5:eth0:off, and DHCP is used
+//	if it is not given.
+//
+//	The root is one of
+//
+//	  root=/dev/nfs nfsroot=[SERVER:]PATH[,OPTIONS]
+//	  root=nfs:[SERVER:]PATH[:OPTIONS], or nfs4:
+//	  netroot=iscsi:SERVER:PROTOCOL:PORT:LUN:TARGET [root=DEVICE]
+//	  root=dhcp, to use the root path of the DHCP lease
+//
+//	The iSCSI initiator name is given by rd.iscsi.initiator=. Once the root
+//	is mounted, init= or /sbin/init is run in it.
+//
+// Options:
+//
+//	-dir:       where to mount the root (default /newroot)
+//	-no-switch: mount the root, but do not switch into it
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/u-root/u-root/pkg/cmdline"
+	"github.com/u-root/u-root/pkg/mount"
+	"github.com/u-root/u-root/pkg/netroot"
+)
+
+var (
+	dir      = flag.String("dir", "/newroot", "where to mount the root")
+	noSwitch = flag.Bool("no-switch", false, "mount the root, but do not switch into it")
+)
+
+func main() {
+	flag.Parse()
+
+	c, err := netroot.FromCmdline(cmdline.NewCmdLine())
+	if err != nil {
+		log.Fatal(err)
+	}
+	lease, err := netroot.ConfigureNetwork(context.Background(), c.IP)
+	if err != nil {
+		log.Fatalf("Could not configure network: %v", err)
+	}
+	if lease != nil {
+		log.Printf("Configured %s", lease)
+	}
+	if _, err := c.Mount(lease, *dir); err != nil {
+		log.Fatalf("Could not mount root: %v", err)
+	}
+	if *noSwitch {
+		return
+	}
+	if err := mount.SwitchRoot(*dir, c.Init); err != nil {
+		log.Fatalf("switch_root failed: %v", err)
+	}
+}
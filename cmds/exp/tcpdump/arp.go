@@ -0,0 +1,74 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/gopacket/gopacket/layers"
+)
+
+var arpOperations = map[uint16]string{
+	layers.ARPRequest: "Request",
+	layers.ARPReply:   "Reply",
+	3:                 "Reverse Request",
+	4:                 "Reverse Reply",
+	8:                 "InArp Request",
+	9:                 "InArp Reply",
+}
+
+// arpData returns a string representation of the ARP layer.
+func arpData(layer *layers.ARP, verbose bool) string {
+	var data string
+
+	if verbose {
+		data = fmt.Sprintf("%s (len %d), %s (len %d), ", layer.AddrType, layer.HwAddressSize, layer.Protocol, layer.ProtAddressSize)
+	}
+
+	op, ok := arpOperations[layer.Operation]
+	if !ok {
+		op = fmt.Sprintf("Unknown (%d)", layer.Operation)
+	}
+	data += op
+
+	length := len(layer.Contents) + len(layer.Payload)
+
+	if layer.Protocol != layers.EthernetTypeIPv4 || layer.ProtAddressSize != net.IPv4len {
+		return fmt.Sprintf("%s, length %d", data, length)
+	}
+
+	srcHw, dstHw := net.HardwareAddr(layer.SourceHwAddress), net.HardwareAddr(layer.DstHwAddress)
+	srcIP, dstIP := net.IP(layer.SourceProtAddress), net.IP(layer.DstProtAddress)
+
+	switch layer.Operation {
+	case layers.ARPRequest:
+		data += fmt.Sprintf(" who-has %s", dstIP)
+		if !isZero(dstHw) {
+			data += fmt.Sprintf(" (%s)", dstHw)
+		}
+		data += fmt.Sprintf(" tell %s", srcIP)
+	case layers.ARPReply:
+		data += fmt.Sprintf(" %s is-at %s", srcIP, srcHw)
+	case 3:
+		data += fmt.Sprintf(" who-is %s tell %s", dstHw, srcHw)
+	case 4:
+		data += fmt.Sprintf(" %s at %s", dstHw, dstIP)
+	}
+
+	return fmt.Sprintf("%s, length %d", data, length)
+}
+
+// isZero reports whether b only holds zeros.
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+)
+
+func TestArpData(t *testing.T) {
+	request := []byte{
+		0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01,
+		0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0xc0, 0xa8, 0x00, 0x68,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0xa8, 0x00, 0x01,
+	}
+	reply := []byte{
+		0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x02,
+		0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xc0, 0xa8, 0x00, 0x01,
+		0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0xc0, 0xa8, 0x00, 0x68,
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		verbose  bool
+		expected string
+	}{
+		{
+			name:     "Request",
+			data:     request,
+			expected: "Request who-has 192.168.0.1 tell 192.168.0.104, length 28",
+		},
+		{
+			name:     "Request verbose",
+			data:     request,
+			verbose:  true,
+			expected: "Ethernet (len 6), IPv4 (len 4), Request who-has 192.168.0.1 tell 192.168.0.104, length 28",
+		},
+		{
+			name:     "Reply",
+			data:     reply,
+			expected: "Reply 192.168.0.1 is-at 66:77:88:99:aa:bb, length 28",
+		},
+		{
+			name: "Reverse request",
+			data: []byte{
+				0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x03,
+				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x00, 0x00, 0x00, 0x00,
+			},
+			expected: "Reverse Request who-is 00:11:22:33:44:55 tell 00:11:22:33:44:55, length 28",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet := gopacket.NewPacket(tt.data, layers.LayerTypeARP, gopacket.Default)
+			layer := packet.Layer(layers.LayerTypeARP).(*layers.ARP)
+
+			if got := arpData(layer, tt.verbose); got != tt.expected {
+				t.Errorf("arpData() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,502 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gopacket/gopacket/layers"
+)
+
+// dhcpOption describes how a DHCP option is printed. The formats are the
+// ones of tcpdump's BOOTP printer:
+//
+//	i - IPv4 addresses
+//	p - pairs of IPv4 addresses
+//	l - signed 32 bit integers
+//	s - unsigned 16 bit integers
+//	b - period-separated decimal bytes
+//	a - ASCII string
+//	B - on/off bytes
+//	$ - decoded by dhcpSpecialOption
+//
+// Options that do not match their format are printed as hex bytes.
+type dhcpOption struct {
+	name   string
+	format byte
+}
+
+var dhcpOptions = map[layers.DHCPOpt]dhcpOption{
+	1:   {"Subnet-Mask", 'i'},
+	2:   {"Time-Zone", 'l'},
+	3:   {"Default-Gateway", 'i'},
+	4:   {"Time-Server", 'i'},
+	5:   {"IEN-Name-Server", 'i'},
+	6:   {"Domain-Name-Server", 'i'},
+	7:   {"LOG", 'i'},
+	8:   {"CS", 'i'},
+	9:   {"LPR-Server", 'i'},
+	10:  {"IM", 'i'},
+	11:  {"RL", 'i'},
+	12:  {"Hostname", 'a'},
+	13:  {"BS", 's'},
+	14:  {"MA", 'a'},
+	15:  {"Domain-Name", 'a'},
+	16:  {"SS", 'i'},
+	17:  {"RP", 'a'},
+	18:  {"EP", 'a'},
+	19:  {"IPF", 'B'},
+	20:  {"SRT", 'B'},
+	21:  {"PF", 'p'},
+	22:  {"RSZ", 's'},
+	23:  {"TTL", 'b'},
+	24:  {"MTU-Timeout", 'l'},
+	25:  {"MTU-Table", 's'},
+	26:  {"MTU", 's'},
+	27:  {"LSN", 'B'},
+	28:  {"BR", 'i'},
+	29:  {"MD", 'B'},
+	30:  {"MS", 'B'},
+	31:  {"RD", 'B'},
+	32:  {"RSA", 'i'},
+	33:  {"Static-Route", 'p'},
+	34:  {"UT", 'B'},
+	35:  {"AT", 'l'},
+	36:  {"IE", 'B'},
+	37:  {"TT", 'b'},
+	38:  {"KI", 'l'},
+	39:  {"KG", 'B'},
+	40:  {"YD", 'a'},
+	41:  {"YS", 'i'},
+	42:  {"NTP", 'i'},
+	43:  {"VO", 'b'},
+	44:  {"Netbios-Name-Server", 'i'},
+	45:  {"WDD", 'i'},
+	46:  {"Netbios-Node", '$'},
+	47:  {"Netbios-Scope", 'a'},
+	48:  {"XFS", 'i'},
+	49:  {"XDM", 'i'},
+	50:  {"Requested-IP", 'i'},
+	51:  {"Lease-Time", 'l'},
+	52:  {"OO", '$'},
+	53:  {"DHCP-Message", '$'},
+	54:  {"Server-ID", 'i'},
+	55:  {"Parameter-Request", '$'},
+	56:  {"MSG", 'a'},
+	57:  {"MSZ", 's'},
+	58:  {"RN", 'l'},
+	59:  {"RB", 'l'},
+	60:  {"Vendor-Class", 'a'},
+	61:  {"Client-ID", '$'},
+	66:  {"TFTP", 'a'},
+	67:  {"BF", 'a'},
+	77:  {"CLASS", 'a'},
+	81:  {"FQDN", '$'},
+	82:  {"Agent-Information", '$'},
+	93:  {"ARCH", 's'},
+	94:  {"NDI", 'b'},
+	97:  {"GUID", 'b'},
+	119: {"Domain-Search", '$'},
+	121: {"Classless-Static-Route", '$'},
+}
+
+var dhcpMessageTypes = map[byte]string{
+	1:  "Discover",
+	2:  "Offer",
+	3:  "Request",
+	4:  "Decline",
+	5:  "ACK",
+	6:  "NACK",
+	7:  "Release",
+	8:  "Inform",
+	9:  "Force Renew",
+	10: "Lease Query",
+	11: "Lease Unassigned",
+	12: "Lease Unknown",
+	13: "Lease Active",
+}
+
+var dhcpAgentSubOptions = map[byte]string{
+	1: "Circuit-ID",
+	2: "Remote-ID",
+	6: "Subscriber-ID",
+}
+
+// dhcpData returns a string representation of the DHCPv4 layer.
+func dhcpData(layer *layers.DHCPv4, verbose bool) string {
+	var b strings.Builder
+
+	switch layer.Operation {
+	case layers.DHCPOpRequest:
+		b.WriteString("BOOTP/DHCP, Request")
+		if layer.HardwareType == layers.LinkTypeEthernet && layer.HardwareLen == 6 {
+			fmt.Fprintf(&b, " from %s", layer.ClientHWAddr)
+		}
+	case layers.DHCPOpReply:
+		b.WriteString("BOOTP/DHCP, Reply")
+	default:
+		fmt.Fprintf(&b, "BOOTP/DHCP, unknown (0x%02x)", byte(layer.Operation))
+	}
+
+	fmt.Fprintf(&b, ", length %d", len(layer.Contents))
+
+	if !verbose {
+		return b.String()
+	}
+
+	if layer.RelayHops != 0 {
+		fmt.Fprintf(&b, ", hops %d", layer.RelayHops)
+	}
+	if layer.Xid != 0 {
+		fmt.Fprintf(&b, ", xid 0x%x", layer.Xid)
+	}
+	if layer.Secs != 0 {
+		fmt.Fprintf(&b, ", secs %d", layer.Secs)
+	}
+
+	flags := "none"
+	if layer.Flags&0x8000 != 0 {
+		flags = "Broadcast"
+	}
+	fmt.Fprintf(&b, ", Flags [%s]", flags)
+
+	for _, addr := range []struct {
+		name string
+		ip   net.IP
+	}{
+		{"Client-IP", layer.ClientIP},
+		{"Your-IP", layer.YourClientIP},
+		{"Server-IP", layer.NextServerIP},
+		{"Gateway-IP", layer.RelayAgentIP},
+	} {
+		if addr.ip != nil && !addr.ip.IsUnspecified() {
+			fmt.Fprintf(&b, "\n\t  %s %s", addr.name, addr.ip)
+		}
+	}
+
+	if layer.HardwareType == layers.LinkTypeEthernet && layer.HardwareLen == 6 {
+		fmt.Fprintf(&b, "\n\t  Client-Ethernet-Address %s", layer.ClientHWAddr)
+	}
+
+	if name := cString(layer.ServerName); name != "" {
+		fmt.Fprintf(&b, "\n\t  sname %q", name)
+	}
+	if file := cString(layer.File); file != "" {
+		fmt.Fprintf(&b, "\n\t  file %q", file)
+	}
+
+	b.WriteString("\n\t  Vendor-rfc1048 Extensions")
+	fmt.Fprintf(&b, "\n\t    Magic Cookie 0x%08x", layers.DHCPMagic)
+
+	for _, opt := range layer.Options {
+		if opt.Type == layers.DHCPOptPad {
+			continue
+		}
+
+		info, ok := dhcpOptions[opt.Type]
+		if !ok {
+			info = dhcpOption{name: fmt.Sprintf("Option %d", opt.Type)}
+			// Guess the format from the size, like tcpdump.
+			switch {
+			case len(opt.Data)%2 == 1:
+				info.format = 'b'
+			case len(opt.Data)%4 == 2:
+				info.format = 's'
+			default:
+				info.format = 'l'
+			}
+		}
+
+		fmt.Fprintf(&b, "\n\t    %s (%d), length %d", info.name, opt.Type, len(opt.Data))
+		if len(opt.Data) > 0 {
+			fmt.Fprintf(&b, ": %s", dhcpOptionValue(opt, info.format))
+		}
+	}
+	b.WriteString("\n\t    END (255), length 0")
+
+	return b.String()
+}
+
+// dhcpOptionValue returns the value of a DHCP option in the given format.
+func dhcpOptionValue(opt layers.DHCPOption, format byte) string {
+	data := opt.Data
+
+	switch format {
+	case 'i', 'p':
+		if len(data)%net.IPv4len != 0 {
+			break
+		}
+		var addrs []string
+		for i := 0; i < len(data); i += net.IPv4len {
+			addr := net.IP(data[i : i+net.IPv4len]).String()
+			if format == 'p' && i%(2*net.IPv4len) != 0 {
+				addrs[len(addrs)-1] += ":" + addr
+				continue
+			}
+			addrs = append(addrs, addr)
+		}
+		return strings.Join(addrs, ",")
+	case 'l':
+		if len(data)%4 != 0 {
+			break
+		}
+		var values []string
+		for i := 0; i < len(data); i += 4 {
+			values = append(values, fmt.Sprint(int32(binary.BigEndian.Uint32(data[i:]))))
+		}
+		return strings.Join(values, ",")
+	case 's':
+		if len(data)%2 != 0 {
+			break
+		}
+		var values []string
+		for i := 0; i < len(data); i += 2 {
+			values = append(values, fmt.Sprint(binary.BigEndian.Uint16(data[i:])))
+		}
+		return strings.Join(values, ",")
+	case 'b':
+		values := make([]string, len(data))
+		for i, v := range data {
+			values[i] = fmt.Sprint(v)
+		}
+		return strings.Join(values, ".")
+	case 'a':
+		return fmt.Sprintf("%q", string(data))
+	case 'B':
+		values := make([]string, len(data))
+		for i, v := range data {
+			switch v {
+			case 0:
+				values[i] = "N"
+			case 1:
+				values[i] = "Y"
+			default:
+				values[i] = fmt.Sprintf("%d?", v)
+			}
+		}
+		return strings.Join(values, ",")
+	case '$':
+		if s, ok := dhcpSpecialOption(opt); ok {
+			return s
+		}
+	}
+
+	return hexBytes(data)
+}
+
+// dhcpSpecialOption decodes the options that have their own format. It
+// returns false if the option is malformed.
+func dhcpSpecialOption(opt layers.DHCPOption) (string, bool) {
+	data := opt.Data
+
+	switch opt.Type {
+	case layers.DHCPOptMessageType:
+		if len(data) != 1 {
+			return "", false
+		}
+		if name, ok := dhcpMessageTypes[data[0]]; ok {
+			return name, true
+		}
+		return fmt.Sprintf("Unknown (%d)", data[0]), true
+
+	case layers.DHCPOptParamsRequest:
+		var s strings.Builder
+		for i, code := range data {
+			if i%4 == 0 {
+				s.WriteString("\n\t      ")
+			} else {
+				s.WriteString(", ")
+			}
+			name := fmt.Sprintf("Option %d", code)
+			if info, ok := dhcpOptions[layers.DHCPOpt(code)]; ok {
+				name = info.name
+			}
+			fmt.Fprintf(&s, "%s (%d)", name, code)
+		}
+		return s.String(), true
+
+	case layers.DHCPOptClientID:
+		if len(data) < 2 {
+			return "", false
+		}
+		if data[0] == 0 {
+			return fmt.Sprintf("%q", string(data[1:])), true
+		}
+		if data[0] == byte(layers.LinkTypeEthernet) {
+			return "ether " + net.HardwareAddr(data[1:]).String(), true
+		}
+		return fmt.Sprintf("hardware-type %d, %s", data[0], net.HardwareAddr(data[1:])), true
+
+	case layers.DHCPOptNETBIOSTCPNodeType:
+		if len(data) != 1 {
+			return "", false
+		}
+		switch data[0] {
+		case 1:
+			return "b-node", true
+		case 2:
+			return "p-node", true
+		case 4:
+			return "m-node", true
+		case 8:
+			return "h-node", true
+		}
+		return fmt.Sprintf("unknown (0x%02x)", data[0]), true
+
+	case layers.DHCPOptExtOptions:
+		if len(data) != 1 {
+			return "", false
+		}
+		switch data[0] {
+		case 1:
+			return "file", true
+		case 2:
+			return "sname", true
+		case 3:
+			return "file+sname", true
+		}
+		return fmt.Sprintf("unknown (%d)", data[0]), true
+
+	case 81: // Client FQDN, RFC 4702.
+		if len(data) < 3 {
+			return "", false
+		}
+		var s string
+		if data[0]&0x0f != 0 {
+			s = "["
+			for bit, flag := range "SOEN" {
+				if data[0]&(1<<bit) != 0 {
+					s += string(flag)
+				}
+			}
+			s += "] "
+		}
+		if data[1] != 0 || data[2] != 0 {
+			s += fmt.Sprintf("%d/%d ", data[1], data[2])
+		}
+		return s + fmt.Sprintf("%q", string(data[3:])), true
+
+	case 82: // Relay agent information, RFC 3046.
+		var s strings.Builder
+		for len(data) >= 2 {
+			code, n := data[0], int(data[1])
+			if n > len(data)-2 {
+				return "", false
+			}
+			name, ok := dhcpAgentSubOptions[code]
+			if !ok {
+				name = "Unknown"
+			}
+			fmt.Fprintf(&s, "\n\t      %s SubOption %d, length %d: ", name, code, n)
+			if ok {
+				s.Write(data[2 : 2+n])
+			} else {
+				s.WriteString(hexBytes(data[2 : 2+n]))
+			}
+			data = data[2+n:]
+		}
+		return s.String(), true
+
+	case layers.DHCPOptDomainSearch:
+		names, err := dnsNames(data)
+		if err != nil {
+			return "", false
+		}
+		return strings.Join(names, ", "), true
+
+	case layers.DHCPOptClasslessStaticRoute:
+		var routes []string
+		for len(data) > 0 {
+			width := int(data[0])
+			n := (width + 7) / 8
+			if width > 32 || len(data) < 1+n+net.IPv4len {
+				return "", false
+			}
+			dst := "default"
+			if width > 0 {
+				prefix := make(net.IP, net.IPv4len)
+				copy(prefix, data[1:1+n])
+				dst = fmt.Sprintf("%s/%d", prefix, width)
+			}
+			routes = append(routes, fmt.Sprintf("(%s:%s)", dst, net.IP(data[1+n:1+n+net.IPv4len])))
+			data = data[1+n+net.IPv4len:]
+		}
+		return strings.Join(routes, ","), true
+	}
+
+	return "", false
+}
+
+// dnsNames decodes a list of uncompressed DNS names, as used in the domain
+// search option. Compression pointers are followed within data.
+func dnsNames(data []byte) ([]string, error) {
+	var names []string
+	for off := 0; off < len(data); {
+		name, next, err := dnsName(data, off)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		off = next
+	}
+	return names, nil
+}
+
+// dnsName decodes the DNS name at off and returns it together with the
+// offset of the data following it.
+func dnsName(data []byte, off int) (string, int, error) {
+	var (
+		labels []string
+		next   = -1
+	)
+	for hops := 0; ; hops++ {
+		if off >= len(data) || hops > len(data) {
+			return "", 0, fmt.Errorf("malformed DNS name")
+		}
+		n := int(data[off])
+		switch {
+		case n == 0:
+			if next < 0 {
+				next = off + 1
+			}
+			return strings.Join(labels, ".") + ".", next, nil
+		case n&0xc0 == 0xc0:
+			if off+1 >= len(data) {
+				return "", 0, fmt.Errorf("malformed DNS name")
+			}
+			if next < 0 {
+				next = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(data[off:]) & 0x3fff)
+		default:
+			if off+1+n > len(data) {
+				return "", 0, fmt.Errorf("malformed DNS name")
+			}
+			labels = append(labels, string(data[off+1:off+1+n]))
+			off += 1 + n
+		}
+	}
+}
+
+// cString returns the NUL-terminated string at the start of b.
+func cString(b []byte) string {
+	if i := strings.IndexByte(string(b), 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// hexBytes returns b as colon-separated hex bytes.
+func hexBytes(b []byte) string {
+	values := make([]string, len(b))
+	for i, v := range b {
+		values[i] = fmt.Sprintf("%02x", v)
+	}
+	return strings.Join(values, ":")
+}
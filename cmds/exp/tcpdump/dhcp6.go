@@ -0,0 +1,278 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gopacket/gopacket/layers"
+)
+
+var dhcp6MessageTypes = map[byte]string{
+	1:  "solicit",
+	2:  "advertise",
+	3:  "request",
+	4:  "confirm",
+	5:  "renew",
+	6:  "rebind",
+	7:  "reply",
+	8:  "release",
+	9:  "decline",
+	10: "reconfigure",
+	11: "inf-req",
+	12: "relay-fwd",
+	13: "relay-reply",
+	14: "leasequery",
+	15: "leasequery-reply",
+}
+
+var dhcp6OptionNames = map[uint16]string{
+	1:  "client-ID",
+	2:  "server-ID",
+	3:  "IA_NA",
+	4:  "IA_TA",
+	5:  "IA_ADDR",
+	6:  "option-request",
+	7:  "preference",
+	8:  "elapsed-time",
+	9:  "relay-message",
+	11: "authentication",
+	12: "server-unicast",
+	13: "status-code",
+	14: "rapid-commit",
+	15: "user-class",
+	16: "vendor-class",
+	17: "vendor-specific-info",
+	18: "interface-ID",
+	19: "reconfigure-message",
+	20: "reconfigure-accept",
+	21: "SIP-servers-domain",
+	22: "SIP-servers-address",
+	23: "DNS-server",
+	24: "DNS-search-list",
+	25: "IA_PD",
+	26: "IA_PD-prefix",
+	27: "NIS-server",
+	28: "NIS+-server",
+	29: "NIS-domain-name",
+	30: "NIS+-domain-name",
+	31: "SNTP-servers",
+	32: "info-refresh-time",
+	39: "client-FQDN",
+	56: "NTP-server",
+	59: "bootfile-URL",
+}
+
+var dhcp6StatusCodes = map[uint16]string{
+	0: "Success",
+	1: "Unspecified failure",
+	2: "No addresses",
+	3: "No binding",
+	4: "Not on-link",
+	5: "Use multicast",
+	6: "No prefix available",
+}
+
+// dhcp6Data returns a string representation of the DHCPv6 layer.
+func dhcp6Data(layer *layers.DHCPv6, verbose bool) string {
+	return dhcp6Message(layer.Contents, verbose)
+}
+
+// dhcp6Message returns a string representation of a DHCPv6 message. It is
+// called recursively for messages carried in relay-message options.
+func dhcp6Message(data []byte, verbose bool) string {
+	if len(data) < 4 {
+		return "dhcp6 [|dhcp6]"
+	}
+
+	name, ok := dhcp6MessageTypes[data[0]]
+	if !ok {
+		name = fmt.Sprintf("msgtype-%d", data[0])
+	}
+
+	if !verbose {
+		return "dhcp6 " + name
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "dhcp6 %s (", name)
+
+	switch data[0] {
+	case 12, 13:
+		if len(data) < 34 {
+			b.WriteString("[|dhcp6])")
+			return b.String()
+		}
+		fmt.Fprintf(&b, "linkaddr=%s peeraddr=%s", net.IP(data[2:18]), net.IP(data[18:34]))
+		data = data[34:]
+	default:
+		fmt.Fprintf(&b, "xid=%x", uint32(data[1])<<16|uint32(binary.BigEndian.Uint16(data[2:])))
+		data = data[4:]
+	}
+
+	dhcp6Options(&b, data, verbose)
+	b.WriteString(")")
+
+	return b.String()
+}
+
+// dhcp6Options writes the options in data to b, each as " (name value)".
+func dhcp6Options(b *strings.Builder, data []byte, verbose bool) {
+	for len(data) > 0 {
+		if len(data) < 4 {
+			b.WriteString(" [|dhcp6ext]")
+			return
+		}
+
+		code, n := binary.BigEndian.Uint16(data), int(binary.BigEndian.Uint16(data[2:]))
+		if len(data) < 4+n {
+			b.WriteString(" [|dhcp6ext]")
+			return
+		}
+		opt := data[4 : 4+n]
+		data = data[4+n:]
+
+		name, ok := dhcp6OptionNames[code]
+		if !ok {
+			name = fmt.Sprintf("opt_%d", code)
+		}
+		fmt.Fprintf(b, " (%s", name)
+		dhcp6OptionValue(b, code, opt, verbose)
+		b.WriteString(")")
+	}
+}
+
+// dhcp6OptionValue writes the value of a DHCPv6 option to b. Options that are
+// not decoded only show their name, like in tcpdump.
+func dhcp6OptionValue(b *strings.Builder, code uint16, opt []byte, verbose bool) {
+	switch code {
+	case 1, 2: // client-ID, server-ID
+		if len(opt) < 2 {
+			b.WriteString(" ?")
+			return
+		}
+		switch duid := binary.BigEndian.Uint16(opt); {
+		case duid == 1 && len(opt) >= 8:
+			fmt.Fprintf(b, " hwaddr/time type %d time %d %x", binary.BigEndian.Uint16(opt[2:]), binary.BigEndian.Uint32(opt[4:]), opt[8:])
+		case duid == 2 && len(opt) >= 6:
+			fmt.Fprintf(b, " vid %x", opt[2:])
+		case duid == 3 && len(opt) >= 4:
+			fmt.Fprintf(b, " hwaddr type %d %x", binary.BigEndian.Uint16(opt[2:]), opt[4:])
+		case duid == 4:
+			fmt.Fprintf(b, " uuid %x", opt[2:])
+		default:
+			fmt.Fprintf(b, " type %d", duid)
+		}
+
+	case 3, 25: // IA_NA, IA_PD
+		if len(opt) < 12 {
+			b.WriteString(" ?")
+			return
+		}
+		fmt.Fprintf(b, " IAID:%d T1:%d T2:%d", binary.BigEndian.Uint32(opt), binary.BigEndian.Uint32(opt[4:]), binary.BigEndian.Uint32(opt[8:]))
+		dhcp6Options(b, opt[12:], verbose)
+
+	case 4: // IA_TA
+		if len(opt) < 4 {
+			b.WriteString(" ?")
+			return
+		}
+		fmt.Fprintf(b, " IAID:%d", binary.BigEndian.Uint32(opt))
+		dhcp6Options(b, opt[4:], verbose)
+
+	case 5: // IA_ADDR
+		if len(opt) < 24 {
+			b.WriteString(" ?")
+			return
+		}
+		fmt.Fprintf(b, " %s pltime:%d vltime:%d", net.IP(opt[:16]), binary.BigEndian.Uint32(opt[16:]), binary.BigEndian.Uint32(opt[20:]))
+		dhcp6Options(b, opt[24:], verbose)
+
+	case 26: // IA_PD-prefix
+		if len(opt) < 25 {
+			b.WriteString(" ?")
+			return
+		}
+		fmt.Fprintf(b, " %s/%d pltime:%d vltime:%d", net.IP(opt[9:25]), opt[8], binary.BigEndian.Uint32(opt), binary.BigEndian.Uint32(opt[4:]))
+		dhcp6Options(b, opt[25:], verbose)
+
+	case 6: // option-request
+		for i := 0; i+1 < len(opt); i += 2 {
+			code := binary.BigEndian.Uint16(opt[i:])
+			if name, ok := dhcp6OptionNames[code]; ok {
+				fmt.Fprintf(b, " %s", name)
+			} else {
+				fmt.Fprintf(b, " opt_%d", code)
+			}
+		}
+
+	case 7: // preference
+		if len(opt) == 1 {
+			fmt.Fprintf(b, " %d", opt[0])
+		}
+
+	case 8: // elapsed-time
+		if len(opt) == 2 {
+			fmt.Fprintf(b, " %d", binary.BigEndian.Uint16(opt))
+		}
+
+	case 32: // info-refresh-time
+		if len(opt) == 4 {
+			fmt.Fprintf(b, " %d", binary.BigEndian.Uint32(opt))
+		}
+
+	case 9: // relay-message
+		fmt.Fprintf(b, " (%s)", dhcp6Message(opt, verbose))
+
+	case 13: // status-code
+		if len(opt) < 2 {
+			b.WriteString(" ?")
+			return
+		}
+		status := binary.BigEndian.Uint16(opt)
+		if name, ok := dhcp6StatusCodes[status]; ok {
+			fmt.Fprintf(b, " %s", name)
+		} else {
+			fmt.Fprintf(b, " code%d", status)
+		}
+		if len(opt) > 2 {
+			fmt.Fprintf(b, " %q", opt[2:])
+		}
+
+	case 12, 22, 23, 27, 28, 31: // lists of IPv6 addresses
+		if len(opt)%net.IPv6len != 0 {
+			b.WriteString(" ?")
+			return
+		}
+		for i := 0; i < len(opt); i += net.IPv6len {
+			fmt.Fprintf(b, " %s", net.IP(opt[i:i+net.IPv6len]))
+		}
+
+	case 21, 24, 29, 30: // lists of domain names
+		names, err := dnsNames(opt)
+		if err != nil {
+			b.WriteString(" ?")
+			return
+		}
+		for _, name := range names {
+			fmt.Fprintf(b, " %s", name)
+		}
+
+	case 16, 17: // vendor-class, vendor-specific-info
+		if len(opt) >= 4 {
+			fmt.Fprintf(b, " enterprise %d", binary.BigEndian.Uint32(opt))
+		}
+
+	case 18: // interface-ID
+		fmt.Fprintf(b, " %x", opt)
+
+	case 59: // bootfile-URL
+		fmt.Fprintf(b, " %q", opt)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+)
+
+func TestDhcp6Data(t *testing.T) {
+	solicit := []byte{
+		0x01, 0x01, 0x02, 0x03,
+		// client-ID: DUID-LLT
+		0x00, 0x01, 0x00, 0x0e, 0x00, 0x01, 0x00, 0x01, 0x12, 0x34, 0x56, 0x78, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55,
+		// elapsed-time
+		0x00, 0x08, 0x00, 0x02, 0x00, 0x00,
+		// IA_NA
+		0x00, 0x03, 0x00, 0x0c, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		// option-request
+		0x00, 0x06, 0x00, 0x06, 0x00, 0x17, 0x00, 0x18, 0x00, 0x3b,
+	}
+
+	reply := []byte{
+		0x07, 0x01, 0x02, 0x03,
+		// server-ID: DUID-LL
+		0x00, 0x02, 0x00, 0x0a, 0x00, 0x03, 0x00, 0x01, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb,
+		// IA_NA with an IA_ADDR
+		0x00, 0x03, 0x00, 0x28, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x0e, 0x10, 0x00, 0x00, 0x15, 0x18,
+		0x00, 0x05, 0x00, 0x18,
+		0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00,
+		0x00, 0x00, 0x1c, 0x20, 0x00, 0x00, 0x1c, 0x20,
+		// DNS-server
+		0x00, 0x17, 0x00, 0x10,
+		0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		// status-code
+		0x00, 0x0d, 0x00, 0x04, 0x00, 0x00, 0x4f, 0x4b,
+		// bootfile-URL
+		0x00, 0x3b, 0x00, 0x12, 't', 'f', 't', 'p', ':', '/', '/', '[', ':', ':', '1', ']', '/', 'b', 'o', 'o', 't', '0',
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		verbose  bool
+		expected string
+	}{
+		{
+			name:     "Solicit",
+			data:     solicit,
+			expected: "dhcp6 solicit",
+		},
+		{
+			name:     "Solicit verbose",
+			data:     solicit,
+			verbose:  true,
+			expected: "dhcp6 solicit (xid=10203 (client-ID hwaddr/time type 1 time 305419896 001122334455) (elapsed-time 0) (IA_NA IAID:1 T1:0 T2:0) (option-request DNS-server DNS-search-list bootfile-URL))",
+		},
+		{
+			name:    "Reply verbose",
+			data:    reply,
+			verbose: true,
+			expected: "dhcp6 reply (xid=10203 (server-ID hwaddr type 1 66778899aabb) (IA_NA IAID:1 T1:3600 T2:5400 (IA_ADDR 2001:db8::100 pltime:7200 vltime:7200))" +
+				" (DNS-server 2001:db8::1) (status-code Success \"OK\") (bootfile-URL \"tftp://[::1]/boot0\"))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet := gopacket.NewPacket(tt.data, layers.LayerTypeDHCPv6, gopacket.Default)
+			layer, ok := packet.Layer(layers.LayerTypeDHCPv6).(*layers.DHCPv6)
+			if !ok {
+				t.Fatalf("packet has no DHCPv6 layer: %v", packet.ErrorLayer())
+			}
+
+			if got := dhcp6Data(layer, tt.verbose); got != tt.expected {
+				t.Errorf("dhcp6Data() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+)
+
+func TestDhcpData(t *testing.T) {
+	mac := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+
+	discover := &layers.DHCPv4{
+		Operation:    layers.DHCPOpRequest,
+		HardwareType: layers.LinkTypeEthernet,
+		HardwareLen:  6,
+		Xid:          0x3903f326,
+		Flags:        0x8000,
+		ClientHWAddr: mac,
+		Options: layers.DHCPOptions{
+			layers.NewDHCPOption(layers.DHCPOptMessageType, []byte{byte(layers.DHCPMsgTypeDiscover)}),
+			layers.NewDHCPOption(layers.DHCPOptClientID, append([]byte{1}, mac...)),
+			layers.NewDHCPOption(layers.DHCPOptParamsRequest, []byte{1, 3, 6, 15, 66, 67}),
+			layers.NewDHCPOption(layers.DHCPOptHostname, []byte("u-root")),
+		},
+	}
+
+	offer := &layers.DHCPv4{
+		Operation:    layers.DHCPOpReply,
+		HardwareType: layers.LinkTypeEthernet,
+		HardwareLen:  6,
+		Xid:          0x3903f326,
+		YourClientIP: net.IP{192, 168, 0, 104},
+		NextServerIP: net.IP{192, 168, 0, 1},
+		ClientHWAddr: mac,
+		File:         []byte("pxelinux.0"),
+		Options: layers.DHCPOptions{
+			layers.NewDHCPOption(layers.DHCPOptMessageType, []byte{byte(layers.DHCPMsgTypeOffer)}),
+			layers.NewDHCPOption(layers.DHCPOptServerID, []byte{192, 168, 0, 1}),
+			layers.NewDHCPOption(layers.DHCPOptLeaseTime, []byte{0x00, 0x00, 0x0e, 0x10}),
+			layers.NewDHCPOption(layers.DHCPOptSubnetMask, []byte{255, 255, 255, 0}),
+			layers.NewDHCPOption(layers.DHCPOptRouter, []byte{192, 168, 0, 1}),
+			layers.NewDHCPOption(layers.DHCPOptDNS, []byte{192, 168, 0, 1, 8, 8, 8, 8}),
+			layers.NewDHCPOption(66, []byte("192.168.0.1")),
+			layers.NewDHCPOption(layers.DHCPOptClasslessStaticRoute, []byte{24, 10, 1, 2, 192, 168, 0, 2, 0, 192, 168, 0, 1}),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		layer    *layers.DHCPv4
+		verbose  bool
+		expected string
+	}{
+		{
+			name:     "Discover",
+			layer:    discover,
+			expected: "BOOTP/DHCP, Request from 00:11:22:33:44:55, length 269",
+		},
+		{
+			name:    "Discover verbose",
+			layer:   discover,
+			verbose: true,
+			expected: "BOOTP/DHCP, Request from 00:11:22:33:44:55, length 269, xid 0x3903f326, Flags [Broadcast]" +
+				"\n\t  Client-Ethernet-Address 00:11:22:33:44:55" +
+				"\n\t  Vendor-rfc1048 Extensions" +
+				"\n\t    Magic Cookie 0x63825363" +
+				"\n\t    DHCP-Message (53), length 1: Discover" +
+				"\n\t    Client-ID (61), length 7: ether 00:11:22:33:44:55" +
+				"\n\t    Parameter-Request (55), length 6: " +
+				"\n\t      Subnet-Mask (1), Default-Gateway (3), Domain-Name-Server (6), Domain-Name (15)" +
+				"\n\t      TFTP (66), BF (67)" +
+				"\n\t    Hostname (12), length 6: \"u-root\"" +
+				"\n\t    END (255), length 0",
+		},
+		{
+			name:    "Offer verbose",
+			layer:   offer,
+			verbose: true,
+			expected: "BOOTP/DHCP, Reply, length 306, xid 0x3903f326, Flags [none]" +
+				"\n\t  Your-IP 192.168.0.104" +
+				"\n\t  Server-IP 192.168.0.1" +
+				"\n\t  Client-Ethernet-Address 00:11:22:33:44:55" +
+				"\n\t  file \"pxelinux.0\"" +
+				"\n\t  Vendor-rfc1048 Extensions" +
+				"\n\t    Magic Cookie 0x63825363" +
+				"\n\t    DHCP-Message (53), length 1: Offer" +
+				"\n\t    Server-ID (54), length 4: 192.168.0.1" +
+				"\n\t    Lease-Time (51), length 4: 3600" +
+				"\n\t    Subnet-Mask (1), length 4: 255.255.255.0" +
+				"\n\t    Default-Gateway (3), length 4: 192.168.0.1" +
+				"\n\t    Domain-Name-Server (6), length 8: 192.168.0.1,8.8.8.8" +
+				"\n\t    TFTP (66), length 11: \"192.168.0.1\"" +
+				"\n\t    Classless-Static-Route (121), length 13: (10.1.2.0/24:192.168.0.2),(default:192.168.0.1)" +
+				"\n\t    END (255), length 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := gopacket.NewSerializeBuffer()
+			if err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true}, tt.layer); err != nil {
+				t.Fatalf("SerializeLayers() = %v", err)
+			}
+			packet := gopacket.NewPacket(buf.Bytes(), layers.LayerTypeDHCPv4, gopacket.Default)
+			layer := packet.Layer(layers.LayerTypeDHCPv4).(*layers.DHCPv4)
+
+			if got := dhcpData(layer, tt.verbose); got != tt.expected {
+				t.Errorf("dhcpData() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
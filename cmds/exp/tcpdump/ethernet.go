@@ -9,10 +9,20 @@ import (
 	"fmt"
 
 	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
 )
 
-func (cmd cmd) ethernetInfo(ethernetLayer gopacket.LinkLayer, networkLayer gopacket.NetworkLayer) string {
+// ethernetInfo returns the link-level part of a dump line. Unless -e is
+// given, this is only the network protocol and the device.
+func (cmd cmd) ethernetInfo(packet gopacket.Packet) string {
+	ethernetLayer := packet.LinkLayer()
+	networkLayer := packet.NetworkLayer()
+
 	if !cmd.Opts.Ether {
+		if networkLayer == nil {
+			return ""
+		}
+
 		return fmt.Sprintf("%s %s", networkLayer.NetworkFlow().EndpointType(), cmd.Opts.Device)
 	}
 
@@ -23,7 +33,33 @@ func (cmd cmd) ethernetInfo(ethernetLayer gopacket.LinkLayer, networkLayer gopac
 		dstHost = "Broadcast"
 	}
 
+	var etherType fmt.Stringer
+	if eth, ok := ethernetLayer.(*layers.Ethernet); ok {
+		etherType = eth.EthernetType
+	} else if networkLayer != nil {
+		etherType = networkLayer.NetworkFlow().EndpointType()
+	}
+
 	length := len(ethernetLayer.LayerContents()) + len(ethernetLayer.LayerPayload())
 
-	return fmt.Sprintf("%s > %s, ethertype %s, length %d:", src, dstHost, networkLayer.NetworkFlow().EndpointType(), length)
+	info := fmt.Sprintf("%s > %s, ethertype %s, length %d:", src, dstHost, etherType, length)
+
+	// 802.1Q tags, outermost first.
+	for _, layer := range packet.Layers() {
+		if tag, ok := layer.(*layers.Dot1Q); ok {
+			info += vlanInfo(tag)
+		}
+	}
+
+	return info
+}
+
+// vlanInfo returns a string representation of an 802.1Q tag.
+func vlanInfo(tag *layers.Dot1Q) string {
+	var dei string
+	if tag.DropEligible {
+		dei = ", DEI"
+	}
+
+	return fmt.Sprintf(" vlan %d, p %d%s, ethertype %s,", tag.VLANIdentifier, tag.Priority, dei, tag.Type)
 }
@@ -13,11 +13,15 @@ import (
 )
 
 func TestEthernetInfo(t *testing.T) {
+	ipv4 := []byte{
+		0x45, 0x00, 0x00, 0x14, 0x1c, 0x46, 0x40, 0x00, 0x40, 0x06, 0xb1, 0xe6, 0xc0, 0xa8, 0x00, 0x01,
+		0xc0, 0xa8, 0x00, 0x02,
+	}
+
 	tests := []struct {
 		name           string
 		cmd            cmd
-		ethernetLayer  gopacket.LinkLayer
-		networkLayer   gopacket.NetworkLayer
+		packetData     []byte
 		expectedOutput string
 	}{
 		{
@@ -28,16 +32,9 @@ func TestEthernetInfo(t *testing.T) {
 					Device: "eth0",
 				},
 			},
-			ethernetLayer: &layers.Ethernet{
-				SrcMAC:       []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
-				DstMAC:       []byte{0x66, 0x77, 0x88, 0x99, 0xAA, 0xBB},
-				EthernetType: layers.EthernetTypeIPv4,
-			},
-			networkLayer: &layers.IPv4{
-				SrcIP:    []byte{192, 168, 0, 1},
-				DstIP:    []byte{192, 168, 0, 2},
-				Protocol: layers.IPProtocolTCP,
-			},
+			packetData: append([]byte{
+				0x66, 0x77, 0x88, 0x99, 0xAA, 0xBB, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x08, 0x00,
+			}, ipv4...),
 			expectedOutput: "IPv4 eth0",
 		},
 		{
@@ -48,51 +45,58 @@ func TestEthernetInfo(t *testing.T) {
 					Device: "eth0",
 				},
 			},
-			ethernetLayer: &layers.Ethernet{
-				SrcMAC:       []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
-				DstMAC:       []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
-				EthernetType: layers.EthernetTypeIPv4,
-				Length:       14,
-				BaseLayer: layers.BaseLayer{
-					Contents: []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xAA, 0xBB, 0x08, 0x00},
+			packetData: append([]byte{
+				0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x08, 0x00,
+			}, ipv4...),
+			expectedOutput: "00:11:22:33:44:55 > Broadcast, ethertype IPv4, length 34:",
+		},
+		{
+			name: "Ether option enabled with unicast",
+			cmd: cmd{
+				Opts: flags{
+					Ether:  true,
+					Device: "eth0",
 				},
 			},
-			networkLayer: &layers.IPv4{
-				SrcIP:    []byte{192, 168, 0, 1},
-				DstIP:    []byte{192, 168, 0, 2},
-				Protocol: layers.IPProtocolTCP,
-			},
-			expectedOutput: "00:11:22:33:44:55 > Broadcast, ethertype IPv4, length 14:",
+			packetData: append([]byte{
+				0x66, 0x77, 0x88, 0x99, 0xAA, 0xBB, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x08, 0x00,
+			}, ipv4...),
+			expectedOutput: "00:11:22:33:44:55 > 66:77:88:99:aa:bb, ethertype IPv4, length 34:",
 		},
 		{
-			name: "Ether option enabled with unicast",
+			name: "Ether option enabled with VLAN tag",
 			cmd: cmd{
 				Opts: flags{
 					Ether:  true,
 					Device: "eth0",
 				},
 			},
-			ethernetLayer: &layers.Ethernet{
-				SrcMAC:       []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
-				DstMAC:       []byte{0x66, 0x77, 0x88, 0x99, 0xAA, 0xBB},
-				EthernetType: layers.EthernetTypeIPv4,
-				Length:       14,
-				BaseLayer: layers.BaseLayer{
-					Contents: []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xAA, 0xBB, 0x08, 0x00},
+			packetData: append([]byte{
+				0x66, 0x77, 0x88, 0x99, 0xAA, 0xBB, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x81, 0x00,
+				// 802.1Q tag: priority 5, VLAN 100
+				0xa0, 0x64, 0x08, 0x00,
+			}, ipv4...),
+			expectedOutput: "00:11:22:33:44:55 > 66:77:88:99:aa:bb, ethertype Dot1Q, length 38: vlan 100, p 5, ethertype IPv4,",
+		},
+		{
+			name: "Ether option disabled without network layer",
+			cmd: cmd{
+				Opts: flags{
+					Device: "eth0",
 				},
 			},
-			networkLayer: &layers.IPv4{
-				SrcIP:    []byte{192, 168, 0, 1},
-				DstIP:    []byte{192, 168, 0, 2},
-				Protocol: layers.IPProtocolTCP,
+			packetData: []byte{
+				0x66, 0x77, 0x88, 0x99, 0xAA, 0xBB, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x88, 0xb5,
 			},
-			expectedOutput: "00:11:22:33:44:55 > 66:77:88:99:aa:bb, ethertype IPv4, length 14:",
+			expectedOutput: "",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := tt.cmd.ethernetInfo(tt.ethernetLayer, tt.networkLayer)
+			packet := gopacket.NewPacket(tt.packetData, layers.LayerTypeEthernet, gopacket.Default)
+
+			result := tt.cmd.ethernetInfo(packet)
 			if result != tt.expectedOutput {
 				t.Errorf("ethernetInfo() = %v, want %v", result, tt.expectedOutput)
 			}
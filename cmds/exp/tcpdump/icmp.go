@@ -6,15 +6,28 @@
 package main
 
 import (
+	"encoding/binary"
 	"fmt"
+	"net"
+	"strings"
 
 	"github.com/gopacket/gopacket"
 
 	"github.com/gopacket/gopacket/layers"
 )
 
+var ndOptionNames = map[layers.ICMPv6Opt]string{
+	layers.ICMPv6OptSourceAddress:    "source link-address",
+	layers.ICMPv6OptTargetAddress:    "destination link-address",
+	layers.ICMPv6OptPrefixInfo:       "prefix info",
+	layers.ICMPv6OptRedirectedHeader: "redirected header",
+	layers.ICMPv6OptMTU:              "mtu",
+	25:                               "rdnss",
+	31:                               "dnssl",
+}
+
 // parseICMP parses ICMP packets and returns a string representation of the packet.
-func parseICMP(packet gopacket.Packet) string {
+func parseICMP(packet gopacket.Packet, verbose bool) string {
 	icmpv4 := packet.Layer(layers.LayerTypeICMPv4)
 	if icmpv4 != nil {
 		layer := icmpv4.(*layers.ICMPv4)
@@ -25,9 +38,192 @@ func parseICMP(packet gopacket.Packet) string {
 	icmpv6 := packet.Layer(layers.LayerTypeICMPv6)
 	if icmpv6 != nil {
 		layer := icmpv6.(*layers.ICMPv6)
+		length := len(layer.Contents) + len(layer.Payload)
+
+		if nd := ndData(packet, length, verbose); nd != "" {
+			return nd
+		}
 
-		return fmt.Sprintf("ICMP6 %s, length %d", layer.TypeCode.String(), len(layer.Contents)+len(layer.Payload))
+		return fmt.Sprintf("ICMP6 %s, length %d", layer.TypeCode.String(), length)
 	}
 
 	return ""
 }
+
+// ndData returns a string representation of an IPv6 neighbor discovery
+// message, or an empty string if the packet does not carry one. Like in
+// tcpdump, the options are only shown in verbose mode.
+func ndData(packet gopacket.Packet, length int, verbose bool) string {
+	var (
+		b       strings.Builder
+		options layers.ICMPv6Options
+	)
+
+	// message writes the first line of the message. tcpdump puts the
+	// addresses after the length in verbose mode and before it otherwise.
+	message := func(name, what string) {
+		if what == "" {
+			fmt.Fprintf(&b, "ICMP6, %s, length %d", name, length)
+		} else if verbose {
+			fmt.Fprintf(&b, "ICMP6, %s, length %d, %s", name, length, what)
+		} else {
+			fmt.Fprintf(&b, "ICMP6, %s, %s, length %d", name, what, length)
+		}
+	}
+
+	switch {
+	case packet.Layer(layers.LayerTypeICMPv6NeighborSolicitation) != nil:
+		layer := packet.Layer(layers.LayerTypeICMPv6NeighborSolicitation).(*layers.ICMPv6NeighborSolicitation)
+		message("neighbor solicitation", "who has "+layer.TargetAddress.String())
+		options = layer.Options
+
+	case packet.Layer(layers.LayerTypeICMPv6NeighborAdvertisement) != nil:
+		layer := packet.Layer(layers.LayerTypeICMPv6NeighborAdvertisement).(*layers.ICMPv6NeighborAdvertisement)
+		message("neighbor advertisement", "tgt is "+layer.TargetAddress.String())
+		if verbose {
+			fmt.Fprintf(&b, ", Flags [%s]", ndFlags(layer.Flags, []string{"router", "solicited", "override"}))
+		}
+		options = layer.Options
+
+	case packet.Layer(layers.LayerTypeICMPv6RouterSolicitation) != nil:
+		layer := packet.Layer(layers.LayerTypeICMPv6RouterSolicitation).(*layers.ICMPv6RouterSolicitation)
+		message("router solicitation", "")
+		options = layer.Options
+
+	case packet.Layer(layers.LayerTypeICMPv6RouterAdvertisement) != nil:
+		layer := packet.Layer(layers.LayerTypeICMPv6RouterAdvertisement).(*layers.ICMPv6RouterAdvertisement)
+		message("router advertisement", "")
+		if verbose {
+			fmt.Fprintf(&b, "\n\thop limit %d, Flags [%s], pref %s, router lifetime %ds, reachable time %dms, retrans timer %dms",
+				layer.HopLimit, ndFlags(layer.Flags, []string{"managed", "other stateful", "home agent", "", "", "proxy"}),
+				ndRouterPreference(layer.Flags), layer.RouterLifetime, layer.ReachableTime, layer.RetransTimer)
+		}
+		options = layer.Options
+
+	case packet.Layer(layers.LayerTypeICMPv6Redirect) != nil:
+		layer := packet.Layer(layers.LayerTypeICMPv6Redirect).(*layers.ICMPv6Redirect)
+		message("redirect", fmt.Sprintf("%s to %s", layer.DestinationAddress, layer.TargetAddress))
+		options = layer.Options
+
+	default:
+		return ""
+	}
+
+	if verbose {
+		for _, opt := range options {
+			ndOption(&b, opt)
+		}
+	}
+
+	return b.String()
+}
+
+// ndOption writes a neighbor discovery option to b. The length is printed in
+// bytes and in the 8 byte units used on the wire.
+func ndOption(b *strings.Builder, opt layers.ICMPv6Option) {
+	name, ok := ndOptionNames[opt.Type]
+	if !ok {
+		name = "unknown"
+	}
+
+	size := len(opt.Data) + 2
+	fmt.Fprintf(b, "\n\t  %s option (%d), length %d (%d): ", name, opt.Type, size, size/8)
+
+	data := opt.Data
+
+	switch opt.Type {
+	case layers.ICMPv6OptSourceAddress, layers.ICMPv6OptTargetAddress:
+		b.WriteString(net.HardwareAddr(data).String())
+
+	case layers.ICMPv6OptPrefixInfo:
+		if len(data) < 30 {
+			b.WriteString("[|icmp6]")
+			return
+		}
+		fmt.Fprintf(b, "%s/%d, Flags [%s], valid time %s, pref. time %s",
+			net.IP(data[14:30]), data[0], ndFlags(data[1], []string{"onlink", "auto", "router"}),
+			ndLifetime(binary.BigEndian.Uint32(data[2:])), ndLifetime(binary.BigEndian.Uint32(data[6:])))
+
+	case layers.ICMPv6OptMTU:
+		if len(data) < 6 {
+			b.WriteString("[|icmp6]")
+			return
+		}
+		fmt.Fprintf(b, "%d", binary.BigEndian.Uint32(data[2:]))
+
+	case 25: // rdnss
+		if len(data) < 6 {
+			b.WriteString("[|icmp6]")
+			return
+		}
+		fmt.Fprintf(b, " lifetime %s,", ndLifetime(binary.BigEndian.Uint32(data[2:])))
+		for addr := data[6:]; len(addr) >= net.IPv6len; addr = addr[net.IPv6len:] {
+			fmt.Fprintf(b, " addr: %s", net.IP(addr[:net.IPv6len]))
+		}
+
+	case 31: // dnssl
+		if len(data) < 6 {
+			b.WriteString("[|icmp6]")
+			return
+		}
+		fmt.Fprintf(b, " lifetime %s, domain(s):", ndLifetime(binary.BigEndian.Uint32(data[2:])))
+		// The list is padded with zeroes to a multiple of 8 bytes.
+		names, err := dnsNames(trimPadding(data[6:]))
+		if err != nil {
+			b.WriteString(" [|icmp6]")
+			return
+		}
+		for _, name := range names {
+			fmt.Fprintf(b, " %s", name)
+		}
+
+	default:
+		fmt.Fprintf(b, "0x%x", data)
+	}
+}
+
+// ndFlags returns the names of the flags set in v, from the most significant
+// bit down, or "none".
+func ndFlags(v uint8, names []string) string {
+	var set []string
+	for i, name := range names {
+		if name != "" && v&(0x80>>i) != 0 {
+			set = append(set, name)
+		}
+	}
+	if len(set) == 0 {
+		return "none"
+	}
+	return strings.Join(set, ", ")
+}
+
+// ndRouterPreference returns the default router preference of a router
+// advertisement (RFC 4191).
+func ndRouterPreference(flags uint8) string {
+	switch flags & 0x18 {
+	case 0x08:
+		return "high"
+	case 0x10:
+		return "reserved"
+	case 0x18:
+		return "low"
+	}
+	return "medium"
+}
+
+// ndLifetime formats a lifetime in seconds, where all ones means forever.
+func ndLifetime(v uint32) string {
+	if v == 0xffffffff {
+		return "infinity"
+	}
+	return fmt.Sprintf("%ds", v)
+}
+
+// trimPadding strips trailing zero bytes past the final root label of a
+// list of domain names.
+func trimPadding(data []byte) []byte {
+	for len(data) > 1 && data[len(data)-1] == 0 && data[len(data)-2] == 0 {
+		data = data[:len(data)-1]
+	}
+	return data
+}
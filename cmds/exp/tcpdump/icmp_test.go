@@ -16,6 +16,7 @@ func TestParseICMP(t *testing.T) {
 	tests := []struct {
 		name           string
 		packet         gopacket.Packet
+		verbose        bool
 		expectedOutput string
 	}{
 		{
@@ -40,6 +41,106 @@ func TestParseICMP(t *testing.T) {
 			),
 			expectedOutput: "ICMP6 EchoRequest, length 12",
 		},
+		{
+			name: "Neighbor solicitation",
+			packet: gopacket.NewPacket(
+				[]byte{
+					0x87, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					// Target address
+					0xfe, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+					// Source link-address option
+					0x01, 0x01, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55,
+				},
+				layers.LayerTypeICMPv6,
+				gopacket.Default,
+			),
+			expectedOutput: "ICMP6, neighbor solicitation, who has fe80::1, length 32",
+		},
+		{
+			name: "Neighbor solicitation verbose",
+			packet: gopacket.NewPacket(
+				[]byte{
+					0x87, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					// Target address
+					0xfe, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+					// Source link-address option
+					0x01, 0x01, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55,
+				},
+				layers.LayerTypeICMPv6,
+				gopacket.Default,
+			),
+			verbose:        true,
+			expectedOutput: "ICMP6, neighbor solicitation, length 32, who has fe80::1\n\t  source link-address option (1), length 8 (1): 00:11:22:33:44:55",
+		},
+		{
+			name: "Neighbor advertisement verbose",
+			packet: gopacket.NewPacket(
+				[]byte{
+					0x88, 0x00, 0x00, 0x00, 0x60, 0x00, 0x00, 0x00,
+					// Target address
+					0xfe, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+					// Destination link-address option
+					0x02, 0x01, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55,
+				},
+				layers.LayerTypeICMPv6,
+				gopacket.Default,
+			),
+			verbose:        true,
+			expectedOutput: "ICMP6, neighbor advertisement, length 32, tgt is fe80::1, Flags [solicited, override]\n\t  destination link-address option (2), length 8 (1): 00:11:22:33:44:55",
+		},
+		{
+			name: "Router solicitation",
+			packet: gopacket.NewPacket(
+				[]byte{
+					0x85, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				},
+				layers.LayerTypeICMPv6,
+				gopacket.Default,
+			),
+			expectedOutput: "ICMP6, router solicitation, length 8",
+		},
+		{
+			name: "Router advertisement verbose",
+			packet: gopacket.NewPacket(
+				[]byte{
+					0x86, 0x00, 0x00, 0x00, 0x40, 0x40, 0x07, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					// Source link-address option
+					0x01, 0x01, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55,
+					// MTU option
+					0x05, 0x01, 0x00, 0x00, 0x00, 0x00, 0x05, 0xdc,
+					// Prefix information option
+					0x03, 0x04, 0x40, 0xc0, 0x00, 0x27, 0x8d, 0x00, 0x00, 0x09, 0x3a, 0x80, 0x00, 0x00, 0x00, 0x00,
+					0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					// Recursive DNS server option
+					0x19, 0x03, 0x00, 0x00, 0x00, 0x00, 0x0e, 0x10,
+					0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+				},
+				layers.LayerTypeICMPv6,
+				gopacket.Default,
+			),
+			verbose: true,
+			expectedOutput: "ICMP6, router advertisement, length 88" +
+				"\n\thop limit 64, Flags [other stateful], pref medium, router lifetime 1800s, reachable time 0ms, retrans timer 0ms" +
+				"\n\t  source link-address option (1), length 8 (1): 00:11:22:33:44:55" +
+				"\n\t  mtu option (5), length 8 (1): 1500" +
+				"\n\t  prefix info option (3), length 32 (4): 2001:db8::/64, Flags [onlink, auto], valid time 2592000s, pref. time 604800s" +
+				"\n\t  rdnss option (25), length 24 (3):  lifetime 3600s, addr: 2001:db8::1",
+		},
+		{
+			name: "Redirect",
+			packet: gopacket.NewPacket(
+				[]byte{
+					0x89, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+					// Target address
+					0xfe, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+					// Destination address
+					0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+				},
+				layers.LayerTypeICMPv6,
+				gopacket.Default,
+			),
+			expectedOutput: "ICMP6, redirect, 2001:db8::2 to fe80::1, length 40",
+		},
 		{
 			name: "Non-ICMP Packet",
 			packet: gopacket.NewPacket(
@@ -56,7 +157,7 @@ func TestParseICMP(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := parseICMP(tt.packet)
+			result := parseICMP(tt.packet, tt.verbose)
 			if result != tt.expectedOutput {
 				t.Errorf("parseICMP() = %v, want %v", result, tt.expectedOutput)
 			}
@@ -0,0 +1,202 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gopacket/gopacket/layers"
+)
+
+var lldpTLVNames = map[layers.LLDPTLVType]string{
+	layers.LLDPTLVEnd:             "End",
+	layers.LLDPTLVChassisID:       "Chassis ID",
+	layers.LLDPTLVPortID:          "Port ID",
+	layers.LLDPTLVTTL:             "Time to Live",
+	layers.LLDPTLVPortDescription: "Port Description",
+	layers.LLDPTLVSysName:         "System Name",
+	layers.LLDPTLVSysDescription:  "System Description",
+	layers.LLDPTLVSysCapabilities: "System Capabilities",
+	layers.LLDPTLVMgmtAddress:     "Management Address",
+	layers.LLDPTLVOrgSpecific:     "Organization specific",
+}
+
+var lldpChassisSubtypes = map[layers.LLDPChassisIDSubType]string{
+	1: "Chassis component",
+	2: "Interface alias",
+	3: "Port component",
+	4: "MAC address",
+	5: "Network address",
+	6: "Interface name",
+	7: "Local",
+}
+
+var lldpPortSubtypes = map[layers.LLDPPortIDSubType]string{
+	1: "Interface alias",
+	2: "Port component",
+	3: "MAC address",
+	4: "Network Address",
+	5: "Interface Name",
+	6: "Agent circuit ID",
+	7: "Local",
+}
+
+var lldpCapabilities = []string{
+	"Other",
+	"Repeater",
+	"Bridge",
+	"WLAN AP",
+	"Router",
+	"Telephone",
+	"Docsis",
+	"Station Only",
+}
+
+var lldpInterfaceNumbering = map[byte]string{
+	1: "Unknown",
+	2: "Interface Index",
+	3: "System Port Number",
+}
+
+var lldpOUIs = map[uint32]string{
+	0x0080c2: "IEEE 802.1 Private",
+	0x00120f: "IEEE 802.3 Private",
+	0x0012bb: "ANSI/TIA",
+}
+
+// lldpData returns a string representation of the LLDP layer.
+func lldpData(layer *layers.LinkLayerDiscovery, verbose bool) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "LLDP, length %d", len(layer.Contents))
+
+	if !verbose {
+		for _, v := range layer.Values {
+			if v.Type == layers.LLDPTLVSysName {
+				fmt.Fprintf(&b, ": %s", v.Value)
+			}
+		}
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "\n\tChassis ID TLV (%d), length %d", layers.LLDPTLVChassisID, len(layer.ChassisID.ID)+1)
+	fmt.Fprintf(&b, "\n\t  Subtype %s (%d): %s", lldpName(lldpChassisSubtypes, layer.ChassisID.Subtype), layer.ChassisID.Subtype,
+		lldpID(layer.ChassisID.ID, layer.ChassisID.Subtype == layers.LLDPChassisIDSubTypeMACAddr, layer.ChassisID.Subtype == layers.LLDPChassisIDSubTypeNetworkAddr))
+
+	fmt.Fprintf(&b, "\n\tPort ID TLV (%d), length %d", layers.LLDPTLVPortID, len(layer.PortID.ID)+1)
+	fmt.Fprintf(&b, "\n\t  Subtype %s (%d): %s", lldpName(lldpPortSubtypes, layer.PortID.Subtype), layer.PortID.Subtype,
+		lldpID(layer.PortID.ID, layer.PortID.Subtype == layers.LLDPPortIDSubtypeMACAddr, layer.PortID.Subtype == layers.LLDPPortIDSubtypeNetworkAddr))
+
+	fmt.Fprintf(&b, "\n\tTime to Live TLV (%d), length 2: TTL %ds", layers.LLDPTLVTTL, layer.TTL)
+
+	for _, v := range layer.Values {
+		name, ok := lldpTLVNames[v.Type]
+		if !ok {
+			name = "Unknown"
+		}
+		fmt.Fprintf(&b, "\n\t%s TLV (%d), length %d", name, v.Type, v.Length)
+		lldpValue(&b, v)
+	}
+
+	fmt.Fprintf(&b, "\n\tEnd TLV (%d), length 0", layers.LLDPTLVEnd)
+
+	return b.String()
+}
+
+// lldpValue writes the value of an optional TLV to b.
+func lldpValue(b *strings.Builder, v layers.LinkLayerDiscoveryValue) {
+	data := v.Value
+
+	switch v.Type {
+	case layers.LLDPTLVPortDescription, layers.LLDPTLVSysName:
+		fmt.Fprintf(b, ": %s", data)
+
+	case layers.LLDPTLVSysDescription:
+		fmt.Fprintf(b, "\n\t  %s", data)
+
+	case layers.LLDPTLVSysCapabilities:
+		if len(data) != 4 {
+			return
+		}
+		system, enabled := binary.BigEndian.Uint16(data), binary.BigEndian.Uint16(data[2:])
+		fmt.Fprintf(b, "\n\t  System  Capabilities [%s] (0x%04x)", lldpCapabilityNames(system), system)
+		fmt.Fprintf(b, "\n\t  Enabled Capabilities [%s] (0x%04x)", lldpCapabilityNames(enabled), enabled)
+
+	case layers.LLDPTLVMgmtAddress:
+		if len(data) < 1 || len(data) < 1+int(data[0])+5 || data[0] < 1 {
+			return
+		}
+		n := int(data[0])
+		addr := data[1 : 1+n]
+		fmt.Fprintf(b, "\n\t  Management Address length %d, %s", n, lldpNetworkAddress(addr))
+		subtype := data[1+n]
+		fmt.Fprintf(b, "\n\t  %s Interface Numbering (%d): %d", lldpName(lldpInterfaceNumbering, subtype), subtype, binary.BigEndian.Uint32(data[2+n:]))
+
+	case layers.LLDPTLVOrgSpecific:
+		if len(data) < 4 {
+			return
+		}
+		oui := uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2])
+		subtype := data[3]
+		fmt.Fprintf(b, ": OUI %s (0x%06x), subtype %d", lldpName(lldpOUIs, oui), oui, subtype)
+		// The port VLAN ID is the one 802.1 TLV worth decoding on a boot
+		// network.
+		if oui == 0x0080c2 && subtype == 1 && len(data) == 6 {
+			fmt.Fprintf(b, "\n\t    port vlan id (PVID): %d", binary.BigEndian.Uint16(data[4:]))
+		}
+	}
+}
+
+// lldpID formats a chassis or port ID.
+func lldpID(id []byte, mac, network bool) string {
+	switch {
+	case mac && len(id) == 6:
+		return net.HardwareAddr(id).String()
+	case network:
+		return lldpNetworkAddress(id)
+	}
+	return string(id)
+}
+
+// lldpNetworkAddress formats an address prefixed by its IANA address family.
+func lldpNetworkAddress(addr []byte) string {
+	if len(addr) < 1 {
+		return ""
+	}
+
+	afi, addr := addr[0], addr[1:]
+	switch {
+	case afi == 1 && len(addr) == net.IPv4len:
+		return fmt.Sprintf("AFI IPv4 (1): %s", net.IP(addr))
+	case afi == 2 && len(addr) == net.IPv6len:
+		return fmt.Sprintf("AFI IPv6 (2): %s", net.IP(addr))
+	case afi == 6 && len(addr) == 6:
+		return fmt.Sprintf("AFI 802 (6): %s", net.HardwareAddr(addr))
+	}
+	return fmt.Sprintf("AFI Unknown (%d): %x", afi, addr)
+}
+
+// lldpCapabilityNames returns the names of the capability bits set in caps.
+func lldpCapabilityNames(caps uint16) string {
+	var names []string
+	for i, name := range lldpCapabilities {
+		if caps&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// lldpName looks up a name in one of the LLDP name tables.
+func lldpName[K comparable](names map[K]string, key K) string {
+	if name, ok := names[key]; ok {
+		return name
+	}
+	return "Unknown"
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+)
+
+func TestLldpData(t *testing.T) {
+	data := []byte{
+		// Chassis ID: MAC address
+		0x02, 0x07, 0x04, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55,
+		// Port ID: interface name
+		0x04, 0x05, 0x05, 'e', 't', 'h', '0',
+		// Time to Live
+		0x06, 0x02, 0x00, 0x78,
+		// Port Description
+		0x08, 0x06, 'u', 'p', 'l', 'i', 'n', 'k',
+		// System Name
+		0x0a, 0x07, 's', 'w', 'i', 't', 'c', 'h', '1',
+		// System Description
+		0x0c, 0x12, 'u', '-', 'r', 'o', 'o', 't', ' ', 't', 'e', 's', 't', ' ', 's', 'w', 'i', 't', 'c', 'h',
+		// System Capabilities
+		0x0e, 0x04, 0x00, 0x14, 0x00, 0x04,
+		// Management Address
+		0x10, 0x0c, 0x05, 0x01, 0xc0, 0xa8, 0x00, 0x02, 0x02, 0x00, 0x00, 0x03, 0xe9, 0x00,
+		// IEEE 802.1 port VLAN ID
+		0xfe, 0x06, 0x00, 0x80, 0xc2, 0x01, 0x00, 0x64,
+		// End
+		0x00, 0x00,
+	}
+
+	tests := []struct {
+		name     string
+		verbose  bool
+		expected string
+	}{
+		{
+			name:     "Default",
+			expected: "LLDP, length 87: switch1",
+		},
+		{
+			name:    "Verbose",
+			verbose: true,
+			expected: "LLDP, length 87" +
+				"\n\tChassis ID TLV (1), length 7" +
+				"\n\t  Subtype MAC address (4): 00:11:22:33:44:55" +
+				"\n\tPort ID TLV (2), length 5" +
+				"\n\t  Subtype Interface Name (5): eth0" +
+				"\n\tTime to Live TLV (3), length 2: TTL 120s" +
+				"\n\tPort Description TLV (4), length 6: uplink" +
+				"\n\tSystem Name TLV (5), length 7: switch1" +
+				"\n\tSystem Description TLV (6), length 18" +
+				"\n\t  u-root test switch" +
+				"\n\tSystem Capabilities TLV (7), length 4" +
+				"\n\t  System  Capabilities [Bridge, Router] (0x0014)" +
+				"\n\t  Enabled Capabilities [Bridge] (0x0004)" +
+				"\n\tManagement Address TLV (8), length 12" +
+				"\n\t  Management Address length 5, AFI IPv4 (1): 192.168.0.2" +
+				"\n\t  Interface Index Interface Numbering (2): 1001" +
+				"\n\tOrganization specific TLV (127), length 6: OUI IEEE 802.1 Private (0x0080c2), subtype 1" +
+				"\n\t    port vlan id (PVID): 100" +
+				"\n\tEnd TLV (0), length 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet := gopacket.NewPacket(data, layers.LayerTypeLinkLayerDiscovery, gopacket.Default)
+			layer, ok := packet.Layer(layers.LayerTypeLinkLayerDiscovery).(*layers.LinkLayerDiscovery)
+			if !ok {
+				t.Fatalf("packet has no LLDP layer: %v", packet.ErrorLayer())
+			}
+
+			if got := lldpData(layer, tt.verbose); got != tt.expected {
+				t.Errorf("lldpData() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
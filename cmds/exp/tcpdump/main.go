@@ -7,6 +7,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -22,6 +23,7 @@ import (
 
 	"github.com/gopacket/gopacket"
 	pcap "github.com/packetcap/go-pcap"
+	"golang.org/x/net/bpf"
 
 	"github.com/gopacket/gopacket/layers"
 	"github.com/u-root/u-root/pkg/uroot/unixflag"
@@ -53,11 +55,17 @@ type flags struct {
 	FilterFile             string
 	TimeStampInNanoSeconds bool
 	IcmpOnly               bool
+	WriteFile              string
+	ReadFile               string
+	FileSize               int
+	FileCount              int
+	Print                  bool
 }
 
 const tcpdumpHelp = `       tcpdump [ -ADehnpqtvx# ] [ -icmp ]
-                [ -c count ] [ --count ] [ -F file ][ -i interface ]
-			    [ --number ] [ --print ] [ -s snaplen ] [ --nano ] 
+                [ -c count ] [ --count ] [ -C file_size ] [ -F file ][ -i interface ]
+			    [ --number ] [ --print ] [ -r file ] [ -s snaplen ] [ --nano ] 
+			    [ -w file ] [ -W filecount ]
 				[ EXPRESSION ]
 	EXPRESSION := [ EXPRESSION ] [ and ] [ or ] [ not ] 
 				  [ gateway host ] [ proto protocol ] [ ether type ] [ src host ]
@@ -98,6 +106,11 @@ func parseFlags(args []string, out io.Writer) (cmd, error) {
 	fs.BoolVar(&opts.ASCII, "A", false, "Print each packet (minus its link level header) in ASCII.  Handy for capturing web pages")
 	fs.BoolVar(&opts.Quiet, "q", false, "Quiet output. Print less protocol information so output lines are shorter")
 	fs.BoolVar(&opts.Verbose, "v", false, "When parsing and printing, produce (slightly more) verbose output.  For example, the time to live, identification, total length and options in an IP packet are printed.  Also enables additional packet integrity checks such as verifying the IP and ICMP header checksum")
+	fs.StringVar(&opts.WriteFile, "w", "", "Write the raw packets to file rather than parsing and printing them out. Files ending in .pcapng are written in pcapng format, all others in pcap format. Standard output is used if file is '-'")
+	fs.StringVar(&opts.ReadFile, "r", "", "Read packets from file (which was created with the -w option or by other tools that write pcap or pcapng files). Standard input is used if file is '-'")
+	fs.IntVar(&opts.FileSize, "C", 0, "Before writing a raw packet to a savefile, check whether the file is currently larger than file_size and, if so, close the current savefile and open a new one. file_size is in units of millions of bytes")
+	fs.IntVar(&opts.FileCount, "W", 0, "Used in conjunction with the -C option, this will limit the number of files created to the specified number, and begin overwriting files from the beginning, thus creating a 'rotating' buffer")
+	fs.BoolVar(&opts.Print, "print", false, "Print parsed packet output, even if the raw packets are being saved to a file with the -w flag")
 	fs.BoolVar(&opts.Verbose, "verbose", false, "When parsing and printing, produce (slightly more) verbose output.  For example, the time to live, identification, total length and options in an IP packet are printed.  Also enables additional packet integrity checks such as verifying the IP and ICMP header checksum")
 
 	fs.Usage = func() {
@@ -113,6 +126,22 @@ func parseFlags(args []string, out io.Writer) (cmd, error) {
 		return cmd{}, fmt.Errorf("cannot use both -v and -q flags")
 	}
 
+	if opts.FileSize < 0 || opts.FileCount < 0 {
+		return cmd{}, fmt.Errorf("-C and -W must not be negative")
+	}
+
+	if (opts.FileSize > 0 || opts.FileCount > 0) && opts.WriteFile == "" {
+		return cmd{}, fmt.Errorf("-C and -W require -w")
+	}
+
+	if opts.FileCount > 0 && opts.FileSize == 0 {
+		return cmd{}, fmt.Errorf("-W requires -C")
+	}
+
+	if opts.FileSize > 0 && opts.WriteFile == "-" {
+		return cmd{}, fmt.Errorf("cannot rotate files when writing to standard output")
+	}
+
 	var filter strings.Builder
 	if fs.NArg() > 0 {
 		for _, arg := range fs.Args() {
@@ -141,11 +170,6 @@ type cmd struct {
 }
 
 func (cmd *cmd) run() error {
-	var (
-		src *pcap.Handle
-		err error
-	)
-
 	if cmd.Opts.Help {
 		cmd.usage()
 
@@ -156,7 +180,7 @@ func (cmd *cmd) run() error {
 		return listDevices()
 	}
 
-	if cmd.Opts.Device == "" {
+	if cmd.Opts.Device == "" && cmd.Opts.ReadFile == "" {
 		return fmt.Errorf("no device specified")
 	}
 
@@ -172,46 +196,140 @@ func (cmd *cmd) run() error {
 		cancel()
 	}()
 
-	if src, err = pcap.OpenLive(cmd.Opts.Device, int32(cmd.Opts.SnapshotLength), !cmd.Opts.NoPromisc, 0, false); err != nil {
-		if strings.Contains(err.Error(), "operation not permitted") {
-			return fmt.Errorf("you don't have permission to capture on that/these device(s)")
+	// When the savefile goes to stdout, everything else goes to stderr.
+	out := cmd.Out
+	if cmd.Opts.WriteFile == "-" {
+		cmd.Out = os.Stderr
+	}
+
+	var (
+		src      gopacket.PacketDataSource
+		linkType layers.LinkType
+		vm       *bpf.VM
+	)
+
+	if cmd.Opts.ReadFile != "" {
+		file, closer, err := cmd.openFile()
+		if err != nil {
+			return err
 		}
+		defer closer.Close()
 
-		return err
+		src, linkType = file, file.LinkType()
+		if vm, err = compileFilter(cmd.Opts.Filter, linkType); err != nil {
+			return err
+		}
+	} else {
+		handle, err := cmd.openLive()
+		if err != nil {
+			return err
+		}
+		defer handle.Close()
 
+		src, linkType = handle, layers.LinkType(handle.LinkType())
 	}
-	defer src.Close()
 
-	if err := src.SetBPFFilter(cmd.Opts.Filter); err != nil {
-		return err
+	var sf *savefile
+	if cmd.Opts.WriteFile != "" {
+		var err error
+		if sf, err = newSavefile(cmd.Opts, out, linkType); err != nil {
+			return err
+		}
 	}
 
-	packetSource := gopacket.NewPacketSource(src, layers.LinkTypeEthernet)
-	packetSource.NoCopy = true
+	err := cmd.capture(ctx, src, linkType, vm, sf)
+	if sf != nil {
+		// Closing flushes the pcapng writer.
+		err = errors.Join(err, sf.Close())
+	}
+	return err
+}
+
+// openLive opens the device for a live capture with the filter installed in
+// the kernel.
+func (cmd *cmd) openLive() (*pcap.Handle, error) {
+	src, err := pcap.OpenLive(cmd.Opts.Device, int32(cmd.Opts.SnapshotLength), !cmd.Opts.NoPromisc, 0, false)
+	if err != nil {
+		if strings.Contains(err.Error(), "operation not permitted") {
+			return nil, fmt.Errorf("you don't have permission to capture on that/these device(s)")
+		}
+
+		return nil, err
+	}
+
+	if err := src.SetBPFFilter(cmd.Opts.Filter); err != nil {
+		src.Close()
+		return nil, err
+	}
 
 	fmt.Fprintf(cmd.Out, "tcpdump: verbose output suppressed, use -v for full protocol decode\nlistening on %s, link-type %d, snapshot length %d bytes\n", cmd.Opts.Device, src.LinkType(), cmd.Opts.SnapshotLength)
 
+	return src, nil
+}
+
+// openFile opens the savefile given with -r.
+func (cmd *cmd) openFile() (packetFile, io.Closer, error) {
+	file, closer, err := openSavefile(cmd.Opts.ReadFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := cmd.Opts.ReadFile
+	if name == "-" {
+		name = "standard input"
+	}
+	fmt.Fprintf(cmd.Out, "reading from file %s, link-type %s\n", name, file.LinkType())
+
+	return file, closer, nil
+}
+
+// capture reads packets from src until it is exhausted, the -c limit is
+// reached or ctx is cancelled. Packets that pass vm, if not nil, are written
+// to sf, if not nil, and printed.
+func (cmd *cmd) capture(ctx context.Context, src gopacket.PacketDataSource, linkType layers.LinkType, vm *bpf.VM, sf *savefile) error {
+	packetSource := gopacket.NewPacketSource(src, linkType)
+	packetSource.NoCopy = true
+	packets := packetSource.PacketsCtx(ctx)
+
 	var (
 		capturedPackets int
 		timeStamp       time.Time
 	)
-	if cmd.Opts.TTTTT {
-		timeStamp = time.Now()
-	}
 
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Fprintf(cmd.Out, "\n%d packets captured\n", capturedPackets)
+			cmd.summary(capturedPackets)
 
 			return nil
-		case packet := <-packetSource.PacketsCtx(ctx):
-			capturedPackets++
-			if cmd.Opts.CountPkg > 0 && capturedPackets >= cmd.Opts.CountPkg {
+		case packet, ok := <-packets:
+			if !ok {
+				cmd.summary(capturedPackets)
+
 				return nil
 			}
 
-			if !cmd.Opts.Count {
+			if vm != nil {
+				if n, err := vm.Run(packet.Data()); err != nil || n == 0 {
+					continue
+				}
+			}
+
+			capturedPackets++
+
+			// -ttt and -ttttt print deltas relative to the first packet,
+			// which has no predecessor.
+			if capturedPackets == 1 {
+				timeStamp = packet.Metadata().Timestamp
+			}
+
+			if sf != nil {
+				if err := sf.WritePacket(packet.Metadata().CaptureInfo, packet.Data()); err != nil {
+					return err
+				}
+			}
+
+			if !cmd.Opts.Count && (sf == nil || cmd.Opts.Print) {
 				pkgTime := cmd.processPacket(packet, capturedPackets, timeStamp)
 
 				if cmd.Opts.TTT {
@@ -219,10 +337,26 @@ func (cmd *cmd) run() error {
 				}
 			}
 
+			if cmd.Opts.CountPkg > 0 && capturedPackets >= cmd.Opts.CountPkg {
+				cmd.summary(capturedPackets)
+
+				return nil
+			}
 		}
 	}
 }
 
+// summary prints the number of packets captured. Like tcpdump, nothing is
+// printed when reading a savefile unless --count was given.
+func (cmd *cmd) summary(capturedPackets int) {
+	switch {
+	case cmd.Opts.ReadFile == "":
+		fmt.Fprintf(cmd.Out, "\n%d packets captured\n", capturedPackets)
+	case cmd.Opts.Count:
+		fmt.Fprintf(cmd.Out, "%d packets\n", capturedPackets)
+	}
+}
+
 // processPacket processes a packet and prints the output to the output writer.
 // A timestamp of the packet is returned.
 func (cmd *cmd) processPacket(packet gopacket.Packet, num int, lastPkgTimeStamp time.Time) time.Time {
@@ -257,10 +391,20 @@ func (cmd *cmd) processPacket(packet gopacket.Packet, num int, lastPkgTimeStamp
 	networkLayer := packet.NetworkLayer()
 
 	if networkLayer == nil {
-		return lastPkgTimeStamp
+		data := cmd.linkData(packet)
+		if data == "" || cmd.Opts.IcmpOnly {
+			return lastPkgTimeStamp
+		}
+
+		pkgTimeStamp := packet.Metadata().Timestamp
+
+		fmt.Fprintf(cmd.Out, "%s%s %s\n", no, cmd.parseTimeStamp(pkgTimeStamp, lastPkgTimeStamp), data)
+		cmd.printContents(packet, nil)
+
+		return pkgTimeStamp
 	}
 
-	etherInfo := cmd.ethernetInfo(ethernetLayer, networkLayer)
+	etherInfo := cmd.ethernetInfo(packet)
 
 	if cmd.Opts.Verbose {
 		switch layer := networkLayer.(type) {
@@ -291,7 +435,7 @@ func (cmd *cmd) processPacket(packet gopacket.Packet, num int, lastPkgTimeStamp
 		dstAddr += "."
 	}
 
-	data := parseICMP(packet)
+	data := parseICMP(packet, cmd.Opts.Verbose)
 
 	if cmd.Opts.IcmpOnly && data == "" {
 		return lastPkgTimeStamp
@@ -309,10 +453,24 @@ func (cmd *cmd) processPacket(packet gopacket.Packet, num int, lastPkgTimeStamp
 	// parse the application layer
 	applicationLayer := packet.ApplicationLayer()
 
-	if applicationLayer != nil && !cmd.Opts.Quiet {
+	if !cmd.Opts.Quiet {
 		switch layer := applicationLayer.(type) {
 		case *layers.DNS:
 			data = dnsData(layer)
+		case *layers.NTP:
+			data = ntpData(layer, cmd.Opts.Verbose)
+		}
+
+		// DHCP is not an application layer to gopacket.
+		if layer, ok := packet.Layer(layers.LayerTypeDHCPv4).(*layers.DHCPv4); ok {
+			data = dhcpData(layer, cmd.Opts.Verbose)
+		}
+		if layer, ok := packet.Layer(layers.LayerTypeDHCPv6).(*layers.DHCPv6); ok {
+			data = dhcp6Data(layer, cmd.Opts.Verbose)
+		}
+
+		if layer, ok := transportLayer.(*layers.UDP); ok && data == "" && (layer.SrcPort == tftpPort || layer.DstPort == tftpPort) {
+			data = tftpData(layer.Payload)
 		}
 	}
 
@@ -351,6 +509,38 @@ func (cmd *cmd) processPacket(packet gopacket.Packet, num int, lastPkgTimeStamp
 		dstPort,
 		data)
 
+	cmd.printContents(packet, applicationLayer)
+
+	return pkgTimeStamp
+}
+
+// linkData returns a string representation of a packet that has no network
+// layer, or an empty string if its protocol is not decoded.
+func (cmd *cmd) linkData(packet gopacket.Packet) string {
+	var data string
+
+	switch {
+	case packet.Layer(layers.LayerTypeARP) != nil:
+		data = arpData(packet.Layer(layers.LayerTypeARP).(*layers.ARP), cmd.Opts.Verbose)
+		// With -e, the ethertype already says this is ARP.
+		if !cmd.Opts.Ether {
+			data = "ARP, " + data
+		}
+	case packet.Layer(layers.LayerTypeLinkLayerDiscovery) != nil:
+		data = lldpData(packet.Layer(layers.LayerTypeLinkLayerDiscovery).(*layers.LinkLayerDiscovery), cmd.Opts.Verbose)
+	default:
+		return ""
+	}
+
+	if cmd.Opts.Ether {
+		data = cmd.ethernetInfo(packet) + " " + data
+	}
+
+	return data
+}
+
+// printContents prints the packet contents requested by -A, -x or -xx.
+func (cmd *cmd) printContents(packet gopacket.Packet, applicationLayer gopacket.ApplicationLayer) {
 	switch {
 	case cmd.Opts.ASCII:
 		content := []byte("")
@@ -363,8 +553,6 @@ func (cmd *cmd) processPacket(packet gopacket.Packet, num int, lastPkgTimeStamp
 	case cmd.Opts.DataWithHeader:
 		fmt.Fprintf(cmd.Out, "%s\n", formatPacketData(packet.Data()))
 	}
-
-	return pkgTimeStamp
 }
 
 func main() {
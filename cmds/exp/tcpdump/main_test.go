@@ -75,6 +75,45 @@ func TestParseFlags(t *testing.T) {
 			args:        []string{"cmd", "-F", "xyz"},
 			expectedErr: true,
 		},
+		{
+			name: "write rotating savefiles",
+			args: []string{"cmd", "-w", "dump.pcapng", "-C", "10", "-W", "5", "--print"},
+			expectedCmd: cmd{
+				Opts: flags{
+					SnapshotLength: 262144,
+					WriteFile:      "dump.pcapng",
+					FileSize:       10,
+					FileCount:      5,
+					Print:          true,
+				},
+			},
+		},
+		{
+			name: "read savefile",
+			args: []string{"cmd", "-r", "dump.pcap", "tcp"},
+			expectedCmd: cmd{
+				Opts: flags{
+					SnapshotLength: 262144,
+					ReadFile:       "dump.pcap",
+					Filter:         "tcp ",
+				},
+			},
+		},
+		{
+			name:        "rotation without savefile",
+			args:        []string{"cmd", "-C", "10"},
+			expectedErr: true,
+		},
+		{
+			name:        "file count without file size",
+			args:        []string{"cmd", "-w", "dump.pcap", "-W", "5"},
+			expectedErr: true,
+		},
+		{
+			name:        "rotation on stdout",
+			args:        []string{"cmd", "-w", "-", "-C", "10"},
+			expectedErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -195,6 +234,51 @@ func TestProcessPacket(t *testing.T) {
 			opts:             flags{DataWithHeader: true, Device: "eth0", Numerical: true},
 			expectedOutput:   "00:00:00.000000 IPv4 eth0 192.168.0.104.53 > 192.168.0.1.53: 4660+ A? www.google.com (32)\n0x0000:  001c 4200 0008 001c 4200 0001 0800 4500 \n0x0010:  003c 1c46 4000 4011 b1e6 c0a8 0068 c0a8 \n0x0020:  0001 0035 0035 0028 917c 1234 0100 0001 \n0x0030:  0000 0000 0000 0377 7777 0667 6f6f 676c \n0x0040:  6503 636f 6d00 0001 0001                \n\n",
 		},
+		{
+			name: "ARP request",
+			packetData: []byte{
+				// Ethernet header
+				0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x1c, 0x42, 0x00, 0x00, 0x01, 0x08, 0x06,
+				// ARP
+				0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01, 0x00, 0x1c, 0x42, 0x00, 0x00, 0x01, 0xc0, 0xa8, 0x00, 0x68,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0xa8, 0x00, 0x01,
+			},
+			num:              5,
+			lastPkgTimeStamp: time.Now(),
+			opts:             flags{Number: true, Device: "eth0", Numerical: true},
+			expectedOutput:   "5  00:00:00.000000 ARP, Request who-has 192.168.0.1 tell 192.168.0.104, length 28\n",
+		},
+		{
+			name: "ARP request with link-level header",
+			packetData: []byte{
+				// Ethernet header
+				0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x1c, 0x42, 0x00, 0x00, 0x01, 0x08, 0x06,
+				// ARP
+				0x00, 0x01, 0x08, 0x00, 0x06, 0x04, 0x00, 0x01, 0x00, 0x1c, 0x42, 0x00, 0x00, 0x01, 0xc0, 0xa8, 0x00, 0x68,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xc0, 0xa8, 0x00, 0x01,
+			},
+			num:              6,
+			lastPkgTimeStamp: time.Now(),
+			opts:             flags{Ether: true, Device: "eth0", Numerical: true},
+			expectedOutput:   "00:00:00.000000 00:1c:42:00:00:01 > Broadcast, ethertype ARP, length 42: Request who-has 192.168.0.1 tell 192.168.0.104, length 28\n",
+		},
+		{
+			name: "IPv4 TFTP read request",
+			packetData: []byte{
+				// Ethernet header
+				0x00, 0x1c, 0x42, 0x00, 0x00, 0x08, 0x00, 0x1c, 0x42, 0x00, 0x00, 0x01, 0x08, 0x00,
+				// IPv4 header
+				0x45, 0x00, 0x00, 0x2d, 0x1c, 0x46, 0x40, 0x00, 0x40, 0x11, 0xb1, 0xe6, 0xc0, 0xa8, 0x00, 0x68, 0xc0, 0xa8, 0x00, 0x01,
+				// UDP header
+				0x08, 0x16, 0x00, 0x45, 0x00, 0x19, 0x00, 0x00,
+				// TFTP
+				0x00, 0x01, 'b', 'o', 'o', 't', '.', 'e', 'f', 'i', 0x00, 'o', 'c', 't', 'e', 't', 0x00,
+			},
+			num:              7,
+			lastPkgTimeStamp: time.Now(),
+			opts:             flags{Device: "eth0", Numerical: true},
+			expectedOutput:   "00:00:00.000000 IPv4 eth0 192.168.0.104.2070 > 192.168.0.1.69: TFTP, length 17, RRQ \"boot.efi\" octet\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,140 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gopacket/gopacket/layers"
+)
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and
+// the Unix epoch (1970).
+const ntpEpochOffset = 2208988800
+
+var ntpModes = map[layers.NTPMode]string{
+	0: "unspecified",
+	1: "symmetric active",
+	2: "symmetric passive",
+	3: "Client",
+	4: "Server",
+	5: "Broadcast",
+	6: "Control Message",
+	7: "Reserved",
+}
+
+var ntpLeapIndicators = map[layers.NTPLeapIndicator]string{
+	0: "",
+	1: "+1s",
+	2: "-1s",
+	3: "clock unsynchronized",
+}
+
+// ntpData returns a string representation of the NTP layer.
+func ntpData(layer *layers.NTP, verbose bool) string {
+	data := fmt.Sprintf("NTPv%d, %s, length %d", layer.Version, ntpModes[layer.Mode&7], len(layer.Contents))
+
+	// Only time messages are decoded further.
+	if !verbose || layer.Mode < 1 || layer.Mode > 5 {
+		return data
+	}
+
+	var stratum string
+	switch {
+	case layer.Stratum == 0:
+		stratum = "unspecified"
+	case layer.Stratum == 1:
+		stratum = "primary reference"
+	case layer.Stratum <= 15:
+		stratum = "secondary reference"
+	default:
+		stratum = "reserved"
+	}
+
+	var b strings.Builder
+	b.WriteString(data)
+
+	fmt.Fprintf(&b, "\n\tLeap indicator: %s (%d), Stratum %d (%s), poll %d (%gs), precision %d",
+		ntpLeapIndicators[layer.LeapIndicator&3], layer.LeapIndicator, layer.Stratum, stratum,
+		layer.Poll, math.Ldexp(1, int(layer.Poll)), layer.Precision)
+
+	fmt.Fprintf(&b, "\n\tRoot Delay: %s, Root dispersion: %s, Reference-ID: %s",
+		ntpShortTime(uint32(layer.RootDelay)), ntpShortTime(uint32(layer.RootDispersion)), ntpReferenceID(layer))
+
+	fmt.Fprintf(&b, "\n\t  Reference Timestamp:  %s", ntpTime(uint64(layer.ReferenceTimestamp)))
+	fmt.Fprintf(&b, "\n\t  Originator Timestamp: %s", ntpTime(uint64(layer.OriginTimestamp)))
+	fmt.Fprintf(&b, "\n\t  Receive Timestamp:    %s", ntpTime(uint64(layer.ReceiveTimestamp)))
+	fmt.Fprintf(&b, "\n\t  Transmit Timestamp:   %s", ntpTime(uint64(layer.TransmitTimestamp)))
+
+	fmt.Fprintf(&b, "\n\t    Originator - Receive Timestamp:  %s", ntpDelta(uint64(layer.OriginTimestamp), uint64(layer.ReceiveTimestamp)))
+	fmt.Fprintf(&b, "\n\t    Originator - Transmit Timestamp: %s", ntpDelta(uint64(layer.OriginTimestamp), uint64(layer.TransmitTimestamp)))
+
+	return b.String()
+}
+
+// ntpReferenceID returns the reference ID, which is a clock name for primary
+// servers and the address of the upstream server otherwise.
+func ntpReferenceID(layer *layers.NTP) string {
+	var id [4]byte
+	binary.BigEndian.PutUint32(id[:], uint32(layer.ReferenceID))
+
+	switch layer.Stratum {
+	case 0:
+		return "(unspec)"
+	case 1:
+		return strings.TrimRight(string(id[:]), "\x00")
+	default:
+		return net.IP(id[:]).String()
+	}
+}
+
+// ntpShortTime formats a 16.16 fixed point number of seconds.
+func ntpShortTime(v uint32) string {
+	return fmt.Sprintf("%d.%06d", v>>16, uint64(v&0xffff)*1e6>>16)
+}
+
+// ntpFraction converts the fractional part of a 32.32 fixed point timestamp
+// to nanoseconds.
+func ntpFraction(v uint64) uint64 {
+	return (v & 0xffffffff) * 1e9 >> 32
+}
+
+// ntpTime formats a 32.32 fixed point NTP timestamp, followed by the UTC time
+// if it is set.
+func ntpTime(ts uint64) string {
+	s := fmt.Sprintf("%d.%09d", ts>>32, ntpFraction(ts))
+	if ts>>32 == 0 {
+		return s
+	}
+
+	secs := int64(ts>>32) - ntpEpochOffset
+	// Timestamps before 1970 belong to the next NTP era, which starts in 2036.
+	if secs < 0 {
+		secs += 1 << 32
+	}
+
+	return s + time.Unix(secs, 0).UTC().Format(" (2006-01-02T15:04:05Z)")
+}
+
+// ntpDelta formats the difference between two NTP timestamps. If the origin
+// is not set, ts is formatted instead.
+func ntpDelta(origin, ts uint64) string {
+	if origin == 0 {
+		return ntpTime(ts)
+	}
+
+	sign, delta := "+", ts-origin
+	if ts < origin {
+		sign, delta = "-", origin-ts
+	}
+
+	return fmt.Sprintf("%s%d.%09d", sign, delta>>32, ntpFraction(delta))
+}
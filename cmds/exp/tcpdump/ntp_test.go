@@ -0,0 +1,88 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+)
+
+func TestNtpData(t *testing.T) {
+	request := []byte{
+		0x23, 0x00, 0x06, 0xe9, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xe8, 0xfe, 0x6f, 0x80, 0x80, 0x00, 0x00, 0x00,
+	}
+
+	response := []byte{
+		0x24, 0x02, 0x06, 0xe9, 0x00, 0x00, 0x08, 0x00, 0x00, 0x00, 0x04, 0x00, 0xc0, 0xa8, 0x00, 0x01,
+		// Reference timestamp
+		0xe8, 0xfe, 0x6f, 0x00, 0x00, 0x00, 0x00, 0x00,
+		// Originate timestamp
+		0xe8, 0xfe, 0x6f, 0x80, 0x80, 0x00, 0x00, 0x00,
+		// Receive timestamp
+		0xe8, 0xfe, 0x6f, 0x80, 0xc0, 0x00, 0x00, 0x00,
+		// Transmit timestamp
+		0xe8, 0xfe, 0x6f, 0x81, 0x00, 0x00, 0x00, 0x00,
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		verbose  bool
+		expected string
+	}{
+		{
+			name:     "Client request",
+			data:     request,
+			expected: "NTPv4, Client, length 48",
+		},
+		{
+			name:    "Client request verbose",
+			data:    request,
+			verbose: true,
+			expected: "NTPv4, Client, length 48" +
+				"\n\tLeap indicator:  (0), Stratum 0 (unspecified), poll 6 (64s), precision -23" +
+				"\n\tRoot Delay: 0.000000, Root dispersion: 0.000000, Reference-ID: (unspec)" +
+				"\n\t  Reference Timestamp:  0.000000000" +
+				"\n\t  Originator Timestamp: 0.000000000" +
+				"\n\t  Receive Timestamp:    0.000000000" +
+				"\n\t  Transmit Timestamp:   3908988800.500000000 (2023-11-14T22:13:20Z)" +
+				"\n\t    Originator - Receive Timestamp:  0.000000000" +
+				"\n\t    Originator - Transmit Timestamp: 3908988800.500000000 (2023-11-14T22:13:20Z)",
+		},
+		{
+			name:    "Server response verbose",
+			data:    response,
+			verbose: true,
+			expected: "NTPv4, Server, length 48" +
+				"\n\tLeap indicator:  (0), Stratum 2 (secondary reference), poll 6 (64s), precision -23" +
+				"\n\tRoot Delay: 0.031250, Root dispersion: 0.015625, Reference-ID: 192.168.0.1" +
+				"\n\t  Reference Timestamp:  3908988672.000000000 (2023-11-14T22:11:12Z)" +
+				"\n\t  Originator Timestamp: 3908988800.500000000 (2023-11-14T22:13:20Z)" +
+				"\n\t  Receive Timestamp:    3908988800.750000000 (2023-11-14T22:13:20Z)" +
+				"\n\t  Transmit Timestamp:   3908988801.000000000 (2023-11-14T22:13:21Z)" +
+				"\n\t    Originator - Receive Timestamp:  +0.250000000" +
+				"\n\t    Originator - Transmit Timestamp: +0.500000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet := gopacket.NewPacket(tt.data, layers.LayerTypeNTP, gopacket.Default)
+			layer, ok := packet.Layer(layers.LayerTypeNTP).(*layers.NTP)
+			if !ok {
+				t.Fatalf("packet has no NTP layer: %v", packet.ErrorLayer())
+			}
+
+			if got := ntpData(layer, tt.verbose); got != tt.expected {
+				t.Errorf("ntpData() =\n%s\nwant\n%s", got, tt.expected)
+			}
+		})
+	}
+}
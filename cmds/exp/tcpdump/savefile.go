@@ -0,0 +1,246 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/gopacket/gopacket/pcapgo"
+	"github.com/packetcap/go-pcap/filter"
+	"golang.org/x/net/bpf"
+)
+
+// pcapngMagic is the block type of the section header block that starts
+// every pcapng file.
+var pcapngMagic = []byte{0x0a, 0x0d, 0x0d, 0x0a}
+
+// packetFile is a savefile opened for reading.
+type packetFile interface {
+	gopacket.PacketDataSource
+	LinkType() layers.LinkType
+}
+
+// openSavefile opens the pcap or pcapng savefile name for reading. A name of
+// "-" reads from stdin.
+func openSavefile(name string) (packetFile, io.Closer, error) {
+	var f *os.File
+	if name == "-" {
+		f = os.Stdin
+	} else {
+		var err error
+		if f, err = os.Open(name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	r := bufio.NewReader(f)
+	magic, err := r.Peek(len(pcapngMagic))
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	var pf packetFile
+	if bytes.Equal(magic, pcapngMagic) {
+		pf, err = pcapgo.NewNgReader(r, pcapgo.DefaultNgReaderOptions)
+	} else {
+		pf, err = pcapgo.NewReader(r)
+	}
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return pf, f, nil
+}
+
+// packetWriter writes packets in either pcap or pcapng format.
+type packetWriter interface {
+	WritePacket(ci gopacket.CaptureInfo, data []byte) error
+}
+
+// countingWriter counts the bytes written to the underlying writer, so that
+// savefiles can be rotated by size.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// savefile writes captured packets to one or more files, rotating them like
+// tcpdump's -C and -W flags.
+type savefile struct {
+	name     string
+	pcapng   bool
+	nanos    bool
+	snaplen  uint32
+	linkType layers.LinkType
+	device   string
+
+	// fileSize is the size in bytes after which a new file is started; 0
+	// means no rotation. With fileCount > 0, at most fileCount files are
+	// used as a ring buffer.
+	fileSize  int64
+	fileCount int
+
+	stdout  io.Writer
+	index   int
+	packets int
+	f       io.WriteCloser
+	cw      *countingWriter
+	w       packetWriter
+}
+
+// newSavefile creates a savefile and opens the first file. Names ending in
+// .pcapng are written in pcapng format, everything else in pcap format. A
+// name of "-" writes to stdout.
+func newSavefile(opts flags, stdout io.Writer, linkType layers.LinkType) (*savefile, error) {
+	s := &savefile{
+		name:      opts.WriteFile,
+		pcapng:    strings.HasSuffix(opts.WriteFile, ".pcapng"),
+		nanos:     opts.TimeStampInNanoSeconds,
+		snaplen:   uint32(opts.SnapshotLength),
+		linkType:  linkType,
+		device:    opts.Device,
+		fileSize:  int64(opts.FileSize) * 1000 * 1000,
+		fileCount: opts.FileCount,
+		stdout:    stdout,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// fileName returns the name of the index'th file. Like tcpdump, the first
+// file carries no number unless a ring buffer is used.
+func (s *savefile) fileName(index int) string {
+	if s.fileCount > 0 {
+		width := len(strconv.Itoa(s.fileCount - 1))
+		return fmt.Sprintf("%s%0*d", s.name, width, index%s.fileCount)
+	}
+	if index == 0 {
+		return s.name
+	}
+	return s.name + strconv.Itoa(index)
+}
+
+func (s *savefile) open() error {
+	if s.name == "-" {
+		s.f = nopCloser{s.stdout}
+	} else {
+		f, err := os.Create(s.fileName(s.index))
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+	s.cw = &countingWriter{w: s.f}
+	s.packets = 0
+
+	if s.pcapng {
+		intf := pcapgo.DefaultNgInterface
+		intf.Name = s.device
+		intf.LinkType = s.linkType
+		intf.SnapLength = s.snaplen
+		w, err := pcapgo.NewNgWriterInterface(s.cw, intf, pcapgo.DefaultNgWriterOptions)
+		if err != nil {
+			s.f.Close()
+			return err
+		}
+		s.w = w
+		return nil
+	}
+
+	w := pcapgo.NewWriter(s.cw)
+	if s.nanos {
+		w = pcapgo.NewWriterNanos(s.cw)
+	}
+	if err := w.WriteFileHeader(s.snaplen, s.linkType); err != nil {
+		s.f.Close()
+		return err
+	}
+	s.w = w
+	return nil
+}
+
+// WritePacket writes a packet, starting the next file first if the current
+// one has reached the size limit. Every file holds at least one packet.
+func (s *savefile) WritePacket(ci gopacket.CaptureInfo, data []byte) error {
+	if s.fileSize > 0 && s.packets > 0 && s.cw.n >= s.fileSize {
+		if err := s.Close(); err != nil {
+			return err
+		}
+		s.index++
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.w.WritePacket(ci, data); err != nil {
+		return err
+	}
+	s.packets++
+	// The pcapng writer is buffered. Flush it so that the size check above
+	// sees every packet and an interrupted capture leaves a usable file.
+	if ng, ok := s.w.(*pcapgo.NgWriter); ok {
+		return ng.Flush()
+	}
+	return nil
+}
+
+// Close flushes and closes the current file.
+func (s *savefile) Close() error {
+	var err error
+	if ng, ok := s.w.(*pcapgo.NgWriter); ok {
+		err = ng.Flush()
+	}
+	return errors.Join(err, s.f.Close())
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// compileFilter compiles a tcpdump filter expression into a BPF program that
+// is run in userspace, for packets of linkType that do not come from a live
+// capture. It returns nil for an empty expression. The program assumes
+// Ethernet frames, so other link types cannot be filtered.
+func compileFilter(expr string, linkType layers.LinkType) (*bpf.VM, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+	if linkType != layers.LinkTypeEthernet {
+		return nil, fmt.Errorf("filters are only supported on Ethernet, not on link-type %s", linkType)
+	}
+	e := filter.NewExpression(expr)
+	if e == nil {
+		return nil, fmt.Errorf("no expression received for filter '%s'", expr)
+	}
+	instructions, err := e.Compile().Compile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile filter into instructions: %w", err)
+	}
+	return bpf.NewVM(instructions)
+}
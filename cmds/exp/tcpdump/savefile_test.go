@@ -0,0 +1,274 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gopacket/gopacket"
+	"github.com/gopacket/gopacket/layers"
+	"github.com/gopacket/gopacket/pcapgo"
+)
+
+// testdata/trace.pcap and testdata/trace.pcapng hold the same four packets:
+// an ICMP echo request and reply, a DNS query and a TCP SYN.
+var traces = []string{"testdata/trace.pcap", "testdata/trace.pcapng"}
+
+// readAll reads all packets of the savefile name.
+func readAll(t *testing.T, name string) ([][]byte, []gopacket.CaptureInfo) {
+	t.Helper()
+
+	file, closer, err := openSavefile(name)
+	if err != nil {
+		t.Fatalf("openSavefile(%q) = %v", name, err)
+	}
+	defer closer.Close()
+
+	if file.LinkType() != layers.LinkTypeEthernet {
+		t.Errorf("LinkType() = %v, want %v", file.LinkType(), layers.LinkTypeEthernet)
+	}
+
+	var (
+		data [][]byte
+		cis  []gopacket.CaptureInfo
+	)
+	for {
+		d, ci, err := file.ReadPacketData()
+		if err == io.EOF {
+			return data, cis
+		}
+		if err != nil {
+			t.Fatalf("ReadPacketData() = %v", err)
+		}
+		data = append(data, d)
+		cis = append(cis, ci)
+	}
+}
+
+func TestReadSavefile(t *testing.T) {
+	tests := []struct {
+		name string
+		opts flags
+		want string
+	}{
+		{
+			name: "default",
+			opts: flags{Numerical: true},
+			want: `22:13:20.123456 IPv4  192.168.0.104. > 192.168.0.1.: ICMP EchoRequest, id 1, seq 1, length 16
+22:13:20.123706 IPv4  192.168.0.1. > 192.168.0.104.: ICMP EchoReply, id 1, seq 1, length 16
+22:13:21.123956 IPv4  192.168.0.104.40000 > 192.168.0.1.53: 4660+ A? u-root.org (28)
+22:13:22.124456 IPv4  192.168.0.104.40001 > 192.168.0.1.80: Flags [S], seq 1000, ack 0, win 64240, options [], length 0
+`,
+		},
+		{
+			name: "filter",
+			opts: flags{Numerical: true, TT: true, Filter: "udp port 53 "},
+			want: "1700000001 IPv4  192.168.0.104.40000 > 192.168.0.1.53: 4660+ A? u-root.org (28)\n",
+		},
+		{
+			name: "count limit",
+			opts: flags{Numerical: true, Number: true, Quiet: true, CountPkg: 2, TTT: true},
+			want: `1  00:00:00.000000 IPv4  192.168.0.104. > 192.168.0.1.: ICMP EchoRequest, id 1, seq 1, length 16
+2  00:00:00.000250 IPv4  192.168.0.1. > 192.168.0.104.: ICMP EchoReply, id 1, seq 1, length 16
+`,
+		},
+		{
+			name: "delta since first packet",
+			opts: flags{Numerical: true, Quiet: true, TTTTT: true, Filter: "tcp or udp"},
+			want: `00:00:00.000000 IPv4  192.168.0.104.40000 > 192.168.0.1.53: UDP, length 28
+00:00:01.000500 IPv4  192.168.0.104.40001 > 192.168.0.1.80: TCP, length 0
+`,
+		},
+		{
+			name: "count",
+			opts: flags{Count: true, Filter: "not tcp"},
+			want: "3 packets\n",
+		},
+	}
+
+	for _, trace := range traces {
+		for _, tt := range tests {
+			t.Run(filepath.Base(trace)+"/"+tt.name, func(t *testing.T) {
+				var out bytes.Buffer
+				cmd := cmd{Out: &out, Opts: tt.opts}
+				cmd.Opts.ReadFile = trace
+
+				if err := cmd.run(); err != nil {
+					t.Fatalf("run() = %v", err)
+				}
+
+				want := "reading from file " + trace + ", link-type Ethernet\n" + tt.want
+				if diff := cmp.Diff(want, out.String()); diff != "" {
+					t.Errorf("run() mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestReadSavefileErrors(t *testing.T) {
+	dir := t.TempDir()
+	garbage := filepath.Join(dir, "garbage")
+	if err := os.WriteFile(garbage, []byte("this is not a capture file"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Filters assume Ethernet frames.
+	raw := filepath.Join(dir, "raw.pcap")
+	f, err := os.Create(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pcapgo.NewWriter(f).WriteFileHeader(65535, layers.LinkTypeRaw); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	for _, opts := range []flags{
+		{ReadFile: filepath.Join(dir, "missing")},
+		{ReadFile: garbage},
+		{ReadFile: traces[0], Filter: "bogus expression"},
+		{ReadFile: raw, Filter: "udp"},
+	} {
+		cmd := cmd{Out: io.Discard, Opts: opts}
+		if err := cmd.run(); err == nil {
+			t.Errorf("run(%+v) = nil, want error", opts)
+		}
+	}
+}
+
+func TestWriteSavefileCloseError(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip(err)
+	}
+	// Without packets, the pcapng header is only written when the file
+	// is closed, which fails on /dev/full.
+	path := filepath.Join(t.TempDir(), "out.pcapng")
+	if err := os.Symlink("/dev/full", path); err != nil {
+		t.Fatal(err)
+	}
+	cmd := cmd{Out: io.Discard, Opts: flags{ReadFile: traces[0], WriteFile: path, Filter: "udp port 9", SnapshotLength: 262144}}
+	if err := cmd.run(); err == nil {
+		t.Errorf("run() writing to /dev/full = nil, want error")
+	}
+}
+
+func TestWriteSavefile(t *testing.T) {
+	want, wantCIs := readAll(t, traces[0])
+
+	for _, name := range []string{"out.pcap", "out.pcapng"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+
+			var out bytes.Buffer
+			cmd := cmd{Out: &out, Opts: flags{ReadFile: traces[0], WriteFile: path, SnapshotLength: 262144}}
+			if err := cmd.run(); err != nil {
+				t.Fatalf("run() = %v", err)
+			}
+
+			// Without --print, the packets are only written.
+			if got, want := out.String(), "reading from file "+traces[0]+", link-type Ethernet\n"; got != want {
+				t.Errorf("run() printed %q, want %q", got, want)
+			}
+
+			got, gotCIs := readAll(t, path)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("packet data mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(wantCIs, gotCIs, cmp.Comparer(func(a, b gopacket.CaptureInfo) bool {
+				return a.Timestamp.Equal(b.Timestamp) && a.Length == b.Length && a.CaptureLength == b.CaptureLength
+			})); diff != "" {
+				t.Errorf("capture info mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSavefileRotation(t *testing.T) {
+	data, cis := readAll(t, traces[0])
+
+	tests := []struct {
+		name      string
+		fileCount int
+		// want lists the number of packets in each file.
+		want map[string]int
+	}{
+		{
+			name: "unlimited",
+			want: map[string]int{"trace.pcap": 1, "trace.pcap1": 1, "trace.pcap2": 1, "trace.pcap3": 1},
+		},
+		{
+			name:      "ring buffer",
+			fileCount: 3,
+			want:      map[string]int{"trace.pcap0": 1, "trace.pcap1": 1, "trace.pcap2": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			sf, err := newSavefile(flags{WriteFile: filepath.Join(dir, "trace.pcap"), SnapshotLength: 262144, FileSize: 1, FileCount: tt.fileCount}, nil, layers.LinkTypeEthernet)
+			if err != nil {
+				t.Fatalf("newSavefile() = %v", err)
+			}
+			// Rotate after every packet.
+			sf.fileSize = 1
+
+			for i := range data {
+				if err := sf.WritePacket(cis[i], data[i]); err != nil {
+					t.Fatalf("WritePacket() = %v", err)
+				}
+			}
+			if err := sf.Close(); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string]int{}
+			for _, e := range entries {
+				packets, _ := readAll(t, filepath.Join(dir, e.Name()))
+				got[e.Name()] = len(packets)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("files mismatch (-want +got):\n%s", diff)
+			}
+
+			// The ring buffer wraps around, so the oldest file holds the last packet.
+			if tt.fileCount > 0 {
+				packets, _ := readAll(t, filepath.Join(dir, "trace.pcap0"))
+				if !bytes.Equal(packets[0], data[len(data)-1]) {
+					t.Errorf("trace.pcap0 does not hold the last packet")
+				}
+			}
+		})
+	}
+}
+
+func TestSavefileName(t *testing.T) {
+	for _, tt := range []struct {
+		fileCount int
+		index     int
+		want      string
+	}{
+		{index: 0, want: "dump"},
+		{index: 12, want: "dump12"},
+		{fileCount: 5, index: 0, want: "dump0"},
+		{fileCount: 5, index: 7, want: "dump2"},
+		{fileCount: 12, index: 3, want: "dump03"},
+	} {
+		s := &savefile{name: "dump", fileCount: tt.fileCount}
+		if got := s.fileName(tt.index); got != tt.want {
+			t.Errorf("fileName(%d) with -W %d = %q, want %q", tt.index, tt.fileCount, got, tt.want)
+		}
+	}
+}
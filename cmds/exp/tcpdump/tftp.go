@@ -0,0 +1,117 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// tftpPort is the well-known TFTP port. Only requests, and whatever is sent
+// to or from this port, can be recognized as TFTP: the transfer itself uses
+// ephemeral ports.
+const tftpPort = 69
+
+var tftpOpcodes = map[uint16]string{
+	1: "RRQ",
+	2: "WRQ",
+	3: "DATA",
+	4: "ACK",
+	5: "ERROR",
+	6: "OACK",
+}
+
+var tftpErrors = map[uint16]string{
+	0: "EUNDEF",
+	1: "ENOTFOUND",
+	2: "EACCESS",
+	3: "ENOSPACE",
+	4: "EBADOP",
+	5: "EBADID",
+	6: "EEXISTS",
+	7: "ENOUSER",
+	8: "EOPTNEG",
+}
+
+// tftpData returns a string representation of a TFTP packet.
+func tftpData(payload []byte) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "TFTP, length %d", len(payload))
+
+	if len(payload) < 2 {
+		b.WriteString(" [|tftp]")
+		return b.String()
+	}
+
+	opcode := binary.BigEndian.Uint16(payload)
+	name, ok := tftpOpcodes[opcode]
+	if !ok {
+		name = fmt.Sprintf("tftp-#%d", opcode)
+	}
+	fmt.Fprintf(&b, ", %s", name)
+
+	data := payload[2:]
+
+	switch opcode {
+	case 1, 2: // RRQ, WRQ
+		fields := tftpStrings(data)
+		if len(fields) == 0 {
+			b.WriteString(" [|tftp]")
+			break
+		}
+		fmt.Fprintf(&b, " %q", fields[0])
+		for _, field := range fields[1:] {
+			fmt.Fprintf(&b, " %s", field)
+		}
+	case 6: // OACK
+		for _, field := range tftpStrings(data) {
+			fmt.Fprintf(&b, " %s", field)
+		}
+	case 3, 4: // DATA, ACK
+		if len(data) < 2 {
+			b.WriteString(" [|tftp]")
+			break
+		}
+		fmt.Fprintf(&b, " block %d", binary.BigEndian.Uint16(data))
+	case 5: // ERROR
+		if len(data) < 2 {
+			b.WriteString(" [|tftp]")
+			break
+		}
+		code := binary.BigEndian.Uint16(data)
+		if name, ok := tftpErrors[code]; ok {
+			fmt.Fprintf(&b, " %s", name)
+		} else {
+			fmt.Fprintf(&b, " tftp-err-#%d", code)
+		}
+		var msg string
+		if fields := tftpStrings(data[2:]); len(fields) > 0 {
+			msg = fields[0]
+		}
+		fmt.Fprintf(&b, " %q", msg)
+	}
+
+	return b.String()
+}
+
+// tftpStrings splits data into its NUL-terminated strings. An unterminated
+// trailing string is included as well.
+func tftpStrings(data []byte) []string {
+	var fields []string
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, 0)
+		if i < 0 {
+			fields = append(fields, string(data))
+			break
+		}
+		fields = append(fields, string(data[:i]))
+		data = data[i+1:]
+	}
+	return fields
+}
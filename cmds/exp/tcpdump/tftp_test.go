@@ -0,0 +1,62 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"testing"
+)
+
+func TestTftpData(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  []byte
+		expected string
+	}{
+		{
+			name:     "Read request with options",
+			payload:  []byte("\x00\x01pxelinux.0\x00octet\x00tsize\x000\x00blksize\x001468\x00"),
+			expected: `TFTP, length 40, RRQ "pxelinux.0" octet tsize 0 blksize 1468`,
+		},
+		{
+			name:     "Option acknowledgment",
+			payload:  []byte("\x00\x06tsize\x0026758\x00blksize\x001468\x00"),
+			expected: "TFTP, length 27, OACK tsize 26758 blksize 1468",
+		},
+		{
+			name:     "Data",
+			payload:  append([]byte{0x00, 0x03, 0x00, 0x01}, make([]byte, 512)...),
+			expected: "TFTP, length 516, DATA block 1",
+		},
+		{
+			name:     "Ack",
+			payload:  []byte{0x00, 0x04, 0x00, 0x01},
+			expected: "TFTP, length 4, ACK block 1",
+		},
+		{
+			name:     "Error",
+			payload:  []byte("\x00\x05\x00\x01File not found\x00"),
+			expected: `TFTP, length 19, ERROR ENOTFOUND "File not found"`,
+		},
+		{
+			name:     "Unknown opcode",
+			payload:  []byte{0x00, 0x09},
+			expected: "TFTP, length 2, tftp-#9",
+		},
+		{
+			name:     "Truncated",
+			payload:  []byte{0x00},
+			expected: "TFTP, length 1 [|tftp]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tftpData(tt.payload); got != tt.expected {
+				t.Errorf("tftpData() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -46,7 +46,7 @@ func (cmd *cmd) parseTimeStamp(currentTimestamp, lastTimeStamp time.Time) string
 	if cmd.Opts.TT {
 		return fmt.Sprintf("%d", currentTimestamp.Unix())
 	}
-	if cmd.Opts.TTT {
+	if cmd.Opts.TTT || cmd.Opts.TTTTT {
 		diff := currentTimestamp.Sub(lastTimeStamp)
 		if cmd.Opts.TimeStampInNanoSeconds {
 			return fmt.Sprintf("%02d:%02d:%02d.%09d", int(diff.Hours()), int(diff.Minutes())%60, int(diff.Seconds())%60, diff.Nanoseconds()%1e9)
@@ -35,6 +35,13 @@
 //	  linux_spi:dev=/dev/spidev0.0,spispeed=5000
 //	    Set the SPI controller's speed. The frequency is in kilohertz.
 //
+//	internal
+//	  Use the SPI controller of the chipset, found on the PCI bus and
+//	  accessed through /dev/mem. Intel PCH 100 series and later (hardware
+//	  sequencing) and AMD SPI100 are supported. The lock state of the
+//	  controller is printed; regions and ranges it protects cannot be
+//	  written.
+//
 // Description:
 //
 //	flash is u-root's implementation of flashrom. It has a very limited
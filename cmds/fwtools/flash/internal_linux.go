@@ -0,0 +1,91 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+//go:build !tinygo || tinygo.enable
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/u-root/u-root/pkg/flash/chipset"
+)
+
+// device is a flash behind a chipset SPI controller.
+type device interface {
+	io.ReaderAt
+	io.WriterAt
+	EraseAt(int64, int64) (int64, error)
+	Size() int64
+}
+
+type internalProgrammer struct {
+	device
+	mmio *chipset.MMIO
+}
+
+func (p *internalProgrammer) Close() error {
+	return p.mmio.Close()
+}
+
+func init() {
+	supportedProgrammers["internal"] = func(params programmerParams) (programmer, error) {
+		if len(params) != 0 {
+			return nil, fmt.Errorf("unrecognized parameters: %v", params)
+		}
+
+		c, err := chipset.Find()
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Found %v SPI controller at %s, SPIBAR %#x", c.Kind, c.Dev.Addr, c.SPIBAR)
+
+		mmio, err := chipset.NewMMIO(int64(c.SPIBAR))
+		if err != nil {
+			return nil, err
+		}
+		d, err := openInternal(c, mmio)
+		if err != nil {
+			mmio.Close()
+			return nil, err
+		}
+		return &internalProgrammer{device: d, mmio: mmio}, nil
+	}
+}
+
+// openInternal identifies the flash behind controller c and reports how
+// the chipset protects it.
+func openInternal(c *chipset.Controller, mmio *chipset.MMIO) (device, error) {
+	switch c.Kind {
+	case chipset.IntelPCH:
+		i, err := chipset.NewIntel(mmio)
+		if err != nil {
+			return nil, err
+		}
+		prot, err := i.Protection()
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Flash %#06x (%s), %#x bytes", i.Chip.ID, i.Chip.Chip, i.Size())
+		log.Print(prot)
+		return i, nil
+	case chipset.AMDSPI100:
+		a := chipset.NewAMD(mmio)
+		locked, err := a.Locked()
+		if err != nil {
+			return nil, err
+		}
+		if locked {
+			log.Printf("Warning: the chipset blocks host access to the flash")
+		}
+		f, err := a.Flash()
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Flash %#06x (%s), %#x bytes", f.Chip.ID, f.Chip.Chip, f.Size())
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported controller %v", c.Kind)
+}
@@ -4,13 +4,14 @@
 
 // Package bls parses systemd Boot Loader Spec config files.
 //
-// See spec at https://systemd.io/BOOT_LOADER_SPECIFICATION. Only Type #1 BLS
-// entries are supported at the moment, while Type #2 EFI entries are left
-// unimplemented awaiting EFI boot support in u-root/LinuxBoot.
+// See spec at https://systemd.io/BOOT_LOADER_SPECIFICATION. Type #1 BLS
+// entries are supported, as are Type #2 entries, i.e. Unified Kernel Images in
+// EFI/Linux. EFI programs other than UKIs cannot be booted.
 //
 // This package also supports the systemd-boot loader.conf as described in
 // https://www.freedesktop.org/software/systemd/man/loader.conf.html. Only the
-// "default" keyword is implemented.
+// "default" keyword is implemented. Boot counting as described in
+// https://systemd.io/AUTOMATIC_BOOT_ASSESSMENT/ is supported, see CountBoot.
 package bls
 
 import (
@@ -24,12 +25,15 @@ import (
 	"strings"
 
 	"github.com/u-root/u-root/pkg/boot"
+	"github.com/u-root/u-root/pkg/boot/uki"
 	"github.com/u-root/u-root/pkg/ulog"
 )
 
 const (
 	blsEntriesDir  = "loader/entries"
 	blsEntriesDir2 = "boot/loader/entries"
+	ukiEntriesDir  = "EFI/Linux"
+	ukiSuffix      = ".efi"
 	// Set a higher default rank for BLS. It should be booted prior to the
 	// other local images.
 	blsDefaultRank = 1
@@ -64,8 +68,9 @@ func ScanBLSEntries(l ulog.Logger, fsRoot string, variables map[string]string, g
 	// TODO: Rank entries by version or machine-id attribute as suggested
 	// in the spec (but not mandated, surprisingly).
 	imgs := make(map[string]boot.OSImage)
+	bad := make(map[string]bool)
 	for _, f := range files {
-		identifier := strings.TrimSuffix(filepath.Base(f), ".conf")
+		identifier := entryID(filepath.Base(f))
 
 		// If the config file name is the same as the Grub default option, pass true for grubDefaultFlag
 		var img boot.OSImage
@@ -79,14 +84,73 @@ func ScanBLSEntries(l ulog.Logger, fsRoot string, variables map[string]string, g
 			l.Printf("BootLoaderSpec skipping entry %s: %v", f, err)
 			continue
 		}
+		if li, ok := img.(*boot.LinuxImage); ok {
+			li.BLSEntry = f
+		}
 		imgs[identifier] = img
+		bad[identifier] = isBad(filepath.Base(f))
 	}
 
-	return sortImages(loaderConf, imgs), nil
+	return sortImages(loaderConf, imgs, bad), nil
 }
 
-func sortImages(loaderConf map[string]string, imgs map[string]boot.OSImage) []boot.OSImage {
-	// rankedImages = sort(default-images) + sort(remaining images)
+// ScanUKIEntries scans the ESP or XBOOTLDR partition mounted at fsRoot for
+// Type #2 entries, i.e. Unified Kernel Images in EFI/Linux. Like
+// ScanBLSEntries, it skips over entries that cannot be read.
+func ScanUKIEntries(l ulog.Logger, fsRoot string) ([]boot.OSImage, error) {
+	dir := filepath.Join(fsRoot, ukiEntriesDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("no BootLoaderSpec Type #2 entries found: %w", err)
+	}
+
+	imgs := make(map[string]boot.OSImage)
+	bad := make(map[string]bool)
+	for _, e := range entries {
+		// The ESP is FAT, so the suffix may come in any case.
+		name := e.Name()
+		if !e.Type().IsRegular() || len(name) <= len(ukiSuffix) || !strings.EqualFold(name[len(name)-len(ukiSuffix):], ukiSuffix) {
+			continue
+		}
+		img, err := parseUKI(filepath.Join(dir, name))
+		if err != nil {
+			l.Printf("BootLoaderSpec skipping entry %s: %v", name, err)
+			continue
+		}
+		img.BLSEntry = filepath.Join(dir, name)
+		imgs[entryID(name)] = img
+		bad[entryID(name)] = isBad(name)
+	}
+	if len(imgs) == 0 {
+		return nil, fmt.Errorf("no BootLoaderSpec Type #2 entries found in %s", dir)
+	}
+
+	loaderConf, err := parseConf(filepath.Join(fsRoot, "loader", "loader.conf"))
+	if err != nil {
+		loaderConf = make(map[string]string)
+	}
+	return sortImages(loaderConf, imgs, bad), nil
+}
+
+// parseUKI opens the Unified Kernel Image at path.
+func parseUKI(path string) (*boot.LinuxImage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	img, err := uki.New(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	img.BootRank = bootRank(false)
+	return img, nil
+}
+
+// sortImages sorts images by identifier, the loader.conf default first and
+// entries without boot tries left last.
+func sortImages(loaderConf map[string]string, imgs map[string]boot.OSImage, bad map[string]bool) []boot.OSImage {
+	// rankedImages = sort(default-images) + sort(remaining images) + sort(bad images)
 	var rankedImages []boot.OSImage
 
 	pattern, ok := loaderConf["default"]
@@ -97,11 +161,14 @@ func sortImages(loaderConf map[string]string, imgs map[string]boot.OSImage) []bo
 
 	var defaultIdents []string
 	var otherIdents []string
+	var badIdents []string
 
-	// Find default and non-default identifiers.
+	// Find default, non-default and bad identifiers.
 	for ident := range imgs {
 		ok, err := filepath.Match(pattern, ident)
-		if err != nil && ok {
+		if bad[ident] {
+			badIdents = append(badIdents, ident)
+		} else if err != nil && ok {
 			defaultIdents = append(defaultIdents, ident)
 		} else {
 			otherIdents = append(otherIdents, ident)
@@ -111,6 +178,7 @@ func sortImages(loaderConf map[string]string, imgs map[string]boot.OSImage) []bo
 	// Sort them in the order we want them.
 	sort.Sort(sort.Reverse(sort.StringSlice(defaultIdents)))
 	sort.Sort(sort.Reverse(sort.StringSlice(otherIdents)))
+	sort.Sort(sort.Reverse(sort.StringSlice(badIdents)))
 
 	// Add images to rankedImages in that sorted order, defaults first.
 	for _, ident := range defaultIdents {
@@ -119,6 +187,9 @@ func sortImages(loaderConf map[string]string, imgs map[string]boot.OSImage) []bo
 	for _, ident := range otherIdents {
 		rankedImages = append(rankedImages, imgs[ident])
 	}
+	for _, ident := range badIdents {
+		rankedImages = append(rankedImages, imgs[ident])
+	}
 	return rankedImages
 }
 
@@ -174,7 +245,6 @@ func parseLinuxImage(vals map[string]string, fsRoot string, variables map[string
 	linux := &boot.LinuxImage{}
 	var cmdlines []string
 	var tokens []string
-	var value string
 	for key, val := range vals {
 		switch key {
 		case "linux":
@@ -202,32 +272,11 @@ func parseLinuxImage(vals map[string]string, fsRoot string, variables map[string
 
 		// options may appear more than once.
 		case "options":
-			tokens = strings.Split(val, " ")
-			var err error
-			for _, w := range tokens {
-				switch w {
-				// TODO: GRUB/BLS parser should also get kernelopts from grubenv file
-				case "$kernelopts":
-					if value, err = getGrubvalue(variables, "kernelopts"); err != nil {
-						return nil, fmt.Errorf("variables map is nil for $kernelopts")
-					}
-					if value == "" {
-						// If it's not found, fallback to look for default_kernelopts
-						log.Printf("kernelopts is empty, look for default_kernelopts\n")
-						if value, _ = getGrubvalue(variables, "default_kernelopts"); value == "" {
-							return nil, fmt.Errorf("no valid kernelopts is found")
-						}
-					}
-					cmdlines = append(cmdlines, value)
-				case "$tuned_params":
-					if value, err = getGrubvalue(variables, "tuned_params"); err != nil {
-						return nil, fmt.Errorf("variables map is nil for $tuned_params")
-					}
-					cmdlines = append(cmdlines, value)
-				default:
-					cmdlines = append(cmdlines, w)
-				}
+			opts, err := parseOptions(val, variables)
+			if err != nil {
+				return nil, err
 			}
+			cmdlines = append(cmdlines, opts...)
 		}
 	}
 
@@ -236,6 +285,47 @@ func parseLinuxImage(vals map[string]string, fsRoot string, variables map[string
 		return nil, fmt.Errorf("malformed Linux config: linux keyword missing")
 	}
 
+	// If both title and version were empty, so will this.
+	linux.Name = entryName(vals)
+	linux.Cmdline = strings.Join(cmdlines, " ")
+	linux.BootRank = bootRank(grubDefaultFlag)
+	return linux, nil
+}
+
+// parseOptions expands the GRUB variables in the value of an options key.
+func parseOptions(val string, variables map[string]string) ([]string, error) {
+	var cmdlines []string
+	var value string
+	var err error
+	for _, w := range strings.Split(val, " ") {
+		switch w {
+		// TODO: GRUB/BLS parser should also get kernelopts from grubenv file
+		case "$kernelopts":
+			if value, err = getGrubvalue(variables, "kernelopts"); err != nil {
+				return nil, fmt.Errorf("variables map is nil for $kernelopts")
+			}
+			if value == "" {
+				// If it's not found, fallback to look for default_kernelopts
+				log.Printf("kernelopts is empty, look for default_kernelopts\n")
+				if value, _ = getGrubvalue(variables, "default_kernelopts"); value == "" {
+					return nil, fmt.Errorf("no valid kernelopts is found")
+				}
+			}
+			cmdlines = append(cmdlines, value)
+		case "$tuned_params":
+			if value, err = getGrubvalue(variables, "tuned_params"); err != nil {
+				return nil, fmt.Errorf("variables map is nil for $tuned_params")
+			}
+			cmdlines = append(cmdlines, value)
+		default:
+			cmdlines = append(cmdlines, w)
+		}
+	}
+	return cmdlines, nil
+}
+
+// entryName joins the title and version of an entry.
+func entryName(vals map[string]string) string {
 	var name []string
 	if title, ok := vals["title"]; ok && len(title) > 0 {
 		name = append(name, title)
@@ -243,23 +333,48 @@ func parseLinuxImage(vals map[string]string, fsRoot string, variables map[string
 	if version, ok := vals["version"]; ok && len(version) > 0 {
 		name = append(name, version)
 	}
-	// If both title and version were empty, so will this.
-	linux.Name = strings.Join(name, " ")
-	linux.Cmdline = strings.Join(cmdlines, " ")
-	// If this is the default option, increase the BootRank by 1
-	// when os.LookupEnv("BLS_BOOT_RANK") doesn't exist so it's not affected.
+	return strings.Join(name, " ")
+}
+
+// bootRank returns the rank of a BLS image. If this is the default option,
+// increase the BootRank by 1 when os.LookupEnv("BLS_BOOT_RANK") doesn't exist
+// so it's not affected.
+func bootRank(grubDefaultFlag bool) int {
 	if val, exist := os.LookupEnv("BLS_BOOT_RANK"); exist {
 		if rank, err := strconv.Atoi(val); err == nil {
-			linux.BootRank = rank
-		}
-	} else {
-		if grubDefaultFlag {
-			linux.BootRank = blsDefaultRank + 1
-		} else {
-			linux.BootRank = blsDefaultRank
+			return rank
 		}
+		return 0
+	}
+	if grubDefaultFlag {
+		return blsDefaultRank + 1
 	}
+	return blsDefaultRank
+}
 
+// parseUKIEntry takes a Type #1 BLS entry whose efi key refers to a Unified
+// Kernel Image. Options given in the entry replace the command line of the
+// UKI, as systemd-stub does when not booted with Secure Boot.
+func parseUKIEntry(vals map[string]string, fsRoot string, variables map[string]string, grubDefaultFlag bool) (boot.OSImage, error) {
+	var cmdlines []string
+	opts, hasOpts := vals["options"]
+	if hasOpts {
+		var err error
+		if cmdlines, err = parseOptions(opts, variables); err != nil {
+			return nil, err
+		}
+	}
+	linux, err := parseUKI(filePath(fsRoot, vals["efi"]))
+	if err != nil {
+		return nil, err
+	}
+	if hasOpts {
+		linux.Cmdline = strings.Join(cmdlines, " ")
+	}
+	if name := entryName(vals); name != "" {
+		linux.Name = name
+	}
+	linux.BootRank = bootRank(grubDefaultFlag)
 	return linux, nil
 }
 
@@ -279,7 +394,7 @@ func parseBLSEntry(entryPath, fsRoot string, variables map[string]string, grubDe
 	} else if _, ok := vals["multiboot"]; ok {
 		err = fmt.Errorf("multiboot not yet supported")
 	} else if _, ok := vals["efi"]; ok {
-		err = fmt.Errorf("EFI not yet supported")
+		img, err = parseUKIEntry(vals, fsRoot, variables, grubDefaultFlag)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("error parsing config in %s: %w", entryPath, err)
@@ -5,13 +5,16 @@
 package bls
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/u-root/u-root/pkg/boot"
 	"github.com/u-root/u-root/pkg/boot/boottest"
+	"github.com/u-root/u-root/pkg/boot/uki"
 	"github.com/u-root/u-root/pkg/ulog/ulogtest"
 )
 
@@ -105,8 +108,7 @@ func TestSetBLSRank(t *testing.T) {
 	fsRoot := "./testdata/madeup"
 	dir := filepath.Join(fsRoot, "loader/entries")
 	testRank := 2
-	originRank := os.Getenv("BLS_BOOT_RANK")
-	os.Setenv("BLS_BOOT_RANK", strconv.Itoa(testRank))
+	t.Setenv("BLS_BOOT_RANK", strconv.Itoa(testRank))
 
 	for _, tt := range blsEntries {
 		t.Run(tt.entry, func(t *testing.T) {
@@ -129,6 +131,62 @@ func TestSetBLSRank(t *testing.T) {
 			}
 		})
 	}
+}
+
+func TestScanUKIEntries(t *testing.T) {
+	imgs, err := ScanUKIEntries(ulogtest.Logger{TB: t}, "./testdata/uki")
+	if err != nil {
+		t.Fatalf("ScanUKIEntries() = %v", err)
+	}
+
+	// The EFI shell and README are skipped.
+	want := []struct {
+		label   string
+		cmdline string
+	}{
+		{"Fedora Linux 40 (Forty) 6.9.7-200.fc40.x86_64", "root=UUID=0b0b2c4c quiet"},
+		{"Arch Linux", "root=/dev/vda2 rw"},
+	}
+	if len(imgs) != len(want) {
+		t.Fatalf("ScanUKIEntries() = %v, want %d images", imgs, len(want))
+	}
+	for i, w := range want {
+		li, ok := imgs[i].(*boot.LinuxImage)
+		if !ok {
+			t.Fatalf("image %d is %T, want *boot.LinuxImage", i, imgs[i])
+		}
+		if li.Label() != w.label || li.Cmdline != w.cmdline || li.Rank() != blsDefaultRank {
+			t.Errorf("image %d = %q with %q and rank %d, want %q with %q and rank %d", i, li.Label(), li.Cmdline, li.Rank(), w.label, w.cmdline, blsDefaultRank)
+		}
+	}
 
-	os.Setenv("BLS_BOOT_RANK", originRank)
+	if _, err := ScanUKIEntries(ulogtest.Logger{TB: t}, "./testdata/madeup"); err == nil {
+		t.Errorf("ScanUKIEntries() without EFI/Linux = nil, want error")
+	}
+}
+
+func TestUKIEntry(t *testing.T) {
+	imgs, err := ScanBLSEntries(ulogtest.Logger{TB: t}, "./testdata/uki", nil, "")
+	if err != nil {
+		t.Fatalf("ScanBLSEntries() = %v", err)
+	}
+	if len(imgs) != 1 {
+		t.Fatalf("ScanBLSEntries() = %v, want 1 image", imgs)
+	}
+	li := imgs[0].(*boot.LinuxImage)
+	if want := "Arch Linux (serial console)"; li.Label() != want {
+		t.Errorf("Label() = %q, want %q", li.Label(), want)
+	}
+	if want := "root=/dev/vda2 rw console=ttyS0,115200"; li.Cmdline != want {
+		t.Errorf("Cmdline = %q, want %q", li.Cmdline, want)
+	}
+
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "shell.conf")
+	if err := os.WriteFile(conf, []byte("efi /EFI/Linux/shell.efi\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseBLSEntry(conf, "./testdata/uki", nil, false); !errors.Is(err, uki.ErrNoKernel) {
+		t.Errorf("parseBLSEntry(EFI shell) = %v, want %v", err, uki.ErrNoKernel)
+	}
 }
@@ -0,0 +1,105 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bls
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Boot counting as in https://systemd.io/AUTOMATIC_BOOT_ASSESSMENT/: an entry
+// file named ID+LEFT-DONE.conf, or ID+LEFT.conf, is booted LEFT more times
+// and was booted DONE times without being marked good. The boot loader counts
+// a try by renaming it to ID+(LEFT-1)-(DONE+1).conf before booting it, and
+// the booted system drops the counter, renaming it to ID.conf, once the boot
+// is good. Entries with no tries left are bad and sorted after all others.
+//
+// The same applies to Type #2 entries, with an .efi suffix.
+
+// bootCounter is the boot counter of an entry file.
+type bootCounter struct {
+	left, done int
+}
+
+// splitEntryName splits the file name of an entry into the entry
+// identifier without the boot counter, the boot counter, and the suffix.
+// counter is nil if the name has no valid boot counter.
+func splitEntryName(name string) (id string, counter *bootCounter, suffix string) {
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".conf") || strings.EqualFold(ext, ukiSuffix) {
+		name, suffix = strings.TrimSuffix(name, ext), ext
+	}
+	i := strings.LastIndexByte(name, '+')
+	if i < 0 {
+		return name, nil, suffix
+	}
+	l, d, hasDone := strings.Cut(name[i+1:], "-")
+	left, err := strconv.ParseUint(l, 10, 31)
+	if err != nil {
+		return name, nil, suffix
+	}
+	var done uint64
+	if hasDone {
+		if done, err = strconv.ParseUint(d, 10, 31); err != nil {
+			return name, nil, suffix
+		}
+	}
+	return name[:i], &bootCounter{left: int(left), done: int(done)}, suffix
+}
+
+// entryID returns the identifier of the entry file name, with the boot
+// counter removed. Type #1 identifiers do not include the .conf suffix,
+// Type #2 identifiers include the .efi suffix.
+func entryID(name string) string {
+	id, _, suffix := splitEntryName(name)
+	if strings.EqualFold(suffix, ".conf") {
+		return id
+	}
+	return id + suffix
+}
+
+// isBad reports whether the entry file name has no tries left.
+func isBad(name string) bool {
+	_, c, _ := splitEntryName(name)
+	return c != nil && c.left == 0
+}
+
+// CountBoot counts a try of the entry file at path, which must be on a
+// writable file system, and returns its new path. Entries without boot
+// counter, or without tries left, are not renamed.
+func CountBoot(path string) (string, error) {
+	dir, name := filepath.Split(path)
+	id, c, suffix := splitEntryName(name)
+	if c == nil || c.left == 0 {
+		return path, nil
+	}
+	newPath := filepath.Join(dir, id+"+"+strconv.Itoa(c.left-1)+"-"+strconv.Itoa(c.done+1)+suffix)
+	if err := os.Rename(path, newPath); err != nil {
+		return "", err
+	}
+	return newPath, nil
+}
+
+// MarkGood removes the boot counter of the entry file at path, so that it
+// is no longer counted, and returns its new path.
+func MarkGood(path string) (string, error) {
+	dir, name := filepath.Split(path)
+	id, c, suffix := splitEntryName(name)
+	if c == nil {
+		return path, nil
+	}
+	newPath := filepath.Join(dir, id+suffix)
+	if err := os.Rename(path, newPath); err != nil {
+		return "", err
+	}
+	return newPath, nil
+}
+
+// HasBootCounter reports whether the entry file at path has a boot counter.
+func HasBootCounter(path string) bool {
+	_, c, _ := splitEntryName(filepath.Base(path))
+	return c != nil
+}
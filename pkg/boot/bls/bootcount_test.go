@@ -0,0 +1,118 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bls
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/boot"
+	"github.com/u-root/u-root/pkg/ulog/ulogtest"
+)
+
+func TestSplitEntryName(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		id      string
+		counter *bootCounter
+		suffix  string
+	}{
+		{name: "fedora.conf", id: "fedora", suffix: ".conf"},
+		{name: "fedora+3.conf", id: "fedora", counter: &bootCounter{left: 3}, suffix: ".conf"},
+		{name: "fedora+2-1.conf", id: "fedora", counter: &bootCounter{left: 2, done: 1}, suffix: ".conf"},
+		{name: "a+b+0-3.conf", id: "a+b", counter: &bootCounter{done: 3}, suffix: ".conf"},
+		{name: "linux+1-0.EFI", id: "linux", counter: &bootCounter{left: 1}, suffix: ".EFI"},
+		{name: "fedora+x.conf", id: "fedora+x", suffix: ".conf"},
+		{name: "fedora+-1.conf", id: "fedora+-1", suffix: ".conf"},
+		{name: "fedora+1-.conf", id: "fedora+1-", suffix: ".conf"},
+	} {
+		id, counter, suffix := splitEntryName(tt.name)
+		if id != tt.id || suffix != tt.suffix || (counter == nil) != (tt.counter == nil) || (counter != nil && *counter != *tt.counter) {
+			t.Errorf("splitEntryName(%q) = %q, %v, %q, want %q, %v, %q", tt.name, id, counter, suffix, tt.id, tt.counter, tt.suffix)
+		}
+	}
+}
+
+func TestCountBoot(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		name    string
+		counted string
+	}{
+		{name: "plain.conf", counted: "plain.conf"},
+		{name: "new+3.conf", counted: "new+2-1.conf"},
+		{name: "new+2-1.conf", counted: "new+1-2.conf"},
+		{name: "bad+0-3.conf", counted: "bad+0-3.conf"},
+	} {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := CountBoot(path)
+		if err != nil || got != filepath.Join(dir, tt.counted) {
+			t.Errorf("CountBoot(%s) = %q, %v, want %s", tt.name, got, err, tt.counted)
+		}
+		if _, err := os.Stat(filepath.Join(dir, tt.counted)); err != nil {
+			t.Errorf("CountBoot(%s) did not rename: %v", tt.name, err)
+		}
+		os.Remove(got)
+	}
+
+	path := filepath.Join(dir, "new+1-2.conf")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := MarkGood(path)
+	if err != nil || got != filepath.Join(dir, "new.conf") {
+		t.Errorf("MarkGood() = %q, %v, want new.conf", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.conf")); err != nil {
+		t.Errorf("MarkGood() did not rename: %v", err)
+	}
+	if _, err := CountBoot(filepath.Join(dir, "gone+1.conf")); err == nil {
+		t.Errorf("CountBoot() of a missing entry = nil, want error")
+	}
+}
+
+func TestScanBootCounting(t *testing.T) {
+	root := t.TempDir()
+	entries := filepath.Join(root, blsEntriesDir)
+	if err := os.MkdirAll(entries, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "vmlinuz"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.conf", "b+2-1.conf", "c+0-3.conf"} {
+		if err := os.WriteFile(filepath.Join(entries, name), []byte("title "+name+"\nlinux /vmlinuz\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	imgs, err := ScanBLSEntries(ulogtest.Logger{TB: t}, root, nil, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The bad entry c comes last, and b is the GRUB default without
+	// its counter.
+	want := []struct {
+		name, entry string
+		rank        int
+	}{
+		{"b+2-1.conf", "b+2-1.conf", blsDefaultRank + 1},
+		{"a.conf", "a.conf", blsDefaultRank},
+		{"c+0-3.conf", "c+0-3.conf", blsDefaultRank},
+	}
+	if len(imgs) != len(want) {
+		t.Fatalf("ScanBLSEntries() = %v, want %d images", imgs, len(want))
+	}
+	for i, w := range want {
+		li := imgs[i].(*boot.LinuxImage)
+		if li.Name != w.name || li.BLSEntry != filepath.Join(entries, w.entry) || li.BootRank != w.rank {
+			t.Errorf("image %d = %q from %s with rank %d, want %q from %s with rank %d", i, li.Name, li.BLSEntry, li.BootRank, w.name, w.entry, w.rank)
+		}
+	}
+}
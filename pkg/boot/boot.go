@@ -8,6 +8,7 @@ package boot
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/u-root/u-root/pkg/boot/kexec"
 	"github.com/u-root/uio/ulog"
@@ -27,6 +28,8 @@ type loadOptions struct {
 	logger        ulog.Logger
 	verbose       bool
 	callKexecLoad bool
+	crash         bool
+	verifier      Verifier
 }
 
 func defaultLoadOptions() *loadOptions {
@@ -70,6 +73,69 @@ func WithDryRun(dryRun bool) LoadOption {
 	}
 }
 
+// WithCrash is a LoadOption that loads the image as the crash kernel, which
+// is booted when the running kernel panics, rather than the kernel to kexec
+// into.
+//
+// Crash kernels are loaded into the memory reserved with crashkernel=.
+func WithCrash(crash bool) LoadOption {
+	return func(o *loadOptions) {
+		o.crash = crash
+	}
+}
+
+// Artifact names the part of an OSImage a Verifier is asked to check.
+type Artifact string
+
+// Artifacts passed to Verifier.Verify.
+const (
+	ArtifactKernel Artifact = "kernel"
+	ArtifactInitrd Artifact = "initrd"
+	ArtifactDTB    Artifact = "dtb"
+	ArtifactModule Artifact = "module"
+)
+
+// Verifier checks the kernel, initramfs, device tree and modules of an
+// OSImage before Load loads them.
+//
+// See package github.com/u-root/u-root/pkg/boot/verify for implementations.
+type Verifier interface {
+	// Verify returns an error if r is not trusted.
+	//
+	// r is the artifact as given in the OSImage, e.g. a curl.File for
+	// netbooted images or an *os.File.
+	Verify(a Artifact, r io.ReaderAt) error
+}
+
+// WithVerifier is a LoadOption that checks every artifact of the image with
+// v before loading it. Load fails if v fails.
+//
+// Verification is not TOCTTOU-safe against the contents of an artifact
+// changing between Verify and Load.
+func WithVerifier(v Verifier) LoadOption {
+	return func(o *loadOptions) {
+		o.verifier = v
+	}
+}
+
+func (o *loadOptions) verify(a Artifact, r io.ReaderAt) error {
+	if o.verifier == nil || r == nil {
+		return nil
+	}
+	if c, ok := r.(*catInitrd); ok {
+		for _, p := range c.parts {
+			if err := o.verify(a, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := o.verifier.Verify(a, r); err != nil {
+		return fmt.Errorf("verifying %s %s: %w", a, stringer(r), err)
+	}
+	return nil
+}
+
 // OSImage represents a bootable OS package.
 type OSImage interface {
 	fmt.Stringer
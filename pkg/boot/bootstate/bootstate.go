@@ -0,0 +1,268 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package bootstate remembers boot attempts across reboots.
+//
+// The state lives in a Store, such as a GRUB environment block, EFI
+// variables or VPD, and implements:
+//
+//   - a next entry, booted once on the next boot only,
+//   - trial entries with a boot counter: a new kernel is booted at most N
+//     times, and the boot falls back to the default entry once all tries are
+//     used up without the boot being marked good,
+//   - marking the current boot good, which makes a trial entry the default.
+//
+// Boots of Boot Loader Specification entries with a boot counter are also
+// counted in the entry file name, see package bls.
+//
+// The variables are compatible with GRUB's saved_entry and next_entry, so a
+// grubenv can be shared with GRUB.
+package bootstate
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/u-root/u-root/pkg/boot"
+	"github.com/u-root/u-root/pkg/boot/bls"
+)
+
+// Variable names of the state.
+const (
+	// KeyDefault is the entry booted by default, as in GRUB.
+	KeyDefault = "saved_entry"
+
+	// KeyNext is the entry booted once on the next boot, as in GRUB.
+	KeyNext = "next_entry"
+
+	// KeyTrial is the entry being tried, and KeyTriesLeft the number of
+	// times it is going to be booted before falling back.
+	KeyTrial     = "trial_entry"
+	KeyTriesLeft = "trial_tries_left"
+
+	// KeyCurrent is the entry chosen for the current boot, and KeyGood is
+	// "1" once the current boot was marked good.
+	KeyCurrent = "boot_entry"
+	KeyGood    = "boot_success"
+
+	// KeyBLSEntry is the file name of the BLS entry of the current boot,
+	// if it has a boot counter.
+	KeyBLSEntry = "boot_bls_entry"
+)
+
+var keys = []string{KeyDefault, KeyNext, KeyTrial, KeyTriesLeft, KeyCurrent, KeyGood, KeyBLSEntry}
+
+// ErrNoEntries is returned by Choose if there is nothing to boot.
+var ErrNoEntries = errors.New("no boot entries")
+
+// State is the boot state. Entries are identified by their label.
+type State struct {
+	// Default is the entry to boot if there is no other choice. If
+	// empty, the first entry is the default.
+	Default string
+
+	// Next is booted once on the next boot.
+	Next string
+
+	// Trial is booted TriesLeft more times, unless a boot of it is
+	// marked good, which makes it the Default.
+	Trial     string
+	TriesLeft int
+
+	// Current is the entry chosen for the current boot, and Good is
+	// true if the current boot has been marked good.
+	Current string
+	Good    bool
+
+	// BLSEntry is the file name of the Boot Loader Specification entry
+	// of the current boot if it has a boot counter, so that the booted
+	// system can mark it good with bls.MarkGood.
+	BLSEntry string
+}
+
+// Load reads the state from s.
+func Load(s Store) (*State, error) {
+	vars, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	st := &State{
+		Default:  vars[KeyDefault],
+		Next:     vars[KeyNext],
+		Trial:    vars[KeyTrial],
+		Current:  vars[KeyCurrent],
+		Good:     vars[KeyGood] == "1",
+		BLSEntry: vars[KeyBLSEntry],
+	}
+	if v, ok := vars[KeyTriesLeft]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid %s %q", KeyTriesLeft, v)
+		}
+		st.TriesLeft = n
+	}
+	return st, nil
+}
+
+// Save writes the state to s. Other variables in s are kept.
+func (st *State) Save(s Store) error {
+	vars := map[string]string{
+		KeyDefault:  st.Default,
+		KeyNext:     st.Next,
+		KeyTrial:    st.Trial,
+		KeyCurrent:  st.Current,
+		KeyBLSEntry: st.BLSEntry,
+	}
+	if st.Trial != "" {
+		vars[KeyTriesLeft] = strconv.Itoa(st.TriesLeft)
+	} else {
+		vars[KeyTriesLeft] = ""
+	}
+	if st.Good {
+		vars[KeyGood] = "1"
+	} else {
+		vars[KeyGood] = ""
+	}
+	return s.Update(vars)
+}
+
+// Failed reports whether the trial entry used up all its tries without
+// being marked good.
+func (st *State) Failed() bool {
+	return st.Trial != "" && st.TriesLeft == 0
+}
+
+// Choose picks the entry for this boot among entries and returns all of
+// them in the order they should be tried: the chosen entry first, and a
+// failed trial entry last.
+//
+// The entry is the next entry if set, then the trial entry if it has tries
+// left, then the default entry. Choose does not change the state; Boot
+// records the entry that is actually booted.
+func (st *State) Choose(entries []string) ([]string, error) {
+	if len(entries) == 0 {
+		return nil, ErrNoEntries
+	}
+	// A failed trial entry is only booted if there is nothing else.
+	good := slices.DeleteFunc(slices.Clone(entries), func(e string) bool {
+		return st.Failed() && e == st.Trial
+	})
+	if len(good) == 0 {
+		good = entries
+	}
+
+	var chosen string
+	switch {
+	case st.Next != "" && slices.Contains(entries, st.Next):
+		chosen = st.Next
+	case st.Trial != "" && st.TriesLeft > 0 && slices.Contains(entries, st.Trial):
+		chosen = st.Trial
+	case slices.Contains(good, st.Default):
+		chosen = st.Default
+	default:
+		chosen = good[0]
+	}
+
+	order := []string{chosen}
+	for _, e := range good {
+		if e != chosen {
+			order = append(order, e)
+		}
+	}
+	for _, e := range entries {
+		if !slices.Contains(order, e) {
+			order = append(order, e)
+		}
+	}
+	return order, nil
+}
+
+// Boot records that entry is being booted. It consumes the next entry, even
+// if another entry is booted, and one try of the trial entry if that is the
+// entry booted.
+func (st *State) Boot(entry string) {
+	if entry == st.Trial && st.TriesLeft > 0 {
+		st.TriesLeft--
+	}
+	st.Next = ""
+	st.Current = entry
+	st.Good = false
+	st.BLSEntry = ""
+}
+
+// MarkGood marks the current boot good. If the current boot is of the
+// trial entry, it becomes the default.
+func (st *State) MarkGood() {
+	st.Good = true
+	if st.Trial != "" && st.Current == st.Trial {
+		st.Default = st.Trial
+		st.Trial = ""
+		st.TriesLeft = 0
+	}
+}
+
+// Try makes entry the trial entry, to be booted at most tries times.
+func (st *State) Try(entry string, tries int) {
+	st.Trial = entry
+	st.TriesLeft = max(tries, 0)
+}
+
+// Order chooses among images by their labels as State.Choose does and
+// returns them in the order they should be tried. It does not change the
+// state in s: call Record with the image that is booted once it is known,
+// e.g. after a boot menu.
+func Order(s Store, imgs []boot.OSImage) ([]boot.OSImage, error) {
+	st, err := Load(s)
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]string, 0, len(imgs))
+	byLabel := make(map[string][]boot.OSImage)
+	for _, img := range imgs {
+		l := img.Label()
+		if _, ok := byLabel[l]; !ok {
+			labels = append(labels, l)
+		}
+		byLabel[l] = append(byLabel[l], img)
+	}
+	order, err := st.Choose(labels)
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]boot.OSImage, 0, len(imgs))
+	for _, l := range order {
+		ordered = append(ordered, byLabel[l]...)
+	}
+	return ordered, nil
+}
+
+// Record records in s that img is being booted, as State.Boot does. Call
+// it before loading img, so that a boot that never comes back counts as an
+// attempt.
+//
+// If img was read from a BLS entry with a boot counter, Record also counts
+// the try with bls.CountBoot, which needs the entry on a writable file
+// system.
+func Record(s Store, img boot.OSImage) error {
+	st, err := Load(s)
+	if err != nil {
+		return err
+	}
+	st.Boot(img.Label())
+
+	var errs error
+	if li, ok := img.(*boot.LinuxImage); ok && bls.HasBootCounter(li.BLSEntry) {
+		path, err := bls.CountBoot(li.BLSEntry)
+		if err != nil {
+			errs = fmt.Errorf("counting boot of %s: %w", li.BLSEntry, err)
+		} else {
+			li.BLSEntry = path
+			st.BLSEntry = filepath.Base(path)
+		}
+	}
+	return errors.Join(errs, st.Save(s))
+}
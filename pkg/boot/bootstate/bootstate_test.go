@@ -0,0 +1,391 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bootstate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/boot"
+	"github.com/u-root/u-root/pkg/efivarfs"
+	"github.com/u-root/u-root/pkg/vpd"
+)
+
+func TestChoose(t *testing.T) {
+	entries := []string{"a", "b", "c"}
+	for _, tt := range []struct {
+		desc  string
+		st    State
+		want  []string
+		after State
+	}{
+		{
+			desc:  "first entry by default",
+			want:  []string{"a", "b", "c"},
+			after: State{Current: "a"},
+		},
+		{
+			desc:  "default",
+			st:    State{Default: "b", Good: true},
+			want:  []string{"b", "a", "c"},
+			after: State{Default: "b", Current: "b"},
+		},
+		{
+			desc:  "unknown default",
+			st:    State{Default: "z"},
+			want:  []string{"a", "b", "c"},
+			after: State{Default: "z", Current: "a"},
+		},
+		{
+			desc:  "next entry once",
+			st:    State{Default: "b", Next: "c"},
+			want:  []string{"c", "a", "b"},
+			after: State{Default: "b", Current: "c"},
+		},
+		{
+			desc:  "unknown next entry is consumed",
+			st:    State{Default: "b", Next: "z"},
+			want:  []string{"b", "a", "c"},
+			after: State{Default: "b", Current: "b"},
+		},
+		{
+			desc:  "trial",
+			st:    State{Default: "b", Trial: "c", TriesLeft: 2},
+			want:  []string{"c", "a", "b"},
+			after: State{Default: "b", Trial: "c", TriesLeft: 1, Current: "c"},
+		},
+		{
+			desc:  "next entry before trial",
+			st:    State{Default: "b", Next: "a", Trial: "c", TriesLeft: 2},
+			want:  []string{"a", "b", "c"},
+			after: State{Default: "b", Trial: "c", TriesLeft: 2, Current: "a"},
+		},
+		{
+			desc:  "failed trial falls back to default",
+			st:    State{Default: "b", Trial: "c", Current: "c"},
+			want:  []string{"b", "a", "c"},
+			after: State{Default: "b", Trial: "c", Current: "b"},
+		},
+		{
+			desc:  "failed trial is tried last",
+			st:    State{Trial: "a"},
+			want:  []string{"b", "c", "a"},
+			after: State{Trial: "a", Current: "b"},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			st := tt.st
+			got, err := st.Choose(entries)
+			if err != nil {
+				t.Fatalf("Choose() = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Choose() = %q, want %q", got, tt.want)
+			}
+			if st != tt.st {
+				t.Errorf("Choose() changed state to %+v", st)
+			}
+			st.Boot(got[0])
+			if st != tt.after {
+				t.Errorf("state after Boot(%q) = %+v, want %+v", got[0], st, tt.after)
+			}
+		})
+	}
+
+	var st State
+	if _, err := st.Choose(nil); !errors.Is(err, ErrNoEntries) {
+		t.Errorf("Choose(nil) = %v, want %v", err, ErrNoEntries)
+	}
+}
+
+func TestBootOther(t *testing.T) {
+	// An entry picked in the menu instead of the trial entry does not use
+	// up a try, but consumes the next entry.
+	st := State{Default: "b", Next: "a", Trial: "c", TriesLeft: 2}
+	st.Boot("b")
+	want := State{Default: "b", Trial: "c", TriesLeft: 2, Current: "b"}
+	if st != want {
+		t.Errorf("state after Boot() = %+v, want %+v", st, want)
+	}
+}
+
+func images(labels ...string) []boot.OSImage {
+	var imgs []boot.OSImage
+	for _, l := range labels {
+		imgs = append(imgs, &boot.LinuxImage{Name: l})
+	}
+	return imgs
+}
+
+func labels(imgs []boot.OSImage) []string {
+	var l []string
+	for _, img := range imgs {
+		l = append(l, img.Label())
+	}
+	return l
+}
+
+// TestTrialRollout walks through an A/B rollout of a new kernel stored in
+// a grubenv shared with GRUB.
+func TestTrialRollout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grubenv")
+	if err := os.WriteFile(path, []byte("# GRUB Environment Block\nsaved_entry=old\nkernelopts=quiet\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := &GrubEnv{Path: path}
+	imgs := images("old", "new")
+
+	boot := func(want string) {
+		t.Helper()
+		got, err := Order(s, imgs)
+		if err != nil {
+			t.Fatalf("Order() = %v", err)
+		}
+		if got[0].Label() != want {
+			t.Fatalf("Order() = %q, want %q first", labels(got), want)
+		}
+		if err := Record(s, got[0]); err != nil {
+			t.Fatalf("Record() = %v", err)
+		}
+	}
+	update := func(f func(st *State)) {
+		t.Helper()
+		st, err := Load(s)
+		if err != nil {
+			t.Fatalf("Load() = %v", err)
+		}
+		f(st)
+		if err := st.Save(s); err != nil {
+			t.Fatalf("Save() = %v", err)
+		}
+	}
+
+	boot("old")
+	update(func(st *State) { st.Try("new", 2) })
+	boot("new")
+	boot("new")
+	// Both tries used up without marking the boot good.
+	boot("old")
+	boot("old")
+
+	// Try again and mark the boot good this time.
+	update(func(st *State) { st.Try("new", 1) })
+	boot("new")
+	update(func(st *State) { st.MarkGood() })
+	boot("new")
+
+	st, err := Load(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := State{Default: "new", Current: "new"}
+	if *st != want {
+		t.Errorf("Load() = %+v, want %+v", st, want)
+	}
+	vars, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["kernelopts"] != "quiet" {
+		t.Errorf("grubenv lost kernelopts: %v", vars)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Size() != 1024 {
+		t.Errorf("grubenv is not one block: %v, %v", fi, err)
+	}
+}
+
+func TestRecordBLS(t *testing.T) {
+	dir := t.TempDir()
+	entry := filepath.Join(dir, "new+2.conf")
+	if err := os.WriteFile(entry, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := &GrubEnv{Path: filepath.Join(dir, "grubenv")}
+	img := &boot.LinuxImage{Name: "new", BLSEntry: entry}
+	if err := Record(s, img); err != nil {
+		t.Fatalf("Record() = %v", err)
+	}
+	counted := filepath.Join(dir, "new+1-1.conf")
+	if img.BLSEntry != counted {
+		t.Errorf("BLSEntry = %q, want %q", img.BLSEntry, counted)
+	}
+	if _, err := os.Stat(counted); err != nil {
+		t.Errorf("BLS entry not counted: %v", err)
+	}
+	st, err := Load(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := State{Current: "new", BLSEntry: "new+1-1.conf"}
+	if *st != want {
+		t.Errorf("Load() = %+v, want %+v", st, want)
+	}
+}
+
+type fakeEFIVars map[efivarfs.VariableDescriptor][]byte
+
+func (f fakeEFIVars) Get(desc efivarfs.VariableDescriptor) (efivarfs.VariableAttributes, []byte, error) {
+	d, ok := f[desc]
+	if !ok {
+		return 0, nil, efivarfs.ErrVarNotExist
+	}
+	return efiAttrs, d, nil
+}
+
+func (f fakeEFIVars) List() ([]efivarfs.VariableDescriptor, error) {
+	var descs []efivarfs.VariableDescriptor
+	for d := range f {
+		descs = append(descs, d)
+	}
+	return descs, nil
+}
+
+func (f fakeEFIVars) Remove(desc efivarfs.VariableDescriptor) error {
+	if _, ok := f[desc]; !ok {
+		return efivarfs.ErrVarNotExist
+	}
+	delete(f, desc)
+	return nil
+}
+
+func (f fakeEFIVars) Set(desc efivarfs.VariableDescriptor, _ efivarfs.VariableAttributes, data []byte) error {
+	f[desc] = data
+	return nil
+}
+
+type fakeVPD struct {
+	blob   vpd.Blob
+	writes int
+}
+
+func newFakeVPD(kv ...string) *fakeVPD {
+	f := &fakeVPD{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		f.blob.Set(kv[i], []byte(kv[i+1]))
+	}
+	return f
+}
+
+func (f *fakeVPD) GetAll(readOnly bool) (map[string][]byte, error) {
+	if readOnly {
+		return nil, errors.New("read-only VPD")
+	}
+	return f.blob.Map(), nil
+}
+
+func (f *fakeVPD) Modify(readOnly bool, fn func(b *vpd.Blob) error) error {
+	if readOnly {
+		return errors.New("read-only VPD")
+	}
+	b := vpd.Blob{Entries: slices.Clone(f.blob.Entries)}
+	if err := fn(&b); err != nil {
+		return err
+	}
+	f.blob = b
+	f.writes++
+	return nil
+}
+
+func TestStores(t *testing.T) {
+	efi := fakeEFIVars{
+		{Name: "Other"}: []byte("x"),
+	}
+	vpdVars := newFakeVPD("serial_number", "1234")
+	for _, tt := range []struct {
+		name  string
+		store Store
+		check func(t *testing.T)
+	}{
+		{
+			name:  "grubenv",
+			store: &GrubEnv{Path: filepath.Join(t.TempDir(), "grubenv")},
+		},
+		{
+			name:  "efivars",
+			store: &EFIVars{Vars: efi},
+			check: func(t *testing.T) {
+				if len(efi) != 4 {
+					t.Errorf("EFI variables = %v, want Other, saved_entry, boot_entry and boot_success", efi)
+				}
+			},
+		},
+		{
+			name:  "vpd",
+			store: &VPD{Vars: vpdVars, Prefix: DefaultVPDPrefix},
+			check: func(t *testing.T) {
+				m := vpdVars.blob.Map()
+				if string(m["serial_number"]) != "1234" || string(m["bootstate_saved_entry"]) != "b" {
+					t.Errorf("VPD = %q", m)
+				}
+				// One write per Save.
+				if vpdVars.writes != 2 {
+					t.Errorf("VPD written %d times, want 2", vpdVars.writes)
+				}
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := Load(tt.store)
+			if err != nil {
+				t.Fatalf("Load() = %v", err)
+			}
+			if *st != (State{}) {
+				t.Errorf("Load() = %+v, want empty state", st)
+			}
+
+			st = &State{Default: "b", Next: "a", Trial: "c", TriesLeft: 3}
+			if err := st.Save(tt.store); err != nil {
+				t.Fatalf("Save() = %v", err)
+			}
+			order, err := st.Choose([]string{"a", "b", "c"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			st.Boot(order[0])
+			st.MarkGood()
+			st.Try("", 0)
+			if err := st.Save(tt.store); err != nil {
+				t.Fatalf("Save() = %v", err)
+			}
+
+			got, err := Load(tt.store)
+			if err != nil {
+				t.Fatalf("Load() = %v", err)
+			}
+			want := State{Default: "b", Current: "a", Good: true}
+			if *got != want {
+				t.Errorf("Load() = %+v, want %+v", got, want)
+			}
+			if tt.check != nil {
+				tt.check(t)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	s := &VPD{Vars: newFakeVPD("trial_tries_left", "many")}
+	if _, err := Load(s); err == nil {
+		t.Errorf("Load() = nil, want error")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for _, spec := range []string{"", "grubenv", "grubenv:", "vpd", "efivarfs:not-a-guid", "nvram:foo"} {
+		if _, err := Open(spec); err == nil {
+			t.Errorf("Open(%q) = nil, want error", spec)
+		}
+	}
+	s, err := Open("grubenv:/boot/grub/grubenv")
+	if err != nil {
+		t.Fatalf("Open() = %v", err)
+	}
+	if g, ok := s.(*GrubEnv); !ok || g.Path != "/boot/grub/grubenv" {
+		t.Errorf("Open() = %#v, want grubenv /boot/grub/grubenv", s)
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bootstate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	guid "github.com/google/uuid"
+	"github.com/u-root/u-root/pkg/boot/grub"
+	"github.com/u-root/u-root/pkg/efivarfs"
+	"github.com/u-root/u-root/pkg/vpd"
+)
+
+// Store persists string variables.
+type Store interface {
+	// Load returns all variables.
+	Load() (map[string]string, error)
+
+	// Update sets variables, deleting those set to "". Other variables
+	// are kept.
+	Update(vars map[string]string) error
+}
+
+// GrubEnv stores variables in a GRUB environment block, such as
+// /boot/grub/grubenv, which GRUB reads with load_env.
+type GrubEnv struct {
+	Path string
+}
+
+var _ Store = &GrubEnv{}
+
+// Load implements Store.Load. A missing file has no variables.
+func (g *GrubEnv) Load() (map[string]string, error) {
+	f, err := os.Open(g.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	env, err := grub.ParseEnvFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", g.Path, err)
+	}
+	return env.Vars, nil
+}
+
+// Update implements Store.Update. The file is replaced atomically.
+func (g *GrubEnv) Update(vars map[string]string) error {
+	old, err := g.Load()
+	if err != nil {
+		return err
+	}
+	env := grub.NewEnvFile()
+	env.Vars = old
+	for k, v := range vars {
+		if v == "" {
+			delete(env.Vars, k)
+		} else {
+			env.Vars[k] = v
+		}
+	}
+
+	var b bytes.Buffer
+	if _, err := env.WriteTo(&b); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(g.Path), ".grubenv")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), g.Path)
+}
+
+// DefaultGUID is the vendor GUID u-root uses for boot state EFI variables.
+// It is not shared with any other software: to exchange state with another
+// boot loader, set EFIVars.GUID to that boot loader's vendor GUID.
+var DefaultGUID = guid.MustParse("5c9c0d55-3b3f-4c3e-9f0a-6f2b3c1e4d7a")
+
+// EFIVars stores each variable in an EFI variable of the same name. Values
+// are stored as UTF-8 without a terminating NUL.
+type EFIVars struct {
+	Vars efivarfs.EFIVar
+
+	// GUID is the vendor GUID of the variables, DefaultGUID if zero.
+	GUID guid.UUID
+}
+
+var _ Store = &EFIVars{}
+
+const efiAttrs = efivarfs.AttributeNonVolatile | efivarfs.AttributeBootserviceAccess | efivarfs.AttributeRuntimeAccess
+
+func (e *EFIVars) guid() guid.UUID {
+	if e.GUID == (guid.UUID{}) {
+		return DefaultGUID
+	}
+	return e.GUID
+}
+
+// Load implements Store.Load.
+func (e *EFIVars) Load() (map[string]string, error) {
+	descs, err := e.Vars.List()
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string)
+	for _, desc := range descs {
+		if desc.GUID != e.guid() {
+			continue
+		}
+		_, data, err := e.Vars.Get(desc)
+		if err != nil {
+			return nil, fmt.Errorf("EFI variable %s: %w", desc.Name, err)
+		}
+		vars[desc.Name] = string(data)
+	}
+	return vars, nil
+}
+
+// Update implements Store.Update.
+func (e *EFIVars) Update(vars map[string]string) error {
+	for k, v := range vars {
+		desc := efivarfs.VariableDescriptor{Name: k, GUID: e.guid()}
+		if v == "" {
+			if err := e.Vars.Remove(desc); err != nil && !errors.Is(err, efivarfs.ErrVarNotExist) {
+				return fmt.Errorf("EFI variable %s: %w", k, err)
+			}
+			continue
+		}
+		if err := e.Vars.Set(desc, efiAttrs, []byte(v)); err != nil {
+			return fmt.Errorf("EFI variable %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// VPDVars is the part of *vpd.Image used by VPD.
+type VPDVars interface {
+	GetAll(readOnly bool) (map[string][]byte, error)
+	Modify(readOnly bool, f func(b *vpd.Blob) error) error
+}
+
+var _ VPDVars = &vpd.Image{}
+
+// VPD stores variables in the RW_VPD region, with keys prefixed by Prefix
+// to keep them apart from other VPD variables.
+type VPD struct {
+	Vars   VPDVars
+	Prefix string
+}
+
+var _ Store = &VPD{}
+
+// Load implements Store.Load. A region without VPD has no variables.
+func (v *VPD) Load() (map[string]string, error) {
+	all, err := v.Vars.GetAll(false)
+	if errors.Is(err, vpd.ErrNoVPD) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string)
+	for k, val := range all {
+		if name, ok := strings.CutPrefix(k, v.Prefix); ok {
+			vars[name] = string(val)
+		}
+	}
+	return vars, nil
+}
+
+// Update implements Store.Update. All variables are written at once, with
+// a single write of the region.
+func (v *VPD) Update(vars map[string]string) error {
+	return v.Vars.Modify(false, func(b *vpd.Blob) error {
+		for k, val := range vars {
+			if val == "" {
+				// Deleting a missing variable is fine.
+				_ = b.Delete(v.Prefix + k)
+				continue
+			}
+			b.Set(v.Prefix+k, []byte(val))
+		}
+		return nil
+	})
+}
+
+// DefaultVPDPrefix is the prefix of VPD keys used by Open.
+const DefaultVPDPrefix = "bootstate_"
+
+// vpdFile is a firmware image file, opened for each access so that no
+// file stays open across a kexec.
+type vpdFile string
+
+func (f vpdFile) with(fn func(img *vpd.Image) error) error {
+	file, err := os.OpenFile(string(f), os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	img, err := vpd.NewImage(file, fi.Size())
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("%s: %w", f, err)
+	}
+	if err := fn(img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (f vpdFile) GetAll(readOnly bool) (m map[string][]byte, err error) {
+	err = f.with(func(img *vpd.Image) error {
+		m, err = img.GetAll(readOnly)
+		return err
+	})
+	return m, err
+}
+
+func (f vpdFile) Modify(readOnly bool, fn func(b *vpd.Blob) error) error {
+	return f.with(func(img *vpd.Image) error {
+		return img.Modify(readOnly, fn)
+	})
+}
+
+// Open returns the store described by spec:
+//
+//	grubenv:PATH     a GRUB environment block
+//	efivarfs[:GUID]  EFI variables, with DefaultGUID by default
+//	vpd:IMAGE        the RW_VPD region of a firmware image or flash device
+func Open(spec string) (Store, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "grubenv":
+		if arg == "" {
+			return nil, fmt.Errorf("%q: missing path", spec)
+		}
+		return &GrubEnv{Path: arg}, nil
+	case "efivarfs":
+		vars, err := efivarfs.New()
+		if err != nil {
+			return nil, err
+		}
+		s := &EFIVars{Vars: vars}
+		if arg != "" {
+			g, err := guid.Parse(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", spec, err)
+			}
+			s.GUID = g
+		}
+		return s, nil
+	case "vpd":
+		if arg == "" {
+			return nil, fmt.Errorf("%q: missing image path", spec)
+		}
+		return &VPD{Vars: vpdFile(arg), Prefix: DefaultVPDPrefix}, nil
+	}
+	return nil, fmt.Errorf("unknown boot state store %q, want grubenv:PATH, efivarfs[:GUID] or vpd:IMAGE", spec)
+}
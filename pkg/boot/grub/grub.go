@@ -10,7 +10,8 @@
 // - https://www.gnu.org/software/grub/manual/grub/html_node/Shell_002dlike-scripting.html
 // - https://www.gnu.org/software/grub/manual/grub/html_node/Commands.html
 //
-// See parser.append function for list of commands that are supported.
+// Configs are evaluated as GRUB scripts, with conditionals, loops, functions
+// and submenus. See parser.exec for the list of commands that are supported.
 package grub
 
 import (
@@ -20,10 +21,14 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -33,7 +38,6 @@ import (
 	"github.com/u-root/u-root/pkg/curl"
 	"github.com/u-root/u-root/pkg/mount"
 	"github.com/u-root/u-root/pkg/mount/block"
-	"github.com/u-root/u-root/pkg/shlex"
 	"github.com/u-root/u-root/pkg/ulog"
 	"github.com/u-root/uio/uio"
 )
@@ -53,19 +57,8 @@ var probeGrubEnvFiles = []string{
 	"boot/grub2/grubenv",
 }
 
-// Grub syntax for OpenSUSE/Fedora/RHEL has some undocumented quirks. You
-// won't find it on the master branch, but instead look at the rhel and fedora
-// branches for these commits:
-//
-// * https://github.com/rhboot/grub2/commit/7e6775e6d4a8de9baf3f4676d4e021cc2f5dd761
-// * https://github.com/rhboot/grub2/commit/0c26c6f7525737962d1389ebdfbb918f52d1b3b6
-//
-// They add a special case to not escape hex sequences:
-//
-//	grub> echo hello \xff \xfg
-//	hello \xff xfg
-//
-// Their default installations depend on this functionality.
+// hexEscape and anyEscape match the escapes cmdlineQuote has to keep or
+// quote. See lexer.hexEscape for the \xXX quirk.
 var (
 	hexEscape = regexp.MustCompile(`\\x[0-9a-fA-F]{2}`)
 	anyEscape = regexp.MustCompile(`\\.{0,3}`)
@@ -91,7 +84,7 @@ func absFileScheme(path string) (*url.URL, error) {
 
 // ParseLocalConfig looks for a GRUB config in the disk partition mounted at
 // diskDir and parses out OSes to boot.
-func ParseLocalConfig(ctx context.Context, diskDir string, devices block.BlockDevices, mountPool *mount.Pool) ([]boot.OSImage, error) {
+func ParseLocalConfig(ctx context.Context, diskDir string, devices block.BlockDevices, mountPool *mount.Pool, opts ...Option) ([]boot.OSImage, error) {
 	root, err := absFileScheme(diskDir)
 	if err != nil {
 		return nil, err
@@ -117,7 +110,7 @@ func ParseLocalConfig(ctx context.Context, diskDir string, devices block.BlockDe
 	}
 
 	for _, relname := range append(relNames, probeGrubFiles...) {
-		c, err := ParseConfigFile(ctx, curl.DefaultSchemes, relname, root, devices, mountPool)
+		c, err := ParseConfigFile(ctx, curl.DefaultSchemes, relname, root, devices, mountPool, opts...)
 		if curl.IsURLError(err) {
 			continue
 		}
@@ -165,86 +158,125 @@ func findkeywordGrubEnv(file string, fsRoot string, key string) (string, error)
 // ParseConfigFile parses a grub configuration as specified in
 // https://www.gnu.org/software/grub/manual/grub/
 //
-// See parser.append function for list of commands that are supported.
+// The configuration is evaluated as a GRUB script. See parser.exec for the
+// list of commands that are supported.
 //
 // `root` is the default scheme, host, and path for any files named as a
 // relative path - e.g. kernel and initramfs paths are requested relative to
 // the root.
-func ParseConfigFile(ctx context.Context, s curl.Schemes, configFile string, root *url.URL, devices block.BlockDevices, mountPool *mount.Pool) ([]boot.OSImage, error) {
+//
+// Images are returned in menu order, except that the entry GRUB would boot
+// by default comes first.
+func ParseConfigFile(ctx context.Context, s curl.Schemes, configFile string, root *url.URL, devices block.BlockDevices, mountPool *mount.Pool, opts ...Option) ([]boot.OSImage, error) {
 	p := newParser(root, devices, mountPool, s)
-	if err := p.appendFile(ctx, configFile); err != nil {
-		return nil, err
+	for _, opt := range opts {
+		opt(p)
 	}
-
-	// Don't add entries twice.
-	//
-	// Multiple labels can refer to the same image, so we have to dedup by pointer.
-	seenLinux := make(map[*boot.LinuxImage]struct{})
-	seenMB := make(map[*boot.MultibootImage]struct{})
-
-	var grubDefaultSavedEntry string
-	// If the value of keyword "default_saved_entry" exists, find the value of "save_entry" from grubenv files from all possible paths.
-	if _, ok := p.variables["default_saved_entry"]; ok {
-		for _, m := range mountPool.MountPoints {
-			for _, file := range probeGrubEnvFiles {
-				// Parse grubenv and return the value of 'saved_entry'.
-				val, _ := findkeywordGrubEnv(file, m.Path, "saved_entry")
-				if val != "" {
-					grubDefaultSavedEntry = val
-				}
-			}
+	if _, ok := p.variables["prefix"]; !ok {
+		// GRUB sets $prefix to the directory it was installed to,
+		// which is where the config and grubenv live.
+		if u, err := parseURL(path.Dir(configFile), p.variables["root"]); err == nil {
+			p.variables["prefix"] = u.String()
 		}
 	}
+	if err := p.appendFile(ctx, configFile); err != nil {
+		return nil, err
+	}
 
-	if defaultEntry, ok := p.variables["default"]; ok {
-		p.labelOrder = append([]string{defaultEntry}, p.labelOrder...)
+	entries, err := p.evalMenu(ctx, p.top, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	var images []boot.OSImage
 	if p.blscfgFound {
+		// BLS entries are named by their file name, which is what
+		// saved_entry refers to.
+		grubDefaultSavedEntry := p.variables["default"]
+		if grubDefaultSavedEntry == "" && mountPool != nil {
+			// Find the value of "saved_entry" from grubenv files from all possible paths.
+			for _, m := range mountPool.MountPoints {
+				for _, file := range probeGrubEnvFiles {
+					// Parse grubenv and return the value of 'saved_entry'.
+					val, _ := findkeywordGrubEnv(file, m.Path, "saved_entry")
+					if val != "" {
+						grubDefaultSavedEntry = val
+					}
+				}
+			}
+		}
 		if imgs, err := grubScanBLSEntries(p.mountPool, p.variables, grubDefaultSavedEntry); err == nil {
 			images = append(images, imgs...)
 		}
 	}
 
-	for _, label := range p.labelOrder {
-		if img, ok := p.linuxEntries[label]; ok {
-			if _, ok := seenLinux[img]; !ok {
-				images = append(images, img)
-				seenLinux[img] = struct{}{}
-			}
-		}
-
-		if img, ok := p.mbEntries[label]; ok {
-			if _, ok := seenMB[img]; !ok {
-				images = append(images, img)
-				seenMB[img] = struct{}{}
-			}
+	// Put the default entry first.
+	def := defaultEntry(entries, p.variables["default"])
+	if def >= 0 {
+		images = append(images, entries[def].img)
+	}
+	for i, e := range entries {
+		if i != def {
+			images = append(images, e.img)
 		}
 	}
 	return images, nil
 }
 
-type parser struct {
-	linuxEntries map[string]*boot.LinuxImage
-	mbEntries    map[string]*boot.MultibootImage
+// Option configures the evaluation of a GRUB config.
+type Option func(*parser)
 
-	labelOrder []string
+// WithVars presets variables in the GRUB environment, as if they had been
+// set before the config was loaded.
+func WithVars(vars map[string]string) Option {
+	return func(p *parser) {
+		maps.Copy(p.variables, vars)
+	}
+}
+
+var (
+	// errTooManySteps is returned when a script runs too long, most
+	// likely because of an endless loop or recursion.
+	errTooManySteps = errors.New("script did not terminate")
+
+	// Control flow of break, continue, return and exit.
+	errBreak    = errors.New("break")
+	errContinue = errors.New("continue")
+	errReturn   = errors.New("return")
+	errExit     = errors.New("exit")
+)
 
+const (
+	// maxSteps is the maximum number of commands evaluated for a config.
+	maxSteps = 20000
+
+	// maxDepth is the maximum nesting of function calls and sourced files.
+	maxDepth = 64
+
+	// maxVarLen is the maximum length of a variable value. Longer
+	// assignments fail, so that loops cannot grow values exponentially.
+	maxVarLen = 16 << 10
+)
+
+type parser struct {
 	W io.Writer
 
-	// parser internals.
-	numEntry int
 	// Special variables:
 	//   * default: Default boot option.
 	//   * root: Root "partition" as a URL.
+	//   * prefix: Directory of the GRUB installation as a URL.
 	variables map[string]string
 
-	// curEntry is the current entry number as a string.
-	curEntry string
+	// top is the scope of the top-level script. Its variables are
+	// `variables`.
+	top *scope
 
-	// curLabel is the last parsed label from a "menuentry".
-	curLabel string
+	functions map[string]*functionCommand
+
+	// steps counts evaluated commands and depth the nesting of function
+	// calls and sourced files, to bound the evaluation.
+	steps int
+	depth int
 
 	devices   block.BlockDevices
 	mountPool *mount.Pool
@@ -254,6 +286,46 @@ type parser struct {
 	blscfgFound bool
 }
 
+// scope is the state of a script being evaluated. Like GRUB, menu entries
+// are evaluated in a copy of the environment of the script defining them.
+type scope struct {
+	vars map[string]string
+
+	// args are the positional parameters $1, $2, ...
+	args []string
+
+	// status is the exit status of the last command, true for success.
+	status bool
+
+	// menu is the list of menu entries and submenus defined so far.
+	menu []*menuItem
+
+	// linux or mb is the image loaded by the menu entry being evaluated.
+	linux *boot.LinuxImage
+	mb    *boot.MultibootImage
+}
+
+// menuItem is a menuentry or submenu. As in GRUB, its body is only
+// evaluated once the script defining it is done.
+type menuItem struct {
+	submenu bool
+	title   string
+	id      string
+
+	// index is the position of the item in its menu.
+	index int
+
+	// args are the positional parameters of the body.
+	args []string
+	body []command
+}
+
+// menuEntry is a bootable image and the menu path leading to it.
+type menuEntry struct {
+	path []*menuItem
+	img  boot.OSImage
+}
+
 // newParser returns a new grub parser using `root` and schemes `s`.
 //
 // We are going off script here by using URLs instead of grub's device syntax.
@@ -269,12 +341,29 @@ type parser struct {
 // resolves to the device node "/dev/disk/by-partlabel/LINUX". This grub parser
 // looks through mounts for a matching device number.
 func newParser(root *url.URL, devices block.BlockDevices, mountPool *mount.Pool, s curl.Schemes) *parser {
+	vars := map[string]string{
+		"root": root.String(),
+
+		// Features of the GRUB version we pretend to be, tested by
+		// grub-mkconfig generated configs.
+		"feature_200_final":            "y",
+		"feature_all_video_module":     "y",
+		"feature_chainloader_bpb":      "y",
+		"feature_default_font_path":    "y",
+		"feature_menuentry_id":         "y",
+		"feature_menuentry_options":    "y",
+		"feature_nativedisk_cmd":       "y",
+		"feature_ntldr":                "y",
+		"feature_platform_search_hint": "y",
+		"feature_timeout_style":        "y",
+
+		"grub_cpu":      grubCPU(),
+		"grub_platform": grubPlatform(),
+	}
 	return &parser{
-		linuxEntries: make(map[string]*boot.LinuxImage),
-		mbEntries:    make(map[string]*boot.MultibootImage),
-		variables: map[string]string{
-			"root": root.String(),
-		},
+		variables:   vars,
+		top:         &scope{vars: vars, status: true},
+		functions:   make(map[string]*functionCommand),
 		devices:     devices,
 		mountPool:   mountPool,
 		schemes:     s,
@@ -282,6 +371,32 @@ func newParser(root *url.URL, devices block.BlockDevices, mountPool *mount.Pool,
 	}
 }
 
+// grubCPU returns $grub_cpu for the running architecture.
+func grubCPU() string {
+	switch runtime.GOARCH {
+	case "amd64":
+		return "x86_64"
+	case "386":
+		return "i386"
+	case "arm":
+		return "arm"
+	case "arm64":
+		return "arm64"
+	case "riscv64":
+		return "riscv64"
+	}
+	return runtime.GOARCH
+}
+
+// grubPlatform returns $grub_platform for the running architecture. Only
+// x86 still commonly boots with a BIOS.
+func grubPlatform() string {
+	if _, err := os.Stat("/sys/firmware/efi"); err == nil || (runtime.GOARCH != "amd64" && runtime.GOARCH != "386") {
+		return "efi"
+	}
+	return "pc"
+}
+
 func parseURL(surl string, root string) (*url.URL, error) {
 	u, err := url.Parse(surl)
 	if err != nil {
@@ -304,36 +419,48 @@ func parseURL(surl string, root string) (*url.URL, error) {
 	return u, nil
 }
 
-// getFile parses `url` relative to the current root and returns an io.Reader
-// for the requested url.
+// resolve parses the GRUB file name `name` relative to the current root.
 //
-// If url is just a relative path and not a full URL, c.root is used for the
-// relative path; the resulting URL is roughly path.Join(root, url).
-func (c *parser) getFile(url string) (io.ReaderAt, error) {
-	url = strings.TrimPrefix(url, "($root)")
-	u, err := parseURL(url, c.variables["root"])
-	if err != nil {
-		return nil, err
+// GRUB file names may start with a device, like (hd0,1)/boot/vmlinuz. Since
+// devices are URLs here, ($root)/boot/vmlinuz works. Any other device is
+// assumed to be the current root.
+func (c *parser) resolve(sc *scope, name string) (*url.URL, error) {
+	root := sc.vars["root"]
+	if rest, ok := strings.CutPrefix(name, "("); ok {
+		if dev, file, ok := strings.Cut(rest, ")"); ok {
+			if u, err := url.Parse(dev); err == nil && u.Scheme != "" {
+				root = dev
+			}
+			name = file
+		}
 	}
-
-	return c.schemes.LazyFetch(u)
+	return parseURL(name, root)
 }
 
-// appendFile parses the config file downloaded from `url` and adds it to `c`.
-func (c *parser) appendFile(ctx context.Context, url string) error {
-	u, err := parseURL(url, c.variables["root"])
+// getFile parses `name` relative to the current root and returns an
+// io.ReaderAt for the requested url.
+//
+// If name is just a relative path and not a full URL, the root is used for
+// the relative path; the resulting URL is roughly path.Join(root, name).
+func (c *parser) getFile(sc *scope, name string) (io.ReaderAt, error) {
+	u, err := c.resolve(sc, name)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return c.schemes.LazyFetch(u)
+}
 
+// readScript downloads and parses the script at `u`.
+func (c *parser) readScript(ctx context.Context, u *url.URL) ([]command, error) {
 	r, err := c.schemes.Fetch(ctx, u)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer r.Close()
 
 	config, err := uio.ReadAll(r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(config) > 500 {
 		// Avoid flooding the console on real systems
@@ -342,7 +469,21 @@ func (c *parser) appendFile(ctx context.Context, url string) error {
 	} else {
 		log.Printf("[grub] Got config file %s:\n%s\n", r, string(config))
 	}
-	return c.append(ctx, string(config))
+	return parseScript(string(config))
+}
+
+// appendFile evaluates the config file downloaded from `url`.
+func (c *parser) appendFile(ctx context.Context, url string) error {
+	u, err := c.resolve(c.top, url)
+	if err != nil {
+		return err
+	}
+	cmds, err := c.readScript(ctx, u)
+	if err != nil {
+		return err
+	}
+	c.variables["config_directory"] = u.JoinPath("..").String()
+	return c.eval(ctx, c.top, cmds)
 }
 
 // CmdlineQuote quotes the command line as grub-core/lib/cmdline.c does
@@ -366,252 +507,818 @@ func cmdlineQuote(args []string) string {
 	return strings.Join(q, " ")
 }
 
-// append parses `config` and adds the respective configuration to `c`.
-//
-// NOTE: This parser has outlived its usefulness already, given that it doesn't
-// even understand the {} scoping in GRUB. But let's get the tests to pass, and
-// then we can do a rewrite.
+// append evaluates the script `config` at the top level.
 func (c *parser) append(ctx context.Context, config string) error {
-	// Here's a shitty parser.
-	for line := range strings.SplitSeq(config, "\n") {
-		// Add extra backslash for OpenSUSE/Fedora/RHEL use case. shlex
-		// will convert it back to a single backslash.
-		line = hexEscape.ReplaceAllString(line, `\\$0`)
-		kv := shlex.Argv(line)
-		if len(kv) < 1 {
-			continue
+	cmds, err := parseScript(config)
+	if err != nil {
+		return err
+	}
+	return c.eval(ctx, c.top, cmds)
+}
+
+// eval runs a script, stopping early at exit or return.
+func (c *parser) eval(ctx context.Context, sc *scope, cmds []command) error {
+	err := c.run(ctx, sc, cmds)
+	if errors.Is(err, errTooManySteps) {
+		return err
+	}
+	return nil
+}
+
+// run runs commands in scope `sc`. The only errors are control flow and
+// errTooManySteps.
+func (c *parser) run(ctx context.Context, sc *scope, cmds []command) error {
+	for _, cmd := range cmds {
+		c.steps++
+		if c.steps > maxSteps {
+			return errTooManySteps
 		}
-		directive := strings.ToLower(kv[0])
-		// blscfg len(kv) is 1 so need to be checked here
-		if directive == "blscfg" {
-			c.blscfgFound = true
+		if err := c.runCommand(ctx, sc, cmd); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		// Used by tests (allow no parameters here)
-		if c.W != nil && directive == "echo" {
-			fmt.Fprintf(c.W, "echo:%#v\n", kv[1:])
+func (c *parser) runCommand(ctx context.Context, sc *scope, cmd command) error {
+	switch cmd := cmd.(type) {
+	case *simpleCommand:
+		args := c.expand(sc, cmd.args)
+		if len(args) == 0 {
+			return nil
 		}
-
-		if len(kv) <= 1 {
-			continue
+		ok, err := c.exec(ctx, sc, args)
+		if cmd.negate {
+			ok = !ok
 		}
-		arg := kv[1]
-
-		switch directive {
-		case "search.file", "search.fs_label", "search.fs_uuid":
-			// Alias to regular search directive.
-			kv = append(
-				[]string{"search", map[string]string{
-					"search.file":     "--file",
-					"search.fs_label": "--fs-label",
-					"search.fs_uuid":  "--fs-uuid",
-				}[directive]},
-				kv[1:]...,
-			)
-			fallthrough
-		case "search":
-			// Parses a line with this format:
-			//   search [--file|--label|--fs-uuid] [--set [var]] [--no-floppy] name
-			fs := flag.NewFlagSet("grub.search", flag.ContinueOnError)
-			searchUUID := fs.Bool("fs-uuid", false, "")
-			searchLabel := fs.Bool("fs-label", false, "")
-			searchFile := fs.Bool("file", false, "")
-			fs.BoolVar(searchUUID, "u", false, "")
-			fs.BoolVar(searchLabel, "l", false, "")
-			fs.BoolVar(searchFile, "f", false, "")
-			setVar := fs.String("set", "root", "")
-			// Ignored flags
-			fs.String("no-floppy", "", "ignored")
-			fs.String("hint", "", "ignored")
-			// Everything that begins with "hint" is ignored.
-			// This was slightly cleaner with pflag, but, at the same time,
-			// we're unlikely to see --hint used as anything but a flag.
-			// The real args we need to fix start at kv[1].
-			// kv[0] can not possibly start with --hint,
-			// and this makes the range simpler, so we do not worry
-			// about an extra loop iterator.
-			for i := range kv {
-				if strings.HasPrefix(kv[i], "--hint") {
-					kv[i] = "--hint"
-				}
+		sc.status = ok
+		return err
+
+	case *ifCommand:
+		for i, cond := range cmd.conds {
+			if err := c.run(ctx, sc, cond); err != nil {
+				return err
+			}
+			if sc.status {
+				return c.run(ctx, sc, cmd.bodies[i])
 			}
+		}
+		sc.status = true
+		return c.run(ctx, sc, cmd.els)
 
-			if err := fs.Parse(kv[1:]); err != nil || fs.NArg() != 1 {
-				log.Printf("Warning: Grub parser could not parse %q", kv)
-				continue
+	case *loopCommand:
+		for {
+			if err := c.run(ctx, sc, cmd.cond); err != nil {
+				return err
 			}
-			searchName := fs.Arg(0)
-			if *searchUUID && *searchLabel || *searchUUID && *searchFile || *searchLabel && *searchFile {
-				log.Printf("Warning: Grub parser found more than one search option in %q, skipping line", line)
-				continue
+			if sc.status == cmd.until {
+				sc.status = true
+				return nil
+			}
+			// Count iterations of empty loops, too.
+			c.steps++
+			if c.steps > maxSteps {
+				return errTooManySteps
 			}
-			if !*searchUUID && !*searchLabel && !*searchFile {
-				// defaults to searchUUID
-				*searchUUID = true
+			err := c.run(ctx, sc, cmd.body)
+			if errors.Is(err, errBreak) {
+				return nil
 			}
+			if err != nil && !errors.Is(err, errContinue) {
+				return err
+			}
+		}
 
-			switch {
-			case *searchUUID:
-				d := c.devices.FilterFSUUID(searchName)
-				if len(d) != 1 {
-					log.Printf("Error: Expected 1 device with UUID %q, found %d", searchName, len(d))
-					continue
-				}
-				mp, err := c.mountPool.Mount(d[0], mountFlags)
-				if err != nil {
-					log.Printf("Error: Could not mount %v: %v", d[0], err)
-					continue
-				}
-				setVal, err := absFileScheme(mp.Path)
-				if err != nil {
-					continue
-				}
-				c.variables[*setVar] = setVal.String()
-			case *searchLabel:
-				d := c.devices.FilterPartLabel(searchName)
-				if len(d) != 1 {
-					log.Printf("Error: Expected 1 device with label %q, found %d", searchName, len(d))
-					continue
-				}
-				mp, err := c.mountPool.Mount(d[0], mountFlags)
-				if err != nil {
-					log.Printf("Error: Could not mount %v: %v", d[0], err)
-					continue
-				}
-				setVal, err := absFileScheme(mp.Path)
-				if err != nil {
-					continue
-				}
-				c.variables[*setVar] = setVal.String()
-			case *searchFile:
-				// Make sure searchName stays in mountpoint. Remove "../" components.
-				cleanPath, err := filepath.Rel("/", filepath.Clean(filepath.Join("/", searchName)))
-				if err != nil {
-					log.Printf("Error: Could not clean path %q: %v", searchName, err)
-					continue
-				}
-				// Search through all the devices for the file.
-				for _, d := range c.devices {
-					mp, err := c.mountPool.Mount(d, mountFlags)
-					if err != nil {
-						log.Printf("Warning: Could not mount %v: %v", mp, err)
-						continue
-					}
-					file := filepath.Join(mp.Path, cleanPath)
-					if _, err := os.Stat(file); err == nil {
-						setVal, err := absFileScheme(mp.Path)
-						if err != nil {
-							continue
-						}
-						c.variables[*setVar] = setVal.String()
-						break
-					}
-				}
+	case *forCommand:
+		for _, item := range c.expand(sc, cmd.items) {
+			sc.vars[cmd.name] = item
+			err := c.run(ctx, sc, cmd.body)
+			if errors.Is(err, errBreak) {
+				return nil
 			}
+			if err != nil && !errors.Is(err, errContinue) {
+				return err
+			}
+		}
 
-		case "set":
-			vals := strings.SplitN(arg, "=", 2)
-			if len(vals) == 2 {
-				// TODO: We cannot parse grub device syntax.
-				if vals[0] == "root" {
-					continue
-				}
-				// here we only add the support for the case: set default="${saved_entry}".
-				if vals[0] == "default" {
-					if vals[1] == "${saved_entry}" {
-						c.variables["default_saved_entry"] = vals[1]
-					} else {
-						c.variables[vals[0]] = vals[1]
-					}
-				} else {
-					c.variables[vals[0]] = vals[1]
+	case *functionCommand:
+		c.functions[cmd.name] = cmd
+
+	case *menuCommand:
+		c.defineMenu(sc, cmd)
+	}
+	return nil
+}
+
+// lookup returns the value of variable `name`.
+func (sc *scope) lookup(name string) string {
+	switch name {
+	case "?":
+		if sc.status {
+			return "0"
+		}
+		return "1"
+	case "#":
+		return strconv.Itoa(len(sc.args))
+	case "@", "*":
+		return strings.Join(sc.args, " ")
+	}
+	if n, err := strconv.Atoi(name); err == nil {
+		if n > 0 && n <= len(sc.args) {
+			return sc.args[n-1]
+		}
+		return ""
+	}
+	return sc.vars[name]
+}
+
+// expand expands variables in words. Like in GRUB, values of unquoted
+// variables are split into several words at whitespace, and an unquoted
+// empty variable is no word at all.
+func (c *parser) expand(sc *scope, words []word) []string {
+	args := []string{}
+	for _, w := range words {
+		var cur strings.Builder
+		inWord := false
+		flush := func() {
+			if inWord {
+				args = append(args, cur.String())
+			}
+			cur.Reset()
+			inWord = false
+		}
+		for _, p := range w {
+			if !p.isVar || p.quoted {
+				v := p.text
+				if p.isVar {
+					v = sc.lookup(p.text)
 				}
+				cur.WriteString(v)
+				inWord = inWord || p.quoted || v != ""
+				continue
 			}
 
-		case "configfile":
-			// TODO test that
-			if err := c.appendFile(ctx, arg); err != nil {
-				return err
+			v := sc.lookup(p.text)
+			fields := strings.Fields(v)
+			if v != "" && isSpace(v[0]) || v != "" && len(fields) == 0 {
+				flush()
 			}
-
-		case "devicetree":
-			if e, ok := c.linuxEntries[c.curEntry]; ok {
-				dtb, err := c.getFile(arg)
-				if err != nil {
-					return err
+			for i, f := range fields {
+				if i > 0 {
+					flush()
 				}
-				e.DTB = dtb
+				cur.WriteString(f)
+				inWord = true
 			}
+			if len(fields) > 0 && isSpace(v[len(v)-1]) {
+				flush()
+			}
+		}
+		flush()
+	}
+	return args
+}
+
+// isAssignment returns whether the command `arg` is name=value.
+func isAssignment(arg string) bool {
+	name, _, ok := strings.Cut(arg, "=")
+	if !ok || name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for i := range len(name) {
+		if !isNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// setVar sets a variable and returns whether it succeeded.
+func (sc *scope) setVar(name, value string) bool {
+	if len(value) > maxVarLen {
+		return false
+	}
+	// TODO: We cannot parse grub device syntax.
+	if name == "root" {
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" {
+			return true
+		}
+	}
+	sc.vars[name] = value
+	return true
+}
+
+// exec runs a command or function and returns whether it succeeded.
+//
+// Commands changing the environment, loading files and images, as well as
+// test and control flow are supported. Other commands, e.g. insmod, are
+// ignored and succeed.
+func (c *parser) exec(ctx context.Context, sc *scope, args []string) (bool, error) {
+	if len(args) == 1 && isAssignment(args[0]) {
+		name, value, _ := strings.Cut(args[0], "=")
+		return sc.setVar(name, value), nil
+	}
 
-		case "menuentry":
-			c.curEntry = strconv.Itoa(c.numEntry)
-			c.curLabel = arg
-			c.numEntry++
-			c.labelOrder = append(c.labelOrder, c.curEntry, c.curLabel)
+	if f, ok := c.functions[args[0]]; ok {
+		if c.depth >= maxDepth {
+			return false, errTooManySteps
+		}
+		c.depth++
+		saved := sc.args
+		sc.args = args[1:]
+		err := c.run(ctx, sc, f.body)
+		sc.args = saved
+		c.depth--
+		if err != nil && !errors.Is(err, errReturn) {
+			return false, err
+		}
+		return sc.status, nil
+	}
 
-		case "linux", "linux16", "linuxefi":
-			k, err := c.getFile(arg)
+	directive := strings.ToLower(args[0])
+	switch directive {
+	case "echo":
+		// Used by tests
+		if c.W != nil {
+			fmt.Fprintf(c.W, "echo:%#v\n", args[1:])
+		}
+
+	case "set":
+		for _, arg := range args[1:] {
+			if name, value, ok := strings.Cut(arg, "="); ok && !sc.setVar(name, value) {
+				return false, nil
+			}
+		}
+
+	case "unset":
+		for _, name := range args[1:] {
+			delete(sc.vars, name)
+		}
+
+	case "[":
+		if args[len(args)-1] != "]" {
+			return false, nil
+		}
+		return c.test(sc, args[1:len(args)-1]), nil
+
+	case "test":
+		return c.test(sc, args[1:]), nil
+
+	case "true":
+	case "false":
+		return false, nil
+
+	case "return":
+		if len(args) > 1 {
+			n, _ := strconv.Atoi(args[1])
+			sc.status = n == 0
+		}
+		return sc.status, errReturn
+
+	case "break":
+		return true, errBreak
+
+	case "continue":
+		return true, errContinue
+
+	case "exit":
+		return true, errExit
+
+	case "blscfg":
+		c.blscfgFound = true
+
+	case "load_env":
+		return c.loadEnv(ctx, sc, args[1:]), nil
+
+	case "source", ".", "configfile", "normal":
+		if len(args) < 2 {
+			// normal without argument enters the menu.
+			return directive == "normal", nil
+		}
+		return c.source(ctx, sc, args[1], directive != "source" && directive != ".")
+
+	case "search.file", "search.fs_label", "search.fs_uuid":
+		// Alias to regular search directive. The second argument
+		// is the variable to set.
+		flag := map[string]string{
+			"search.file":     "--file",
+			"search.fs_label": "--fs-label",
+			"search.fs_uuid":  "--fs-uuid",
+		}[directive]
+		kv := []string{"search", flag}
+		if len(args) > 2 {
+			kv = append(kv, "--set", args[2])
+		}
+		if len(args) > 1 {
+			kv = append(kv, args[1])
+		}
+		return c.search(sc, kv), nil
+
+	case "search":
+		return c.search(sc, args), nil
+
+	case "linux", "linux16", "linuxefi":
+		if len(args) < 2 {
+			return false, nil
+		}
+		k, err := c.getFile(sc, args[1])
+		if err != nil {
+			log.Printf("Warning: Grub parser could not load kernel %q: %v", args[1], err)
+			return false, nil
+		}
+		// from grub manual: "Any initrd must be reloaded after using this command" so we can replace the entry
+		sc.linux = &boot.LinuxImage{
+			Kernel:  k,
+			Cmdline: cmdlineQuote(args[2:]),
+		}
+		sc.mb = nil
+
+	case "initrd", "initrd16", "initrdefi":
+		if sc.linux == nil || len(args) < 2 {
+			return false, nil
+		}
+		var initrds []io.ReaderAt
+		for _, name := range args[1:] {
+			i, err := c.getFile(sc, name)
 			if err != nil {
-				return err
+				log.Printf("Warning: Grub parser could not load initrd %q: %v", name, err)
+				return false, nil
+			}
+			initrds = append(initrds, i)
+		}
+		if len(initrds) == 1 {
+			sc.linux.Initrd = initrds[0]
+		} else {
+			sc.linux.Initrd = boot.CatInitrds(initrds...)
+		}
+
+	case "devicetree":
+		if sc.linux == nil || len(args) < 2 {
+			return false, nil
+		}
+		dtb, err := c.getFile(sc, args[1])
+		if err != nil {
+			return false, nil
+		}
+		sc.linux.DTB = dtb
+
+	case "multiboot", "multiboot2":
+		// TODO handle --quirk-* arguments ? (change parsing)
+		if len(args) < 2 {
+			return false, nil
+		}
+		k, err := c.getFile(sc, args[1])
+		if err != nil {
+			return false, nil
+		}
+		// from grub manual: "Any initrd must be reloaded after using this command" so we can replace the entry
+		sc.mb = &boot.MultibootImage{
+			Kernel:  k,
+			Cmdline: cmdlineQuote(args[2:]),
+		}
+		sc.linux = nil
+
+	case "module", "module2":
+		if sc.mb == nil || len(args) < 2 {
+			return false, nil
+		}
+		// The only allowed arg
+		cmdline := args[1:]
+		if cmdline[0] == "--nounzip" {
+			cmdline = cmdline[1:]
+		}
+		if len(cmdline) == 0 {
+			log.Printf("Warning: Grub parser found no file argument in %q", args)
+			return false, nil
+		}
+		m, err := c.getFile(sc, cmdline[0])
+		if err != nil {
+			return false, nil
+		}
+		// TODO: Lasy tryGzipFilter(m)
+		sc.mb.Modules = append(sc.mb.Modules, multiboot.Module{
+			Module:  m,
+			Cmdline: cmdlineQuote(cmdline),
+		})
+	}
+	return true, nil
+}
+
+// source evaluates the script in file `name` in the current scope.
+// configfile additionally changes $config_directory.
+func (c *parser) source(ctx context.Context, sc *scope, name string, configfile bool) (bool, error) {
+	u, err := c.resolve(sc, name)
+	if err != nil {
+		return false, nil
+	}
+	cmds, err := c.readScript(ctx, u)
+	if err != nil {
+		log.Printf("Warning: Grub parser could not read %q: %v", name, err)
+		return false, nil
+	}
+	if c.depth >= maxDepth {
+		return false, errTooManySteps
+	}
+	if configfile {
+		sc.vars["config_directory"] = u.JoinPath("..").String()
+	}
+	c.depth++
+	defer func() { c.depth-- }()
+	if err := c.run(ctx, sc, cmds); errors.Is(err, errTooManySteps) || errors.Is(err, errExit) {
+		return false, err
+	}
+	return sc.status, nil
+}
+
+// loadEnv implements load_env [-f file] [--skip-sig] [variable...], which
+// sets variables from a GRUB environment block, by default
+// $prefix/grubenv.
+func (c *parser) loadEnv(ctx context.Context, sc *scope, args []string) bool {
+	file := sc.vars["prefix"] + "/grubenv"
+	var names []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-f", "--file":
+			if i+1 < len(args) {
+				i++
+				file = args[i]
 			}
-			// from grub manual: "Any initrd must be reloaded after using this command" so we can replace the entry
-			entry := &boot.LinuxImage{
-				Name:    c.curLabel,
-				Kernel:  k,
-				Cmdline: cmdlineQuote(kv[2:]),
-				Env:     c.variables,
+		case "-s", "--skip-sig":
+		default:
+			names = append(names, args[i])
+		}
+	}
+
+	u, err := c.resolve(sc, file)
+	if err != nil {
+		return false
+	}
+	r, err := c.schemes.Fetch(ctx, u)
+	if err != nil {
+		log.Printf("Warning: Grub parser could not load environment %q: %v", file, err)
+		return false
+	}
+	defer r.Close()
+	env, err := ParseEnvFile(uio.Reader(r))
+	if err != nil {
+		log.Printf("Warning: Grub parser could not parse environment %q: %v", file, err)
+		return false
+	}
+	for k, v := range env.Vars {
+		if len(names) == 0 || slices.Contains(names, k) {
+			sc.vars[k] = v
+		}
+	}
+	return true
+}
+
+// search implements the search directive, which sets a variable to the
+// mounted partition matching a file system UUID, label or containing a file.
+func (c *parser) search(sc *scope, kv []string) bool {
+	// Parses a line with this format:
+	//   search [--file|--label|--fs-uuid] [--set [var]] [--no-floppy] name
+	fs := flag.NewFlagSet("grub.search", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	searchUUID := fs.Bool("fs-uuid", false, "")
+	searchLabel := fs.Bool("fs-label", false, "")
+	searchFile := fs.Bool("file", false, "")
+	fs.BoolVar(searchUUID, "u", false, "")
+	fs.BoolVar(searchLabel, "label", false, "")
+	fs.BoolVar(searchLabel, "l", false, "")
+	fs.BoolVar(searchFile, "f", false, "")
+	setVar := fs.String("set", "root", "")
+	// Ignored flags
+	fs.Bool("no-floppy", false, "ignored")
+	fs.Bool("n", false, "ignored")
+
+	// Hints (--hint, --hint-bios, ...) only speed up GRUB's search and
+	// are ignored. Their value may be a separate argument.
+	var args []string
+	for i := 1; i < len(kv); i++ {
+		if strings.HasPrefix(kv[i], "--hint") {
+			if !strings.Contains(kv[i], "=") {
+				i++
+			}
+			continue
+		}
+		args = append(args, kv[i])
+	}
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		log.Printf("Warning: Grub parser could not parse %q", kv)
+		return false
+	}
+	searchName := fs.Arg(0)
+	if *searchUUID && *searchLabel || *searchUUID && *searchFile || *searchLabel && *searchFile {
+		log.Printf("Warning: Grub parser found more than one search option in %q, skipping line", kv)
+		return false
+	}
+	if !*searchUUID && !*searchLabel && !*searchFile {
+		// defaults to searchUUID
+		*searchUUID = true
+	}
+
+	var d block.BlockDevices
+	switch {
+	case *searchUUID:
+		d = c.devices.FilterFSUUID(searchName)
+		if len(d) != 1 {
+			log.Printf("Error: Expected 1 device with UUID %q, found %d", searchName, len(d))
+			return false
+		}
+	case *searchLabel:
+		d = c.devices.FilterPartLabel(searchName)
+		if len(d) != 1 {
+			log.Printf("Error: Expected 1 device with label %q, found %d", searchName, len(d))
+			return false
+		}
+	case *searchFile:
+		d = c.devices
+	}
+
+	// Make sure searchName stays in mountpoint. Remove "../" components.
+	cleanPath, err := filepath.Rel("/", filepath.Clean(filepath.Join("/", searchName)))
+	if err != nil {
+		log.Printf("Error: Could not clean path %q: %v", searchName, err)
+		return false
+	}
+	// Search through the devices, for the file if searching by file.
+	for _, dev := range d {
+		mp, err := c.mountPool.Mount(dev, mountFlags)
+		if err != nil {
+			log.Printf("Warning: Could not mount %v: %v", dev, err)
+			continue
+		}
+		if *searchFile {
+			if _, err := os.Stat(filepath.Join(mp.Path, cleanPath)); err != nil {
+				continue
 			}
-			c.linuxEntries[c.curEntry] = entry
-			c.linuxEntries[c.curLabel] = entry
-
-		case "initrd", "initrd16", "initrdefi":
-			if e, ok := c.linuxEntries[c.curEntry]; ok {
-				i, err := c.getFile(arg)
-				if err != nil {
-					return err
+		}
+		setVal, err := absFileScheme(mp.Path)
+		if err != nil {
+			continue
+		}
+		sc.vars[*setVar] = setVal.String()
+		return true
+	}
+	return false
+}
+
+// defineMenu adds a menu entry or submenu to the current menu.
+//
+//	menuentry title [--class class] [--users users] [--unrestricted]
+//	          [--hotkey key] [--id id] [arg...] { body }
+func (c *parser) defineMenu(sc *scope, cmd *menuCommand) {
+	args := c.expand(sc, cmd.args)
+	item := &menuItem{
+		submenu: cmd.kind == "submenu",
+		body:    cmd.body,
+	}
+	titled := false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--unrestricted":
+		case arg == "--class" || arg == "--users" || arg == "--hotkey" || arg == "--id":
+			if i+1 < len(args) {
+				i++
+				if arg == "--id" {
+					item.id = args[i]
 				}
-				e.Initrd = i
 			}
+		case !titled:
+			item.title = arg
+			titled = true
+		default:
+			item.args = append(item.args, arg)
+		}
+	}
+	// Hidden entries are not part of the menu and cannot be the default.
+	if cmd.kind == "hiddenentry" {
+		return
+	}
+	item.index = len(sc.menu)
+	sc.menu = append(sc.menu, item)
+}
 
-		case "multiboot", "multiboot2":
-			// TODO handle --quirk-* arguments ? (change parsing)
-			k, err := c.getFile(arg)
+// evalMenu evaluates the bodies of the menu entries defined in `parent`
+// and returns the images they load. Submenus are flattened.
+func (c *parser) evalMenu(ctx context.Context, parent *scope, path []*menuItem) ([]menuEntry, error) {
+	var entries []menuEntry
+	for _, item := range parent.menu {
+		p := append(slices.Clone(path), item)
+		titles := make([]string, 0, len(p))
+		for _, i := range p {
+			titles = append(titles, i.title)
+		}
+		sc := &scope{
+			vars:   maps.Clone(parent.vars),
+			args:   item.args,
+			status: true,
+		}
+		sc.vars["chosen"] = strings.Join(titles, ">")
+		if err := c.eval(ctx, sc, item.body); err != nil {
+			return nil, err
+		}
+
+		if item.submenu {
+			sub, err := c.evalMenu(ctx, sc, p)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			// from grub manual: "Any initrd must be reloaded after using this command" so we can replace the entry
-			entry := &boot.MultibootImage{
-				Name:    c.curLabel,
-				Kernel:  k,
-				Cmdline: cmdlineQuote(kv[2:]),
-			}
-			c.mbEntries[c.curEntry] = entry
-			c.mbEntries[c.curLabel] = entry
-
-		case "module", "module2":
-			// TODO handle --nounzip arguments ? (change parsing)
-			if e, ok := c.mbEntries[c.curEntry]; ok {
-				// The only allowed arg
-				cmdline := kv[1:]
-				if arg == "--nounzip" {
-					if len(kv) < 3 {
-						return fmt.Errorf("no file argument given: %v", kv)
-					}
-					arg = kv[2]
-					cmdline = kv[2:]
-				}
-				m, err := c.getFile(arg)
-				if err != nil {
-					return err
-				}
-				// TODO: Lasy tryGzipFilter(m)
-				mod := multiboot.Module{
-					Module:  m,
-					Cmdline: cmdlineQuote(cmdline),
-				}
-				e.Modules = append(e.Modules, mod)
+			entries = append(entries, sub...)
+			continue
+		}
+
+		// Entries in submenus are labelled with the path to them, like
+		// GRUB's default and saved_entry.
+		name := sc.vars["chosen"]
+		switch {
+		case sc.linux != nil:
+			sc.linux.Name = name
+			sc.linux.Env = sc.vars
+			entries = append(entries, menuEntry{path: p, img: sc.linux})
+		case sc.mb != nil:
+			sc.mb.Name = name
+			entries = append(entries, menuEntry{path: p, img: sc.mb})
+		}
+	}
+	return entries, nil
+}
+
+// defaultEntry returns the index of the entry selected by `def`, the value
+// of $default, or -1 if there is none.
+//
+// Like GRUB, each ">"-separated part of def selects an entry of a menu
+// level by its index, title or id. If def is empty or does not match, the
+// first entry is the default.
+func defaultEntry(entries []menuEntry, def string) int {
+	if len(entries) == 0 {
+		return -1
+	}
+	parts := strings.Split(def, ">")
+	for i, e := range entries {
+		if len(e.path) != len(parts) {
+			continue
+		}
+		match := true
+		for j, item := range e.path {
+			if !matchesItem(item, parts[j]) {
+				match = false
+				break
 			}
 		}
+		if match {
+			return i
+		}
 	}
-	return nil
+	return 0
+}
+
+// matchesItem returns whether menu item `item` is selected by `sel`.
+func matchesItem(item *menuItem, sel string) bool {
+	return sel == item.title || (item.id != "" && sel == item.id) || sel == strconv.Itoa(item.index)
+}
+
+// testExpr evaluates the arguments of the test command:
+//
+//	! expr, ( expr ), expr -a expr, expr -o expr,
+//	-n str, -z str, str, str = str, str == str, str != str, str < str, str > str,
+//	int -eq int, int -ne int, int -lt int, int -le int, int -gt int, int -ge int,
+//	-e file, -f file, -d file, -s file
+type testExpr struct {
+	c    *parser
+	sc   *scope
+	args []string
+	pos  int
+}
+
+// test implements the test and [ commands.
+func (c *parser) test(sc *scope, args []string) bool {
+	t := &testExpr{c: c, sc: sc, args: args}
+	return t.or()
+}
+
+func (t *testExpr) peek(s string) bool {
+	return t.pos < len(t.args) && t.args[t.pos] == s
+}
+
+func (t *testExpr) or() bool {
+	v := t.and()
+	for t.peek("-o") {
+		t.pos++
+		w := t.and()
+		v = v || w
+	}
+	return v
+}
+
+func (t *testExpr) and() bool {
+	v := t.unary()
+	for t.peek("-a") {
+		t.pos++
+		w := t.unary()
+		v = v && w
+	}
+	return v
+}
+
+func (t *testExpr) unary() bool {
+	switch {
+	case t.peek("!"):
+		t.pos++
+		return !t.unary()
+	case t.peek("("):
+		t.pos++
+		v := t.or()
+		if t.peek(")") {
+			t.pos++
+		}
+		return v
+	}
+	return t.primary()
+}
+
+func (t *testExpr) primary() bool {
+	a := t.args[t.pos:]
+	if len(a) >= 3 {
+		if v, ok := compare(a[0], a[1], a[2]); ok {
+			t.pos += 3
+			return v
+		}
+	}
+	if len(a) >= 2 {
+		if v, ok := t.fileOrString(a[0], a[1]); ok {
+			t.pos += 2
+			return v
+		}
+	}
+	if len(a) == 0 {
+		return false
+	}
+	t.pos++
+	return a[0] != ""
+}
+
+// compare evaluates binary operator `op`, if it is one.
+func compare(x, op, y string) (bool, bool) {
+	switch op {
+	case "=", "==":
+		return x == y, true
+	case "!=":
+		return x != y, true
+	case "<":
+		return x < y, true
+	case ">":
+		return x > y, true
+	}
+	// Like GRUB, numbers that do not parse are 0.
+	a, _ := strconv.ParseInt(x, 10, 64)
+	b, _ := strconv.ParseInt(y, 10, 64)
+	switch op {
+	case "-eq":
+		return a == b, true
+	case "-ne":
+		return a != b, true
+	case "-lt":
+		return a < b, true
+	case "-le":
+		return a <= b, true
+	case "-gt":
+		return a > b, true
+	case "-ge":
+		return a >= b, true
+	}
+	return false, false
+}
+
+// fileOrString evaluates unary operator `op`, if it is one.
+func (t *testExpr) fileOrString(op, s string) (bool, bool) {
+	switch op {
+	case "-n":
+		return s != "", true
+	case "-z":
+		return s == "", true
+	case "-e", "-f", "-d", "-s":
+	default:
+		return false, false
+	}
+
+	// Only local files can be tested.
+	u, err := t.c.resolve(t.sc, s)
+	if err != nil || u.Scheme != "file" {
+		return false, true
+	}
+	fi, err := os.Stat(u.Path)
+	if err != nil {
+		return false, true
+	}
+	switch op {
+	case "-f":
+		return fi.Mode().IsRegular(), true
+	case "-d":
+		return fi.IsDir(), true
+	case "-s":
+		return fi.Size() > 0, true
+	}
+	return true, true
 }
@@ -5,9 +5,16 @@
 package grub
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/u-root/u-root/pkg/curl"
+	"github.com/u-root/u-root/pkg/mount"
 )
 
 func TestCmdlineQuote(t *testing.T) {
@@ -79,3 +86,169 @@ func TestFindkeywordGrubEnv(t *testing.T) {
 		})
 	}
 }
+
+// ubuntuConfig is the relevant part of the grub.cfg generated by
+// grub-mkconfig on Debian and Ubuntu.
+const ubuntuConfig = `
+if [ -s $prefix/grubenv ]; then
+  set have_grubenv=true
+  load_env
+fi
+if [ "${next_entry}" ] ; then
+   set default="${next_entry}"
+   set next_entry=
+   save_env next_entry
+   set boot_once=true
+else
+   set default="${saved_entry}"
+fi
+
+function savedefault {
+  if [ -z "${boot_once}" ]; then
+    saved_entry="${chosen}"
+    save_env saved_entry
+  fi
+}
+
+if [ x$feature_menuentry_id = xy ]; then
+  menuentry_id_option="--id"
+else
+  menuentry_id_option=""
+fi
+
+menuentry 'Ubuntu' --class ubuntu $menuentry_id_option 'gnulinux-simple-1234' {
+	savedefault
+	linux /boot/vmlinuz-6.8 root=UUID=1234 ro $vt_handoff
+	initrd /boot/initrd.img-6.8
+}
+submenu 'Advanced options for Ubuntu' $menuentry_id_option 'gnulinux-advanced-1234' {
+	menuentry 'Ubuntu, with Linux 6.8' --class ubuntu $menuentry_id_option 'gnulinux-6.8-advanced-1234' {
+		savedefault
+		linux /boot/vmlinuz-6.8 root=UUID=1234 ro
+	}
+	menuentry 'Ubuntu, with Linux 6.5' --class ubuntu $menuentry_id_option 'gnulinux-6.5-advanced-1234' {
+		savedefault
+		linux /boot/vmlinuz-6.5 root=UUID=1234 ro
+	}
+}
+menuentry 'UEFI Firmware Settings' $menuentry_id_option 'uefi-firmware' {
+	fwsetup
+}
+if [ "$grub_platform" = "efi" -o "$grub_platform" = "pc" ]; then
+	menuentry 'Memory test' {
+		linux16 /boot/memtest86+.bin
+	}
+fi
+`
+
+func TestDefaultEntry(t *testing.T) {
+	for _, tt := range []struct {
+		desc    string
+		grubenv string
+		want    []string
+	}{
+		{
+			desc: "no grubenv",
+			want: []string{
+				"Ubuntu",
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.8",
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.5",
+				"Memory test",
+			},
+		},
+		{
+			desc:    "saved entry by title",
+			grubenv: "saved_entry=Advanced options for Ubuntu>Ubuntu, with Linux 6.5\n",
+			want: []string{
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.5",
+				"Ubuntu",
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.8",
+				"Memory test",
+			},
+		},
+		{
+			desc:    "saved entry by id",
+			grubenv: "saved_entry=gnulinux-advanced-1234>gnulinux-6.8-advanced-1234\n",
+			want: []string{
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.8",
+				"Ubuntu",
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.5",
+				"Memory test",
+			},
+		},
+		{
+			desc:    "next entry by index overrides saved entry",
+			grubenv: "saved_entry=Ubuntu\nnext_entry=3\n",
+			want: []string{
+				"Memory test",
+				"Ubuntu",
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.8",
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.5",
+			},
+		},
+		{
+			desc:    "saved entry by index path",
+			grubenv: "saved_entry=1>1\n",
+			want: []string{
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.5",
+				"Ubuntu",
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.8",
+				"Memory test",
+			},
+		},
+		{
+			desc:    "unknown saved entry",
+			grubenv: "saved_entry=Debian\n",
+			want: []string{
+				"Ubuntu",
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.8",
+				"Advanced options for Ubuntu>Ubuntu, with Linux 6.5",
+				"Memory test",
+			},
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, "boot/grub"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, "boot/grub/grub.cfg"), []byte(ubuntuConfig), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.grubenv != "" {
+				env := "# GRUB Environment Block\n" + tt.grubenv
+				if err := os.WriteFile(filepath.Join(dir, "boot/grub/grubenv"), []byte(env), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			root := &url.URL{Scheme: "file", Path: dir}
+			imgs, err := ParseConfigFile(context.Background(), curl.DefaultSchemes, "boot/grub/grub.cfg", root, nil, &mount.Pool{})
+			if err != nil {
+				t.Fatalf("ParseConfigFile() = %v", err)
+			}
+			var got []string
+			for _, img := range imgs {
+				got = append(got, img.Label())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ParseConfigFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	for _, script := range []string{
+		"if true; then echo",
+		"while true; do echo; fi",
+		"menuentry foo",
+		"menuentry foo {",
+		"function {",
+		"}",
+	} {
+		if _, err := parseScript(script); !errors.Is(err, errSyntax) {
+			t.Errorf("parseScript(%q) = %v, want %v", script, err, errSyntax)
+		}
+	}
+}
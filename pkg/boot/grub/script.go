@@ -0,0 +1,554 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grub
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errSyntax = errors.New("syntax error")
+
+// wordPart is a piece of a word: literal text, or a variable to expand.
+type wordPart struct {
+	text string
+
+	// isVar is true if text is the name of a variable.
+	isVar bool
+
+	// quoted is true if the part was quoted. Unquoted variables are
+	// split into several words at whitespace, and keywords must not be
+	// quoted.
+	quoted bool
+}
+
+// word is a word of a command before expansion.
+type word []wordPart
+
+// keyword returns the word if it is a plain, unquoted word.
+func (w word) keyword() string {
+	var s strings.Builder
+	for _, p := range w {
+		if p.isVar || p.quoted {
+			return ""
+		}
+		s.WriteString(p.text)
+	}
+	return s.String()
+}
+
+type tokenKind uint8
+
+const (
+	tokWord tokenKind = iota
+	tokNewline
+	tokSemicolon
+	tokLBrace
+	tokRBrace
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	w    word
+	line int
+}
+
+// lexer splits a GRUB script into tokens following the quoting rules of
+// https://www.gnu.org/software/grub/manual/grub/grub.html#Quoting.
+type lexer struct {
+	s    string
+	pos  int
+	line int
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\v' || b == '\f'
+}
+
+func isHex(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
+func isNameChar(b byte) bool {
+	return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// isWordEnd returns whether the word ends at i.
+func (l *lexer) isWordEnd(i int) bool {
+	return i >= len(l.s) || isSpace(l.s[i]) || l.s[i] == '\n' || l.s[i] == ';'
+}
+
+func (l *lexer) next() token {
+	l.skip()
+	if l.pos >= len(l.s) {
+		return token{kind: tokEOF, line: l.line}
+	}
+	switch l.s[l.pos] {
+	case '\n':
+		l.pos++
+		l.line++
+		return token{kind: tokNewline, line: l.line - 1}
+	case ';':
+		l.pos++
+		return token{kind: tokSemicolon, line: l.line}
+	case '{', '}':
+		if l.isWordEnd(l.pos + 1) {
+			kind := tokLBrace
+			if l.s[l.pos] == '}' {
+				kind = tokRBrace
+			}
+			l.pos++
+			return token{kind: kind, line: l.line}
+		}
+	}
+	return token{kind: tokWord, w: l.word(), line: l.line}
+}
+
+// skip skips whitespace, line continuations and comments.
+func (l *lexer) skip() {
+	for l.pos < len(l.s) {
+		switch {
+		case isSpace(l.s[l.pos]):
+			l.pos++
+		case strings.HasPrefix(l.s[l.pos:], "\\\n"):
+			l.pos += 2
+			l.line++
+		case l.s[l.pos] == '#':
+			for l.pos < len(l.s) && l.s[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// word lexes a word starting at l.pos.
+func (l *lexer) word() word {
+	var w word
+	var lit strings.Builder
+	litQuoted := false
+	flush := func() {
+		if lit.Len() > 0 {
+			w = append(w, wordPart{text: lit.String(), quoted: litQuoted})
+			lit.Reset()
+		}
+	}
+	literal := func(s string, quoted bool) {
+		if quoted != litQuoted {
+			flush()
+			litQuoted = quoted
+		}
+		lit.WriteString(s)
+	}
+	variable := func(quoted bool) {
+		name, ok := l.variable()
+		if !ok {
+			literal("$", quoted)
+			return
+		}
+		flush()
+		w = append(w, wordPart{text: name, isVar: true, quoted: quoted})
+	}
+
+	for !l.isWordEnd(l.pos) {
+		c := l.s[l.pos]
+		switch c {
+		case '\\':
+			l.pos++
+			switch {
+			case l.pos >= len(l.s):
+			case l.s[l.pos] == '\n':
+				l.line++
+				l.pos++
+			case l.hexEscape(l.pos):
+				literal(`\`, true)
+			default:
+				literal(l.s[l.pos:l.pos+1], true)
+				l.pos++
+			}
+
+		case '\'':
+			l.pos++
+			end := strings.IndexByte(l.s[l.pos:], '\'')
+			if end < 0 {
+				end = len(l.s) - l.pos
+			}
+			l.line += strings.Count(l.s[l.pos:l.pos+end], "\n")
+			// An empty quoted string is still a word.
+			flush()
+			w = append(w, wordPart{text: l.s[l.pos : l.pos+end], quoted: true})
+			l.pos = min(l.pos+end+1, len(l.s))
+
+		case '"':
+			l.pos++
+			flush()
+			litQuoted = true
+			empty := true
+			for l.pos < len(l.s) && l.s[l.pos] != '"' {
+				empty = false
+				switch c := l.s[l.pos]; {
+				case c == '\\' && l.pos+1 < len(l.s):
+					switch e := l.s[l.pos+1]; e {
+					case '\n':
+						l.line++
+					case '$', '"', '\\':
+						literal(string(e), true)
+					default:
+						literal(`\`+string(e), true)
+					}
+					l.pos += 2
+				case c == '$':
+					l.pos++
+					variable(true)
+				default:
+					if c == '\n' {
+						l.line++
+					}
+					literal(string(c), true)
+					l.pos++
+				}
+			}
+			if empty {
+				w = append(w, wordPart{quoted: true})
+			}
+			l.pos = min(l.pos+1, len(l.s))
+
+		case '$':
+			l.pos++
+			variable(false)
+
+		default:
+			literal(string(c), false)
+			l.pos++
+		}
+	}
+	flush()
+	return w
+}
+
+// hexEscape returns whether \ followed by the text at i is a \xXX escape.
+//
+// Grub syntax for OpenSUSE/Fedora/RHEL has some undocumented quirks. You
+// won't find it on the master branch, but instead look at the rhel and fedora
+// branches for these commits:
+//
+// * https://github.com/rhboot/grub2/commit/7e6775e6d4a8de9baf3f4676d4e021cc2f5dd761
+// * https://github.com/rhboot/grub2/commit/0c26c6f7525737962d1389ebdfbb918f52d1b3b6
+//
+// They add a special case to not escape hex sequences:
+//
+//	grub> echo hello \xff \xfg
+//	hello \xff xfg
+//
+// Their default installations depend on this functionality.
+func (l *lexer) hexEscape(i int) bool {
+	return i+2 < len(l.s) && l.s[i] == 'x' && isHex(l.s[i+1]) && isHex(l.s[i+2])
+}
+
+// variable lexes a variable name after a $: ${name}, $name, or one of the
+// special variables $?, $#, $@ and $*.
+func (l *lexer) variable() (string, bool) {
+	if l.pos >= len(l.s) {
+		return "", false
+	}
+	switch c := l.s[l.pos]; {
+	case c == '{':
+		end := strings.IndexByte(l.s[l.pos:], '}')
+		if end < 0 {
+			return "", false
+		}
+		name := l.s[l.pos+1 : l.pos+end]
+		l.pos += end + 1
+		return name, true
+	case c == '?' || c == '#' || c == '@' || c == '*':
+		l.pos++
+		return string(c), true
+	case isNameChar(c):
+		start := l.pos
+		for l.pos < len(l.s) && isNameChar(l.s[l.pos]) {
+			l.pos++
+		}
+		return l.s[start:l.pos], true
+	}
+	return "", false
+}
+
+// command is a parsed GRUB command.
+type command interface{}
+
+// simpleCommand runs a command or function.
+type simpleCommand struct {
+	args []word
+
+	// negate is true for "! command".
+	negate bool
+}
+
+// ifCommand is if cond; then body; [elif cond; then body;]... [else body;] fi.
+type ifCommand struct {
+	conds  [][]command
+	bodies [][]command
+	els    []command
+}
+
+// loopCommand is while cond; do body; done, or until.
+type loopCommand struct {
+	until bool
+	cond  []command
+	body  []command
+}
+
+// forCommand is for name in items; do body; done.
+type forCommand struct {
+	name  string
+	items []word
+	body  []command
+}
+
+// functionCommand is function name { body }.
+type functionCommand struct {
+	name string
+	body []command
+}
+
+// menuCommand is menuentry, submenu or hiddenentry args { body }.
+type menuCommand struct {
+	kind string
+	args []word
+	body []command
+}
+
+// scriptParser parses tokens into commands.
+type scriptParser struct {
+	l   *lexer
+	tok token
+}
+
+// parseScript parses a GRUB script.
+func parseScript(s string) ([]command, error) {
+	p := &scriptParser{l: &lexer{s: s, line: 1}}
+	p.advance()
+	cmds, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+	return cmds, nil
+}
+
+func (p *scriptParser) advance() {
+	p.tok = p.l.next()
+}
+
+func (p *scriptParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", errSyntax, p.tok.line, fmt.Sprintf(format, args...))
+}
+
+func (p *scriptParser) describe() string {
+	switch p.tok.kind {
+	case tokWord:
+		return fmt.Sprintf("%q", p.tok.w.keyword())
+	case tokNewline:
+		return "newline"
+	case tokSemicolon:
+		return "';'"
+	case tokLBrace:
+		return "'{'"
+	case tokRBrace:
+		return "'}'"
+	}
+	return "end of file"
+}
+
+// atKeyword returns whether the current token is one of the keywords.
+func (p *scriptParser) atKeyword(keywords ...string) bool {
+	if p.tok.kind != tokWord {
+		return false
+	}
+	kw := p.tok.w.keyword()
+	for _, k := range keywords {
+		if kw == k {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *scriptParser) skipSeparators() {
+	for p.tok.kind == tokNewline || p.tok.kind == tokSemicolon {
+		p.advance()
+	}
+}
+
+func (p *scriptParser) expectKeyword(kw string) error {
+	p.skipSeparators()
+	if !p.atKeyword(kw) {
+		return p.errorf("expected %q, got %s", kw, p.describe())
+	}
+	p.advance()
+	return nil
+}
+
+// list parses commands up to one of the terminating keywords, a '}' or the
+// end of the script, which are not consumed.
+func (p *scriptParser) list(terminators ...string) ([]command, error) {
+	var cmds []command
+	for {
+		p.skipSeparators()
+		if p.tok.kind == tokEOF || p.tok.kind == tokRBrace || p.atKeyword(terminators...) {
+			return cmds, nil
+		}
+		cmd, err := p.command()
+		if err != nil {
+			return nil, err
+		}
+		cmds = append(cmds, cmd)
+	}
+}
+
+// block parses { commands }.
+func (p *scriptParser) block() ([]command, error) {
+	for p.tok.kind == tokNewline {
+		p.advance()
+	}
+	if p.tok.kind != tokLBrace {
+		return nil, p.errorf("expected '{', got %s", p.describe())
+	}
+	p.advance()
+	body, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokRBrace {
+		return nil, p.errorf("expected '}', got %s", p.describe())
+	}
+	p.advance()
+	return body, nil
+}
+
+func (p *scriptParser) command() (command, error) {
+	if p.tok.kind != tokWord {
+		return nil, p.errorf("unexpected %s", p.describe())
+	}
+	switch kw := p.tok.w.keyword(); kw {
+	case "if":
+		p.advance()
+		c := &ifCommand{}
+		for {
+			cond, err := p.list("then")
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("then"); err != nil {
+				return nil, err
+			}
+			body, err := p.list("elif", "else", "fi")
+			if err != nil {
+				return nil, err
+			}
+			c.conds = append(c.conds, cond)
+			c.bodies = append(c.bodies, body)
+			if !p.atKeyword("elif") {
+				break
+			}
+			p.advance()
+		}
+		if p.atKeyword("else") {
+			p.advance()
+			els, err := p.list("fi")
+			if err != nil {
+				return nil, err
+			}
+			// An empty else still differs from no else for $?, but
+			// that is not worth tracking.
+			c.els = els
+		}
+		return c, p.expectKeyword("fi")
+
+	case "while", "until":
+		p.advance()
+		cond, err := p.list("do")
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("do"); err != nil {
+			return nil, err
+		}
+		body, err := p.list("done")
+		if err != nil {
+			return nil, err
+		}
+		return &loopCommand{until: kw == "until", cond: cond, body: body}, p.expectKeyword("done")
+
+	case "for":
+		p.advance()
+		if p.tok.kind != tokWord {
+			return nil, p.errorf("expected variable name, got %s", p.describe())
+		}
+		c := &forCommand{name: p.tok.w.keyword()}
+		p.advance()
+		if !p.atKeyword("in") {
+			return nil, p.errorf("expected \"in\", got %s", p.describe())
+		}
+		p.advance()
+		for p.tok.kind == tokWord {
+			c.items = append(c.items, p.tok.w)
+			p.advance()
+		}
+		if err := p.expectKeyword("do"); err != nil {
+			return nil, err
+		}
+		body, err := p.list("done")
+		if err != nil {
+			return nil, err
+		}
+		c.body = body
+		return c, p.expectKeyword("done")
+
+	case "function":
+		p.advance()
+		if p.tok.kind != tokWord {
+			return nil, p.errorf("expected function name, got %s", p.describe())
+		}
+		c := &functionCommand{name: p.tok.w.keyword()}
+		p.advance()
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		c.body = body
+		return c, nil
+
+	case "menuentry", "submenu", "hiddenentry":
+		p.advance()
+		c := &menuCommand{kind: kw}
+		for p.tok.kind == tokWord {
+			c.args = append(c.args, p.tok.w)
+			p.advance()
+		}
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		c.body = body
+		return c, nil
+	}
+
+	c := &simpleCommand{}
+	if p.atKeyword("!") {
+		c.negate = true
+		p.advance()
+	}
+	for p.tok.kind == tokWord {
+		c.args = append(c.args, p.tok.w)
+		p.advance()
+	}
+	return c, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// RISCVMagic is the deprecated magic value of the RISC-V Image
+	// header, "RISCV".
+	RISCVMagic = 0x5643534952
+	// RISCVMagic2 is the magic value of the RISC-V Image header since
+	// header version 0.2, "RSC\x05".
+	RISCVMagic2 = 0x05435352
+)
+
+// RISCVHeader is header for RISC-V Image.
+//
+// See https://docs.kernel.org/arch/riscv/boot-image-header.html.
+type RISCVHeader struct {
+	Code0      uint32 `offset:"0x00"`
+	Code1      uint32 `offset:"0x04"`
+	TextOffset uint64 `offset:"0x08"`
+	ImageSize  uint64 `offset:"0x10"`
+	Flags      uint64 `offset:"0x18"`
+	Version    uint32 `offset:"0x20"`
+	Res1       uint32 `offset:"0x24"`
+	Res2       uint64 `offset:"0x28"`
+	Magic      uint64 `offset:"0x30"`
+	Magic2     uint32 `offset:"0x38"`
+	Res3       uint32 `offset:"0x3c"`
+}
+
+// RISCVImage is a RISC-V Linux Image.
+type RISCVImage struct {
+	Header RISCVHeader
+	Data   []byte
+}
+
+// ParseRISCVFromBytes parses a RISC-V Image from its bytes.
+func ParseRISCVFromBytes(data []byte) (*RISCVImage, error) {
+	img := &RISCVImage{}
+
+	if err := binary.Read(bytes.NewBuffer(data), binary.LittleEndian, &img.Header); err != nil {
+		return img, fmt.Errorf("unmarshaling RISC-V header: %w", err)
+	}
+
+	if img.Header.Magic2 != RISCVMagic2 && img.Header.Magic != RISCVMagic {
+		return img, errBadMagic
+	}
+
+	// Bit 0 of the flags is the kernel endianness, 1 if big endian.
+	if img.Header.Flags&0x1 != 0 {
+		return img, errBadEndianness
+	}
+
+	// Images of old kernels may not have the image size set.
+	if img.Header.ImageSize == 0 {
+		img.Header.ImageSize = kernelImageSize
+	}
+
+	img.Data = data
+
+	return img, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func riscvImage(t *testing.T, h RISCVHeader) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := binary.Write(&b, binary.LittleEndian, h); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestParseRISCVFromBytes(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		header  RISCVHeader
+		want    RISCVHeader
+		wantErr error
+	}{
+		{
+			name:   "v0.2",
+			header: RISCVHeader{Code0: 0x5a4d, TextOffset: 0, ImageSize: 0x1400000, Version: 0x2, Magic: RISCVMagic, Magic2: RISCVMagic2},
+			want:   RISCVHeader{Code0: 0x5a4d, TextOffset: 0, ImageSize: 0x1400000, Version: 0x2, Magic: RISCVMagic, Magic2: RISCVMagic2},
+		},
+		{
+			name:   "deprecated magic without image size",
+			header: RISCVHeader{TextOffset: 0x200000, Magic: RISCVMagic},
+			want:   RISCVHeader{TextOffset: 0x200000, ImageSize: kernelImageSize, Magic: RISCVMagic},
+		},
+		{
+			name:    "arm64",
+			header:  RISCVHeader{ImageSize: 0x1400000, Magic2: Magic},
+			wantErr: errBadMagic,
+		},
+		{
+			name:    "big endian",
+			header:  RISCVHeader{ImageSize: 0x1400000, Flags: 0x1, Magic2: RISCVMagic2},
+			wantErr: errBadEndianness,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data := riscvImage(t, tt.header)
+			got, err := ParseRISCVFromBytes(data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseRISCVFromBytes() = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Header != tt.want {
+				t.Errorf("got %+v, want %+v", got.Header, tt.want)
+			}
+			if !bytes.Equal(got.Data, data) {
+				t.Errorf("Data does not match the image")
+			}
+		})
+	}
+
+	if _, err := ParseRISCVFromBytes([]byte{0x4d, 0x5a}); err == nil {
+		t.Errorf("ParseRISCVFromBytes(short) = nil, want error")
+	}
+}
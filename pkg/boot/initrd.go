@@ -15,22 +15,53 @@ import (
 	"github.com/u-root/uio/uio"
 )
 
+// catInitrd is an initrd concatenated from parts. It has no signature of its
+// own, so a Verifier is asked to check each of the parts instead.
+type catInitrd struct {
+	*uio.LazyOpenerAt
+	parts []io.ReaderAt
+}
+
+// fileCache reads r into a tmpfs file on first ReadAt call, so that it can be
+// read more than once, e.g. to verify it and to concatenate it.
+func fileCache(r io.Reader) io.ReaderAt {
+	if ra, ok := r.(io.ReaderAt); ok {
+		return ra
+	}
+	return uio.NewLazyOpenerAt(stringer(r), func() (io.ReaderAt, error) {
+		f, err := os.CreateTemp("", "initrd")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return f, nil
+	})
+}
+
 // CatInitrdsWithFileCache lazily reads up multiple initrds into single tmpfs file
 // and return a os.File disguising as a io.ReaderAt.
 // It starts processing after first ReadAt call is made.
+//
+// Initrds that are not io.ReaderAts are first read into their own tmpfs file,
+// so that a Verifier can check each of them.
 func CatInitrdsWithFileCache(initrds ...io.Reader) io.ReaderAt {
 	var names []string
+	var parts []io.ReaderAt
 	for _, initrd := range initrds {
 		names = append(names, stringer(initrd))
+		parts = append(parts, fileCache(initrd))
 	}
-	return uio.NewLazyOpenerAt(strings.Join(names, ","), func() (io.ReaderAt, error) {
+	cat := uio.NewLazyOpenerAt(strings.Join(names, ","), func() (io.ReaderAt, error) {
 		f, err := os.CreateTemp("", "combined-initrd")
 		if err != nil {
 			return nil, err
 		}
 		defer f.Close()
-		for i, ireader := range initrds {
-			size, err := io.Copy(f, ireader)
+		for i, ireader := range parts {
+			size, err := io.Copy(f, uio.Reader(ireader))
 			if err != nil {
 				return nil, err
 			}
@@ -56,6 +87,7 @@ func CatInitrdsWithFileCache(initrds ...io.Reader) io.ReaderAt {
 		}
 		return readOnlyF, nil
 	})
+	return &catInitrd{LazyOpenerAt: cat, parts: parts}
 }
 
 // CatInitrds concatenates initrds on first ReadAt call from a list of
@@ -66,7 +98,7 @@ func CatInitrds(initrds ...io.ReaderAt) io.ReaderAt {
 		names = append(names, stringer(initrd))
 	}
 
-	return uio.NewLazyOpenerAt(strings.Join(names, ","), func() (io.ReaderAt, error) {
+	cat := uio.NewLazyOpenerAt(strings.Join(names, ","), func() (io.ReaderAt, error) {
 		buf := new(bytes.Buffer)
 		for i, ireader := range initrds {
 			size, err := buf.ReadFrom(uio.Reader(ireader))
@@ -82,6 +114,7 @@ func CatInitrds(initrds ...io.ReaderAt) io.ReaderAt {
 		// Buffer doesn't implement ReadAt, so wrap in NewReader
 		return bytes.NewReader(buf.Bytes()), nil
 	})
+	return &catInitrd{LazyOpenerAt: cat, parts: initrds}
 }
 
 // CreateInitrd creates an initrd with the collection of files passed in.
@@ -83,39 +83,23 @@ func isRemovable(b *block.BlockDev) (bool, error) {
 	return strings.TrimSpace(string(data)) == "1", nil
 }
 
-func parseImg(img boot.OSImage, isoloc, fsuuid string) (boot.OSImage, bool) {
-	if li, ok := img.(*boot.LinuxImage); ok {
-		// grubMapper expands vars set by grub parser
-		// NOTE grub parser does not process any logic,
-		// and just uses the last var "set"
-		grubMapper := func(k string) string {
-			if v, ok := li.Env[k]; ok {
-				return v
-			}
-			return fmt.Sprintf("${%s}", k)
-		}
-
-		// manualMapper expands known vars used for loopback
-		imgParsed := false
-		manualMapper := func(s string) string {
-			switch s {
-			case "iso_path": // happy path
-				imgParsed = true
-				return fmt.Sprintf("/%s", isoloc)
-
-			// Arch Linux
-			case "archiso_img_dev_uuid":
-				return fsuuid
-			case "archiso_platform": // used in li.Name
-				return "BIOS"
-			}
-			return fmt.Sprintf("${%s}", s)
-		}
+// loopbackVars returns the variables a loopback.cfg expects to be set by
+// the config booting the ISO.
+func loopbackVars(isoloc, fsuuid string) map[string]string {
+	return map[string]string{
+		"iso_path": fmt.Sprintf("/%s", isoloc),
+
+		// Arch Linux
+		"archiso_img_dev_uuid": fsuuid,
+		"archiso_platform":     "BIOS", // used in li.Name
+	}
+}
 
-		li.Name = fmt.Sprintf("[%s] %s", isoloc, os.Expand(li.Name, manualMapper))
-		li.Cmdline = os.Expand(li.Cmdline, grubMapper)
-		li.Cmdline = os.Expand(li.Cmdline, manualMapper)
-		if imgParsed {
+func parseImg(img boot.OSImage, isoloc string) (boot.OSImage, bool) {
+	if li, ok := img.(*boot.LinuxImage); ok {
+		li.Name = fmt.Sprintf("[%s] %s", isoloc, li.Name)
+		// The grub parser expanded $iso_path, the happy path.
+		if strings.Contains(li.Cmdline, fmt.Sprintf("/%s", isoloc)) {
 			return img, true
 		}
 		// Other tests for ISO, where var sub is not used OR var not defined using "set"
@@ -171,17 +155,18 @@ func ParseISOFiles(l ulog.Logger, mountDir string, dev *block.BlockDev, mountPoo
 			Scheme: "file",
 			Path:   dir,
 		}
+		vars := grub.WithVars(loopbackVars(isoloc, dev.FsUUID))
 		// first try loopback.cfg
 		var isoImgs []boot.OSImage
 		for _, cfgfile := range probeLoopbackFiles {
-			isoImgs, err = grub.ParseConfigFile(context.Background(), curl.DefaultSchemes, cfgfile, root, nil, nil)
+			isoImgs, err = grub.ParseConfigFile(context.Background(), curl.DefaultSchemes, cfgfile, root, nil, nil, vars)
 			if err == nil && len(isoImgs) > 0 {
 				break
 			}
 		}
 		// failing that, try the usual grub method
 		if len(isoImgs) == 0 {
-			isoImgs, err = grub.ParseLocalConfig(context.Background(), dir, nil, nil)
+			isoImgs, err = grub.ParseLocalConfig(context.Background(), dir, nil, nil, vars)
 			if err != nil {
 				return nil
 			}
@@ -189,7 +174,7 @@ func ParseISOFiles(l ulog.Logger, mountDir string, dev *block.BlockDev, mountPoo
 
 		imgFound := false
 		for _, img := range isoImgs {
-			if i, ok := parseImg(img, isoloc, dev.FsUUID); ok {
+			if i, ok := parseImg(img, isoloc); ok {
 				imgFound = true
 				images = append(images, i)
 			}
@@ -0,0 +1,314 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kexec
+
+import (
+	"bufio"
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrNoCrashKernel is returned when no memory is reserved for a crash kernel.
+var ErrNoCrashKernel = errors.New("no memory reserved for a crash kernel, boot with crashkernel=")
+
+var (
+	iomemPath      = "/proc/iomem"
+	cpuRoot        = "/sys/devices/system/cpu"
+	vmcoreinfoPath = "/sys/kernel/vmcoreinfo"
+)
+
+// CrashMemory describes the memory of the running kernel from the point of
+// view of a crash kernel, i.e. a kernel loaded with KEXEC_ON_CRASH that is
+// booted when the running kernel panics and exposes its memory as
+// /proc/vmcore.
+type CrashMemory struct {
+	// Crash is the memory reserved with crashkernel=. The crash kernel is
+	// loaded and runs in it.
+	Crash Ranges
+
+	// RAM is the System RAM of the running kernel outside of Crash, i.e.
+	// the memory to be dumped.
+	RAM Ranges
+
+	// ACPI holds the ACPI tables and non-volatile storage, which the crash
+	// kernel has to leave alone.
+	ACPI Ranges
+
+	// Notes are the per-CPU crash notes and the VMCOREINFO note of the
+	// running kernel.
+	Notes Ranges
+
+	// Backup, if not empty, is where purgatory saves the memory in
+	// CrashBackupSource before the crash kernel reuses it. It must be in
+	// Crash, and the ELF core headers point at it for that memory.
+	Backup Range
+}
+
+// CrashBackupSource is the memory below 640K, which an x86 crash kernel needs
+// to boot its other CPUs. It is saved to CrashMemory.Backup on panic, like
+// kexec-tools does.
+var CrashBackupSource = Range{Start: 0, Size: 640 << 10}
+
+// CrashMemoryFromIOMem reads the memory reserved for a crash kernel and the
+// memory to dump from /proc/iomem, and the location of the crash notes from
+// sysfs.
+//
+// It returns ErrNoCrashKernel if no memory is reserved.
+func CrashMemoryFromIOMem() (*CrashMemory, error) {
+	f, err := os.Open(iomemPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cm, err := crashMemoryFromIOMem(f)
+	if err != nil {
+		return nil, err
+	}
+	if cm.Notes, err = crashNotes(cpuRoot, vmcoreinfoPath); err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+func crashMemoryFromIOMem(r io.Reader) (*CrashMemory, error) {
+	var ram Ranges
+	cm := &CrashMemory{}
+	b := bufio.NewScanner(r)
+	for b.Scan() {
+		// Format, with nested resources indented:
+		//   100000000-23fffffff : System RAM
+		//     1e0000000-1efffffff : Crash kernel
+		line := b.Text()
+		addrs, typ, ok := strings.Cut(line, " : ")
+		if !ok {
+			continue
+		}
+		start, end, ok := strings.Cut(strings.TrimSpace(addrs), "-")
+		if !ok {
+			continue
+		}
+		s, err := strconv.ParseUint(start, 16, 64)
+		if err != nil {
+			continue
+		}
+		e, err := strconv.ParseUint(end, 16, 64)
+		if err != nil || s == e {
+			continue
+		}
+		rng := RangeFromInclusiveInterval(uintptr(s), uintptr(e))
+
+		// System RAM is dumped as a whole, including the kernel
+		// itself, which shows up as nested resources.
+		toplevel := !strings.HasPrefix(line, " ")
+		switch RangeType(typ) {
+		case RangeRAM:
+			if toplevel {
+				ram = append(ram, rng)
+			}
+		case RangeCrashKernel:
+			cm.Crash = append(cm.Crash, rng)
+		case RangeACPI, RangeNVS:
+			cm.ACPI = append(cm.ACPI, rng)
+		}
+	}
+	if err := b.Err(); err != nil {
+		return nil, err
+	}
+	if len(cm.Crash) == 0 {
+		return nil, ErrNoCrashKernel
+	}
+	for _, c := range cm.Crash {
+		ram = ram.Minus(c)
+	}
+	cm.RAM = ram
+	cm.RAM.Sort()
+	return cm, nil
+}
+
+// crashNotes returns the location of the crash notes the running kernel
+// fills in on panic: one per CPU, and the VMCOREINFO note describing the
+// kernel's data structures. Missing notes are skipped.
+func crashNotes(cpuRoot, vmcoreinfo string) (Ranges, error) {
+	var notes Ranges
+	cpus, err := filepath.Glob(filepath.Join(cpuRoot, "cpu[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+	for _, cpu := range cpus {
+		// crash_notes is a hexadecimal address without 0x, and
+		// crash_notes_size a decimal size.
+		addr, err := readUint(filepath.Join(cpu, "crash_notes"), 16)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		size, err := readUint(filepath.Join(cpu, "crash_notes_size"), 10)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, Range{Start: uintptr(addr), Size: uint(size)})
+	}
+
+	b, err := os.ReadFile(vmcoreinfo)
+	if errors.Is(err, os.ErrNotExist) {
+		return notes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Format: "<hex address> <hex size>".
+	var addr, size uint64
+	if _, err := fmt.Sscanf(string(b), "%x %x", &addr, &size); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", vmcoreinfo, err)
+	}
+	return append(notes, Range{Start: uintptr(addr), Size: uint(size)}), nil
+}
+
+func readUint(name string, base int) (uint64, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(strings.TrimSpace(string(b)), base, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// MemoryMap returns the memory map crash kernel segments are allocated from:
+// the crash kernel memory is the only RAM in it.
+func (cm *CrashMemory) MemoryMap() MemoryMap {
+	var mm MemoryMap
+	for _, r := range cm.Crash {
+		mm = append(mm, TypedRange{Range: r, Type: RangeRAM})
+	}
+	mm.sort()
+	return mm
+}
+
+var elfMachines = map[string]elf.Machine{
+	"amd64":   elf.EM_X86_64,
+	"arm64":   elf.EM_AARCH64,
+	"riscv64": elf.EM_RISCV,
+}
+
+// ElfCoreHeader returns the ELF core headers of the running kernel, which
+// the crash kernel finds through elfcorehdr= and turns into /proc/vmcore.
+//
+// Notes become PT_NOTE and RAM becomes PT_LOAD program headers, with their
+// offset and physical address set to the physical address of the memory,
+// except that RAM in CrashBackupSource is read from Backup if it is set.
+// Virtual addresses are left zero; dump tools translate addresses using
+// VMCOREINFO.
+func (cm *CrashMemory) ElfCoreHeader() ([]byte, error) {
+	machine, ok := elfMachines[runtime.GOARCH]
+	if !ok {
+		return nil, fmt.Errorf("ELF core headers are not supported on %s", runtime.GOARCH)
+	}
+
+	var progs []elf.Prog64
+	for _, n := range cm.Notes {
+		progs = append(progs, elf.Prog64{
+			Type:   uint32(elf.PT_NOTE),
+			Off:    uint64(n.Start),
+			Paddr:  uint64(n.Start),
+			Filesz: uint64(n.Size),
+			Memsz:  uint64(n.Size),
+		})
+	}
+	load := func(r Range, off uintptr) {
+		progs = append(progs, elf.Prog64{
+			Type:   uint32(elf.PT_LOAD),
+			Flags:  uint32(elf.PF_R | elf.PF_W | elf.PF_X),
+			Off:    uint64(off),
+			Paddr:  uint64(r.Start),
+			Filesz: uint64(r.Size),
+			Memsz:  uint64(r.Size),
+		})
+	}
+	for _, r := range cm.RAM {
+		if cm.Backup.Size == 0 || r.Disjunct(CrashBackupSource) {
+			load(r, r.Start)
+			continue
+		}
+		// Split off the saved part, which is read from the backup.
+		// Nothing is below CrashBackupSource, so the rest follows it.
+		saved := r.Intersect(CrashBackupSource)
+		load(*saved, cm.Backup.Start+saved.Start-CrashBackupSource.Start)
+		for _, rest := range r.Minus(CrashBackupSource) {
+			load(rest, rest.Start)
+		}
+	}
+
+	hdr := elf.Header64{
+		Type:      uint16(elf.ET_CORE),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     uint64(binary.Size(elf.Header64{})),
+		Ehsize:    uint16(binary.Size(elf.Header64{})),
+		Phentsize: uint16(binary.Size(elf.Prog64{})),
+		Phnum:     uint16(len(progs)),
+	}
+	copy(hdr.Ident[:], elf.ELFMAG)
+	hdr.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	hdr.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	hdr.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var b bytes.Buffer
+	if err := binary.Write(&b, binary.LittleEndian, hdr); err != nil {
+		return nil, err
+	}
+	if err := binary.Write(&b, binary.LittleEndian, progs); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// KernelArgs returns the command line arguments telling an x86 crash kernel
+// where to find the ELF core headers and which memory it may use.
+//
+// Crash kernels loaded with kexec_load are given the firmware memory map of
+// the running kernel, so memmap=exactmap replaces it with CrashBackupSource,
+// the crash kernel memory minus the ELF core headers at elfcorehdr and the
+// backup, and the ACPI memory.
+func (cm *CrashMemory) KernelArgs(elfcorehdr Range) string {
+	args := []string{
+		fmt.Sprintf("elfcorehdr=%dK", elfcorehdr.Start>>10),
+		"memmap=exactmap",
+		memmapArg(CrashBackupSource, '@'),
+	}
+	usable := cm.Crash.Minus(elfcorehdr)
+	if cm.Backup.Size != 0 {
+		usable = usable.Minus(cm.Backup)
+	}
+	for _, r := range usable {
+		args = append(args, memmapArg(r, '@'))
+	}
+	for _, r := range cm.ACPI {
+		args = append(args, memmapArg(r, '#'))
+	}
+	return strings.Join(args, " ")
+}
+
+// memmapArg formats a memmap=size<sep>start argument, in KiB like
+// kexec-tools.
+func memmapArg(r Range, sep byte) string {
+	start := r.Start >> 10
+	end := (r.End() + 1<<10 - 1) >> 10
+	return fmt.Sprintf("memmap=%dK%c%dK", end-start, sep, start)
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kexec
+
+import (
+	"bytes"
+	"debug/elf"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const crashIOMem = `00000000-00000fff : Reserved
+00001000-0009fbff : System RAM
+0009fc00-0009ffff : Reserved
+000f0000-000fffff : System ROM
+00100000-bffdafff : System RAM
+  0f000000-0fffffff : Crash kernel
+  2a400000-2b2fffff : Kernel code
+  2b400000-2bd6bfff : Kernel rodata
+bffdb000-bfffffff : Reserved
+  bfffe000-bfffefff : ACPI Tables
+fed00000-fed003ff : PNP0103:00
+100000000-13fffffff : System RAM
+`
+
+func TestCrashMemoryFromIOMem(t *testing.T) {
+	cm, err := crashMemoryFromIOMem(strings.NewReader(crashIOMem))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &CrashMemory{
+		Crash: Ranges{RangeFromInterval(0x0f000000, 0x10000000)},
+		RAM: Ranges{
+			RangeFromInterval(0x1000, 0x9fc00),
+			RangeFromInterval(0x100000, 0x0f000000),
+			RangeFromInterval(0x10000000, 0xbffdb000),
+			RangeFromInterval(0x100000000, 0x140000000),
+		},
+		ACPI: Ranges{RangeFromInterval(0xbfffe000, 0xbffff000)},
+	}
+	if !reflect.DeepEqual(cm, want) {
+		t.Errorf("crashMemoryFromIOMem() = %+v, want %+v", cm, want)
+	}
+
+	wantMap := MemoryMap{{Range: RangeFromInterval(0x0f000000, 0x10000000), Type: RangeRAM}}
+	if mm := cm.MemoryMap(); !reflect.DeepEqual(mm, wantMap) {
+		t.Errorf("MemoryMap() = %v, want %v", mm, wantMap)
+	}
+
+	noCrash := "00100000-bffdafff : System RAM\n"
+	if _, err := crashMemoryFromIOMem(strings.NewReader(noCrash)); !errors.Is(err, ErrNoCrashKernel) {
+		t.Errorf("crashMemoryFromIOMem() without crash kernel = %v, want %v", err, ErrNoCrashKernel)
+	}
+}
+
+func TestCrashNotes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"cpu/cpu0/crash_notes":      "13fd1e8c0\n",
+		"cpu/cpu0/crash_notes_size": "464\n",
+		"cpu/cpu1/crash_notes":      "13fd5e8c0\n",
+		"cpu/cpu1/crash_notes_size": "464\n",
+		"cpu/cpufreq/boost":         "1\n",
+		"vmcoreinfo":                "1a2c3000 1024\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notes, err := crashNotes(filepath.Join(dir, "cpu"), filepath.Join(dir, "vmcoreinfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Ranges{
+		{Start: 0x13fd1e8c0, Size: 464},
+		{Start: 0x13fd5e8c0, Size: 464},
+		{Start: 0x1a2c3000, Size: 0x1024},
+	}
+	if !reflect.DeepEqual(notes, want) {
+		t.Errorf("crashNotes() = %v, want %v", notes, want)
+	}
+
+	notes, err = crashNotes(filepath.Join(dir, "nocpus"), filepath.Join(dir, "novmcoreinfo"))
+	if err != nil || notes != nil {
+		t.Errorf("crashNotes() without notes = %v, %v, want nil, nil", notes, err)
+	}
+}
+
+func TestElfCoreHeader(t *testing.T) {
+	if _, ok := elfMachines[runtime.GOARCH]; !ok {
+		t.Skipf("ELF core headers are not supported on %s", runtime.GOARCH)
+	}
+
+	type prog struct {
+		typ        elf.ProgType
+		off, paddr uint64
+		size       uint64
+	}
+	for _, tt := range []struct {
+		name string
+		cm   *CrashMemory
+		want []prog
+	}{
+		{
+			name: "no backup",
+			cm: &CrashMemory{
+				RAM:   Ranges{RangeFromInterval(0x100000, 0x0f000000), RangeFromInterval(0x10000000, 0xbffdb000)},
+				Notes: Ranges{{Start: 0x13fd1e8c0, Size: 464}},
+			},
+			want: []prog{
+				{elf.PT_NOTE, 0x13fd1e8c0, 0x13fd1e8c0, 464},
+				{elf.PT_LOAD, 0x100000, 0x100000, 0x0ef00000},
+				{elf.PT_LOAD, 0x10000000, 0x10000000, 0xaffdb000},
+			},
+		},
+		{
+			name: "backup",
+			cm: &CrashMemory{
+				RAM:    Ranges{RangeFromInterval(0x1000, 0xc0000), RangeFromInterval(0x100000, 0x0f000000)},
+				Notes:  Ranges{{Start: 0x13fd1e8c0, Size: 464}},
+				Backup: Range{Start: 0x0f001000, Size: 640 << 10},
+			},
+			want: []prog{
+				{elf.PT_NOTE, 0x13fd1e8c0, 0x13fd1e8c0, 464},
+				{elf.PT_LOAD, 0x0f002000, 0x1000, 0x9f000},
+				{elf.PT_LOAD, 0xa0000, 0xa0000, 0x20000},
+				{elf.PT_LOAD, 0x100000, 0x100000, 0x0ef00000},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			hdr, err := tt.cm.ElfCoreHeader()
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := elf.NewFile(bytes.NewReader(hdr))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Type != elf.ET_CORE || f.Machine != elfMachines[runtime.GOARCH] || f.Class != elf.ELFCLASS64 {
+				t.Errorf("ELF header = %v, %v, %v, want core file for %s", f.Type, f.Machine, f.Class, runtime.GOARCH)
+			}
+
+			var got []prog
+			for _, p := range f.Progs {
+				if p.Filesz != p.Memsz {
+					t.Errorf("%v: file size %#x != memory size %#x", p, p.Filesz, p.Memsz)
+				}
+				got = append(got, prog{p.Type, p.Off, p.Paddr, p.Memsz})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("program headers = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKernelArgs(t *testing.T) {
+	cm := &CrashMemory{
+		Crash: Ranges{RangeFromInterval(0x0f000000, 0x10000000)},
+		ACPI:  Ranges{RangeFromInterval(0xbfffe000, 0xbffff000)},
+	}
+	got := cm.KernelArgs(Range{Start: 0x0f000000, Size: 0x1000})
+	want := "elfcorehdr=245760K memmap=exactmap memmap=640K@0K memmap=16380K@245764K memmap=4K#3145720K"
+	if got != want {
+		t.Errorf("KernelArgs() = %q, want %q", got, want)
+	}
+
+	cm.Backup = Range{Start: 0x0f001000, Size: 640 << 10}
+	got = cm.KernelArgs(Range{Start: 0x0f000000, Size: 0x1000})
+	want = "elfcorehdr=245760K memmap=exactmap memmap=640K@0K memmap=15740K@246404K memmap=4K#3145720K"
+	if got != want {
+		t.Errorf("KernelArgs() with backup = %q, want %q", got, want)
+	}
+}
@@ -18,7 +18,19 @@ import (
 //
 // The kexec_file_load(2) syscall is x86-64 and arm64 only.
 func FileLoad(kernel, ramfs *os.File, cmdline string) error {
-	var flags int
+	return fileLoad(kernel, ramfs, cmdline, 0)
+}
+
+// FileLoadCrash loads the given kernel as the crash kernel, which is booted
+// when the running kernel panics.
+//
+// The running kernel places it in the memory reserved with crashkernel= and
+// passes it the ELF core headers of the memory to dump.
+func FileLoadCrash(kernel, ramfs *os.File, cmdline string) error {
+	return fileLoad(kernel, ramfs, cmdline, unix.KEXEC_FILE_ON_CRASH)
+}
+
+func fileLoad(kernel, ramfs *os.File, cmdline string, flags int) error {
 	var ramfsfd int
 	if ramfs != nil {
 		ramfsfd = int(ramfs.Fd())
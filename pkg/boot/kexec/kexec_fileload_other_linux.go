@@ -15,3 +15,8 @@ import (
 func FileLoad(kernel, ramfs *os.File, cmdline string) error {
 	return syscall.ENOSYS
 }
+
+// FileLoadCrash is not implemented for platforms other than amd64, arm64 and riscv64.
+func FileLoadCrash(kernel, ramfs *os.File, cmdline string) error {
+	return syscall.ENOSYS
+}
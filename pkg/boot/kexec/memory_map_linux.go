@@ -30,6 +30,10 @@ const (
 	RangeACPI     RangeType = "ACPI Tables"
 	RangeNVS      RangeType = "ACPI Non-volatile Storage"
 	RangeReserved RangeType = "Reserved"
+
+	// RangeCrashKernel is memory reserved for a crash kernel with
+	// crashkernel=, as listed in /proc/iomem.
+	RangeCrashKernel RangeType = "Crash kernel"
 )
 
 // String implements fmt.Stringer.
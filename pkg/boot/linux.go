@@ -34,12 +34,18 @@ type LinuxImage struct {
 	Env         map[string]string
 
 	// ReservedRanges are additional physical memory pieces that will be
-	// avoided when allocating kexec segments. Only used for LoadSyscall.
+	// avoided when allocating kexec segments. Only used for LoadSyscall,
+	// and not for crash kernels.
 	//
 	// ReservedRanges will not be shared with the next kernel, which is
 	// free to use this memory unless some other mechanism (such as
 	// memmap=) reserves it.
 	ReservedRanges kexec.Ranges
+
+	// BLSEntry is the path of the Boot Loader Specification entry file
+	// the image was read from, if any. Its boot counter is counted when
+	// the image is booted, see bls.CountBoot.
+	BLSEntry string
 }
 
 var _ OSImage = &LinuxImage{}
@@ -146,6 +152,9 @@ func CopyToFileIfNotRegular(r io.ReaderAt, verbose bool) (*os.File, error) {
 
 	// For boot entries whose image is lazily downloaded, try booting the
 	// backend file directly if possible.
+	if c, ok := r.(*catInitrd); ok {
+		r = c.LazyOpenerAt
+	}
 	if lor, ok := r.(*uio.LazyOpenerAt); ok {
 		// It is lazy, so read one byte to make sure backend file
 		// is created.
@@ -244,6 +253,19 @@ func (li *LinuxImage) Load(opts ...LoadOption) error {
 		opt(loadOpts)
 	}
 
+	for _, a := range []struct {
+		a Artifact
+		r io.ReaderAt
+	}{
+		{ArtifactKernel, li.Kernel},
+		{ArtifactInitrd, li.Initrd},
+		{ArtifactDTB, li.DTB},
+	} {
+		if err := loadOpts.verify(a.a, a.r); err != nil {
+			return err
+		}
+	}
+
 	k, i, err := li.loadImage(loadOpts)
 	if err != nil {
 		return err
@@ -263,8 +285,28 @@ func (li *LinuxImage) Load(opts ...LoadOption) error {
 	if !loadOpts.callKexecLoad {
 		return nil
 	}
+	if loadOpts.crash {
+		return li.loadCrash(k, i, loadOpts)
+	}
 	if li.LoadSyscall {
 		return linux.KexecLoad(k, i, li.Cmdline, li.DTB, li.ReservedRanges)
 	}
 	return kexec.FileLoad(k, i, li.Cmdline)
 }
+
+// loadCrash loads the kernel as the crash kernel.
+//
+// With kexec_file_load, the running kernel places the kernel in the memory
+// reserved for crash kernels and generates the ELF core headers. With
+// kexec_load, the memory and the headers come from /proc/iomem.
+func (li *LinuxImage) loadCrash(k, i *os.File, loadOpts *loadOptions) error {
+	if !li.LoadSyscall {
+		return kexec.FileLoadCrash(k, i, li.Cmdline)
+	}
+	cm, err := kexec.CrashMemoryFromIOMem()
+	if err != nil {
+		return err
+	}
+	loadOpts.logger.Printf("Crash kernel memory: %v", cm.Crash)
+	return linux.KexecLoadCrash(k, i, li.Cmdline, li.DTB, cm)
+}
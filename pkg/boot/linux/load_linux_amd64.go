@@ -6,15 +6,19 @@ package linux
 
 import (
 	"bytes"
+	"debug/elf"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"strings"
 
 	"github.com/u-root/u-root/pkg/boot/bzimage"
 	"github.com/u-root/u-root/pkg/boot/kexec"
 	"github.com/u-root/u-root/pkg/boot/purgatory"
 	"github.com/u-root/uio/uio"
+	"golang.org/x/sys/unix"
 )
 
 const (
@@ -26,18 +30,77 @@ const (
 //
 // It uses the kexec_load system call.
 func KexecLoad(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, reservations kexec.Ranges) error {
+	// Prepare segments.
+	Debug("Try parsing memory map...")
+	// TODO(10000TB): refactor this call into initialization of
+	// kexec.Memory, as it does not depend on specific boot.
+	mm, err := kexec.MemoryMapFromSysfsMemmap()
+	if err != nil {
+		return fmt.Errorf("parse memory map: %w", err)
+	}
+	for _, r := range reservations {
+		mm.Insert(kexec.TypedRange{Range: r, Type: kexec.RangeReserved})
+	}
+	// kmem is a struct holding kexec segments.
+	//
+	// It has routines to work with physical memory
+	// ranges.
+	kmem := &kexec.Memory{
+		Phys: mm,
+	}
+	return kexecLoadBzImage(kmem, kernel, ramfs, cmdline, nil)
+}
+
+// KexecLoadCrash loads a bzImage-formated Linux kernel file as the crash
+// kernel, with the given ramfs file and cmdline string, into the memory
+// reserved for it in cm.
+//
+// The crash kernel reuses the memory below 640K, so purgatory saves it to a
+// backup segment first. The ELF core headers describing cm and the backup are
+// loaded along with the kernel, and elfcorehdr= and memmap= arguments are
+// appended to cmdline to pass them and the usable memory to the crash kernel.
+func KexecLoadCrash(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, cm *kexec.CrashMemory) error {
+	kmem := &kexec.Memory{
+		Phys: cm.MemoryMap(),
+	}
+	backup, err := kmem.AddKexecSegment(make([]byte, kexec.CrashBackupSource.Size))
+	if err != nil {
+		return fmt.Errorf("add backup segment: %w", err)
+	}
+	cm.Backup = backup
+	Debug("Added %s backup at %s", kexec.CrashBackupSource, backup)
+
+	hdr, err := cm.ElfCoreHeader()
+	if err != nil {
+		return err
+	}
+	hdrRange, err := kmem.AddKexecSegment(hdr)
+	if err != nil {
+		return fmt.Errorf("add ELF core header segment: %w", err)
+	}
+	Debug("Added %d byte ELF core header at %s", len(hdr), hdrRange)
+
+	cmdline = strings.TrimSpace(cmdline + " " + cm.KernelArgs(hdrRange))
+	return kexecLoadBzImage(kmem, kernel, ramfs, cmdline, cm)
+}
+
+// kexecLoadBzImage loads the bzImage kernel into kmem and calls kexec_load.
+// If cm is not nil, the kernel is a crash kernel, which is loaded wherever
+// kmem has room rather than at the address the kernel was linked for, and
+// started through the crash purgatory.
+func kexecLoadBzImage(kmem *kexec.Memory, kernel, ramfs *os.File, cmdline string, cm *kexec.CrashMemory) error {
 	bzimage.Debug = Debug
+	crash := cm != nil
+	var flags uint64
+	if crash {
+		flags = unix.KEXEC_ON_CRASH
+	}
 
 	// A collection of vars used for processing the kernel for kexec
 	var err error
 	// bzimage is the deserialized bzImage from the kernel
 	// io.ReaderAt.
 	var bzimg bzimage.BzImage
-	// kmem is a struct holding kexec segments.
-	//
-	// It has routines to work with physical memory
-	// ranges.
-	var kmem *kexec.Memory
 	// TODO(10000TB): construct default params in go.
 	//
 	// boot_params directory is x86 specific. So for now, following code only
@@ -71,21 +134,6 @@ func KexecLoad(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, reservat
 	kernelEntry := uintptr(kelf.Entry)
 	Debug("kernelEntry: %v", kernelEntry)
 
-	// Prepare segments.
-	Debug("Try parsing memory map...")
-	// TODO(10000TB): refactor this call into initialization of
-	// kexec.Memory, as it does not depend on specific boot.
-	mm, err := kexec.MemoryMapFromSysfsMemmap()
-	if err != nil {
-		return fmt.Errorf("parse memory map: %w", err)
-	}
-	for _, r := range reservations {
-		mm.Insert(kexec.TypedRange{Range: r, Type: kexec.RangeReserved})
-	}
-	kmem = &kexec.Memory{
-		Phys: mm,
-	}
-
 	var relocatableKernel bool
 	if bzimg.Header.Protocolversion < 0x0205 {
 		return fmt.Errorf("bzImage boot protocol earlier thatn 2.05 is not supported currently: %v", bzimg.Header.Protocolversion)
@@ -97,7 +145,14 @@ func KexecLoad(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, reservat
 	if !relocatableKernel {
 		return errors.New("non-relocateable Kernels are not supported")
 	}
-	if _, err := kmem.LoadElfSegments(bytes.NewReader(bzimg.KernelCode)); err != nil {
+	if crash {
+		delta, err := loadRelocatedElfSegments(kmem, kelf, uint(bzimg.Header.Kernelalignment))
+		if err != nil {
+			return fmt.Errorf("loading kernel ELF segments: %w", err)
+		}
+		kernelEntry += delta
+		Debug("Relocated kernel by %#x, kernelEntry: %#x", delta, kernelEntry)
+	} else if _, err := kmem.LoadElfSegments(bytes.NewReader(bzimg.KernelCode)); err != nil {
 		return fmt.Errorf("loading kernel ELF segments: %w", err)
 	}
 
@@ -175,15 +230,56 @@ func KexecLoad(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, reservat
 	// TODO(10000TB): if rel_addr < setupRange.Start then return error.
 
 	// Load purgatory.
-	purgatoryEntry, err := purgatory.Load(kmem, kernelEntry, setupRange.Start)
+	var purgatoryEntry uintptr
+	if crash {
+		purgatoryEntry, err = purgatory.LoadCrash(kmem, kernelEntry, setupRange.Start, kexec.CrashBackupSource, cm.Backup.Start)
+	} else {
+		purgatoryEntry, err = purgatory.Load(kmem, kernelEntry, setupRange.Start)
+	}
 	if err != nil {
 		return err
 	}
 	Debug("purgatory entry: %v", purgatoryEntry)
 
 	// Load it.
-	if err := kexec.Load(purgatoryEntry, kmem.Segments, 0); err != nil {
-		return fmt.Errorf("kexec load(%v, %v, %d): %w", purgatoryEntry, kmem.Segments, 0, err)
+	if err := kexec.Load(purgatoryEntry, kmem.Segments, flags); err != nil {
+		return fmt.Errorf("kexec load(%v, %v, %d): %w", purgatoryEntry, kmem.Segments, flags, err)
 	}
 	return nil
 }
+
+// loadRelocatedElfSegments loads the loadable segments of a relocatable
+// kernel ELF wherever kmem has room, aligned to alignSize, keeping their
+// layout. It returns the distance the kernel was moved by.
+func loadRelocatedElfSegments(kmem *kexec.Memory, kelf *elf.File, alignSize uint) (uintptr, error) {
+	var progs []*elf.Prog
+	var start, end uint64 = math.MaxUint64, 0
+	for _, p := range kelf.Progs {
+		if p.Type != elf.PT_LOAD {
+			continue
+		}
+		progs = append(progs, p)
+		start = min(start, p.Paddr)
+		end = max(end, p.Paddr+p.Memsz)
+	}
+	if len(progs) == 0 {
+		return 0, errors.New("kernel ELF has no loadable segments")
+	}
+
+	r, err := kmem.AvailableRAM().FindSpace(uint(end-start), kexec.WithAlignment(alignSize))
+	if err != nil {
+		return 0, err
+	}
+	delta := r.Start - uintptr(start)
+	for _, p := range progs {
+		d := make([]byte, p.Filesz)
+		if _, err := p.ReadAt(d, 0); err != nil {
+			return 0, err
+		}
+		kmem.Segments.Insert(kexec.NewSegment(d, kexec.Range{
+			Start: uintptr(p.Paddr) + delta,
+			Size:  uint(p.Memsz),
+		}))
+	}
+	return delta, nil
+}
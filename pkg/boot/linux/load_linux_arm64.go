@@ -10,6 +10,7 @@ import (
 	"os"
 
 	"github.com/u-root/u-root/pkg/boot/kexec"
+	"golang.org/x/sys/unix"
 )
 
 // KexecLoad loads arm64 Image, with the given ramfs and kernel cmdline.
@@ -28,3 +29,18 @@ func KexecLoad(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, reserved
 	}
 	return nil
 }
+
+// KexecLoadCrash loads arm64 Image as the crash kernel, with the given ramfs
+// and kernel cmdline, into the memory reserved for it in cm. The device tree
+// passes the crash kernel the ELF core headers describing cm.
+func KexecLoadCrash(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, cm *kexec.CrashMemory) error {
+	img, err := kexecLoadCrashImage(kernel, ramfs, cmdline, dtb, cm)
+	if err != nil {
+		return err
+	}
+	defer img.clean()
+	if err = kexec.Load(img.entry, img.segments, unix.KEXEC_ON_CRASH); err != nil {
+		return fmt.Errorf("kexec Load(%v, %v, %d) = %w", img.entry, img.segments, unix.KEXEC_ON_CRASH, err)
+	}
+	return nil
+}
@@ -55,7 +55,8 @@ func sanitizeFDT(fdt *dt.FDT) (*dt.Node, error) {
 
 var ErrMemmapEmpty = errors.New("memory map is empty or contains no information about system RAM")
 
-func kexecLoadImage(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, reservedRanges kexec.Ranges) (*kimage, error) {
+// readFDT reads the FDT dtb, or the FDT of the running system if dtb is nil.
+func readFDT(dtb io.ReaderAt) (*dt.FDT, error) {
 	var fdt *dt.FDT
 	var err error
 	// We want to fail when a user-supplied FDT is not parseable, not
@@ -69,7 +70,24 @@ func kexecLoadImage(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, res
 		return nil, fmt.Errorf("read FDT = %w", err)
 	}
 	Debug("Loaded FDT: %s", fdt)
+	return fdt, nil
+}
 
+func kexecLoadImage(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, reservedRanges kexec.Ranges) (*kimage, error) {
+	fdt, err := readFDT(dtb)
+	if err != nil {
+		return nil, err
+	}
+	mm, err := memoryMapFromFDT(fdt, reservedRanges)
+	if err != nil {
+		return nil, err
+	}
+	return kexecLoadImageMM(mm, kernel, ramfs, fdt, cmdline, nil)
+}
+
+// memoryMapFromFDT returns the memory map of fdt, with reservedRanges
+// reserved.
+func memoryMapFromFDT(fdt *dt.FDT, reservedRanges kexec.Ranges) (kexec.MemoryMap, error) {
 	// Prepare segments.
 	Debug("Try parsing memory map...")
 	mm, err := kexec.MemoryMapFromFDT(fdt)
@@ -83,7 +101,17 @@ func kexecLoadImage(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, res
 	for _, r := range reservedRanges {
 		mm.Insert(kexec.TypedRange{Range: r, Type: kexec.RangeReserved})
 	}
-	return kexecLoadImageMM(mm, kernel, ramfs, fdt, cmdline)
+	return mm, nil
+}
+
+// kexecLoadCrashImage loads an Image as a crash kernel into the memory
+// reserved for it.
+func kexecLoadCrashImage(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, cm *kexec.CrashMemory) (*kimage, error) {
+	fdt, err := readFDT(dtb)
+	if err != nil {
+		return nil, err
+	}
+	return kexecLoadImageMM(cm.MemoryMap(), kernel, ramfs, fdt, cmdline, cm)
 }
 
 var (
@@ -91,9 +119,13 @@ var (
 	errInitramfsSegmentFailed  = errors.New("failed to add initramfs segment")
 	errDTBSegmentFailed        = errors.New("failed to add DTB segment")
 	errTrampolineSegmentFailed = errors.New("failed to add trampolineSegment")
+	errElfCoreHdrSegmentFailed = errors.New("failed to add ELF core header segment")
 )
 
-func kexecLoadImageMM(mm kexec.MemoryMap, kernel, ramfs *os.File, fdt *dt.FDT, cmdline string) (*kimage, error) {
+// kexecLoadImageMM loads an Image into the RAM of mm. If cm is not nil, the
+// Image is a crash kernel, and the device tree tells it where to find the ELF
+// core headers of cm and which memory it may use.
+func kexecLoadImageMM(mm kexec.MemoryMap, kernel, ramfs *os.File, fdt *dt.FDT, cmdline string, cm *kexec.CrashMemory) (*kimage, error) {
 	kmem := &kexec.Memory{
 		Phys: mm,
 	}
@@ -138,6 +170,12 @@ func kexecLoadImageMM(mm kexec.MemoryMap, kernel, ramfs *os.File, fdt *dt.FDT, c
 	}
 	Debug("FDT after sanitization: %s", fdt)
 
+	if cm != nil {
+		if err := addCrashProperties(kmem, chosen, cm); err != nil {
+			return nil, err
+		}
+	}
+
 	if ramfs != nil {
 		ramfsBuf, cleanup, err := getFile(ramfs)
 		if err != nil {
@@ -230,3 +268,34 @@ func kexecLoadImageMM(mm kexec.MemoryMap, kernel, ramfs *os.File, fdt *dt.FDT, c
 	Debug("Entry: %#x", img.entry)
 	return img, nil
 }
+
+// addCrashProperties loads the ELF core headers of cm and tells a crash
+// kernel where to find them and which memory it may use in the /chosen node.
+func addCrashProperties(kmem *kexec.Memory, chosen *dt.Node, cm *kexec.CrashMemory) error {
+	hdr, err := cm.ElfCoreHeader()
+	if err != nil {
+		return err
+	}
+	hdrRange, err := kmem.AddKexecSegment(hdr)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errElfCoreHdrSegmentFailed, err)
+	}
+	Debug("Added %d byte ELF core header at %s", len(hdr), hdrRange)
+
+	// Both properties use the address and size cells of the root node,
+	// which are 2 on 64-bit platforms.
+	chosen.UpdateProperty("linux,elfcorehdr", fdtRegions(kexec.Ranges{hdrRange}))
+	chosen.UpdateProperty("linux,usable-memory-range", fdtRegions(cm.Crash))
+	return nil
+}
+
+// fdtRegions encodes rs as a reg-style property with 2 address and 2 size
+// cells.
+func fdtRegions(rs kexec.Ranges) []byte {
+	var b []byte
+	for _, r := range rs {
+		b = binary.BigEndian.AppendUint64(b, uint64(r.Start))
+		b = binary.BigEndian.AppendUint64(b, uint64(r.Size))
+	}
+	return b
+}
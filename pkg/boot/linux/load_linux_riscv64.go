@@ -0,0 +1,46 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package linux
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/u-root/u-root/pkg/boot/kexec"
+	"golang.org/x/sys/unix"
+)
+
+// KexecLoad loads riscv64 Image, with the given ramfs and kernel cmdline.
+//
+// reservedRanges are additional pieces of physical memory that are not used
+// for kexec segment allocation. They are not transmitted to the next kernel to
+// be considered reserved.
+func KexecLoad(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, reservedRanges kexec.Ranges) error {
+	img, err := kexecLoadRISCVImage(kernel, ramfs, cmdline, dtb, reservedRanges)
+	if err != nil {
+		return err
+	}
+	defer img.clean()
+	if err = kexec.Load(img.entry, img.segments, 0); err != nil {
+		return fmt.Errorf("kexec Load(%v, %v, %d) = %w", img.entry, img.segments, 0, err)
+	}
+	return nil
+}
+
+// KexecLoadCrash loads riscv64 Image as the crash kernel, with the given
+// ramfs and kernel cmdline, into the memory reserved for it in cm. The device
+// tree passes the crash kernel the ELF core headers describing cm.
+func KexecLoadCrash(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, cm *kexec.CrashMemory) error {
+	img, err := kexecLoadCrashRISCVImage(kernel, ramfs, cmdline, dtb, cm)
+	if err != nil {
+		return err
+	}
+	defer img.clean()
+	if err = kexec.Load(img.entry, img.segments, unix.KEXEC_ON_CRASH); err != nil {
+		return fmt.Errorf("kexec Load(%v, %v, %d) = %w", img.entry, img.segments, unix.KEXEC_ON_CRASH, err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package linux
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/u-root/u-root/pkg/boot/image"
+	"github.com/u-root/u-root/pkg/boot/kexec"
+	"github.com/u-root/u-root/pkg/dt"
+)
+
+func kexecLoadRISCVImage(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, reservedRanges kexec.Ranges) (*kimage, error) {
+	fdt, err := readFDT(dtb)
+	if err != nil {
+		return nil, err
+	}
+	mm, err := memoryMapFromFDT(fdt, reservedRanges)
+	if err != nil {
+		return nil, err
+	}
+	return kexecLoadRISCVImageMM(mm, kernel, ramfs, fdt, cmdline, nil)
+}
+
+// kexecLoadCrashRISCVImage loads a RISC-V Image as a crash kernel into the
+// memory reserved for it.
+func kexecLoadCrashRISCVImage(kernel, ramfs *os.File, cmdline string, dtb io.ReaderAt, cm *kexec.CrashMemory) (*kimage, error) {
+	fdt, err := readFDT(dtb)
+	if err != nil {
+		return nil, err
+	}
+	return kexecLoadRISCVImageMM(cm.MemoryMap(), kernel, ramfs, fdt, cmdline, cm)
+}
+
+// kexecLoadRISCVImageMM loads a RISC-V Image into the RAM of mm. If cm is not
+// nil, the Image is a crash kernel, and the device tree tells it where to
+// find the ELF core headers of cm and which memory it may use.
+func kexecLoadRISCVImageMM(mm kexec.MemoryMap, kernel, ramfs *os.File, fdt *dt.FDT, cmdline string, cm *kexec.CrashMemory) (*kimage, error) {
+	kmem := &kexec.Memory{
+		Phys: mm,
+	}
+
+	img := &kimage{}
+
+	// Load kernel.
+	kernelBuf, cleanup, err := getFile(kernel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kernel contents: %w", err)
+	}
+	img.cleanup = append(img.cleanup, cleanup)
+
+	kImage, err := image.ParseRISCVFromBytes(kernelBuf)
+	if err != nil {
+		return nil, fmt.Errorf("parse RISC-V Image from bytes: %w", err)
+	}
+
+	// "The Image must be placed at a 2MB aligned address on RV64, 4MB
+	// on RV32, plus text_offset." At least image_size bytes must be
+	// free for the kernel. (riscv/boot.rst)
+	kernelRange, err := kmem.AddKexecSegmentExplicit(kernelBuf, uint(kImage.Header.ImageSize), uint(kImage.Header.TextOffset), kernelAlignSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errKernelSegmentFailed, err)
+	}
+
+	Debug("Added %#x byte (size %#x) kernel at %s with offset %#x with alignment %#x", len(kernelBuf), kImage.Header.ImageSize, kernelRange, kImage.Header.TextOffset, kernelAlignSize)
+
+	chosen, err := sanitizeFDT(fdt)
+	if err != nil {
+		return nil, fmt.Errorf("sanitizeFDT(%v) = %w", fdt, err)
+	}
+	Debug("FDT after sanitization: %s", fdt)
+
+	if cm != nil {
+		if err := addCrashProperties(kmem, chosen, cm); err != nil {
+			return nil, err
+		}
+	}
+
+	if ramfs != nil {
+		ramfsBuf, cleanup, err := getFile(ramfs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get initramfs contents: %w", err)
+		}
+		img.cleanup = append(img.cleanup, cleanup)
+
+		ramfsRange, err := kmem.AddKexecSegment(ramfsBuf)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInitramfsSegmentFailed, err)
+		}
+		Debug("Added %d byte initramfs at %s", len(ramfsBuf), ramfsRange)
+
+		chosen.UpdateProperty("linux,initrd-start", binary.BigEndian.AppendUint64(nil, uint64(ramfsRange.Start)))
+		chosen.UpdateProperty("linux,initrd-end", binary.BigEndian.AppendUint64(nil, uint64(ramfsRange.Start)+uint64(ramfsRange.Size)))
+	}
+
+	Debug("Kernel cmdline to append: %s", cmdline)
+	if len(cmdline) > 0 {
+		chosen.UpdateProperty("bootargs", append([]byte(cmdline), 0))
+	} else {
+		chosen.RemoveProperty("bootargs")
+	}
+
+	// The kernel looks for the device tree among the segments to pass
+	// it to the next kernel, so it must be loaded as one of its own.
+	var dtbBuffer bytes.Buffer
+	if _, err := fdt.Write(&dtbBuffer); err != nil {
+		return nil, fmt.Errorf("flattening device tree: %w", err)
+	}
+	dtbBuf := dtbBuffer.Bytes()
+	dtbRange, err := kmem.AddKexecSegment(dtbBuf)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errDTBSegmentFailed, err)
+	}
+	Debug("Added %d byte device tree at %s", len(dtbBuf), dtbRange)
+
+	// Trampoline.
+	//
+	// The kernel jumps to the entry point with the hart ID in a0, as the
+	// boot protocol requires, and the device tree it found in a1. The
+	// trampoline sets a1 to our device tree rather than relying on that
+	// search, and jumps to the kernel.
+	trampoline := riscvTrampoline(kernelRange.Start, dtbRange.Start)
+	Debug("trampoline bytes %x", trampoline)
+	trampolineRange, err := kmem.AddKexecSegment(trampoline)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errTrampolineSegmentFailed, err)
+	}
+	Debug("Added %d byte trampoline at %s", len(trampoline), trampolineRange)
+
+	/* Load it */
+	img.entry = trampolineRange.Start
+	img.segments = kmem.Segments
+	Debug("Entry: %#x", img.entry)
+	return img, nil
+}
+
+// riscvTrampoline returns a position-independent trampoline that jumps to
+// kernelEntry with a1 set to dtbBase, leaving a0 alone.
+func riscvTrampoline(kernelEntry, dtbBase uintptr) []byte {
+	var trampoline [8]uint32
+	// Instruction encoding per "The RISC-V Instruction Set Manual
+	// Volume I: Unprivileged ISA".
+	trampoline[0] = 0x00000297 // auipc t0, 0
+	trampoline[1] = 0x0182b583 // ld a1, 24(t0) (trampoline[6 and 7])
+	trampoline[2] = 0x0102b303 // ld t1, 16(t0) (trampoline[4 and 5])
+	trampoline[3] = 0x00030067 // jr t1
+
+	trampoline[4] = uint32(uint64(kernelEntry) & 0xffffffff)
+	trampoline[5] = uint32(uint64(kernelEntry) >> 32)
+	trampoline[6] = uint32(uint64(dtbBase) & 0xffffffff)
+	trampoline[7] = uint32(uint64(dtbBase) >> 32)
+
+	b := make([]byte, 0, 4*len(trampoline))
+	for _, t := range trampoline {
+		b = binary.LittleEndian.AppendUint32(b, t)
+	}
+	return b
+}
@@ -0,0 +1,228 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package linux
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/boot/image"
+	"github.com/u-root/u-root/pkg/boot/kexec"
+	"github.com/u-root/u-root/pkg/dt"
+)
+
+// riscvImage returns a RISC-V Image of 0x2000 bytes that needs imageSize
+// bytes of memory.
+func riscvImage(t *testing.T, imageSize uint64) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	if err := binary.Write(&b, binary.LittleEndian, image.RISCVHeader{
+		ImageSize: imageSize,
+		Version:   0x2,
+		Magic:     image.RISCVMagic,
+		Magic2:    image.RISCVMagic2,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return append(b.Bytes(), make([]byte, 0x2000-b.Len())...)
+}
+
+func riscvMemory(start, size uint64) *dt.Node {
+	return dt.NewNode("memory", dt.WithProperty(
+		dt.PropertyString("device_type", "memory"),
+		dt.PropertyRegion("reg", start, size),
+	))
+}
+
+func TestRISCVTrampoline(t *testing.T) {
+	want := []byte{
+		0x97, 0x02, 0x00, 0x00, // auipc t0, 0
+		0x83, 0xb5, 0x82, 0x01, // ld a1, 24(t0)
+		0x03, 0xb3, 0x02, 0x01, // ld t1, 16(t0)
+		0x67, 0x00, 0x03, 0x00, // jr t1
+		0x00, 0x00, 0x20, 0x80, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x10, 0x40, 0x80, 0x00, 0x00, 0x00, 0x00,
+	}
+	if got := riscvTrampoline(0x80200000, 0x80401000); !bytes.Equal(got, want) {
+		t.Errorf("riscvTrampoline() = %x, want %x", got, want)
+	}
+}
+
+func TestKexecLoadRISCVImage(t *testing.T) {
+	Debug = t.Logf
+
+	kernel := riscvImage(t, 0x200000)
+	for _, tt := range []struct {
+		name string
+
+		// Inputs
+		kernel       *os.File
+		ramfs        *os.File
+		fdt          *dt.FDT
+		cmdline      string
+		reservations kexec.Ranges
+
+		// Results
+		segments kexec.Segments
+		entry    uintptr
+		errs     []error
+	}{
+		{
+			name:    "load-initramfs-and-cmdline",
+			kernel:  createFile(t, kernel),
+			ramfs:   createFile(t, []byte("ramfs")),
+			cmdline: "console=ttyS0",
+			fdt: &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+				dt.NewNode("chosen", dt.WithProperty(
+					dt.PropertyString("bootargs", "ohno"),
+				)),
+				riscvMemory(0x80000000, 0x1000000),
+			))},
+			entry: 0x80202000,
+			segments: kexec.Segments{
+				kexec.NewSegment(kernel, kexec.Range{Start: 0x80000000, Size: 0x200000}),
+				kexec.NewSegment([]byte("ramfs"), kexec.Range{Start: 0x80200000, Size: 0x1000}),
+				kexec.NewSegment(fdtBytes(t, &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+					dt.NewNode("chosen", dt.WithProperty(
+						dt.PropertyString("bootargs", "console=ttyS0"),
+						dt.PropertyU64("linux,initrd-start", 0x80200000),
+						dt.PropertyU64("linux,initrd-end", 0x80201000),
+					)),
+					riscvMemory(0x80000000, 0x1000000),
+				))}), kexec.Range{Start: 0x80201000, Size: 0x1000}),
+				kexec.NewSegment(riscvTrampoline(0x80000000, 0x80201000), kexec.Range{Start: 0x80202000, Size: 0x1000}),
+			},
+		},
+		{
+			name:   "load-with-reservation",
+			kernel: createFile(t, kernel),
+			fdt: &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+				dt.NewNode("chosen"),
+				riscvMemory(0x80000000, 0x1000000),
+			))},
+			reservations: kexec.Ranges{
+				// OpenSBI usually lives at the start of RAM. This
+				// forces the kernel to the next 2M boundary.
+				{Start: 0x80000000, Size: 0x40000},
+			},
+			entry: 0x80041000,
+			segments: kexec.Segments{
+				kexec.NewSegment(fdtBytes(t, &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+					dt.NewNode("chosen"),
+					riscvMemory(0x80000000, 0x1000000),
+				))}), kexec.Range{Start: 0x80040000, Size: 0x1000}),
+				kexec.NewSegment(riscvTrampoline(0x80200000, 0x80040000), kexec.Range{Start: 0x80041000, Size: 0x1000}),
+				kexec.NewSegment(kernel, kexec.Range{Start: 0x80200000, Size: 0x200000}),
+			},
+		},
+		{
+			name:   "not enough space for kernel image",
+			kernel: createFile(t, kernel),
+			fdt: &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+				dt.NewNode("chosen"),
+				riscvMemory(0x80000000, 0x100000),
+			))},
+			errs: []error{errKernelSegmentFailed, kexec.ErrNotEnoughSpace},
+		},
+		{
+			name:   "no chosen node in fdt",
+			kernel: createFile(t, kernel),
+			fdt: &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+				riscvMemory(0x80000000, 0x1000000),
+			))},
+			errs: []error{errNoChosenNode},
+		},
+		{
+			name:   "no-memmap",
+			kernel: createFile(t, kernel),
+			fdt: &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+				dt.NewNode("chosen", dt.WithProperty(dt.PropertyString("bootargs", "ohno"))),
+			))},
+			errs: []error{ErrMemmapEmpty},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := kexecLoadRISCVImage(tt.kernel, tt.ramfs, tt.cmdline, fdtReader(t, tt.fdt), tt.reservations)
+			for _, wantErr := range tt.errs {
+				if !errors.Is(err, wantErr) {
+					t.Errorf("kexecLoad RISC-V Image = %v, want %v", err, wantErr)
+				}
+			}
+			if len(tt.errs) == 0 && err != nil {
+				t.Fatalf("kexecLoad RISC-V Image = %v", err)
+			}
+			if got == nil {
+				return
+			}
+			if got.entry != tt.entry {
+				t.Errorf("kexecLoad RISC-V Image = %#x, want %#x", got.entry, tt.entry)
+			}
+			if !kexec.SegmentsEqual(got.segments, tt.segments) {
+				t.Errorf("kexecLoad RISC-V Image =\n%v, want\n%v", got.segments, tt.segments)
+			}
+		})
+	}
+}
+
+func TestKexecLoadRISCVImageNotRISCV(t *testing.T) {
+	fdt := &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+		dt.NewNode("chosen"),
+		riscvMemory(0x80000000, 0x1000000),
+	))}
+	if _, err := kexecLoadRISCVImage(openFile(t, "../image/testdata/Image"), nil, "", fdtReader(t, fdt), nil); err == nil {
+		t.Errorf("kexecLoad RISC-V Image of arm64 Image = nil, want error")
+	}
+}
+
+func TestKexecLoadCrashRISCVImage(t *testing.T) {
+	Debug = t.Logf
+
+	kernel := riscvImage(t, 0x200000)
+	cm := &kexec.CrashMemory{
+		Crash: kexec.Ranges{{Start: 0x88000000, Size: 0x800000}},
+		RAM:   kexec.Ranges{{Start: 0x80000000, Size: 0x8000000}},
+	}
+	fdt := &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+		dt.NewNode("chosen", dt.WithProperty(
+			dt.PropertyRegion("linux,elfcorehdr", 0x1000, 0x1000),
+		)),
+		riscvMemory(0x80000000, 0x10000000),
+	))}
+	got, err := kexecLoadCrashRISCVImage(createFile(t, kernel), nil, "", fdtReader(t, fdt), cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hdr, err := cm.ElfCoreHeader()
+	if err != nil {
+		t.Skip(err)
+	}
+	want := kexec.Segments{
+		kexec.NewSegment(kernel, kexec.Range{Start: 0x88000000, Size: 0x200000}),
+		kexec.NewSegment(hdr, kexec.Range{Start: 0x88200000, Size: 0x1000}),
+		kexec.NewSegment(fdtBytes(t, &dt.FDT{RootNode: dt.NewNode("/", dt.WithChildren(
+			dt.NewNode("chosen", dt.WithProperty(
+				dt.PropertyRegion("linux,elfcorehdr", 0x88200000, 0x1000),
+				dt.PropertyRegion("linux,usable-memory-range", 0x88000000, 0x800000),
+			)),
+			riscvMemory(0x80000000, 0x10000000),
+		))}), kexec.Range{Start: 0x88201000, Size: 0x1000}),
+		kexec.NewSegment(riscvTrampoline(0x88000000, 0x88201000), kexec.Range{Start: 0x88202000, Size: 0x1000}),
+	}
+	if got.entry != 0x88202000 {
+		t.Errorf("kexecLoad RISC-V crash Image = %#x, want %#x", got.entry, 0x88202000)
+	}
+	if !kexec.SegmentsEqual(got.segments, want) {
+		t.Errorf("kexecLoad RISC-V crash Image =\n%v, want\n%v", got.segments, want)
+	}
+	for i := range got.segments {
+		if !kexec.SegmentEqual(got.segments[i], want[i]) {
+			t.Errorf("Segment %d wrong: %x", i, got.segments[i].Buf)
+		}
+	}
+}
@@ -294,3 +294,74 @@ func TestLoadLinuxImage(t *testing.T) {
 		})
 	}
 }
+
+type verifierFunc func(Artifact, io.ReaderAt) error
+
+func (f verifierFunc) Verify(a Artifact, r io.ReaderAt) error {
+	return f(a, r)
+}
+
+func TestLoadVerifier(t *testing.T) {
+	errUnsigned := errors.New("unsigned")
+	var verified []Artifact
+	v := verifierFunc(func(a Artifact, r io.ReaderAt) error {
+		verified = append(verified, a)
+		if r.(fmt.Stringer).String() == "unsigned" {
+			return errUnsigned
+		}
+		return nil
+	})
+	file := func(name string) io.ReaderAt {
+		return uio.NewLazyOpenerAt(name, func() (io.ReaderAt, error) {
+			return strings.NewReader(name), nil
+		})
+	}
+
+	for _, tt := range []struct {
+		name string
+		li   *LinuxImage
+		want []Artifact
+		err  error
+	}{
+		{
+			name: "kernel",
+			li:   &LinuxImage{Kernel: file("kernel")},
+			want: []Artifact{ArtifactKernel},
+		},
+		{
+			name: "all",
+			li:   &LinuxImage{Kernel: file("kernel"), Initrd: file("initrd"), DTB: file("dtb")},
+			want: []Artifact{ArtifactKernel, ArtifactInitrd, ArtifactDTB},
+		},
+		{
+			name: "unsigned initrd",
+			li:   &LinuxImage{Kernel: file("kernel"), Initrd: file("unsigned"), DTB: file("dtb")},
+			want: []Artifact{ArtifactKernel, ArtifactInitrd},
+			err:  errUnsigned,
+		},
+		{
+			name: "concatenated initrds",
+			li:   &LinuxImage{Kernel: file("kernel"), Initrd: CatInitrds(file("initrd"), file("ucode"))},
+			want: []Artifact{ArtifactKernel, ArtifactInitrd, ArtifactInitrd},
+		},
+		{
+			name: "unsigned concatenated initrd",
+			li: &LinuxImage{Kernel: file("kernel"), Initrd: CatInitrdsWithFileCache(
+				uio.NewLazyOpener("initrd", func() (io.Reader, error) { return strings.NewReader("initrd"), nil }),
+				uio.NewLazyOpener("unsigned", func() (io.Reader, error) { return strings.NewReader("unsigned"), nil }),
+			)},
+			want: []Artifact{ArtifactKernel, ArtifactInitrd, ArtifactInitrd},
+			err:  errUnsigned,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			verified = nil
+			if err := tt.li.Load(WithVerifier(v), WithDryRun(true)); !errors.Is(err, tt.err) {
+				t.Errorf("Load = %v, want %v", err, tt.err)
+			}
+			if !cmp.Equal(verified, tt.want) {
+				t.Errorf("verified %v, want %v", verified, tt.want)
+			}
+		})
+	}
+}
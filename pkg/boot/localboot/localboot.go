@@ -34,6 +34,13 @@ func parse(l ulog.Logger, device *block.BlockDev, devices block.BlockDevices, mo
 		l.Printf("No systemd-boot BootLoaderSpec configs found on %s, trying another format...: %v", device, err)
 	}
 
+	// Unified Kernel Images on the ESP or XBOOTLDR partition.
+	ukiImgs, err := bls.ScanUKIEntries(l, mountDir)
+	if err != nil {
+		l.Printf("No Unified Kernel Images found on %s, trying another format...: %v", device, err)
+	}
+	imgs = append(imgs, ukiImgs...)
+
 	// Grub parser may want to load files (kernel, initramfs, modules, ...)
 	// from another partition, thus it is given devices and mountPool in
 	// order to reuse mounts and mount more file systems.
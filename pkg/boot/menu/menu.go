@@ -260,11 +260,18 @@ func showMenuAndLoadFromFile(file *os.File, allowEdit bool, entries ...Entry) En
 
 // OSImages returns menu entries for the given OSImages.
 func OSImages(verbose bool, imgs ...boot.OSImage) []Entry {
+	return OSImagesWithOpts(verbose, nil, imgs...)
+}
+
+// OSImagesWithOpts returns menu entries for the given OSImages, which are
+// loaded with opts.
+func OSImagesWithOpts(verbose bool, opts []boot.LoadOption, imgs ...boot.OSImage) []Entry {
 	var menu []Entry
 	for _, img := range imgs {
 		menu = append(menu, &OSImageAction{
-			OSImage: img,
-			Verbose: verbose,
+			OSImage:  img,
+			Verbose:  verbose,
+			LoadOpts: opts,
 		})
 	}
 	return menu
@@ -275,11 +282,15 @@ type OSImageAction struct {
 	boot.OSImage
 	Verbose     bool
 	NoKexecLoad bool
+
+	// LoadOpts are passed to OSImage.Load, e.g. boot.WithVerifier.
+	LoadOpts []boot.LoadOption
 }
 
 // Load implements Entry.Load by loading the OS image into memory.
 func (oia OSImageAction) Load() error {
-	if err := oia.OSImage.Load(boot.WithVerbose(oia.Verbose), boot.WithDryRun(oia.NoKexecLoad)); err != nil {
+	opts := append([]boot.LoadOption{boot.WithVerbose(oia.Verbose), boot.WithDryRun(oia.NoKexecLoad)}, oia.LoadOpts...)
+	if err := oia.OSImage.Load(opts...); err != nil {
 		return fmt.Errorf("could not load image %s: %w", oia.OSImage, err)
 	}
 	return nil
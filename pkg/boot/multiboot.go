@@ -5,6 +5,7 @@
 package boot
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -53,6 +54,19 @@ func (mi *MultibootImage) Load(opts ...LoadOption) error {
 		opt(loadOpts)
 	}
 
+	if loadOpts.crash {
+		return errors.New("multiboot kernels cannot be loaded as crash kernels")
+	}
+
+	if err := loadOpts.verify(ArtifactKernel, mi.Kernel); err != nil {
+		return err
+	}
+	for _, m := range mi.Modules {
+		if err := loadOpts.verify(ArtifactModule, m.Module); err != nil {
+			return err
+		}
+	}
+
 	entryPoint, segments, err := multiboot.PrepareLoad(loadOpts.verbose, mi.Kernel, mi.Cmdline, mi.Modules, mi.IBFT)
 	if err != nil {
 		return err
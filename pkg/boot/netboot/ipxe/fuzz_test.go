@@ -5,6 +5,7 @@
 package ipxe
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -34,6 +35,6 @@ func FuzzParseIpxeConfig(f *testing.F) {
 		parser := &parser{
 			log: ulogtest.Logger{t},
 		}
-		parser.parseIpxe(string(data))
+		parser.parseIpxe(context.Background(), string(data))
 	})
 }
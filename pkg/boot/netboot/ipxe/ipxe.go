@@ -2,17 +2,30 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package ipxe implements a trivial IPXE config file parser.
+// Package ipxe implements an interpreter for iPXE scripts.
+//
+// The interpreter supports the iPXE commands used to pick and boot a Linux
+// kernel: kernel, initrd, imgargs, chain and boot and their img* aliases,
+// set, clear, isset, iseq, goto, menu, item, choose and exit, commands
+// joined with || and &&, and ${setting} expansion.
+//
+// Interactive menus are not shown. Instead, the script is run once for each
+// menu item, and each of the resulting images is returned, with the default
+// item first, so that callers can offer them in a pkg/boot/menu menu.
 package ipxe
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/url"
 	"os"
 	"path"
+	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/u-root/u-root/pkg/boot"
@@ -21,85 +34,209 @@ import (
 	"github.com/u-root/uio/uio"
 )
 
-// ErrNotIpxeScript is returned when the config file is not an
-// ipxe script.
-var ErrNotIpxeScript = errors.New("config file is not ipxe as it does not start with #!ipxe")
+var (
+	// ErrNotIpxeScript is returned when the config file is not an
+	// ipxe script.
+	ErrNotIpxeScript = errors.New("config file is not ipxe as it does not start with #!ipxe")
+
+	// ErrNoImage is returned when the script exits without booting.
+	ErrNoImage = errors.New("ipxe script did not boot an image")
+
+	errNoKernel    = errors.New("no kernel selected")
+	errNoSuchLabel = errors.New("no such label")
+	errNoItems     = errors.New("menu has no items")
+	errUsage       = errors.New("invalid arguments")
+	errTooManyCmds = errors.New("too many commands executed, script is looping")
+	errChainDepth  = errors.New("too many nested chained scripts")
+	errNoKey       = errors.New("no key pressed")
+	errNotEqual    = errors.New("values are not equal")
+	errNotSet      = errors.New("value is not set")
+)
+
+const (
+	// maxSteps limits the number of lines a script runs, as scripts may
+	// loop forever, e.g. retrying a chain that keeps failing.
+	maxSteps = 10000
+
+	// maxChainDepth limits how deep scripts may chain other scripts.
+	maxChainDepth = 16
+)
 
 // parser encapsulates a parsed ipxe configuration file.
-//
-// We currently only support kernel and initrd commands.
 type parser struct {
-	bootImage *boot.LinuxImage
+	// images are the images booted by the script, in the order the
+	// menu items leading to them were offered.
+	images []*boot.LinuxImage
 
 	// wd is the current working directory.
 	//
 	// Relative file paths are interpreted relative to this URL.
 	wd *url.URL
 
+	// vars are the settings the script starts with.
+	vars map[string]string
+
 	log ulog.Logger
 
 	schemes curl.Schemes
 }
 
-// ParseConfig returns a new configuration with the file at URL and default
-// schemes.
-//
-// `s` is used to get files referred to by URLs in the configuration.
-func ParseConfig(ctx context.Context, l ulog.Logger, configURL *url.URL, s curl.Schemes) (*boot.LinuxImage, error) {
+// Option is an optional argument to ParseConfig and ParseConfigImages.
+type Option func(*parser)
+
+// WithVars sets settings scripts can expand with ${name}, e.g. "net0/mac"
+// or "net0/ip". As in iPXE, ${mac} finds net0/mac if mac itself is not set.
+func WithVars(vars map[string]string) Option {
+	return func(c *parser) {
+		maps.Copy(c.vars, vars)
+	}
+}
+
+func newParser(l ulog.Logger, s curl.Schemes, opts ...Option) *parser {
 	c := &parser{
 		schemes: s,
 		log:     l,
+		vars:    builtinVars(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// buildArches maps GOARCH to iPXE's ${buildarch}.
+var buildArches = map[string]string{
+	"386":     "i386",
+	"amd64":   "x86_64",
+	"arm":     "arm32",
+	"arm64":   "arm64",
+	"riscv64": "riscv64",
+}
+
+func builtinVars() map[string]string {
+	vars := make(map[string]string)
+	if arch, ok := buildArches[runtime.GOARCH]; ok {
+		vars["buildarch"] = arch
+	}
+	return vars
+}
+
+// ParseConfig returns the image the script at configURL boots by default.
+//
+// `s` is used to get files referred to by URLs in the configuration.
+func ParseConfig(ctx context.Context, l ulog.Logger, configURL *url.URL, s curl.Schemes, opts ...Option) (*boot.LinuxImage, error) {
+	c := newParser(l, s, opts...)
+	if err := c.getAndParseFile(ctx, configURL); err != nil {
+		return nil, err
 	}
+	return c.images[0], nil
+}
+
+// ParseConfigImages returns all images the script at configURL may boot:
+// the default image first, followed by those of the other menu items.
+//
+// `s` is used to get files referred to by URLs in the configuration.
+func ParseConfigImages(ctx context.Context, l ulog.Logger, configURL *url.URL, s curl.Schemes, opts ...Option) ([]boot.OSImage, error) {
+	c := newParser(l, s, opts...)
 	if err := c.getAndParseFile(ctx, configURL); err != nil {
 		return nil, err
 	}
-	return c.bootImage, nil
+	var images []boot.OSImage
+	for _, img := range c.images {
+		images = append(images, img)
+	}
+	return images, nil
 }
 
 // getAndParse parses the config file downloaded from `url` and fills in `c`.
 func (c *parser) getAndParseFile(ctx context.Context, u *url.URL) error {
-	r, err := c.schemes.Fetch(ctx, u)
+	s, err := c.getScript(ctx, u)
 	if err != nil {
 		return err
 	}
+	c.wd = s.wd
+	return c.run(ctx, newState(s, c.vars))
+}
+
+// getScript fetches the script at u.
+func (c *parser) getScript(ctx context.Context, u *url.URL) (*script, error) {
+	r, err := c.schemes.Fetch(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile(r)
 	data, err := uio.ReadAll(r)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return c.readScript(data, u, r)
+}
+
+// readScript parses data, the contents of the script at u read from r.
+func (c *parser) readScript(data []byte, u *url.URL, r any) (*script, error) {
 	config := string(data)
 	if !strings.HasPrefix(config, "#!ipxe") {
-		return ErrNotIpxeScript
+		return nil, ErrNotIpxeScript
 	}
 	c.log.Printf("Got ipxe config file %s:\n%s\n", r, config)
 
 	// Parent dir of the config file.
-	c.wd = &url.URL{
+	wd := &url.URL{
 		Scheme: u.Scheme,
 		Host:   u.Host,
 		Path:   path.Dir(u.Path),
 	}
-	return c.parseIpxe(config)
+	return parseScript(config, wd), nil
 }
 
-// getFile parses `surl` and returns an io.Reader for the requested url.
-func (c *parser) getFile(surl string) (io.ReaderAt, error) {
-	u, err := parseURL(surl, c.wd)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse URL %q: %w", surl, err)
+// sniff reads enough of r to tell whether it is an iPXE script. It returns
+// a reader for all of r.
+func sniff(r io.Reader) (bool, io.Reader, error) {
+	magic := make([]byte, len("#!ipxe"))
+	n, err := io.ReadFull(r, magic)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, nil, err
 	}
+	return string(magic[:n]) == "#!ipxe", io.MultiReader(bytes.NewReader(magic[:n]), r), nil
+}
+
+// closeFile closes a fetched file if it can be closed.
+func closeFile(r any) error {
+	if c, ok := r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// readCloser reads a file through Reader and closes the file f.
+type readCloser struct {
+	io.Reader
+	f any
+}
+
+func (r *readCloser) Close() error {
+	return closeFile(r.f)
+}
+
+// getFile returns an io.ReaderAt for the file at u. If r is not nil, it is
+// the already opened file, which is read and closed instead of fetching u
+// again.
+func (c *parser) getFile(u *url.URL, r io.Reader) io.ReaderAt {
 	// Cache content read from http body into a tmpfs file, other
 	// than in heap. This cuts down ram consumption and help boot
 	// on board with low ram config.
-	return uio.NewLazyOpenerAt(surl, func() (io.ReaderAt, error) {
+	return uio.NewLazyOpenerAt(u.String(), func() (io.ReaderAt, error) {
 		f, err := os.CreateTemp("", "cache-kernel")
 		if err != nil {
 			return nil, err
 		}
 		defer f.Close()
-		r, err := c.schemes.LazyFetchWithoutCache(u)
-		if err != nil {
-			return nil, err
+		if r == nil {
+			if r, err = c.schemes.LazyFetchWithoutCache(u); err != nil {
+				return nil, err
+			}
 		}
+		defer closeFile(r)
 		_, err = io.Copy(f, r)
 		if err != nil {
 			return nil, err
@@ -113,15 +250,7 @@ func (c *parser) getFile(surl string) (io.ReaderAt, error) {
 			return nil, err
 		}
 		return readOnlyF, nil
-	}), nil
-}
-
-func (c *parser) getFileWithoutCache(surl string) (io.Reader, error) {
-	u, err := parseURL(surl, c.wd)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse URL %q: %w", surl, err)
-	}
-	return c.schemes.LazyFetchWithoutCache(u)
+	})
 }
 
 func parseURL(name string, wd *url.URL) (*url.URL, error) {
@@ -149,70 +278,509 @@ func parseURL(name string, wd *url.URL) (*url.URL, error) {
 	return u, nil
 }
 
-func (c *parser) createInitrd(initrds []io.Reader) {
-	if len(initrds) > 0 {
-		c.bootImage.Initrd = boot.CatInitrdsWithFileCache(initrds...)
+// frame is a script being run.
+type frame struct {
+	s *script
+
+	// pc is the index of the next line to run.
+	pc int
+}
+
+// menuItem is an item of an iPXE menu.
+type menuItem struct {
+	label     string
+	text      string
+	isDefault bool
+}
+
+// state is the state of a script being run.
+//
+// At a menu, the script is run once for each item with a copy of the state.
+type state struct {
+	vars map[string]string
+
+	// frames are the scripts being run, the current one last. A chained
+	// script returns to the script that chained it when it ends.
+	frames []frame
+
+	// rest is the rest of the line the menu item was chosen on, to be
+	// run first.
+	rest []token
+
+	// Selected image. kernelFile is the kernel file if chain has opened
+	// it already.
+	kernel     *url.URL
+	kernelName string
+	kernelFile io.Reader
+	cmdline    string
+	initrds    []*url.URL
+
+	// menu is the menu being built with item.
+	menu []menuItem
+
+	// name is the text of the menu item chosen last.
+	name string
+
+	// chosen are the lines the menus were chosen at.
+	chosen []frame
+
+	steps int
+	done  bool
+}
+
+func newState(s *script, vars map[string]string) *state {
+	st := &state{
+		vars:   make(map[string]string),
+		frames: []frame{{s: s}},
 	}
+	maps.Copy(st.vars, vars)
+	return st
 }
 
-// parseIpxe parses `config` and constructs a BootImage for `c`.
-func (c *parser) parseIpxe(config string) error {
-	// A trivial ipxe script parser.
-	// Currently only supports kernel and initrd commands.
-	c.bootImage = &boot.LinuxImage{}
+func (st *state) clone() *state {
+	n := *st
+	n.vars = maps.Clone(st.vars)
+	n.frames = slices.Clone(st.frames)
+	n.initrds = slices.Clone(st.initrds)
+	n.menu = nil
+	n.chosen = slices.Clone(st.chosen)
+	return &n
+}
 
-	var initrds []io.Reader
-	for line := range strings.SplitSeq(config, "\n") {
-		// Skip blank lines and comment lines.
-		line = strings.TrimSpace(line)
-		if line == "" || line[0] == '#' {
+func (st *state) current() *frame {
+	return &st.frames[len(st.frames)-1]
+}
+
+// lookup returns the value of a setting.
+func (st *state) lookup(name string) string {
+	if v, ok := st.vars[name]; ok {
+		return v
+	}
+	scope, setting, scoped := strings.Cut(name, "/")
+	switch {
+	case !scoped:
+		// iPXE searches all settings blocks for unscoped settings.
+		return st.vars["net0/"+name]
+	case scope == "netX":
+		// netX is the most recently opened network device.
+		return st.vars["net0/"+setting]
+	}
+	return ""
+}
+
+// parseIpxe runs the script `config`, adding the images it boots to `c`.
+func (c *parser) parseIpxe(ctx context.Context, config string) error {
+	return c.run(ctx, newState(parseScript(config, c.wd), c.vars))
+}
+
+// run runs the script of st and adds the images it boots to c.
+//
+// The script runs until it boots, exits or reaches a menu. If it reaches
+// the end, the selected image, if any, is booted.
+func (c *parser) run(ctx context.Context, st *state) error {
+	if err := c.runScript(ctx, st); err != nil && len(c.images) == 0 {
+		return err
+	} else if err != nil {
+		c.log.Printf("ipxe script failed: %v", err)
+	}
+	if len(c.images) == 0 {
+		return ErrNoImage
+	}
+	return nil
+}
+
+func (c *parser) runScript(ctx context.Context, st *state) error {
+	if st.rest != nil {
+		rest := st.rest
+		st.rest = nil
+		if err := c.execLine(ctx, st, rest); err != nil {
+			return err
+		}
+	}
+	for !st.done && len(st.frames) > 0 {
+		f := st.current()
+		if f.pc >= len(f.s.lines) {
+			st.frames = st.frames[:len(st.frames)-1]
 			continue
 		}
+		line := f.s.lines[f.pc]
+		f.pc++
 
-		args := strings.Fields(line)
-		if len(args) == 0 {
+		// Skip blank lines, comment lines and labels.
+		if line == "" || line[0] == '#' || line[0] == ':' {
 			continue
 		}
-		cmd := strings.ToLower(args[0])
 
-		switch cmd {
-		case "kernel":
-			if len(args) > 1 {
-				k, err := c.getFile(args[1])
-				if err != nil {
-					return err
-				}
-				c.bootImage.Kernel = k
+		st.steps++
+		if st.steps > maxSteps {
+			return errTooManyCmds
+		}
+		if err := c.execLine(ctx, st, tokenize(line)); err != nil {
+			return fmt.Errorf("%q: %w", line, err)
+		}
+	}
+	if st.done || st.kernel == nil {
+		return nil
+	}
+
+	// EOF - we should go ahead and boot.
+	return c.boot(st)
+}
+
+// execLine runs the commands of a line joined by || and &&. Like iPXE, it
+// returns the error of the last command run.
+func (c *parser) execLine(ctx context.Context, st *state, tokens []token) error {
+	var err error
+	run := true
+	for {
+		i := slices.IndexFunc(tokens, func(t token) bool { return t.op })
+		if i < 0 {
+			i = len(tokens)
+		}
+		if run {
+			var args []string
+			for _, t := range tokens[:i] {
+				args = append(args, expand(t.text, st.lookup))
+			}
+			err = c.exec(ctx, st, args, tokens[i:])
+			if st.done {
+				return nil
 			}
+		}
+		if i == len(tokens) {
+			return err
+		}
+		switch tokens[i].text {
+		case "||":
+			run = err != nil
+		case "&&":
+			run = err == nil
+		}
+		tokens = tokens[i+1:]
+	}
+}
+
+// exec runs a command. rest is the rest of the line after the command.
+func (c *parser) exec(ctx context.Context, st *state, args []string, rest []token) error {
+	if len(args) == 0 {
+		return nil
+	}
+	cmd := strings.ToLower(args[0])
+	args = args[1:]
+
+	switch cmd {
+	case "kernel", "imgselect", "imgload", "load":
+		return c.selectKernel(st, args)
+
+	case "initrd", "imgfetch", "module", "fetch":
+		return c.fetchInitrd(st, args)
+
+	case "chain", "imgexec", "exec":
+		return c.chain(ctx, st, args)
+
+	case "boot", "imgboot":
+		if st.kernel == nil {
+			return errNoKernel
+		}
+		return c.boot(st)
+
+	case "imgargs":
+		if len(args) < 1 {
+			return errUsage
+		}
+		if st.kernel == nil || args[0] != st.kernelName {
+			return fmt.Errorf("%w: %q", errNoKernel, args[0])
+		}
+		st.cmdline = strings.Join(args[1:], " ")
+		return nil
+
+	case "set":
+		if len(args) < 1 {
+			return errUsage
+		}
+		if len(args) == 1 {
+			delete(st.vars, args[0])
+		} else {
+			st.vars[args[0]] = strings.Join(args[1:], " ")
+		}
+		return nil
+
+	case "clear":
+		if len(args) != 1 {
+			return errUsage
+		}
+		delete(st.vars, args[0])
+		return nil
+
+	case "isset":
+		if len(args) < 1 || args[0] == "" {
+			return errNotSet
+		}
+		return nil
+
+	case "iseq":
+		if len(args) != 2 {
+			return errUsage
+		}
+		if args[0] != args[1] {
+			return errNotEqual
+		}
+		return nil
+
+	case "goto":
+		if len(args) != 1 {
+			return errUsage
+		}
+		f := st.current()
+		pc, ok := f.s.labels[args[0]]
+		if !ok {
+			return fmt.Errorf("%w: %q", errNoSuchLabel, args[0])
+		}
+		f.pc = pc
+		return nil
+
+	case "menu":
+		st.menu = nil
+		return nil
+
+	case "item":
+		return c.addItem(st, args)
+
+	case "choose":
+		return c.choose(ctx, st, args, rest)
+
+	case "echo":
+		c.log.Printf("%s", strings.Join(args, " "))
+		return nil
+
+	case "prompt":
+		// Nobody is there to press a key.
+		return errNoKey
+
+	case "exit", "shell":
+		c.log.Printf("ipxe script ran %q, not booting", cmd)
+		st.done = true
+		return nil
 
-			// Add cmdline if there are any.
-			if len(args) > 2 {
-				c.bootImage.Cmdline = strings.Join(args[2:], " ")
+	case "dhcp", "ifopen", "ifconf", "ifstat", "sleep":
+		// The network is already up.
+		return nil
+
+	default:
+		c.log.Printf("Ignoring unsupported ipxe cmd: %s %s", cmd, strings.Join(args, " "))
+		return nil
+	}
+}
+
+// imageArgs parses the options of the image commands, returning the
+// image name given with --name and the remaining arguments.
+func imageArgs(args []string) (string, []string) {
+	var name string
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		opt, val, hasVal := strings.Cut(args[0], "=")
+		args = args[1:]
+		switch opt {
+		case "-n", "--name", "-t", "--timeout":
+			if !hasVal && len(args) > 0 {
+				val = args[0]
+				args = args[1:]
 			}
+			if opt == "-n" || opt == "--name" {
+				name = val
+			}
+		}
+	}
+	return name, args
+}
 
-		case "initrd":
-			if len(args) > 1 {
-				for f := range strings.SplitSeq(args[1], ",") {
-					i, err := c.getFileWithoutCache(f)
-					if err != nil {
-						return err
-					}
-					initrds = append(initrds, i)
-				}
+func (c *parser) selectKernel(st *state, args []string) error {
+	name, args := imageArgs(args)
+	if len(args) < 1 {
+		return errUsage
+	}
+	u, err := parseURL(args[0], st.current().s.wd)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		name = path.Base(u.Path)
+	}
+	st.kernel = u
+	st.kernelName = name
+	st.kernelFile = nil
+	st.cmdline = strings.Join(args[1:], " ")
+	return nil
+}
+
+func (c *parser) fetchInitrd(st *state, args []string) error {
+	_, args = imageArgs(args)
+	if len(args) < 1 {
+		return errUsage
+	}
+	// Comma-separated lists of initrds are a u-root extension.
+	for f := range strings.SplitSeq(args[0], ",") {
+		u, err := parseURL(f, st.current().s.wd)
+		if err != nil {
+			return err
+		}
+		st.initrds = append(st.initrds, u)
+	}
+	return nil
+}
+
+// chain runs the script at the given URL, or boots the kernel at the URL
+// if it is not a script.
+func (c *parser) chain(ctx context.Context, st *state, args []string) error {
+	_, args = imageArgs(args)
+	if len(args) < 1 {
+		return errUsage
+	}
+	u, err := parseURL(args[0], st.current().s.wd)
+	if err != nil {
+		return err
+	}
+	f, err := c.schemes.FetchWithoutCache(ctx, u)
+	if err != nil {
+		return err
+	}
+	ok, r, err := sniff(f)
+	if err != nil {
+		closeFile(f)
+		return err
+	}
+	if !ok {
+		if err := c.selectKernel(st, args); err != nil {
+			closeFile(f)
+			return err
+		}
+		st.kernelFile = &readCloser{r, f}
+		return c.boot(st)
+	}
+	defer closeFile(f)
+
+	if len(st.frames) >= maxChainDepth {
+		return errChainDepth
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s, err := c.readScript(data, u, f)
+	if err != nil {
+		return err
+	}
+	st.frames = append(st.frames, frame{s: s})
+	return nil
+}
+
+func (c *parser) addItem(st *state, args []string) error {
+	var item menuItem
+	var gap bool
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		opt, _, hasVal := strings.Cut(args[0], "=")
+		args = args[1:]
+		switch opt {
+		case "-k", "--key", "-m", "--menu":
+			if !hasVal && len(args) > 0 {
+				args = args[1:]
 			}
+		case "-d", "--default":
+			item.isDefault = true
+		case "-g", "--gap":
+			gap = true
+		}
+	}
+	if gap || len(args) == 0 {
+		// Separator.
+		return nil
+	}
+	item.label = args[0]
+	item.text = strings.Join(args[1:], " ")
+	if item.text == "" {
+		item.text = item.label
+	}
+	st.menu = append(st.menu, item)
+	return nil
+}
 
-		case "boot":
-			// Stop parsing at this point, we should go ahead and
-			// boot.
-			c.createInitrd(initrds)
-			return nil
+// choose runs the rest of the script once for each item of the menu, with
+// the setting named by the last argument set to the label of the item.
+// The default item is run first.
+func (c *parser) choose(ctx context.Context, st *state, args []string, rest []token) error {
+	var def string
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		opt, val, hasVal := strings.Cut(args[0], "=")
+		args = args[1:]
+		switch opt {
+		case "-d", "--default", "-t", "--timeout", "-m", "--menu":
+			if !hasVal && len(args) > 0 {
+				val = args[0]
+				args = args[1:]
+			}
+			if opt == "-d" || opt == "--default" {
+				def = val
+			}
+		}
+	}
+	if len(args) != 1 {
+		return errUsage
+	}
+	if len(st.menu) == 0 {
+		return errNoItems
+	}
+
+	// A menu reached again through one of its own items, e.g. a "back"
+	// item, has already been offered.
+	here := frame{s: st.current().s, pc: st.current().pc}
+	st.done = true
+	if slices.Contains(st.chosen, here) {
+		return nil
+	}
 
-		default:
-			c.log.Printf("Ignoring unsupported ipxe cmd: %s", line)
+	items := slices.Clone(st.menu)
+	i := slices.IndexFunc(items, func(item menuItem) bool {
+		return item.label == def || (def == "" && item.isDefault)
+	})
+	if i > 0 {
+		first := items[i]
+		items = slices.Insert(slices.Delete(items, i, i+1), 0, first)
+	}
+	for _, item := range items {
+		branch := st.clone()
+		branch.done = false
+		branch.vars[args[0]] = item.label
+		branch.name = item.text
+		branch.rest = rest
+		branch.chosen = append(branch.chosen, here)
+		if err := c.runScript(ctx, branch); err != nil {
+			c.log.Printf("ipxe menu item %q failed: %v", item.label, err)
 		}
 	}
+	return nil
+}
 
-	// EOF - we should go ahead and boot.
-	c.createInitrd(initrds)
+// boot adds the selected image to c and ends the script.
+func (c *parser) boot(st *state) error {
+	img := &boot.LinuxImage{
+		Name:    st.name,
+		Cmdline: st.cmdline,
+	}
+	if st.kernel != nil {
+		img.Kernel = c.getFile(st.kernel, st.kernelFile)
+	}
+
+	var initrds []io.Reader
+	for _, u := range st.initrds {
+		i, err := c.schemes.LazyFetchWithoutCache(u)
+		if err != nil {
+			return err
+		}
+		initrds = append(initrds, i)
+	}
+	if len(initrds) > 0 {
+		img.Initrd = boot.CatInitrdsWithFileCache(initrds...)
+	}
+	c.images = append(c.images, img)
+	st.done = true
 	return nil
 }
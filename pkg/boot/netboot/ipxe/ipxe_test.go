@@ -6,10 +6,12 @@ package ipxe
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
 	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -358,7 +360,7 @@ func TestIpxeConfig(t *testing.T) {
 				Host:   "someplace.com",
 				Path:   "/foobar/pxefiles/ipxeconfig",
 			},
-			want: &boot.LinuxImage{},
+			err: ErrNoImage,
 		},
 		{
 			desc: "valid config with kernel cmdline args",
@@ -465,3 +467,296 @@ func TestIpxeConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestIpxeScript(t *testing.T) {
+	type image struct {
+		name    string
+		kernel  string
+		initrd  string
+		cmdline string
+	}
+	for _, tt := range []struct {
+		desc  string
+		files map[string]string
+		vars  map[string]string
+		want  []image
+		err   error
+	}{
+		{
+			desc: "variables and expansion",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				set base http://someplace.com/boot
+				set args console=ttyS0 \
+					quiet
+				kernel ${base}/kernel-${buildarch:string} ${args} mac=${net0/mac:hexhyp} ip=${ip}
+				initrd ${base}/initrd
+				boot`,
+				"/boot/kernel-" + buildArches[runtime.GOARCH]: "kernel",
+				"/boot/initrd": "initrd",
+			},
+			vars: map[string]string{"net0/mac": "52:54:00:12:34:56", "net0/ip": "192.168.0.2"},
+			want: []image{{kernel: "kernel", initrd: "initrd", cmdline: "console=ttyS0 quiet mac=52-54-00-12-34-56 ip=192.168.0.2"}},
+		},
+		{
+			desc: "goto, isset and iseq",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				isset ${hostname} || set hostname unknown
+				iseq ${hostname} unknown && goto provision ||
+				kernel kernel-installed
+				boot
+				:provision
+				kernel kernel-provision host=${hostname}
+				boot`,
+				"/boot/kernel-provision": "provision",
+			},
+			want: []image{{kernel: "provision", cmdline: "host=unknown"}},
+		},
+		{
+			desc: "imgargs",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				kernel --name vmlinuz kernel
+				imgargs vmlinuz root=/dev/nfs
+				boot vmlinuz`,
+				"/boot/kernel": "kernel",
+			},
+			want: []image{{kernel: "kernel", cmdline: "root=/dev/nfs"}},
+		},
+		{
+			desc: "chain to script by mac",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				chain --autofree hosts/${mac:hexraw}.ipxe || chain hosts/default.ipxe`,
+				"/boot/hosts/default.ipxe": `#!ipxe
+				kernel /other/kernel`,
+				"/other/kernel": "kernel",
+			},
+			vars: map[string]string{"net0/mac": "52:54:00:12:34:56"},
+			want: []image{{kernel: "kernel"}},
+		},
+		{
+			desc: "chain to kernel",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				initrd initrd
+				chain kernel console=ttyS0`,
+				"/boot/kernel": "kernel",
+				"/boot/initrd": "initrd",
+			},
+			want: []image{{kernel: "kernel", initrd: "initrd", cmdline: "console=ttyS0"}},
+		},
+		{
+			desc: "chained script returns",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				chain vars.ipxe
+				kernel kernel-${flavor}
+				boot`,
+				"/boot/vars.ipxe": `#!ipxe
+				set flavor debug`,
+				"/boot/kernel-debug": "kernel",
+			},
+			want: []image{{kernel: "kernel"}},
+		},
+		{
+			desc: "menu",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				:start
+				menu Boot menu
+				item --gap Operating systems
+				item linux Linux
+				item --key r rescue Rescue system
+				item shell Drop to shell
+				choose --default rescue --timeout 5000 target && goto ${target}
+				:linux
+				kernel kernel
+				boot
+				:rescue
+				kernel kernel rescue
+				boot
+				:shell
+				shell`,
+				"/boot/kernel": "kernel",
+			},
+			want: []image{
+				{name: "Rescue system", kernel: "kernel", cmdline: "rescue"},
+				{name: "Linux", kernel: "kernel"},
+			},
+		},
+		{
+			desc: "nested menus with back item",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				:start
+				menu
+				item linux Linux
+				item more More...
+				choose target || exit
+				goto ${target}
+				:more
+				menu
+				item debug Debug
+				item start Back
+				choose target && goto ${target} ||
+				:debug
+				kernel kernel debug
+				boot
+				:linux
+				kernel kernel
+				boot`,
+				"/boot/kernel": "kernel",
+			},
+			want: []image{
+				{name: "Linux", kernel: "kernel"},
+				{name: "Debug", kernel: "kernel", cmdline: "debug"},
+			},
+		},
+		{
+			desc: "prompt for shell",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				prompt --key 0x02 --timeout 2000 Press Ctrl-B for the iPXE command line... && shell ||
+				kernel kernel`,
+				"/boot/kernel": "kernel",
+			},
+			want: []image{{kernel: "kernel"}},
+		},
+		{
+			desc: "exit",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				exit
+				kernel kernel`,
+			},
+			err: ErrNoImage,
+		},
+		{
+			desc: "failing command ends script",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				goto nowhere
+				kernel kernel`,
+			},
+			err: errNoSuchLabel,
+		},
+		{
+			desc: "boot without kernel",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				boot`,
+			},
+			err: errNoKernel,
+		},
+		{
+			desc: "loop",
+			files: map[string]string{
+				"/boot/ipxeconfig": `#!ipxe
+				:retry
+				chain missing.ipxe || goto retry`,
+			},
+			err: errTooManyCmds,
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			fs := curl.NewMockScheme("http")
+			for p, content := range tt.files {
+				fs.Add("someplace.com", p, content)
+			}
+			s := make(curl.Schemes)
+			s.Register(fs.Scheme, fs)
+
+			u := &url.URL{Scheme: "http", Host: "someplace.com", Path: "/boot/ipxeconfig"}
+			got, err := ParseConfigImages(context.Background(), ulogtest.Logger{TB: t}, u, s, WithVars(tt.vars))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseConfigImages() = %v, want %v", err, tt.err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseConfigImages() = %d images, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				li := got[i].(*boot.LinuxImage)
+				if li.Name != want.name {
+					t.Errorf("image %d: got name %q, want %q", i, li.Name, want.name)
+				}
+				if k := mustReadAll(li.Kernel); k != want.kernel {
+					t.Errorf("image %d: got kernel %q, want %q", i, k, want.kernel)
+				}
+				if r := mustReadAll(li.Initrd); r != want.initrd {
+					t.Errorf("image %d: got initrd %q, want %q", i, r, want.initrd)
+				}
+				if li.Cmdline != want.cmdline {
+					t.Errorf("image %d: got cmdline %q, want %q", i, li.Cmdline, want.cmdline)
+				}
+			}
+		})
+	}
+}
+
+func TestChainFetchesOnce(t *testing.T) {
+	fs := curl.NewMockScheme("http")
+	fs.Add("someplace.com", "/boot/ipxeconfig", "#!ipxe\nchain next.ipxe")
+	fs.Add("someplace.com", "/boot/next.ipxe", "#!ipxe\nchain kernel")
+	fs.Add("someplace.com", "/boot/kernel", "kernel")
+	s := make(curl.Schemes)
+	s.Register(fs.Scheme, fs)
+
+	u := &url.URL{Scheme: "http", Host: "someplace.com", Path: "/boot/ipxeconfig"}
+	img, err := ParseConfig(context.Background(), ulogtest.Logger{TB: t}, u, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := mustReadAll(img.Kernel); k != "kernel" {
+		t.Errorf("got kernel %q, want %q", k, "kernel")
+	}
+	for _, p := range []string{"/boot/next.ipxe", "/boot/kernel"} {
+		u := &url.URL{Scheme: "http", Host: "someplace.com", Path: p}
+		if n := fs.NumCalled(u); n != 1 {
+			t.Errorf("%s fetched %d times, want 1", p, n)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize(`kernel "a b" 'c||d' e\ f || echo "&&" && x`)
+	want := []token{
+		{text: "kernel"},
+		{text: "a b"},
+		{text: "c||d"},
+		{text: "e f"},
+		{text: "||", op: true},
+		{text: "echo"},
+		{text: "&&"},
+		{text: "&&", op: true},
+		{text: "x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	vars := map[string]string{
+		"net0/mac": "52:54:00:12:34:56",
+		"which":    "net0/mac",
+		"url":      "a b&c/d",
+	}
+	lookup := func(name string) string { return vars[name] }
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{in: "${net0/mac}", want: "52:54:00:12:34:56"},
+		{in: "x${net0/mac:hexhyp}y", want: "x52-54-00-12-34-56y"},
+		{in: "${net0/mac:hexraw}", want: "525400123456"},
+		{in: "${${which}}", want: "52:54:00:12:34:56"},
+		{in: "${url:uristring}", want: "a%20b%26c%2Fd"},
+		{in: "${unset}-", want: "-"},
+		{in: "${unterminated", want: "${unterminated"},
+	} {
+		if got := expand(tt.in, lookup); got != tt.want {
+			t.Errorf("expand(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
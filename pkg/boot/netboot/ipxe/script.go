@@ -0,0 +1,168 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipxe
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// script is an iPXE script split into lines.
+type script struct {
+	// wd is the directory the script was fetched from. Relative file
+	// paths in the script are interpreted relative to this URL.
+	wd *url.URL
+
+	lines []string
+
+	// labels maps a label to the index of the line defining it.
+	labels map[string]int
+}
+
+func parseScript(config string, wd *url.URL) *script {
+	s := &script{
+		wd:     wd,
+		labels: make(map[string]int),
+	}
+	var cont string
+	for line := range strings.SplitSeq(config, "\n") {
+		line = strings.TrimSpace(line)
+
+		// A trailing backslash continues the line.
+		if l, ok := strings.CutSuffix(line, `\`); ok {
+			cont += l
+			continue
+		}
+		line = cont + line
+		cont = ""
+
+		if label, ok := strings.CutPrefix(line, ":"); ok {
+			label = strings.TrimSpace(label)
+			// Like goto in iPXE, which searches from the start of
+			// the script, the first definition wins.
+			if _, ok := s.labels[label]; !ok {
+				s.labels[label] = len(s.lines)
+			}
+		}
+		s.lines = append(s.lines, line)
+	}
+	if cont != "" {
+		s.lines = append(s.lines, cont)
+	}
+	return s
+}
+
+// token is a word of a command line.
+type token struct {
+	text string
+
+	// op is true for the unquoted command separators || and &&.
+	op bool
+}
+
+// tokenize splits a command line into words. Words are separated by
+// whitespace, quotes group words, and a backslash escapes the next
+// character.
+func tokenize(line string) []token {
+	var (
+		tokens []token
+		cur    strings.Builder
+		quote  rune
+		inWord bool
+		quoted bool
+		escape bool
+	)
+	end := func() {
+		if inWord {
+			text := cur.String()
+			tokens = append(tokens, token{
+				text: text,
+				op:   !quoted && (text == "||" || text == "&&"),
+			})
+		}
+		cur.Reset()
+		inWord, quoted = false, false
+	}
+	for _, r := range line {
+		switch {
+		case escape:
+			cur.WriteRune(r)
+			escape = false
+		case r == '\\':
+			inWord, escape = true, true
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			inWord, quoted = true, true
+			quote = r
+		case r == ' ' || r == '\t' || r == '\r':
+			end()
+		default:
+			inWord = true
+			cur.WriteRune(r)
+		}
+	}
+	end()
+	return tokens
+}
+
+// expand replaces ${name} and ${name:type} in s with the value of the
+// setting name, formatted according to type. Unset settings expand to the
+// empty string.
+//
+// Expansions nest: the innermost ${...} is expanded first, so that
+// ${${var}} expands to the setting named by the value of var.
+func expand(s string, lookup func(name string) string) string {
+	limit := len(s)
+	for {
+		start := strings.LastIndex(s[:limit], "${")
+		if start < 0 {
+			return s
+		}
+		length := strings.IndexByte(s[start:], '}')
+		if length < 0 {
+			limit = start
+			continue
+		}
+		name, typ, _ := strings.Cut(s[start+2:start+length], ":")
+		s = s[:start] + format(lookup(name), typ) + s[start+length+1:]
+		limit = start
+	}
+}
+
+// format formats a setting value according to an iPXE setting type. Values
+// are stored as strings, so only the types changing the representation of
+// MAC-style hex strings and URIs are interesting.
+func format(v, typ string) string {
+	switch typ {
+	case "hexhyp":
+		return strings.ReplaceAll(v, ":", "-")
+	case "hexraw":
+		return strings.ReplaceAll(v, ":", "")
+	case "uristring":
+		return uriEscape(v)
+	default:
+		return v
+	}
+}
+
+// uriEscape percent-encodes v like iPXE encodes uristring settings:
+// non-printing characters, spaces, '%' and the characters delimiting URI
+// fields.
+func uriEscape(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte("%/#:@?=&", c) >= 0 {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
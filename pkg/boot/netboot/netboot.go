@@ -46,28 +46,71 @@ func BootImages(ctx context.Context, l ulog.Logger, s curl.Schemes, lease dhclie
 	if p4, ok := lease.(*dhclient.Packet4); ok {
 		ip = p4.Lease().IP
 	}
-	return getBootImages(ctx, l, s, uri, lease.Link().Attrs().HardwareAddr, ip), nil
+	return getBootImages(ctx, l, s, uri, lease.Link().Attrs().HardwareAddr, ip, leaseVars(lease, uri)), nil
+}
+
+// leaseVars returns the iPXE settings of net0 derived from lease, which was
+// received for the boot file at uri.
+func leaseVars(lease dhclient.Lease, uri *url.URL) map[string]string {
+	vars := map[string]string{
+		"net0/filename": uri.String(),
+	}
+	if mac := lease.Link().Attrs().HardwareAddr; mac != nil {
+		vars["net0/mac"] = mac.String()
+	}
+
+	switch p := lease.(type) {
+	case *dhclient.Packet4:
+		vars["net0/ip"] = p.P.YourIPAddr.String()
+		if mask := p.P.SubnetMask(); mask != nil {
+			vars["net0/netmask"] = net.IP(mask).String()
+		}
+		if routers := p.P.Router(); len(routers) > 0 {
+			vars["net0/gateway"] = routers[0].String()
+		}
+		if dns := p.P.DNS(); len(dns) > 0 {
+			vars["net0/dns"] = dns[0].String()
+		}
+		if domain := p.P.DomainName(); domain != "" {
+			vars["net0/domain"] = domain
+		}
+		if hostname := p.P.HostName(); hostname != "" {
+			vars["net0/hostname"] = hostname
+		}
+		if !p.P.ServerIPAddr.IsUnspecified() {
+			vars["net0/next-server"] = p.P.ServerIPAddr.String()
+		}
+
+	case *dhclient.Packet6:
+		if iana := p.Lease(); iana != nil {
+			vars["net0/ip6"] = iana.IPv6Addr.String()
+		}
+		if dns := p.DNS(); len(dns) > 0 {
+			vars["net0/dns6"] = dns[0].String()
+		}
+	}
+	return vars
 }
 
 // getBootImages attempts to parse the file at uri as an ipxe config and returns
-// the ipxe boot image. Otherwise falls back to pxe and uses the uri directory,
+// the ipxe boot images. Otherwise falls back to pxe and uses the uri directory,
 // ip, and mac address to search for pxe configs.
-func getBootImages(ctx context.Context, l ulog.Logger, schemes curl.Schemes, uri *url.URL, mac net.HardwareAddr, ip net.IP) []boot.OSImage {
+//
+// vars are the settings iPXE scripts may use.
+func getBootImages(ctx context.Context, l ulog.Logger, schemes curl.Schemes, uri *url.URL, mac net.HardwareAddr, ip net.IP, vars map[string]string) []boot.OSImage {
 	var images []boot.OSImage
 
 	// 1: Attempt to download the given url as is.
 	//
 	// 1.1: Try ipxe config file.
-	ipc, err := ipxe.ParseConfig(ctx, l, uri, schemes)
+	ipc, err := ipxe.ParseConfigImages(ctx, l, uri, schemes, ipxe.WithVars(vars))
 	if err != nil {
 		l.Printf("Parsing boot files as iPXE failed, trying other formats...: %v", err)
 	}
-	if ipc != nil {
-		images = append(images, ipc)
-	}
+	images = append(images, ipc...)
 
 	// 1.2: Check if target is a simple file instead of config script
-	if ipc == nil {
+	if len(ipc) == 0 {
 		l.Printf("Trying to parse file as a non config Image...")
 		sImages, err := simple.FetchAndProbe(ctx, uri, schemes)
 		if err != nil {
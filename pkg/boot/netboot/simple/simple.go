@@ -25,6 +25,7 @@ func FetchAndProbe(ctx context.Context, u *url.URL, s curl.Schemes) ([]boot.OSIm
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 	var images []boot.OSImage
 
 	fimgs, err := fit.ParseConfig(io.NewSectionReader(file, 0, math.MaxInt64))
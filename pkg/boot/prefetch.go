@@ -0,0 +1,62 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boot
+
+import (
+	"context"
+	"io"
+	"math"
+
+	"github.com/u-root/uio/ulog"
+	"golang.org/x/sync/errgroup"
+)
+
+// artifacts returns the files of img that Load reads.
+func artifacts(img OSImage) []io.ReaderAt {
+	var rs []io.ReaderAt
+	switch img := img.(type) {
+	case *LinuxImage:
+		rs = append(rs, img.Kernel, img.Initrd, img.DTB)
+	case *MultibootImage:
+		rs = append(rs, img.Kernel)
+		for _, m := range img.Modules {
+			rs = append(rs, m.Module)
+		}
+	}
+	return rs
+}
+
+// Prefetch reads the kernels, initramfs, device trees and modules of imgs,
+// up to parallel at a time, so that loading them later does not wait for
+// their download. If parallel is not positive, there is no limit.
+//
+// It is most useful with images parsed from files fetched through a
+// curl.Cache, which keeps the files on disk rather than in memory.
+//
+// Prefetch reads all files even if some fail, and returns the first error.
+func Prefetch(ctx context.Context, l ulog.Logger, parallel int, imgs ...OSImage) error {
+	var g errgroup.Group
+	if parallel > 0 {
+		g.SetLimit(parallel)
+	}
+	for _, img := range imgs {
+		for _, r := range artifacts(img) {
+			if r == nil {
+				continue
+			}
+			g.Go(func() error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if _, err := io.Copy(io.Discard, io.NewSectionReader(r, 0, math.MaxInt64)); err != nil {
+					l.Printf("Prefetching %s failed: %v", stringer(r), err)
+					return err
+				}
+				return nil
+			})
+		}
+	}
+	return g.Wait()
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/boot/multiboot"
+	"github.com/u-root/uio/ulog/ulogtest"
+)
+
+// countingReaderAt counts the bytes read from it.
+type countingReaderAt struct {
+	r    io.ReaderAt
+	read atomic.Int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+type errReaderAt struct{ err error }
+
+func (e errReaderAt) ReadAt([]byte, int64) (int, error) {
+	return 0, e.err
+}
+
+func TestPrefetch(t *testing.T) {
+	newFile := func(s string) *countingReaderAt {
+		return &countingReaderAt{r: strings.NewReader(s)}
+	}
+	kernel, initrd := newFile("kernel"), newFile("initramfs")
+	mbKernel, module := newFile("xen"), newFile("dom0")
+	imgs := []OSImage{
+		&LinuxImage{Kernel: kernel, Initrd: initrd},
+		&MultibootImage{Kernel: mbKernel, Modules: []multiboot.Module{{Module: module}}},
+	}
+	if err := Prefetch(context.Background(), ulogtest.Logger{TB: t}, 2, imgs...); err != nil {
+		t.Fatalf("Prefetch = %v", err)
+	}
+	for _, f := range []*countingReaderAt{kernel, initrd, mbKernel, module} {
+		if got, want := f.read.Load(), f.r.(*strings.Reader).Size(); got != want {
+			t.Errorf("read %d bytes, want %d", got, want)
+		}
+	}
+
+	errFetch := errors.New("fetch failed")
+	other := newFile("kernel")
+	imgs = []OSImage{
+		&LinuxImage{Kernel: errReaderAt{errFetch}},
+		&LinuxImage{Kernel: other},
+	}
+	if err := Prefetch(context.Background(), ulogtest.Logger{TB: t}, 0, imgs...); !errors.Is(err, errFetch) {
+		t.Errorf("Prefetch = %v, want %v", err, errFetch)
+	}
+	if other.read.Load() == 0 {
+		t.Errorf("Prefetch stopped at the first error")
+	}
+}
@@ -1470,6 +1470,139 @@ var Purgatories = map[string]*Purgatory{
 			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
 		},
 	},
+	"crash": {
+		Name: "crash",
+		Hexdump: `00000000  7f 45 4c 46 02 01 01 00  00 00 00 00 00 00 00 00  |.ELF............|
+00000010  02 00 3e 00 01 00 00 00  00 00 00 00 00 00 00 00  |..>.............|
+00000020  40 00 00 00 00 00 00 00  b0 02 00 00 00 00 00 00  |@...............|
+00000030  00 00 00 00 40 00 38 00  01 00 40 00 05 00 04 00  |....@.8...@.....|
+00000040  01 00 00 00 07 00 00 00  80 00 00 00 00 00 00 00  |................|
+00000050  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000060  a8 00 00 00 00 00 00 00  a8 00 00 00 00 00 00 00  |................|
+00000070  80 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000080  eb 7e 66 0f 1f 44 00 00  00 00 00 00 00 00 00 00  |.~f..D..........|
+00000090  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+000000a0  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+000000b0  66 66 2e 0f 1f 84 00 00  00 00 00 66 66 2e 0f 1f  |ff.........ff...|
+000000c0  84 00 00 00 00 00 66 66  2e 0f 1f 84 00 00 00 00  |......ff........|
+000000d0  00 66 66 2e 0f 1f 84 00  00 00 00 00 66 66 2e 0f  |.ff.........ff..|
+000000e0  1f 84 00 00 00 00 00 66  66 2e 0f 1f 84 00 00 00  |.......ff.......|
+000000f0  00 00 66 66 2e 0f 1f 84  00 00 00 00 00 0f 1f 00  |..ff............|
+00000100  48 8b 35 91 ff ff ff 48  8b 3d 92 ff ff ff 48 8b  |H.5....H.=....H.|
+00000110  0d 93 ff ff ff fc f3 a4  48 8b 05 69 ff ff ff 48  |........H..i...H|
+00000120  8b 35 6a ff ff ff ff e0  00 00 00 00 00 00 00 00  |.5j.............|
+00000130  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000140  01 00 00 00 04 00 f1 ff  00 00 00 00 00 00 00 00  |................|
+00000150  00 00 00 00 00 00 00 00  09 00 00 00 00 00 01 00  |................|
+00000160  08 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000170  0f 00 00 00 00 00 01 00  10 00 00 00 00 00 00 00  |................|
+00000180  00 00 00 00 00 00 00 00  16 00 00 00 00 00 01 00  |................|
+00000190  18 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+000001a0  21 00 00 00 00 00 01 00  20 00 00 00 00 00 00 00  |!....... .......|
+000001b0  00 00 00 00 00 00 00 00  2d 00 00 00 00 00 01 00  |........-.......|
+000001c0  28 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |(...............|
+000001d0  39 00 00 00 10 00 00 00  00 00 00 00 00 00 00 00  |9...............|
+000001e0  00 00 00 00 00 00 00 00  42 00 00 00 10 00 01 00  |........B.......|
+000001f0  a8 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000200  4e 00 00 00 10 00 01 00  a8 00 00 00 00 00 00 00  |N...............|
+00000210  00 00 00 00 00 00 00 00  55 00 00 00 10 00 01 00  |........U.......|
+00000220  a8 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000230  00 63 72 61 73 68 2e 6f  00 65 6e 74 72 79 00 70  |.crash.o.entry.p|
+00000240  61 72 61 6d 73 00 62 61  63 6b 75 70 5f 73 72 63  |arams.backup_src|
+00000250  00 62 61 63 6b 75 70 5f  64 65 73 74 00 62 61 63  |.backup_dest.bac|
+00000260  6b 75 70 5f 73 69 7a 65  00 20 65 6e 74 72 79 36  |kup_size. entry6|
+00000270  34 00 5f 5f 62 73 73 5f  73 74 61 72 74 00 5f 65  |4.__bss_start._e|
+00000280  64 61 74 61 00 5f 65 6e  64 00 00 2e 73 79 6d 74  |data._end...symt|
+00000290  61 62 00 2e 73 74 72 74  61 62 00 2e 73 68 73 74  |ab..strtab..shst|
+000002a0  72 74 61 62 00 2e 74 65  78 74 00 00 00 00 00 00  |rtab..text......|
+000002b0  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+000002c0  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+000002d0  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+000002e0  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+000002f0  1b 00 00 00 01 00 00 00  07 00 00 00 00 00 00 00  |................|
+00000300  00 00 00 00 00 00 00 00  80 00 00 00 00 00 00 00  |................|
+00000310  a8 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000320  80 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000330  01 00 00 00 02 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000340  00 00 00 00 00 00 00 00  28 01 00 00 00 00 00 00  |........(.......|
+00000350  08 01 00 00 00 00 00 00  03 00 00 00 07 00 00 00  |................|
+00000360  08 00 00 00 00 00 00 00  18 00 00 00 00 00 00 00  |................|
+00000370  09 00 00 00 03 00 00 00  00 00 00 00 00 00 00 00  |................|
+00000380  00 00 00 00 00 00 00 00  30 02 00 00 00 00 00 00  |........0.......|
+00000390  5a 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |Z...............|
+000003a0  01 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+000003b0  11 00 00 00 03 00 00 00  00 00 00 00 00 00 00 00  |................|
+000003c0  00 00 00 00 00 00 00 00  8a 02 00 00 00 00 00 00  |................|
+000003d0  21 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |!...............|
+000003e0  01 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|
+
+`,
+		Code: []byte{
+			0x7f, 0x45, 0x4c, 0x46, 0x02, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x02, 0x00, 0x3e, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x40, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xb0, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x40, 0x00, 0x38, 0x00, 0x01, 0x00, 0x40, 0x00, 0x05, 0x00, 0x04, 0x00,
+			0x01, 0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xa8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xa8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xeb, 0x7e, 0x66, 0x0f, 0x1f, 0x44, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x66, 0x66, 0x2e, 0x0f, 0x1f, 0x84, 0x00, 0x00, 0x00, 0x00, 0x00, 0x66, 0x66, 0x2e, 0x0f, 0x1f,
+			0x84, 0x00, 0x00, 0x00, 0x00, 0x00, 0x66, 0x66, 0x2e, 0x0f, 0x1f, 0x84, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x66, 0x66, 0x2e, 0x0f, 0x1f, 0x84, 0x00, 0x00, 0x00, 0x00, 0x00, 0x66, 0x66, 0x2e, 0x0f,
+			0x1f, 0x84, 0x00, 0x00, 0x00, 0x00, 0x00, 0x66, 0x66, 0x2e, 0x0f, 0x1f, 0x84, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x66, 0x66, 0x2e, 0x0f, 0x1f, 0x84, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0f, 0x1f, 0x00,
+			0x48, 0x8b, 0x35, 0x91, 0xff, 0xff, 0xff, 0x48, 0x8b, 0x3d, 0x92, 0xff, 0xff, 0xff, 0x48, 0x8b,
+			0x0d, 0x93, 0xff, 0xff, 0xff, 0xfc, 0xf3, 0xa4, 0x48, 0x8b, 0x05, 0x69, 0xff, 0xff, 0xff, 0x48,
+			0x8b, 0x35, 0x6a, 0xff, 0xff, 0xff, 0xff, 0xe0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x01, 0x00, 0x00, 0x00, 0x04, 0x00, 0xf1, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x09, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00,
+			0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x0f, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x16, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00,
+			0x18, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x21, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2d, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00,
+			0x28, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x39, 0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x42, 0x00, 0x00, 0x00, 0x10, 0x00, 0x01, 0x00,
+			0xa8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x4e, 0x00, 0x00, 0x00, 0x10, 0x00, 0x01, 0x00, 0xa8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x55, 0x00, 0x00, 0x00, 0x10, 0x00, 0x01, 0x00,
+			0xa8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x63, 0x72, 0x61, 0x73, 0x68, 0x2e, 0x6f, 0x00, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x00, 0x70,
+			0x61, 0x72, 0x61, 0x6d, 0x73, 0x00, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x5f, 0x73, 0x72, 0x63,
+			0x00, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x5f, 0x64, 0x65, 0x73, 0x74, 0x00, 0x62, 0x61, 0x63,
+			0x6b, 0x75, 0x70, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x00, 0x20, 0x65, 0x6e, 0x74, 0x72, 0x79, 0x36,
+			0x34, 0x00, 0x5f, 0x5f, 0x62, 0x73, 0x73, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x00, 0x5f, 0x65,
+			0x64, 0x61, 0x74, 0x61, 0x00, 0x5f, 0x65, 0x6e, 0x64, 0x00, 0x00, 0x2e, 0x73, 0x79, 0x6d, 0x74,
+			0x61, 0x62, 0x00, 0x2e, 0x73, 0x74, 0x72, 0x74, 0x61, 0x62, 0x00, 0x2e, 0x73, 0x68, 0x73, 0x74,
+			0x72, 0x74, 0x61, 0x62, 0x00, 0x2e, 0x74, 0x65, 0x78, 0x74, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x1b, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xa8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x80, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x28, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x08, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x00,
+			0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x18, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x09, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x30, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x5a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x11, 0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x8a, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x21, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		},
+	},
 	"loop_3000": {
 		Name: "loop_3000",
 		Hexdump: `00000000  7f 45 4c 46 02 01 01 00  00 00 00 00 00 00 00 00  |.ELF............|
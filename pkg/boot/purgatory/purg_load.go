@@ -66,25 +66,10 @@ func Select(name string) error {
 // Load loads the selected purgatory into kmem, instructing it to jump to entry
 // with RSI set to rsi.
 func Load(kmem *kexec.Memory, entry, rsi uintptr) (uintptr, error) {
-	elfFile, err := elf.NewFile(bytes.NewReader(curPurgatory.Code))
+	b, p, elfEntry, err := code(curPurgatory)
 	if err != nil {
-		return 0, fmt.Errorf("parse purgatory ELF file from ELF buffer: %w", err)
-	}
-
-	log.Printf("Elf file: %#v, %d Progs", elfFile, len(elfFile.Progs))
-	if len(elfFile.Progs) != 1 {
-		return 0, fmt.Errorf("parse purgatory ELF file: can only handle one Prog, not %d", len(elfFile.Progs))
-	}
-	p := elfFile.Progs[0]
-
-	// the package really wants things page-sized, and rather than
-	// deal with all the bugs that arise from that, just keep it happy.
-	p.Memsz = uint64(align.UpPage(uint(p.Memsz)))
-	b := make([]byte, p.Memsz)
-	if _, err := p.ReadAt(b[:p.Filesz], 0); err != nil {
 		return 0, err
 	}
-	elfEntry := uintptr(elfFile.Entry)
 
 	// Debug("Start is %#x, param is %#x", start, param)
 	binary.LittleEndian.PutUint64(b[8:], uint64(entry))
@@ -101,3 +86,52 @@ func Load(kmem *kexec.Memory, entry, rsi uintptr) (uintptr, error) {
 	kmem.Segments.Insert(kexec.NewSegment(b, phyRange))
 	return elfEntry, nil
 }
+
+// LoadCrash loads the crash purgatory anywhere in kmem, instructing it to copy
+// backup to backupDest and jump to entry with RSI set to rsi.
+//
+// Crash kernels have to use this purgatory: kexec_load refuses crash kernel
+// segments outside the memory reserved for them, and the other purgatories
+// only run at the address they were linked for.
+func LoadCrash(kmem *kexec.Memory, entry, rsi uintptr, backup kexec.Range, backupDest uintptr) (uintptr, error) {
+	b, p, elfEntry, err := code(Purgatories["crash"])
+	if err != nil {
+		return 0, err
+	}
+
+	binary.LittleEndian.PutUint64(b[8:], uint64(entry))
+	binary.LittleEndian.PutUint64(b[16:], uint64(rsi))
+	binary.LittleEndian.PutUint64(b[24:], uint64(backup.Start))
+	binary.LittleEndian.PutUint64(b[32:], uint64(backupDest))
+	binary.LittleEndian.PutUint64(b[40:], uint64(backup.Size))
+
+	phyRange, err := kmem.AddKexecSegment(b)
+	if err != nil {
+		return 0, fmt.Errorf("purgatory: add segment of size %d: %w", len(b), err)
+	}
+	return phyRange.Start + elfEntry - uintptr(p.Vaddr), nil
+}
+
+// code returns the page-aligned loadable code of purgatory pg, its program
+// header and its entry point.
+func code(pg *Purgatory) ([]byte, *elf.Prog, uintptr, error) {
+	elfFile, err := elf.NewFile(bytes.NewReader(pg.Code))
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("parse purgatory ELF file from ELF buffer: %w", err)
+	}
+
+	log.Printf("Elf file: %#v, %d Progs", elfFile, len(elfFile.Progs))
+	if len(elfFile.Progs) != 1 {
+		return nil, nil, 0, fmt.Errorf("parse purgatory ELF file: can only handle one Prog, not %d", len(elfFile.Progs))
+	}
+	p := elfFile.Progs[0]
+
+	// the package really wants things page-sized, and rather than
+	// deal with all the bugs that arise from that, just keep it happy.
+	p.Memsz = uint64(align.UpPage(uint(p.Memsz)))
+	b := make([]byte, p.Memsz)
+	if _, err := p.ReadAt(b[:p.Filesz], 0); err != nil {
+		return nil, nil, 0, err
+	}
+	return b, p, uintptr(elfFile.Entry), nil
+}
@@ -296,6 +296,38 @@ movl %eax, %esp
 entry32_regs: .long 0
 .globl cmdline_end
 cmdline_end: .long 0
+`,
+	},
+	{
+		// crash is position independent, so that it can run from the
+		// memory reserved for a crash kernel. Before jumping to the
+		// crash kernel, it saves the memory the crash kernel reuses
+		// like kexec-tools' purgatory does.
+		name: "crash",
+		cc:   []string{"x86_64-linux-gnu-gcc", "-c", "-nostdlib", "-nostdinc", "-static"},
+		ld:   []string{"ld", "-N", "-e entry64", "-Ttext=0"},
+		code: `
+jmp 1f
+.align 8
+// Known to Go.
+entry: .quad 0
+params: .quad 0
+backup_src: .quad 0
+backup_dest: .quad 0
+backup_size: .quad 0
+// end Known to Go
+.align 128
+1:
+	/* Save the memory the crash kernel is about to reuse, which
+	 * /proc/vmcore reads from backup_dest instead. */
+	movq	backup_src(%rip), %rsi
+	movq	backup_dest(%rip), %rdi
+	movq	backup_size(%rip), %rcx
+	cld
+	rep movsb
+	movq	entry(%rip), %rax
+	movq	params(%rip), %rsi
+	jmp	*%rax
 `,
 	},
 	{
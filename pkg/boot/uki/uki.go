@@ -0,0 +1,189 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package uki parses Unified Kernel Images.
+//
+// A UKI is a PE binary combining the EFI stub of systemd with a Linux kernel
+// and the files needed to boot it, each in its own section. See
+// https://uapi-group.org/specifications/specs/unified_kernel_image/.
+package uki
+
+import (
+	"bufio"
+	"bytes"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/u-root/u-root/pkg/boot"
+)
+
+// PE sections of a UKI.
+const (
+	sectionLinux   = ".linux"
+	sectionInitrd  = ".initrd"
+	sectionUcode   = ".ucode"
+	sectionCmdline = ".cmdline"
+	sectionOSRel   = ".osrel"
+	sectionDTB     = ".dtb"
+	sectionUname   = ".uname"
+)
+
+// ErrNoKernel is returned for PE binaries without a .linux section, i.e.
+// those that are not UKIs.
+var ErrNoKernel = errors.New("PE binary has no .linux section")
+
+// Image is a parsed Unified Kernel Image. The readers refer to the sections
+// of the underlying UKI.
+type Image struct {
+	Kernel io.ReaderAt
+	// Initrd is the initramfs, with the microcode of the .ucode section
+	// prepended if there is one. It is nil if the UKI has neither.
+	Initrd io.ReaderAt
+	// DTB is nil if the UKI has no device tree.
+	DTB     io.ReaderAt
+	Cmdline string
+	// OSRelease holds the os-release(5) fields of the .osrel section.
+	OSRelease map[string]string
+	// Uname is the kernel release, as printed by uname -r.
+	Uname string
+}
+
+// Parse parses the UKI r.
+func Parse(r io.ReaderAt) (*Image, error) {
+	f, err := pe.NewFile(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a PE binary: %w", err)
+	}
+
+	sections := map[string]*io.SectionReader{}
+	for _, s := range f.Sections {
+		// The raw size is rounded up to the file alignment; the
+		// virtual size is the size of the contents.
+		size := s.Size
+		if s.VirtualSize != 0 && s.VirtualSize < size {
+			size = s.VirtualSize
+		}
+		sections[s.Name] = io.NewSectionReader(r, int64(s.Offset), int64(size))
+	}
+
+	linux, ok := sections[sectionLinux]
+	if !ok {
+		return nil, ErrNoKernel
+	}
+	img := &Image{Kernel: linux}
+
+	if initrd, ok := sections[sectionInitrd]; ok {
+		img.Initrd = initrd
+	}
+	if ucode, ok := sections[sectionUcode]; ok {
+		if img.Initrd != nil {
+			img.Initrd = boot.CatInitrds(ucode, img.Initrd)
+		} else {
+			img.Initrd = ucode
+		}
+	}
+	if dtb, ok := sections[sectionDTB]; ok {
+		img.DTB = dtb
+	}
+	if img.Cmdline, err = readString(sections[sectionCmdline]); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", sectionCmdline, err)
+	}
+	if img.Uname, err = readString(sections[sectionUname]); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", sectionUname, err)
+	}
+	osrel, err := readString(sections[sectionOSRel])
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", sectionOSRel, err)
+	}
+	img.OSRelease = ParseOSRelease(osrel)
+	return img, nil
+}
+
+// readString reads a text section, which may be NUL-terminated. A missing
+// section reads as an empty string.
+func readString(s *io.SectionReader) (string, error) {
+	if s == nil {
+		return "", nil
+	}
+	b, err := io.ReadAll(s)
+	if err != nil {
+		return "", err
+	}
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// ParseOSRelease parses the contents of an os-release(5) file.
+func ParseOSRelease(s string) map[string]string {
+	vals := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if uq, err := strconv.Unquote(val); err == nil {
+			val = uq
+		} else if len(val) >= 2 && val[0] == '\'' && val[len(val)-1] == '\'' {
+			val = val[1 : len(val)-1]
+		}
+		vals[key] = val
+	}
+	return vals
+}
+
+// Name describes the image the way systemd-boot does: by the pretty name of
+// the OS and its version, or failing that, the kernel release.
+func (i *Image) Name() string {
+	var name []string
+	for _, key := range []string{"PRETTY_NAME", "NAME", "ID"} {
+		if v := i.OSRelease[key]; v != "" {
+			name = append(name, v)
+			break
+		}
+	}
+	// PRETTY_NAME usually includes VERSION_ID already.
+	version := i.OSRelease["VERSION_ID"]
+	if len(name) > 0 && strings.Contains(name[0], version) {
+		version = ""
+	}
+	for _, v := range []string{i.Uname, i.OSRelease["IMAGE_VERSION"], version} {
+		if v != "" {
+			name = append(name, v)
+			break
+		}
+	}
+	return strings.Join(name, " ")
+}
+
+// LinuxImage returns a LinuxImage that boots the kernel of the UKI with its
+// initramfs, device tree and command line.
+func (i *Image) LinuxImage() *boot.LinuxImage {
+	return &boot.LinuxImage{
+		Name:    i.Name(),
+		Kernel:  i.Kernel,
+		Initrd:  i.Initrd,
+		DTB:     i.DTB,
+		Cmdline: i.Cmdline,
+	}
+}
+
+// New parses the UKI r and returns a LinuxImage for it.
+func New(r io.ReaderAt) (*boot.LinuxImage, error) {
+	img, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return img.LinuxImage(), nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package uki
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type section struct {
+	name string
+	data string
+}
+
+// buildPE returns a PE binary with the given sections. Their raw data is
+// padded to the file alignment as linkers do.
+func buildPE(t *testing.T, sections ...section) []byte {
+	t.Helper()
+	const (
+		peOffset  = 0x40
+		alignment = 0x200
+	)
+	var b bytes.Buffer
+	dos := make([]byte, peOffset)
+	copy(dos, "MZ")
+	binary.LittleEndian.PutUint32(dos[0x3c:], peOffset)
+	b.Write(dos)
+	b.WriteString("PE\x00\x00")
+	binary.Write(&b, binary.LittleEndian, pe.FileHeader{
+		Machine:          pe.IMAGE_FILE_MACHINE_AMD64,
+		NumberOfSections: uint16(len(sections)),
+	})
+
+	offset := uint32(alignment)
+	var data []byte
+	for _, s := range sections {
+		size := (uint32(len(s.data)) + alignment - 1) / alignment * alignment
+		h := pe.SectionHeader32{
+			VirtualSize:      uint32(len(s.data)),
+			VirtualAddress:   offset,
+			SizeOfRawData:    size,
+			PointerToRawData: offset,
+		}
+		copy(h.Name[:], s.name)
+		binary.Write(&b, binary.LittleEndian, h)
+
+		padded := make([]byte, size)
+		copy(padded, s.data)
+		data = append(data, padded...)
+		offset += size
+	}
+	if b.Len() > alignment {
+		t.Fatalf("too many sections")
+	}
+	b.Write(make([]byte, alignment-b.Len()))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func readAll(t *testing.T, r io.ReaderAt) string {
+	t.Helper()
+	if r == nil {
+		return ""
+	}
+	b, err := io.ReadAll(io.NewSectionReader(r, 0, 1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+const fedoraOSRel = `NAME="Fedora Linux"
+VERSION="40 (Forty)"
+ID=fedora
+VERSION_ID=40
+PRETTY_NAME="Fedora Linux 40 (Forty)"
+# comment
+ANSI_COLOR='0;38;2;60;110;180'
+`
+
+func TestParse(t *testing.T) {
+	uki := buildPE(t,
+		section{".text", "stub"},
+		section{".osrel", fedoraOSRel},
+		section{".cmdline", "root=/dev/sda1 quiet\n\x00"},
+		section{".uname", "6.9.7-200.fc40.x86_64"},
+		section{".ucode", "microcode"},
+		section{".initrd", "initramfs"},
+		section{".linux", "bzImage"},
+	)
+	img, err := Parse(bytes.NewReader(uki))
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+	if got := readAll(t, img.Kernel); got != "bzImage" {
+		t.Errorf("Kernel = %q, want bzImage", got)
+	}
+	// CatInitrds pads the microcode to 512 bytes.
+	if got := readAll(t, img.Initrd); got != "microcode"+strings.Repeat("\x00", 512-9)+"initramfs" {
+		t.Errorf("Initrd = %q, want the microcode followed by the initramfs", got)
+	}
+	if img.DTB != nil {
+		t.Errorf("DTB = %v, want nil", img.DTB)
+	}
+	if img.Cmdline != "root=/dev/sda1 quiet" {
+		t.Errorf("Cmdline = %q, want %q", img.Cmdline, "root=/dev/sda1 quiet")
+	}
+	if img.OSRelease["ANSI_COLOR"] != "0;38;2;60;110;180" || img.OSRelease["ID"] != "fedora" {
+		t.Errorf("OSRelease = %v", img.OSRelease)
+	}
+
+	li := img.LinuxImage()
+	if want := "Fedora Linux 40 (Forty) 6.9.7-200.fc40.x86_64"; li.Label() != want {
+		t.Errorf("Label() = %q, want %q", li.Label(), want)
+	}
+	if li.Cmdline != img.Cmdline {
+		t.Errorf("Cmdline = %q, want %q", li.Cmdline, img.Cmdline)
+	}
+}
+
+func TestParseMinimal(t *testing.T) {
+	img, err := Parse(bytes.NewReader(buildPE(t,
+		section{".linux", "Image"},
+		section{".dtb", "fdt"},
+	)))
+	if err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+	if img.Initrd != nil || img.Cmdline != "" || len(img.OSRelease) != 0 {
+		t.Errorf("Parse() = %+v, want no initrd, command line or os-release", img)
+	}
+	if got := readAll(t, img.DTB); got != "fdt" {
+		t.Errorf("DTB = %q, want fdt", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(bytes.NewReader(buildPE(t, section{".text", "efi app"}))); !errors.Is(err, ErrNoKernel) {
+		t.Errorf("Parse(EFI application) = %v, want %v", err, ErrNoKernel)
+	}
+	if _, err := Parse(bytes.NewReader([]byte("#!/bin/sh\n"))); err == nil {
+		t.Errorf("Parse(script) = nil, want error")
+	}
+}
+
+func TestName(t *testing.T) {
+	for _, tt := range []struct {
+		osrel string
+		uname string
+		want  string
+	}{
+		{osrel: fedoraOSRel, want: "Fedora Linux 40 (Forty)"},
+		{osrel: "NAME=Arch\nID=arch\nIMAGE_VERSION=2026.10.01", want: "Arch 2026.10.01"},
+		{osrel: "ID=debian\nVERSION_ID=13", want: "debian 13"},
+		{uname: "6.10.0", want: "6.10.0"},
+	} {
+		img := &Image{OSRelease: ParseOSRelease(tt.osrel), Uname: tt.uname}
+		if got := img.Name(); got != tt.want {
+			t.Errorf("Name() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	li, err := New(bytes.NewReader(buildPE(t,
+		section{".linux", "bzImage"},
+		section{".osrel", `PRETTY_NAME="Arch Linux"`},
+	)))
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if li.Name != "Arch Linux" || readAll(t, li.Kernel) != "bzImage" {
+		t.Errorf("New() = %v", li)
+	}
+}
@@ -119,6 +119,29 @@ var (
 	warningMsg []error
 )
 
+var (
+	// smbiosBuilder holds the SMBIOS tables set by UseSMBIOS.
+	smbiosBuilder *smbios.Builder
+	// smbiosBuiltBase is the address smbiosBuilder's entry point was placed
+	// at, or 0 if the firmware's tables are used.
+	smbiosBuiltBase uint64
+)
+
+// UseSMBIOS makes Load hand the SMBIOS tables built by b to the payload
+// instead of the firmware's tables. A nil b restores the default.
+func UseSMBIOS(b *smbios.Builder) {
+	smbiosBuilder = b
+}
+
+// smbiosEntryPoint returns the base and size of the SMBIOS entry point that
+// is handed to the payload.
+func smbiosEntryPoint() (int64, int64, error) {
+	if smbiosBuiltBase != 0 {
+		return int64(smbiosBuiltBase), getSMBIOS3HdrSize(), nil
+	}
+	return getSMBIOSBase()
+}
+
 // Create GUID HOB with specified GUID string
 func constructGUIDHOB(name string) (*EFIHOBGUIDType, error) {
 	length := uint16(unsafe.Sizeof(EFIHOBGUIDType{}) + guidToLength[name])
@@ -164,7 +187,7 @@ func constructUniversalPayloadBase(addr uint64) *UniversalPayloadBase {
 
 // Construct UniversalPayloadSmbiosTable HOB
 func constructSmbiosTable() (*UniversalPayloadSmbiosTable, error) {
-	smbiosTableBase, _, err := getSMBIOSBase()
+	smbiosTableBase, _, err := smbiosEntryPoint()
 	if err != nil {
 		return nil, errors.Join(ErrFailToGetSmbiosTable, err)
 	}
@@ -427,8 +450,17 @@ func prepareBootloaderParameter(fdtLoad *FdtLoad, loadAddr uint64, mem *kexec.Me
 		rsdpBase = loadAddr + rsdpTableOffset
 	}
 
+	// Next step, SMBIOS tables will be placed if they are provided
+	smbiosTableOffset = rsdpTableOffset + uint64(align.UpPage(uint64(len(rsdpData))))
+
+	smbiosLen, err := prepareSmbiosData(loadAddr, mem)
+	if err != nil {
+		debug("universalpayload: failed to place SMBIOS tables (%v)\n", err)
+		return err
+	}
+
 	// Next step, Handoff Blocks will be placed
-	tmpHobOffset = rsdpTableOffset + uint64(align.UpPage(uint64(len(rsdpData))))
+	tmpHobOffset = smbiosTableOffset + uint64(align.UpPage(smbiosLen))
 
 	hobBuf := &bytes.Buffer{}
 	hobListBuf := &bytes.Buffer{}
@@ -482,6 +514,34 @@ func prepareBootloaderParameter(fdtLoad *FdtLoad, loadAddr uint64, mem *kexec.Me
 	return nil
 }
 
+// prepareSmbiosData places the tables set by UseSMBIOS at smbiosTableOffset
+// and returns their size.
+func prepareSmbiosData(loadAddr uint64, mem *kexec.Memory) (uint64, error) {
+	smbiosBuiltBase = 0
+	if smbiosBuilder == nil {
+		return 0, nil
+	}
+
+	base := loadAddr + smbiosTableOffset
+	img, err := smbiosBuilder.Image(base)
+	if err != nil {
+		return 0, err
+	}
+
+	// Check whether reserved components size is overflowed.
+	if err := checkComponentsSize(align.UpPage(uint(len(img)))); err != nil {
+		return 0, err
+	}
+	s := kexec.NewSegment(img, kexec.Range{
+		Start: uintptr(base),
+		Size:  uint(len(img)),
+	})
+	mem.Segments.Insert(s)
+
+	smbiosBuiltBase = base
+	return uint64(len(img)), nil
+}
+
 func prepareFdtData(fdt *FdtLoad, data []byte, addr uint64, mem *kexec.Memory) error {
 	if err := relocateFdtData(addr+uplImageOffset, fdt, data); err != nil {
 		debug("universalpayload: failed to relocate FIT image (%v)\n", err)
@@ -522,6 +582,8 @@ func loadKexecMemWithHOBs(fdt *FdtLoad, data []byte, mem *kexec.Memory) (uintptr
 	//  |------------------------|
 	//  |  BOOTLOADER PARAMETER  |
 	//  |------------------------|
+	//  |      SMBIOS DATA       |
+	//  |------------------------|
 	//  |       ACPI DATA        |
 	//  |------------------------|
 	//  |       FIT IMAGE        |
@@ -18,6 +18,7 @@ import (
 	"github.com/u-root/u-root/pkg/align"
 	"github.com/u-root/u-root/pkg/boot/kexec"
 	"github.com/u-root/u-root/pkg/efivarfs"
+	"github.com/u-root/u-root/pkg/smbios"
 )
 
 func mockKexecMemoryMapFromIOMem() (kexec.MemoryMap, error) {
@@ -504,3 +505,53 @@ func expectErr(t *testing.T, err error, expectedErr error) {
 		}
 	}
 }
+
+func TestPrepareSmbiosData(t *testing.T) {
+	defer func(old func() (int64, int64, error)) { getSMBIOSBase = old }(getSMBIOSBase)
+	getSMBIOSBase = mockGetSMBIOSBase
+	defer UseSMBIOS(nil)
+	defer func(old uint64) { smbiosTableOffset = old }(smbiosTableOffset)
+	smbiosTableOffset = 0x3000
+
+	b, err := smbios.NewBuilder(3, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Add(&smbios.SystemInfo{Manufacturer: "u-root"}); err != nil {
+		t.Fatal(err)
+	}
+	UseSMBIOS(b)
+
+	const loadAddr = 0x200000
+	var mem kexec.Memory
+	n, err := prepareSmbiosData(loadAddr, &mem)
+	if err != nil {
+		t.Fatalf("prepareSmbiosData() = %v", err)
+	}
+	if len(mem.Segments) != 1 || mem.Segments[0].Phys.Start != loadAddr+0x3000 || uint64(mem.Segments[0].Phys.Size) != n {
+		t.Fatalf("prepareSmbiosData() placed segments %v, want one at %#x of size %#x", mem.Segments, loadAddr+0x3000, n)
+	}
+	_, e64, err := smbios.ParseEntry(mem.Segments[0].Buf)
+	if err != nil || e64 == nil {
+		t.Fatalf("ParseEntry() = %v, %v, want a 64-bit entry point", e64, err)
+	}
+
+	smbiosTable, err := constructSmbiosTable()
+	if err != nil {
+		t.Fatalf("constructSmbiosTable() = %v", err)
+	}
+	if smbiosTable.SmBiosEntryPoint != loadAddr+0x3000 {
+		t.Errorf("SmBiosEntryPoint = %#x, want %#x", smbiosTable.SmBiosEntryPoint, loadAddr+0x3000)
+	}
+
+	// Without a builder, the firmware's tables are used.
+	UseSMBIOS(nil)
+	mem = kexec.Memory{}
+	if n, err := prepareSmbiosData(loadAddr, &mem); err != nil || n != 0 || len(mem.Segments) != 0 {
+		t.Fatalf("prepareSmbiosData() = %d, %v, %v, want nothing placed", n, err, mem.Segments)
+	}
+	base, _, _ := mockGetSMBIOSBase()
+	if got, _, _ := smbiosEntryPoint(); got != base {
+		t.Errorf("smbiosEntryPoint() = %#x, want %#x", got, base)
+	}
+}
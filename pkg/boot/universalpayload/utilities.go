@@ -44,7 +44,7 @@ const (
 //   TRAMPOLINE CODE depends on base address of:
 //     TEMP STACK, Device Tree Info, ACPI DATA, UPL FIT IMAGE
 //   Device Tree Info depends on base address of:
-//     HoBs, ACPI DATA, UPL FIT IMAGE
+//     HoBs, SMBIOS DATA, ACPI DATA, UPL FIT IMAGE
 //
 // |------------------------| <-- Memory Region top
 // |     TRAMPOLINE CODE    |
@@ -56,6 +56,8 @@ const (
 // |  BOOTLOADER PARAMETER  |
 // |  HoBs (Handoff Blocks) |
 // |------------------------| <-- loadAddr + tmpHobOffset
+// |      SMBIOS DATA       |
+// |------------------------| <-- loadAddr + smbiosTableOffset
 // |       ACPI DATA        |
 // |------------------------| <-- loadAddr + rsdpTableOffset
 // |     UPL FIT IMAGE      |
@@ -64,6 +66,8 @@ const (
 // During runtime, we need to find a available Memory Region to place all
 // above components, size of each components should be updated at runtime.
 //
+// SMBIOS DATA is only present if tables were provided with UseSMBIOS.
+//
 // uplImageOffset is always set to be Zero. We keep it here in case
 // anything more needs to be placed before UPL Image.
 // Components should be placed by above sequence, once component is placed,
@@ -71,12 +75,13 @@ const (
 // information are updated correctly.
 
 var (
-	uplImageOffset   uint64
-	rsdpTableOffset  uint64
-	tmpHobOffset     uint64
-	fdtDtbOffset     uint64
-	tmpStackOffset   uint64
-	trampolineOffset uint64
+	uplImageOffset    uint64
+	rsdpTableOffset   uint64
+	smbiosTableOffset uint64
+	tmpHobOffset      uint64
+	fdtDtbOffset      uint64
+	tmpStackOffset    uint64
+	trampolineOffset  uint64
 )
 
 // componentsSize is used to check whether reversed size, which is defined in
@@ -744,7 +749,7 @@ func constructOptionNode(loadAddr uint64) (*dt.Node, error) {
 }
 
 func constructSMBIOS3Node() (*dt.Node, error) {
-	smbiosTableBase, size, err := getSMBIOSBase()
+	smbiosTableBase, size, err := smbiosEntryPoint()
 
 	// According to EDK2 UPL implementation, only SMBIOS3 is supported in FDT.
 	if (err != nil) || (size != getSMBIOS3HdrSize()) {
@@ -0,0 +1,464 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package verify
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/u-root/u-root/pkg/boot"
+)
+
+// Authenticode verifies Authenticode signatures of PE images, such as EFI
+// stub kernels signed with sbsign or pesign.
+//
+// Only kernels are PE images; other artifacts fail with ErrNotSigned.
+type Authenticode struct {
+	// Roots are the certificates the signer must chain to. Like UEFI
+	// Secure Boot, certificate validity periods are not checked.
+	Roots *x509.CertPool
+}
+
+var (
+	oidSignedData        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentType       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigest     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidSpcIndirectData   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 4}
+	oidSHA256            = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384            = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512            = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	errNotPE             = fmt.Errorf("%w: not a PE image", ErrNotSigned)
+	errNoCertificates    = fmt.Errorf("%w: PE image has no certificate table", ErrNotSigned)
+	errMalformedPE       = errors.New("malformed PE image")
+	errUnsupportedDigest = errors.New("unsupported digest algorithm")
+)
+
+// contentInfo is a PKCS #7 ContentInfo. Content includes the explicit tag.
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+// signedData is a PKCS #7 SignedData.
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      contentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type issuerAndSerial struct {
+	Issuer asn1.RawValue
+	Serial *big.Int
+}
+
+type signerInfo struct {
+	Version                   int
+	IssuerAndSerial           issuerAndSerial
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+	UnauthenticatedAttributes asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue `asn1:"set"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+// spcIndirectDataContent is the signed content of an Authenticode signature.
+type spcIndirectDataContent struct {
+	Data          asn1.RawValue
+	MessageDigest digestInfo
+}
+
+func hashFor(alg pkix.AlgorithmIdentifier) (crypto.Hash, error) {
+	switch {
+	case alg.Algorithm.Equal(oidSHA256):
+		return crypto.SHA256, nil
+	case alg.Algorithm.Equal(oidSHA384):
+		return crypto.SHA384, nil
+	case alg.Algorithm.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("%w %v", errUnsupportedDigest, alg.Algorithm)
+}
+
+// peImage is the layout of a PE image relevant to Authenticode.
+type peImage struct {
+	// checksum, certDir and certTable are the file offsets excluded from
+	// the image hash.
+	checksum  int64
+	certDir   int64
+	certTable int64
+	certSize  int64
+
+	headerSize int64
+	sections   []peSection
+}
+
+type peSection struct {
+	offset, size int64
+}
+
+// parsePE finds the parts of a PE image relevant to Authenticode.
+func parsePE(r io.ReaderAt) (*peImage, error) {
+	var dos [64]byte
+	if _, err := r.ReadAt(dos[:], 0); err != nil || !bytes.Equal(dos[:2], []byte("MZ")) {
+		return nil, errNotPE
+	}
+	peOff := int64(binary.LittleEndian.Uint32(dos[0x3c:]))
+
+	var hdr [24]byte
+	if _, err := r.ReadAt(hdr[:], peOff); err != nil || !bytes.Equal(hdr[:4], []byte("PE\x00\x00")) {
+		return nil, errNotPE
+	}
+	numSections := int(binary.LittleEndian.Uint16(hdr[6:]))
+	optSize := int64(binary.LittleEndian.Uint16(hdr[20:]))
+	optOff := peOff + int64(len(hdr))
+
+	opt := make([]byte, optSize)
+	if _, err := r.ReadAt(opt, optOff); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMalformedPE, err)
+	}
+	var numDirsOff, dirsOff int
+	switch magic := binary.LittleEndian.Uint16(opt); magic {
+	case 0x10b:
+		numDirsOff, dirsOff = 92, 96
+	case 0x20b:
+		numDirsOff, dirsOff = 108, 112
+	default:
+		return nil, fmt.Errorf("%w: unknown optional header magic %#x", errMalformedPE, magic)
+	}
+	const certDirIndex = 4
+	certDirOff := dirsOff + certDirIndex*8
+	if len(opt) < certDirOff+8 || binary.LittleEndian.Uint32(opt[numDirsOff:]) <= certDirIndex {
+		return nil, errNoCertificates
+	}
+
+	pe := &peImage{
+		checksum:   optOff + 64,
+		certDir:    optOff + int64(certDirOff),
+		certTable:  int64(binary.LittleEndian.Uint32(opt[certDirOff:])),
+		certSize:   int64(binary.LittleEndian.Uint32(opt[certDirOff+4:])),
+		headerSize: int64(binary.LittleEndian.Uint32(opt[60:])),
+	}
+	if pe.certTable == 0 || pe.certSize == 0 {
+		return nil, errNoCertificates
+	}
+	if pe.headerSize < pe.certDir+8 {
+		return nil, fmt.Errorf("%w: headers end at %#x, before the data directories", errMalformedPE, pe.headerSize)
+	}
+
+	const sectionSize = 40
+	sections := make([]byte, numSections*sectionSize)
+	if _, err := r.ReadAt(sections, optOff+optSize); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMalformedPE, err)
+	}
+	for i := range numSections {
+		s := sections[i*sectionSize:]
+		size := int64(binary.LittleEndian.Uint32(s[16:]))
+		if size == 0 {
+			continue
+		}
+		pe.sections = append(pe.sections, peSection{
+			offset: int64(binary.LittleEndian.Uint32(s[20:])),
+			size:   size,
+		})
+	}
+	sort.Slice(pe.sections, func(i, j int) bool {
+		return pe.sections[i].offset < pe.sections[j].offset
+	})
+	return pe, nil
+}
+
+// hashRange hashes the bytes of r in [start, end). An end of -1 hashes
+// until EOF.
+func hashRange(h io.Writer, r io.ReaderAt, start, end int64) error {
+	n := int64(math.MaxInt64 - start)
+	if end >= 0 {
+		n = end - start
+	}
+	if n <= 0 {
+		return nil
+	}
+	copied, err := io.Copy(h, io.NewSectionReader(r, start, n))
+	if err != nil {
+		return err
+	}
+	if end >= 0 && copied != n {
+		return fmt.Errorf("%w: image ends at %#x, before %#x", errMalformedPE, start+copied, end)
+	}
+	return nil
+}
+
+// digest computes the Authenticode hash of the image, which covers
+// everything but the checksum, the certificate table and its data directory
+// entry.
+func (pe *peImage) digest(r io.ReaderAt, hash crypto.Hash) ([]byte, error) {
+	h := hash.New()
+	for _, rng := range [][2]int64{
+		{0, pe.checksum},
+		{pe.checksum + 4, pe.certDir},
+		{pe.certDir + 8, pe.headerSize},
+	} {
+		if err := hashRange(h, r, rng[0], rng[1]); err != nil {
+			return nil, err
+		}
+	}
+	end := pe.headerSize
+	for _, s := range pe.sections {
+		if err := hashRange(h, r, s.offset, s.offset+s.size); err != nil {
+			return nil, err
+		}
+		end = max(end, s.offset+s.size)
+	}
+	// Data after the sections, such as debug information, is hashed too.
+	if err := hashRange(h, r, end, pe.certTable); err != nil {
+		return nil, err
+	}
+	if err := hashRange(h, r, max(end, pe.certTable+pe.certSize), -1); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// signatures returns the PKCS #7 signatures in the certificate table.
+func (pe *peImage) signatures(r io.ReaderAt) ([][]byte, error) {
+	const (
+		revision2          = 0x200
+		typePKCSSignedData = 0x2
+	)
+	table := make([]byte, pe.certSize)
+	if _, err := r.ReadAt(table, pe.certTable); err != nil {
+		return nil, fmt.Errorf("%w: reading certificate table: %v", errMalformedPE, err)
+	}
+	var sigs [][]byte
+	for len(table) >= 8 {
+		length := int(binary.LittleEndian.Uint32(table))
+		if length < 8 || length > len(table) {
+			return nil, fmt.Errorf("%w: invalid certificate length %d", errMalformedPE, length)
+		}
+		if binary.LittleEndian.Uint16(table[4:]) == revision2 && binary.LittleEndian.Uint16(table[6:]) == typePKCSSignedData {
+			sigs = append(sigs, table[8:length])
+		}
+		// Entries are 8-byte aligned.
+		table = table[min(len(table), (length+7)&^7):]
+	}
+	if len(sigs) == 0 {
+		return nil, errNoCertificates
+	}
+	return sigs, nil
+}
+
+// Verify implements boot.Verifier.
+func (a *Authenticode) Verify(kind boot.Artifact, r io.ReaderAt) error {
+	if kind != boot.ArtifactKernel {
+		return fmt.Errorf("%w: only kernels can carry Authenticode signatures", ErrNotSigned)
+	}
+	pe, err := parsePE(r)
+	if err != nil {
+		return err
+	}
+	sigs, err := pe.signatures(r)
+	if err != nil {
+		return err
+	}
+	digests := make(map[crypto.Hash][]byte)
+	var errs []error
+	for _, sig := range sigs {
+		err := a.verifySignature(sig, func(h crypto.Hash) ([]byte, error) {
+			if d, ok := digests[h]; ok {
+				return d, nil
+			}
+			d, err := pe.digest(r, h)
+			if err != nil {
+				return nil, err
+			}
+			digests[h] = d
+			return d, nil
+		})
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// verifySignature verifies the PKCS #7 SignedData sig over an image with the
+// given digest function.
+func (a *Authenticode) verifySignature(sig []byte, digest func(crypto.Hash) ([]byte, error)) error {
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(sig, &ci); err != nil {
+		return fmt.Errorf("parsing signature: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return fmt.Errorf("signature content type is %v, want SignedData", ci.ContentType)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return fmt.Errorf("parsing SignedData: %w", err)
+	}
+	if !sd.ContentInfo.ContentType.Equal(oidSpcIndirectData) {
+		return fmt.Errorf("signed content type is %v, want SpcIndirectDataContent", sd.ContentInfo.ContentType)
+	}
+	if len(sd.SignerInfos) != 1 {
+		return fmt.Errorf("got %d signers, want 1", len(sd.SignerInfos))
+	}
+	si := sd.SignerInfos[0]
+
+	// The image digest is in the signed content.
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(sd.ContentInfo.Content.Bytes, &raw); err != nil {
+		return fmt.Errorf("parsing signed content: %w", err)
+	}
+	var content spcIndirectDataContent
+	if _, err := asn1.Unmarshal(raw.FullBytes, &content); err != nil {
+		return fmt.Errorf("parsing SpcIndirectDataContent: %w", err)
+	}
+	imageHash, err := hashFor(content.MessageDigest.Algorithm)
+	if err != nil {
+		return err
+	}
+	d, err := digest(imageHash)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(d, content.MessageDigest.Digest) {
+		return errors.New("image digest does not match the signed digest")
+	}
+
+	// The content digest is in the authenticated attributes, over which
+	// the signature is made.
+	hash, err := hashFor(si.DigestAlgorithm)
+	if err != nil {
+		return err
+	}
+	if len(si.AuthenticatedAttributes.FullBytes) == 0 {
+		return errors.New("signer has no authenticated attributes")
+	}
+	attrs, err := parseAttributes(si.AuthenticatedAttributes.Bytes)
+	if err != nil {
+		return err
+	}
+	var contentType asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(attrs[oidContentType.String()], &contentType); err != nil || !contentType.Equal(oidSpcIndirectData) {
+		return errors.New("authenticated content type is not SpcIndirectDataContent")
+	}
+	var messageDigest []byte
+	if _, err := asn1.Unmarshal(attrs[oidMessageDigest.String()], &messageDigest); err != nil {
+		return errors.New("no message digest in authenticated attributes")
+	}
+	// Authenticode digests the content without its SEQUENCE header.
+	h := hash.New()
+	h.Write(raw.Bytes)
+	if !bytes.Equal(h.Sum(nil), messageDigest) {
+		return errors.New("content digest does not match the authenticated digest")
+	}
+
+	certs, err := x509.ParseCertificates(sd.Certificates.Bytes)
+	if err != nil {
+		return fmt.Errorf("parsing certificates: %w", err)
+	}
+	signer, intermediates, err := findSigner(certs, si.IssuerAndSerial)
+	if err != nil {
+		return err
+	}
+
+	// The signature is over the DER encoding of the attributes as a SET.
+	signed := append([]byte{0x31}, si.AuthenticatedAttributes.FullBytes[1:]...)
+	h = hash.New()
+	h.Write(signed)
+	if err := checkSignature(signer.PublicKey, hash, h.Sum(nil), si.EncryptedDigest); err != nil {
+		return err
+	}
+
+	if a.Roots == nil {
+		return fmt.Errorf("%w: no trusted certificates", ErrUntrusted)
+	}
+	if _, err := signer.Verify(x509.VerifyOptions{
+		Roots:         a.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   signer.NotBefore,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return fmt.Errorf("%w: %v", ErrUntrusted, err)
+	}
+	return nil
+}
+
+// parseAttributes returns the first value of each attribute, keyed by OID.
+func parseAttributes(b []byte) (map[string][]byte, error) {
+	attrs := make(map[string][]byte)
+	for len(b) > 0 {
+		var attr attribute
+		rest, err := asn1.Unmarshal(b, &attr)
+		if err != nil {
+			return nil, fmt.Errorf("parsing authenticated attributes: %w", err)
+		}
+		var v asn1.RawValue
+		if _, err := asn1.Unmarshal(attr.Values.Bytes, &v); err != nil {
+			return nil, fmt.Errorf("parsing authenticated attribute %v: %w", attr.Type, err)
+		}
+		attrs[attr.Type.String()] = v.FullBytes
+		b = rest
+	}
+	return attrs, nil
+}
+
+// findSigner returns the certificate identified by id and a pool of the
+// others.
+func findSigner(certs []*x509.Certificate, id issuerAndSerial) (*x509.Certificate, *x509.CertPool, error) {
+	var signer *x509.Certificate
+	intermediates := x509.NewCertPool()
+	for _, c := range certs {
+		if signer == nil && c.SerialNumber.Cmp(id.Serial) == 0 && bytes.Equal(c.RawIssuer, id.Issuer.FullBytes) {
+			signer = c
+			continue
+		}
+		intermediates.AddCert(c)
+	}
+	if signer == nil {
+		return nil, nil, errors.New("signer certificate not included in the signature")
+	}
+	return signer, intermediates, nil
+}
+
+func checkSignature(pub crypto.PublicKey, hash crypto.Hash, digest, sig []byte) error {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(k, hash, digest, sig); err != nil {
+			return fmt.Errorf("invalid signature: %w", err)
+		}
+		return nil
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest, sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported signer key type %T", pub)
+}
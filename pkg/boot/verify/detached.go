@@ -0,0 +1,164 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+
+	"github.com/u-root/u-root/pkg/boot"
+	"github.com/u-root/u-root/pkg/curl"
+	"github.com/u-root/uio/uio"
+)
+
+// DefaultSuffix is appended to an artifact's location to find its detached
+// signature.
+const DefaultSuffix = ".sig"
+
+// maxSignatureSize bounds the size of detached signatures.
+const maxSignatureSize = 4096
+
+// ErrNoLocation is returned for an artifact that was not fetched from a
+// file or URL, so that its detached signature cannot be found.
+var ErrNoLocation = errors.New("artifact location unknown")
+
+// Detached verifies signatures stored next to an artifact.
+//
+// The signature of an artifact fetched from a URL, such as
+// http://server/vmlinuz, or opened from a file is fetched from the same
+// location with Suffix appended, such as http://server/vmlinuz.sig.
+//
+// ed25519 signatures are over the artifact. ECDSA signatures are ASN.1
+// encoded and over the SHA-256, SHA-384 or SHA-512 digest of the artifact
+// for P-256, P-384 and P-521 keys respectively, as made by
+//
+//	openssl dgst -sha256 -sign key.pem -out vmlinuz.sig vmlinuz
+type Detached struct {
+	// Keys are the trusted ed25519 and ECDSA keys.
+	Keys []crypto.PublicKey
+
+	// Schemes fetch signatures. If nil, curl.DefaultSchemes is used.
+	Schemes curl.Schemes
+
+	// Suffix is appended to the artifact location. If empty,
+	// DefaultSuffix is used.
+	Suffix string
+}
+
+// location returns the URL an artifact was fetched from.
+func location(r io.ReaderAt) (*url.URL, error) {
+	switch f := r.(type) {
+	case curl.File:
+		return f.URL(), nil
+	case named:
+		return &url.URL{Scheme: "file", Path: f.Name()}, nil
+	case fmt.Stringer:
+		// E.g. uio.LazyOpenerAt, whose name is a path or URL.
+		u, err := url.Parse(f.String())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrNoLocation, err)
+		}
+		if u.Scheme == "" {
+			u.Scheme = "file"
+		}
+		return u, nil
+	}
+	return nil, ErrNoLocation
+}
+
+func (d *Detached) signature(r io.ReaderAt) ([]byte, error) {
+	u, err := location(r)
+	if err != nil {
+		return nil, err
+	}
+	suffix := d.Suffix
+	if suffix == "" {
+		suffix = DefaultSuffix
+	}
+	su := *u
+	// A digest fragment is the artifact's, not the signature's.
+	su.Fragment, su.RawFragment = "", ""
+	su.Path += suffix
+	if su.RawPath != "" {
+		su.RawPath += url.PathEscape(suffix)
+	}
+
+	s := d.Schemes
+	if s == nil {
+		s = curl.DefaultSchemes
+	}
+	f, err := s.FetchWithoutCache(context.Background(), &su)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotSigned, err)
+	}
+	defer f.Close()
+	sig, err := io.ReadAll(io.LimitReader(f, maxSignatureSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading signature %s: %w", &su, err)
+	}
+	if len(sig) > maxSignatureSize {
+		return nil, fmt.Errorf("signature %s is larger than %d bytes", &su, maxSignatureSize)
+	}
+	return sig, nil
+}
+
+// Verify implements boot.Verifier.
+func (d *Detached) Verify(_ boot.Artifact, r io.ReaderAt) error {
+	if len(d.Keys) == 0 {
+		return fmt.Errorf("%w: no trusted keys", ErrUntrusted)
+	}
+	sig, err := d.signature(r)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	digests := make(map[crypto.Hash][]byte)
+	for _, k := range d.Keys {
+		switch k := k.(type) {
+		case ed25519.PublicKey:
+			if data == nil {
+				if data, err = uio.ReadAll(r); err != nil {
+					return err
+				}
+			}
+			if ed25519.Verify(k, data, sig) {
+				return nil
+			}
+
+		case *ecdsa.PublicKey:
+			hash := ecdsaHash(k.Curve)
+			if _, ok := digests[hash]; !ok {
+				h := hash.New()
+				if _, err := io.Copy(h, io.NewSectionReader(r, 0, math.MaxInt64)); err != nil {
+					return err
+				}
+				digests[hash] = h.Sum(nil)
+			}
+			if ecdsa.VerifyASN1(k, digests[hash], sig) {
+				return nil
+			}
+		}
+	}
+	return ErrUntrusted
+}
+
+func ecdsaHash(c elliptic.Curve) crypto.Hash {
+	switch c.Params().BitSize {
+	case 384:
+		return crypto.SHA384
+	case 521:
+		return crypto.SHA512
+	}
+	return crypto.SHA256
+}
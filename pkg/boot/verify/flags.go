@@ -0,0 +1,44 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package verify
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/u-root/u-root/pkg/boot"
+	"github.com/u-root/u-root/pkg/curl"
+	"github.com/u-root/uio/ulog"
+)
+
+// Flags are the -trust and -verify-policy flags of boot commands.
+type Flags struct {
+	// Trust is a comma separated list of files with trusted certificates
+	// and public keys, see New. Nothing is verified if it is empty.
+	Trust string
+
+	// Policy is applied to artifacts failing verification.
+	Policy Policy
+}
+
+// Register defines -trust and -verify-policy in fs.
+func (f *Flags) Register(fs *flag.FlagSet) {
+	fs.StringVar(&f.Trust, "trust", "", "comma separated list of PEM or DER files with the certificates and public keys that must sign kernels and initramfs")
+	fs.Var(&f.Policy, "verify-policy", "what to do with images failing verification against -trust: refuse, warn or measure")
+}
+
+// LoadOptions returns the options for OSImage.Load verifying images as
+// the flags say, fetching detached signatures with s. It returns no options
+// if -trust is not set.
+func (f *Flags) LoadOptions(l ulog.Logger, s curl.Schemes) ([]boot.LoadOption, error) {
+	if f.Trust == "" {
+		return nil, nil
+	}
+	v, err := New(f.Policy, l, s, strings.Split(f.Trust, ",")...)
+	if err != nil {
+		return nil, err
+	}
+	return []boot.LoadOption{boot.WithVerifier(v)}, nil
+}
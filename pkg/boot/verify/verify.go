@@ -0,0 +1,296 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package verify implements boot.Verifiers checking kernels and initramfs
+// signatures before they are kexec'd.
+//
+// Two kinds of signatures are supported:
+//
+//   - Authenticode signatures embedded in PE images, such as EFI stub
+//     kernels signed for UEFI Secure Boot, chaining to a set of trusted
+//     certificates.
+//   - Detached ed25519 or ECDSA signatures stored next to the artifact,
+//     e.g. vmlinuz.sig next to vmlinuz, fetched with the same curl.Schemes
+//     as the artifact.
+//
+// A Policy decides what happens to an artifact that fails verification.
+//
+// Use with boot.WithVerifier:
+//
+//	v, err := verify.New(verify.Refuse, ulog.Log, curl.DefaultSchemes, "/etc/boot-trust.pem")
+//	...
+//	err = img.Load(boot.WithVerifier(v))
+package verify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/u-root/u-root/pkg/boot"
+	ucrypto "github.com/u-root/u-root/pkg/crypto"
+	"github.com/u-root/u-root/pkg/curl"
+	"github.com/u-root/uio/uio"
+	"github.com/u-root/uio/ulog"
+)
+
+// ErrNotSigned is returned for an artifact that carries no signature a
+// Verifier understands.
+var ErrNotSigned = errors.New("not signed")
+
+// ErrUntrusted is returned for an artifact whose signature is not made by a
+// trusted key.
+var ErrUntrusted = errors.New("signature is not trusted")
+
+// Policy is what to do with an artifact that fails verification.
+type Policy int
+
+// Policies.
+const (
+	// Refuse fails loading the image.
+	Refuse Policy = iota
+
+	// Warn logs the failure and loads the image anyway.
+	Warn
+
+	// MeasureOnly measures every artifact into the TPM and loads the
+	// image regardless of the verification result, leaving the decision
+	// to remote attestation. Failures are logged.
+	MeasureOnly
+)
+
+var policies = map[Policy]string{
+	Refuse:      "refuse",
+	Warn:        "warn",
+	MeasureOnly: "measure",
+}
+
+// String implements fmt.Stringer and flag.Value.
+func (p Policy) String() string {
+	if s, ok := policies[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// Set implements flag.Value, accepting "refuse", "warn" or "measure".
+func (p *Policy) Set(s string) error {
+	for k, v := range policies {
+		if v == s {
+			*p = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown verification policy %q, want refuse, warn or measure", s)
+}
+
+// measure measures an artifact into the TPM. It is replaced in tests.
+var measure = func(a boot.Artifact, r io.ReaderAt) error {
+	b, err := uio.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return ucrypto.TryMeasureData(ucrypto.BlobPCR, b, string(a))
+}
+
+// WithPolicy returns a Verifier that applies p to the results of v, logging
+// to l.
+func WithPolicy(v boot.Verifier, p Policy, l ulog.Logger) boot.Verifier {
+	if l == nil {
+		l = ulog.Null
+	}
+	return &policyVerifier{v: v, p: p, l: l}
+}
+
+type policyVerifier struct {
+	v boot.Verifier
+	p Policy
+	l ulog.Logger
+}
+
+// Verify implements boot.Verifier.
+func (pv *policyVerifier) Verify(a boot.Artifact, r io.ReaderAt) error {
+	err := pv.v.Verify(a, r)
+	switch pv.p {
+	case Refuse:
+		return err
+
+	case Warn:
+		if err != nil {
+			pv.l.Printf("Loading %s %s despite failed verification: %v", a, name(r), err)
+		}
+		return nil
+
+	case MeasureOnly:
+		if err != nil {
+			pv.l.Printf("Verification of %s %s failed: %v", a, name(r), err)
+		}
+		if err := measure(a, r); err != nil {
+			pv.l.Printf("Could not measure %s %s: %v", a, name(r), err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown verification policy %v", pv.p)
+}
+
+// Any is a Verifier that trusts an artifact if one of its Verifiers does.
+type Any []boot.Verifier
+
+// Verify implements boot.Verifier.
+func (vs Any) Verify(a boot.Artifact, r io.ReaderAt) error {
+	var errs []error
+	for _, v := range vs {
+		err := v.Verify(a, r)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return ErrNotSigned
+	}
+	return errors.Join(errs...)
+}
+
+// TrustStore is a set of trusted certificates and public keys.
+type TrustStore struct {
+	// Roots are the certificates Authenticode signatures must chain to.
+	Roots *x509.CertPool
+
+	// Keys are the ed25519 and ECDSA keys trusted for detached
+	// signatures. Keys of ed25519 and ECDSA certificates in Roots are
+	// included.
+	Keys []crypto.PublicKey
+}
+
+// NewTrustStore returns an empty TrustStore.
+func NewTrustStore() *TrustStore {
+	return &TrustStore{Roots: x509.NewCertPool()}
+}
+
+// AddCert trusts c.
+func (ts *TrustStore) AddCert(c *x509.Certificate) {
+	ts.Roots.AddCert(c)
+	switch c.PublicKey.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		ts.Keys = append(ts.Keys, c.PublicKey)
+	}
+}
+
+// AddKey trusts the ed25519 or ECDSA key k for detached signatures.
+func (ts *TrustStore) AddKey(k crypto.PublicKey) error {
+	switch k.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey:
+		ts.Keys = append(ts.Keys, k)
+		return nil
+	}
+	return fmt.Errorf("unsupported public key type %T", k)
+}
+
+// Add trusts the certificates and public keys in b, which is either a DER
+// encoded certificate or PEM encoded CERTIFICATE and PUBLIC KEY blocks.
+//
+// A PUBLIC KEY block of 32 bytes is an ed25519 key as written by
+// pkg/crypto.
+func (ts *TrustStore) Add(b []byte) error {
+	if c, err := x509.ParseCertificate(b); err == nil {
+		ts.AddCert(c)
+		return nil
+	}
+
+	var n int
+	for {
+		var block *pem.Block
+		block, b = pem.Decode(b)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "CERTIFICATE":
+			c, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return err
+			}
+			ts.AddCert(c)
+
+		case ucrypto.PubKeyIdentifier:
+			if len(block.Bytes) == ed25519.PublicKeySize {
+				ts.Keys = append(ts.Keys, ed25519.PublicKey(block.Bytes))
+				break
+			}
+			k, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return err
+			}
+			if err := ts.AddKey(k); err != nil {
+				return err
+			}
+
+		default:
+			continue
+		}
+		n++
+	}
+	if n == 0 {
+		return errors.New("no certificates or public keys found")
+	}
+	return nil
+}
+
+// AddFile trusts the certificates and public keys in the file at path. See
+// Add.
+func (ts *TrustStore) AddFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := ts.Add(b); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Verifier returns a Verifier trusting artifacts with an Authenticode
+// signature chaining to ts.Roots or a detached signature fetched with s made
+// by one of ts.Keys.
+func (ts *TrustStore) Verifier(s curl.Schemes) boot.Verifier {
+	return Any{
+		&Authenticode{Roots: ts.Roots},
+		&Detached{Keys: ts.Keys, Schemes: s},
+	}
+}
+
+// New returns a Verifier trusting the certificates and keys in the files at
+// paths, see TrustStore.Verifier, and applying p to artifacts that fail
+// verification.
+func New(p Policy, l ulog.Logger, s curl.Schemes, paths ...string) (boot.Verifier, error) {
+	ts := NewTrustStore()
+	for _, path := range paths {
+		if err := ts.AddFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return WithPolicy(ts.Verifier(s), p, l), nil
+}
+
+// named is satisfied by *os.File.
+type named interface {
+	Name() string
+}
+
+func name(r io.ReaderAt) string {
+	switch f := r.(type) {
+	case fmt.Stringer:
+		return f.String()
+	case named:
+		return f.Name()
+	}
+	return fmt.Sprintf("%T", r)
+}
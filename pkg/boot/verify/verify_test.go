@@ -0,0 +1,575 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package verify
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"io"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/u-root/u-root/pkg/boot"
+	"github.com/u-root/u-root/pkg/curl"
+	"github.com/u-root/uio/uio"
+)
+
+func newCert(t *testing.T, cn string, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// testPE returns a PE32+ image with a single section holding data.
+func testPE(data []byte) []byte {
+	const (
+		peOff      = 0x40
+		optSize    = 112 + 16*8
+		headerSize = 0x200
+	)
+	img := make([]byte, headerSize+len(data))
+	copy(img, "MZ")
+	binary.LittleEndian.PutUint32(img[0x3c:], peOff)
+	copy(img[peOff:], "PE\x00\x00")
+	coff := img[peOff+4:]
+	binary.LittleEndian.PutUint16(coff[0:], 0x8664)
+	binary.LittleEndian.PutUint16(coff[2:], 1)
+	binary.LittleEndian.PutUint16(coff[16:], optSize)
+	opt := img[peOff+24:]
+	binary.LittleEndian.PutUint16(opt[0:], 0x20b)
+	binary.LittleEndian.PutUint32(opt[60:], headerSize)
+	binary.LittleEndian.PutUint32(opt[64:], 0xdeadbeef)
+	binary.LittleEndian.PutUint32(opt[108:], 16)
+	section := opt[optSize:]
+	copy(section, ".text")
+	binary.LittleEndian.PutUint32(section[16:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(section[20:], headerSize)
+	copy(img[headerSize:], data)
+	return img
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// explicit wraps der in a context-specific tag.
+func explicit(t *testing.T, tag int, der ...[]byte) asn1.RawValue {
+	return asn1.RawValue{FullBytes: mustMarshal(t, asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        tag,
+		IsCompound: true,
+		Bytes:      bytes.Join(der, nil),
+	})}
+}
+
+// signPE returns img with an Authenticode signature by key and signer,
+// including chain, as sbsign would add.
+func signPE(t *testing.T, img []byte, key crypto.Signer, signer *x509.Certificate, chain ...*x509.Certificate) []byte {
+	t.Helper()
+	img = bytes.Clone(img)
+	certDir := 0x40 + 24 + 112 + 4*8
+	binary.LittleEndian.PutUint32(img[certDir:], uint32(len(img)))
+	binary.LittleEndian.PutUint32(img[certDir+4:], 8)
+	pe, err := parsePE(bytes.NewReader(img))
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := pe.digest(bytes.NewReader(img), crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sha256Alg := pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue}
+	content := mustMarshal(t, spcIndirectDataContent{
+		Data: asn1.RawValue{FullBytes: mustMarshal(t, []asn1.ObjectIdentifier{{1, 3, 6, 1, 4, 1, 311, 2, 1, 15}})},
+		MessageDigest: digestInfo{
+			Algorithm: sha256Alg,
+			Digest:    digest,
+		},
+	})
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(content, &raw); err != nil {
+		t.Fatal(err)
+	}
+	contentDigest := sha256.Sum256(raw.Bytes)
+
+	type attr struct {
+		Type   asn1.ObjectIdentifier
+		Values []asn1.RawValue `asn1:"set"`
+	}
+	attrs := bytes.Join([][]byte{
+		mustMarshal(t, attr{oidContentType, []asn1.RawValue{{FullBytes: mustMarshal(t, oidSpcIndirectData)}}}),
+		mustMarshal(t, attr{oidMessageDigest, []asn1.RawValue{{FullBytes: mustMarshal(t, contentDigest[:])}}}),
+	}, nil)
+	signed := mustMarshal(t, asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: attrs})
+	signedDigest := sha256.Sum256(signed)
+	sig, err := key.Sign(rand.Reader, signedDigest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certs := [][]byte{signer.Raw}
+	for _, c := range chain {
+		certs = append(certs, c.Raw)
+	}
+	sd := signedData{
+		Version:          1,
+		DigestAlgorithms: []pkix.AlgorithmIdentifier{sha256Alg},
+		ContentInfo:      contentInfo{ContentType: oidSpcIndirectData, Content: explicit(t, 0, content)},
+		Certificates:     explicit(t, 0, certs...),
+		SignerInfos: []signerInfo{{
+			Version:                   1,
+			IssuerAndSerial:           issuerAndSerial{Issuer: asn1.RawValue{FullBytes: signer.RawIssuer}, Serial: signer.SerialNumber},
+			DigestAlgorithm:           sha256Alg,
+			AuthenticatedAttributes:   explicit(t, 0, attrs),
+			DigestEncryptionAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}},
+			EncryptedDigest:           sig,
+		}},
+	}
+	pkcs7 := mustMarshal(t, contentInfo{ContentType: oidSignedData, Content: explicit(t, 0, mustMarshal(t, sd))})
+
+	length := 8 + len(pkcs7)
+	table := make([]byte, (length+7)&^7)
+	binary.LittleEndian.PutUint32(table[0:], uint32(length))
+	binary.LittleEndian.PutUint16(table[4:], 0x200)
+	binary.LittleEndian.PutUint16(table[6:], 0x2)
+	copy(table[8:], pkcs7)
+	binary.LittleEndian.PutUint32(img[certDir+4:], uint32(len(table)))
+	return append(img, table...)
+}
+
+func TestAuthenticode(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := newCert(t, "Test CA", caKey, nil, nil)
+	signerKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := newCert(t, "Test Signer", signerKey, ca, caKey)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := newCert(t, "Other CA", otherKey, nil, nil)
+	selfSigned := newCert(t, "Self", otherKey, nil, nil)
+
+	pool := func(certs ...*x509.Certificate) *x509.CertPool {
+		p := x509.NewCertPool()
+		for _, c := range certs {
+			p.AddCert(c)
+		}
+		return p
+	}
+	unsigned := testPE(bytes.Repeat([]byte("kernel"), 100))
+	signed := signPE(t, unsigned, signerKey, signer, ca)
+	tampered := bytes.Clone(signed)
+	tampered[0x210] ^= 1
+
+	for _, tt := range []struct {
+		name  string
+		kind  boot.Artifact
+		img   []byte
+		roots *x509.CertPool
+		err   error
+	}{
+		{name: "chain to root", img: signed, roots: pool(ca)},
+		{name: "signer trusted", img: signed, roots: pool(signer)},
+		{name: "self signed", img: signPE(t, unsigned, otherKey, selfSigned), roots: pool(selfSigned)},
+		{name: "untrusted", img: signed, roots: pool(other), err: ErrUntrusted},
+		{name: "no roots", img: signed, err: ErrUntrusted},
+		{name: "wrong key", img: signPE(t, unsigned, otherKey, signer), roots: pool(ca), err: errors.New("invalid signature")},
+		{name: "tampered", img: tampered, roots: pool(ca), err: errors.New("digest mismatch")},
+		{name: "unsigned", img: unsigned, roots: pool(ca), err: ErrNotSigned},
+		{name: "not PE", img: []byte("#!ipxe\n"), roots: pool(ca), err: ErrNotSigned},
+		{name: "initrd", kind: boot.ArtifactInitrd, img: signed, roots: pool(ca), err: ErrNotSigned},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			kind := tt.kind
+			if kind == "" {
+				kind = boot.ArtifactKernel
+			}
+			a := &Authenticode{Roots: tt.roots}
+			err := a.Verify(kind, bytes.NewReader(tt.img))
+			if (err == nil) != (tt.err == nil) || (errors.Is(tt.err, ErrNotSigned) || errors.Is(tt.err, ErrUntrusted)) && !errors.Is(err, tt.err) {
+				t.Errorf("Verify = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDetached(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kernel := []byte("a kernel")
+	edSig := ed25519.Sign(edKey, kernel)
+	digest := sha512.Sum384(kernel)
+	ecSig, err := ecdsa.SignASN1(rand.Reader, ecKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string][]byte{
+		"vmlinuz":     kernel,
+		"vmlinuz.sig": edSig,
+		"unsigned":    kernel,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	open := func(name string) io.ReaderAt {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+
+	m := curl.NewMockScheme("http")
+	m.Add("server", "/boot/vmlinuz", string(kernel))
+	m.Add("server", "/boot/vmlinuz.p7s", string(ecSig))
+	schemes := curl.Schemes{"http": m}
+	fetch := func(s string) io.ReaderAt {
+		u, err := url.Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := schemes.LazyFetch(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	for _, tt := range []struct {
+		name string
+		d    *Detached
+		r    io.ReaderAt
+		err  error
+	}{
+		{
+			name: "ed25519 file",
+			d:    &Detached{Keys: []crypto.PublicKey{otherKey.Public(), edKey.Public()}},
+			r:    open("vmlinuz"),
+		},
+		{
+			name: "lazy file",
+			d:    &Detached{Keys: []crypto.PublicKey{edKey.Public()}},
+			r:    uio.NewLazyFile(filepath.Join(dir, "vmlinuz")),
+		},
+		{
+			name: "ecdsa URL",
+			d:    &Detached{Keys: []crypto.PublicKey{ecKey.Public()}, Schemes: schemes, Suffix: ".p7s"},
+			r:    fetch("http://server/boot/vmlinuz"),
+		},
+		{
+			name: "wrong key",
+			d:    &Detached{Keys: []crypto.PublicKey{otherKey.Public()}},
+			r:    open("vmlinuz"),
+			err:  ErrUntrusted,
+		},
+		{
+			name: "no keys",
+			d:    &Detached{},
+			r:    open("vmlinuz"),
+			err:  ErrUntrusted,
+		},
+		{
+			name: "no signature",
+			d:    &Detached{Keys: []crypto.PublicKey{edKey.Public()}},
+			r:    open("unsigned"),
+			err:  ErrNotSigned,
+		},
+		{
+			name: "no signature at URL",
+			d:    &Detached{Keys: []crypto.PublicKey{ecKey.Public()}, Schemes: schemes},
+			r:    fetch("http://server/boot/vmlinuz"),
+			err:  ErrNotSigned,
+		},
+		{
+			name: "in memory",
+			d:    &Detached{Keys: []crypto.PublicKey{edKey.Public()}},
+			r:    bytes.NewReader(kernel),
+			err:  ErrNoLocation,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.d.Verify(boot.ArtifactKernel, tt.r); !errors.Is(err, tt.err) {
+				t.Errorf("Verify = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestDetachedInitrds checks that initrds concatenated by netboot config
+// parsers are verified one by one.
+func TestDetachedInitrds(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := curl.NewMockScheme("http")
+	for name, content := range map[string]string{
+		"vmlinuz":  "a kernel",
+		"initrd":   "an initrd",
+		"modules":  "more initrd",
+		"unsigned": "unsigned initrd",
+	} {
+		m.Add("server", "/boot/"+name, content)
+		if name != "unsigned" {
+			m.Add("server", "/boot/"+name+".sig", string(ed25519.Sign(key, []byte(content))))
+		}
+	}
+	schemes := curl.Schemes{"http": m}
+	fetch := func(name string) io.Reader {
+		f, err := schemes.LazyFetchWithoutCache(&url.URL{Scheme: "http", Host: "server", Path: "/boot/" + name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	v := &Detached{Keys: []crypto.PublicKey{key.Public()}, Schemes: schemes}
+
+	for _, tt := range []struct {
+		name    string
+		initrds []string
+		err     error
+	}{
+		{name: "signed", initrds: []string{"initrd", "modules"}},
+		{name: "unsigned", initrds: []string{"initrd", "unsigned"}, err: ErrNotSigned},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var initrds []io.Reader
+			for _, name := range tt.initrds {
+				initrds = append(initrds, fetch(name))
+			}
+			li := &boot.LinuxImage{
+				Kernel: uio.NewLazyOpenerAt("http://server/boot/vmlinuz", func() (io.ReaderAt, error) {
+					return strings.NewReader("a kernel"), nil
+				}),
+				Initrd: boot.CatInitrdsWithFileCache(initrds...),
+			}
+			if err := li.Load(boot.WithVerifier(v), boot.WithDryRun(true)); !errors.Is(err, tt.err) {
+				t.Errorf("Load = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+type verifierFunc func(boot.Artifact, io.ReaderAt) error
+
+func (f verifierFunc) Verify(a boot.Artifact, r io.ReaderAt) error {
+	return f(a, r)
+}
+
+func TestPolicy(t *testing.T) {
+	var measured []boot.Artifact
+	defer func(m func(boot.Artifact, io.ReaderAt) error) { measure = m }(measure)
+	measure = func(a boot.Artifact, r io.ReaderAt) error {
+		measured = append(measured, a)
+		return errors.New("no TPM")
+	}
+
+	fail := verifierFunc(func(a boot.Artifact, _ io.ReaderAt) error {
+		if a == boot.ArtifactInitrd {
+			return ErrNotSigned
+		}
+		return nil
+	})
+	for _, tt := range []struct {
+		policy   string
+		err      error
+		measured []boot.Artifact
+	}{
+		{policy: "refuse", err: ErrNotSigned},
+		{policy: "warn"},
+		{policy: "measure", measured: []boot.Artifact{boot.ArtifactKernel, boot.ArtifactInitrd}},
+	} {
+		t.Run(tt.policy, func(t *testing.T) {
+			measured = nil
+			var p Policy
+			if err := p.Set(tt.policy); err != nil {
+				t.Fatal(err)
+			}
+			if p.String() != tt.policy {
+				t.Errorf("String = %q, want %q", p, tt.policy)
+			}
+			v := WithPolicy(fail, p, nil)
+			var err error
+			for _, a := range []boot.Artifact{boot.ArtifactKernel, boot.ArtifactInitrd} {
+				err = errors.Join(err, v.Verify(a, bytes.NewReader(nil)))
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Verify = %v, want %v", err, tt.err)
+			}
+			if len(measured) != len(tt.measured) {
+				t.Errorf("measured %v, want %v", measured, tt.measured)
+			}
+		})
+	}
+
+	var p Policy
+	if err := p.Set("enforce"); err == nil {
+		t.Errorf("Set(enforce) = nil, want error")
+	}
+}
+
+func TestTrustStore(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := newCert(t, "Test CA", ecKey, nil, nil)
+	pkixPub, err := x509.MarshalPKIXPublicKey(edPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	pemFile := filepath.Join(dir, "trust.pem")
+	if err := os.WriteFile(pemFile, bytes.Join([][]byte{
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkixPub}),
+		// As written by pkg/crypto.
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: edPub}),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("ignored")}),
+	}, nil), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	derFile := filepath.Join(dir, "db.der")
+	if err := os.WriteFile(derFile, ca.Raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	badFile := filepath.Join(dir, "bad")
+	if err := os.WriteFile(badFile, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := NewTrustStore()
+	for _, f := range []string{pemFile, derFile} {
+		if err := ts.AddFile(f); err != nil {
+			t.Errorf("AddFile(%s) = %v", f, err)
+		}
+	}
+	// The ECDSA CA key twice, the ed25519 key twice.
+	if len(ts.Keys) != 4 {
+		t.Errorf("got %d keys, want 4", len(ts.Keys))
+	}
+	if _, err := ca.Verify(x509.VerifyOptions{Roots: ts.Roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		t.Errorf("CA not in roots: %v", err)
+	}
+	if err := ts.AddFile(badFile); err == nil {
+		t.Errorf("AddFile(%s) = nil, want error", badFile)
+	}
+	if _, err := New(Refuse, nil, nil, pemFile, filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("New with missing file = nil, want error")
+	}
+}
+
+func TestFlags(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: edPub}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		args    []string
+		want    Flags
+		opts    int
+		wantErr bool
+	}{
+		{want: Flags{Policy: Refuse}},
+		{args: []string{"-trust", keyFile, "-verify-policy", "warn"}, want: Flags{Trust: keyFile, Policy: Warn}, opts: 1},
+		{args: []string{"-trust", keyFile + ",missing"}, want: Flags{Trust: keyFile + ",missing"}, wantErr: true},
+	} {
+		var f Flags
+		fs := flag.NewFlagSet("boot", flag.ContinueOnError)
+		f.Register(fs)
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("Parse(%q) = %v", tt.args, err)
+		}
+		if f != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.args, f, tt.want)
+		}
+		opts, err := f.LoadOptions(nil, nil)
+		if (err != nil) != tt.wantErr || len(opts) != tt.opts {
+			t.Errorf("LoadOptions(%q) = %d options, %v, want %d options, error %t", tt.args, len(opts), err, tt.opts, tt.wantErr)
+		}
+	}
+
+	fs := flag.NewFlagSet("boot", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	new(Flags).Register(fs)
+	if err := fs.Parse([]string{"-verify-policy", "ignore"}); err == nil {
+		t.Errorf("Parse(-verify-policy ignore) = nil, want error")
+	}
+}
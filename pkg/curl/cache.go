@@ -0,0 +1,494 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curl
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/u-root/uio/ulog"
+)
+
+// Cache is a content-addressed cache of fetched files in a directory, such
+// as on a tmpfs, shared by all FileSchemes it wraps. It outlives failed boot
+// attempts, so that the same initramfs is not downloaded again.
+//
+// Files are stored by the SHA-256 of their content, and are found by the
+// digest in their URL, see Schemes.Fetch, or by their URL. Files found by
+// their URL are found again by another Cache if the HTTP server sent an ETag
+// or Last-Modified date for them, which is trusted to still be current unless
+// WithRevalidation is given. Other files, such as TFTP files without digest,
+// are only found again by the same Cache, as they may have changed since
+// another one fetched them.
+//
+// When the cached files exceed the size of the Cache, the least recently used
+// are evicted.
+type Cache struct {
+	dir        string
+	maxSize    int64
+	logger     ulog.Logger
+	revalidate bool
+
+	mu sync.Mutex
+	// urls maps URLs without validator to blobs.
+	urls map[string]string
+	// vals are the validators asked for by key, so that each file is
+	// asked for once.
+	vals map[string]string
+	// inflight are the downloads in progress by key.
+	inflight map[string]*flight
+}
+
+// flight is a download other fetches of the same file wait for.
+type flight struct {
+	done chan struct{}
+	err  error
+}
+
+// CacheOpt is an optional parameter to NewCache.
+type CacheOpt func(*Cache)
+
+// WithCacheLogger logs cache hits, misses and evictions to l instead of the
+// standard logger.
+func WithCacheLogger(l ulog.Logger) CacheOpt {
+	return func(c *Cache) {
+		c.logger = l
+	}
+}
+
+// WithRevalidation makes the Cache ask the HTTP server whether a file found
+// by its URL changed since another Cache stored it, rather than only asking
+// on a miss. Each file is asked for once per Cache.
+func WithRevalidation() CacheOpt {
+	return func(c *Cache) {
+		c.revalidate = true
+	}
+}
+
+// NewCache returns a Cache storing up to maxSize bytes of files in dir, which
+// is created if it does not exist. If maxSize is 0, the size is not bounded.
+func NewCache(dir string, maxSize int64, opts ...CacheOpt) (*Cache, error) {
+	c := &Cache{
+		dir:      dir,
+		maxSize:  maxSize,
+		logger:   ulog.Log,
+		urls:     make(map[string]string),
+		vals:     make(map[string]string),
+		inflight: make(map[string]*flight),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for _, d := range []string{c.blobDir(), c.keyDir()} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *Cache) blobDir() string {
+	return filepath.Join(c.dir, "blobs")
+}
+
+func (c *Cache) keyDir() string {
+	return filepath.Join(c.dir, "keys")
+}
+
+func (c *Cache) blobPath(blob string) string {
+	return filepath.Join(c.blobDir(), blob)
+}
+
+func (c *Cache) keyPath(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return filepath.Join(c.keyDir(), hex.EncodeToString(h[:]))
+}
+
+// Scheme returns a FileScheme that fetches files with fs through c.
+func (c *Cache) Scheme(fs FileScheme) FileScheme {
+	return &cachedScheme{c: c, fs: fs}
+}
+
+// Schemes returns s with all schemes but local files fetched through c.
+func (c *Cache) Schemes(s Schemes) Schemes {
+	cs := make(Schemes, len(s))
+	for name, fs := range s {
+		if name != "file" {
+			fs = c.Scheme(fs)
+		}
+		cs[name] = fs
+	}
+	return cs
+}
+
+// cachedScheme is a FileScheme whose files are fetched through a Cache.
+type cachedScheme struct {
+	c  *Cache
+	fs FileScheme
+}
+
+// Fetch implements FileScheme.Fetch.
+func (cs *cachedScheme) Fetch(ctx context.Context, u *url.URL) (io.ReaderAt, error) {
+	f, err := cs.c.fetch(ctx, cs.fs, u)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// FetchWithoutCache implements FileScheme.FetchWithoutCache.
+//
+// The file is still stored in the Cache, just not in memory.
+func (cs *cachedScheme) FetchWithoutCache(ctx context.Context, u *url.URL) (io.Reader, error) {
+	f, err := cs.c.fetch(ctx, cs.fs, u)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// validator is implemented by FileSchemes that can tell whether a file has
+// changed without fetching it.
+type validator interface {
+	// validate returns a string that changes with the file at u, or ""
+	// if there is none.
+	validate(ctx context.Context, u *url.URL) string
+}
+
+// validate implements validator with the strong ETag or the Last-Modified
+// date of the file.
+func (h HTTPClient) validate(ctx context.Context, u *url.URL) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return ""
+	}
+	resp, err := h.c.Do(req)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return ""
+	}
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return "etag " + etag
+	}
+	if lm := resp.Header.Get("Last-Modified"); lm != "" {
+		return "last-modified " + lm
+	}
+	return ""
+}
+
+// validate implements validator.
+func (s *SchemeWithRetries) validate(ctx context.Context, u *url.URL) string {
+	if v, ok := s.Scheme.(validator); ok {
+		return v.validate(ctx, u)
+	}
+	return ""
+}
+
+type digestKey struct{}
+
+// withDigest passes the digest in the URL of a file to a cachedScheme, which
+// only gets the URL without it.
+func withDigest(ctx context.Context, d *digest) context.Context {
+	if d == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, digestKey{}, d)
+}
+
+// key returns the key of the file at u: the digest in its URL if there is
+// one, and the URL otherwise.
+func key(ctx context.Context, u *url.URL) (string, bool) {
+	if d, ok := ctx.Value(digestKey{}).(*digest); ok {
+		return d.name + ":" + hex.EncodeToString(d.want), true
+	}
+	return u.String(), false
+}
+
+// validate returns the validator of the file at u with fs, asking for it
+// once per key.
+func (c *Cache) validate(ctx context.Context, fs FileScheme, u *url.URL, key string) string {
+	c.mu.Lock()
+	val, ok := c.vals[key]
+	c.mu.Unlock()
+	if ok {
+		return val
+	}
+	if v, ok := fs.(validator); ok {
+		val = v.validate(ctx, u)
+	}
+	c.mu.Lock()
+	c.vals[key] = val
+	c.mu.Unlock()
+	return val
+}
+
+// readKey returns the blob and validator recorded for key, if any.
+func (c *Cache) readKey(key string) (blob, val string) {
+	b, err := os.ReadFile(c.keyPath(key))
+	if err != nil {
+		return "", ""
+	}
+	blob, val, _ = strings.Cut(string(b), "\n")
+	return blob, val
+}
+
+// lookup returns the cached file for key, or nil if there is none.
+func (c *Cache) lookup(ctx context.Context, fs FileScheme, u *url.URL, key string, isDigest bool) *os.File {
+	var blob string
+	if name, sum, ok := strings.Cut(key, ":"); ok && isDigest && name == "sha256" {
+		// Blobs are named by their SHA-256.
+		blob = sum
+	} else {
+		c.mu.Lock()
+		blob = c.urls[key]
+		c.mu.Unlock()
+		if blob == "" {
+			var val string
+			blob, val = c.readKey(key)
+			if blob != "" && !isDigest && c.revalidate {
+				if c.validate(ctx, fs, u, key) != val {
+					c.logger.Printf("Cached %s changed", u)
+					return nil
+				}
+			}
+		}
+	}
+	if blob == "" {
+		return nil
+	}
+	f, err := os.Open(c.blobPath(blob))
+	if err != nil {
+		// Evicted.
+		return nil
+	}
+	// Eviction goes by modification time.
+	now := time.Now()
+	_ = os.Chtimes(f.Name(), now, now)
+	return f
+}
+
+// fetch returns the file at u from the cache, or fetches it with fs into the
+// cache.
+func (c *Cache) fetch(ctx context.Context, fs FileScheme, u *url.URL) (*os.File, error) {
+	key, isDigest := key(ctx, u)
+
+	for {
+		if f := c.lookup(ctx, fs, u, key, isDigest); f != nil {
+			c.logger.Printf("Cache hit for %s", u)
+			return f, nil
+		}
+
+		c.mu.Lock()
+		fl, ok := c.inflight[key]
+		if !ok {
+			fl = &flight{done: make(chan struct{})}
+			c.inflight[key] = fl
+		}
+		c.mu.Unlock()
+		if !ok {
+			break
+		}
+		// Someone else fetches the file already.
+		select {
+		case <-fl.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if fl.err != nil {
+			return nil, fl.err
+		}
+	}
+
+	// The file may have been stored since the lookup.
+	f := c.lookup(ctx, fs, u, key, isDigest)
+	var err error
+	if f != nil {
+		c.logger.Printf("Cache hit for %s", u)
+	} else {
+		f, err = c.store(ctx, fs, u, key, isDigest)
+	}
+
+	c.mu.Lock()
+	fl := c.inflight[key]
+	delete(c.inflight, key)
+	c.mu.Unlock()
+	fl.err = err
+	close(fl.done)
+	return f, err
+}
+
+// store fetches the file at u with fs into the cache under key.
+func (c *Cache) store(ctx context.Context, fs FileScheme, u *url.URL, key string, isDigest bool) (*os.File, error) {
+	c.logger.Printf("Cache miss for %s, fetching", u)
+	// Files found by their URL are only found again by another Cache
+	// if they have a validator. It is asked for before the file is
+	// fetched, so that a change in between makes it stale rather than
+	// going unnoticed.
+	persistent := isDigest
+	var val string
+	if !isDigest {
+		val = c.validate(ctx, fs, u, key)
+		persistent = val != ""
+	}
+
+	r, err := fs.FetchWithoutCache(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if rc, ok := r.(io.Closer); ok {
+		defer rc.Close()
+	}
+
+	tmp, err := os.CreateTemp(c.dir, "fetch-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	sum := sha256.New()
+	w := io.MultiWriter(tmp, sum)
+	var d *digest
+	var dh hash.Hash
+	if d, _ = ctx.Value(digestKey{}).(*digest); d != nil && d.hash != crypto.SHA256 {
+		dh = d.hash.New()
+		w = io.MultiWriter(w, dh)
+	}
+	n, err := io.Copy(w, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	blob := hex.EncodeToString(sum.Sum(nil))
+	if d != nil {
+		if dh == nil {
+			dh = sum
+		}
+		if err := d.check(dh); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.maxSize > 0 && n > c.maxSize {
+		c.logger.Printf("Not caching %s, its %d bytes exceed the cache size", u, n)
+		// The file is only removed from the directory, and read
+		// until it is closed.
+		return os.Open(tmp.Name())
+	}
+
+	if err := os.Rename(tmp.Name(), c.blobPath(blob)); err != nil {
+		return nil, err
+	}
+	if persistent {
+		record := blob
+		if val != "" {
+			record += "\n" + val
+		}
+		if err := writeFileAtomic(c.keyPath(key), []byte(record)); err != nil {
+			return nil, err
+		}
+	} else {
+		c.mu.Lock()
+		c.urls[key] = blob
+		c.mu.Unlock()
+	}
+	c.logger.Printf("Cached %s as %s (%d bytes)", u, blob, n)
+
+	f, err := os.Open(c.blobPath(blob))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.evict(blob); err != nil {
+		c.logger.Printf("Evicting cached files failed: %v", err)
+	}
+	return f, nil
+}
+
+// evict removes the least recently used blobs other than keep until the
+// cache fits in its size.
+func (c *Cache) evict(keep string) error {
+	if c.maxSize == 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.blobDir())
+	if err != nil {
+		return err
+	}
+	var infos []fs.FileInfo
+	var size int64
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		infos = append(infos, fi)
+		size += fi.Size()
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+
+	var errs []error
+	for _, fi := range infos {
+		if size <= c.maxSize {
+			break
+		}
+		if fi.Name() == keep {
+			continue
+		}
+		// Keys of evicted blobs are left dangling, and lookup treats
+		// them as misses.
+		if err := os.Remove(c.blobPath(fi.Name())); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c.logger.Printf("Evicted cached file %s (%d bytes)", fi.Name(), fi.Size())
+		size -= fi.Size()
+	}
+	return errors.Join(errs...)
+}
+
+// writeFileAtomic writes b to path, so that readers never see a partial file.
+func writeFileAtomic(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
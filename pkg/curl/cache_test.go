@@ -0,0 +1,242 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/u-root/uio/uio"
+	"github.com/u-root/uio/ulog"
+)
+
+// countingScheme serves files from a map and counts fetches.
+type countingScheme struct {
+	mu      sync.Mutex
+	files   map[string]string
+	fetches map[string]int
+}
+
+func (cs *countingScheme) FetchWithoutCache(_ context.Context, u *url.URL) (io.Reader, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.fetches[u.String()]++
+	content, ok := cs.files[u.String()]
+	if !ok {
+		return nil, ErrNoSuchFile
+	}
+	return strings.NewReader(content), nil
+}
+
+func (cs *countingScheme) Fetch(ctx context.Context, u *url.URL) (io.ReaderAt, error) {
+	r, err := cs.FetchWithoutCache(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	return uio.NewCachingReader(r), nil
+}
+
+func (cs *countingScheme) count(u string) int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return cs.fetches[u]
+}
+
+func mustParse(t *testing.T, s string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func readFetch(t *testing.T, s Schemes, u string) (string, error) {
+	t.Helper()
+	f, err := s.Fetch(context.Background(), mustParse(t, u))
+	if err != nil {
+		return "", err
+	}
+	b, err := uio.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	// Closing f closes the file in the cache.
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if _, err := f.ReadAt(make([]byte, 1), 0); err == nil {
+		t.Errorf("ReadAt(%s) after Close = nil, want error", u)
+	}
+	return string(b), nil
+}
+
+func TestCache(t *testing.T) {
+	sum := sha256.Sum256([]byte("initramfs"))
+	digestURL := "tftp://server/initramfs#sha256=" + hex.EncodeToString(sum[:])
+
+	cs := &countingScheme{
+		files: map[string]string{
+			"tftp://server/initramfs": "initramfs",
+			"tftp://server/kernel":    "kernel",
+		},
+		fetches: make(map[string]int),
+	}
+	dir := t.TempDir()
+	c, err := NewCache(dir, 0, WithCacheLogger(ulog.Null))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := c.Schemes(Schemes{"tftp": cs})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := readFetch(t, s, "tftp://server/kernel"); err != nil || got != "kernel" {
+				t.Errorf("Fetch = %q, %v, want kernel", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := cs.count("tftp://server/kernel"); n != 1 {
+		t.Errorf("kernel fetched %d times, want 1", n)
+	}
+
+	if got, err := readFetch(t, s, digestURL); err != nil || got != "initramfs" {
+		t.Errorf("Fetch(%s) = %q, %v, want initramfs", digestURL, got, err)
+	}
+
+	// A new cache, e.g. of the next boot attempt, finds files by digest,
+	// but not by TFTP URL.
+	c2, err := NewCache(dir, 0, WithCacheLogger(ulog.Null))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2 := c2.Schemes(Schemes{"tftp": cs})
+	if got, err := readFetch(t, s2, digestURL); err != nil || got != "initramfs" {
+		t.Errorf("Fetch(%s) = %q, %v, want initramfs", digestURL, got, err)
+	}
+	if n := cs.count("tftp://server/initramfs"); n != 1 {
+		t.Errorf("initramfs fetched %d times, want 1", n)
+	}
+	if _, err := readFetch(t, s2, "tftp://server/kernel"); err != nil {
+		t.Error(err)
+	}
+	if n := cs.count("tftp://server/kernel"); n != 2 {
+		t.Errorf("kernel fetched %d times, want 2", n)
+	}
+
+	// Mismatching files are not cached.
+	bad := "tftp://server/kernel#sha256=" + hex.EncodeToString(make([]byte, sha256.Size))
+	for range 2 {
+		if _, err := readFetch(t, s2, bad); !errors.Is(err, ErrDigestMismatch) {
+			t.Errorf("Fetch(%s) = %v, want %v", bad, err, ErrDigestMismatch)
+		}
+	}
+	if n := cs.count("tftp://server/kernel"); n != 4 {
+		t.Errorf("kernel fetched %d times, want 4", n)
+	}
+}
+
+func TestCacheHTTP(t *testing.T) {
+	var mu sync.Mutex
+	etag := `"1"`
+	var gets, heads int
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("ETag", etag)
+		switch r.Method {
+		case http.MethodGet:
+			gets++
+		case http.MethodHead:
+			heads++
+		}
+		io.WriteString(w, "vmlinuz "+etag)
+	}))
+	defer s.Close()
+
+	dir := t.TempDir()
+	fetch := func(want string, wantGets, wantHeads int, opts ...CacheOpt) {
+		t.Helper()
+		c, err := NewCache(dir, 0, append([]CacheOpt{WithCacheLogger(ulog.Null)}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schemes := c.Schemes(Schemes{"http": NewHTTPClient(s.Client())})
+		got, err := readFetch(t, schemes, s.URL+"/vmlinuz")
+		if err != nil || got != want {
+			t.Errorf("Fetch = %q, %v, want %q", got, err, want)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if gets != wantGets || heads != wantHeads {
+			t.Errorf("server got %d GETs and %d HEADs, want %d and %d", gets, heads, wantGets, wantHeads)
+		}
+	}
+
+	// The ETag is asked for on a miss only.
+	fetch(`vmlinuz "1"`, 1, 1)
+	fetch(`vmlinuz "1"`, 1, 1)
+
+	mu.Lock()
+	etag = `"2"`
+	mu.Unlock()
+	// The stored ETag is trusted, unless revalidating.
+	fetch(`vmlinuz "1"`, 1, 1)
+	fetch(`vmlinuz "2"`, 2, 2, WithRevalidation())
+	fetch(`vmlinuz "2"`, 2, 3, WithRevalidation())
+	fetch(`vmlinuz "2"`, 2, 3)
+}
+
+func TestCacheEviction(t *testing.T) {
+	cs := &countingScheme{
+		files: map[string]string{
+			"tftp://server/a": strings.Repeat("a", 10),
+			"tftp://server/b": strings.Repeat("b", 10),
+			"tftp://server/c": strings.Repeat("c", 10),
+			"tftp://server/d": strings.Repeat("d", 100),
+		},
+		fetches: make(map[string]int),
+	}
+	c, err := NewCache(t.TempDir(), 25, WithCacheLogger(ulog.Null))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := c.Schemes(Schemes{"tftp": cs})
+
+	for _, u := range []string{"a", "b", "a", "c", "a", "b", "d", "d"} {
+		if _, err := readFetch(t, s, "tftp://server/"+u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// b was evicted for c, being least recently used, and d does not
+	// fit.
+	for u, want := range map[string]int{"a": 1, "b": 2, "c": 1, "d": 2} {
+		if n := cs.count("tftp://server/" + u); n != want {
+			t.Errorf("%s fetched %d times, want %d", u, n, want)
+		}
+	}
+
+	entries, err := os.ReadDir(c.blobDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("cache holds %d files, want 2", len(entries))
+	}
+}
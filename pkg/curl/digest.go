@@ -0,0 +1,153 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curl
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+	"net/url"
+	"sync"
+
+	// Register the hashes for crypto.Hash.New.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// ErrDigestMismatch is returned when reading a file whose content does not
+// match the digest in its URL.
+var ErrDigestMismatch = errors.New("digest mismatch")
+
+// digests are the supported digest fragments, strongest first.
+var digests = []struct {
+	name string
+	hash crypto.Hash
+}{
+	{"sha512", crypto.SHA512},
+	{"sha384", crypto.SHA384},
+	{"sha256", crypto.SHA256},
+}
+
+// digest is the expected digest of a file.
+type digest struct {
+	name string
+	hash crypto.Hash
+	want []byte
+}
+
+func (d *digest) check(h hash.Hash) error {
+	if got := h.Sum(nil); !bytes.Equal(got, d.want) {
+		return fmt.Errorf("%w: %s is %x, want %x", ErrDigestMismatch, d.name, got, d.want)
+	}
+	return nil
+}
+
+// parseDigest returns the digest in u's fragment, such as #sha256=HEX, and u
+// without the fragment to fetch.
+//
+// If the fragment holds several digests, the strongest is used. If it holds
+// none, u is returned unchanged.
+func parseDigest(u *url.URL) (*url.URL, *digest, error) {
+	if u.Fragment == "" {
+		return u, nil, nil
+	}
+	v, err := url.ParseQuery(u.Fragment)
+	if err != nil {
+		return u, nil, nil
+	}
+	for _, dg := range digests {
+		name, h := dg.name, dg.hash
+		want := v.Get(name)
+		if want == "" {
+			continue
+		}
+		b, err := hex.DecodeString(want)
+		if err != nil || len(b) != h.Size() {
+			return nil, nil, fmt.Errorf("invalid %s digest %q", name, want)
+		}
+		stripped := *u
+		stripped.Fragment, stripped.RawFragment = "", ""
+		return &stripped, &digest{name: name, hash: h, want: b}, nil
+	}
+	return u, nil, nil
+}
+
+// digestReader verifies the digest of r when reaching EOF.
+type digestReader struct {
+	r io.Reader
+	h hash.Hash
+	d *digest
+}
+
+func newDigestReader(r io.Reader, d *digest) io.Reader {
+	if d == nil {
+		return r
+	}
+	return &digestReader{r: r, h: d.hash.New(), d: d}
+}
+
+// Read implements io.Reader.
+func (dr *digestReader) Read(p []byte) (int, error) {
+	n, err := dr.r.Read(p)
+	dr.h.Write(p[:n])
+	if errors.Is(err, io.EOF) {
+		if derr := dr.d.check(dr.h); derr != nil {
+			return n, derr
+		}
+	}
+	return n, err
+}
+
+// Close implements io.Closer, closing r if it is an io.Closer.
+func (dr *digestReader) Close() error {
+	if c, ok := dr.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// digestReaderAt verifies the digest of all of r before the first read.
+type digestReaderAt struct {
+	r    io.ReaderAt
+	d    *digest
+	once sync.Once
+	err  error
+}
+
+func newDigestReaderAt(r io.ReaderAt, d *digest) io.ReaderAt {
+	if d == nil {
+		return r
+	}
+	return &digestReaderAt{r: r, d: d}
+}
+
+// ReadAt implements io.ReaderAt.
+func (dr *digestReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	dr.once.Do(func() {
+		h := dr.d.hash.New()
+		if _, err := io.Copy(h, io.NewSectionReader(dr.r, 0, math.MaxInt64)); err != nil {
+			dr.err = err
+			return
+		}
+		dr.err = dr.d.check(h)
+	})
+	if dr.err != nil {
+		return 0, dr.err
+	}
+	return dr.r.ReadAt(p, off)
+}
+
+// Close implements io.Closer, closing r if it is an io.Closer.
+func (dr *digestReaderAt) Close() error {
+	if c, ok := dr.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curl
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"testing"
+
+	"github.com/u-root/uio/uio"
+)
+
+func TestDigest(t *testing.T) {
+	content := "initramfs"
+	sha256Sum := sha256.Sum256([]byte(content))
+	sha512Sum := sha512.Sum512([]byte(content))
+	good256 := hex.EncodeToString(sha256Sum[:])
+	good512 := hex.EncodeToString(sha512Sum[:])
+	bad := hex.EncodeToString(make([]byte, sha256.Size))
+
+	m := NewMockScheme("tftp")
+	m.Add("server", "/initramfs", content)
+	s := Schemes{"tftp": m}
+
+	for _, tt := range []struct {
+		url string
+		err error
+	}{
+		{url: "tftp://server/initramfs"},
+		{url: "tftp://server/initramfs#sha256=" + good256},
+		{url: "tftp://server/initramfs#sha512=" + good512},
+		// Only the strongest digest is checked.
+		{url: "tftp://server/initramfs#sha256=" + bad + "&sha512=" + good512},
+		{url: "tftp://server/initramfs#sha256=" + bad, err: ErrDigestMismatch},
+		{url: "tftp://server/initramfs#sha256=abc", err: errors.New("invalid digest")},
+		// Not a digest.
+		{url: "tftp://server/initramfs#foo"},
+	} {
+		t.Run(tt.url, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check := func(name string, b []byte, err error) {
+				t.Helper()
+				if (err != nil) != (tt.err != nil) || errors.Is(tt.err, ErrDigestMismatch) && !errors.Is(err, tt.err) {
+					t.Errorf("%s = %v, want %v", name, err, tt.err)
+				}
+				if err == nil && string(b) != content {
+					t.Errorf("%s = %q, want %q", name, b, content)
+				}
+			}
+
+			f, err := s.FetchWithoutCache(context.Background(), u)
+			if err == nil {
+				var b []byte
+				b, err = io.ReadAll(f)
+				check("FetchWithoutCache", b, err)
+			} else {
+				check("FetchWithoutCache", nil, err)
+			}
+
+			fc, err := s.Fetch(context.Background(), u)
+			if err == nil {
+				var b []byte
+				b, err = uio.ReadAll(fc)
+				check("Fetch", b, err)
+			} else {
+				check("Fetch", nil, err)
+			}
+
+			lf, err := s.LazyFetch(u)
+			if err != nil {
+				check("LazyFetch", nil, err)
+				return
+			}
+			b, err := uio.ReadAll(lf)
+			check("LazyFetch", b, err)
+			if got := lf.URL().String(); got != tt.url {
+				t.Errorf("URL() = %s, want %s", got, tt.url)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curl
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/u-root/u-root/pkg/cmdline"
+)
+
+// ErrPinMismatch is returned when no certificate of an HTTPS server matches
+// a pinned public key.
+var ErrPinMismatch = errors.New("no certificate matches a pinned public key")
+
+// HTTPSConfig configures the TLS connections of an HTTPS FileScheme.
+//
+// Files are paths in the initramfs, which must be trusted.
+type HTTPSConfig struct {
+	// CAFiles are PEM files with the certificates server certificates
+	// must chain to.
+	CAFiles []string `json:"ca,omitempty"`
+
+	// CertFile and KeyFile are a PEM client certificate and key to
+	// present to servers that require them.
+	CertFile string `json:"cert,omitempty"`
+	KeyFile  string `json:"key,omitempty"`
+
+	// Pins are base64 SHA-256 hashes of the SubjectPublicKeyInfo of
+	// certificates, optionally prefixed with "sha256/", as in HPKP.
+	//
+	// One certificate of the server's chain must match a pin. If there
+	// are no CAFiles, the server's certificate must match a pin and its
+	// chain is not checked.
+	Pins []string `json:"pins,omitempty"`
+}
+
+// LoadHTTPSConfig reads an HTTPSConfig from the JSON file at path, such as
+//
+//	{"ca": ["/etc/ca.pem"], "cert": "/etc/client.pem", "key": "/etc/client.key"}
+func LoadHTTPSConfig(path string) (*HTTPSConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c HTTPSConfig
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("parsing HTTPS config %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Kernel command line flags read by HTTPSConfigFromCmdline. Lists are comma
+// separated.
+const (
+	CmdlineHTTPSConfig = "uroot.https"
+	CmdlineHTTPSCA     = "uroot.https_ca"
+	CmdlineHTTPSCert   = "uroot.https_cert"
+	CmdlineHTTPSKey    = "uroot.https_key"
+	CmdlineHTTPSPins   = "uroot.https_pin"
+)
+
+// HTTPSConfigFromCmdline returns the HTTPSConfig given on the kernel command
+// line c, or nil if there is none.
+//
+// uroot.https=FILE loads the config from FILE, see LoadHTTPSConfig. The
+// uroot.https_ca, uroot.https_cert, uroot.https_key and uroot.https_pin
+// flags set or override its fields.
+func HTTPSConfigFromCmdline(c *cmdline.CmdLine) (*HTTPSConfig, error) {
+	var hc *HTTPSConfig
+	if path, ok := c.Flag(CmdlineHTTPSConfig); ok {
+		var err error
+		if hc, err = LoadHTTPSConfig(path); err != nil {
+			return nil, err
+		}
+	}
+	set := func(flag string, f func(v string)) {
+		if v, ok := c.Flag(flag); ok && v != "" {
+			if hc == nil {
+				hc = &HTTPSConfig{}
+			}
+			f(v)
+		}
+	}
+	set(CmdlineHTTPSCA, func(v string) { hc.CAFiles = strings.Split(v, ",") })
+	set(CmdlineHTTPSCert, func(v string) { hc.CertFile = v })
+	set(CmdlineHTTPSKey, func(v string) { hc.KeyFile = v })
+	set(CmdlineHTTPSPins, func(v string) { hc.Pins = strings.Split(v, ",") })
+	return hc, nil
+}
+
+func parsePin(pin string) ([]byte, error) {
+	pin = strings.TrimPrefix(strings.TrimPrefix(pin, "sha256/"), "/")
+	b, err := base64.StdEncoding.DecodeString(pin)
+	if err != nil || len(b) != sha256.Size {
+		return nil, fmt.Errorf("invalid public key pin %q", pin)
+	}
+	return b, nil
+}
+
+// TLSConfig returns the tls.Config for c.
+func (c *HTTPSConfig) TLSConfig() (*tls.Config, error) {
+	conf := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(c.CAFiles) > 0 {
+		conf.RootCAs = x509.NewCertPool()
+		for _, f := range c.CAFiles {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			if !conf.RootCAs.AppendCertsFromPEM(b) {
+				return nil, fmt.Errorf("no certificates in %s", f)
+			}
+		}
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	if len(c.Pins) > 0 {
+		pins := make(map[[sha256.Size]byte]bool)
+		for _, p := range c.Pins {
+			b, err := parsePin(p)
+			if err != nil {
+				return nil, err
+			}
+			pins[[sha256.Size]byte(b)] = true
+		}
+		// Without CAs, the pins alone identify the server, so the
+		// usual chain and host name verification is skipped.
+		pinsOnly := conf.RootCAs == nil
+		conf.InsecureSkipVerify = pinsOnly
+		conf.VerifyConnection = func(cs tls.ConnectionState) error {
+			chains := cs.VerifiedChains
+			if pinsOnly && len(cs.PeerCertificates) > 0 {
+				chains = [][]*x509.Certificate{cs.PeerCertificates[:1]}
+			}
+			for _, chain := range chains {
+				for _, cert := range chain {
+					if pins[sha256.Sum256(cert.RawSubjectPublicKeyInfo)] {
+						return nil
+					}
+				}
+			}
+			return ErrPinMismatch
+		}
+	}
+	return conf, nil
+}
+
+// NewHTTPSClient returns an HTTP FileScheme whose TLS connections are
+// configured by c.
+func NewHTTPSClient(c *HTTPSConfig, opts ...HTTPClientOpt) (*HTTPClient, error) {
+	conf, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = conf
+	return NewHTTPClient(&http.Client{Transport: t}, opts...), nil
+}
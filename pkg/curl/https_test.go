@@ -0,0 +1,369 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package curl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/go-cmp/cmp"
+	"github.com/u-root/u-root/pkg/cmdline"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert, client bool) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if client {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	if parent == nil {
+		tmpl.ExtKeyUsage = nil
+	}
+	parentCert, parentKey := tmpl, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parentCert, key.Public(), parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: c, key: key}
+}
+
+// write writes the certificate and key as PEM files and returns their paths.
+func (c *testCert) write(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	certFile := filepath.Join(dir, c.cert.Subject.CommonName+".pem")
+	keyFile := filepath.Join(dir, c.cert.Subject.CommonName+".key")
+	key, err := x509.MarshalPKCS8PrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func (c *testCert) pin() string {
+	h := sha256.Sum256(c.cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(h[:])
+}
+
+func TestHTTPS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil, false)
+	server := newTestCert(t, "server", ca, false)
+	client := newTestCert(t, "client", ca, true)
+	other := newTestCert(t, "other", nil, false)
+	caFile, _ := ca.write(t, dir)
+	otherFile, _ := other.write(t, dir)
+	clientCert, clientKey := client.write(t, dir)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello %s", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	ts.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{server.cert.Raw, ca.cert.Raw},
+			PrivateKey:  server.key,
+		}},
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	ts.StartTLS()
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		c    *HTTPSConfig
+		err  bool
+	}{
+		{
+			name: "CA",
+			c:    &HTTPSConfig{CAFiles: []string{caFile}, CertFile: clientCert, KeyFile: clientKey},
+		},
+		{
+			name: "CA and pinned CA",
+			c:    &HTTPSConfig{CAFiles: []string{otherFile, caFile}, CertFile: clientCert, KeyFile: clientKey, Pins: []string{ca.pin()}},
+		},
+		{
+			name: "pinned server",
+			c:    &HTTPSConfig{CertFile: clientCert, KeyFile: clientKey, Pins: []string{other.pin(), server.pin()}},
+		},
+		{
+			name: "wrong CA",
+			c:    &HTTPSConfig{CAFiles: []string{otherFile}, CertFile: clientCert, KeyFile: clientKey},
+			err:  true,
+		},
+		{
+			name: "wrong pin",
+			c:    &HTTPSConfig{CAFiles: []string{caFile}, CertFile: clientCert, KeyFile: clientKey, Pins: []string{other.pin()}},
+			err:  true,
+		},
+		{
+			name: "pinned CA without CA",
+			c:    &HTTPSConfig{CertFile: clientCert, KeyFile: clientKey, Pins: []string{ca.pin()}},
+			err:  true,
+		},
+		{
+			name: "no client certificate",
+			c:    &HTTPSConfig{CAFiles: []string{caFile}},
+			err:  true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := NewHTTPSClient(tt.c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := Schemes{"https": h}.FetchWithoutCache(context.Background(), u)
+			if (err != nil) != tt.err {
+				t.Fatalf("Fetch = %v, want error %t", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			b, err := io.ReadAll(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := string(b), "hello client"; got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+
+	for _, c := range []*HTTPSConfig{
+		{CAFiles: []string{filepath.Join(dir, "client.key")}},
+		{CertFile: clientCert},
+		{Pins: []string{"sha256/tooshort"}},
+	} {
+		if _, err := c.TLSConfig(); err == nil {
+			t.Errorf("TLSConfig(%+v) = nil, want error", c)
+		}
+	}
+}
+
+func TestHTTPSConfigFromCmdline(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "https.json")
+	if err := os.WriteFile(conf, []byte(`{"ca": ["/ca.pem"], "cert": "/client.pem", "key": "/client.key"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		flags map[string]string
+		want  *HTTPSConfig
+		err   bool
+	}{
+		{flags: map[string]string{"console": "ttyS0"}},
+		{
+			flags: map[string]string{"uroot.https": conf},
+			want:  &HTTPSConfig{CAFiles: []string{"/ca.pem"}, CertFile: "/client.pem", KeyFile: "/client.key"},
+		},
+		{
+			flags: map[string]string{"uroot.https": conf, "uroot.https_ca": "/a.pem,/b.pem", "uroot.https_pin": "sha256/x"},
+			want:  &HTTPSConfig{CAFiles: []string{"/a.pem", "/b.pem"}, CertFile: "/client.pem", KeyFile: "/client.key", Pins: []string{"sha256/x"}},
+		},
+		{
+			flags: map[string]string{"uroot.https_cert": "/c.pem", "uroot.https_key": "/c.key"},
+			want:  &HTTPSConfig{CertFile: "/c.pem", KeyFile: "/c.key"},
+		},
+		{
+			flags: map[string]string{"uroot.https": filepath.Join(dir, "missing")},
+			err:   true,
+		},
+	} {
+		got, err := HTTPSConfigFromCmdline(&cmdline.CmdLine{AsMap: tt.flags})
+		if (err != nil) != tt.err {
+			t.Errorf("HTTPSConfigFromCmdline(%v) = %v, want error %t", tt.flags, err, tt.err)
+		}
+		if diff := cmp.Diff(tt.want, got); diff != "" {
+			t.Errorf("HTTPSConfigFromCmdline(%v) (-want +got):\n%s", tt.flags, diff)
+		}
+	}
+}
+
+// flakyHandler serves content, breaking the connection after every chunk
+// bytes.
+type flakyHandler struct {
+	content string
+	chunk   int
+	etag    string
+	ranges  []string
+}
+
+func (f *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.ranges = append(f.ranges, r.Header.Get("Range"))
+	start := 0
+	if rng := r.Header.Get("Range"); rng != "" && r.Header.Get("If-Range") == f.etag {
+		fmt.Sscanf(rng, "bytes=%d-", &start)
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(f.content)-1, len(f.content)))
+		w.Header().Set("Content-Length", fmt.Sprint(len(f.content)-start))
+		w.Header().Set("ETag", f.etag)
+		w.WriteHeader(http.StatusPartialContent)
+	} else {
+		w.Header().Set("Content-Length", fmt.Sprint(len(f.content)))
+		w.Header().Set("ETag", f.etag)
+	}
+	end := min(len(f.content), start+f.chunk)
+	io.WriteString(w, f.content[start:end])
+	if end < len(f.content) {
+		// Break the connection mid-body.
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}
+}
+
+func TestHTTPResume(t *testing.T) {
+	content := "0123456789abcdefghijklmnopqrstuvwxyz"
+	for _, tt := range []struct {
+		name    string
+		chunk   int
+		resumes int
+		etag    string
+		want    string
+		ranges  []string
+		err     bool
+	}{
+		{
+			name:    "resumed",
+			chunk:   10,
+			resumes: 5,
+			etag:    `"v1"`,
+			want:    content,
+			ranges:  []string{"", "bytes=10-", "bytes=20-", "bytes=30-"},
+		},
+		{
+			name:    "too many resumes",
+			chunk:   10,
+			resumes: 2,
+			etag:    `"v1"`,
+			want:    content[:30],
+			ranges:  []string{"", "bytes=10-", "bytes=20-"},
+			err:     true,
+		},
+		{
+			name:    "not resumed",
+			chunk:   10,
+			resumes: 0,
+			want:    content[:10],
+			ranges:  []string{""},
+			err:     true,
+		},
+		{
+			name:    "content changed",
+			chunk:   10,
+			resumes: 5,
+			// A weak ETag is not sent, so the server sends
+			// everything again.
+			etag:   `W/"v1"`,
+			want:   content[:10],
+			ranges: []string{"", "bytes=10-"},
+			err:    true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &flakyHandler{content: content, chunk: tt.chunk, etag: tt.etag}
+			ts := httptest.NewServer(f)
+			defer ts.Close()
+			u, err := url.Parse(ts.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			h := NewHTTPClient(http.DefaultClient, WithResumes(tt.resumes), WithResumeBackOff(func() backoff.BackOff {
+				return &backoff.ZeroBackOff{}
+			}))
+			r, err := h.FetchWithoutCache(context.Background(), u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if (err != nil) != tt.err {
+				t.Errorf("ReadAll = %v, want error %t", err, tt.err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if !cmp.Equal(f.ranges, tt.ranges) {
+				t.Errorf("got requests for ranges %q, want %q", f.ranges, tt.ranges)
+			}
+			if err := r.(io.Closer).Close(); err != nil {
+				t.Errorf("Close = %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+	u, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewHTTPClient(http.DefaultClient).FetchWithoutCache(context.Background(), u)
+	var herr *HTTPClientCodeError
+	if !errors.As(err, &herr) || herr.HTTPCode != http.StatusNotFound {
+		t.Errorf("Fetch = %v, want HTTP 404", err)
+	}
+}
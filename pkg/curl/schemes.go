@@ -4,7 +4,12 @@
 
 // Package curl implements routines to fetch files given a URL.
 //
-// curl currently supports HTTP, TFTP, and local files.
+// curl currently supports HTTP(S), TFTP, and local files.
+//
+// A URL may carry the digest of the file as fragment, e.g.
+// http://server/initramfs.cpio#sha256=HEX, which is verified when reading.
+//
+// Cache keeps fetched files on disk, to share them across boot attempts.
 package curl
 
 import (
@@ -46,6 +51,9 @@ type File interface {
 	fmt.Stringer
 	// URL is the file's original URL.
 	URL() *url.URL
+	// Close releases the file, such as an HTTP response body or a file
+	// in a Cache.
+	io.Closer
 }
 
 // FileWithCache is a io.ReaderAt with a nice stringer for file's original URL.
@@ -155,6 +163,15 @@ func (f cacheFile) String() string {
 	return f.url.String()
 }
 
+// Close implements io.Closer, closing the underlying file, e.g. a file in
+// a Cache.
+func (f cacheFile) Close() error {
+	if c, ok := f.ReaderAt.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 // file is an io.Reader with a nice Stringer.
 type file struct {
 	io.Reader
@@ -172,6 +189,15 @@ func (f file) String() string {
 	return f.url.String()
 }
 
+// Close implements io.Closer, closing the underlying reader, e.g. an HTTP
+// response body.
+func (f file) Close() error {
+	if c, ok := f.Reader.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 // Fetch fetchs the file with the given `u`. `u.Scheme` is used to
 // select the FileScheme via `s`.
 //
@@ -179,16 +205,23 @@ func (f file) String() string {
 // returned.
 //
 // Content is cached in memory as it reads.
+//
+// If `u` has a digest fragment such as #sha256=HEX, reading the file fails
+// with ErrDigestMismatch unless its content matches the digest.
 func (s Schemes) Fetch(ctx context.Context, u *url.URL) (FileWithCache, error) {
 	fg, ok := s[u.Scheme]
 	if !ok {
 		return nil, &URLError{URL: u, Err: ErrNoSuchScheme}
 	}
-	r, err := fg.Fetch(ctx, u)
+	fu, d, err := parseDigest(u)
 	if err != nil {
 		return nil, &URLError{URL: u, Err: err}
 	}
-	return &cacheFile{ReaderAt: r, url: u}, nil
+	r, err := fg.Fetch(withDigest(ctx, d), fu)
+	if err != nil {
+		return nil, &URLError{URL: u, Err: err}
+	}
+	return &cacheFile{ReaderAt: newDigestReaderAt(r, d), url: u}, nil
 }
 
 // FetchWithoutCache is same as Fetch, but returns a io.Reader of File that
@@ -198,11 +231,15 @@ func (s Schemes) FetchWithoutCache(ctx context.Context, u *url.URL) (FileWithout
 	if !ok {
 		return nil, &URLError{URL: u, Err: ErrNoSuchScheme}
 	}
-	r, err := fg.FetchWithoutCache(ctx, u)
+	fu, d, err := parseDigest(u)
+	if err != nil {
+		return nil, &URLError{URL: u, Err: err}
+	}
+	r, err := fg.FetchWithoutCache(withDigest(ctx, d), fu)
 	if err != nil {
 		return nil, &URLError{URL: u, Err: err}
 	}
-	return &file{Reader: r, url: u}, nil
+	return &file{Reader: newDigestReader(r, d), url: u}, nil
 }
 
 // LazyFetch calls LazyFetchWithoutCache on DefaultSchemes.
@@ -216,15 +253,19 @@ func (s Schemes) LazyFetchWithoutCache(u *url.URL) (FileWithoutCache, error) {
 	if !ok {
 		return nil, &URLError{URL: u, Err: ErrNoSuchScheme}
 	}
+	fu, d, err := parseDigest(u)
+	if err != nil {
+		return nil, &URLError{URL: u, Err: err}
+	}
 
 	return &file{
 		url: u,
 		Reader: uio.NewLazyOpener(u.String(), func() (io.Reader, error) {
-			r, err := fg.FetchWithoutCache(context.TODO(), u)
+			r, err := fg.FetchWithoutCache(withDigest(context.TODO(), d), fu)
 			if err != nil {
 				return nil, &URLError{URL: u, Err: err}
 			}
-			return r, nil
+			return newDigestReader(r, d), nil
 		}),
 	}, nil
 }
@@ -242,16 +283,20 @@ func (s Schemes) LazyFetch(u *url.URL) (FileWithCache, error) {
 	if !ok {
 		return nil, &URLError{URL: u, Err: ErrNoSuchScheme}
 	}
+	fu, d, err := parseDigest(u)
+	if err != nil {
+		return nil, &URLError{URL: u, Err: err}
+	}
 
 	return &cacheFile{
 		url: u,
 		ReaderAt: uio.NewLazyOpenerAt(u.String(), func() (io.ReaderAt, error) {
 			// TODO
-			r, err := fg.Fetch(context.TODO(), u)
+			r, err := fg.Fetch(withDigest(context.TODO(), d), fu)
 			if err != nil {
 				return nil, &URLError{URL: u, Err: err}
 			}
-			return r, nil
+			return newDigestReaderAt(r, d), nil
 		}),
 	}, nil
 }
@@ -410,34 +455,192 @@ func (h *HTTPClientCodeError) Unwrap() error {
 // HTTPClient implements FileScheme for HTTP files.
 type HTTPClient struct {
 	c *http.Client
+
+	// resumes is how often a download is resumed after a read error.
+	resumes int
+
+	// backOff returns how long to wait before resuming.
+	backOff func() backoff.BackOff
+}
+
+// DefaultResumes is how often HTTPClient resumes a download after a read
+// error by default.
+const DefaultResumes = 5
+
+// HTTPClientOpt is an optional argument to NewHTTPClient.
+type HTTPClientOpt func(*HTTPClient)
+
+// WithResumes sets how often a download is resumed with a Range request
+// after the connection fails. 0 disables resuming.
+func WithResumes(n int) HTTPClientOpt {
+	return func(h *HTTPClient) {
+		h.resumes = n
+	}
+}
+
+// WithResumeBackOff sets how long to wait before resuming a download. b is
+// called for every download.
+func WithResumeBackOff(b func() backoff.BackOff) HTTPClientOpt {
+	return func(h *HTTPClient) {
+		h.backOff = b
+	}
 }
 
 // NewHTTPClient returns a new HTTP FileScheme based on the given http.Client.
-func NewHTTPClient(c *http.Client) *HTTPClient {
-	return &HTTPClient{
-		c: c,
+//
+// Downloads interrupted by a read error are resumed DefaultResumes times
+// with exponential backoff, unless opts say otherwise.
+func NewHTTPClient(c *http.Client, opts ...HTTPClientOpt) *HTTPClient {
+	h := &HTTPClient{
+		c:       c,
+		resumes: DefaultResumes,
+		backOff: func() backoff.BackOff {
+			b := backoff.NewExponentialBackOff()
+			b.InitialInterval = 500 * time.Millisecond
+			b.MaxElapsedTime = time.Minute
+			return b
+		},
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-func httpFetch(ctx context.Context, c *http.Client, u *url.URL) (io.Reader, error) {
+func httpGet(ctx context.Context, c *http.Client, u *url.URL, header http.Header) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.Do(req)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return c.Do(req)
+}
+
+func httpFetch(ctx context.Context, h *HTTPClient, u *url.URL) (io.Reader, error) {
+	resp, err := httpGet(ctx, h.c, u, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode != 200 {
+		resp.Body.Close()
 		return nil, &HTTPClientCodeError{ErrStatusNotOk, resp.StatusCode}
 	}
-	return resp.Body, nil
+	if h.resumes == 0 {
+		return resp.Body, nil
+	}
+	r := &resumingReader{
+		ctx:     ctx,
+		h:       h,
+		u:       u,
+		body:    resp.Body,
+		resumes: h.resumes,
+	}
+	// If-Range only accepts strong validators.
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		r.validator = etag
+	} else {
+		r.validator = resp.Header.Get("Last-Modified")
+	}
+	return r, nil
+}
+
+// resumingReader reads an HTTP response body, resuming the download with a
+// Range request where it broke off when reading fails.
+type resumingReader struct {
+	ctx  context.Context
+	h    *HTTPClient
+	u    *url.URL
+	body io.ReadCloser
+
+	// off is the number of bytes read so far.
+	off int64
+
+	// validator is the ETag or Last-Modified date of the first
+	// response, which makes sure a resumed download is of the same
+	// content.
+	validator string
+
+	resumes int
+	backOff backoff.BackOff
+}
+
+// Read implements io.Reader.
+func (r *resumingReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.off += int64(n)
+		if err == nil || errors.Is(err, io.EOF) || r.resumes == 0 || r.ctx.Err() != nil {
+			return n, err
+		}
+		r.body.Close()
+		if rerr := r.resume(); rerr != nil {
+			return n, fmt.Errorf("%w (resuming: %w)", err, rerr)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Close implements io.Closer.
+func (r *resumingReader) Close() error {
+	return r.body.Close()
+}
+
+// resume requests the rest of the content after r.off.
+func (r *resumingReader) resume() error {
+	if r.backOff == nil {
+		r.backOff = backoff.WithContext(r.h.backOff(), r.ctx)
+	}
+	for r.resumes > 0 {
+		r.resumes--
+		d := r.backOff.NextBackOff()
+		if d == backoff.Stop {
+			break
+		}
+		time.Sleep(d)
+
+		log.Printf("Resuming %v at byte %d", r.u, r.off)
+		header := http.Header{"Range": {fmt.Sprintf("bytes=%d-", r.off)}}
+		if r.validator != "" {
+			header.Set("If-Range", r.validator)
+		}
+		resp, err := httpGet(r.ctx, r.h.c, r.u, header)
+		if err != nil {
+			log.Printf("Error: Resuming %v: %v", r.u, err)
+			continue
+		}
+		if resp.StatusCode != http.StatusPartialContent {
+			// The server does not support ranges, or the content
+			// changed. Starting over would duplicate what was read.
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return errors.New("server did not resume the download, content may have changed")
+			}
+			err := &HTTPClientCodeError{ErrStatusNotOk, resp.StatusCode}
+			if !RetryHTTP(r.u, err) {
+				return err
+			}
+			log.Printf("Error: Resuming %v: %v", r.u, err)
+			continue
+		}
+		var start int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start); err != nil || start != r.off {
+			resp.Body.Close()
+			return fmt.Errorf("server resumed at %q, want byte %d", resp.Header.Get("Content-Range"), r.off)
+		}
+		r.body = resp.Body
+		return nil
+	}
+	return errors.New("too many resumes")
 }
 
 // Fetch implements FileScheme.Fetch for HTTP.
 func (h HTTPClient) Fetch(ctx context.Context, u *url.URL) (io.ReaderAt, error) {
-	r, err := httpFetch(ctx, h.c, u)
+	r, err := httpFetch(ctx, &h, u)
 	if err != nil {
 		return nil, err
 	}
@@ -446,7 +649,7 @@ func (h HTTPClient) Fetch(ctx context.Context, u *url.URL) (io.ReaderAt, error)
 
 // FetchWithoutCache implements FileScheme.FetchWithoutCache for HTTP.
 func (h HTTPClient) FetchWithoutCache(ctx context.Context, u *url.URL) (io.Reader, error) {
-	return httpFetch(ctx, h.c, u)
+	return httpFetch(ctx, &h, u)
 }
 
 // RetryOr returns a DoRetry function that returns true if any one of fn return
@@ -354,3 +354,56 @@ func TestHttpFetches(t *testing.T) {
 		t.Errorf("got %s, want %s", got, c)
 	}
 }
+
+type closeScheme struct {
+	closed bool
+}
+
+func (c *closeScheme) Fetch(context.Context, *url.URL) (io.ReaderAt, error) {
+	return nil, errTest
+}
+
+func (c *closeScheme) FetchWithoutCache(context.Context, *url.URL) (io.Reader, error) {
+	return c, nil
+}
+
+func (c *closeScheme) Read([]byte) (int, error) {
+	return 0, io.EOF
+}
+
+func (c *closeScheme) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestFetchWithoutCacheClose(t *testing.T) {
+	for _, s := range []string{
+		"fake://host/file",
+		// The digest reader forwards Close, too.
+		"fake://host/file#sha256=e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	} {
+		for _, lazy := range []bool{false, true} {
+			c := &closeScheme{}
+			schemes := Schemes{"fake": c}
+			u, err := url.Parse(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var f FileWithoutCache
+			if lazy {
+				f, err = schemes.LazyFetchWithoutCache(u)
+			} else {
+				f, err = schemes.FetchWithoutCache(context.Background(), u)
+			}
+			if err != nil {
+				t.Fatalf("fetching %s = %v", u, err)
+			}
+			if _, err := io.ReadAll(f); err != nil {
+				t.Fatalf("reading %s = %v", u, err)
+			}
+			if err := f.Close(); err != nil || !c.closed {
+				t.Errorf("Close(%s, lazy %t) = %v, closed %t, want closed", u, lazy, err, c.closed)
+			}
+		}
+	}
+}
@@ -0,0 +1,733 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dhclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv4/nclient4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/insomniacslk/dhcp/dhcpv6/nclient6"
+	"github.com/insomniacslk/dhcp/iana"
+	"github.com/vishvananda/netlink"
+)
+
+// LeaseState is the state of a lease kept by a LeaseManager, as described in
+// RFC 2131 Section 4.4 and RFC 8415 Section 18.2.
+type LeaseState int
+
+// Lease states.
+const (
+	// StateBound means the lease was obtained, renewed or rebound.
+	StateBound LeaseState = iota
+	// StateRenewing means the lease is past T1 and is being renewed
+	// with the server that granted it.
+	StateRenewing
+	// StateRebinding means the lease is past T2 and is being extended by
+	// any server.
+	StateRebinding
+	// StateExpired means the lease ran out or was refused by the server,
+	// and its address was removed.
+	StateExpired
+	// StateReleased means the lease was given up on shutdown.
+	StateReleased
+)
+
+func (s LeaseState) String() string {
+	switch s {
+	case StateBound:
+		return "bound"
+	case StateRenewing:
+		return "renewing"
+	case StateRebinding:
+		return "rebinding"
+	case StateExpired:
+		return "expired"
+	case StateReleased:
+		return "released"
+	}
+	return fmt.Sprintf("unknown lease state (%d)", int(s))
+}
+
+// LeaseEvent is a change of state of a lease kept by a LeaseManager.
+type LeaseEvent struct {
+	// Interface is the name of the interface of the lease.
+	Interface string
+
+	// Protocol is either NetIPv4 or NetIPv6.
+	Protocol NetworkProtocol
+
+	// State is the state the lease entered.
+	State LeaseState
+
+	// Old is the lease before the change, and nil if a new lease was
+	// obtained.
+	Old Lease
+
+	// New is the lease after the change, and nil if the lease expired or
+	// was released.
+	New Lease
+}
+
+// AddressChanged returns whether the address of the interface changed.
+func (e LeaseEvent) AddressChanged() bool {
+	return !LeaseAddr(e.Old).Equal(LeaseAddr(e.New))
+}
+
+// LeaseHook is called by a LeaseManager on every LeaseEvent.
+//
+// Hooks of leases on different interfaces or protocols may be called
+// concurrently. A hook blocks the lease it is called for, so it should return
+// quickly.
+type LeaseHook func(LeaseEvent)
+
+// LeaseAddr returns the address assigned by l, or nil if there is none.
+func LeaseAddr(l Lease) net.IP {
+	switch p := l.(type) {
+	case *Packet4:
+		if p != nil {
+			return p.P.YourIPAddr
+		}
+	case *Packet6:
+		if p != nil {
+			if a := p.Lease(); a != nil {
+				return a.IPv6Addr
+			}
+		}
+	}
+	return nil
+}
+
+// leaseTimes are the times of a lease, relative to when it was obtained.
+type leaseTimes struct {
+	// T1 is when to start renewing the lease.
+	T1 time.Duration
+	// T2 is when to start rebinding the lease.
+	T2 time.Duration
+	// Valid is when the lease expires.
+	Valid time.Duration
+}
+
+// Default lease time for DHCPv4 servers that do not give one.
+const defaultLeaseTime = time.Hour
+
+// timesOf returns the times of l.
+//
+// Missing or inconsistent T1 and T2 default to 0.5 and 0.875 of the lease
+// time for DHCPv4 (RFC 2131 Section 4.4.5), and to 0.5 and 0.8 of the
+// preferred lifetime for DHCPv6 (RFC 8415 Section 21.4).
+func timesOf(l Lease) leaseTimes {
+	var t leaseTimes
+	var base time.Duration
+	var t2Frac float64
+	switch p := l.(type) {
+	case *Packet4:
+		t.Valid = p.P.IPAddressLeaseTime(defaultLeaseTime)
+		t.T1 = p.P.IPAddressRenewalTime(0)
+		t.T2 = p.P.IPAddressRebindingTime(0)
+		base, t2Frac = t.Valid, 0.875
+
+	case *Packet6:
+		if a := p.Lease(); a != nil {
+			t.Valid = a.ValidLifetime
+			base = a.PreferredLifetime
+		}
+		if ia := p.p.Options.OneIANA(); ia != nil {
+			t.T1, t.T2 = ia.T1, ia.T2
+		}
+		t2Frac = 0.8
+	}
+	if t.T1 == 0 {
+		t.T1 = base / 2
+	}
+	if t.T2 == 0 || t.T2 < t.T1 || t.T2 > t.Valid {
+		t.T2 = time.Duration(float64(base) * t2Frac)
+	}
+	if t.T1 > t.T2 {
+		t.T1 = t.T2
+	}
+	return t
+}
+
+// errNoBinding is returned by a leaseClient when the server refuses to
+// extend the lease.
+var errNoBinding = errors.New("server refused to extend the lease")
+
+// leaseClient exchanges the messages that keep a lease alive.
+type leaseClient interface {
+	// request obtains a new lease.
+	request(ctx context.Context) (Lease, error)
+
+	// renew extends l with the server that granted it.
+	renew(ctx context.Context, l Lease) (Lease, error)
+
+	// rebind extends l with any server.
+	rebind(ctx context.Context, l Lease) (Lease, error)
+
+	// release gives l back to its server.
+	release(ctx context.Context, l Lease) error
+}
+
+// clock is the time source of a LeaseManager.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+const (
+	// minRetry is the shortest time between two attempts to renew or
+	// rebind a lease (RFC 2131 Section 4.4.5).
+	minRetry = 60 * time.Second
+
+	// maxRequestBackoff is the longest time between two attempts to
+	// obtain a new lease after one expired.
+	maxRequestBackoff = 2 * time.Minute
+
+	// releaseTimeout is how long to try to release a lease on shutdown.
+	releaseTimeout = 5 * time.Second
+)
+
+// LeaseManager keeps leases alive: it renews them at T1, rebinds them at T2,
+// obtains new ones when they expire, and releases them when it is stopped.
+//
+// Every change is written to LeaseFile and passed to Hooks.
+type LeaseManager struct {
+	// Config is used for all DHCP exchanges.
+	Config Config
+
+	// LinkUpTimeout is how long to wait for an IPv6 link to be ready when
+	// a new lease is needed.
+	LinkUpTimeout time.Duration
+
+	// DryRun does not change the configuration of the interfaces.
+	DryRun bool
+
+	// LeaseFile, if set, is a file where the state of all leases is kept
+	// as a JSON object, keyed by interface and protocol, e.g. "eth0/IPv4".
+	LeaseFile string
+
+	// Hooks are called on every change of a lease.
+	Hooks []LeaseHook
+
+	// Release releases leases when the manager is stopped.
+	Release bool
+
+	// clock and newClient are replaced by tests.
+	clock     clock
+	newClient func(iface netlink.Link, p NetworkProtocol) leaseClient
+
+	mu     sync.Mutex
+	leases map[string]*LeaseRecord
+}
+
+// LeaseRecord is the state of a lease as written to the lease file.
+type LeaseRecord struct {
+	Interface string    `json:"interface"`
+	Protocol  string    `json:"protocol"`
+	State     string    `json:"state"`
+	Address   string    `json:"address,omitempty"`
+	Obtained  time.Time `json:"obtained,omitzero"`
+	Renew     time.Time `json:"renew,omitzero"`
+	Rebind    time.Time `json:"rebind,omitzero"`
+	Expires   time.Time `json:"expires,omitzero"`
+
+	// Message is the DHCP message that granted the lease.
+	Message []byte `json:"message,omitempty"`
+}
+
+// Manage configures the interfaces of all successful results, and keeps
+// their leases until ctx is done. It returns once all leases were released.
+func (m *LeaseManager) Manage(ctx context.Context, results <-chan *Result) {
+	var wg sync.WaitGroup
+	for r := range results {
+		if r.Err != nil {
+			log.Printf("Could not get %s lease on %s: %v", r.Protocol, r.Interface.Attrs().Name, r.Err)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Run(ctx, r.Lease)
+		}()
+	}
+	wg.Wait()
+}
+
+// Run configures the interface of l, and keeps l until ctx is done.
+func (m *LeaseManager) Run(ctx context.Context, l Lease) {
+	c := m.client(l)
+	obtained := m.now()
+	m.bind(nil, l, obtained)
+	for {
+		t := timesOf(l)
+		if !m.sleep(ctx, obtained.Add(t.T1)) {
+			m.stop(c, l)
+			return
+		}
+
+		m.event(StateRenewing, l, l, obtained)
+		nl, err := m.extend(ctx, obtained.Add(t.T2), l, c.renew)
+		if nl == nil && err == nil {
+			m.event(StateRebinding, l, l, obtained)
+			nl, err = m.extend(ctx, obtained.Add(t.Valid), l, c.rebind)
+		}
+		if ctx.Err() != nil {
+			m.stop(c, l)
+			return
+		}
+		if nl != nil {
+			obtained = m.now()
+			m.bind(l, nl, obtained)
+			l = nl
+			continue
+		}
+		if err != nil {
+			log.Printf("Lease %s on %s: %v", l, l.Link().Attrs().Name, err)
+		}
+
+		m.expire(l)
+		nl = m.request(ctx, c)
+		if nl == nil {
+			return
+		}
+		obtained = m.now()
+		m.bind(nil, nl, obtained)
+		l = nl
+	}
+}
+
+// extend tries to extend l with f until deadline, and returns the extended
+// lease. It returns nil and no error if the deadline passed, and nil and an
+// error if the server refused.
+func (m *LeaseManager) extend(ctx context.Context, deadline time.Time, l Lease, f func(context.Context, Lease) (Lease, error)) (Lease, error) {
+	for m.now().Before(deadline) {
+		nl, err := f(ctx, l)
+		if err == nil {
+			return nl, nil
+		}
+		if errors.Is(err, errNoBinding) || ctx.Err() != nil {
+			return nil, err
+		}
+		log.Printf("Could not extend %s on %s: %v", l, l.Link().Attrs().Name, err)
+
+		// Wait half the remaining time, but at least a minute.
+		left := deadline.Sub(m.now())
+		if !m.sleep(ctx, m.now().Add(min(max(left/2, minRetry), left))) {
+			return nil, nil
+		}
+	}
+	return nil, nil
+}
+
+// request obtains a new lease with c, backing off between attempts, until
+// ctx is done.
+func (m *LeaseManager) request(ctx context.Context, c leaseClient) Lease {
+	backoff := time.Second
+	for {
+		l, err := c.request(ctx)
+		if err == nil {
+			return l
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("Could not get a new lease: %v", err)
+		if !m.sleep(ctx, m.now().Add(backoff)) {
+			return nil
+		}
+		backoff = min(2*backoff, maxRequestBackoff)
+	}
+}
+
+// bind configures the interface with l, which replaces old.
+func (m *LeaseManager) bind(old, l Lease, obtained time.Time) {
+	if !m.DryRun {
+		if old != nil && !LeaseAddr(old).Equal(LeaseAddr(l)) {
+			if err := deconfigure(old); err != nil {
+				log.Printf("Could not remove %s: %v", old, err)
+			}
+		}
+		if err := l.Configure(); err != nil {
+			log.Printf("Could not configure %s: %v", l, err)
+		}
+	}
+	m.event(StateBound, old, l, obtained)
+}
+
+// expire removes the address of l.
+func (m *LeaseManager) expire(l Lease) {
+	if !m.DryRun {
+		if err := deconfigure(l); err != nil {
+			log.Printf("Could not remove %s: %v", l, err)
+		}
+	}
+	m.event(StateExpired, l, nil, time.Time{})
+}
+
+// stop releases l if m.Release is set.
+func (m *LeaseManager) stop(c leaseClient, l Lease) {
+	if !m.Release {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := c.release(ctx, l); err != nil {
+		log.Printf("Could not release %s: %v", l, err)
+	}
+	if !m.DryRun {
+		if err := deconfigure(l); err != nil {
+			log.Printf("Could not remove %s: %v", l, err)
+		}
+	}
+	m.event(StateReleased, l, nil, time.Time{})
+}
+
+// event records the state of a lease and calls the hooks.
+func (m *LeaseManager) event(s LeaseState, old, l Lease, obtained time.Time) {
+	cur := l
+	if cur == nil {
+		cur = old
+	}
+	e := LeaseEvent{
+		Interface: cur.Link().Attrs().Name,
+		Protocol:  protocolOf(cur),
+		State:     s,
+		Old:       old,
+		New:       l,
+	}
+	if s != StateRenewing && s != StateRebinding {
+		log.Printf("Lease on %s %s: %s", e.Interface, s, cur)
+	}
+
+	rec := &LeaseRecord{
+		Interface: e.Interface,
+		Protocol:  e.Protocol.String(),
+		State:     s.String(),
+	}
+	if l != nil {
+		t := timesOf(l)
+		rec.Address = LeaseAddr(l).String()
+		rec.Obtained = obtained
+		rec.Renew = obtained.Add(t.T1)
+		rec.Rebind = obtained.Add(t.T2)
+		rec.Expires = obtained.Add(t.Valid)
+		switch p4, p6 := l.Message(); {
+		case p4 != nil:
+			rec.Message = p4.ToBytes()
+		case p6 != nil:
+			rec.Message = p6.ToBytes()
+		}
+	}
+	if err := m.record(rec); err != nil {
+		log.Printf("Could not write lease file: %v", err)
+	}
+
+	for _, h := range m.Hooks {
+		h(e)
+	}
+}
+
+// record writes rec to the lease file.
+func (m *LeaseManager) record(rec *LeaseRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.leases == nil {
+		m.leases = make(map[string]*LeaseRecord)
+	}
+	m.leases[rec.Interface+"/"+rec.Protocol] = rec
+	if m.LeaseFile == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(m.leases, "", "\t")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(m.LeaseFile)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".leases")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), m.LeaseFile)
+}
+
+// sleep waits until t, and returns false if ctx was done first.
+func (m *LeaseManager) sleep(ctx context.Context, t time.Time) bool {
+	d := t.Sub(m.now())
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-m.getClock().After(d):
+		return true
+	}
+}
+
+func (m *LeaseManager) getClock() clock {
+	if m.clock == nil {
+		return realClock{}
+	}
+	return m.clock
+}
+
+func (m *LeaseManager) now() time.Time {
+	return m.getClock().Now()
+}
+
+func (m *LeaseManager) client(l Lease) leaseClient {
+	p := protocolOf(l)
+	if m.newClient != nil {
+		return m.newClient(l.Link(), p)
+	}
+	if p == NetIPv4 {
+		return &client4{iface: l.Link(), c: m.Config}
+	}
+	return &client6{iface: l.Link(), c: m.Config, linkUpTimeout: m.LinkUpTimeout}
+}
+
+func protocolOf(l Lease) NetworkProtocol {
+	if _, ok := l.(*Packet6); ok {
+		return NetIPv6
+	}
+	return NetIPv4
+}
+
+// deconfigure removes the address of l from its interface.
+func deconfigure(l Lease) error {
+	var ipnet *net.IPNet
+	switch p := l.(type) {
+	case *Packet4:
+		ipnet = p.Lease()
+	case *Packet6:
+		a := p.Lease()
+		if a == nil {
+			return nil
+		}
+		ipnet = &net.IPNet{IP: a.IPv6Addr, Mask: net.CIDRMask(128, 128)}
+	default:
+		return fmt.Errorf("unknown lease type %T", l)
+	}
+	err := netlink.AddrDel(l.Link(), &netlink.Addr{IPNet: ipnet})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// client4 keeps DHCPv4 leases alive.
+type client4 struct {
+	iface netlink.Link
+	c     Config
+}
+
+func (c *client4) request(ctx context.Context) (Lease, error) {
+	return lease4(ctx, c.iface, c.c)
+}
+
+func (c *client4) renew(ctx context.Context, l Lease) (Lease, error) {
+	return c.extend(ctx, l.(*Packet4), true)
+}
+
+func (c *client4) rebind(ctx context.Context, l Lease) (Lease, error) {
+	return c.extend(ctx, l.(*Packet4), false)
+}
+
+// extend sends a DHCPREQUEST for l, either to its server or broadcast.
+func (c *client4) extend(ctx context.Context, l *Packet4, unicast bool) (Lease, error) {
+	server := l.P.ServerIdentifier()
+	mods := c.opts()
+	dest := &net.UDPAddr{IP: net.IPv4bcast, Port: nclient4.ServerPort}
+	if unicast && server != nil {
+		dest = &net.UDPAddr{IP: server, Port: nclient4.ServerPort}
+		mods = append(mods,
+			nclient4.WithUnicast(&net.UDPAddr{IP: l.P.YourIPAddr, Port: nclient4.ClientPort}),
+			nclient4.WithServerAddr(dest))
+	} else if c.c.V4ServerAddr != nil {
+		dest = c.c.V4ServerAddr
+	}
+	client, err := nclient4.New(c.iface.Attrs().Name, mods...)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	req, err := dhcpv4.NewRenewFromAck(l.P, c.c.Modifiers4...)
+	if err != nil {
+		return nil, err
+	}
+	match := nclient4.IsMessageType(dhcpv4.MessageTypeAck, dhcpv4.MessageTypeNak)
+	if unicast && server != nil {
+		match = nclient4.IsAll(nclient4.IsCorrectServer(server), match)
+	}
+	ack, err := client.SendAndRead(ctx, dest, req, match)
+	if err != nil {
+		return nil, err
+	}
+	if ack.MessageType() == dhcpv4.MessageTypeNak {
+		return nil, fmt.Errorf("%w: DHCPNAK from %s: %s", errNoBinding, ack.ServerIdentifier(), ack.Message())
+	}
+
+	// Some servers leave options out of renewal ACKs, keep those of the
+	// lease being renewed.
+	for code, v := range l.P.Options {
+		if _, ok := ack.Options[code]; !ok {
+			ack.Options[code] = v
+		}
+	}
+	return NewPacket4(c.iface, ack), nil
+}
+
+func (c *client4) release(ctx context.Context, l Lease) error {
+	client, err := nclient4.New(c.iface.Attrs().Name, c.opts()...)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Release(&nclient4.Lease{ACK: l.(*Packet4).P}, c.c.Modifiers4...)
+}
+
+func (c *client4) opts() []nclient4.ClientOpt {
+	mods := []nclient4.ClientOpt{
+		nclient4.WithTimeout(c.c.Timeout),
+		nclient4.WithRetry(c.c.Retries),
+	}
+	switch c.c.LogLevel {
+	case LogSummary:
+		mods = append(mods, nclient4.WithSummaryLogger())
+	case LogDebug:
+		mods = append(mods, nclient4.WithDebugLogger())
+	}
+	return mods
+}
+
+// client6 keeps DHCPv6 IA_NA leases alive.
+type client6 struct {
+	iface         netlink.Link
+	c             Config
+	linkUpTimeout time.Duration
+}
+
+func (c *client6) request(ctx context.Context) (Lease, error) {
+	return lease6(ctx, c.iface, c.c, c.linkUpTimeout)
+}
+
+func (c *client6) renew(ctx context.Context, l Lease) (Lease, error) {
+	return c.extend(ctx, l.(*Packet6), dhcpv6.MessageTypeRenew)
+}
+
+func (c *client6) rebind(ctx context.Context, l Lease) (Lease, error) {
+	return c.extend(ctx, l.(*Packet6), dhcpv6.MessageTypeRebind)
+}
+
+func (c *client6) release(ctx context.Context, l Lease) error {
+	_, err := c.exchange(ctx, l.(*Packet6), dhcpv6.MessageTypeRelease)
+	return err
+}
+
+// extend sends a Renew or Rebind for l (RFC 8415 Sections 18.2.4 and
+// 18.2.5).
+func (c *client6) extend(ctx context.Context, l *Packet6, t dhcpv6.MessageType) (Lease, error) {
+	reply, err := c.exchange(ctx, l, t)
+	if err != nil {
+		return nil, err
+	}
+	if s := reply.Options.Status(); s != nil && s.StatusCode != iana.StatusSuccess {
+		return nil, fmt.Errorf("%w: %s", errNoBinding, s)
+	}
+	ia := reply.Options.OneIANA()
+	if ia == nil {
+		return nil, fmt.Errorf("%w: no IA_NA in reply", errNoBinding)
+	}
+	if s := ia.Options.Status(); s != nil && s.StatusCode != iana.StatusSuccess {
+		return nil, fmt.Errorf("%w: %s", errNoBinding, s)
+	}
+	if a := ia.Options.OneAddress(); a == nil || a.ValidLifetime == 0 {
+		return nil, fmt.Errorf("%w: address not extended", errNoBinding)
+	}
+
+	// Replies to renewals need not repeat the other options of the lease.
+	for _, o := range l.p.Options.Options {
+		if reply.GetOneOption(o.Code()) == nil {
+			reply.AddOption(o)
+		}
+	}
+	return NewPacket6(c.iface, reply), nil
+}
+
+// exchange sends a message of type t for l and returns the reply.
+func (c *client6) exchange(ctx context.Context, l *Packet6, t dhcpv6.MessageType) (*dhcpv6.Message, error) {
+	msg, err := dhcpv6.NewMessage(c.c.Modifiers6...)
+	if err != nil {
+		return nil, err
+	}
+	msg.MessageType = t
+	msg.UpdateOption(dhcpv6.OptClientID(l.p.Options.ClientID()))
+	if t != dhcpv6.MessageTypeRebind {
+		msg.UpdateOption(dhcpv6.OptServerID(l.p.Options.ServerID()))
+	}
+	msg.UpdateOption(dhcpv6.OptElapsedTime(0))
+	if ia := l.p.Options.OneIANA(); ia != nil {
+		msg.UpdateOption(ia)
+	}
+
+	clientPort := dhcpv6.DefaultClientPort
+	if c.c.V6ClientPort != nil {
+		clientPort = *c.c.V6ClientPort
+	}
+	conn, err := nclient6.NewIPv6UDPConn(c.iface.Attrs().Name, clientPort)
+	if err != nil {
+		return nil, err
+	}
+	mods := []nclient6.ClientOpt{
+		nclient6.WithTimeout(c.c.Timeout),
+		nclient6.WithRetry(c.c.Retries),
+	}
+	switch c.c.LogLevel {
+	case LogSummary:
+		mods = append(mods, nclient6.WithSummaryLogger())
+	case LogDebug:
+		mods = append(mods, nclient6.WithDebugLogger())
+	}
+	client, err := nclient6.NewWithConn(conn, c.iface.Attrs().HardwareAddr, mods...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	defer client.Close()
+
+	dest := &net.UDPAddr{IP: dhcpv6.AllDHCPRelayAgentsAndServers, Port: dhcpv6.DefaultServerPort}
+	if c.c.V6ServerAddr != nil {
+		dest = c.c.V6ServerAddr
+	}
+	return client.SendAndRead(ctx, dest, msg, nclient6.IsMessageType(dhcpv6.MessageTypeReply))
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dhclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/insomniacslk/dhcp/dhcpv6"
+	"github.com/vishvananda/netlink"
+)
+
+// fakeClock advances whenever it is waited on.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+type step struct {
+	method string
+	lease  Lease
+	err    error
+}
+
+// fakeClient plays back steps, and cancels the manager once they run out.
+type fakeClient struct {
+	t      *testing.T
+	clock  *fakeClock
+	start  time.Time
+	steps  []step
+	calls  []string
+	cancel func()
+}
+
+func (c *fakeClient) call(method string) (Lease, error) {
+	c.calls = append(c.calls, fmt.Sprintf("%s@%v", method, c.clock.now.Sub(c.start)))
+	if len(c.steps) == 0 {
+		c.cancel()
+		return nil, context.Canceled
+	}
+	s := c.steps[0]
+	c.steps = c.steps[1:]
+	if s.method != method {
+		c.t.Errorf("got %s, want %s", method, s.method)
+	}
+	return s.lease, s.err
+}
+
+func (c *fakeClient) request(context.Context) (Lease, error) {
+	return c.call("request")
+}
+
+func (c *fakeClient) renew(context.Context, Lease) (Lease, error) {
+	return c.call("renew")
+}
+
+func (c *fakeClient) rebind(context.Context, Lease) (Lease, error) {
+	return c.call("rebind")
+}
+
+func (c *fakeClient) release(context.Context, Lease) error {
+	c.calls = append(c.calls, fmt.Sprintf("release@%v", c.clock.now.Sub(c.start)))
+	return nil
+}
+
+var testLink = &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}}
+
+func lease4WithTime(t *testing.T, ip string, leaseTime uint32) *Packet4 {
+	return NewPacket4(testLink, mustNew(t,
+		dhcpv4.WithYourIP(net.ParseIP(ip)),
+		dhcpv4.WithLeaseTime(leaseTime),
+	))
+}
+
+func TestLeaseManager(t *testing.T) {
+	errTimeout := errors.New("timeout")
+	for _, tt := range []struct {
+		name       string
+		steps      []step
+		wantCalls  []string
+		wantStates []LeaseState
+		wantAddr   string
+	}{
+		{
+			name:       "renew",
+			steps:      []step{{method: "renew", lease: lease4WithTime(t, "10.0.0.5", 1000)}},
+			wantCalls:  []string{"renew@8m20s", "renew@16m40s", "release@16m40s"},
+			wantStates: []LeaseState{StateBound, StateRenewing, StateBound, StateRenewing, StateReleased},
+			wantAddr:   "10.0.0.5",
+		},
+		{
+			name: "rebind",
+			steps: []step{
+				{method: "renew", err: errTimeout},
+				{method: "renew", err: errTimeout},
+				{method: "renew", err: errTimeout},
+				{method: "renew", err: errTimeout},
+				{method: "rebind", lease: lease4WithTime(t, "10.0.0.5", 1000)},
+			},
+			wantCalls: []string{
+				"renew@8m20s", "renew@11m27.5s", "renew@13m1.25s", "renew@14m1.25s",
+				"rebind@14m35s",
+				"renew@22m55s", "release@22m55s",
+			},
+			wantStates: []LeaseState{StateBound, StateRenewing, StateRebinding, StateBound, StateRenewing, StateReleased},
+			wantAddr:   "10.0.0.5",
+		},
+		{
+			name: "nak",
+			steps: []step{
+				{method: "renew", err: errNoBinding},
+				{method: "request", lease: lease4WithTime(t, "10.0.0.6", 1000)},
+			},
+			wantCalls:  []string{"renew@8m20s", "request@8m20s", "renew@16m40s", "release@16m40s"},
+			wantStates: []LeaseState{StateBound, StateRenewing, StateExpired, StateBound, StateRenewing, StateReleased},
+			wantAddr:   "10.0.0.6",
+		},
+		{
+			name: "expire",
+			steps: []step{
+				{method: "renew", err: errTimeout},
+				{method: "renew", err: errTimeout},
+				{method: "renew", err: errTimeout},
+				{method: "renew", err: errTimeout},
+				{method: "rebind", err: errTimeout},
+				{method: "rebind", err: errTimeout},
+				{method: "rebind", err: errTimeout},
+				{method: "request", err: errTimeout},
+				{method: "request", lease: lease4WithTime(t, "10.0.0.7", 1000)},
+			},
+			wantCalls: []string{
+				"renew@8m20s", "renew@11m27.5s", "renew@13m1.25s", "renew@14m1.25s",
+				"rebind@14m35s", "rebind@15m37.5s", "rebind@16m37.5s",
+				"request@16m40s", "request@16m41s",
+				"renew@25m1s", "release@25m1s",
+			},
+			wantStates: []LeaseState{StateBound, StateRenewing, StateRebinding, StateExpired, StateBound, StateRenewing, StateReleased},
+			wantAddr:   "10.0.0.7",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+			c := &fakeClient{t: t, clock: clock, start: clock.now, steps: tt.steps, cancel: cancel}
+			var states []LeaseState
+			var lastBound LeaseEvent
+			leaseFile := filepath.Join(t.TempDir(), "leases.json")
+			m := &LeaseManager{
+				DryRun:    true,
+				LeaseFile: leaseFile,
+				Release:   true,
+				Hooks: []LeaseHook{func(e LeaseEvent) {
+					states = append(states, e.State)
+					if e.State == StateBound {
+						lastBound = e
+					}
+				}},
+				clock:     clock,
+				newClient: func(netlink.Link, NetworkProtocol) leaseClient { return c },
+			}
+			m.Run(ctx, lease4WithTime(t, "10.0.0.5", 1000))
+
+			if !reflect.DeepEqual(c.calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", c.calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(states, tt.wantStates) {
+				t.Errorf("states = %v, want %v", states, tt.wantStates)
+			}
+			if got := LeaseAddr(lastBound.New).String(); got != tt.wantAddr {
+				t.Errorf("last bound address = %s, want %s", got, tt.wantAddr)
+			}
+			if tt.wantAddr != "10.0.0.5" && !lastBound.AddressChanged() {
+				t.Errorf("AddressChanged() = false, want true")
+			}
+
+			b, err := os.ReadFile(leaseFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var leases map[string]*LeaseRecord
+			if err := json.Unmarshal(b, &leases); err != nil {
+				t.Fatal(err)
+			}
+			if r := leases["eth0/IPv4"]; r == nil || r.State != "released" {
+				t.Errorf("lease file = %s, want eth0/IPv4 released", b)
+			}
+		})
+	}
+}
+
+func TestTimesOf(t *testing.T) {
+	iaAddr := &dhcpv6.OptIAAddress{
+		IPv6Addr:          net.ParseIP("fd00::5"),
+		PreferredLifetime: 1000 * time.Second,
+		ValidLifetime:     2000 * time.Second,
+	}
+	iana := func(t1, t2 time.Duration) *Packet6 {
+		m, err := dhcpv6.NewMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.MessageType = dhcpv6.MessageTypeReply
+		m.AddOption(&dhcpv6.OptIANA{T1: t1, T2: t2, Options: dhcpv6.IdentityOptions{Options: dhcpv6.Options{iaAddr}}})
+		return NewPacket6(testLink, m)
+	}
+
+	for _, tt := range []struct {
+		name string
+		l    Lease
+		want leaseTimes
+	}{
+		{
+			name: "v4 defaults",
+			l:    lease4WithTime(t, "10.0.0.5", 1000),
+			want: leaseTimes{T1: 500 * time.Second, T2: 875 * time.Second, Valid: 1000 * time.Second},
+		},
+		{
+			name: "v4 explicit",
+			l: NewPacket4(testLink, mustNew(t,
+				dhcpv4.WithLeaseTime(1000),
+				dhcpv4.WithOption(dhcpv4.OptGeneric(dhcpv4.OptionRenewTimeValue, []byte{0, 0, 0, 100})),
+				dhcpv4.WithOption(dhcpv4.OptGeneric(dhcpv4.OptionRebindingTimeValue, []byte{0, 0, 0, 200})),
+			)),
+			want: leaseTimes{T1: 100 * time.Second, T2: 200 * time.Second, Valid: 1000 * time.Second},
+		},
+		{
+			name: "v6 defaults",
+			l:    iana(0, 0),
+			want: leaseTimes{T1: 500 * time.Second, T2: 800 * time.Second, Valid: 2000 * time.Second},
+		},
+		{
+			name: "v6 explicit",
+			l:    iana(300*time.Second, 600*time.Second),
+			want: leaseTimes{T1: 300 * time.Second, T2: 600 * time.Second, Valid: 2000 * time.Second},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := timesOf(tt.l); got != tt.want {
+				t.Errorf("timesOf = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
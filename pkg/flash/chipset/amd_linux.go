@@ -0,0 +1,288 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chipset
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/u-root/u-root/pkg/flash"
+	"github.com/u-root/u-root/pkg/flash/chips"
+	"github.com/u-root/u-root/pkg/flash/op"
+	"github.com/u-root/u-root/pkg/spidev"
+)
+
+// AMD SPI100 registers.
+const (
+	regSPICntrl0   = 0x00
+	regCmdCode     = 0x45
+	regCmdTrigger  = 0x47
+	regTxByteCount = 0x48
+	regRxByteCount = 0x4b
+	regSPIStatus   = 0x4c
+	regFIFO        = 0x80
+
+	// fifoSize is the size of the FIFO, shared by the bytes sent after
+	// the opcode and the bytes received.
+	fifoSize = 71
+
+	cmdExecute   = 1 << 7
+	statusBusy   = 1 << 31
+	cntrl0MacRom = 1 << 22
+	cntrl0Host   = 1 << 23
+)
+
+// ErrTooLong is returned for SPI transactions that do not fit the FIFO
+// and cannot be split.
+var ErrTooLong = errors.New("SPI transaction does not fit the controller FIFO")
+
+// AMD drives an AMD SPI100 controller. Unlike Intel's hardware sequencer,
+// it executes arbitrary SPI commands, so AMD implements flash.SPI and the
+// flash itself is driven by pkg/flash.
+type AMD struct {
+	regs Registers
+
+	// Timeout bounds each command. It defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// Is4BA says whether flash addresses are 4 bytes long, which is
+	// needed to split reads and page programs. It follows Enter4BA and
+	// Exit4BA commands, and should be set from flash.Flash.Is4BA.
+	Is4BA bool
+}
+
+// NewAMD returns an AMD controller using regs.
+func NewAMD(regs Registers) *AMD {
+	return &AMD{regs: regs, Timeout: DefaultTimeout}
+}
+
+// Locked returns whether the chipset blocks the host from accessing the
+// flash: either SpiAccessMacRomEn or SpiHostAccessRomEn is clear.
+func (a *AMD) Locked() (bool, error) {
+	v, err := read32(a.regs, regSPICntrl0)
+	if err != nil {
+		return false, err
+	}
+	return v&cntrl0MacRom == 0 || v&cntrl0Host == 0, nil
+}
+
+func (a *AMD) wait() error {
+	return poll(a.Timeout, func() (bool, error) {
+		v, err := read32(a.regs, regSPIStatus)
+		return v&statusBusy == 0, err
+	})
+}
+
+// command executes a single SPI command: opcode and tx are sent, then
+// len(rx) bytes are received.
+func (a *AMD) command(opcode byte, tx, rx []byte) error {
+	if len(tx)+len(rx) > fifoSize {
+		return fmt.Errorf("opcode %#02x with %d bytes out and %d in: %w", opcode, len(tx), len(rx), ErrTooLong)
+	}
+	if err := a.wait(); err != nil {
+		return err
+	}
+	for _, w := range []struct {
+		reg int64
+		v   uint8
+	}{
+		{regCmdCode, opcode},
+		{regTxByteCount, uint8(len(tx))},
+		{regRxByteCount, uint8(len(rx))},
+	} {
+		if err := write8(a.regs, w.reg, w.v); err != nil {
+			return err
+		}
+	}
+	for i, b := range tx {
+		if err := write8(a.regs, regFIFO+int64(i), b); err != nil {
+			return err
+		}
+	}
+	if err := write8(a.regs, regCmdTrigger, cmdExecute); err != nil {
+		return err
+	}
+	if err := a.wait(); err != nil {
+		return fmt.Errorf("opcode %#02x: %w", opcode, err)
+	}
+	// Received bytes follow the sent ones in the FIFO.
+	for i := range rx {
+		b, err := read8(a.regs, regFIFO+int64(len(tx)+i))
+		if err != nil {
+			return err
+		}
+		rx[i] = b
+	}
+	return nil
+}
+
+// transaction is one SPI command, i.e. everything sent while the chip is
+// selected.
+type transaction struct {
+	tx []byte
+	rx [][]byte
+}
+
+// Transfer implements flash.SPI.
+//
+// The controller is half duplex and runs one command per chip select.
+// Every transfer with Tx starts a new command, and transfers with only Rx
+// receive the response of the current one, which is how pkg/flash issues
+// commands. Reads and page programs larger than the FIFO are split.
+func (a *AMD) Transfer(transfers []spidev.Transfer) error {
+	var ts []transaction
+	for _, t := range transfers {
+		switch {
+		case len(t.Tx) > 0 && len(t.Rx) > 0:
+			return fmt.Errorf("full duplex transfers are not supported")
+		case len(t.Tx) > 0:
+			ts = append(ts, transaction{tx: t.Tx})
+		case len(t.Rx) > 0:
+			if len(ts) == 0 {
+				return fmt.Errorf("receive without a command")
+			}
+			ts[len(ts)-1].rx = append(ts[len(ts)-1].rx, t.Rx)
+		}
+	}
+	for _, t := range ts {
+		if err := a.transaction(&t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *AMD) transaction(t *transaction) error {
+	opcode, tx := op.OpCode(t.tx[0]), t.tx[1:]
+	var rxLen int
+	for _, r := range t.rx {
+		rxLen += len(r)
+	}
+	rx := make([]byte, rxLen)
+	defer func() {
+		for _, r := range t.rx {
+			rx = rx[copy(r, rx):]
+		}
+	}()
+
+	switch opcode {
+	case op.Enter4BA:
+		a.Is4BA = true
+	case op.Exit4BA:
+		a.Is4BA = false
+	}
+	if len(tx)+len(rx) <= fifoSize {
+		return a.command(byte(opcode), tx, rx)
+	}
+
+	switch opcode {
+	case op.Read, op.ReadSFDP:
+		// tx is the address, plus a dummy byte for ReadSFDP.
+		return a.chunkedRead(opcode, tx, rx)
+	case op.PageProgram:
+		if len(t.rx) == 0 && len(tx) > a.addrLen(opcode) {
+			return a.chunkedProgram(tx)
+		}
+	}
+	return fmt.Errorf("%v with %d bytes out and %d in: %w", opcode, len(tx), len(rx), ErrTooLong)
+}
+
+// addrLen returns the number of address bytes sent with opcode.
+func (a *AMD) addrLen(opcode op.OpCode) int {
+	if opcode != op.ReadSFDP && a.Is4BA {
+		return 4
+	}
+	return 3
+}
+
+func putAddr(b []byte, addr int64) {
+	for i := range b {
+		b[len(b)-1-i] = byte(addr >> (8 * i))
+	}
+}
+
+func getAddr(b []byte) int64 {
+	var addr int64
+	for _, c := range b {
+		addr = addr<<8 | int64(c)
+	}
+	return addr
+}
+
+func (a *AMD) chunkedRead(opcode op.OpCode, tx, rx []byte) error {
+	n := a.addrLen(opcode)
+	if len(tx) < n {
+		return fmt.Errorf("%v needs a %d byte address, got %d bytes", opcode, n, len(tx))
+	}
+	hdr := append([]byte(nil), tx...)
+	addr := getAddr(hdr[:n])
+	chunk := fifoSize - len(hdr)
+	for i := 0; i < len(rx); i += chunk {
+		putAddr(hdr[:n], addr+int64(i))
+		if err := a.command(byte(opcode), hdr, rx[i:min(i+chunk, len(rx))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkedProgram splits a page program. Every chunk after the first needs
+// a new write enable, and the previous chunk must have completed.
+func (a *AMD) chunkedProgram(tx []byte) error {
+	n := a.addrLen(op.PageProgram)
+	addr, data := getAddr(tx[:n]), tx[n:]
+	chunk := fifoSize - n
+	for i := 0; i < len(data); i += chunk {
+		if i > 0 {
+			if err := a.waitWIP(); err != nil {
+				return err
+			}
+			if err := a.command(byte(op.WriteEnable), nil, nil); err != nil {
+				return err
+			}
+		}
+		hdr := make([]byte, n, n+chunk)
+		putAddr(hdr, addr+int64(i))
+		if err := a.command(byte(op.PageProgram), append(hdr, data[i:min(i+chunk, len(data))]...), nil); err != nil {
+			return err
+		}
+	}
+	return a.waitWIP()
+}
+
+// waitWIP waits for the flash to finish a program or erase.
+func (a *AMD) waitWIP() error {
+	return poll(a.Timeout, func() (bool, error) {
+		s, err := a.Status()
+		return !s.Busy(), err
+	})
+}
+
+// ID implements flash.SPI.
+func (a *AMD) ID() (chips.ID, error) {
+	var b [3]byte
+	if err := a.command(byte(op.ReadJEDECID), nil, b[:]); err != nil {
+		return 0, err
+	}
+	return chips.ID(b[0])<<16 | chips.ID(b[1])<<8 | chips.ID(b[2]), nil
+}
+
+// Status implements flash.SPI.
+func (a *AMD) Status() (op.Status, error) {
+	var b [1]byte
+	err := a.command(byte(op.ReadStatus), nil, b[:])
+	return op.Status(b[0]), err
+}
+
+// Flash returns the flash chip behind the controller.
+func (a *AMD) Flash() (*flash.Flash, error) {
+	f, err := flash.New(a)
+	if err != nil {
+		return nil, err
+	}
+	a.Is4BA = f.Is4BA
+	return f, nil
+}
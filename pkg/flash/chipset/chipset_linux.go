@@ -0,0 +1,201 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chipset drives the SPI controllers built into x86 chipsets, which
+// is where the boot flash of most x86 machines is attached. It supports
+// Intel PCH hardware sequencing (100 series and later) and the AMD SPI100
+// controller (family 17h and later).
+//
+// The controllers are found with pkg/pci and their registers (SPIBAR) are
+// accessed through pkg/memio.
+//
+// Useful references:
+//   - Intel 300 Series Chipset PCH datasheet, vol. 2, "SPI Interface"
+//   - AMD PPR for family 17h model 31h, "SPI Controller"
+//   - https://github.com/flashrom/flashrom/blob/main/ichspi.c
+//   - https://github.com/flashrom/flashrom/blob/main/amd_spi100.c
+package chipset
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/u-root/u-root/pkg/memio"
+	"github.com/u-root/u-root/pkg/pci"
+)
+
+var (
+	// ErrNotFound is returned when no supported SPI controller is found.
+	ErrNotFound = errors.New("no supported chipset SPI controller found")
+
+	// ErrTimeout is returned when the controller does not finish a cycle
+	// in time.
+	ErrTimeout = errors.New("SPI controller timed out")
+)
+
+// DefaultTimeout is how long to wait for a single flash cycle. Erasing a
+// 64 KiB block can take close to a second on slow parts.
+const DefaultTimeout = 3 * time.Second
+
+// Registers gives access to the memory mapped registers of a controller.
+// Offsets are relative to SPIBAR. *memio.MMap has the same methods, but
+// with absolute addresses; see NewMMIO.
+type Registers interface {
+	ReadAt(off int64, data memio.UintN) error
+	WriteAt(off int64, data memio.UintN) error
+}
+
+// MMIO is a register window at a physical address.
+type MMIO struct {
+	m    *memio.MMap
+	base int64
+}
+
+// NewMMIO maps the registers at physical address base through /dev/mem.
+func NewMMIO(base int64) (*MMIO, error) {
+	m, err := memio.NewMMap("/dev/mem")
+	if err != nil {
+		return nil, err
+	}
+	return &MMIO{m: m, base: base}, nil
+}
+
+// ReadAt implements Registers.
+func (r *MMIO) ReadAt(off int64, data memio.UintN) error {
+	return r.m.ReadAt(r.base+off, data)
+}
+
+// WriteAt implements Registers.
+func (r *MMIO) WriteAt(off int64, data memio.UintN) error {
+	return r.m.WriteAt(r.base+off, data)
+}
+
+// Close unmaps the registers.
+func (r *MMIO) Close() error {
+	return r.m.Close()
+}
+
+func read8(r Registers, off int64) (uint8, error) {
+	var v memio.Uint8
+	err := r.ReadAt(off, &v)
+	return uint8(v), err
+}
+
+func write8(r Registers, off int64, v uint8) error {
+	d := memio.Uint8(v)
+	return r.WriteAt(off, &d)
+}
+
+func read32(r Registers, off int64) (uint32, error) {
+	var v memio.Uint32
+	err := r.ReadAt(off, &v)
+	return uint32(v), err
+}
+
+func write32(r Registers, off int64, v uint32) error {
+	d := memio.Uint32(v)
+	return r.WriteAt(off, &d)
+}
+
+// poll calls done until it returns true or timeout elapses.
+func poll(timeout time.Duration, done func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := done()
+		if err != nil || ok {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return ErrTimeout
+		}
+		time.Sleep(10 * time.Microsecond)
+	}
+}
+
+// Kind identifies a controller type.
+type Kind int
+
+// Supported controllers.
+const (
+	IntelPCH Kind = iota
+	AMDSPI100
+)
+
+// String implements fmt.Stringer.
+func (k Kind) String() string {
+	switch k {
+	case IntelPCH:
+		return "Intel PCH"
+	case AMDSPI100:
+		return "AMD SPI100"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Controller is a SPI controller found on the PCI bus.
+type Controller struct {
+	Kind Kind
+	// Dev is the PCI function the controller belongs to.
+	Dev *pci.PCI
+	// SPIBAR is the physical address of the controller registers.
+	SPIBAR uint64
+}
+
+const (
+	intelVendor = 0x8086
+	amdVendor   = 0x1022
+
+	// intelSPIFunction is the PCI function of the SPI controller on
+	// PCH 100 series and later. SPIBAR is its BAR 0.
+	intelSPIFunction = "0000:00:1f.5"
+
+	// amdLPCFunction is the FCH LPC bridge, which holds the SPI base
+	// address in its config space.
+	amdLPCFunction = "0000:00:14.3"
+	amdLPCDevice   = 0x790e
+	amdSPIBase     = 0xa0
+)
+
+// controllerFromPCI returns the controller behind PCI function p, if any.
+func controllerFromPCI(p *pci.PCI) (*Controller, error) {
+	switch {
+	case p.Addr == intelSPIFunction && p.Vendor == intelVendor:
+		if len(p.BARS) == 0 || p.BARS[0].Index != 0 {
+			return nil, fmt.Errorf("%s: SPIBAR is not assigned: %w", p.Addr, ErrNotFound)
+		}
+		return &Controller{Kind: IntelPCH, Dev: p, SPIBAR: p.BARS[0].Base}, nil
+	case p.Addr == amdLPCFunction && p.Vendor == amdVendor && p.Device == amdLPCDevice:
+		v, err := p.ReadConfigRegister(amdSPIBase, 32)
+		if err != nil {
+			return nil, err
+		}
+		base := v &^ 0xff
+		if base == 0 {
+			return nil, fmt.Errorf("%s: SPI base address is not set: %w", p.Addr, ErrNotFound)
+		}
+		return &Controller{Kind: AMDSPI100, Dev: p, SPIBAR: base}, nil
+	}
+	return nil, ErrNotFound
+}
+
+// Find returns the chipset SPI controller of this machine.
+func Find() (*Controller, error) {
+	r, err := pci.NewBusReader(intelSPIFunction, amdLPCFunction)
+	if err != nil {
+		return nil, err
+	}
+	devs, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range devs {
+		if c, err := controllerFromPCI(d); err == nil {
+			return c, nil
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, ErrNotFound
+}
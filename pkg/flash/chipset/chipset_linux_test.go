@@ -0,0 +1,457 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chipset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/flash/ifd"
+	"github.com/u-root/u-root/pkg/flash/spimock"
+	"github.com/u-root/u-root/pkg/memio"
+	"github.com/u-root/u-root/pkg/pci"
+	"github.com/u-root/u-root/pkg/spidev"
+)
+
+// intelSim is a register-level model of the Intel PCH hardware sequencer,
+// backed by the contents and SFDP of a spimock.
+type intelSim struct {
+	spi    *spimock.MockSPI
+	id     []byte
+	regs   map[int64]uint32
+	cycles []uint32
+	// failCycle makes every cycle fail with FCERR.
+	failCycle bool
+}
+
+func newIntelSim() *intelSim {
+	return &intelSim{
+		spi:  spimock.New(),
+		id:   []byte{0xc2, 0x20, 0x1a},
+		regs: map[int64]uint32{},
+	}
+}
+
+func (s *intelSim) ReadAt(off int64, data memio.UintN) error {
+	d, ok := data.(*memio.Uint32)
+	if !ok {
+		return fmt.Errorf("%d byte read of %#x, want 32 bits", data.Size(), off)
+	}
+	*d = memio.Uint32(s.regs[off])
+	return nil
+}
+
+func (s *intelSim) WriteAt(off int64, data memio.UintN) error {
+	d, ok := data.(*memio.Uint32)
+	if !ok {
+		return fmt.Errorf("%d byte write of %#x, want 32 bits", data.Size(), off)
+	}
+	v := uint32(*d)
+	locked := s.regs[regHSFSTSCTL]&hsfsFLOCKDN != 0
+	switch {
+	case off == regHSFSTSCTL:
+		// The status bits are write-1-to-clear.
+		s.regs[off] &^= v & hsfsClear
+		if v&hsfcFGO != 0 {
+			s.run(v)
+		}
+	case locked && off >= regPR && off < regPR+4*numPR:
+	default:
+		s.regs[off] = v
+	}
+	return nil
+}
+
+func (s *intelSim) protected(addr uint32) bool {
+	for i := range numPR {
+		v := s.regs[regPR+int64(4*i)]
+		base, limit := (v&0x7fff)<<12, ((v>>16)&0x7fff)<<12|0xfff
+		if v&(1<<31) != 0 && addr >= base && addr <= limit {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *intelSim) run(ctl uint32) {
+	cycle := (ctl >> hsfcFCYCLEShift) & 0xf
+	n := int((ctl>>hsfcFDBCShift)&0x3f) + 1
+	addr := s.regs[regFADDR]
+	s.cycles = append(s.cycles, cycle)
+
+	var fdata [hwseqMax]byte
+	for i := range numFDATA {
+		v := s.regs[regFDATA+int64(4*i)]
+		fdata[4*i], fdata[4*i+1], fdata[4*i+2], fdata[4*i+3] = byte(v), byte(v>>8), byte(v>>16), byte(v>>24)
+	}
+
+	status := uint32(hsfsFDONE)
+	switch {
+	case s.failCycle:
+		status |= hsfsFCERR
+	case cycle == cycleRead:
+		copy(fdata[:n], s.spi.Data[addr:])
+	case cycle == cycleReadSFDP:
+		for i := range n {
+			fdata[i] = 0xff
+			if int(addr)+i < len(s.spi.SFDP) {
+				fdata[i] = s.spi.SFDP[int(addr)+i]
+			}
+		}
+	case cycle == cycleReadJEDEC:
+		copy(fdata[:], s.id)
+	case (cycle == cycleWrite || cycle == cycleErase4K || cycle == cycleErase64K) && s.protected(addr):
+		status |= hsfsFCERR
+	case cycle == cycleWrite:
+		for i := range n {
+			s.spi.Data[int(addr)+i] &= fdata[i]
+		}
+	case cycle == cycleErase4K:
+		copy(s.spi.Data[addr&^0xfff:], bytes.Repeat([]byte{0xff}, 0x1000))
+	case cycle == cycleErase64K:
+		copy(s.spi.Data[addr&^0xffff:], bytes.Repeat([]byte{0xff}, 0x10000))
+	default:
+		status |= hsfsFCERR
+	}
+
+	for i := range numFDATA {
+		s.regs[regFDATA+int64(4*i)] = uint32(fdata[4*i]) | uint32(fdata[4*i+1])<<8 | uint32(fdata[4*i+2])<<16 | uint32(fdata[4*i+3])<<24
+	}
+	s.regs[regHSFSTSCTL] |= status
+}
+
+func TestIntel(t *testing.T) {
+	sim := newIntelSim()
+	c, err := NewIntel(sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Size() != spimock.FakeSize || c.Chip.ID != 0xc2201a {
+		t.Errorf("NewIntel() = %v, want %#x bytes, ID 0xc2201a", &c.Chip, spimock.FakeSize)
+	}
+
+	// Cross a page boundary, with a length that is not a multiple of 4.
+	data := []byte(strings.Repeat("u-root flash ", 30))
+	const off = 0x10f0
+	if _, err := c.EraseAt(0x1000, 0x1000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.WriteAt(data, off); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(data))
+	if _, err := c.ReadAt(got, off); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("ReadAt() = %q, want %q", got, data)
+	}
+	for i := 0; i < len(data); {
+		a := off + i
+		n := min(hwseqMax, len(data)-i, hwseqPage-a%hwseqPage)
+		if a/hwseqPage != (a+n-1)/hwseqPage {
+			t.Errorf("write at %#x crosses a page", a)
+		}
+		i += n
+	}
+
+	sim.cycles = nil
+	if _, err := c.EraseAt(0x11000, 0x10000); err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint32{cycleErase64K, cycleErase4K}; fmt.Sprint(sim.cycles) != fmt.Sprint(want) {
+		t.Errorf("erase cycles = %v, want %v", sim.cycles, want)
+	}
+	if _, err := c.EraseAt(0x1000, 0x1000); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReadAt(got, off); err != nil || !bytes.Equal(got, bytes.Repeat([]byte{0xff}, len(got))) {
+		t.Errorf("ReadAt() after erase = %q, %v, want 0xff", got, err)
+	}
+	if _, err := c.EraseAt(0x100, 0x1000); err == nil {
+		t.Errorf("EraseAt(unaligned) = nil, want error")
+	}
+
+	sim.failCycle = true
+	if _, err := c.ReadAt(got, 0); !errors.Is(err, ErrCycle) {
+		t.Errorf("ReadAt() with FCERR = %v, want %v", err, ErrCycle)
+	}
+}
+
+func TestIntelProtection(t *testing.T) {
+	sim := newIntelSim()
+	c, err := NewIntel(sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fd 0-0xfff read-only, bios 0x200000-0x3fffff read-write, me
+	// 0x1000-0x1fffff locked. PR0 write-protects the top 64 KiB of BIOS.
+	sim.regs[regFREG] = 0x00000000
+	sim.regs[regFREG+4] = 0x03ff0200
+	sim.regs[regFREG+8] = 0x01ff0001
+	for i := 3; i < numFREG; i++ {
+		sim.regs[regFREG+int64(4*i)] = 0x00007fff
+	}
+	sim.regs[regFRAP] = 0x0203
+	sim.regs[regPR] = 1<<31 | 0x03ff<<16 | 0x03f0
+	sim.regs[regHSFSTSCTL] = hsfsFLOCKDN | hsfsFDV
+
+	p, err := c.Protection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Locked || !p.DescriptorValid || p.WriteStatusDisabled {
+		t.Errorf("Protection() = %v", p)
+	}
+	want := "FLOCKDN=true WRSDIS=false FDV=true\n" +
+		"region fd: 0x00000000-0x00000fff read-only\n" +
+		"region bios: 0x00200000-0x003fffff read-write\n" +
+		"region me: 0x00001000-0x001fffff locked\n" +
+		"PR0 0x003f0000-0x003fffff write-protected"
+	if got := p.String(); got != want {
+		t.Errorf("Protection() =\n%s\nwant\n%s", got, want)
+	}
+	if r := p.Regions[ifd.RegionBIOS]; r.Base != 0x200000 || !r.Write {
+		t.Errorf("BIOS region = %v", &r)
+	}
+
+	// FLOCKDN keeps the protected range from being cleared.
+	if err := write32(sim, regPR, 0); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := c.Protection(); err != nil || !p.Ranges[0].WriteProtect {
+		t.Errorf("PR0 after clearing it with FLOCKDN = %v, %v, want write-protected", p, err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		off  int64
+		err  error
+	}{
+		{"bios", 0x200000, nil},
+		{"protected range", 0x3f0000, ErrProtected},
+		{"me", 0x100000, ErrProtected},
+		{"descriptor", 0, ErrProtected},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sim.cycles = nil
+			if _, err := c.EraseAt(0x1000, tt.off); !errors.Is(err, tt.err) {
+				t.Errorf("EraseAt(%#x) = %v, want %v", tt.off, err, tt.err)
+			}
+			if _, err := c.WriteAt([]byte{0}, tt.off); !errors.Is(err, tt.err) {
+				t.Errorf("WriteAt(%#x) = %v, want %v", tt.off, err, tt.err)
+			}
+			if tt.err != nil && len(sim.cycles) != 0 {
+				t.Errorf("ran cycles %v on a protected address", sim.cycles)
+			}
+		})
+	}
+
+	// Without FDV, only the protected ranges apply; the controller still
+	// refuses writes to them with FCERR.
+	sim.regs[regHSFSTSCTL] = 0
+	if _, err := c.WriteAt([]byte{0}, 0x100000); err != nil {
+		t.Errorf("WriteAt(me) without descriptor = %v, want nil", err)
+	}
+	sim.regs[regPR] = 0
+	sim.regs[regPR+4] = 1<<31 | 0x0010<<16 | 0x0010
+	if _, err := c.WriteAt([]byte{0}, 0x10000); !errors.Is(err, ErrProtected) {
+		t.Errorf("WriteAt(PR1) = %v, want %v", err, ErrProtected)
+	}
+}
+
+func TestIntelChipFallback(t *testing.T) {
+	sim := newIntelSim()
+	sim.spi.SFDP = nil
+	if _, err := NewIntel(sim); err == nil {
+		t.Errorf("NewIntel() without SFDP or known chip = nil, want error")
+	}
+
+	sim.id = []byte{0xbf, 0x25, 0x41}
+	c, err := NewIntel(sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Chip.Chip != "SST25VF016B" || c.Size() != 2<<20 {
+		t.Errorf("NewIntel() = %v, want SST25VF016B", &c.Chip)
+	}
+}
+
+func TestIntelLargeDensity(t *testing.T) {
+	// Bit 31 of the density dword selects the 4 Gbit+ encoding.
+	sim := newIntelSim()
+	sim.spi.SFDP = slices.Clone(spimock.FakeSFDP)
+	sim.spi.SFDP[0x37] |= 0x80
+	if _, err := NewIntel(sim); err == nil {
+		t.Errorf("NewIntel() with large density and unknown chip = nil, want error")
+	}
+
+	sim.id = []byte{0xbf, 0x25, 0x41}
+	c, err := NewIntel(sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Chip.Chip != "SST25VF016B" {
+		t.Errorf("NewIntel() = %v, want SST25VF016B from the chips table", &c.Chip)
+	}
+}
+
+// amdSim is a register-level model of the AMD SPI100 controller, which
+// passes the commands it executes on to a spimock.
+type amdSim struct {
+	spi    *spimock.MockSPI
+	regs   [0x100]byte
+	cntrl0 uint32
+	ops    []byte
+}
+
+func (s *amdSim) ReadAt(off int64, data memio.UintN) error {
+	switch d := data.(type) {
+	case *memio.Uint8:
+		*d = memio.Uint8(s.regs[off])
+	case *memio.Uint32:
+		switch off {
+		case regSPICntrl0:
+			*d = memio.Uint32(s.cntrl0)
+		case regSPIStatus:
+			// The simulated controller is never busy.
+			*d = 0
+		default:
+			return fmt.Errorf("unexpected 32 bit read of %#x", off)
+		}
+	default:
+		return fmt.Errorf("%d byte read of %#x", data.Size(), off)
+	}
+	return nil
+}
+
+func (s *amdSim) WriteAt(off int64, data memio.UintN) error {
+	d, ok := data.(*memio.Uint8)
+	if !ok {
+		return fmt.Errorf("%d byte write of %#x, want 8 bits", data.Size(), off)
+	}
+	s.regs[off] = byte(*d)
+	if off == regCmdTrigger && *d&cmdExecute != 0 {
+		return s.execute()
+	}
+	return nil
+}
+
+func (s *amdSim) execute() error {
+	opcode, txn, rxn := s.regs[regCmdCode], int(s.regs[regTxByteCount]), int(s.regs[regRxByteCount])
+	if txn+rxn > fifoSize {
+		return fmt.Errorf("FIFO overflow: %d+%d bytes", txn, rxn)
+	}
+	s.ops = append(s.ops, opcode)
+	tx := append([]byte{opcode}, s.regs[regFIFO:regFIFO+txn]...)
+	transfers := []spidev.Transfer{{Tx: tx}}
+	if rxn > 0 {
+		transfers = append(transfers, spidev.Transfer{Rx: s.regs[regFIFO+txn : regFIFO+txn+rxn]})
+	}
+	if err := s.spi.Transfer(transfers); err != nil {
+		return err
+	}
+	// The simulated chip finishes programming instantly.
+	s.spi.WritePending = 0
+	return nil
+}
+
+func TestAMD(t *testing.T) {
+	sim := &amdSim{spi: spimock.New(), cntrl0: cntrl0MacRom | cntrl0Host}
+	a := NewAMD(sim)
+	if locked, err := a.Locked(); err != nil || locked {
+		t.Errorf("Locked() = %v, %v, want false, nil", locked, err)
+	}
+
+	f, err := a.Flash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Size() != spimock.FakeSize || !a.Is4BA {
+		t.Errorf("Flash() = %v, want %#x bytes with 4-byte addresses", &f.Chip, spimock.FakeSize)
+	}
+	// The mock does not need Enter4BA, so put it in 4-byte mode directly.
+	sim.spi.Is4BA = true
+
+	// A full page program is split into several commands.
+	data := bytes.Repeat([]byte("SPI100"), 256/6+1)[:256]
+	if _, err := f.EraseAt(0x1000, 0x2000); err != nil {
+		t.Fatal(err)
+	}
+	sim.ops = nil
+	if _, err := f.WriteAt(data, 0x2000); err != nil {
+		t.Fatal(err)
+	}
+	var programs int
+	for _, o := range sim.ops {
+		if o == 0x02 {
+			programs++
+		}
+	}
+	if programs != 4 {
+		t.Errorf("page program took %d commands, want 4", programs)
+	}
+	if !bytes.Equal(sim.spi.Data[0x2000:0x2100], data) {
+		t.Errorf("flash contents = %q, want %q", sim.spi.Data[0x2000:0x2100], data)
+	}
+
+	got := make([]byte, 1000)
+	copy(sim.spi.Data[0x3000:], bytes.Repeat([]byte{0x5a}, len(got)))
+	if _, err := f.ReadAt(got, 0x3000); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, bytes.Repeat([]byte{0x5a}, len(got))) {
+		t.Errorf("ReadAt() = %x", got)
+	}
+
+	if err := a.Transfer([]spidev.Transfer{{Tx: make([]byte, 100)}}); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Transfer(100 byte command) = %v, want %v", err, ErrTooLong)
+	}
+
+	sim.cntrl0 = cntrl0Host
+	if locked, err := a.Locked(); err != nil || !locked {
+		t.Errorf("Locked() = %v, %v, want true, nil", locked, err)
+	}
+}
+
+func TestControllerFromPCI(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		dev  *pci.PCI
+		want *Controller
+		err  error
+	}{
+		{
+			name: "intel",
+			dev:  &pci.PCI{Addr: intelSPIFunction, Vendor: intelVendor, BARS: []pci.BAR{{Index: 0, Base: 0xfe010000}}},
+			want: &Controller{Kind: IntelPCH, SPIBAR: 0xfe010000},
+		},
+		{
+			name: "intel without SPIBAR",
+			dev:  &pci.PCI{Addr: intelSPIFunction, Vendor: intelVendor},
+			err:  ErrNotFound,
+		},
+		{
+			name: "other device",
+			dev:  &pci.PCI{Addr: "0000:00:1f.3", Vendor: intelVendor, BARS: []pci.BAR{{Index: 0, Base: 0xfe010000}}},
+			err:  ErrNotFound,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := controllerFromPCI(tt.dev)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("controllerFromPCI() = %v, want %v", err, tt.err)
+			}
+			if tt.want != nil && (c.Kind != tt.want.Kind || c.SPIBAR != tt.want.SPIBAR) {
+				t.Errorf("controllerFromPCI() = %v %#x, want %v %#x", c.Kind, c.SPIBAR, tt.want.Kind, tt.want.SPIBAR)
+			}
+		})
+	}
+}
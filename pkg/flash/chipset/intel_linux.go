@@ -0,0 +1,436 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chipset
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/u-root/u-root/pkg/flash/chips"
+	"github.com/u-root/u-root/pkg/flash/ifd"
+	"github.com/u-root/u-root/pkg/flash/sfdp"
+)
+
+// Intel PCH SPIBAR registers, PCH 100 series and later.
+const (
+	regHSFSTSCTL = 0x04
+	regFADDR     = 0x08
+	regFDATA     = 0x10
+	regFRAP      = 0x50
+	regFREG      = 0x54
+	regPR        = 0x84
+
+	numFDATA = 16
+	numFREG  = 12
+	numPR    = 5
+)
+
+// HSFSTS_CTL bits.
+const (
+	hsfsFDONE   = 1 << 0
+	hsfsFCERR   = 1 << 1
+	hsfsAEL     = 1 << 2
+	hsfsSCIP    = 1 << 5
+	hsfsWRSDIS  = 1 << 11
+	hsfsFDV     = 1 << 14
+	hsfsFLOCKDN = 1 << 15
+	hsfcFGO     = 1 << 16
+
+	hsfcFCYCLEShift = 17
+	hsfcFDBCShift   = 24
+
+	hsfsClear = hsfsFDONE | hsfsFCERR | hsfsAEL
+)
+
+// Hardware sequencing cycles.
+const (
+	cycleRead      = 0
+	cycleWrite     = 2
+	cycleErase4K   = 3
+	cycleErase64K  = 4
+	cycleReadSFDP  = 5
+	cycleReadJEDEC = 6
+)
+
+const (
+	// hwseqMax is the most data one cycle transfers.
+	hwseqMax = numFDATA * 4
+	// hwseqPage is the page size writes must not cross.
+	hwseqPage = 256
+)
+
+var (
+	// ErrCycle is returned when the controller reports a failed cycle,
+	// e.g. because the flash refused a write.
+	ErrCycle = errors.New("flash cycle error")
+
+	// ErrProtected is returned for accesses the controller blocks, either
+	// through a protected range or the region access permissions.
+	ErrProtected = fmt.Errorf("access blocked by SPI controller: %w", os.ErrPermission)
+)
+
+// ProtectedRange is a PRn register.
+type ProtectedRange struct {
+	Base  uint32
+	Limit uint32
+
+	ReadProtect  bool
+	WriteProtect bool
+}
+
+// Enabled returns whether the range protects anything.
+func (p *ProtectedRange) Enabled() bool {
+	return p.ReadProtect || p.WriteProtect
+}
+
+// Contains returns whether [off, off+n) overlaps the range.
+func (p *ProtectedRange) Contains(off, n int64) bool {
+	return off <= int64(p.Limit) && off+n > int64(p.Base)
+}
+
+// String implements fmt.Stringer.
+func (p *ProtectedRange) String() string {
+	var prot []string
+	if p.ReadProtect {
+		prot = append(prot, "read")
+	}
+	if p.WriteProtect {
+		prot = append(prot, "write")
+	}
+	return fmt.Sprintf("0x%08x-0x%08x %s-protected", p.Base, p.Limit, strings.Join(prot, ","))
+}
+
+// RegionAccess is a flash region and what the host may do with it.
+type RegionAccess struct {
+	ifd.Region
+	Read  bool
+	Write bool
+}
+
+// String implements fmt.Stringer.
+func (r *RegionAccess) String() string {
+	access := map[[2]bool]string{
+		{false, false}: "locked",
+		{true, false}:  "read-only",
+		{false, true}:  "write-only",
+		{true, true}:   "read-write",
+	}[[2]bool{r.Read, r.Write}]
+	return fmt.Sprintf("%v %s", &r.Region, access)
+}
+
+// Protection is the lock state of an Intel SPI controller.
+type Protection struct {
+	// Locked is FLOCKDN: the protected ranges and the lock itself
+	// cannot be changed until reset.
+	Locked bool
+	// WriteStatusDisabled is WRSDIS: writes to the flash status
+	// register, which holds the flash chip's own protection bits, are
+	// blocked.
+	WriteStatusDisabled bool
+	// DescriptorValid is FDV: the flash contains a valid descriptor and
+	// Regions is meaningful.
+	DescriptorValid bool
+
+	Regions []RegionAccess
+	Ranges  []ProtectedRange
+}
+
+// String implements fmt.Stringer.
+func (p *Protection) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "FLOCKDN=%v WRSDIS=%v FDV=%v", p.Locked, p.WriteStatusDisabled, p.DescriptorValid)
+	for _, r := range p.Regions {
+		if r.Used() {
+			fmt.Fprintf(&b, "\nregion %v", &r)
+		}
+	}
+	for i, r := range p.Ranges {
+		if r.Enabled() {
+			fmt.Fprintf(&b, "\nPR%d %v", i, &r)
+		}
+	}
+	return b.String()
+}
+
+// writable returns an error if the host may not write [off, off+n).
+func (p *Protection) writable(off, n int64) error {
+	for i, r := range p.Ranges {
+		if r.WriteProtect && r.Contains(off, n) {
+			return fmt.Errorf("%#x+%#x overlaps PR%d %v: %w", off, n, i, &r, ErrProtected)
+		}
+	}
+	if !p.DescriptorValid {
+		return nil
+	}
+	for _, r := range p.Regions {
+		if r.Used() && !r.Write && off <= int64(r.Limit) && off+n > int64(r.Base) {
+			return fmt.Errorf("%#x+%#x overlaps %v: %w", off, n, &r, ErrProtected)
+		}
+	}
+	return nil
+}
+
+// Intel drives the hardware sequencer of an Intel PCH SPI controller. The
+// hardware sequencer hides the SPI opcodes and only offers read, write and
+// erase cycles, so Intel implements the flash operations directly instead
+// of a flash.SPI.
+type Intel struct {
+	regs Registers
+
+	// Timeout bounds each cycle. It defaults to DefaultTimeout.
+	Timeout time.Duration
+
+	// Chip describes the flash. ArraySize covers only the first flash
+	// component on systems with two.
+	chips.Chip
+}
+
+// NewIntel returns an Intel controller using regs and identifies the
+// flash through SFDP, falling back to the chips table.
+func NewIntel(regs Registers) (*Intel, error) {
+	c := &Intel{regs: regs, Timeout: DefaultTimeout}
+
+	id, err := c.ID()
+	if err != nil {
+		return nil, fmt.Errorf("reading JEDEC ID: %w", err)
+	}
+
+	// Densities of 4 Gbit and above use the extended encoding, which is not
+	// decoded here; the chips table may still know such parts.
+	var density int64
+	s, err := sfdp.Read(c.SFDPReader())
+	if err == nil {
+		density, err = s.Param(sfdp.ParamFlashMemoryDensity)
+	}
+	if err == nil && density < 0x80000000 {
+		c.Chip = chips.Chip{ID: id, Chip: "SFDP", ArraySize: (density + 1) / 8}
+	} else {
+		chip, lerr := chips.Lookup(id)
+		if lerr != nil {
+			if err == nil {
+				err = fmt.Errorf("unsupported SFDP density %#x", density)
+			}
+			return nil, fmt.Errorf("chip %#06x: chip not known, and no usable SFDP: %w", id, err)
+		}
+		c.Chip = *chip
+	}
+	c.PageSize, c.SectorSize, c.BlockSize = hwseqPage, 4096, 65536
+	return c, nil
+}
+
+// cycle runs one hardware sequencing cycle of n bytes at off. data is
+// written to FDATA before write cycles and filled from FDATA after read
+// cycles.
+func (c *Intel) cycle(cycle uint32, off int64, data []byte, n int) error {
+	if err := poll(c.Timeout, func() (bool, error) {
+		v, err := read32(c.regs, regHSFSTSCTL)
+		return v&hsfsSCIP == 0, err
+	}); err != nil {
+		return fmt.Errorf("waiting for idle controller: %w", err)
+	}
+	if err := write32(c.regs, regHSFSTSCTL, hsfsClear); err != nil {
+		return err
+	}
+	if err := write32(c.regs, regFADDR, uint32(off)); err != nil {
+		return err
+	}
+	if cycle == cycleWrite {
+		var buf [hwseqMax]byte
+		copy(buf[:], data)
+		for i := 0; i < n; i += 4 {
+			v := uint32(buf[i]) | uint32(buf[i+1])<<8 | uint32(buf[i+2])<<16 | uint32(buf[i+3])<<24
+			if err := write32(c.regs, regFDATA+int64(i), v); err != nil {
+				return err
+			}
+		}
+	}
+
+	ctl := uint32(hsfsClear|hsfcFGO) | cycle<<hsfcFCYCLEShift
+	if n > 0 {
+		ctl |= uint32(n-1) << hsfcFDBCShift
+	}
+	if err := write32(c.regs, regHSFSTSCTL, ctl); err != nil {
+		return err
+	}
+
+	var status uint32
+	if err := poll(c.Timeout, func() (bool, error) {
+		var err error
+		status, err = read32(c.regs, regHSFSTSCTL)
+		return status&(hsfsFDONE|hsfsFCERR) != 0, err
+	}); err != nil {
+		return fmt.Errorf("cycle %d at %#x: %w", cycle, off, err)
+	}
+	if status&hsfsAEL != 0 {
+		return fmt.Errorf("cycle %d at %#x: %w", cycle, off, ErrProtected)
+	}
+	if status&hsfsFCERR != 0 {
+		return fmt.Errorf("cycle %d at %#x: %w", cycle, off, ErrCycle)
+	}
+
+	if cycle != cycleWrite {
+		for i := 0; i < n; i += 4 {
+			v, err := read32(c.regs, regFDATA+int64(i))
+			if err != nil {
+				return err
+			}
+			for j := 0; j < 4 && i+j < n; j++ {
+				data[i+j] = byte(v >> (8 * j))
+			}
+		}
+	}
+	return nil
+}
+
+// ID reads the JEDEC ID of the flash.
+func (c *Intel) ID() (chips.ID, error) {
+	var b [3]byte
+	if err := c.cycle(cycleReadJEDEC, 0, b[:], len(b)); err != nil {
+		return 0, err
+	}
+	return chips.ID(b[0])<<16 | chips.ID(b[1])<<8 | chips.ID(b[2]), nil
+}
+
+// Protection reads the lock state of the controller.
+func (c *Intel) Protection() (*Protection, error) {
+	hsfs, err := read32(c.regs, regHSFSTSCTL)
+	if err != nil {
+		return nil, err
+	}
+	p := &Protection{
+		Locked:              hsfs&hsfsFLOCKDN != 0,
+		WriteStatusDisabled: hsfs&hsfsWRSDIS != 0,
+		DescriptorValid:     hsfs&hsfsFDV != 0,
+	}
+
+	frap, err := read32(c.regs, regFRAP)
+	if err != nil {
+		return nil, err
+	}
+	// BRRA and BRWA hold the BIOS master's read and write permissions
+	// for regions 0-7. Other regions follow the descriptor, which does
+	// not grant the host access to them.
+	brra, brwa := frap&0xff, (frap>>8)&0xff
+	for i := range numFREG {
+		v, err := read32(c.regs, regFREG+int64(4*i))
+		if err != nil {
+			return nil, err
+		}
+		r := RegionAccess{Region: ifd.DecodeRegion(ifd.RegionID(i), v)}
+		if i < 8 {
+			r.Read = brra&(1<<i) != 0
+			r.Write = brwa&(1<<i) != 0
+		}
+		p.Regions = append(p.Regions, r)
+	}
+
+	for i := range numPR {
+		v, err := read32(c.regs, regPR+int64(4*i))
+		if err != nil {
+			return nil, err
+		}
+		p.Ranges = append(p.Ranges, ProtectedRange{
+			Base:         (v & 0x7fff) << 12,
+			Limit:        ((v>>16)&0x7fff)<<12 | 0xfff,
+			ReadProtect:  v&(1<<15) != 0,
+			WriteProtect: v&(1<<31) != 0,
+		})
+	}
+	return p, nil
+}
+
+// Size returns the size of the flash in bytes.
+func (c *Intel) Size() int64 {
+	return c.ArraySize
+}
+
+// ReadAt implements io.ReaderAt.
+func (c *Intel) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off > c.ArraySize {
+		return 0, io.EOF
+	}
+	p = p[:min(int64(len(p)), c.ArraySize-off)]
+	for i := 0; i < len(p); i += hwseqMax {
+		n := min(hwseqMax, len(p)-i)
+		if err := c.cycle(cycleRead, off+int64(i), p[i:i+n], n); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
+
+// WriteAt implements io.WriterAt. Like flash.Flash.WriteAt, it does not
+// erase before writing.
+func (c *Intel) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 || off > c.ArraySize {
+		return 0, io.EOF
+	}
+	p = p[:min(int64(len(p)), c.ArraySize-off)]
+	if err := c.checkWritable(off, int64(len(p))); err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(p); {
+		a := off + int64(i)
+		// Stay within FDATA and within one page.
+		n := min(hwseqMax, len(p)-i, int(hwseqPage-a%hwseqPage))
+		if err := c.cycle(cycleWrite, a, p[i:i+n], n); err != nil {
+			return i, err
+		}
+		i += n
+	}
+	return len(p), nil
+}
+
+// EraseAt erases n bytes from offset off. Both must be aligned to 4 KiB.
+func (c *Intel) EraseAt(n int64, off int64) (int64, error) {
+	if off < 0 || off+n > c.ArraySize || off%c.SectorSize != 0 || n%c.SectorSize != 0 {
+		return 0, fmt.Errorf("erase of %#x bytes at %#x is not 4 KiB aligned or exceeds %#x: %w", n, off, c.ArraySize, os.ErrInvalid)
+	}
+	if err := c.checkWritable(off, n); err != nil {
+		return 0, err
+	}
+	for i := int64(0); i < n; {
+		cycle, size := uint32(cycleErase4K), c.SectorSize
+		if (off+i)%c.BlockSize == 0 && n-i >= c.BlockSize {
+			cycle, size = cycleErase64K, c.BlockSize
+		}
+		if err := c.cycle(cycle, off+i, nil, 0); err != nil {
+			return i, err
+		}
+		i += size
+	}
+	return n, nil
+}
+
+func (c *Intel) checkWritable(off, n int64) error {
+	p, err := c.Protection()
+	if err != nil {
+		return err
+	}
+	return p.writable(off, n)
+}
+
+// SFDPReader returns a reader for the SFDP address space of the flash.
+func (c *Intel) SFDPReader() io.ReaderAt {
+	return (*intelSFDP)(c)
+}
+
+type intelSFDP Intel
+
+// ReadAt implements io.ReaderAt.
+func (s *intelSFDP) ReadAt(p []byte, off int64) (int, error) {
+	c := (*Intel)(s)
+	for i := 0; i < len(p); i += hwseqMax {
+		n := min(hwseqMax, len(p)-i)
+		if err := c.cycle(cycleReadSFDP, off+int64(i), p[i:i+n], n); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
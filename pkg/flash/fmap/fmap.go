@@ -0,0 +1,223 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fmap parses the flash map (FMAP) found in coreboot and Chrome OS
+// firmware images. The FMAP names the areas of a firmware image, such as
+// RO_VPD, RW_VPD or COREBOOT.
+//
+// Useful references:
+// * https://github.com/google/flashmap/blob/master/lib/fmap.h
+// * https://doc.coreboot.org/lib/flashmap.html
+package fmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Signature appears as the first 8 bytes of the FMAP.
+const Signature = "__FMAP__"
+
+// MajorVersion is the only FMAP major version we understand.
+const MajorVersion = 1
+
+// minStride is the smallest alignment at which we look for the FMAP
+// before falling back to a linear search.
+const minStride = 64
+
+var (
+	// ErrNotFound is returned when an image contains no FMAP.
+	ErrNotFound = errors.New("fmap not found")
+
+	// ErrAreaNotFound is returned when an FMAP has no area of the given
+	// name.
+	ErrAreaNotFound = errors.New("fmap area not found")
+)
+
+// Header is the on-flash header of the FMAP.
+type Header struct {
+	Signature [8]uint8
+	VerMajor  uint8
+	VerMinor  uint8
+	Base      uint64
+	Size      uint32
+	Name      [32]uint8
+	NAreas    uint16
+}
+
+// AreaHeader is the on-flash description of an area.
+type AreaHeader struct {
+	Offset uint32
+	Size   uint32
+	Name   [32]uint8
+	Flags  AreaFlags
+}
+
+// AreaFlags are the flags of an area.
+type AreaFlags uint16
+
+// Area flags.
+const (
+	AreaStatic     AreaFlags = 1 << 0
+	AreaCompressed AreaFlags = 1 << 1
+	AreaRO         AreaFlags = 1 << 2
+	AreaPreserve   AreaFlags = 1 << 3
+)
+
+// String implements fmt.Stringer.
+func (f AreaFlags) String() string {
+	var s []string
+	for _, n := range []struct {
+		flag AreaFlags
+		name string
+	}{
+		{AreaStatic, "static"},
+		{AreaCompressed, "compressed"},
+		{AreaRO, "ro"},
+		{AreaPreserve, "preserve"},
+	} {
+		if f&n.flag != 0 {
+			s = append(s, n.name)
+		}
+	}
+	return strings.Join(s, ",")
+}
+
+// FMap is a parsed FMAP.
+type FMap struct {
+	Header
+	Areas []AreaHeader
+
+	// Offset is where the FMAP was found in the image.
+	Offset int64
+}
+
+// cString returns b up to the first NUL byte.
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// String returns the name of the image.
+func (h *Header) String() string {
+	return cString(h.Name[:])
+}
+
+// String returns the name of the area.
+func (a *AreaHeader) String() string {
+	return cString(a.Name[:])
+}
+
+// End returns the offset one past the last byte of the area.
+func (a *AreaHeader) End() int64 {
+	return int64(a.Offset) + int64(a.Size)
+}
+
+// Area returns the area with the given name.
+func (f *FMap) Area(name string) (*AreaHeader, error) {
+	for i := range f.Areas {
+		if f.Areas[i].String() == name {
+			return &f.Areas[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%q: %w", name, ErrAreaNotFound)
+}
+
+// ReadArea returns an io.SectionReader over the area with the given name.
+func (f *FMap) ReadArea(r io.ReaderAt, name string) (*io.SectionReader, error) {
+	a, err := f.Area(name)
+	if err != nil {
+		return nil, err
+	}
+	return io.NewSectionReader(r, int64(a.Offset), int64(a.Size)), nil
+}
+
+// Parse parses the FMAP at offset off of r.
+func Parse(r io.ReaderAt, off int64) (*FMap, error) {
+	f := &FMap{Offset: off}
+	sr := io.NewSectionReader(r, off, 1<<31)
+	if err := binary.Read(sr, binary.LittleEndian, &f.Header); err != nil {
+		return nil, err
+	}
+	if string(f.Signature[:]) != Signature {
+		return nil, fmt.Errorf("signature %q at %#x: %w", f.Signature, off, ErrNotFound)
+	}
+	if f.VerMajor != MajorVersion {
+		return nil, fmt.Errorf("unsupported fmap version %d.%d at %#x", f.VerMajor, f.VerMinor, off)
+	}
+	f.Areas = make([]AreaHeader, f.NAreas)
+	if err := binary.Read(sr, binary.LittleEndian, f.Areas); err != nil {
+		return nil, fmt.Errorf("reading %d areas: %w", f.NAreas, err)
+	}
+	return f, nil
+}
+
+// isFMap checks for the signature and a plausible version at off.
+func isFMap(r io.ReaderAt, off int64) bool {
+	var b [len(Signature) + 1]byte
+	if _, err := r.ReadAt(b[:], off); err != nil {
+		return false
+	}
+	return string(b[:len(Signature)]) == Signature && b[len(Signature)] == MajorVersion
+}
+
+// Find returns the offset of the FMAP in an image of the given size.
+//
+// Like flashrom, it first probes offsets at decreasing power-of-two
+// alignments, which finds the FMAP with few reads on slow media such as a
+// flash chip, and then falls back to a linear search.
+func Find(r io.ReaderAt, size int64) (int64, error) {
+	stride := int64(1)
+	for stride*2 <= size {
+		stride *= 2
+	}
+	for ; stride >= minStride; stride /= 2 {
+		for off := int64(0); off+int64(len(Signature)) <= size; off += stride {
+			// Offsets aligned to a larger stride have already
+			// been checked.
+			if off%(stride*2) == 0 && stride*2 <= size {
+				continue
+			}
+			if isFMap(r, off) {
+				return off, nil
+			}
+		}
+	}
+
+	const chunk = 64 * 1024
+	buf := make([]byte, chunk+len(Signature))
+	for off := int64(0); off < size; off += chunk {
+		n, err := r.ReadAt(buf, off)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		b := buf[:n]
+		for i := 0; ; {
+			j := bytes.Index(b[i:], []byte(Signature))
+			if j < 0 {
+				break
+			}
+			if isFMap(r, off+int64(i+j)) {
+				return off + int64(i+j), nil
+			}
+			i += j + 1
+		}
+	}
+	return 0, ErrNotFound
+}
+
+// Read finds and parses the FMAP in an image of the given size.
+func Read(r io.ReaderAt, size int64) (*FMap, error) {
+	off, err := Find(r, size)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(r, off)
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+func image(size int, off int) []byte {
+	img := bytes.Repeat([]byte{0xff}, size)
+	areas := []AreaHeader{
+		{Offset: 0, Size: uint32(size)},
+		{Offset: 0x100, Size: 0x200, Flags: AreaRO | AreaPreserve},
+	}
+	copy(areas[0].Name[:], "SI_ALL")
+	copy(areas[1].Name[:], "RO_VPD")
+	h := Header{VerMajor: MajorVersion, VerMinor: 1, Size: uint32(size), NAreas: uint16(len(areas))}
+	copy(h.Signature[:], Signature)
+	copy(h.Name[:], "FLASH")
+
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, h)
+	binary.Write(&b, binary.LittleEndian, areas)
+	copy(img[off:], b.Bytes())
+	return img
+}
+
+func TestRead(t *testing.T) {
+	for _, off := range []int{0, 0x1000, 0x8040, 0x3003} {
+		img := image(0x10000, off)
+		// A stray signature with a bad version must be skipped.
+		copy(img[0x20:], Signature+"\x07")
+		f, err := Read(bytes.NewReader(img), int64(len(img)))
+		if err != nil {
+			t.Fatalf("Read(FMAP at %#x) = %v", off, err)
+		}
+		if f.Offset != int64(off) || f.String() != "FLASH" || len(f.Areas) != 2 {
+			t.Errorf("Read(FMAP at %#x) = %+v", off, f)
+		}
+	}
+
+	if _, err := Read(bytes.NewReader(make([]byte, 0x10000)), 0x10000); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Read(no FMAP) = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestArea(t *testing.T) {
+	img := image(0x1000, 0x800)
+	f, err := Read(bytes.NewReader(img), int64(len(img)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := f.Area("RO_VPD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.String() != "RO_VPD" || a.End() != 0x300 || a.Flags.String() != "ro,preserve" {
+		t.Errorf("Area(RO_VPD) = %v, end %#x, flags %v", a, a.End(), a.Flags)
+	}
+	if _, err := f.Area("RW_VPD"); !errors.Is(err, ErrAreaNotFound) {
+		t.Errorf("Area(RW_VPD) = %v, want %v", err, ErrAreaNotFound)
+	}
+
+	copy(img[0x100:], "hello")
+	r, err := f.ReadArea(bytes.NewReader(img), "RO_VPD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil || len(b) != 0x200 || string(b[:5]) != "hello" {
+		t.Errorf("ReadArea(RO_VPD) = %q..., %d bytes, %v", b[:5], len(b), err)
+	}
+}
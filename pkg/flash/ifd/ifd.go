@@ -0,0 +1,190 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ifd parses the Intel Flash Descriptor (IFD), which splits the SPI
+// flash of Intel platforms into regions such as the descriptor itself, the
+// BIOS, the Management Engine (ME) and the Gigabit Ethernet (GbE)
+// configuration.
+//
+// Useful references:
+// * https://github.com/coreboot/coreboot/blob/main/util/ifdtool/ifdtool.h
+// * https://github.com/flashrom/flashrom/blob/main/ich_descriptors.c
+// * Intel 300 Series Chipset PCH datasheet, vol. 1, "SPI Flash Descriptor"
+package ifd
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Signature is the value of FLVALSIG, the first word of the descriptor.
+const Signature = 0x0ff0a55a
+
+// Offsets at which the signature is found. Since ICH8 the descriptor
+// starts with 16 bytes reserved for other uses.
+var signatureOffsets = []int64{0x10, 0}
+
+// MaxRegions is the largest number of regions in a descriptor.
+const MaxRegions = 16
+
+var (
+	// ErrNotFound is returned when an image has no flash descriptor.
+	ErrNotFound = errors.New("flash descriptor not found")
+
+	// ErrRegionNotFound is returned for regions that are not used.
+	ErrRegionNotFound = errors.New("flash descriptor region not found")
+)
+
+// RegionID is the index of a region in the descriptor.
+type RegionID int
+
+// Well-known regions.
+const (
+	RegionDescriptor RegionID = iota
+	RegionBIOS
+	RegionME
+	RegionGbE
+	RegionPD
+	RegionDevExp
+	RegionBIOS2
+	RegionMicrocode
+	RegionEC
+	RegionDevExp2
+	RegionIE
+	Region10GbE0
+	Region10GbE1
+	RegionReserved13
+	RegionReserved14
+	RegionPTT
+)
+
+// regionNames are the names flashrom's --ifd layout uses for regions.
+var regionNames = [MaxRegions]string{
+	"fd", "bios", "me", "gbe", "pd", "reg5", "bios2", "reg7",
+	"ec", "reg9", "ie", "10gbe0", "10gbe1", "reg13", "reg14", "ptt",
+}
+
+// String returns the flashrom name of the region, e.g. "bios".
+func (id RegionID) String() string {
+	if id < 0 || int(id) >= len(regionNames) {
+		return fmt.Sprintf("reg%d", int(id))
+	}
+	return regionNames[id]
+}
+
+// Region is one FLREG entry.
+type Region struct {
+	ID RegionID
+
+	// Base and Limit are the first and last byte of the region.
+	Base  uint32
+	Limit uint32
+}
+
+// Used returns whether the region is present in the flash. Unused
+// regions have a base above their limit.
+func (r *Region) Used() bool {
+	return r.Base <= r.Limit
+}
+
+// Size returns the size of the region in bytes, or 0 if it is unused.
+func (r *Region) Size() int64 {
+	if !r.Used() {
+		return 0
+	}
+	return int64(r.Limit) - int64(r.Base) + 1
+}
+
+// String implements fmt.Stringer.
+func (r *Region) String() string {
+	if !r.Used() {
+		return fmt.Sprintf("%s: unused", r.ID)
+	}
+	return fmt.Sprintf("%s: 0x%08x-0x%08x", r.ID, r.Base, r.Limit)
+}
+
+// Descriptor is a parsed flash descriptor.
+type Descriptor struct {
+	// Offset is the offset of the signature in the image.
+	Offset int64
+
+	// FLMAP0-2 are the descriptor map words.
+	FLMAP0 uint32
+	FLMAP1 uint32
+	FLMAP2 uint32
+
+	// Regions holds every region entry in the descriptor, used or not,
+	// indexed by RegionID.
+	Regions []Region
+}
+
+// FCBA returns the offset of the component section.
+func (d *Descriptor) FCBA() int64 { return int64(d.FLMAP0&0xff) << 4 }
+
+// FRBA returns the offset of the region section.
+func (d *Descriptor) FRBA() int64 { return int64((d.FLMAP0>>16)&0xff) << 4 }
+
+// FMBA returns the offset of the master section.
+func (d *Descriptor) FMBA() int64 { return int64(d.FLMAP1&0xff) << 4 }
+
+// Region returns the region with the given ID.
+func (d *Descriptor) Region(id RegionID) (*Region, error) {
+	if id < 0 || int(id) >= len(d.Regions) || !d.Regions[id].Used() {
+		return nil, fmt.Errorf("%v: %w", id, ErrRegionNotFound)
+	}
+	return &d.Regions[id], nil
+}
+
+// DecodeRegion decodes an FLREG entry, or an FREG register of the SPI
+// controller, which uses the same encoding. Base and limit are in units of
+// 4 KiB.
+func DecodeRegion(id RegionID, v uint32) Region {
+	return Region{
+		ID:    id,
+		Base:  (v & 0x7fff) << 12,
+		Limit: ((v>>16)&0x7fff)<<12 | 0xfff,
+	}
+}
+
+// Parse parses the flash descriptor at the start of r.
+func Parse(r io.ReaderAt) (*Descriptor, error) {
+	var hdr [4]uint32
+	for _, off := range signatureOffsets {
+		if err := binary.Read(io.NewSectionReader(r, off, 16), binary.LittleEndian, &hdr); err != nil {
+			return nil, fmt.Errorf("reading descriptor at %#x: %w", off, err)
+		}
+		if hdr[0] == Signature {
+			return parse(r, off, hdr[1], hdr[2], hdr[3])
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func parse(r io.ReaderAt, off int64, flmap0, flmap1, flmap2 uint32) (*Descriptor, error) {
+	d := &Descriptor{Offset: off, FLMAP0: flmap0, FLMAP1: flmap1, FLMAP2: flmap2}
+
+	// The NR field of FLMAP0 is not reliable across generations, so
+	// read region entries up to the next section, like ifdtool.
+	n := MaxRegions
+	frba := d.FRBA()
+	for _, next := range []int64{d.FCBA(), d.FMBA()} {
+		if next > frba && int((next-frba)/4) < n {
+			n = int((next - frba) / 4)
+		}
+	}
+
+	flreg := make([]uint32, n)
+	if err := binary.Read(io.NewSectionReader(r, frba, int64(n)*4), binary.LittleEndian, flreg); err != nil {
+		return nil, fmt.Errorf("reading %d regions at %#x: %w", n, frba, err)
+	}
+	for i, v := range flreg {
+		d.Regions = append(d.Regions, DecodeRegion(RegionID(i), v))
+	}
+	if len(d.Regions) == 0 || !d.Regions[RegionDescriptor].Used() || d.Regions[RegionDescriptor].Base != 0 {
+		return nil, fmt.Errorf("descriptor region is invalid: %w", ErrNotFound)
+	}
+	return d, nil
+}
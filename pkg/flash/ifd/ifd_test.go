@@ -0,0 +1,81 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ifd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// descriptor returns a 4 KiB descriptor region with the signature at
+// sigOff and the given FLREG entries.
+func descriptor(sigOff int, flreg ...uint32) []byte {
+	b := bytes.Repeat([]byte{0xff}, 0x1000)
+	binary.LittleEndian.PutUint32(b[sigOff:], Signature)
+	// FCBA 0x30, FRBA 0x40, FMBA 0x80.
+	binary.LittleEndian.PutUint32(b[sigOff+4:], 0x00040003)
+	binary.LittleEndian.PutUint32(b[sigOff+8:], 0x00000008)
+	for i := range 16 {
+		v := uint32(0x00007fff)
+		if i < len(flreg) {
+			v = flreg[i]
+		}
+		binary.LittleEndian.PutUint32(b[0x40+4*i:], v)
+	}
+	return b
+}
+
+func TestParse(t *testing.T) {
+	for _, sigOff := range []int{0x10, 0} {
+		d, err := Parse(bytes.NewReader(descriptor(sigOff, 0x00000000, 0x000f0008, 0x00070001)))
+		if err != nil {
+			t.Fatalf("Parse(signature at %#x) = %v", sigOff, err)
+		}
+		if d.Offset != int64(sigOff) || d.FRBA() != 0x40 || d.FMBA() != 0x80 || len(d.Regions) != MaxRegions {
+			t.Errorf("Parse() = %+v", d)
+		}
+
+		for _, tt := range []struct {
+			id          RegionID
+			base, limit uint32
+		}{
+			{RegionDescriptor, 0, 0xfff},
+			{RegionBIOS, 0x8000, 0xffff},
+			{RegionME, 0x1000, 0x7fff},
+		} {
+			r, err := d.Region(tt.id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Base != tt.base || r.Limit != tt.limit {
+				t.Errorf("Region(%v) = %v, want %#x-%#x", tt.id, r, tt.base, tt.limit)
+			}
+		}
+		if r, err := d.Region(RegionGbE); !errors.Is(err, ErrRegionNotFound) {
+			t.Errorf("Region(gbe) = %v, %v, want %v", r, err, ErrRegionNotFound)
+		}
+		if s := d.Regions[RegionBIOS].String(); s != "bios: 0x00008000-0x0000ffff" {
+			t.Errorf("String() = %q", s)
+		}
+		if s := d.Regions[RegionPD].String(); s != "pd: unused" {
+			t.Errorf("String() = %q", s)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(bytes.NewReader(make([]byte, 0x1000))); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Parse(blank) = %v, want %v", err, ErrNotFound)
+	}
+	// The descriptor region must start at 0.
+	if _, err := Parse(bytes.NewReader(descriptor(0x10, 0x00010001))); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Parse(bad descriptor region) = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := Parse(bytes.NewReader(make([]byte, 8))); err == nil {
+		t.Errorf("Parse(short) = nil, want error")
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package region reads, erases and writes named regions of a firmware
+// image, as described by the Intel Flash Descriptor or the FMAP. It works
+// on image files as well as on flash chips such as *flash.Flash, so an
+// updater can rewrite e.g. only the BIOS region and leave the ME alone.
+package region
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/u-root/u-root/pkg/flash/fmap"
+	"github.com/u-root/u-root/pkg/flash/ifd"
+)
+
+var (
+	// ErrNoLayout is returned when an image has neither a flash
+	// descriptor nor an FMAP.
+	ErrNoLayout = errors.New("no flash descriptor or fmap found")
+
+	// ErrNotFound is returned when a layout has no region of the given
+	// name.
+	ErrNotFound = errors.New("region not found")
+
+	// ErrVerify is returned when data read back after a write differs
+	// from what was written.
+	ErrVerify = errors.New("verification failed")
+)
+
+// Source says where a region is defined.
+type Source int
+
+// Region sources.
+const (
+	SourceIFD Source = iota
+	SourceFMAP
+)
+
+// String implements fmt.Stringer.
+func (s Source) String() string {
+	switch s {
+	case SourceIFD:
+		return "ifd"
+	case SourceFMAP:
+		return "fmap"
+	}
+	return fmt.Sprintf("Source(%d)", int(s))
+}
+
+// Region is a named range of a firmware image.
+type Region struct {
+	Name   string
+	Start  int64
+	Size   int64
+	Source Source
+}
+
+// End returns the offset one past the last byte of the region.
+func (r *Region) End() int64 {
+	return r.Start + r.Size
+}
+
+// String implements fmt.Stringer.
+func (r *Region) String() string {
+	return fmt.Sprintf("%s: 0x%08x-0x%08x (%s)", r.Name, r.Start, r.End()-1, r.Source)
+}
+
+// Layout is the list of regions of an image.
+type Layout []Region
+
+// FromIFD returns the used regions of a flash descriptor, named like
+// flashrom's --ifd layout ("fd", "bios", "me", ...).
+func FromIFD(d *ifd.Descriptor) Layout {
+	var l Layout
+	for _, r := range d.Regions {
+		if !r.Used() {
+			continue
+		}
+		l = append(l, Region{Name: r.ID.String(), Start: int64(r.Base), Size: r.Size(), Source: SourceIFD})
+	}
+	return l
+}
+
+// FromFMap returns the areas of an FMAP.
+func FromFMap(f *fmap.FMap) Layout {
+	var l Layout
+	for _, a := range f.Areas {
+		l = append(l, Region{Name: a.String(), Start: int64(a.Offset), Size: int64(a.Size), Source: SourceFMAP})
+	}
+	return l
+}
+
+// Detect returns the regions of the flash descriptor and the FMAP of an
+// image of the given size. Either may be missing, but not both.
+func Detect(r io.ReaderAt, size int64) (Layout, error) {
+	var l Layout
+	d, err := ifd.Parse(r)
+	if err == nil {
+		l = append(l, FromIFD(d)...)
+	} else if !errors.Is(err, ifd.ErrNotFound) && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	f, err := fmap.Read(r, size)
+	if err == nil {
+		l = append(l, FromFMap(f)...)
+	} else if !errors.Is(err, fmap.ErrNotFound) {
+		return nil, err
+	}
+	if len(l) == 0 {
+		return nil, ErrNoLayout
+	}
+	return l, nil
+}
+
+// Find returns the region with the given name.
+func (l Layout) Find(name string) (*Region, error) {
+	for i := range l {
+		if l[i].Name == name {
+			return &l[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%q: %w", name, ErrNotFound)
+}
+
+// Device is storage that must be erased before it is written, such as
+// *flash.Flash.
+type Device interface {
+	io.ReaderAt
+	io.WriterAt
+	EraseAt(n int64, off int64) (int64, error)
+}
+
+// Read returns the contents of region reg.
+func Read(r io.ReaderAt, reg *Region) ([]byte, error) {
+	b := make([]byte, reg.Size)
+	if _, err := r.ReadAt(b, reg.Start); err != nil {
+		return nil, fmt.Errorf("reading %v: %w", reg, err)
+	}
+	return b, nil
+}
+
+func checkAligned(reg *Region, eraseSize int64) error {
+	if eraseSize <= 0 || reg.Start%eraseSize != 0 || reg.Size%eraseSize != 0 {
+		return fmt.Errorf("region %v is not aligned to the erase size %#x: %w", reg, eraseSize, os.ErrInvalid)
+	}
+	return nil
+}
+
+// Erase erases region reg. The region must be aligned to eraseSize.
+func Erase(d Device, reg *Region, eraseSize int64) error {
+	if err := checkAligned(reg, eraseSize); err != nil {
+		return err
+	}
+	if _, err := d.EraseAt(reg.Size, reg.Start); err != nil {
+		return fmt.Errorf("erasing %v: %w", reg, err)
+	}
+	return nil
+}
+
+// Verify checks that region reg contains data.
+func Verify(r io.ReaderAt, reg *Region, data []byte) error {
+	got, err := Read(r, reg)
+	if err != nil {
+		return err
+	}
+	if len(data) != len(got) {
+		return fmt.Errorf("%v: have %d bytes, want %d: %w", reg, len(got), len(data), ErrVerify)
+	}
+	for i := range got {
+		if got[i] != data[i] {
+			return fmt.Errorf("%v: first difference at %#x: %w", reg, reg.Start+int64(i), ErrVerify)
+		}
+	}
+	return nil
+}
+
+// Write replaces the contents of region reg with data, then reads the
+// region back to verify it. data must be exactly as large as the region,
+// which must be aligned to eraseSize.
+//
+// Only erase blocks whose contents change are touched, and blocks are
+// only erased when the new data needs bits set that are clear, since
+// programming can only clear bits. Everything outside the region is left
+// alone.
+func Write(d Device, reg *Region, data []byte, eraseSize int64) error {
+	if int64(len(data)) != reg.Size {
+		return fmt.Errorf("%v: %d bytes of data for a region of %d bytes: %w", reg, len(data), reg.Size, os.ErrInvalid)
+	}
+	if err := checkAligned(reg, eraseSize); err != nil {
+		return err
+	}
+
+	cur := make([]byte, eraseSize)
+	for off := int64(0); off < reg.Size; off += eraseSize {
+		want := data[off : off+eraseSize]
+		if _, err := d.ReadAt(cur, reg.Start+off); err != nil {
+			return fmt.Errorf("reading %#x: %w", reg.Start+off, err)
+		}
+		if bytes.Equal(cur, want) {
+			continue
+		}
+		if needsErase(cur, want) {
+			if _, err := d.EraseAt(eraseSize, reg.Start+off); err != nil {
+				return fmt.Errorf("erasing %#x: %w", reg.Start+off, err)
+			}
+		}
+		if _, err := d.WriteAt(want, reg.Start+off); err != nil {
+			return fmt.Errorf("writing %#x: %w", reg.Start+off, err)
+		}
+	}
+	return Verify(d, reg, data)
+}
+
+// needsErase returns whether going from cur to want sets any bit.
+func needsErase(cur, want []byte) bool {
+	for i := range cur {
+		if cur[i]&want[i] != want[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteFromImage writes the region named name from image, a full firmware
+// image for the device, to d. The region is looked up in the layout of the
+// device, not the image, so a new image cannot move regions around.
+func WriteFromImage(d Device, size int64, image []byte, name string, eraseSize int64) error {
+	if int64(len(image)) != size {
+		return fmt.Errorf("image is %d bytes, device is %d bytes: %w", len(image), size, os.ErrInvalid)
+	}
+	l, err := Detect(d, size)
+	if err != nil {
+		return err
+	}
+	reg, err := l.Find(name)
+	if err != nil {
+		return err
+	}
+	return Write(d, reg, image[reg.Start:reg.End()], eraseSize)
+}
+
+// File makes an image file look like a Device. Erasing fills with 0xff.
+type File struct {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// EraseAt implements Device.
+func (f *File) EraseAt(n int64, off int64) (int64, error) {
+	w, err := f.WriteAt(bytes.Repeat([]byte{0xff}, int(n)), off)
+	return int64(w), err
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package region
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/flash/fmap"
+	"github.com/u-root/u-root/pkg/flash/ifd"
+)
+
+const (
+	imageSize = 0x10000
+	eraseSize = 0x1000
+)
+
+// newImage returns an image with a descriptor (fd 0-0xfff, me
+// 0x1000-0x7fff, bios 0x8000-0xffff) and an FMAP in the BIOS region.
+func newImage(fill byte) []byte {
+	img := bytes.Repeat([]byte{fill}, imageSize)
+	binary.LittleEndian.PutUint32(img[0x10:], ifd.Signature)
+	binary.LittleEndian.PutUint32(img[0x14:], 0x00040003)
+	binary.LittleEndian.PutUint32(img[0x18:], 0x00000008)
+	for i := range ifd.MaxRegions {
+		binary.LittleEndian.PutUint32(img[0x40+4*i:], 0x00007fff)
+	}
+	binary.LittleEndian.PutUint32(img[0x40:], 0x00000000)
+	binary.LittleEndian.PutUint32(img[0x44:], 0x000f0008)
+	binary.LittleEndian.PutUint32(img[0x48:], 0x00070001)
+
+	areas := []fmap.AreaHeader{
+		{Offset: 0x8000, Size: 0x1000},
+		{Offset: 0xe000, Size: 0x2000},
+	}
+	copy(areas[0].Name[:], "FMAP")
+	copy(areas[1].Name[:], "COREBOOT")
+	h := fmap.Header{VerMajor: fmap.MajorVersion, Size: imageSize, NAreas: uint16(len(areas))}
+	copy(h.Signature[:], fmap.Signature)
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, h)
+	binary.Write(&b, binary.LittleEndian, areas)
+	copy(img[0x8000:], b.Bytes())
+	return img
+}
+
+// flash simulates a flash chip: writes can only clear bits.
+type flash struct {
+	data   []byte
+	erases []int64
+	writes []int64
+}
+
+func (f *flash) ReadAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	return copy(p, f.data[off:]), nil
+}
+
+func (f *flash) WriteAt(p []byte, off int64) (int, error) {
+	for i := range p {
+		f.data[off+int64(i)] &= p[i]
+	}
+	f.writes = append(f.writes, off)
+	return len(p), nil
+}
+
+func (f *flash) EraseAt(n int64, off int64) (int64, error) {
+	if n%eraseSize != 0 || off%eraseSize != 0 {
+		return 0, os.ErrInvalid
+	}
+	copy(f.data[off:off+n], bytes.Repeat([]byte{0xff}, int(n)))
+	f.erases = append(f.erases, off)
+	return n, nil
+}
+
+func TestDetect(t *testing.T) {
+	l, err := Detect(bytes.NewReader(newImage(0xff)), imageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Layout{
+		{Name: "fd", Start: 0, Size: 0x1000, Source: SourceIFD},
+		{Name: "bios", Start: 0x8000, Size: 0x8000, Source: SourceIFD},
+		{Name: "me", Start: 0x1000, Size: 0x7000, Source: SourceIFD},
+		{Name: "FMAP", Start: 0x8000, Size: 0x1000, Source: SourceFMAP},
+		{Name: "COREBOOT", Start: 0xe000, Size: 0x2000, Source: SourceFMAP},
+	}
+	if !reflect.DeepEqual(l, want) {
+		t.Errorf("Detect() = %v, want %v", l, want)
+	}
+	if _, err := l.Find("gbe"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(gbe) = %v, want %v", err, ErrNotFound)
+	}
+
+	if _, err := Detect(bytes.NewReader(make([]byte, imageSize)), imageSize); !errors.Is(err, ErrNoLayout) {
+		t.Errorf("Detect(blank) = %v, want %v", err, ErrNoLayout)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	f := &flash{data: newImage(0xff)}
+	// Make the ME region recognizable, so we can tell it is untouched.
+	copy(f.data[0x1000:0x8000], bytes.Repeat([]byte{0x4d}, 0x7000))
+	me := bytes.Clone(f.data[0x1000:0x8000])
+
+	// The new image has a different ME, which must not be written, and
+	// a BIOS that differs from the flash in two blocks: 0xa000 only
+	// clears bits, 0xc000 also sets some.
+	img := newImage(0xff)
+	copy(img[0x1000:0x8000], bytes.Repeat([]byte{0x00}, 0x7000))
+	f.data[0xc000] = 0x00
+	img[0xa123] = 0x12
+	img[0xc000] = 0x34
+
+	if err := WriteFromImage(f, imageSize, img, "bios", eraseSize); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(f.data[0x8000:], img[0x8000:]) {
+		t.Errorf("BIOS region was not written")
+	}
+	if !bytes.Equal(f.data[0x1000:0x8000], me) {
+		t.Errorf("ME region was modified")
+	}
+	if want := []int64{0xc000}; !reflect.DeepEqual(f.erases, want) {
+		t.Errorf("erased blocks %#x, want %#x", f.erases, want)
+	}
+	if want := []int64{0xa000, 0xc000}; !reflect.DeepEqual(f.writes, want) {
+		t.Errorf("wrote blocks %#x, want %#x", f.writes, want)
+	}
+
+	l, err := Detect(f, imageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg, err := l.Find("COREBOOT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Erase(f, reg, eraseSize); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(f, reg, bytes.Repeat([]byte{0xff}, int(reg.Size))); err != nil {
+		t.Errorf("Verify() after Erase = %v", err)
+	}
+	if err := Verify(f, reg, make([]byte, reg.Size)); !errors.Is(err, ErrVerify) {
+		t.Errorf("Verify(wrong data) = %v, want %v", err, ErrVerify)
+	}
+}
+
+// brokenFlash drops writes to one address.
+type brokenFlash struct {
+	flash
+}
+
+func (f *brokenFlash) WriteAt(p []byte, off int64) (int, error) {
+	n, err := f.flash.WriteAt(p, off)
+	if off <= 0x9000 && 0x9000 < off+int64(len(p)) {
+		f.data[0x9000] = 0xff
+	}
+	return n, err
+}
+
+func TestWriteErrors(t *testing.T) {
+	f := &brokenFlash{flash{data: newImage(0xff)}}
+	reg := &Region{Name: "bios", Start: 0x8000, Size: 0x8000}
+	data := make([]byte, reg.Size)
+	if err := Write(f, reg, data, eraseSize); !errors.Is(err, ErrVerify) {
+		t.Errorf("Write(broken flash) = %v, want %v", err, ErrVerify)
+	}
+	if err := Write(f, reg, data[:1], eraseSize); !errors.Is(err, os.ErrInvalid) {
+		t.Errorf("Write(short data) = %v, want %v", err, os.ErrInvalid)
+	}
+	unaligned := &Region{Name: "x", Start: 0x8100, Size: 0x1000}
+	if err := Write(f, unaligned, data[:0x1000], eraseSize); !errors.Is(err, os.ErrInvalid) {
+		t.Errorf("Write(unaligned) = %v, want %v", err, os.ErrInvalid)
+	}
+	if err := WriteFromImage(f, imageSize, data, "bios", eraseSize); !errors.Is(err, os.ErrInvalid) {
+		t.Errorf("WriteFromImage(short image) = %v, want %v", err, os.ErrInvalid)
+	}
+}
+
+func TestFile(t *testing.T) {
+	p := t.TempDir() + "/bios.bin"
+	if err := os.WriteFile(p, newImage(0x00), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fd, err := os.OpenFile(p, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+
+	f := &File{ReaderAt: fd, WriterAt: fd}
+	img := newImage(0x00)
+	img[0xf000] = 0xaa
+	if err := WriteFromImage(f, imageSize, img, "COREBOOT", eraseSize); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, img) {
+		t.Errorf("image file does not match written image")
+	}
+}
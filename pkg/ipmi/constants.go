@@ -13,6 +13,7 @@ const (
 
 	// Net functions
 	_IPMI_NETFN_CHASSIS   NetFn = 0x0
+	_IPMI_NETFN_SENSOR    NetFn = 0x4
 	_IPMI_NETFN_APP       NetFn = 0x6
 	_IPMI_NETFN_STORAGE   NetFn = 0xA
 	_IPMI_NETFN_TRANSPORT NetFn = 0xC
@@ -28,11 +29,26 @@ const (
 	SET_SYSTEM_INFO_PARAMETERS Command = 0x58
 	BMC_ADD_SEL                Command = 0x44
 
+	// Session Commands
+	BMC_SET_SESSION_PRIVILEGE_LEVEL Command = 0x3B
+	BMC_CLOSE_SESSION               Command = 0x3C
+
 	// Chassis Device Commands
 	BMC_GET_CHASSIS_STATUS Command = 0x01
 
+	// Sensor Device Commands
+	BMC_GET_SENSOR_READING Command = 0x2D
+
+	// SDR Repository Commands
+	BMC_GET_SDR_REPO_INFO Command = 0x20
+	BMC_RESERVE_SDR_REPO  Command = 0x22
+	BMC_GET_SDR           Command = 0x23
+
 	// SEL device Commands
-	BMC_GET_SEL_INFO Command = 0x40
+	BMC_GET_SEL_INFO  Command = 0x40
+	BMC_RESERVE_SEL   Command = 0x42
+	BMC_GET_SEL_ENTRY Command = 0x43
+	BMC_CLEAR_SEL     Command = 0x47
 
 	// LAN Device Commands
 	BMC_GET_LAN_CONFIG Command = 0x02
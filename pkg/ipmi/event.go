@@ -0,0 +1,161 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import "fmt"
+
+// Event/Reading type codes.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 42.1.
+const (
+	EVENT_READING_SENSOR_SPECIFIC = 0x6F
+	EVENT_READING_OEM_FIRST       = 0x70
+)
+
+// genericEvents holds the offsets of the generic event/reading types, indexed
+// by event/reading type code.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, table 42-2.
+var genericEvents = map[uint8][]string{
+	EVENT_READING_THRESHOLD: {
+		"Lower Non-critical going low", "Lower Non-critical going high",
+		"Lower Critical going low", "Lower Critical going high",
+		"Lower Non-recoverable going low", "Lower Non-recoverable going high",
+		"Upper Non-critical going low", "Upper Non-critical going high",
+		"Upper Critical going low", "Upper Critical going high",
+		"Upper Non-recoverable going low", "Upper Non-recoverable going high",
+	},
+	0x02: {"Transition to Idle", "Transition to Active", "Transition to Busy"},
+	0x03: {"State Deasserted", "State Asserted"},
+	0x04: {"Predictive Failure Deasserted", "Predictive Failure Asserted"},
+	0x05: {"Limit Not Exceeded", "Limit Exceeded"},
+	0x06: {"Performance Met", "Performance Lags"},
+	0x07: {
+		"Transition to OK", "Transition to Non-critical from OK",
+		"Transition to Critical from less severe",
+		"Transition to Non-recoverable from less severe",
+		"Transition to Non-critical from more severe",
+		"Transition to Critical from Non-recoverable",
+		"Transition to Non-recoverable", "Monitor", "Informational",
+	},
+	0x08: {"Device Absent", "Device Present"},
+	0x09: {"Device Disabled", "Device Enabled"},
+	0x0A: {
+		"Transition to Running", "Transition to In Test",
+		"Transition to Power Off", "Transition to On Line",
+		"Transition to Off Line", "Transition to Off Duty",
+		"Transition to Degraded", "Transition to Power Save", "Install Error",
+	},
+	0x0B: {
+		"Fully Redundant", "Redundancy Lost", "Redundancy Degraded",
+		"Non-redundant: Sufficient from Redundant",
+		"Non-redundant: Sufficient from Insufficient",
+		"Non-redundant: Insufficient Resources",
+		"Redundancy Degraded from Fully Redundant",
+		"Redundancy Degraded from Non-redundant",
+	},
+	0x0C: {"D0 Power State", "D1 Power State", "D2 Power State", "D3 Power State"},
+}
+
+// sensorSpecificEvents holds the offsets of the sensor-specific event/reading
+// type, indexed by sensor type.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, table 42-3.
+var sensorSpecificEvents = map[SensorType][]string{
+	0x05: {
+		"General Chassis intrusion", "Drive Bay intrusion",
+		"I/O Card area intrusion", "Processor area intrusion",
+		"System unplugged from LAN", "Unauthorized dock", "FAN area intrusion",
+	},
+	0x07: {
+		"IERR", "Thermal Trip", "FRB1/BIST failure",
+		"FRB2/Hang in POST failure", "FRB3/Processor Startup/Init failure",
+		"Configuration Error", "SM BIOS Uncorrectable CPU-complex Error",
+		"Presence detected", "Disabled", "Terminator presence detected",
+		"Throttled", "Uncorrectable machine check exception",
+		"Correctable machine check error",
+	},
+	0x08: {
+		"Presence detected", "Failure detected", "Predictive failure",
+		"Power Supply AC lost", "AC lost or out-of-range",
+		"AC out-of-range, but present", "Config Error",
+	},
+	0x09: {
+		"Power off/down", "Power cycle", "240VA power down",
+		"Interlock power down", "AC lost", "Soft-power control failure",
+		"Failure detected", "Predictive failure",
+	},
+	0x0C: {
+		"Correctable ECC", "Uncorrectable ECC", "Parity", "Memory Scrub Error",
+		"Memory Device Disabled", "Correctable ECC logging limit reached",
+		"Presence Detected", "Configuration Error", "Spare", "Throttled",
+		"Critical Overtemperature",
+	},
+	0x0D: {
+		"Drive Present", "Drive Fault", "Predictive Failure", "Hot Spare",
+		"Parity Check In Progress", "In Critical Array", "In Failed Array",
+		"Rebuild In Progress", "Rebuild Aborted",
+	},
+	0x0F: {"System Firmware Error", "System Firmware Hang", "System Firmware Progress"},
+	0x10: {
+		"Correctable memory error logging disabled", "Event logging disabled",
+		"Log area reset/cleared", "All event logging disabled", "Log full",
+		"Log almost full",
+	},
+	0x12: {
+		"System Reconfigured", "OEM System boot event",
+		"Undetermined system hardware failure", "Entry added to auxiliary log",
+		"PEF Action", "Timestamp Clock Sync",
+	},
+	0x13: {
+		"NMI/Diag Interrupt", "Bus Timeout", "I/O Channel check NMI",
+		"Software NMI", "PCI PERR", "PCI SERR", "EISA failsafe timeout",
+		"Bus Correctable error", "Bus Uncorrectable error", "Fatal NMI",
+		"Bus Fatal Error", "Bus Degraded",
+	},
+	0x14: {
+		"Power Button pressed", "Sleep Button pressed", "Reset Button pressed",
+		"FRU latch open", "FRU service request button",
+	},
+	0x1D: {
+		"Initiated by power up", "Initiated by hard reset",
+		"Initiated by warm reset", "User requested PXE boot",
+		"Automatic boot to diagnostic", "OS initiated hard reset",
+		"OS initiated warm reset", "System Restart",
+	},
+	0x1F: {
+		"A: boot completed", "C: boot completed", "PXE boot completed",
+		"Diagnostic boot completed", "CD-ROM boot completed",
+		"ROM boot completed", "boot completed - device not specified",
+		"Base OS/Hypervisor Installation started",
+		"Base OS/Hypervisor Installation completed",
+		"Base OS/Hypervisor Installation aborted",
+		"Base OS/Hypervisor Installation failed",
+	},
+	0x20: {
+		"Critical stop during OS load", "Run-time critical stop",
+		"OS graceful stop", "OS graceful shutdown",
+		"PEF initiated soft shutdown", "Agent not responding",
+	},
+	0x23: {
+		"Timer expired", "Hard reset", "Power down", "Power cycle",
+		"", "", "", "", "Timer interrupt",
+	},
+	0x25: {"Entity Present", "Entity Absent", "Entity Disabled"},
+}
+
+// eventDescription describes the event offset of a system event.
+func eventDescription(st SensorType, eventType, offset uint8) string {
+	var names []string
+	switch {
+	case eventType == EVENT_READING_SENSOR_SPECIFIC:
+		names = sensorSpecificEvents[st]
+	case eventType >= EVENT_READING_OEM_FIRST:
+		return fmt.Sprintf("OEM event type 0x%02x offset 0x%02x", eventType, offset)
+	default:
+		names = genericEvents[eventType]
+	}
+	if int(offset) < len(names) && names[offset] != "" {
+		return names[offset]
+	}
+	return fmt.Sprintf("event type 0x%02x offset 0x%02x", eventType, offset)
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// bmcHandler answers a request. The returned data starts with the
+// completion code.
+type bmcHandler func(netfn NetFn, cmd Command, data []byte) []byte
+
+// fakeBMC implements syscalls by handing requests to a bmcHandler instead of
+// the OpenIPMI driver.
+type fakeBMC struct {
+	handle bmcHandler
+
+	msgid int64
+	resp  []byte
+}
+
+func (b *fakeBMC) syscall(trap, a1, a2, a3 uintptr) (uintptr, uintptr, unix.Errno) {
+	if trap != unix.SYS_IOCTL {
+		return 0, 0, unix.EINVAL
+	}
+	switch a2 {
+	case _IPMICTL_SEND_COMMAND:
+		req := *(**request)(unsafe.Pointer(&a3))
+		var data []byte
+		if req.msg.DataLen > 0 {
+			data = append(data, unsafe.Slice((*byte)(req.msg.Data), req.msg.DataLen)...)
+		}
+		b.msgid = req.msgid
+		b.resp = b.handle(req.msg.Netfn, req.msg.Cmd, data)
+		return 0, 0, 0
+	case _IPMICTL_RECEIVE_MSG_TRUNC:
+		resp := *(**response)(unsafe.Pointer(&a3))
+		resp.msgid = b.msgid
+		resp.msg.DataLen = uint16(copy(unsafe.Slice((*byte)(resp.msg.Data), _IPMI_BUF_SIZE), b.resp))
+		return 0, 0, 0
+	}
+	return 0, 0, unix.EINVAL
+}
+
+func (b *fakeBMC) fileSyscallConn(f *os.File) (syscall.RawConn, error) {
+	return f.SyscallConn()
+}
+
+func (b *fakeBMC) fileSetReadDeadline(f *os.File, t time.Duration) error {
+	return nil
+}
+
+func (b *fakeBMC) connRead(f func(fd uintptr) bool, conn syscall.RawConn) error {
+	return conn.Read(f)
+}
+
+// newFakeIPMI returns an IPMI whose requests are answered by h.
+func newFakeIPMI(t *testing.T, h bmcHandler) *IPMI {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "ipmi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := &IPMI{dev: &dev{f: f, syscalls: &fakeBMC{handle: h}}}
+	t.Cleanup(func() { i.Close() })
+	return i
+}
@@ -43,6 +43,9 @@ var (
 // IPMI represents access to the IPMI interface.
 type IPMI struct {
 	*dev
+
+	// lan is set instead of dev for a session with a BMC over the network.
+	lan *lan
 }
 
 // SendRecv sends the IPMI message, receives the response, and returns the
@@ -65,6 +68,14 @@ func (i *IPMI) SendRecv(netfn NetFn, cmd Command, data []byte) ([]byte, error) {
 // RawSendRecv sends the IPMI message, receives the response, and returns the
 // response data.
 func (i *IPMI) RawSendRecv(msg Msg) ([]byte, error) {
+	if i.lan != nil {
+		var data []byte
+		if msg.DataLen > 0 {
+			data = unsafe.Slice((*byte)(msg.Data), msg.DataLen)
+		}
+		return i.lan.sendRecv(msg.Netfn, msg.Cmd, data)
+	}
+
 	addr := &systemInterfaceAddr{
 		addrType: _IPMI_SYSTEM_INTERFACE_ADDR_TYPE,
 		channel:  _IPMI_BMC_CHANNEL,
@@ -425,7 +436,10 @@ func (i *IPMI) RawCmd(param []byte) ([]byte, error) {
 	return i.RawSendRecv(msg)
 }
 
-// Close closes the file attached to ipmi
+// Close closes the file attached to ipmi, or the session with the BMC.
 func (i *IPMI) Close() error {
+	if i.lan != nil {
+		return i.lan.Close()
+	}
 	return i.dev.Close()
 }
@@ -0,0 +1,354 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// PrivilegeLevel is the privilege level of an IPMI session.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 6.8.
+type PrivilegeLevel byte
+
+// Session privilege levels.
+const (
+	PRIV_LEVEL_CALLBACK PrivilegeLevel = 0x01
+	PRIV_LEVEL_USER     PrivilegeLevel = 0x02
+	PRIV_LEVEL_OPERATOR PrivilegeLevel = 0x03
+	PRIV_LEVEL_ADMIN    PrivilegeLevel = 0x04
+	PRIV_LEVEL_OEM      PrivilegeLevel = 0x05
+)
+
+// Algorithms of cipher suite 3, the only one supported.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 13.28.
+const (
+	authRAKPHMACSHA1      = 0x01
+	integrityHMACSHA1_96  = 0x01
+	confidentialityAESCBC = 0x01
+)
+
+const (
+	// rakpNameOnlyLookup selects the user by name only, not by name and
+	// privilege level.
+	rakpNameOnlyLookup = 0x10
+	rakpRandomLen      = 16
+	rakpGUIDLen        = 16
+	rakpMaxUsername    = 16
+	rakpMaxPassword    = 20
+
+	// seqWindow is the number of sequence numbers below the highest one
+	// received for which a late packet is still accepted.
+	seqWindow = 32
+
+	defaultLANTimeout = 2 * time.Second
+	defaultLANRetries = 3
+)
+
+// LANConfig configures an IPMI-over-LAN session.
+type LANConfig struct {
+	Username string
+	Password string
+	// Privilege is the privilege level requested for the session. It
+	// defaults to PRIV_LEVEL_ADMIN.
+	Privilege PrivilegeLevel
+	// BMCKey is the BMC key K_G. If it is not set, the password is used
+	// in its place, as for BMCs that have no BMC key.
+	BMCKey []byte
+	// Timeout is how long to wait for a response before resending the
+	// request. It defaults to 2 seconds.
+	Timeout time.Duration
+	// Retries is how often a request is resent. It defaults to 3.
+	Retries int
+}
+
+// lan is an RMCP+ session with a BMC.
+type lan struct {
+	conn net.Conn
+	cfg  LANConfig
+
+	// consoleID is our session ID, bmcID the BMC's.
+	consoleID uint32
+	bmcID     uint32
+	keys      *sessionKeys
+
+	// seq is the session sequence number of the last packet sent; rqSeq
+	// the sequence number of the last request message.
+	seq   uint32
+	rqSeq byte
+	tag   byte
+
+	// recvSeq is the highest sequence number received, recvMask marks
+	// which of the seqWindow numbers below it have been received.
+	recvSeq  uint32
+	recvMask uint32
+}
+
+// OpenLAN establishes an RMCP+ session (IPMI v2.0 "lanplus") with the BMC at
+// addr, which is a host name or address with an optional port, 623 by
+// default. The session uses cipher suite 3: RAKP-HMAC-SHA1 authentication,
+// HMAC-SHA1-96 integrity and AES-CBC-128 confidentiality.
+//
+// All IPMI methods of the returned IPMI are sent through the session.
+func OpenLAN(addr string, cfg LANConfig) (*IPMI, error) {
+	if len(cfg.Username) > rakpMaxUsername {
+		return nil, fmt.Errorf("user name is longer than %d bytes", rakpMaxUsername)
+	}
+	if len(cfg.Password) > rakpMaxPassword {
+		return nil, fmt.Errorf("password is longer than %d bytes", rakpMaxPassword)
+	}
+	if cfg.Privilege == 0 {
+		cfg.Privilege = PRIV_LEVEL_ADMIN
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultLANTimeout
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = defaultLANRetries
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, rmcpPort)
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	l := &lan{conn: conn, cfg: cfg}
+	if err := l.open(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &IPMI{lan: l}, nil
+}
+
+// open negotiates the session with Open Session and RAKP messages 1 to 4 and
+// then raises its privilege level.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, sections 13.17 to 13.23.
+func (l *lan) open() error {
+	var id [4]byte
+	for l.consoleID == 0 {
+		if _, err := rand.Read(id[:]); err != nil {
+			return err
+		}
+		l.consoleID = binary.LittleEndian.Uint32(id[:])
+	}
+
+	// Open Session.
+	req := []byte{l.nextTag(), byte(l.cfg.Privilege), 0, 0}
+	req = binary.LittleEndian.AppendUint32(req, l.consoleID)
+	req = append(req,
+		0x00, 0, 0, 8, authRAKPHMACSHA1, 0, 0, 0,
+		0x01, 0, 0, 8, integrityHMACSHA1_96, 0, 0, 0,
+		0x02, 0, 0, 8, confidentialityAESCBC, 0, 0, 0)
+	resp, err := l.handshake(payloadOpenSessionReq, payloadOpenSessionResp, req, 36)
+	if err != nil {
+		return fmt.Errorf("open session: %w", err)
+	}
+	if resp[16] != authRAKPHMACSHA1 || resp[24] != integrityHMACSHA1_96 || resp[32] != confidentialityAESCBC {
+		return errors.New("open session: BMC does not support cipher suite 3")
+	}
+	l.bmcID = binary.LittleEndian.Uint32(resp[8:])
+
+	// RAKP message 1 and 2.
+	var rm [rakpRandomLen]byte
+	if _, err := rand.Read(rm[:]); err != nil {
+		return err
+	}
+	role := rakpNameOnlyLookup | byte(l.cfg.Privilege)
+	user := []byte{role, byte(len(l.cfg.Username))}
+	user = append(user, l.cfg.Username...)
+
+	req = []byte{l.nextTag(), 0, 0, 0}
+	req = binary.LittleEndian.AppendUint32(req, l.bmcID)
+	req = append(req, rm[:]...)
+	req = append(req, role, 0, 0, byte(len(l.cfg.Username)))
+	req = append(req, l.cfg.Username...)
+	resp, err = l.handshake(payloadRAKP1, payloadRAKP2, req, 8+rakpRandomLen+rakpGUIDLen+20)
+	if err != nil {
+		return fmt.Errorf("RAKP 2: %w", err)
+	}
+	rc := resp[8 : 8+rakpRandomLen]
+	guid := resp[8+rakpRandomLen : 8+rakpRandomLen+rakpGUIDLen]
+	code := resp[8+rakpRandomLen+rakpGUIDLen:]
+
+	kuid := []byte(l.cfg.Password)
+	consoleID := binary.LittleEndian.AppendUint32(nil, l.consoleID)
+	bmcID := binary.LittleEndian.AppendUint32(nil, l.bmcID)
+	if !hmac.Equal(code[:20], hmacSHA1(kuid, consoleID, bmcID, rm[:], rc, guid, user)) {
+		return errors.New("RAKP 2: key exchange authentication code mismatch, wrong user name or password")
+	}
+
+	// RAKP message 3 and 4.
+	req = []byte{l.nextTag(), 0, 0, 0}
+	req = append(req, bmcID...)
+	req = append(req, hmacSHA1(kuid, rc, consoleID, user)...)
+	resp, err = l.handshake(payloadRAKP3, payloadRAKP4, req, 8+hmacSHA1_96Len)
+	if err != nil {
+		return fmt.Errorf("RAKP 4: %w", err)
+	}
+	kg := l.cfg.BMCKey
+	if kg == nil {
+		kg = kuid
+	}
+	sik := hmacSHA1(kg, rm[:], rc, user)
+	if !hmac.Equal(resp[8:8+hmacSHA1_96Len], hmacSHA1(sik, rm[:], bmcID, guid)[:hmacSHA1_96Len]) {
+		return errors.New("RAKP 4: integrity check value mismatch")
+	}
+	l.keys = newSessionKeys(sik)
+
+	// Sessions start at user level.
+	data, err := l.sendRecv(_IPMI_NETFN_APP, BMC_SET_SESSION_PRIVILEGE_LEVEL, []byte{byte(l.cfg.Privilege)})
+	if err != nil {
+		return fmt.Errorf("set session privilege level: %w", err)
+	}
+	if len(data) < 2 || PrivilegeLevel(data[1]&0x0f) != l.cfg.Privilege {
+		return fmt.Errorf("set session privilege level: BMC did not grant level %d", l.cfg.Privilege)
+	}
+	return nil
+}
+
+func (l *lan) nextTag() byte {
+	l.tag++
+	return l.tag
+}
+
+// handshake sends a session setup message and returns the response, which
+// must carry the same message tag, a zero status code and our session ID.
+func (l *lan) handshake(reqType, respType byte, req []byte, n int) ([]byte, error) {
+	p := &rmcpPacket{payloadType: reqType, payload: req}
+	resp, err := l.exchange(func() *rmcpPacket { return p }, func(p *rmcpPacket) bool {
+		return p.payloadType == respType && len(p.payload) >= 2 && p.payload[0] == req[0]
+	})
+	if err != nil {
+		return nil, err
+	}
+	b := resp.payload
+	if b[1] != 0 {
+		return nil, fmt.Errorf("BMC returned RMCP+ status code %#02x", b[1])
+	}
+	if len(b) < n {
+		return nil, errRMCPTruncated
+	}
+	if binary.LittleEndian.Uint32(b[4:]) != l.consoleID {
+		return nil, errors.New("response is for another session")
+	}
+	return b, nil
+}
+
+// exchange sends the packet returned by next until a packet for which match
+// returns true arrives, and returns that packet. Packets that fail the
+// integrity check or do not match are dropped.
+func (l *lan) exchange(next func() *rmcpPacket, match func(*rmcpPacket) bool) (*rmcpPacket, error) {
+	buf := make([]byte, _IPMI_BUF_SIZE)
+	for range l.cfg.Retries + 1 {
+		b, err := next().marshal(l.keys)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := l.conn.Write(b); err != nil {
+			return nil, err
+		}
+		if err := l.conn.SetReadDeadline(time.Now().Add(l.cfg.Timeout)); err != nil {
+			return nil, err
+		}
+		for {
+			n, err := l.conn.Read(buf)
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if p, err := unmarshalRMCP(buf[:n], l.keys); err == nil && match(p) {
+				return p, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no response from BMC after %d tries", l.cfg.Retries+1)
+}
+
+// acceptSeq reports whether a packet with session sequence number seq is new
+// and within the window of accepted numbers, and records it as received.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 6.12.13.
+func (l *lan) acceptSeq(seq uint32) bool {
+	switch {
+	case seq == 0:
+		return false
+	case seq > l.recvSeq:
+		if d := seq - l.recvSeq; d < seqWindow {
+			l.recvMask = l.recvMask<<d | 1<<(d-1)
+		} else {
+			l.recvMask = 0
+		}
+		l.recvSeq = seq
+		return true
+	}
+	d := l.recvSeq - seq
+	if d == 0 || d > seqWindow || l.recvMask&(1<<(d-1)) != 0 {
+		return false
+	}
+	l.recvMask |= 1 << (d - 1)
+	return true
+}
+
+// sendRecv sends an IPMI request through the session and returns the
+// response data, which starts with the completion code.
+func (l *lan) sendRecv(netfn NetFn, cmd Command, data []byte) ([]byte, error) {
+	l.rqSeq = (l.rqSeq + 1) & 0x3f
+	msg := marshalLANRequest(netfn, cmd, l.rqSeq, data)
+
+	var resp []byte
+	_, err := l.exchange(func() *rmcpPacket {
+		// Every packet, including a resent one, needs a new
+		// sequence number. Zero is not used.
+		if l.seq++; l.seq == 0 {
+			l.seq++
+		}
+		return &rmcpPacket{
+			payloadType:   payloadIPMI,
+			encrypted:     true,
+			authenticated: true,
+			sessionID:     l.bmcID,
+			seq:           l.seq,
+			payload:       msg,
+		}
+	}, func(p *rmcpPacket) bool {
+		if p.payloadType != payloadIPMI || !p.encrypted || !p.authenticated || p.sessionID != l.consoleID {
+			return false
+		}
+		rnetfn, rqSeq, rcmd, rdata, err := unmarshalLANResponse(p.payload)
+		if err != nil || rnetfn != netfn|1 || rqSeq != l.rqSeq || rcmd != cmd || len(rdata) < 1 {
+			return false
+		}
+		if !l.acceptSeq(p.seq) {
+			return false
+		}
+		resp = rdata
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cc := CompletionCode(resp[0]); cc != IPMI_CC_OK {
+		return nil, CompletionError(cc)
+	}
+	return resp, nil
+}
+
+// Close closes the session and the connection to the BMC.
+func (l *lan) Close() error {
+	_, err := l.sendRecv(_IPMI_NETFN_APP, BMC_CLOSE_SESSION, binary.LittleEndian.AppendUint32(nil, l.bmcID))
+	if cerr := l.conn.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,331 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// RMCP+ status codes sent by fakeLANBMC.
+const (
+	rmcpStatusUnauthorizedName = 0x0D
+	rmcpStatusInvalidICV       = 0x0F
+)
+
+// fakeLANBMC is the managed system side of RMCP+ sessions on a local UDP
+// socket. IPMI requests are answered by handle.
+type fakeLANBMC struct {
+	t        *testing.T
+	conn     *net.UDPConn
+	user     string
+	password string
+	handle   bmcHandler
+
+	mu sync.Mutex
+	// dropIPMI is the number of IPMI requests to ignore.
+	dropIPMI int
+	closed   bool
+	seqs     []uint32
+
+	bmcID, consoleID uint32
+	rm, rc, guid     []byte
+	user1            []byte // role, name length and name from RAKP 1
+	keys             *sessionKeys
+	seq              uint32
+}
+
+func newFakeLANBMC(t *testing.T, user, password string, h bmcHandler) *fakeLANBMC {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeLANBMC{
+		t:        t,
+		conn:     conn,
+		user:     user,
+		password: password,
+		handle:   h,
+		bmcID:    0x0badcafe,
+		rc:       bytes.Repeat([]byte{0xc5}, rakpRandomLen),
+		guid:     bytes.Repeat([]byte{0x9d}, rakpGUIDLen),
+	}
+	t.Cleanup(func() { conn.Close() })
+	go b.serve()
+	return b
+}
+
+func (b *fakeLANBMC) addr() string {
+	return b.conn.LocalAddr().String()
+}
+
+func (b *fakeLANBMC) serve() {
+	buf := make([]byte, _IPMI_BUF_SIZE)
+	for {
+		n, from, err := b.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		resp := b.packet(buf[:n])
+		b.mu.Unlock()
+		if resp == nil {
+			continue
+		}
+		out, err := resp.marshal(b.keys)
+		if err != nil {
+			b.t.Error(err)
+			return
+		}
+		b.conn.WriteTo(out, from)
+	}
+}
+
+func (b *fakeLANBMC) packet(in []byte) *rmcpPacket {
+	p, err := unmarshalRMCP(in, b.keys)
+	if err != nil {
+		b.t.Errorf("BMC received a bad packet: %v", err)
+		return nil
+	}
+	req := p.payload
+	bmcID := binary.LittleEndian.AppendUint32(nil, b.bmcID)
+
+	switch p.payloadType {
+	case payloadOpenSessionReq:
+		b.consoleID = binary.LittleEndian.Uint32(req[4:])
+		consoleID := binary.LittleEndian.AppendUint32(nil, b.consoleID)
+		resp := append([]byte{req[0], 0, req[1], 0}, consoleID...)
+		resp = append(resp, bmcID...)
+		return &rmcpPacket{payloadType: payloadOpenSessionResp, payload: append(resp, req[8:32]...)}
+
+	case payloadRAKP1:
+		consoleID := binary.LittleEndian.AppendUint32(nil, b.consoleID)
+		b.rm = append([]byte(nil), req[8:24]...)
+		b.user1 = append([]byte{req[24]}, req[27:]...)
+		if string(req[28:]) != b.user {
+			return &rmcpPacket{payloadType: payloadRAKP2, payload: append([]byte{req[0], rmcpStatusUnauthorizedName, 0, 0}, consoleID...)}
+		}
+		resp := append([]byte{req[0], 0, 0, 0}, consoleID...)
+		resp = append(resp, b.rc...)
+		resp = append(resp, b.guid...)
+		resp = append(resp, hmacSHA1([]byte(b.password), consoleID, bmcID, b.rm, b.rc, b.guid, b.user1)...)
+		return &rmcpPacket{payloadType: payloadRAKP2, payload: resp}
+
+	case payloadRAKP3:
+		consoleID := binary.LittleEndian.AppendUint32(nil, b.consoleID)
+		if !bytes.Equal(req[8:], hmacSHA1([]byte(b.password), b.rc, consoleID, b.user1)) {
+			return &rmcpPacket{payloadType: payloadRAKP4, payload: append([]byte{req[0], rmcpStatusInvalidICV, 0, 0}, consoleID...)}
+		}
+		sik := hmacSHA1([]byte(b.password), b.rm, b.rc, b.user1)
+		resp := append([]byte{req[0], 0, 0, 0}, consoleID...)
+		resp = append(resp, hmacSHA1(sik, b.rm, bmcID, b.guid)[:hmacSHA1_96Len]...)
+		// Only packets after RAKP 4 are protected.
+		defer func() { b.keys = newSessionKeys(sik) }()
+		return &rmcpPacket{payloadType: payloadRAKP4, payload: resp}
+
+	case payloadIPMI:
+		if !p.encrypted || !p.authenticated || p.sessionID != b.bmcID {
+			b.t.Errorf("BMC received an unprotected IPMI message")
+			return nil
+		}
+		b.seqs = append(b.seqs, p.seq)
+		if b.dropIPMI > 0 {
+			b.dropIPMI--
+			return nil
+		}
+		if checksum(req[:3]) != 0 || checksum(req[3:]) != 0 {
+			b.t.Errorf("BMC received a message with a bad checksum: % x", req)
+			return nil
+		}
+		netfn, rqSeq, cmd, data := NetFn(req[1]>>2), req[4]>>2, Command(req[5]), req[6:len(req)-1]
+
+		var rdata []byte
+		switch {
+		case netfn == _IPMI_NETFN_APP && cmd == BMC_SET_SESSION_PRIVILEGE_LEVEL:
+			rdata = []byte{0, data[0]}
+		case netfn == _IPMI_NETFN_APP && cmd == BMC_CLOSE_SESSION:
+			b.closed = binary.LittleEndian.Uint32(data) == b.bmcID
+			rdata = []byte{0}
+		default:
+			rdata = b.handle(netfn, cmd, data)
+		}
+
+		resp := []byte{ipmbRemoteConsoleAddr, byte(netfn|1) << 2}
+		resp = append(resp, checksum(resp))
+		resp = append(resp, ipmbBMCAddr, rqSeq<<2, byte(cmd))
+		resp = append(resp, rdata...)
+		resp = append(resp, checksum(resp[3:]))
+		b.seq++
+		return &rmcpPacket{
+			payloadType:   payloadIPMI,
+			encrypted:     true,
+			authenticated: true,
+			sessionID:     b.consoleID,
+			seq:           b.seq,
+			payload:       resp,
+		}
+	}
+	b.t.Errorf("BMC received unexpected payload type %#x", p.payloadType)
+	return nil
+}
+
+func deviceIDHandler(netfn NetFn, cmd Command, data []byte) []byte {
+	switch {
+	case netfn == _IPMI_NETFN_APP && cmd == BMC_GET_DEVICE_ID:
+		return []byte{0, 0x20, 0x81, 0x02, 0x13, 0x02, 0xbf, 0x57, 0x01, 0x00, 0x49, 0x0b, 0, 0, 0, 0}
+	case netfn == _IPMI_NETFN_STORAGE && cmd == BMC_GET_SEL_INFO:
+		return []byte{byte(IPMI_CC_NODE_BUSY)}
+	}
+	return []byte{byte(IPMI_CC_INV_CMD)}
+}
+
+func TestLAN(t *testing.T) {
+	b := newFakeLANBMC(t, "admin", "hunter2", deviceIDHandler)
+	i, err := OpenLAN(b.addr(), LANConfig{Username: "admin", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("OpenLAN() = %v", err)
+	}
+
+	id, err := i.GetDeviceID()
+	if err != nil {
+		t.Fatalf("GetDeviceID() = %v", err)
+	}
+	if id.DeviceID != 0x20 || id.IpmiVersion != 0x02 || id.ManufacturerID != [3]byte{0x57, 0x01, 0x00} {
+		t.Errorf("GetDeviceID() = %+v", id)
+	}
+
+	if _, err := i.GetSELInfo(); !errors.Is(err, CompletionError(IPMI_CC_NODE_BUSY)) {
+		t.Errorf("GetSELInfo() = %v, want %v", err, CompletionError(IPMI_CC_NODE_BUSY))
+	}
+
+	if err := i.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		t.Errorf("BMC session was not closed")
+	}
+	for n, seq := range b.seqs {
+		if seq != uint32(n+1) {
+			t.Errorf("session sequence numbers = %v, want 1, 2, ...", b.seqs)
+			break
+		}
+	}
+}
+
+func TestLANRetry(t *testing.T) {
+	b := newFakeLANBMC(t, "admin", "hunter2", deviceIDHandler)
+	i, err := OpenLAN(b.addr(), LANConfig{Username: "admin", Password: "hunter2", Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("OpenLAN() = %v", err)
+	}
+	defer i.Close()
+
+	b.mu.Lock()
+	b.dropIPMI = 2
+	b.mu.Unlock()
+	if _, err := i.GetDeviceID(); err != nil {
+		t.Fatalf("GetDeviceID() with two lost requests = %v", err)
+	}
+
+	b.mu.Lock()
+	b.dropIPMI = defaultLANRetries + 1
+	b.mu.Unlock()
+	if _, err := i.GetDeviceID(); err == nil {
+		t.Errorf("GetDeviceID() with all requests lost = nil, want error")
+	}
+}
+
+func TestLANAuthentication(t *testing.T) {
+	b := newFakeLANBMC(t, "admin", "hunter2", deviceIDHandler)
+	for _, tt := range []struct {
+		cfg  LANConfig
+		want string
+	}{
+		{LANConfig{Username: "admin", Password: "hunter3"}, "RAKP 2: key exchange authentication code mismatch"},
+		{LANConfig{Username: "root", Password: "hunter2"}, "RAKP 2: BMC returned RMCP+ status code 0x0d"},
+		{LANConfig{Username: "admin", Password: strings.Repeat("x", 21)}, "password is longer"},
+	} {
+		if _, err := OpenLAN(b.addr(), tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("OpenLAN(%+v) = %v, want error containing %q", tt.cfg, err, tt.want)
+		}
+	}
+}
+
+func TestAcceptSeq(t *testing.T) {
+	l := &lan{}
+	for _, tt := range []struct {
+		seq  uint32
+		want bool
+	}{
+		{0, false},
+		{1, true},
+		{1, false},
+		{5, true},
+		{3, true},
+		{3, false},
+		{4, true},
+		{40, true},
+		{8, true},
+		{7, false},
+		{5, false},
+	} {
+		if got := l.acceptSeq(tt.seq); got != tt.want {
+			t.Errorf("acceptSeq(%d) = %t, want %t", tt.seq, got, tt.want)
+		}
+	}
+}
+
+func TestRMCPPacket(t *testing.T) {
+	k := newSessionKeys([]byte("session integrity key"))
+	for n := range 40 {
+		p := &rmcpPacket{
+			payloadType:   payloadIPMI,
+			encrypted:     true,
+			authenticated: true,
+			sessionID:     0x12345678,
+			seq:           uint32(n + 1),
+			payload:       bytes.Repeat([]byte{byte(n)}, n),
+		}
+		b, err := p.marshal(k)
+		if err != nil {
+			t.Fatalf("marshal() = %v", err)
+		}
+		if (len(b)-rmcpHeaderLen-hmacSHA1_96Len)%4 != 0 {
+			t.Errorf("authenticated part of %d-byte payload is %d bytes, want a multiple of 4", n, len(b)-rmcpHeaderLen-hmacSHA1_96Len)
+		}
+		got, err := unmarshalRMCP(b, k)
+		if err != nil {
+			t.Fatalf("unmarshalRMCP() of %d-byte payload = %v", n, err)
+		}
+		if got.sessionID != p.sessionID || got.seq != p.seq || !got.encrypted || !got.authenticated || !bytes.Equal(got.payload, p.payload) {
+			t.Errorf("unmarshalRMCP(marshal(%+v)) = %+v", p, got)
+		}
+
+		b[rmcpHeaderLen+rmcpPlusSessionHeaderLen] ^= 1
+		if _, err := unmarshalRMCP(b, k); !errors.Is(err, errRMCPIntegrity) {
+			t.Errorf("unmarshalRMCP() of tampered packet = %v, want %v", err, errRMCPIntegrity)
+		}
+	}
+
+	b, err := (&rmcpPacket{payloadType: payloadIPMI, authenticated: true, payload: []byte{1}}).marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unmarshalRMCP(b, newSessionKeys([]byte("other key"))); !errors.Is(err, errRMCPIntegrity) {
+		t.Errorf("unmarshalRMCP() with the wrong key = %v, want %v", err, errRMCPIntegrity)
+	}
+	if _, err := unmarshalRMCP(b[:10], k); !errors.Is(err, errRMCPTruncated) {
+		t.Errorf("unmarshalRMCP() of truncated packet = %v, want %v", err, errRMCPTruncated)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// RMCP and RMCP+ framing.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 13.
+const (
+	rmcpVersion   = 0x06
+	rmcpNoAck     = 0xFF
+	rmcpClassIPMI = 0x07
+	rmcpPort      = "623"
+
+	authTypeRMCPPlus = 0x06
+
+	payloadIPMI              = 0x00
+	payloadOpenSessionReq    = 0x10
+	payloadOpenSessionResp   = 0x11
+	payloadRAKP1             = 0x12
+	payloadRAKP2             = 0x13
+	payloadRAKP3             = 0x14
+	payloadRAKP4             = 0x15
+	payloadEncrypted         = 0x80
+	payloadAuthenticated     = 0x40
+	payloadTypeMask          = 0x3F
+	integrityNextHeader      = 0x07
+	integrityPad             = 0xFF
+	hmacSHA1_96Len           = 12
+	aesCBC128BlockLen        = aes.BlockSize
+	rmcpHeaderLen            = 4
+	rmcpPlusSessionHeaderLen = 12
+
+	ipmbBMCAddr           = 0x20
+	ipmbRemoteConsoleAddr = 0x81
+)
+
+var (
+	errRMCPTruncated = errors.New("RMCP packet truncated")
+	errRMCPIntegrity = errors.New("RMCP+ packet failed the integrity check")
+)
+
+// sessionKeys are the keys derived from the Session Integrity Key once an
+// RMCP+ session is established with cipher suite 3: HMAC-SHA1-96 integrity
+// and AES-CBC-128 confidentiality.
+type sessionKeys struct {
+	// k1 authenticates packets.
+	k1 []byte
+	// k2 holds the AES key in its first 16 bytes.
+	k2 []byte
+}
+
+// newSessionKeys derives K1 and K2 from the Session Integrity Key.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 13.32.
+func newSessionKeys(sik []byte) *sessionKeys {
+	k := func(c byte) []byte {
+		var b [sha1.Size]byte
+		for i := range b {
+			b[i] = c
+		}
+		return hmacSHA1(sik, b[:])
+	}
+	return &sessionKeys{k1: k(0x01), k2: k(0x02)}
+}
+
+func hmacSHA1(key []byte, data ...[]byte) []byte {
+	h := hmac.New(sha1.New, key)
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// rmcpPacket is an RMCP+ session packet, i.e. one with authentication type
+// RMCP+. IPMI v1.5 packets are not supported.
+type rmcpPacket struct {
+	payloadType   byte
+	encrypted     bool
+	authenticated bool
+	sessionID     uint32
+	seq           uint32
+	payload       []byte
+}
+
+// marshal encodes the packet, encrypting and authenticating it with k as
+// requested by its flags.
+func (p *rmcpPacket) marshal(k *sessionKeys) ([]byte, error) {
+	if (p.encrypted || p.authenticated) && k == nil {
+		return nil, errors.New("RMCP+ session is not established")
+	}
+	payload := p.payload
+	if p.encrypted {
+		var err error
+		if payload, err = encryptAESCBC(k.k2[:16], payload); err != nil {
+			return nil, err
+		}
+	}
+
+	ptype := p.payloadType
+	if p.encrypted {
+		ptype |= payloadEncrypted
+	}
+	if p.authenticated {
+		ptype |= payloadAuthenticated
+	}
+
+	b := make([]byte, 0, rmcpHeaderLen+rmcpPlusSessionHeaderLen+len(payload)+8+hmacSHA1_96Len)
+	b = append(b, rmcpVersion, 0, rmcpNoAck, rmcpClassIPMI)
+	b = append(b, authTypeRMCPPlus, ptype)
+	b = binary.LittleEndian.AppendUint32(b, p.sessionID)
+	b = binary.LittleEndian.AppendUint32(b, p.seq)
+	b = binary.LittleEndian.AppendUint16(b, uint16(len(payload)))
+	b = append(b, payload...)
+
+	if p.authenticated {
+		// Pad so that the session part, from the authentication type
+		// to the next header field, is a multiple of 4 bytes.
+		n := (4 - (len(b)-rmcpHeaderLen+2)%4) % 4
+		for range n {
+			b = append(b, integrityPad)
+		}
+		b = append(b, byte(n), integrityNextHeader)
+		b = append(b, hmacSHA1(k.k1, b[rmcpHeaderLen:])[:hmacSHA1_96Len]...)
+	}
+	return b, nil
+}
+
+// unmarshalRMCP decodes an RMCP+ packet, checking and decrypting it with k
+// as required by its flags.
+func unmarshalRMCP(b []byte, k *sessionKeys) (*rmcpPacket, error) {
+	if len(b) < rmcpHeaderLen+rmcpPlusSessionHeaderLen {
+		return nil, errRMCPTruncated
+	}
+	if b[0] != rmcpVersion || b[3] != rmcpClassIPMI {
+		return nil, fmt.Errorf("not an RMCP IPMI packet: version %#x, class %#x", b[0], b[3])
+	}
+	if b[4] != authTypeRMCPPlus {
+		return nil, fmt.Errorf("unsupported authentication type %#x", b[4])
+	}
+
+	p := &rmcpPacket{
+		payloadType:   b[5] & payloadTypeMask,
+		encrypted:     b[5]&payloadEncrypted != 0,
+		authenticated: b[5]&payloadAuthenticated != 0,
+		sessionID:     binary.LittleEndian.Uint32(b[6:]),
+		seq:           binary.LittleEndian.Uint32(b[10:]),
+	}
+	end := rmcpHeaderLen + rmcpPlusSessionHeaderLen + int(binary.LittleEndian.Uint16(b[14:]))
+	if len(b) < end {
+		return nil, errRMCPTruncated
+	}
+	payload := b[rmcpHeaderLen+rmcpPlusSessionHeaderLen : end]
+
+	if (p.encrypted || p.authenticated) && k == nil {
+		return nil, errors.New("RMCP+ session is not established")
+	}
+	if p.authenticated {
+		n := (4 - (end-rmcpHeaderLen+2)%4) % 4
+		trailer := end + n + 2
+		if len(b) != trailer+hmacSHA1_96Len || int(b[trailer-2]) != n || b[trailer-1] != integrityNextHeader {
+			return nil, errRMCPIntegrity
+		}
+		if !hmac.Equal(hmacSHA1(k.k1, b[rmcpHeaderLen:trailer])[:hmacSHA1_96Len], b[trailer:]) {
+			return nil, errRMCPIntegrity
+		}
+	}
+	if p.encrypted {
+		var err error
+		if payload, err = decryptAESCBC(k.k2[:16], payload); err != nil {
+			return nil, err
+		}
+	}
+	p.payload = append([]byte(nil), payload...)
+	return p, nil
+}
+
+// encryptAESCBC encrypts data as an AES-CBC-128 payload: a random IV followed
+// by the data, padded with the bytes 1, 2, 3, ... and the pad length.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 13.29.
+func encryptAESCBC(key, data []byte) ([]byte, error) {
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	n := (aesCBC128BlockLen - (len(data)+1)%aesCBC128BlockLen) % aesCBC128BlockLen
+	b := make([]byte, aesCBC128BlockLen, aesCBC128BlockLen+len(data)+n+1)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	b = append(b, data...)
+	for i := range n {
+		b = append(b, byte(i+1))
+	}
+	b = append(b, byte(n))
+	cipher.NewCBCEncrypter(c, b[:aesCBC128BlockLen]).CryptBlocks(b[aesCBC128BlockLen:], b[aesCBC128BlockLen:])
+	return b, nil
+}
+
+// decryptAESCBC is the inverse of encryptAESCBC.
+func decryptAESCBC(key, b []byte) ([]byte, error) {
+	if len(b) < 2*aesCBC128BlockLen || len(b)%aesCBC128BlockLen != 0 {
+		return nil, fmt.Errorf("AES-CBC-128 payload of %d bytes is not a whole number of blocks", len(b))
+	}
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, len(b)-aesCBC128BlockLen)
+	cipher.NewCBCDecrypter(c, b[:aesCBC128BlockLen]).CryptBlocks(data, b[aesCBC128BlockLen:])
+
+	n := int(data[len(data)-1])
+	if n >= aesCBC128BlockLen {
+		return nil, fmt.Errorf("invalid AES-CBC-128 pad length %d", n)
+	}
+	data = data[:len(data)-1-n]
+	for i, p := range data[len(data) : len(data)+n] {
+		if p != byte(i+1) {
+			return nil, errors.New("invalid AES-CBC-128 padding")
+		}
+	}
+	return data, nil
+}
+
+// checksum returns the two's complement checksum of b.
+func checksum(b []byte) byte {
+	var s byte
+	for _, c := range b {
+		s += c
+	}
+	return -s
+}
+
+// marshalLANRequest encodes an IPMI request message for the LAN interface,
+// addressed to the BMC.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 13.8.
+func marshalLANRequest(netfn NetFn, cmd Command, rqSeq byte, data []byte) []byte {
+	b := []byte{ipmbBMCAddr, byte(netfn) << 2}
+	b = append(b, checksum(b))
+	b = append(b, ipmbRemoteConsoleAddr, rqSeq<<2, byte(cmd))
+	b = append(b, data...)
+	return append(b, checksum(b[3:]))
+}
+
+// unmarshalLANResponse decodes an IPMI response message from the LAN
+// interface. The returned data starts with the completion code.
+func unmarshalLANResponse(b []byte) (netfn NetFn, rqSeq byte, cmd Command, data []byte, err error) {
+	if len(b) < 8 {
+		return 0, 0, 0, nil, fmt.Errorf("IPMI response of %d bytes is too short", len(b))
+	}
+	if checksum(b[:3]) != 0 || checksum(b[3:]) != 0 {
+		return 0, 0, 0, nil, errors.New("IPMI response checksum mismatch")
+	}
+	return NetFn(b[1] >> 2), b[4] >> 2, Command(b[5]), b[6 : len(b)-1], nil
+}
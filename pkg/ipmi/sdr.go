@@ -0,0 +1,179 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// SDRRecordType is the type of a Sensor Data Record.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 43.
+type SDRRecordType byte
+
+// SDR record types.
+const (
+	SDR_FULL_SENSOR      SDRRecordType = 0x01
+	SDR_COMPACT_SENSOR   SDRRecordType = 0x02
+	SDR_EVENT_ONLY       SDRRecordType = 0x03
+	SDR_ENTITY_ASSOC     SDRRecordType = 0x08
+	SDR_DEVICE_ENTITY    SDRRecordType = 0x09
+	SDR_GENERIC_LOCATOR  SDRRecordType = 0x10
+	SDR_FRU_LOCATOR      SDRRecordType = 0x11
+	SDR_MC_LOCATOR       SDRRecordType = 0x12
+	SDR_MC_CONFIRMATION  SDRRecordType = 0x13
+	SDR_BMC_CHANNEL_INFO SDRRecordType = 0x14
+	SDR_OEM              SDRRecordType = 0xC0
+)
+
+const (
+	sdrHeaderLen = 5
+	// sdrReadChunk is the number of bytes read per Get SDR request. Many
+	// BMCs cannot return more than that in one response.
+	sdrReadChunk           = 16
+	sdrLastRecordID        = 0xFFFF
+	sdrMaxReservationTries = 5
+)
+
+// SDRHeader is the header common to all Sensor Data Records.
+type SDRHeader struct {
+	RecordID uint16
+	Version  byte
+	Type     SDRRecordType
+	// Length is the number of bytes in the record following the header.
+	Length byte
+}
+
+// SDR is a Sensor Data Record read from the SDR Repository.
+type SDR struct {
+	SDRHeader
+	// Body holds the record key and body bytes that follow the header.
+	Body []byte
+}
+
+// SDRRepositoryInfo holds information about the SDR Repository reported by the BMC via IPMI
+type SDRRepositoryInfo struct {
+	Version       byte
+	RecordCount   uint16
+	FreeSpace     uint16
+	LastAddTime   uint32
+	LastEraseTime uint32
+	OpSupport     byte
+}
+
+var errSDRTruncated = errors.New("SDR response truncated")
+
+// GetSDRRepositoryInfo retrieves the SDR Repository information from the IPMI interface.
+func (i *IPMI) GetSDRRepositoryInfo() (*SDRRepositoryInfo, error) {
+	data, err := i.SendRecv(_IPMI_NETFN_STORAGE, BMC_GET_SDR_REPO_INFO, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewReader(data[1:])
+
+	var info SDRRepositoryInfo
+	if err := binary.Read(buf, binary.LittleEndian, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ReserveSDRRepository reserves the SDR Repository and returns the
+// reservation ID needed to read records in parts.
+func (i *IPMI) ReserveSDRRepository() (uint16, error) {
+	data, err := i.SendRecv(_IPMI_NETFN_STORAGE, BMC_RESERVE_SDR_REPO, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < 3 {
+		return 0, errSDRTruncated
+	}
+	return binary.LittleEndian.Uint16(data[1:]), nil
+}
+
+// getSDRPart reads n bytes at offset off of the record with the given ID.
+func (i *IPMI) getSDRPart(reservation, recordID uint16, off, n byte) (uint16, []byte, error) {
+	var req [6]byte
+	binary.LittleEndian.PutUint16(req[0:], reservation)
+	binary.LittleEndian.PutUint16(req[2:], recordID)
+	req[4] = off
+	req[5] = n
+
+	data, err := i.SendRecv(_IPMI_NETFN_STORAGE, BMC_GET_SDR, req[:])
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(data) < 3+int(n) {
+		return 0, nil, fmt.Errorf("%w: record %#04x: got %d bytes at offset %d, want %d", errSDRTruncated, recordID, len(data)-3, off, n)
+	}
+	return binary.LittleEndian.Uint16(data[1:]), data[3 : 3+int(n)], nil
+}
+
+// GetSDR reads the record with the given ID from the SDR Repository. It
+// returns the record and the ID of the next record, which is 0xFFFF after the
+// last record. The record is read in parts, which requires a reservation ID
+// from ReserveSDRRepository.
+func (i *IPMI) GetSDR(reservation, recordID uint16) (*SDR, uint16, error) {
+	next, hdr, err := i.getSDRPart(reservation, recordID, 0, sdrHeaderLen)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sdr := &SDR{}
+	if err := binary.Read(bytes.NewReader(hdr), binary.LittleEndian, &sdr.SDRHeader); err != nil {
+		return nil, 0, err
+	}
+
+	for len(sdr.Body) < int(sdr.Length) {
+		n := min(int(sdr.Length)-len(sdr.Body), sdrReadChunk)
+		_, part, err := i.getSDRPart(reservation, recordID, byte(sdrHeaderLen+len(sdr.Body)), byte(n))
+		if err != nil {
+			return nil, 0, err
+		}
+		sdr.Body = append(sdr.Body, part...)
+	}
+	return sdr, next, nil
+}
+
+// GetSDRs reads all records from the SDR Repository. If the reservation is
+// canceled while reading, e.g. because the repository was modified, reading
+// starts over with a new reservation.
+func (i *IPMI) GetSDRs() ([]*SDR, error) {
+	var err error
+	for range sdrMaxReservationTries {
+		var sdrs []*SDR
+		if sdrs, err = i.getSDRs(); !errors.Is(err, CompletionError(IPMI_CC_RES_CANCELED)) {
+			return sdrs, err
+		}
+	}
+	return nil, err
+}
+
+func (i *IPMI) getSDRs() ([]*SDR, error) {
+	reservation, err := i.ReserveSDRRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	var sdrs []*SDR
+	seen := map[uint16]bool{}
+	for id := uint16(0); id != sdrLastRecordID; {
+		if seen[id] {
+			return nil, fmt.Errorf("SDR repository loops at record %#04x", id)
+		}
+		seen[id] = true
+
+		sdr, next, err := i.GetSDR(reservation, id)
+		if err != nil {
+			return nil, err
+		}
+		sdrs = append(sdrs, sdr)
+		id = next
+	}
+	return sdrs, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeSDRRepo serves SDRs from records, keyed by record ID in order.
+type fakeSDRRepo struct {
+	records     [][]byte
+	reservation uint16
+	canceled    bool
+	// cancelAfter cancels the reservation after that many Get SDR requests.
+	cancelAfter int
+	reads       int
+	maxRead     int
+}
+
+func (r *fakeSDRRepo) handle(netfn NetFn, cmd Command, data []byte) []byte {
+	if netfn != _IPMI_NETFN_STORAGE {
+		return []byte{byte(IPMI_CC_INV_CMD)}
+	}
+	switch cmd {
+	case BMC_GET_SDR_REPO_INFO:
+		return []byte{0, 0x51, byte(len(r.records)), 0, 0x00, 0x10, 1, 0, 0, 0, 2, 0, 0, 0, 0x0f}
+	case BMC_RESERVE_SDR_REPO:
+		r.reservation++
+		r.canceled = false
+		return binary.LittleEndian.AppendUint16([]byte{0}, r.reservation)
+	case BMC_GET_SDR:
+		if len(data) != 6 {
+			return []byte{byte(IPMI_CC_REQ_DATA_INV_LENGTH)}
+		}
+		r.reads++
+		if r.cancelAfter > 0 && r.reads == r.cancelAfter {
+			r.canceled = true
+		}
+		res := binary.LittleEndian.Uint16(data[0:])
+		id := int(binary.LittleEndian.Uint16(data[2:]))
+		off, n := int(data[4]), int(data[5])
+		if off > 0 && (r.canceled || res != r.reservation) {
+			return []byte{byte(IPMI_CC_RES_CANCELED)}
+		}
+		if id >= len(r.records) {
+			return []byte{byte(IPMI_CC_REQ_DATA_NOT_PRESENT)}
+		}
+		r.maxRead = max(r.maxRead, n)
+		next := uint16(id + 1)
+		if int(next) == len(r.records) {
+			next = sdrLastRecordID
+		}
+		rec := r.records[id]
+		if off+n > len(rec) {
+			return []byte{byte(IPMI_CC_PARAM_OUT_OF_RANGE)}
+		}
+		resp := binary.LittleEndian.AppendUint16([]byte{0}, next)
+		return append(resp, rec[off:off+n]...)
+	}
+	return []byte{byte(IPMI_CC_INV_CMD)}
+}
+
+func sdrRecord(id uint16, typ SDRRecordType, body []byte) []byte {
+	rec := binary.LittleEndian.AppendUint16(nil, id)
+	rec = append(rec, 0x51, byte(typ), byte(len(body)))
+	return append(rec, body...)
+}
+
+func TestGetSDRRepositoryInfo(t *testing.T) {
+	repo := &fakeSDRRepo{records: make([][]byte, 3)}
+	i := newFakeIPMI(t, repo.handle)
+
+	info, err := i.GetSDRRepositoryInfo()
+	if err != nil {
+		t.Fatalf("GetSDRRepositoryInfo() = %v", err)
+	}
+	want := &SDRRepositoryInfo{
+		Version:       0x51,
+		RecordCount:   3,
+		FreeSpace:     0x1000,
+		LastAddTime:   1,
+		LastEraseTime: 2,
+		OpSupport:     0x0f,
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("GetSDRRepositoryInfo() = %+v, want %+v", info, want)
+	}
+}
+
+func TestGetSDRs(t *testing.T) {
+	long := make([]byte, 60)
+	for j := range long {
+		long[j] = byte(j)
+	}
+	records := [][]byte{
+		sdrRecord(0, SDR_FULL_SENSOR, long),
+		sdrRecord(1, SDR_FRU_LOCATOR, []byte{1, 2, 3}),
+		sdrRecord(2, SDR_OEM, nil),
+	}
+	want := []*SDR{
+		{SDRHeader: SDRHeader{RecordID: 0, Version: 0x51, Type: SDR_FULL_SENSOR, Length: 60}, Body: long},
+		{SDRHeader: SDRHeader{RecordID: 1, Version: 0x51, Type: SDR_FRU_LOCATOR, Length: 3}, Body: []byte{1, 2, 3}},
+		{SDRHeader: SDRHeader{RecordID: 2, Version: 0x51, Type: SDR_OEM}},
+	}
+
+	for _, tt := range []struct {
+		name        string
+		cancelAfter int
+		wantReserve uint16
+	}{
+		{name: "ok", wantReserve: 1},
+		{name: "reservation canceled", cancelAfter: 3, wantReserve: 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeSDRRepo{records: records, cancelAfter: tt.cancelAfter}
+			i := newFakeIPMI(t, repo.handle)
+
+			got, err := i.GetSDRs()
+			if err != nil {
+				t.Fatalf("GetSDRs() = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetSDRs() = %v, want %v", got, want)
+			}
+			if repo.reservation != tt.wantReserve {
+				t.Errorf("got %d reservations, want %d", repo.reservation, tt.wantReserve)
+			}
+			if repo.maxRead > sdrReadChunk {
+				t.Errorf("read %d bytes at once, want at most %d", repo.maxRead, sdrReadChunk)
+			}
+		})
+	}
+}
+
+func TestGetSDRsErrors(t *testing.T) {
+	// A repository that always cancels the reservation.
+	repo := &fakeSDRRepo{records: [][]byte{sdrRecord(0, SDR_OEM, make([]byte, 20))}, cancelAfter: 2}
+	i := newFakeIPMI(t, func(netfn NetFn, cmd Command, data []byte) []byte {
+		if cmd == BMC_RESERVE_SDR_REPO {
+			repo.reads = 0
+		}
+		return repo.handle(netfn, cmd, data)
+	})
+	if _, err := i.GetSDRs(); !errors.Is(err, CompletionError(IPMI_CC_RES_CANCELED)) {
+		t.Errorf("GetSDRs() = %v, want %v", err, CompletionError(IPMI_CC_RES_CANCELED))
+	}
+
+	// A truncated response.
+	i = newFakeIPMI(t, func(netfn NetFn, cmd Command, data []byte) []byte {
+		if cmd == BMC_RESERVE_SDR_REPO {
+			return []byte{0, 1, 0}
+		}
+		return []byte{0, 0xff, 0xff, 1, 0}
+	})
+	if _, err := i.GetSDRs(); !errors.Is(err, errSDRTruncated) {
+		t.Errorf("GetSDRs() = %v, want %v", err, errSDRTruncated)
+	}
+}
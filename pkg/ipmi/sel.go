@@ -0,0 +1,229 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// SEL record types.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 32.
+const (
+	SEL_SYSTEM_EVENT_RECORD = 0x02
+	SEL_OEM_TS_FIRST        = 0xC0
+	SEL_OEM_NTS_FIRST       = 0xE0
+)
+
+const (
+	selRecordLen    = 16
+	selFirstEntry   = 0x0000
+	selLastEntry    = 0xFFFF
+	selReadAll      = 0xFF
+	selEraseStart   = 0xAA
+	selEraseStatus  = 0x00
+	selEraseDone    = 0x01
+	selEraseTries   = 50
+	selPreInitLimit = 0x20000000
+)
+
+// selErasePoll is the time to wait between erasure status queries.
+var selErasePoll = 100 * time.Millisecond
+
+var errSELTruncated = errors.New("SEL entry truncated")
+
+// ReserveSEL reserves the SEL and returns the reservation ID needed to clear
+// it or to read entries in parts.
+func (i *IPMI) ReserveSEL() (uint16, error) {
+	data, err := i.SendRecv(_IPMI_NETFN_STORAGE, BMC_RESERVE_SEL, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < 3 {
+		return 0, errSELTruncated
+	}
+	return binary.LittleEndian.Uint16(data[1:]), nil
+}
+
+// GetSELEntry reads the SEL entry with the given record ID. ID 0x0000 is the
+// first entry and 0xFFFF the last one. It returns the entry and the ID of the
+// next entry, which is 0xFFFF after the last entry.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 31.5.
+func (i *IPMI) GetSELEntry(recordID uint16) (*Event, uint16, error) {
+	// A reservation is only required for partial reads; the whole record
+	// is read at once.
+	var req [6]byte
+	binary.LittleEndian.PutUint16(req[2:], recordID)
+	req[5] = selReadAll
+
+	data, err := i.SendRecv(_IPMI_NETFN_STORAGE, BMC_GET_SEL_ENTRY, req[:])
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(data) < 3+selRecordLen {
+		return nil, 0, fmt.Errorf("%w: record %#04x: got %d bytes, want %d", errSELTruncated, recordID, len(data)-3, selRecordLen)
+	}
+
+	e, err := unmarshallEvent(data[3 : 3+selRecordLen])
+	if err != nil {
+		return nil, 0, err
+	}
+	return e, binary.LittleEndian.Uint16(data[1:]), nil
+}
+
+// SELEntries returns an iterator over the entries of the SEL, from the first
+// to the last one. Iteration stops after the first error.
+func (i *IPMI) SELEntries() iter.Seq2[*Event, error] {
+	return func(yield func(*Event, error) bool) {
+		seen := map[uint16]bool{}
+		for id := uint16(selFirstEntry); id != selLastEntry; {
+			if seen[id] {
+				yield(nil, fmt.Errorf("SEL loops at record %#04x", id))
+				return
+			}
+			seen[id] = true
+
+			e, next, err := i.GetSELEntry(id)
+			if errors.Is(err, CompletionError(IPMI_CC_REQ_DATA_NOT_PRESENT)) && id == selFirstEntry {
+				// The SEL is empty.
+				return
+			}
+			if !yield(e, err) || err != nil {
+				return
+			}
+			id = next
+		}
+	}
+}
+
+// ClearSEL erases all entries of the SEL and waits for the erasure to
+// complete.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 31.9.
+func (i *IPMI) ClearSEL() error {
+	reservation, err := i.ReserveSEL()
+	if err != nil {
+		return err
+	}
+
+	req := [6]byte{2: 'C', 3: 'L', 4: 'R', 5: selEraseStart}
+	binary.LittleEndian.PutUint16(req[:], reservation)
+	if _, err := i.SendRecv(_IPMI_NETFN_STORAGE, BMC_CLEAR_SEL, req[:]); err != nil {
+		return err
+	}
+
+	req[5] = selEraseStatus
+	for range selEraseTries {
+		data, err := i.SendRecv(_IPMI_NETFN_STORAGE, BMC_CLEAR_SEL, req[:])
+		if err != nil {
+			return err
+		}
+		if len(data) < 2 {
+			return errSELTruncated
+		}
+		if data[1]&0x0f == selEraseDone {
+			return nil
+		}
+		time.Sleep(selErasePoll)
+	}
+	return errors.New("timed out waiting for SEL erasure")
+}
+
+// unmarshallEvent is the inverse of marshall: it fills the part of the Event
+// struct that matches the record type of the 16-byte SEL record data.
+func unmarshallEvent(data []byte) (*Event, error) {
+	if len(data) != selRecordLen {
+		return nil, fmt.Errorf("SEL record is %d bytes, want %d", len(data), selRecordLen)
+	}
+
+	e := &Event{
+		RecordID:   binary.LittleEndian.Uint16(data),
+		RecordType: data[2],
+	}
+	var v any
+	switch {
+	case e.RecordType >= SEL_OEM_NTS_FIRST:
+		v = &e.OEMNonTsEvent
+	case e.RecordType >= SEL_OEM_TS_FIRST:
+		v = &e.OEMTsEvent
+	default:
+		// Types other than 0x02 are unspecified; decode them like
+		// system events, as other tools do.
+		v = &e.StandardEvent
+	}
+	if err := binary.Read(bytes.NewReader(data[3:]), binary.LittleEndian, v); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// SELRecord is a decoded SEL entry.
+type SELRecord struct {
+	RecordID   uint16 `json:"id"`
+	RecordType string `json:"record_type"`
+	// Timestamp is the raw timestamp. Time is only set if the timestamp
+	// is absolute, i.e. it was not logged before the SEL time was set.
+	Timestamp uint32    `json:"timestamp,omitempty"`
+	Time      time.Time `json:"time,omitzero"`
+
+	// Fields of system event records.
+	GeneratorID  uint16 `json:"generator_id,omitempty"`
+	SensorType   string `json:"sensor_type,omitempty"`
+	SensorNumber uint8  `json:"sensor_number,omitempty"`
+	EventType    uint8  `json:"event_type,omitempty"`
+	Asserted     bool   `json:"asserted,omitempty"`
+	Offset       uint8  `json:"offset,omitempty"`
+	Description  string `json:"description,omitempty"`
+	EventData    []byte `json:"event_data,omitempty"`
+
+	// Fields of OEM records.
+	ManufacturerID uint32 `json:"manufacturer_id,omitempty"`
+	OEMData        []byte `json:"oem_data,omitempty"`
+}
+
+// Decode decodes the event according to its record type. The event and
+// reading types of system events are described using the generic and
+// sensor-specific offset tables of the spec.
+func (e *Event) Decode() *SELRecord {
+	r := &SELRecord{RecordID: e.RecordID}
+	switch {
+	case e.RecordType >= SEL_OEM_NTS_FIRST:
+		r.RecordType = "OEM non-timestamped"
+		r.OEMData = append([]byte(nil), e.OEMNontsDefinedData[:]...)
+	case e.RecordType >= SEL_OEM_TS_FIRST:
+		r.RecordType = "OEM timestamped"
+		r.setTime(e.OEMTsEvent.Timestamp)
+		r.ManufacturerID = uint32(e.ManfID[0]) | uint32(e.ManfID[1])<<8 | uint32(e.ManfID[2])<<16
+		r.OEMData = append([]byte(nil), e.OEMTsDefinedData[:]...)
+	default:
+		r.RecordType = "system event"
+		if e.RecordType != SEL_SYSTEM_EVENT_RECORD {
+			r.RecordType = fmt.Sprintf("unknown 0x%02x", e.RecordType)
+		}
+		r.setTime(e.StandardEvent.Timestamp)
+		r.GeneratorID = e.GenID
+		r.SensorType = SensorType(e.SensorType).String()
+		r.SensorNumber = e.SensorNum
+		r.EventType = e.EventTypeDir & 0x7f
+		r.Asserted = e.EventTypeDir&0x80 == 0
+		r.Offset = e.EventData[0] & 0x0f
+		r.Description = eventDescription(SensorType(e.SensorType), r.EventType, r.Offset)
+		r.EventData = append([]byte(nil), e.EventData[:]...)
+	}
+	return r
+}
+
+// setTime sets the timestamp. Timestamps up to 0x20000000 count seconds since
+// the BMC was initialized rather than since the epoch; 0xFFFFFFFF is
+// unspecified.
+func (r *SELRecord) setTime(ts uint32) {
+	r.Timestamp = ts
+	if ts > selPreInitLimit && ts != 0xFFFFFFFF {
+		r.Time = time.Unix(int64(ts), 0).UTC()
+	}
+}
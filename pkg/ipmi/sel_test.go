@@ -0,0 +1,294 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeSEL answers SEL commands from a list of 16-byte records.
+type fakeSEL struct {
+	records     [][]byte
+	reservation uint16
+	clears      int
+	pending     int // status queries until erasure completes
+}
+
+func (s *fakeSEL) handle(netfn NetFn, cmd Command, data []byte) []byte {
+	if netfn != _IPMI_NETFN_STORAGE {
+		return []byte{byte(IPMI_CC_INV_CMD)}
+	}
+	switch cmd {
+	case BMC_RESERVE_SEL:
+		s.reservation++
+		return []byte{0, byte(s.reservation), byte(s.reservation >> 8)}
+
+	case BMC_GET_SEL_ENTRY:
+		if len(data) != 6 || data[4] != 0 || data[5] != 0xff {
+			return []byte{byte(IPMI_CC_INV_DATA_FIELD_IN_REQ)}
+		}
+		id := binary.LittleEndian.Uint16(data[2:])
+		idx := -1
+		for i, r := range s.records {
+			if binary.LittleEndian.Uint16(r) == id || (id == selFirstEntry && i == 0) || (id == selLastEntry && i == len(s.records)-1) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return []byte{byte(IPMI_CC_REQ_DATA_NOT_PRESENT)}
+		}
+		next := uint16(selLastEntry)
+		if idx+1 < len(s.records) {
+			next = binary.LittleEndian.Uint16(s.records[idx+1])
+		}
+		return append([]byte{0, byte(next), byte(next >> 8)}, s.records[idx]...)
+
+	case BMC_CLEAR_SEL:
+		if len(data) != 6 || binary.LittleEndian.Uint16(data) != s.reservation || string(data[2:5]) != "CLR" {
+			return []byte{byte(IPMI_CC_INV_DATA_FIELD_IN_REQ)}
+		}
+		if data[5] == selEraseStart {
+			s.clears++
+			s.records = nil
+			return []byte{0, 0}
+		}
+		if s.pending > 0 {
+			s.pending--
+			return []byte{0, 0}
+		}
+		return []byte{0, selEraseDone}
+	}
+	return []byte{byte(IPMI_CC_INV_CMD)}
+}
+
+func selRecord(t *testing.T, e *Event) []byte {
+	t.Helper()
+	b, err := e.marshall()
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.LittleEndian.PutUint16(b, e.RecordID)
+	return b
+}
+
+var (
+	thresholdEvent = &Event{
+		RecordID:   0x0001,
+		RecordType: SEL_SYSTEM_EVENT_RECORD,
+		StandardEvent: StandardEvent{
+			Timestamp:    1790000000,
+			GenID:        0x0020,
+			EvMRev:       0x04,
+			SensorType:   0x01,
+			SensorNum:    0x30,
+			EventTypeDir: EVENT_READING_THRESHOLD,
+			EventData:    [3]uint8{0x59, 96, 95},
+		},
+	}
+	memoryEvent = &Event{
+		RecordID:   0x0002,
+		RecordType: SEL_SYSTEM_EVENT_RECORD,
+		StandardEvent: StandardEvent{
+			Timestamp:    0x100,
+			GenID:        0x0001,
+			EvMRev:       0x04,
+			SensorType:   0x0C,
+			SensorNum:    0x60,
+			EventTypeDir: 0x80 | EVENT_READING_SENSOR_SPECIFIC,
+			EventData:    [3]uint8{0xa1, 0xff, 0x03},
+		},
+	}
+	oemTsEvent = &Event{
+		RecordID:   0x0003,
+		RecordType: 0xC1,
+		OEMTsEvent: OEMTsEvent{
+			Timestamp:        1790000001,
+			ManfID:           [3]uint8{0x57, 0x01, 0x00},
+			OEMTsDefinedData: [6]uint8{1, 2, 3, 4, 5, 6},
+		},
+	}
+	oemNonTsEvent = &Event{
+		RecordID:   0x0004,
+		RecordType: OEM_NTS_TYPE,
+		OEMNonTsEvent: OEMNonTsEvent{
+			OEMNontsDefinedData: [13]uint8{0xde, 0xad, 0xbe, 0xef},
+		},
+	}
+)
+
+func TestSELEntries(t *testing.T) {
+	events := []*Event{thresholdEvent, memoryEvent, oemTsEvent, oemNonTsEvent}
+	s := &fakeSEL{}
+	for _, e := range events {
+		s.records = append(s.records, selRecord(t, e))
+	}
+	i := newFakeIPMI(t, s.handle)
+
+	var got []*Event
+	for e, err := range i.SELEntries() {
+		if err != nil {
+			t.Fatalf("SELEntries() = %v", err)
+		}
+		got = append(got, e)
+	}
+	if !reflect.DeepEqual(got, events) {
+		t.Errorf("SELEntries() = %+v, want %+v", got, events)
+	}
+
+	// Stopping early must not read further entries.
+	for e := range i.SELEntries() {
+		if e.RecordID != thresholdEvent.RecordID {
+			t.Errorf("first entry = %#04x, want %#04x", e.RecordID, thresholdEvent.RecordID)
+		}
+		break
+	}
+
+	if err := i.ClearSEL(); err != nil {
+		t.Fatalf("ClearSEL() = %v", err)
+	}
+	for e, err := range i.SELEntries() {
+		t.Errorf("SELEntries() after ClearSEL = %+v, %v, want no entries", e, err)
+	}
+}
+
+func TestSELEntriesErrors(t *testing.T) {
+	// The second entry points back at the first one.
+	first := selRecord(t, thresholdEvent)
+	loop := &fakeSEL{records: [][]byte{first, first}}
+	i := newFakeIPMI(t, loop.handle)
+	var err error
+	for _, err = range i.SELEntries() {
+		if err != nil {
+			break
+		}
+	}
+	if err == nil {
+		t.Errorf("SELEntries() on a looping SEL = nil, want error")
+	}
+
+	i = newFakeIPMI(t, func(NetFn, Command, []byte) []byte {
+		return []byte{0, 0xff, 0xff, 1, 2, 3}
+	})
+	for _, err = range i.SELEntries() {
+	}
+	if !errors.Is(err, errSELTruncated) {
+		t.Errorf("SELEntries() = %v, want %v", err, errSELTruncated)
+	}
+}
+
+func TestClearSEL(t *testing.T) {
+	selErasePoll = 0
+	s := &fakeSEL{records: [][]byte{selRecord(t, thresholdEvent)}, pending: 3}
+	i := newFakeIPMI(t, s.handle)
+	if err := i.ClearSEL(); err != nil {
+		t.Fatalf("ClearSEL() = %v", err)
+	}
+	if s.clears != 1 || s.pending != 0 {
+		t.Errorf("ClearSEL() initiated %d erasures and left %d status queries, want 1 and 0", s.clears, s.pending)
+	}
+
+	s.pending = selEraseTries + 1
+	if err := i.ClearSEL(); err == nil {
+		t.Errorf("ClearSEL() = nil, want a timeout error")
+	}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	for _, tt := range []struct {
+		e    *Event
+		want *SELRecord
+	}{
+		{
+			e: thresholdEvent,
+			want: &SELRecord{
+				RecordID:     1,
+				RecordType:   "system event",
+				Timestamp:    1790000000,
+				Time:         time.Unix(1790000000, 0).UTC(),
+				GeneratorID:  0x20,
+				SensorType:   "Temperature",
+				SensorNumber: 0x30,
+				EventType:    EVENT_READING_THRESHOLD,
+				Asserted:     true,
+				Offset:       9,
+				Description:  "Upper Critical going high",
+				EventData:    []byte{0x59, 96, 95},
+			},
+		},
+		{
+			e: memoryEvent,
+			want: &SELRecord{
+				RecordID:     2,
+				RecordType:   "system event",
+				Timestamp:    0x100,
+				GeneratorID:  1,
+				SensorType:   "Memory",
+				SensorNumber: 0x60,
+				EventType:    EVENT_READING_SENSOR_SPECIFIC,
+				Offset:       1,
+				Description:  "Uncorrectable ECC",
+				EventData:    []byte{0xa1, 0xff, 0x03},
+			},
+		},
+		{
+			e: oemTsEvent,
+			want: &SELRecord{
+				RecordID:       3,
+				RecordType:     "OEM timestamped",
+				Timestamp:      1790000001,
+				Time:           time.Unix(1790000001, 0).UTC(),
+				ManufacturerID: 0x157,
+				OEMData:        []byte{1, 2, 3, 4, 5, 6},
+			},
+		},
+		{
+			e: oemNonTsEvent,
+			want: &SELRecord{
+				RecordID:   4,
+				RecordType: "OEM non-timestamped",
+				OEMData:    []byte{0xde, 0xad, 0xbe, 0xef, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			},
+		},
+	} {
+		got := tt.e.Decode()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Decode(%#04x) = %+v, want %+v", tt.e.RecordID, got, tt.want)
+		}
+		b, err := json.Marshal(got)
+		if err != nil {
+			t.Fatalf("json.Marshal(%+v) = %v", got, err)
+		}
+		var back SELRecord
+		if err := json.Unmarshal(b, &back); err != nil || !reflect.DeepEqual(&back, got) {
+			t.Errorf("JSON round trip of %s = %+v, %v, want %+v", b, &back, err, got)
+		}
+	}
+}
+
+func TestEventDescription(t *testing.T) {
+	for _, tt := range []struct {
+		st     SensorType
+		et     uint8
+		offset uint8
+		want   string
+	}{
+		{0x04, EVENT_READING_THRESHOLD, 0, "Lower Non-critical going low"},
+		{0x08, 0x0B, 1, "Redundancy Lost"},
+		{0x07, EVENT_READING_SENSOR_SPECIFIC, 1, "Thermal Trip"},
+		{0x23, EVENT_READING_SENSOR_SPECIFIC, 5, "event type 0x6f offset 0x05"},
+		{0x01, 0x72, 3, "OEM event type 0x72 offset 0x03"},
+		{0x01, 0x03, 7, "event type 0x03 offset 0x07"},
+	} {
+		if got := eventDescription(tt.st, tt.et, tt.offset); got != tt.want {
+			t.Errorf("eventDescription(%#x, %#x, %d) = %q, want %q", byte(tt.st), tt.et, tt.offset, got, tt.want)
+		}
+	}
+}
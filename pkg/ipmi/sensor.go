@@ -0,0 +1,404 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// SensorType is the type of a sensor.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, table 42-3.
+type SensorType byte
+
+var sensorTypeNames = []string{
+	"Reserved", "Temperature", "Voltage", "Current", "Fan",
+	"Physical Security", "Platform Security", "Processor", "Power Supply",
+	"Power Unit", "Cooling Device", "Other Units-based Sensor", "Memory",
+	"Drive Slot (Bay)", "POST Memory Resize", "System Firmware Progress",
+	"Event Logging Disabled", "Watchdog 1", "System Event", "Critical Interrupt",
+	"Button / Switch", "Module / Board", "Microcontroller / Coprocessor",
+	"Add-in Card", "Chassis", "Chip Set", "Other FRU", "Cable / Interconnect",
+	"Terminator", "System Boot / Restart Initiated", "Boot Error",
+	"Base OS Boot / Installation Status", "OS Stop / Shutdown",
+	"Slot / Connector", "System ACPI Power State", "Watchdog 2",
+	"Platform Alert", "Entity Presence", "Monitor ASIC / IC", "LAN",
+	"Management Subsystem Health", "Battery", "Session Audit",
+	"Version Change", "FRU State",
+}
+
+func (t SensorType) String() string {
+	if int(t) < len(sensorTypeNames) {
+		return sensorTypeNames[t]
+	}
+	if t >= 0xC0 {
+		return "OEM reserved"
+	}
+	return fmt.Sprintf("unknown sensor type 0x%02x", byte(t))
+}
+
+// SensorUnit is a sensor unit type code.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 43.17.
+type SensorUnit byte
+
+var sensorUnitNames = []string{
+	"unspecified", "degrees C", "degrees F", "degrees K", "Volts", "Amps",
+	"Watts", "Joules", "Coulombs", "VA", "Nits", "lumen", "lux", "Candela",
+	"kPa", "PSI", "Newton", "CFM", "RPM", "Hz", "microsecond", "millisecond",
+	"second", "minute", "hour", "day", "week", "mil", "inches", "feet",
+	"cu in", "cu feet", "mm", "cm", "m", "cu cm", "cu m", "liters",
+	"fluid ounce", "radians", "steradians", "revolutions", "cycles",
+	"gravities", "ounce", "pound", "ft-lb", "oz-in", "gauss", "gilberts",
+	"henry", "millihenry", "farad", "microfarad", "ohms", "siemens", "mole",
+	"becquerel", "PPM", "reserved", "Decibels", "DbA", "DbC", "gray",
+	"sievert", "color temp deg K", "bit", "kilobit", "megabit", "gigabit",
+	"byte", "kilobyte", "megabyte", "gigabyte", "word", "dword", "qword",
+	"line", "hit", "miss", "retry", "reset", "overrun / overflow", "underrun",
+	"collision", "packets", "messages", "characters", "error",
+	"correctable error", "uncorrectable error", "fatal error", "grams",
+}
+
+func (u SensorUnit) String() string {
+	if int(u) < len(sensorUnitNames) {
+		return sensorUnitNames[u]
+	}
+	return fmt.Sprintf("unknown unit 0x%02x", byte(u))
+}
+
+// AnalogFormat is the numeric format of a sensor's raw readings.
+type AnalogFormat byte
+
+// Analog data formats, from bits 7:6 of Sensor Units 1.
+const (
+	ANALOG_UNSIGNED       AnalogFormat = 0
+	ANALOG_ONES_COMPL     AnalogFormat = 1
+	ANALOG_TWOS_COMPL     AnalogFormat = 2
+	ANALOG_NOT_APPLICABLE AnalogFormat = 3
+)
+
+// Linearization is the function applied to a converted sensor reading.
+type Linearization byte
+
+// Linearization functions, see section 36.3. Values from 0x70 on denote
+// non-linear sensors.
+const (
+	LINEAR     Linearization = 0x00
+	LINEAR_LN  Linearization = 0x01
+	LINEAR_LOG Linearization = 0x02
+	LINEAR_LG2 Linearization = 0x03
+	LINEAR_E   Linearization = 0x04
+	LINEAR_EXP Linearization = 0x05
+	LINEAR_EX2 Linearization = 0x06
+	LINEAR_1X  Linearization = 0x07
+	LINEAR_SQR Linearization = 0x08
+	LINEAR_CUB Linearization = 0x09
+	LINEAR_SQT Linearization = 0x0A
+	LINEAR_CBR Linearization = 0x0B
+	NON_LINEAR Linearization = 0x70
+)
+
+func (l Linearization) apply(x float64) (float64, error) {
+	switch l {
+	case LINEAR:
+		return x, nil
+	case LINEAR_LN:
+		return math.Log(x), nil
+	case LINEAR_LOG:
+		return math.Log10(x), nil
+	case LINEAR_LG2:
+		return math.Log2(x), nil
+	case LINEAR_E:
+		return math.Exp(x), nil
+	case LINEAR_EXP:
+		return math.Pow(10, x), nil
+	case LINEAR_EX2:
+		return math.Exp2(x), nil
+	case LINEAR_1X:
+		return 1 / x, nil
+	case LINEAR_SQR:
+		return x * x, nil
+	case LINEAR_CUB:
+		return x * x * x, nil
+	case LINEAR_SQT:
+		return math.Sqrt(x), nil
+	case LINEAR_CBR:
+		return math.Cbrt(x), nil
+	}
+	if l >= NON_LINEAR {
+		return 0, fmt.Errorf("non-linear sensor 0x%02x needs reading factors", byte(l))
+	}
+	return 0, fmt.Errorf("unknown linearization 0x%02x", byte(l))
+}
+
+// Threshold identifies one of a sensor's thresholds. Its value is the bit
+// number used for it in threshold masks and in the threshold status.
+type Threshold uint
+
+// Sensor thresholds.
+const (
+	LOWER_NON_CRITICAL Threshold = iota
+	LOWER_CRITICAL
+	LOWER_NON_RECOVERABLE
+	UPPER_NON_CRITICAL
+	UPPER_CRITICAL
+	UPPER_NON_RECOVERABLE
+	numThresholds
+)
+
+// ThresholdStatus holds a bit per Threshold that has been crossed.
+type ThresholdStatus byte
+
+// String returns the most severe crossed threshold in the abbreviated form
+// ipmitool uses, or "ok".
+func (s ThresholdStatus) String() string {
+	switch {
+	case s&(1<<LOWER_NON_RECOVERABLE|1<<UPPER_NON_RECOVERABLE) != 0:
+		return "nr"
+	case s&(1<<LOWER_CRITICAL|1<<UPPER_CRITICAL) != 0:
+		return "cr"
+	case s&(1<<LOWER_NON_CRITICAL|1<<UPPER_NON_CRITICAL) != 0:
+		return "nc"
+	}
+	return "ok"
+}
+
+// Crossed reports whether threshold t has been crossed.
+func (s ThresholdStatus) Crossed(t Threshold) bool {
+	return s&(1<<t) != 0
+}
+
+// EVENT_READING_THRESHOLD is the event/reading type code of threshold-based
+// sensors; other codes denote discrete sensors.
+const EVENT_READING_THRESHOLD = 0x01
+
+// SensorRecord holds the fields of a Full or Compact Sensor Record. The
+// conversion factors and thresholds are only present in Full Sensor Records.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, sections 43.1 and 43.2.
+type SensorRecord struct {
+	RecordID         uint16
+	RecordType       SDRRecordType
+	OwnerID          byte
+	OwnerLUN         byte
+	Number           byte
+	EntityID         byte
+	EntityInstance   byte
+	Initialization   byte
+	Capabilities     byte
+	Type             SensorType
+	EventReadingType byte
+	AssertionMask    uint16
+	DeassertionMask  uint16
+	// ReadingMask is the discrete reading mask of discrete sensors and
+	// the settable/readable threshold mask of threshold-based ones.
+	ReadingMask  uint16
+	Units1       byte
+	BaseUnit     SensorUnit
+	ModifierUnit SensorUnit
+	Name         string
+
+	Linearization Linearization
+	M             int16
+	B             int16
+	// RExp and BExp are the result and B exponents, K2 and K1 in the spec.
+	RExp int8
+	BExp int8
+	// Thresholds holds the raw threshold values, indexed by Threshold.
+	Thresholds [numThresholds]byte
+}
+
+// Sensor record body offsets, counted from the end of the header.
+const (
+	sdrUnits1Off        = 15
+	sdrFullLinearOff    = 18
+	sdrFullThresholdOff = 31
+	sdrFullIDOff        = 42
+	sdrCompactIDOff     = 26
+)
+
+// ParseSensorRecord parses a Full or Compact Sensor Record.
+func ParseSensorRecord(sdr *SDR) (*SensorRecord, error) {
+	b := sdr.Body
+	idOff := sdrCompactIDOff
+	switch sdr.Type {
+	case SDR_FULL_SENSOR:
+		idOff = sdrFullIDOff
+	case SDR_COMPACT_SENSOR:
+	default:
+		return nil, fmt.Errorf("record %#04x: type 0x%02x is not a sensor record", sdr.RecordID, byte(sdr.Type))
+	}
+	if len(b) < idOff+1 {
+		return nil, fmt.Errorf("record %#04x: %w: got %d bytes, want at least %d", sdr.RecordID, errSDRTruncated, len(b), idOff+1)
+	}
+
+	s := &SensorRecord{
+		RecordID:         sdr.RecordID,
+		RecordType:       sdr.Type,
+		OwnerID:          b[0],
+		OwnerLUN:         b[1] & 0x3,
+		Number:           b[2],
+		EntityID:         b[3],
+		EntityInstance:   b[4],
+		Initialization:   b[5],
+		Capabilities:     b[6],
+		Type:             SensorType(b[7]),
+		EventReadingType: b[8],
+		AssertionMask:    binary.LittleEndian.Uint16(b[9:]),
+		DeassertionMask:  binary.LittleEndian.Uint16(b[11:]),
+		ReadingMask:      binary.LittleEndian.Uint16(b[13:]),
+		Units1:           b[sdrUnits1Off],
+		BaseUnit:         SensorUnit(b[sdrUnits1Off+1]),
+		ModifierUnit:     SensorUnit(b[sdrUnits1Off+2]),
+		Name:             parseIDString(b[idOff], b[idOff+1:]),
+	}
+
+	if sdr.Type == SDR_FULL_SENSOR {
+		l := b[sdrFullLinearOff:]
+		s.Linearization = Linearization(l[0] & 0x7f)
+		s.M = signExtend(uint16(l[1])|uint16(l[2]&0xc0)<<2, 10)
+		s.B = signExtend(uint16(l[3])|uint16(l[4]&0xc0)<<2, 10)
+		s.RExp = int8(signExtend(uint16(l[6]>>4), 4))
+		s.BExp = int8(signExtend(uint16(l[6]&0xf), 4))
+		// Thresholds are stored from upper non-recoverable down to lower non-critical.
+		for t := range numThresholds {
+			s.Thresholds[t] = b[sdrFullThresholdOff+int(UPPER_NON_RECOVERABLE-t)]
+		}
+	}
+	return s, nil
+}
+
+// signExtend sign-extends the bits-wide two's complement value v.
+func signExtend(v uint16, bits uint) int16 {
+	shift := 16 - bits
+	return int16(v<<shift) >> shift
+}
+
+// parseIDString decodes an ID string given its type/length byte.
+func parseIDString(typeLen byte, b []byte) string {
+	n := min(int(typeLen&0x1f), len(b))
+	b = b[:n]
+	if typeLen>>6 == 3 { // 8-bit ASCII + Latin 1
+		return strings.TrimRight(string(b), "\x00 ")
+	}
+	return fmt.Sprintf("%x", b)
+}
+
+// AnalogFormat returns the numeric format of the sensor's raw readings.
+func (s *SensorRecord) AnalogFormat() AnalogFormat {
+	return AnalogFormat(s.Units1 >> 6)
+}
+
+// IsThreshold reports whether the sensor is threshold-based, as opposed to
+// discrete.
+func (s *SensorRecord) IsThreshold() bool {
+	return s.EventReadingType == EVENT_READING_THRESHOLD
+}
+
+// Unit returns the sensor's unit, e.g. "degrees C" or "Watts/hour".
+func (s *SensorRecord) Unit() string {
+	u := s.BaseUnit.String()
+	switch (s.Units1 >> 1) & 0x3 {
+	case 1:
+		u += "/" + s.ModifierUnit.String()
+	case 2:
+		u += "*" + s.ModifierUnit.String()
+	}
+	rates := []string{"", "/us", "/ms", "/s", "/min", "/hour", "/day"}
+	if r := int(s.Units1>>3) & 0x7; r < len(rates) {
+		u += rates[r]
+	}
+	if s.Units1&1 != 0 {
+		u = "% " + u
+	}
+	return u
+}
+
+// Convert converts a raw reading of the sensor into its unit, following
+// y = L[(M*x + B*10^K1) * 10^K2]. Only threshold-based sensors described by
+// a Full Sensor Record can be converted.
+func (s *SensorRecord) Convert(raw byte) (float64, error) {
+	if s.RecordType != SDR_FULL_SENSOR {
+		return 0, fmt.Errorf("sensor %q: only full sensor records have conversion factors", s.Name)
+	}
+	var x float64
+	switch s.AnalogFormat() {
+	case ANALOG_UNSIGNED:
+		x = float64(raw)
+	case ANALOG_ONES_COMPL:
+		if raw&0x80 != 0 {
+			x = -float64(^raw)
+		} else {
+			x = float64(raw)
+		}
+	case ANALOG_TWOS_COMPL:
+		x = float64(int8(raw))
+	default:
+		return 0, fmt.Errorf("sensor %q: readings are not numeric", s.Name)
+	}
+	y := (float64(s.M)*x + float64(s.B)*math.Pow10(int(s.BExp))) * math.Pow10(int(s.RExp))
+	return s.Linearization.apply(y)
+}
+
+// Threshold returns the converted value of threshold t, and false if the
+// sensor has no readable threshold t.
+func (s *SensorRecord) Threshold(t Threshold) (float64, bool) {
+	if s.RecordType != SDR_FULL_SENSOR || !s.IsThreshold() || s.ReadingMask&(1<<t) == 0 {
+		return 0, false
+	}
+	v, err := s.Convert(s.Thresholds[t])
+	return v, err == nil
+}
+
+// SensorReading is the response to Get Sensor Reading.
+// See Intelligent Platform Management Interface Specification v2.0 rev. 1.1, section 35.14.
+type SensorReading struct {
+	// Raw is the reading of threshold-based sensors, before conversion.
+	Raw   byte
+	Flags byte
+	// Status holds the crossed thresholds of threshold-based sensors.
+	Status ThresholdStatus
+	// States holds the asserted states of discrete sensors.
+	States uint16
+}
+
+// Sensor reading flags.
+const (
+	SENSOR_EVENTS_ENABLED   = 0x80
+	SENSOR_SCANNING_ENABLED = 0x40
+	SENSOR_UNAVAILABLE      = 0x20
+)
+
+// Available reports whether the reading is valid.
+func (r *SensorReading) Available() bool {
+	return r.Flags&SENSOR_SCANNING_ENABLED != 0 && r.Flags&SENSOR_UNAVAILABLE == 0
+}
+
+var errSensorReadingTruncated = errors.New("sensor reading truncated")
+
+// GetSensorReading reads the sensor with the given number. Only sensors owned
+// by the BMC on LUN 0 can be read.
+func (i *IPMI) GetSensorReading(number byte) (*SensorReading, error) {
+	data, err := i.SendRecv(_IPMI_NETFN_SENSOR, BMC_GET_SENSOR_READING, []byte{number})
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 3 {
+		return nil, errSensorReadingTruncated
+	}
+	r := &SensorReading{
+		Raw:   data[1],
+		Flags: data[2],
+	}
+	if len(data) > 3 {
+		r.Status = ThresholdStatus(data[3] & 0x3f)
+		r.States = uint16(data[3])
+	}
+	if len(data) > 4 {
+		r.States |= uint16(data[4]&0x7f) << 8
+	}
+	return r, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ipmi
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+type fullSensor struct {
+	number     byte
+	typ        SensorType
+	units1     byte
+	unit       SensorUnit
+	m, b       uint16 // 10-bit two's complement
+	exps       byte   // R exponent in bits 7:4, B exponent in bits 3:0
+	mask       uint16 // readable thresholds
+	thresholds [numThresholds]byte
+	name       string
+}
+
+func (s fullSensor) body() []byte {
+	b := make([]byte, sdrFullIDOff+1)
+	b[0] = 0x20
+	b[2] = s.number
+	b[3] = 0x07 // processor
+	b[4] = 1
+	b[7] = byte(s.typ)
+	b[8] = EVENT_READING_THRESHOLD
+	b[13] = byte(s.mask)
+	b[14] = byte(s.mask >> 8)
+	b[sdrUnits1Off] = s.units1
+	b[sdrUnits1Off+1] = byte(s.unit)
+	b[sdrFullLinearOff+1] = byte(s.m)
+	b[sdrFullLinearOff+2] = byte(s.m>>2) & 0xc0
+	b[sdrFullLinearOff+3] = byte(s.b)
+	b[sdrFullLinearOff+4] = byte(s.b>>2) & 0xc0
+	b[sdrFullLinearOff+6] = s.exps
+	for t := range numThresholds {
+		b[sdrFullThresholdOff+int(UPPER_NON_RECOVERABLE-t)] = s.thresholds[t]
+	}
+	b[sdrFullIDOff] = 0xc0 | byte(len(s.name))
+	return append(b, s.name...)
+}
+
+func TestParseSensorRecord(t *testing.T) {
+	cpu := fullSensor{
+		number:     0x30,
+		typ:        0x01,
+		units1:     byte(ANALOG_TWOS_COMPL) << 6,
+		unit:       1,
+		m:          1,
+		mask:       1<<UPPER_CRITICAL | 1<<UPPER_NON_CRITICAL,
+		thresholds: [numThresholds]byte{UPPER_CRITICAL: 95, UPPER_NON_CRITICAL: 85, LOWER_CRITICAL: 5},
+		name:       "CPU Temp",
+	}
+	rec, err := ParseSensorRecord(&SDR{SDRHeader: SDRHeader{RecordID: 7, Type: SDR_FULL_SENSOR}, Body: cpu.body()})
+	if err != nil {
+		t.Fatalf("ParseSensorRecord() = %v", err)
+	}
+	if rec.Name != "CPU Temp" || rec.Number != 0x30 || rec.Type.String() != "Temperature" || rec.Unit() != "degrees C" || !rec.IsThreshold() {
+		t.Errorf("ParseSensorRecord() = %+v", rec)
+	}
+	for _, tt := range []struct {
+		raw  byte
+		want float64
+	}{
+		{raw: 45, want: 45},
+		{raw: 0xf6, want: -10},
+	} {
+		if got, err := rec.Convert(tt.raw); err != nil || got != tt.want {
+			t.Errorf("Convert(%#x) = %v, %v, want %v", tt.raw, got, err, tt.want)
+		}
+	}
+	if v, ok := rec.Threshold(UPPER_CRITICAL); !ok || v != 95 {
+		t.Errorf("Threshold(UPPER_CRITICAL) = %v, %t, want 95, true", v, ok)
+	}
+	if _, ok := rec.Threshold(LOWER_CRITICAL); ok {
+		t.Errorf("Threshold(LOWER_CRITICAL) is readable, want unreadable")
+	}
+
+	vcore := fullSensor{
+		number: 0x31,
+		typ:    0x02,
+		unit:   4,
+		m:      196,
+		exps:   0xc0, // R = -4
+		name:   "VCORE",
+	}
+	rec, err = ParseSensorRecord(&SDR{SDRHeader: SDRHeader{Type: SDR_FULL_SENSOR}, Body: vcore.body()})
+	if err != nil {
+		t.Fatalf("ParseSensorRecord() = %v", err)
+	}
+	if got, err := rec.Convert(62); err != nil || math.Abs(got-1.2152) > 1e-9 {
+		t.Errorf("Convert(62) = %v, %v, want 1.2152", got, err)
+	}
+
+	// M = -3, B = 500 * 10^1, result * 10^-2.
+	neg := fullSensor{
+		m:    0x3fd,
+		b:    500,
+		exps: 0xe1,
+		unit: 18,
+		name: "FAN1",
+	}
+	rec, err = ParseSensorRecord(&SDR{SDRHeader: SDRHeader{Type: SDR_FULL_SENSOR}, Body: neg.body()})
+	if err != nil {
+		t.Fatalf("ParseSensorRecord() = %v", err)
+	}
+	if rec.M != -3 || rec.B != 500 || rec.RExp != -2 || rec.BExp != 1 {
+		t.Errorf("ParseSensorRecord() = M %d, B %d, K2 %d, K1 %d, want -3, 500, -2, 1", rec.M, rec.B, rec.RExp, rec.BExp)
+	}
+	if got, err := rec.Convert(100); err != nil || math.Abs(got-47) > 1e-9 {
+		t.Errorf("Convert(100) = %v, %v, want 47", got, err)
+	}
+
+	compact := make([]byte, sdrCompactIDOff+1)
+	compact[2] = 0x40
+	compact[7] = 0x08
+	compact[8] = 0x6f
+	compact[sdrCompactIDOff] = 0xc0 | 3
+	compact = append(compact, "PS1"...)
+	rec, err = ParseSensorRecord(&SDR{SDRHeader: SDRHeader{Type: SDR_COMPACT_SENSOR}, Body: compact})
+	if err != nil {
+		t.Fatalf("ParseSensorRecord() = %v", err)
+	}
+	if rec.Name != "PS1" || rec.Type.String() != "Power Supply" || rec.IsThreshold() {
+		t.Errorf("ParseSensorRecord() = %+v", rec)
+	}
+	if _, err := rec.Convert(1); err == nil {
+		t.Errorf("Convert() on a compact record = nil, want error")
+	}
+
+	if _, err := ParseSensorRecord(&SDR{SDRHeader: SDRHeader{Type: SDR_FRU_LOCATOR}}); err == nil {
+		t.Errorf("ParseSensorRecord(FRU locator) = nil, want error")
+	}
+	if _, err := ParseSensorRecord(&SDR{SDRHeader: SDRHeader{Type: SDR_FULL_SENSOR}, Body: compact}); !errors.Is(err, errSDRTruncated) {
+		t.Errorf("ParseSensorRecord(short) = %v, want %v", err, errSDRTruncated)
+	}
+}
+
+func TestSensorUnit(t *testing.T) {
+	for _, tt := range []struct {
+		units1 byte
+		want   string
+	}{
+		{units1: 0, want: "Watts"},
+		{units1: 1 << 1, want: "Watts/hour"},
+		{units1: 2 << 1, want: "Watts*hour"},
+		{units1: 5 << 3, want: "Watts/hour"},
+		{units1: 1, want: "% Watts"},
+	} {
+		s := &SensorRecord{Units1: tt.units1, BaseUnit: 6, ModifierUnit: 24}
+		if got := s.Unit(); got != tt.want {
+			t.Errorf("Unit() with units1 %#x = %q, want %q", tt.units1, got, tt.want)
+		}
+	}
+}
+
+func TestThresholdStatus(t *testing.T) {
+	for _, tt := range []struct {
+		s    ThresholdStatus
+		want string
+	}{
+		{0, "ok"},
+		{1 << UPPER_NON_CRITICAL, "nc"},
+		{1<<UPPER_NON_CRITICAL | 1<<UPPER_CRITICAL, "cr"},
+		{1 << LOWER_NON_RECOVERABLE, "nr"},
+	} {
+		if got := tt.s.String(); got != tt.want {
+			t.Errorf("ThresholdStatus(%#x).String() = %q, want %q", byte(tt.s), got, tt.want)
+		}
+	}
+}
+
+func TestGetSensorReading(t *testing.T) {
+	i := newFakeIPMI(t, func(netfn NetFn, cmd Command, data []byte) []byte {
+		if netfn != _IPMI_NETFN_SENSOR || cmd != BMC_GET_SENSOR_READING || len(data) != 1 {
+			return []byte{byte(IPMI_CC_INV_CMD)}
+		}
+		switch data[0] {
+		case 0x30:
+			return []byte{0, 90, 0xc0, 1 << UPPER_NON_CRITICAL}
+		case 0x40:
+			return []byte{0, 0, 0xc0, 0x01, 0x82}
+		case 0x41:
+			return []byte{0, 0, 0xe0}
+		}
+		return []byte{byte(IPMI_CC_REQ_DATA_NOT_PRESENT)}
+	})
+
+	r, err := i.GetSensorReading(0x30)
+	if err != nil {
+		t.Fatalf("GetSensorReading(0x30) = %v", err)
+	}
+	if r.Raw != 90 || !r.Available() || r.Status.String() != "nc" || !r.Status.Crossed(UPPER_NON_CRITICAL) {
+		t.Errorf("GetSensorReading(0x30) = %+v", r)
+	}
+
+	r, err = i.GetSensorReading(0x40)
+	if err != nil {
+		t.Fatalf("GetSensorReading(0x40) = %v", err)
+	}
+	if r.States != 0x0201 {
+		t.Errorf("GetSensorReading(0x40).States = %#x, want 0x0201", r.States)
+	}
+
+	r, err = i.GetSensorReading(0x41)
+	if err != nil {
+		t.Fatalf("GetSensorReading(0x41) = %v", err)
+	}
+	if r.Available() {
+		t.Errorf("GetSensorReading(0x41) is available, want unavailable")
+	}
+
+	if _, err := i.GetSensorReading(0x50); !errors.Is(err, CompletionError(IPMI_CC_REQ_DATA_NOT_PRESENT)) {
+		t.Errorf("GetSensorReading(0x50) = %v, want %v", err, CompletionError(IPMI_CC_REQ_DATA_NOT_PRESENT))
+	}
+}
@@ -56,9 +56,10 @@ var (
 
 // BlockDev maps a device name to a BlockStat structure for a given block device
 type BlockDev struct {
-	Name   string
-	FSType string
-	FsUUID string
+	Name    string
+	FSType  string
+	FsUUID  string
+	FSLabel string
 }
 
 // Device makes sure the block device exists and returns a handle to it.
@@ -72,8 +73,8 @@ func Device(maybeDevpath string) (*BlockDev, error) {
 	}
 
 	devpath := filepath.Join("/dev/", devname)
-	if uuid, err := getFSUUID(devpath); err == nil {
-		return &BlockDev{Name: devname, FsUUID: uuid}, nil
+	if fs, err := ProbeFile(devpath); err == nil {
+		return &BlockDev{Name: devname, FSType: fs.Type, FsUUID: fs.UUID, FSLabel: fs.Label}, nil
 	}
 	return &BlockDev{Name: devname}, nil
 }
@@ -99,8 +100,14 @@ func (b *BlockDev) DevName() string {
 // Mount implements mount.Mounter.
 func (b *BlockDev) Mount(path string, flags uintptr, opts ...func() error) (*mount.MountPoint, error) {
 	devpath := filepath.Join("/dev", b.Name)
-	if len(b.FSType) > 0 {
-		return mount.Mount(devpath, path, b.FSType, "", flags, opts...)
+
+	// The probed FSType is only a hint. The kernel may serve it under
+	// another name (ext2 and ext3 through ext4), and types such as LUKS or
+	// swap cannot be mounted at all, so fall back to TryMount.
+	if len(b.FSType) > 0 && mount.FindFileSystem(b.FSType) == nil {
+		if mp, err := mount.Mount(devpath, path, b.FSType, "", flags, opts...); err == nil {
+			return mp, nil
+		}
 	}
 
 	return mount.TryMount(devpath, path, "", flags, opts...)
@@ -234,166 +241,6 @@ func (b *BlockDev) PCIInfo() (*pci.PCI, error) {
 	return pci.OnePCI(p)
 }
 
-func getFSUUID(devpath string) (string, error) {
-	file, err := os.Open(devpath)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	fsuuid, err := tryFAT32(file)
-	if err == nil {
-		return fsuuid, nil
-	}
-	fsuuid, err = tryFAT16(file)
-	if err == nil {
-		return fsuuid, nil
-	}
-	fsuuid, err = tryEXT4(file)
-	if err == nil {
-		return fsuuid, nil
-	}
-	fsuuid, err = tryXFS(file)
-	if err == nil {
-		return fsuuid, nil
-	}
-	return "", fmt.Errorf("unknown UUID (not vfat, ext4, nor xfs)")
-}
-
-// See https://www.nongnu.org/ext2-doc/ext2.html#DISK-ORGANISATION.
-const (
-	// Offset of superblock in partition.
-	ext2SprblkOff = 1024
-
-	// Offset of magic number in suberblock.
-	ext2SprblkMagicOff  = 56
-	ext2SprblkMagicSize = 2
-
-	ext2SprblkMagic = 0xEF53
-
-	// Offset of UUID in superblock.
-	ext2SprblkUUIDOff  = 104
-	ext2SprblkUUIDSize = 16
-)
-
-func tryEXT4(file io.ReaderAt) (string, error) {
-	var off int64
-
-	// Read magic number.
-	b := make([]byte, ext2SprblkMagicSize)
-	off = ext2SprblkOff + ext2SprblkMagicOff
-	if _, err := file.ReadAt(b, off); err != nil {
-		return "", err
-	}
-	magic := binary.LittleEndian.Uint16(b[:2])
-	if magic != ext2SprblkMagic {
-		return "", fmt.Errorf("ext4 magic not found")
-	}
-
-	// Filesystem UUID.
-	b = make([]byte, ext2SprblkUUIDSize)
-	off = ext2SprblkOff + ext2SprblkUUIDOff
-	if _, err := file.ReadAt(b, off); err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
-}
-
-// See https://de.wikipedia.org/wiki/File_Allocation_Table#Aufbau.
-const (
-	fat12Magic = "FAT12   "
-	fat16Magic = "FAT16   "
-
-	// Offset of magic number.
-	fat16MagicOff  = 0x36
-	fat16MagicSize = 8
-
-	// Offset of filesystem ID / serial number. Treated as short filesystem UUID.
-	fat16IDOff  = 0x27
-	fat16IDSize = 4
-)
-
-func tryFAT16(file io.ReaderAt) (string, error) {
-	// Read magic number.
-	b := make([]byte, fat16MagicSize)
-	if _, err := file.ReadAt(b, fat16MagicOff); err != nil {
-		return "", err
-	}
-	magic := string(b)
-	if magic != fat16Magic && magic != fat12Magic {
-		return "", fmt.Errorf("fat16 magic not found")
-	}
-
-	// Filesystem UUID.
-	b = make([]byte, fat16IDSize)
-	if _, err := file.ReadAt(b, fat16IDOff); err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("%02x%02x-%02x%02x", b[3], b[2], b[1], b[0]), nil
-}
-
-// See https://de.wikipedia.org/wiki/File_Allocation_Table#Aufbau.
-const (
-	fat32Magic = "FAT32   "
-
-	// Offset of magic number.
-	fat32MagicOff  = 0x52
-	fat32MagicSize = 8
-
-	// Offset of filesystem ID / serial number. Treated as short filesystem UUID.
-	fat32IDOff  = 67
-	fat32IDSize = 4
-)
-
-func tryFAT32(file io.ReaderAt) (string, error) {
-	// Read magic number.
-	b := make([]byte, fat32MagicSize)
-	if _, err := file.ReadAt(b, fat32MagicOff); err != nil {
-		return "", err
-	}
-	magic := string(b)
-	if magic != fat32Magic {
-		return "", fmt.Errorf("fat32 magic not found")
-	}
-
-	// Filesystem UUID.
-	b = make([]byte, fat32IDSize)
-	if _, err := file.ReadAt(b, fat32IDOff); err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("%02x%02x-%02x%02x", b[3], b[2], b[1], b[0]), nil
-}
-
-const (
-	xfsMagic     = "XFSB"
-	xfsMagicSize = 4
-	xfsUUIDOff   = 32
-	xfsUUIDSize  = 16
-)
-
-func tryXFS(file io.ReaderAt) (string, error) {
-	// Read magic number.
-	b := make([]byte, xfsMagicSize)
-	if _, err := file.ReadAt(b, 0); err != nil {
-		return "", err
-	}
-	magic := string(b)
-	if magic != xfsMagic {
-		return "", fmt.Errorf("xfs magic not found")
-	}
-
-	// Filesystem UUID.
-	b = make([]byte, xfsUUIDSize)
-	if _, err := file.ReadAt(b, xfsUUIDOff); err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
-}
-
 // BlockDevices is a list of block devices.
 type BlockDevices []*BlockDev
 
@@ -470,6 +317,30 @@ func (b BlockDevices) FilterFSUUID(fsuuid string) BlockDevices {
 	return partitions
 }
 
+// FilterFSType returns a list of BlockDev objects whose underlying block
+// device has a filesystem of the given type, e.g. "ext4" or "squashfs".
+func (b BlockDevices) FilterFSType(fstype string) BlockDevices {
+	partitions := make(BlockDevices, 0)
+	for _, device := range b {
+		if device.FSType == fstype {
+			partitions = append(partitions, device)
+		}
+	}
+	return partitions
+}
+
+// FilterFSLabel returns a list of BlockDev objects whose underlying block
+// device has a filesystem with the given label.
+func (b BlockDevices) FilterFSLabel(label string) BlockDevices {
+	partitions := make(BlockDevices, 0)
+	for _, device := range b {
+		if device.FSLabel == label {
+			partitions = append(partitions, device)
+		}
+	}
+	return partitions
+}
+
 // FilterZeroSize attempts to find block devices that have at least one block
 // of content.
 //
@@ -0,0 +1,506 @@
+// Copyright 2017-2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrUnknownFS is returned by Probe when no Prober recognizes the superblock.
+var ErrUnknownFS = errors.New("unknown filesystem")
+
+// FSInfo describes a filesystem identified from its on-disk superblock.
+//
+// Type uses the names blkid(8) and mount(8) use, e.g. "ext4", "vfat",
+// "crypto_LUKS" or "swap".
+type FSInfo struct {
+	Type  string
+	UUID  string
+	Label string
+}
+
+// A Prober recognizes one filesystem type. It returns an error if the
+// superblock does not belong to the filesystem it knows about.
+type Prober func(r io.ReaderAt) (*FSInfo, error)
+
+// Probers is the list of superblock probers consulted by Probe, in order.
+//
+// Probers whose magic lives at a fixed offset in the first sector come
+// first, so that e.g. a LUKS header is never mistaken for something else.
+// Callers may append their own Prober to recognize additional filesystems.
+var Probers = []Prober{
+	probeLUKS,
+	probeFAT32,
+	probeFAT16,
+	probeNTFS,
+	probeSquashfs,
+	probeXFS,
+	probeEXT,
+	probeEROFS,
+	probeBtrfs,
+	probeISO9660,
+	probeSwap,
+}
+
+// Probe returns the filesystem found on r by the first matching Prober.
+func Probe(r io.ReaderAt) (*FSInfo, error) {
+	for _, p := range Probers {
+		if fs, err := p(r); err == nil {
+			return fs, nil
+		}
+	}
+	return nil, ErrUnknownFS
+}
+
+// ProbeFile opens devpath and probes it for a known filesystem.
+func ProbeFile(devpath string) (*FSInfo, error) {
+	f, err := os.Open(devpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Probe(f)
+}
+
+// readAt reads exactly n bytes at off.
+func readAt(r io.ReaderAt, off int64, n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := r.ReadAt(b, off); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// formatUUID formats a 16 byte UUID the way blkid does.
+func formatUUID(b []byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// cString returns b up to the first NUL byte.
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+// See https://www.nongnu.org/ext2-doc/ext2.html#DISK-ORGANISATION.
+const (
+	// Offset of superblock in partition.
+	ext2SprblkOff = 1024
+
+	// Offset of magic number in suberblock.
+	ext2SprblkMagicOff  = 56
+	ext2SprblkMagicSize = 2
+
+	ext2SprblkMagic = 0xEF53
+
+	// Offset of feature flags in superblock.
+	ext2SprblkFeatureCompatOff   = 92
+	ext2SprblkFeatureIncompatOff = 96
+	ext2SprblkFeatureROCompatOff = 100
+
+	// Offset of UUID in superblock.
+	ext2SprblkUUIDOff  = 104
+	ext2SprblkUUIDSize = 16
+
+	// Offset of volume name in superblock.
+	ext2SprblkLabelOff  = 120
+	ext2SprblkLabelSize = 16
+
+	ext3FeatureCompatHasJournal = 0x0004
+
+	// Features an ext3 filesystem may have. Anything beyond these means
+	// the filesystem needs the ext4 driver.
+	ext3FeatureIncompatSupp = 0x0002 | 0x0004 | 0x0008 | 0x0010
+	ext3FeatureROCompatSupp = 0x0001 | 0x0002 | 0x0004
+)
+
+func probeEXT(file io.ReaderAt) (*FSInfo, error) {
+	sb, err := readAt(file, ext2SprblkOff, ext2SprblkLabelOff+ext2SprblkLabelSize)
+	if err != nil {
+		return nil, err
+	}
+	magic := binary.LittleEndian.Uint16(sb[ext2SprblkMagicOff:])
+	if magic != ext2SprblkMagic {
+		return nil, fmt.Errorf("ext4 magic not found")
+	}
+
+	compat := binary.LittleEndian.Uint32(sb[ext2SprblkFeatureCompatOff:])
+	incompat := binary.LittleEndian.Uint32(sb[ext2SprblkFeatureIncompatOff:])
+	rocompat := binary.LittleEndian.Uint32(sb[ext2SprblkFeatureROCompatOff:])
+
+	fstype := "ext2"
+	switch {
+	case incompat&^ext3FeatureIncompatSupp != 0, rocompat&^ext3FeatureROCompatSupp != 0:
+		fstype = "ext4"
+	case compat&ext3FeatureCompatHasJournal != 0:
+		fstype = "ext3"
+	}
+
+	return &FSInfo{
+		Type:  fstype,
+		UUID:  formatUUID(sb[ext2SprblkUUIDOff : ext2SprblkUUIDOff+ext2SprblkUUIDSize]),
+		Label: cString(sb[ext2SprblkLabelOff : ext2SprblkLabelOff+ext2SprblkLabelSize]),
+	}, nil
+}
+
+// See https://de.wikipedia.org/wiki/File_Allocation_Table#Aufbau.
+const (
+	fat12Magic = "FAT12   "
+	fat16Magic = "FAT16   "
+
+	// Offset of magic number.
+	fat16MagicOff  = 0x36
+	fat16MagicSize = 8
+
+	// Offset of filesystem ID / serial number. Treated as short filesystem UUID.
+	fat16IDOff  = 0x27
+	fat16IDSize = 4
+
+	// Offset of the volume label.
+	fat16LabelOff = 0x2b
+
+	fatLabelSize = 11
+	// Label mkfs.vfat writes when none was given.
+	fatNoLabel = "NO NAME"
+)
+
+func fatInfo(id, label []byte) *FSInfo {
+	l := strings.TrimRight(string(label), " \x00")
+	if l == fatNoLabel {
+		l = ""
+	}
+	return &FSInfo{
+		Type:  "vfat",
+		UUID:  fmt.Sprintf("%02x%02x-%02x%02x", id[3], id[2], id[1], id[0]),
+		Label: l,
+	}
+}
+
+func probeFAT16(file io.ReaderAt) (*FSInfo, error) {
+	// Read magic number.
+	b, err := readAt(file, fat16MagicOff, fat16MagicSize)
+	if err != nil {
+		return nil, err
+	}
+	magic := string(b)
+	if magic != fat16Magic && magic != fat12Magic {
+		return nil, fmt.Errorf("fat16 magic not found")
+	}
+
+	id, err := readAt(file, fat16IDOff, fat16IDSize)
+	if err != nil {
+		return nil, err
+	}
+	label, err := readAt(file, fat16LabelOff, fatLabelSize)
+	if err != nil {
+		return nil, err
+	}
+	return fatInfo(id, label), nil
+}
+
+// See https://de.wikipedia.org/wiki/File_Allocation_Table#Aufbau.
+const (
+	fat32Magic = "FAT32   "
+
+	// Offset of magic number.
+	fat32MagicOff  = 0x52
+	fat32MagicSize = 8
+
+	// Offset of filesystem ID / serial number. Treated as short filesystem UUID.
+	fat32IDOff  = 67
+	fat32IDSize = 4
+
+	// Offset of the volume label.
+	fat32LabelOff = 0x47
+)
+
+func probeFAT32(file io.ReaderAt) (*FSInfo, error) {
+	// Read magic number.
+	b, err := readAt(file, fat32MagicOff, fat32MagicSize)
+	if err != nil {
+		return nil, err
+	}
+	if string(b) != fat32Magic {
+		return nil, fmt.Errorf("fat32 magic not found")
+	}
+
+	id, err := readAt(file, fat32IDOff, fat32IDSize)
+	if err != nil {
+		return nil, err
+	}
+	label, err := readAt(file, fat32LabelOff, fatLabelSize)
+	if err != nil {
+		return nil, err
+	}
+	return fatInfo(id, label), nil
+}
+
+// See https://righteousit.com/2018/05/21/xfs-part-1-superblock/.
+const (
+	xfsMagic     = "XFSB"
+	xfsMagicSize = 4
+	xfsUUIDOff   = 32
+	xfsUUIDSize  = 16
+	xfsLabelOff  = 108
+	xfsLabelSize = 12
+)
+
+func probeXFS(file io.ReaderAt) (*FSInfo, error) {
+	sb, err := readAt(file, 0, xfsLabelOff+xfsLabelSize)
+	if err != nil {
+		return nil, err
+	}
+	if string(sb[:xfsMagicSize]) != xfsMagic {
+		return nil, fmt.Errorf("xfs magic not found")
+	}
+
+	return &FSInfo{
+		Type:  "xfs",
+		UUID:  formatUUID(sb[xfsUUIDOff : xfsUUIDOff+xfsUUIDSize]),
+		Label: cString(sb[xfsLabelOff : xfsLabelOff+xfsLabelSize]),
+	}, nil
+}
+
+// See https://btrfs.readthedocs.io/en/latest/dev/On-disk-format.html#superblock.
+const (
+	btrfsSprblkOff = 0x10000
+
+	btrfsMagic    = "_BHRfS_M"
+	btrfsMagicOff = 0x40
+
+	btrfsUUIDOff   = 0x20
+	btrfsUUIDSize  = 16
+	btrfsLabelOff  = 0x12b
+	btrfsLabelSize = 256
+)
+
+func probeBtrfs(file io.ReaderAt) (*FSInfo, error) {
+	sb, err := readAt(file, btrfsSprblkOff, btrfsLabelOff+btrfsLabelSize)
+	if err != nil {
+		return nil, err
+	}
+	if string(sb[btrfsMagicOff:btrfsMagicOff+len(btrfsMagic)]) != btrfsMagic {
+		return nil, fmt.Errorf("btrfs magic not found")
+	}
+
+	return &FSInfo{
+		Type:  "btrfs",
+		UUID:  formatUUID(sb[btrfsUUIDOff : btrfsUUIDOff+btrfsUUIDSize]),
+		Label: cString(sb[btrfsLabelOff : btrfsLabelOff+btrfsLabelSize]),
+	}, nil
+}
+
+// Squashfs has neither a UUID nor a label.
+//
+// See https://dr-emann.github.io/squashfs/#superblock.
+const squashfsMagic = "hsqs"
+
+func probeSquashfs(file io.ReaderAt) (*FSInfo, error) {
+	b, err := readAt(file, 0, len(squashfsMagic))
+	if err != nil {
+		return nil, err
+	}
+	if string(b) != squashfsMagic {
+		return nil, fmt.Errorf("squashfs magic not found")
+	}
+	return &FSInfo{Type: "squashfs"}, nil
+}
+
+// See https://docs.kernel.org/filesystems/erofs.html#on-disk-details.
+const (
+	erofsSprblkOff = 1024
+	erofsMagic     = 0xE0F5E1E2
+
+	erofsUUIDOff   = 48
+	erofsUUIDSize  = 16
+	erofsLabelOff  = 64
+	erofsLabelSize = 16
+)
+
+func probeEROFS(file io.ReaderAt) (*FSInfo, error) {
+	sb, err := readAt(file, erofsSprblkOff, erofsLabelOff+erofsLabelSize)
+	if err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(sb) != erofsMagic {
+		return nil, fmt.Errorf("erofs magic not found")
+	}
+
+	return &FSInfo{
+		Type:  "erofs",
+		UUID:  formatUUID(sb[erofsUUIDOff : erofsUUIDOff+erofsUUIDSize]),
+		Label: cString(sb[erofsLabelOff : erofsLabelOff+erofsLabelSize]),
+	}, nil
+}
+
+// ISO 9660 volume descriptors start at sector 16 and are 2048 bytes each.
+//
+// See https://wiki.osdev.org/ISO_9660#Volume_Descriptors.
+const (
+	isoVDOff      = 0x8000
+	isoVDSize     = 2048
+	isoMaxVDs     = 32
+	isoMagic      = "CD001"
+	isoTypePVD    = 1
+	isoTypeVDTerm = 255
+
+	isoLabelOff     = 40
+	isoLabelSize    = 32
+	isoCreatedOff   = 813
+	isoModifiedOff  = 830
+	isoDateTimeSize = 16
+)
+
+func probeISO9660(file io.ReaderAt) (*FSInfo, error) {
+	for i := range int64(isoMaxVDs) {
+		vd, err := readAt(file, isoVDOff+i*isoVDSize, isoVDSize)
+		if err != nil {
+			return nil, err
+		}
+		if string(vd[1:6]) != isoMagic {
+			return nil, fmt.Errorf("iso9660 magic not found")
+		}
+		switch vd[0] {
+		case isoTypeVDTerm:
+			return nil, fmt.Errorf("iso9660 primary volume descriptor not found")
+		case isoTypePVD:
+			// Like blkid, derive the UUID from the modification
+			// date, or the creation date if there is none.
+			date := isoDate(vd[isoModifiedOff : isoModifiedOff+isoDateTimeSize])
+			if date == "" {
+				date = isoDate(vd[isoCreatedOff : isoCreatedOff+isoDateTimeSize])
+			}
+			return &FSInfo{
+				Type:  "iso9660",
+				UUID:  date,
+				Label: strings.TrimRight(string(vd[isoLabelOff:isoLabelOff+isoLabelSize]), " \x00"),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("iso9660 primary volume descriptor not found")
+}
+
+// isoDate formats an ISO 9660 "YYYYMMDDHHMMSSCC" date as
+// "YYYY-MM-DD-HH-MM-SS-CC". It returns "" for an unset date.
+func isoDate(d []byte) string {
+	if bytes.Count(d, []byte{'0'}) == len(d) || bytes.Count(d, []byte{0}) == len(d) {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s-%s-%s-%s-%s-%s", d[0:4], d[4:6], d[6:8], d[8:10], d[10:12], d[12:14], d[14:16])
+}
+
+// See https://gitlab.com/cryptsetup/cryptsetup/-/wikis/Specification.
+const (
+	luksMagic      = "LUKS\xba\xbe"
+	luksVersionOff = 6
+
+	// The UUID is at the same offset in LUKS1 and LUKS2 headers. It is
+	// stored as a string.
+	luksUUIDOff  = 168
+	luksUUIDSize = 40
+
+	// Only LUKS2 headers have a label.
+	luks2LabelOff  = 24
+	luks2LabelSize = 48
+)
+
+func probeLUKS(file io.ReaderAt) (*FSInfo, error) {
+	hdr, err := readAt(file, 0, luksUUIDOff+luksUUIDSize)
+	if err != nil {
+		return nil, err
+	}
+	if string(hdr[:len(luksMagic)]) != luksMagic {
+		return nil, fmt.Errorf("luks magic not found")
+	}
+
+	fs := &FSInfo{
+		Type: "crypto_LUKS",
+		UUID: cString(hdr[luksUUIDOff : luksUUIDOff+luksUUIDSize]),
+	}
+	switch v := binary.BigEndian.Uint16(hdr[luksVersionOff:]); v {
+	case 1:
+	case 2:
+		fs.Label = cString(hdr[luks2LabelOff : luks2LabelOff+luks2LabelSize])
+	default:
+		return nil, fmt.Errorf("unsupported luks version %d", v)
+	}
+	return fs, nil
+}
+
+// The swap signature lives in the last 10 bytes of the first page, which
+// depends on the page size of the machine that ran mkswap.
+//
+// See include/linux/swap.h.
+const (
+	swapMagicSize  = 10
+	swapUUIDOff    = 1036
+	swapUUIDSize   = 16
+	swapLabelOff   = 1052
+	swapLabelSize  = 16
+	swapV1Magic    = "SWAPSPACE2"
+	swapV0Magic    = "SWAP-SPACE"
+	swapHeaderSize = swapLabelOff + swapLabelSize
+)
+
+var swapPageSizes = []int64{4096, 8192, 16384, 65536}
+
+func probeSwap(file io.ReaderAt) (*FSInfo, error) {
+	for _, ps := range swapPageSizes {
+		b, err := readAt(file, ps-swapMagicSize, swapMagicSize)
+		if err != nil {
+			return nil, err
+		}
+		switch string(b) {
+		case swapV0Magic:
+			return &FSInfo{Type: "swap"}, nil
+		case swapV1Magic:
+			hdr, err := readAt(file, 0, swapHeaderSize)
+			if err != nil {
+				return nil, err
+			}
+			fs := &FSInfo{
+				Type:  "swap",
+				Label: cString(hdr[swapLabelOff : swapLabelOff+swapLabelSize]),
+			}
+			if uuid := hdr[swapUUIDOff : swapUUIDOff+swapUUIDSize]; !bytes.Equal(uuid, make([]byte, swapUUIDSize)) {
+				fs.UUID = formatUUID(uuid)
+			}
+			return fs, nil
+		}
+	}
+	return nil, fmt.Errorf("swap magic not found")
+}
+
+// The NTFS volume label lives in the $Volume MFT record, which we do not
+// parse; only the type and serial number are reported.
+//
+// See https://en.wikipedia.org/wiki/NTFS#Partition_Boot_Sector_(VBR).
+const (
+	ntfsMagic     = "NTFS    "
+	ntfsMagicOff  = 3
+	ntfsSerialOff = 0x48
+)
+
+func probeNTFS(file io.ReaderAt) (*FSInfo, error) {
+	b, err := readAt(file, 0, ntfsSerialOff+8)
+	if err != nil {
+		return nil, err
+	}
+	if string(b[ntfsMagicOff:ntfsMagicOff+len(ntfsMagic)]) != ntfsMagic {
+		return nil, fmt.Errorf("ntfs magic not found")
+	}
+	return &FSInfo{
+		Type: "ntfs",
+		UUID: fmt.Sprintf("%016X", binary.LittleEndian.Uint64(b[ntfsSerialOff:])),
+	}, nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package block
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var testUUID = []byte{
+	0x02, 0x17, 0x59, 0x89, 0xd4, 0x9f, 0x4e, 0x8e,
+	0x83, 0x6e, 0x99, 0x30, 0x0a, 0xf6, 0x6f, 0xc1,
+}
+
+const testUUIDString = "02175989-d49f-4e8e-836e-99300af66fc1"
+
+func TestProbe(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		image func(b []byte)
+		want  *FSInfo
+	}{
+		{
+			name: "ext4",
+			image: func(b []byte) {
+				binary.LittleEndian.PutUint16(b[ext2SprblkOff+ext2SprblkMagicOff:], ext2SprblkMagic)
+				binary.LittleEndian.PutUint32(b[ext2SprblkOff+ext2SprblkFeatureCompatOff:], ext3FeatureCompatHasJournal)
+				binary.LittleEndian.PutUint32(b[ext2SprblkOff+ext2SprblkFeatureIncompatOff:], 0x40 /* extents */)
+				copy(b[ext2SprblkOff+ext2SprblkUUIDOff:], testUUID)
+				copy(b[ext2SprblkOff+ext2SprblkLabelOff:], "root")
+			},
+			want: &FSInfo{Type: "ext4", UUID: testUUIDString, Label: "root"},
+		},
+		{
+			name: "ext3",
+			image: func(b []byte) {
+				binary.LittleEndian.PutUint16(b[ext2SprblkOff+ext2SprblkMagicOff:], ext2SprblkMagic)
+				binary.LittleEndian.PutUint32(b[ext2SprblkOff+ext2SprblkFeatureCompatOff:], ext3FeatureCompatHasJournal)
+				copy(b[ext2SprblkOff+ext2SprblkUUIDOff:], testUUID)
+			},
+			want: &FSInfo{Type: "ext3", UUID: testUUIDString},
+		},
+		{
+			name: "ext2",
+			image: func(b []byte) {
+				binary.LittleEndian.PutUint16(b[ext2SprblkOff+ext2SprblkMagicOff:], ext2SprblkMagic)
+				copy(b[ext2SprblkOff+ext2SprblkUUIDOff:], testUUID)
+			},
+			want: &FSInfo{Type: "ext2", UUID: testUUIDString},
+		},
+		{
+			name: "fat32",
+			image: func(b []byte) {
+				copy(b[fat32MagicOff:], fat32Magic)
+				copy(b[fat32IDOff:], []byte{0x44, 0x51, 0xe5, 0xac})
+				copy(b[fat32LabelOff:], "EFI        ")
+			},
+			want: &FSInfo{Type: "vfat", UUID: "ace5-5144", Label: "EFI"},
+		},
+		{
+			name: "fat16 without label",
+			image: func(b []byte) {
+				copy(b[fat16MagicOff:], fat16Magic)
+				copy(b[fat16IDOff:], []byte{0xb8, 0xd7, 0x96, 0xa8})
+				copy(b[fat16LabelOff:], "NO NAME    ")
+			},
+			want: &FSInfo{Type: "vfat", UUID: "a896-d7b8"},
+		},
+		{
+			name: "xfs",
+			image: func(b []byte) {
+				copy(b, xfsMagic)
+				copy(b[xfsUUIDOff:], testUUID)
+				copy(b[xfsLabelOff:], "data")
+			},
+			want: &FSInfo{Type: "xfs", UUID: testUUIDString, Label: "data"},
+		},
+		{
+			name: "btrfs",
+			image: func(b []byte) {
+				copy(b[btrfsSprblkOff+btrfsMagicOff:], btrfsMagic)
+				copy(b[btrfsSprblkOff+btrfsUUIDOff:], testUUID)
+				copy(b[btrfsSprblkOff+btrfsLabelOff:], "fedora")
+			},
+			want: &FSInfo{Type: "btrfs", UUID: testUUIDString, Label: "fedora"},
+		},
+		{
+			name: "squashfs",
+			image: func(b []byte) {
+				copy(b, squashfsMagic)
+			},
+			want: &FSInfo{Type: "squashfs"},
+		},
+		{
+			name: "erofs",
+			image: func(b []byte) {
+				binary.LittleEndian.PutUint32(b[erofsSprblkOff:], erofsMagic)
+				copy(b[erofsSprblkOff+erofsUUIDOff:], testUUID)
+				copy(b[erofsSprblkOff+erofsLabelOff:], "system")
+			},
+			want: &FSInfo{Type: "erofs", UUID: testUUIDString, Label: "system"},
+		},
+		{
+			name: "iso9660",
+			image: func(b []byte) {
+				pvd := b[isoVDOff:]
+				pvd[0] = isoTypePVD
+				copy(pvd[1:], isoMagic)
+				copy(pvd[isoLabelOff:], "Fedora-WS-Live-40               ")
+				copy(pvd[isoCreatedOff:], "2024041519550300")
+				copy(pvd[isoModifiedOff:], "0000000000000000")
+				term := b[isoVDOff+isoVDSize:]
+				term[0] = isoTypeVDTerm
+				copy(term[1:], isoMagic)
+			},
+			want: &FSInfo{Type: "iso9660", UUID: "2024-04-15-19-55-03-00", Label: "Fedora-WS-Live-40"},
+		},
+		{
+			name: "luks1",
+			image: func(b []byte) {
+				copy(b, luksMagic)
+				binary.BigEndian.PutUint16(b[luksVersionOff:], 1)
+				copy(b[luksUUIDOff:], testUUIDString)
+			},
+			want: &FSInfo{Type: "crypto_LUKS", UUID: testUUIDString},
+		},
+		{
+			name: "luks2",
+			image: func(b []byte) {
+				copy(b, luksMagic)
+				binary.BigEndian.PutUint16(b[luksVersionOff:], 2)
+				copy(b[luks2LabelOff:], "cryptroot")
+				copy(b[luksUUIDOff:], testUUIDString)
+			},
+			want: &FSInfo{Type: "crypto_LUKS", UUID: testUUIDString, Label: "cryptroot"},
+		},
+		{
+			name: "swap",
+			image: func(b []byte) {
+				copy(b[4096-swapMagicSize:], swapV1Magic)
+				copy(b[swapUUIDOff:], testUUID)
+				copy(b[swapLabelOff:], "swap0")
+			},
+			want: &FSInfo{Type: "swap", UUID: testUUIDString, Label: "swap0"},
+		},
+		{
+			name: "ntfs",
+			image: func(b []byte) {
+				copy(b[ntfsMagicOff:], ntfsMagic)
+				binary.LittleEndian.PutUint64(b[ntfsSerialOff:], 0x1234abcd5678ef90)
+			},
+			want: &FSInfo{Type: "ntfs", UUID: "1234ABCD5678EF90"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			b := make([]byte, 128*1024)
+			tt.image(b)
+			got, err := Probe(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("Probe() = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Probe() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeUnknown(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		size int
+	}{
+		{name: "zeros", size: 128 * 1024},
+		{name: "short", size: 512},
+		{name: "empty"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Probe(bytes.NewReader(make([]byte, tt.size))); !errors.Is(err, ErrUnknownFS) {
+				t.Errorf("Probe() = %v, want %v", err, ErrUnknownFS)
+			}
+		})
+	}
+}
+
+func TestProbeFile(t *testing.T) {
+	if _, err := ProbeFile("noexist"); err == nil {
+		t.Error("ProbeFile(noexist) = nil, want error")
+	}
+
+	b := make([]byte, 128*1024)
+	copy(b, squashfsMagic)
+	p := filepath.Join(t.TempDir(), "img")
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs, err := ProbeFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fs.Type != "squashfs" {
+		t.Errorf("ProbeFile() = %+v, want type squashfs", fs)
+	}
+}
+
+func TestBlockDevicesFilterFS(t *testing.T) {
+	devs := BlockDevices{
+		&BlockDev{Name: "sda1", FSType: "vfat", FsUUID: "ace5-5144", FSLabel: "EFI"},
+		&BlockDev{Name: "sda2", FSType: "btrfs", FsUUID: testUUIDString, FSLabel: "fedora"},
+		&BlockDev{Name: "sr0", FSType: "iso9660", FSLabel: "Fedora-WS-Live-40"},
+	}
+
+	if got, want := devs.FilterFSType("btrfs"), (BlockDevices{devs[1]}); !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterFSType(btrfs) = %v, want %v", got, want)
+	}
+	if got, want := devs.FilterFSLabel("Fedora-WS-Live-40"), (BlockDevices{devs[2]}); !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterFSLabel(Fedora-WS-Live-40) = %v, want %v", got, want)
+	}
+	if got := devs.FilterFSType("ntfs"); len(got) != 0 {
+		t.Errorf("FilterFSType(ntfs) = %v, want none", got)
+	}
+}
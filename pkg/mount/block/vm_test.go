@@ -72,7 +72,7 @@ func TestBlockDevMount(t *testing.T) {
 	devs := testDevs(t)
 	devs = devs.FilterName("sdd1")
 	want := BlockDevices{
-		&BlockDev{Name: prefix + "d1", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
+		&BlockDev{Name: prefix + "d1", FSType: "ext4", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
 	}
 	if !reflect.DeepEqual(devs, want) {
 		t.Fatalf("Test block devices: \n\t%v \nwant: \n\t%v", devs, want)
@@ -83,7 +83,7 @@ func TestBlockDevMount(t *testing.T) {
 	// Only testing for the right calls to the mount pkg here.
 	// Mounting itself is out of scope here and covered in pkg mount.
 
-	dev := devs[0] // FSType unset
+	dev := devs[0] // FSType as probed
 	mp, err := dev.Mount(mountPath, mount.ReadOnly)
 	if err != nil {
 		t.Errorf("%s.Mount() = _,%v \nunexpected error", dev.Name, err)
@@ -315,8 +315,8 @@ func TestBlockDevicesFilterPartType(t *testing.T) {
 			want: BlockDevices{
 				&BlockDev{Name: "nvme0n1p2"},
 				&BlockDev{Name: prefix + "c2"},
-				&BlockDev{Name: prefix + "d1", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
-				&BlockDev{Name: prefix + "d2", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
+				&BlockDev{Name: prefix + "d1", FSType: "ext4", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
+				&BlockDev{Name: prefix + "d2", FSType: "ext4", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
 			},
 		},
 	} {
@@ -335,7 +335,7 @@ func TestBlockDevicesFilterPartLabel(t *testing.T) {
 
 	label := "TEST_LABEL"
 	want := BlockDevices{
-		&BlockDev{Name: prefix + "d2", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
+		&BlockDev{Name: prefix + "d2", FSType: "ext4", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
 	}
 
 	parts := devs.FilterPartLabel(label)
@@ -358,18 +358,18 @@ func TestBlockDevicesFilterBlockPCIString(t *testing.T) {
 
 	want := BlockDevices{
 		&BlockDev{Name: prefix + "a"},
-		&BlockDev{Name: prefix + "a1", FsUUID: "2183ead8-a510-4b3d-9777-19c7090f66d9"},
-		&BlockDev{Name: prefix + "a2", FsUUID: "ace5-5144"},
-		&BlockDev{Name: prefix + "a3", FsUUID: "a896-d7b8"},
-		&BlockDev{Name: prefix + "a4", FsUUID: "dca5f234-726b-47e2-b16e-07d3dbde7d8c"},
+		&BlockDev{Name: prefix + "a1", FSType: "ext4", FsUUID: "2183ead8-a510-4b3d-9777-19c7090f66d9"},
+		&BlockDev{Name: prefix + "a2", FSType: "vfat", FsUUID: "ace5-5144"},
+		&BlockDev{Name: prefix + "a3", FSType: "vfat", FsUUID: "a896-d7b8"},
+		&BlockDev{Name: prefix + "a4", FSType: "xfs", FsUUID: "dca5f234-726b-47e2-b16e-07d3dbde7d8c"},
 		&BlockDev{Name: prefix + "b"},
 		&BlockDev{Name: prefix + "b1"},
 		&BlockDev{Name: prefix + "c"},
 		&BlockDev{Name: prefix + "c1"},
 		&BlockDev{Name: prefix + "c2"},
 		&BlockDev{Name: prefix + "d"},
-		&BlockDev{Name: prefix + "d1", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
-		&BlockDev{Name: prefix + "d2", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
+		&BlockDev{Name: prefix + "d1", FSType: "ext4", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
+		&BlockDev{Name: prefix + "d2", FSType: "ext4", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
 	}
 	if !reflect.DeepEqual(devs, want) {
 		t.Fatalf("Filtered block devices: \n\t%v \nwant: \n\t%v", devs, want)
@@ -390,18 +390,18 @@ func TestBlockDevicesFilterBlockPCI(t *testing.T) {
 
 	want := BlockDevices{
 		&BlockDev{Name: prefix + "a"},
-		&BlockDev{Name: prefix + "a1", FsUUID: "2183ead8-a510-4b3d-9777-19c7090f66d9"},
-		&BlockDev{Name: prefix + "a2", FsUUID: "ace5-5144"},
-		&BlockDev{Name: prefix + "a3", FsUUID: "a896-d7b8"},
-		&BlockDev{Name: prefix + "a4", FsUUID: "dca5f234-726b-47e2-b16e-07d3dbde7d8c"},
+		&BlockDev{Name: prefix + "a1", FSType: "ext4", FsUUID: "2183ead8-a510-4b3d-9777-19c7090f66d9"},
+		&BlockDev{Name: prefix + "a2", FSType: "vfat", FsUUID: "ace5-5144"},
+		&BlockDev{Name: prefix + "a3", FSType: "vfat", FsUUID: "a896-d7b8"},
+		&BlockDev{Name: prefix + "a4", FSType: "xfs", FsUUID: "dca5f234-726b-47e2-b16e-07d3dbde7d8c"},
 		&BlockDev{Name: prefix + "b"},
 		&BlockDev{Name: prefix + "b1"},
 		&BlockDev{Name: prefix + "c"},
 		&BlockDev{Name: prefix + "c1"},
 		&BlockDev{Name: prefix + "c2"},
 		&BlockDev{Name: prefix + "d"},
-		&BlockDev{Name: prefix + "d1", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
-		&BlockDev{Name: prefix + "d2", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
+		&BlockDev{Name: prefix + "d1", FSType: "ext4", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
+		&BlockDev{Name: prefix + "d2", FSType: "ext4", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
 	}
 	if !reflect.DeepEqual(devs, want) {
 		t.Fatalf("Filtered block devices: \n\t%v \nwant: \n\t%v", devs, want)
@@ -431,18 +431,18 @@ func testDevs(t *testing.T) BlockDevices {
 		&BlockDev{Name: "nvme0n1p1"},
 		&BlockDev{Name: "nvme0n1p2"},
 		&BlockDev{Name: prefix + "a"},
-		&BlockDev{Name: prefix + "a1", FsUUID: "2183ead8-a510-4b3d-9777-19c7090f66d9"},
-		&BlockDev{Name: prefix + "a2", FsUUID: "ace5-5144"},
-		&BlockDev{Name: prefix + "a3", FsUUID: "a896-d7b8"},
-		&BlockDev{Name: prefix + "a4", FsUUID: "dca5f234-726b-47e2-b16e-07d3dbde7d8c"},
+		&BlockDev{Name: prefix + "a1", FSType: "ext4", FsUUID: "2183ead8-a510-4b3d-9777-19c7090f66d9"},
+		&BlockDev{Name: prefix + "a2", FSType: "vfat", FsUUID: "ace5-5144"},
+		&BlockDev{Name: prefix + "a3", FSType: "vfat", FsUUID: "a896-d7b8"},
+		&BlockDev{Name: prefix + "a4", FSType: "xfs", FsUUID: "dca5f234-726b-47e2-b16e-07d3dbde7d8c"},
 		&BlockDev{Name: prefix + "b"},
 		&BlockDev{Name: prefix + "b1"},
 		&BlockDev{Name: prefix + "c"},
 		&BlockDev{Name: prefix + "c1"},
 		&BlockDev{Name: prefix + "c2"},
 		&BlockDev{Name: prefix + "d"},
-		&BlockDev{Name: prefix + "d1", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
-		&BlockDev{Name: prefix + "d2", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
+		&BlockDev{Name: prefix + "d1", FSType: "ext4", FsUUID: "02175989-d49f-4e8e-836e-99300af66fc1"},
+		&BlockDev{Name: prefix + "d2", FSType: "ext4", FsUUID: "f3323a7f-a90a-4342-9508-d042afed287d"},
 	}
 	if !reflect.DeepEqual(devs, want) {
 		t.Fatalf("Test block devices: \n\t%v \nwant: \n\t%v", devs, want)
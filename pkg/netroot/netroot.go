@@ -0,0 +1,448 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package netroot mounts NFS and iSCSI root file systems given on the kernel
+// command line, so that diskless machines can switch_root into them.
+//
+// It understands the kernel's ip=, root=/dev/nfs and nfsroot= arguments, see
+// https://docs.kernel.org/admin-guide/nfs/nfsroot.html, as well as dracut's
+// root=nfs:, root=nfs4:, netroot=iscsi: and root=dhcp.
+package netroot
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/u-root/u-root/pkg/cmdline"
+	"github.com/u-root/u-root/pkg/dhclient"
+)
+
+// ErrNotNetRoot is returned by FromCmdline when the root file system is not
+// on the network.
+var ErrNotNetRoot = errors.New("root is not a network file system")
+
+// Method is how to configure an interface.
+type Method int
+
+// Methods of IPConfig.
+const (
+	// Static assigns the client address of the IPConfig.
+	Static Method = iota
+	// DHCP requests a DHCPv4 lease.
+	DHCP
+	// DHCP6 requests a DHCPv6 lease.
+	DHCP6
+	// Off leaves the network alone.
+	Off
+)
+
+// String implements fmt.Stringer.
+func (m Method) String() string {
+	switch m {
+	case Static:
+		return "static"
+	case DHCP:
+		return "dhcp"
+	case DHCP6:
+		return "dhcp6"
+	case Off:
+		return "off"
+	}
+	return fmt.Sprintf("Method(%d)", int(m))
+}
+
+// methods maps the autoconf values of ip= to Methods. BOOTP and RARP are
+// served by DHCP.
+var methods = map[string]Method{
+	"off":     Off,
+	"none":    Off,
+	"on":      DHCP,
+	"any":     DHCP,
+	"dhcp":    DHCP,
+	"bootp":   DHCP,
+	"rarp":    DHCP,
+	"both":    DHCP,
+	"dhcp6":   DHCP6,
+	"auto6":   DHCP6,
+	"either6": DHCP6,
+}
+
+// IPConfig is the network configuration given by ip=.
+type IPConfig struct {
+	Method Method
+
+	// Device is the interface to configure. If empty, all Ethernet
+	// interfaces are tried.
+	Device string
+
+	// Client, Netmask, Gateway, Hostname and DNS are the static
+	// configuration.
+	Client   net.IP
+	Netmask  net.IPMask
+	Gateway  net.IP
+	Hostname string
+	DNS      []net.IP
+
+	// Server is the NFS server if nfsroot= does not name one.
+	Server net.IP
+}
+
+// splitFields splits s at colons outside of brackets, which enclose IPv6
+// addresses.
+func splitFields(s string) []string {
+	var fields []string
+	var depth, start int
+	for i, c := range s {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				fields = append(fields, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, s[start:])
+}
+
+func parseIPField(name, s string) (net.IP, error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if s == "" {
+		return nil, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return ip, nil
+}
+
+// ParseIP parses the value of the ip= kernel argument, one of
+//
+//	ip=<autoconf>
+//	ip=<device>:<autoconf>
+//	ip=<client-ip>:<server-ip>:<gw-ip>:<netmask>:<hostname>:<device>:<autoconf>:<dns0-ip>:<dns1-ip>
+//
+// where autoconf is off, none, on, any, dhcp, bootp, rarp, both, dhcp6 or
+// auto6. If autoconf is empty, off or none, the client address is assigned
+// if given. Otherwise, an empty autoconf means DHCP, and off or none leave
+// the network alone.
+func ParseIP(s string) (*IPConfig, error) {
+	if m, ok := methods[s]; ok {
+		return &IPConfig{Method: m}, nil
+	}
+	f := splitFields(s)
+	if len(f) == 2 {
+		if m, ok := methods[f[1]]; ok {
+			return &IPConfig{Method: m, Device: f[0]}, nil
+		}
+	}
+	f = append(f, make([]string, 10)...)
+
+	c := &IPConfig{
+		Hostname: f[4],
+		Device:   f[5],
+	}
+	var err error
+	if c.Client, err = parseIPField("client address", f[0]); err != nil {
+		return nil, err
+	}
+	if c.Server, err = parseIPField("server address", f[1]); err != nil {
+		return nil, err
+	}
+	if c.Gateway, err = parseIPField("gateway", f[2]); err != nil {
+		return nil, err
+	}
+	if f[3] != "" {
+		mask, err := parseIPField("netmask", f[3])
+		if err != nil {
+			return nil, err
+		}
+		if m4 := mask.To4(); m4 != nil {
+			mask = m4
+		}
+		c.Netmask = net.IPMask(mask)
+	}
+	for _, d := range f[7:9] {
+		dns, err := parseIPField("DNS server", d)
+		if err != nil {
+			return nil, err
+		}
+		if dns != nil {
+			c.DNS = append(c.DNS, dns)
+		}
+	}
+
+	switch m, ok := methods[f[6]]; {
+	case ok && m == Off && c.Client != nil:
+		c.Method = Static
+	case ok:
+		c.Method = m
+	case f[6] != "":
+		return nil, fmt.Errorf("invalid autoconf method %q", f[6])
+	case c.Client != nil:
+		c.Method = Static
+	default:
+		c.Method = DHCP
+	}
+	if c.Method == Static && c.Client == nil {
+		return nil, fmt.Errorf("ip=%s: static configuration without client address", s)
+	}
+	return c, nil
+}
+
+// NFSRoot is an NFS root file system.
+type NFSRoot struct {
+	// Server is the host name or address of the server. If empty, the
+	// server of ip= or the DHCP server is used.
+	Server string
+
+	// Path is the exported directory. "%s" is replaced by the client's
+	// address.
+	Path string
+
+	// Version is the NFS version, 3 or 4.
+	Version int
+
+	// Options are NFS mount options, such as "tcp" or "vers=4.2", see
+	// nfs(5).
+	Options []string
+}
+
+// parseNFS parses [<server>:]<path>, followed by sep and the options.
+func parseNFS(s string, sep string, version int) (*NFSRoot, error) {
+	n := &NFSRoot{Version: version}
+	// The path starts at the first ":/", as neither host names nor IPv6
+	// addresses contain a slash.
+	if i := strings.Index(s, ":/"); i >= 0 {
+		n.Server = strings.TrimSuffix(strings.TrimPrefix(s[:i], "["), "]")
+		s = s[i+1:]
+	}
+	if path, opts, ok := strings.Cut(s, sep); ok {
+		s = path
+		n.Options = strings.Split(opts, ",")
+	}
+	n.Path = s
+	if !strings.HasPrefix(n.Path, "/") {
+		return nil, fmt.Errorf("NFS root path %q is not absolute", n.Path)
+	}
+	return n, nil
+}
+
+// ParseNFSRoot parses the value of the kernel's nfsroot= argument,
+//
+//	[<server-ip>:]<root-dir>[,<nfs-options>]
+func ParseNFSRoot(s string) (*NFSRoot, error) {
+	return parseNFS(s, ",", 3)
+}
+
+// MountArgs returns the source, file system type and data to mount n with
+// mount(2), for the client address client and the server address server
+// if n does not name a server.
+//
+// Server host names are resolved, as the kernel needs an address. A path
+// containing "%s" needs the client address.
+func (n *NFSRoot) MountArgs(client, server net.IP) (source, fstype, data string, err error) {
+	if n.Server != "" {
+		if server = net.ParseIP(n.Server); server == nil {
+			addrs, err := net.LookupIP(n.Server)
+			if err != nil {
+				return "", "", "", err
+			}
+			server = addrs[0]
+		}
+	}
+	if server == nil {
+		return "", "", "", errors.New("no NFS server given")
+	}
+
+	path := n.Path
+	if strings.Contains(path, "%s") {
+		if client == nil {
+			return "", "", "", fmt.Errorf("NFS path %s needs the client address", path)
+		}
+		path = strings.ReplaceAll(path, "%s", client.String())
+	}
+	host := server.String()
+	if server.To4() == nil {
+		host = "[" + host + "]"
+	}
+
+	opts := append([]string{}, n.Options...)
+	has := func(names ...string) bool {
+		for _, o := range opts {
+			name, _, _ := strings.Cut(o, "=")
+			for _, n := range names {
+				if name == n {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	fstype = "nfs"
+	if n.Version == 4 {
+		fstype = "nfs4"
+	} else if !has("vers", "nfsvers") {
+		opts = append(opts, "vers=3")
+	}
+	if !has("lock", "nolock") && n.Version != 4 {
+		// There is no rpc.statd to lock with.
+		opts = append(opts, "nolock")
+	}
+	if !has("addr") {
+		opts = append(opts, "addr="+server.String())
+	}
+	if n.Version == 4 && client != nil && !has("clientaddr") {
+		opts = append(opts, "clientaddr="+client.String())
+	}
+	return host + ":" + path, fstype, strings.Join(opts, ","), nil
+}
+
+// ISCSIRoot is a root file system on an iSCSI LUN.
+type ISCSIRoot struct {
+	Target *net.TCPAddr
+	Volume string
+
+	// Initiator is the initiator name. If empty, one is made up from the
+	// MAC address of the interface.
+	Initiator string
+
+	// Device is the block device, such as /dev/sda1, holding the root
+	// file system. If empty, the first one of the LUN with a known file
+	// system is used.
+	Device string
+}
+
+// Root is a network root file system, from NFS, iSCSI or the root path of
+// the DHCP lease.
+type Root struct {
+	NFS   *NFSRoot
+	ISCSI *ISCSIRoot
+
+	// DHCP is set if the root is given by the DHCP root path option.
+	DHCP bool
+}
+
+// ParseRoot parses a network root= or netroot= value, one of
+//
+//	nfs:[<server>:]<path>[:<options>]
+//	nfs4:[<server>:]<path>[:<options>]
+//	iscsi:<server>:<protocol>:<port>:<LUN>:<target>
+//	dhcp, dhcp6
+//
+// and the DHCP root path form <server>:<path>[,<options>] for NFS. DHCP root
+// paths without server are on the DHCP server, which only Config.Mount knows.
+func ParseRoot(s string) (*Root, error) {
+	switch {
+	case s == "dhcp" || s == "dhcp6":
+		return &Root{DHCP: true}, nil
+
+	case strings.HasPrefix(s, "nfs4:"):
+		n, err := parseNFS(strings.TrimPrefix(s, "nfs4:"), ":", 4)
+		if err != nil {
+			return nil, err
+		}
+		return &Root{NFS: n}, nil
+
+	case strings.HasPrefix(s, "nfs:"):
+		n, err := parseNFS(strings.TrimPrefix(s, "nfs:"), ":", 3)
+		if err != nil {
+			return nil, err
+		}
+		return &Root{NFS: n}, nil
+
+	case strings.HasPrefix(s, "iscsi:"):
+		target, volume, err := dhclient.ParseISCSIURI(s)
+		if err != nil {
+			return nil, err
+		}
+		return &Root{ISCSI: &ISCSIRoot{Target: target, Volume: volume}}, nil
+
+	case strings.Contains(s, ":/"):
+		n, err := parseNFS(s, ",", 3)
+		if err != nil {
+			return nil, err
+		}
+		return &Root{NFS: n}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrNotNetRoot, s)
+}
+
+// Config is the network root configuration of the kernel command line.
+type Config struct {
+	// IP is the network configuration. If ip= is not given, DHCP is used
+	// on all Ethernet interfaces.
+	IP *IPConfig
+
+	Root *Root
+
+	// Init is the program to run in the new root.
+	Init string
+
+	// Initiator is the iSCSI initiator name, from rd.iscsi.initiator=.
+	Initiator string
+
+	// ReadOnly and Data are the mount flags and data of the root file
+	// system, from ro and rootflags=.
+	ReadOnly bool
+	Data     string
+}
+
+// DefaultInit is run in the new root if init= is not given.
+const DefaultInit = "/sbin/init"
+
+// FromCmdline returns the network root configuration of the kernel command
+// line c. It returns ErrNotNetRoot if the root is not on the network.
+func FromCmdline(c *cmdline.CmdLine) (*Config, error) {
+	conf := &Config{
+		IP:   &IPConfig{Method: DHCP},
+		Init: DefaultInit,
+	}
+	if v, ok := c.Flag("ip"); ok {
+		ip, err := ParseIP(v)
+		if err != nil {
+			return nil, err
+		}
+		conf.IP = ip
+	}
+	if v, ok := c.Flag("init"); ok && v != "" {
+		conf.Init = v
+	}
+	conf.ReadOnly = c.ContainsFlag("ro")
+	conf.Data, _ = c.Flag("rootflags")
+
+	root, _ := c.Flag("root")
+	var err error
+	switch netroot, ok := c.Flag("netroot"); {
+	case ok:
+		conf.Root, err = ParseRoot(netroot)
+		if err == nil && conf.Root.ISCSI != nil {
+			conf.Root.ISCSI.Device = root
+		}
+
+	case root == "/dev/nfs":
+		v, ok := c.Flag("nfsroot")
+		if !ok {
+			// The kernel would mount /tftpboot/<client-ip>.
+			v = "/tftpboot/%s"
+		}
+		var n *NFSRoot
+		n, err = ParseNFSRoot(v)
+		conf.Root = &Root{NFS: n}
+
+	default:
+		conf.Root, err = ParseRoot(root)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conf.Initiator, _ = c.Flag("rd.iscsi.initiator")
+	return conf, nil
+}
@@ -0,0 +1,280 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netroot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/u-root/iscsinl"
+	"github.com/u-root/u-root/pkg/dhclient"
+	"github.com/u-root/u-root/pkg/mount"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	dhcpTimeout   = 5 * time.Second
+	dhcpTries     = 3
+	linkUpTimeout = 30 * time.Second
+)
+
+// ConfigureNetwork configures the network as given by c, and returns the
+// lease describing the configuration, or nil if c.Method is Off.
+//
+// A static configuration is returned as a DHCPv4 lease, as if a DHCP server
+// had offered it.
+func ConfigureNetwork(ctx context.Context, c *IPConfig) (dhclient.Lease, error) {
+	if c.Method == Off {
+		return nil, nil
+	}
+	ifName := "^e.*"
+	if c.Device != "" {
+		ifName = "^" + regexp.QuoteMeta(c.Device) + "$"
+	}
+	if c.Hostname != "" {
+		if err := unix.Sethostname([]byte(c.Hostname)); err != nil {
+			return nil, fmt.Errorf("setting hostname: %w", err)
+		}
+	}
+
+	if c.Method == Static {
+		p, err := c.packet()
+		if err != nil {
+			return nil, err
+		}
+		ifs, err := dhclient.Interfaces(ifName)
+		if err != nil {
+			return nil, err
+		}
+		iface, err := dhclient.IfUp(ifs[0].Attrs().Name, linkUpTimeout)
+		if err != nil {
+			return nil, err
+		}
+		lease := dhclient.NewPacket4(iface, p)
+		if err := lease.Configure(); err != nil {
+			return nil, err
+		}
+		return lease, nil
+	}
+
+	ifs, err := dhclient.Interfaces(ifName)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, (1<<dhcpTries)*dhcpTimeout)
+	defer cancel()
+	r := dhclient.SendRequests(ctx, ifs, c.Method == DHCP, c.Method == DHCP6, dhclient.Config{
+		Timeout: dhcpTimeout,
+		Retries: dhcpTries,
+	}, linkUpTimeout)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+
+		case result, ok := <-r:
+			if !ok {
+				return nil, errors.New("no DHCP lease on any interface")
+			}
+			if result.Err != nil {
+				log.Printf("Could not configure %s for %s: %v", result.Interface.Attrs().Name, result.Protocol, result.Err)
+				continue
+			}
+			if err := result.Lease.Configure(); err != nil {
+				log.Printf("Failed to configure lease %s: %v", result.Lease, err)
+				continue
+			}
+			return result.Lease, nil
+		}
+	}
+}
+
+// packet returns the static configuration of c as a DHCPv4 offer. Static
+// IPv6 configurations are not supported.
+func (c *IPConfig) packet() (*dhcpv4.DHCPv4, error) {
+	if c.Client.To4() == nil {
+		return nil, fmt.Errorf("static configuration of %s: only IPv4 addresses are supported", c.Client)
+	}
+	p := &dhcpv4.DHCPv4{
+		YourIPAddr:   c.Client,
+		ServerIPAddr: c.Server,
+		Options:      make(dhcpv4.Options),
+	}
+	mask := c.Netmask
+	if mask == nil {
+		mask = c.Client.DefaultMask()
+	}
+	p.UpdateOption(dhcpv4.OptSubnetMask(mask))
+	if c.Gateway != nil {
+		p.UpdateOption(dhcpv4.OptRouter(c.Gateway))
+	}
+	if len(c.DNS) > 0 {
+		p.UpdateOption(dhcpv4.OptDNS(c.DNS...))
+	}
+	if c.Hostname != "" {
+		p.UpdateOption(dhcpv4.OptHostName(c.Hostname))
+	}
+	return p, nil
+}
+
+// leaseAddrs returns the client and server addresses of lease.
+func leaseAddrs(lease dhclient.Lease) (client, server net.IP) {
+	switch p := lease.(type) {
+	case *dhclient.Packet4:
+		client = p.P.YourIPAddr
+		if !p.P.ServerIPAddr.IsUnspecified() {
+			server = p.P.ServerIPAddr
+		}
+	case *dhclient.Packet6:
+		if iana := p.Lease(); iana != nil {
+			client = iana.IPv6Addr
+		}
+	}
+	return client, server
+}
+
+// resolve returns r with the root path of the DHCP lease, if r is given by
+// DHCP.
+//
+// A root path that is just a path is exported by the DHCP server, as the
+// kernel assumes, and left without server for Mount to use the lease's.
+func (r *Root) resolve(lease dhclient.Lease) (*Root, error) {
+	if !r.DHCP {
+		return r, nil
+	}
+	if lease == nil {
+		return nil, errors.New("root path from DHCP, but no DHCP lease")
+	}
+	if p, ok := lease.(*dhclient.Packet4); ok {
+		if rp := p.P.RootPath(); rp != "" && !strings.HasPrefix(rp, "iscsi:") {
+			if strings.HasPrefix(rp, "/") {
+				n, err := parseNFS(rp, ",", 3)
+				if err != nil {
+					return nil, err
+				}
+				return &Root{NFS: n}, nil
+			}
+			root, err := ParseRoot(rp)
+			if err != nil {
+				return nil, err
+			}
+			if root.DHCP {
+				return nil, fmt.Errorf("DHCP root path %q refers to DHCP again", rp)
+			}
+			return root, nil
+		}
+	}
+	target, volume, err := lease.ISCSIBoot()
+	if err != nil {
+		return nil, fmt.Errorf("root path from DHCP: %w", err)
+	}
+	return &Root{ISCSI: &ISCSIRoot{Target: target, Volume: volume}}, nil
+}
+
+// initiator returns an initiator name made up from the MAC address of the
+// interface of lease.
+func initiator(lease dhclient.Lease) string {
+	name := "iqn.2019-10.org.u-root:netroot"
+	if lease != nil {
+		if mac := lease.Link().Attrs().HardwareAddr; mac != nil {
+			name += "-" + strings.ReplaceAll(mac.String(), ":", "")
+		}
+	}
+	return name
+}
+
+// AttachISCSI logs in to the iSCSI target of i, and returns the names of
+// the block devices of its LUNs, such as sda.
+func AttachISCSI(i *ISCSIRoot, lease dhclient.Lease) ([]string, error) {
+	name := i.Initiator
+	if name == "" {
+		name = initiator(lease)
+	}
+	log.Printf("Logging in to iSCSI target %s volume %s as %s", i.Target, i.Volume, name)
+	return iscsinl.MountIscsi(
+		iscsinl.WithInitiator(name),
+		iscsinl.WithTarget(i.Target.String(), i.Volume),
+	)
+}
+
+// mountISCSI mounts the root file system of i at dir.
+func mountISCSI(i *ISCSIRoot, lease dhclient.Lease, dir string, flags uintptr, data string) (*mount.MountPoint, error) {
+	devs, err := AttachISCSI(i, lease)
+	if err != nil {
+		return nil, err
+	}
+	if i.Device != "" {
+		return mount.TryMount(i.Device, dir, data, flags)
+	}
+
+	// Try the whole LUNs, then their partitions.
+	var errs []error
+	for _, dev := range devs {
+		candidates := []string{dev}
+		parts, _ := filepath.Glob(filepath.Join("/sys/class/block", dev, dev+"*"))
+		for _, p := range parts {
+			candidates = append(candidates, filepath.Base(p))
+		}
+		for _, c := range candidates {
+			mp, err := mount.TryMount(filepath.Join("/dev", c), dir, data, flags)
+			if err == nil {
+				return mp, nil
+			}
+			errs = append(errs, err)
+		}
+	}
+	return nil, fmt.Errorf("no root file system on iSCSI volume %s: %w", i.Volume, errors.Join(errs...))
+}
+
+// Mount attaches the root file system of c and mounts it at dir, which is
+// created if needed.
+//
+// lease is the network configuration returned by ConfigureNetwork.
+func (c *Config) Mount(lease dhclient.Lease, dir string) (*mount.MountPoint, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var flags uintptr
+	if c.ReadOnly {
+		flags |= mount.MS_RDONLY
+	}
+
+	root, err := c.Root.resolve(lease)
+	if err != nil {
+		return nil, err
+	}
+	if root.ISCSI != nil {
+		if root.ISCSI.Initiator == "" {
+			root.ISCSI.Initiator = c.Initiator
+		}
+		return mountISCSI(root.ISCSI, lease, dir, flags, c.Data)
+	}
+
+	var client, server net.IP
+	if lease != nil {
+		client, server = leaseAddrs(lease)
+	}
+	if c.IP != nil && c.IP.Server != nil {
+		server = c.IP.Server
+	}
+	source, fstype, data, err := root.NFS.MountArgs(client, server)
+	if err != nil {
+		return nil, err
+	}
+	if c.Data != "" {
+		data += "," + c.Data
+	}
+	log.Printf("Mounting NFS root %s (%s)", source, data)
+	return mount.Mount(source, dir, fstype, data, flags)
+}
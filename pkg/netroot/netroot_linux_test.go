@@ -0,0 +1,74 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netroot
+
+import (
+	"net"
+	"testing"
+
+	"github.com/insomniacslk/dhcp/dhcpv4"
+	"github.com/u-root/u-root/pkg/dhclient"
+)
+
+func TestPacket(t *testing.T) {
+	c, err := ParseIP("10.0.0.5::10.0.0.254::node5:eth0:off")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := c.packet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.YourIPAddr.Equal(net.ParseIP("10.0.0.5")) || p.SubnetMask().String() != net.CIDRMask(8, 32).String() || p.HostName() != "node5" {
+		t.Errorf("packet() = %v, want 10.0.0.5/8 with host name node5", p)
+	}
+
+	c, err = ParseIP("[fd00::5]:::::eth0:off")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.packet(); err == nil {
+		t.Errorf("packet() of %v = nil, want error", c.Client)
+	}
+}
+
+func TestResolveRootPath(t *testing.T) {
+	lease := func(rootPath string) dhclient.Lease {
+		return dhclient.NewPacket4(nil, &dhcpv4.DHCPv4{
+			YourIPAddr:   net.ParseIP("10.0.0.5"),
+			ServerIPAddr: net.ParseIP("10.0.0.1"),
+			Options:      dhcpv4.OptionsFromList(dhcpv4.OptRootPath(rootPath)),
+		})
+	}
+	for _, tt := range []struct {
+		rootPath   string
+		wantSource string
+		wantData   string
+	}{
+		// The server of a bare path is the DHCP server.
+		{rootPath: "/srv/nfsroot/%s", wantSource: "10.0.0.1:/srv/nfsroot/10.0.0.5", wantData: "vers=3,nolock,addr=10.0.0.1"},
+		{rootPath: "/srv/nfsroot,tcp", wantSource: "10.0.0.1:/srv/nfsroot", wantData: "tcp,vers=3,nolock,addr=10.0.0.1"},
+		{rootPath: "10.0.0.2:/srv/nfsroot", wantSource: "10.0.0.2:/srv/nfsroot", wantData: "vers=3,nolock,addr=10.0.0.2"},
+	} {
+		l := lease(tt.rootPath)
+		root, err := (&Root{DHCP: true}).resolve(l)
+		if err != nil {
+			t.Errorf("resolve(%q) = %v", tt.rootPath, err)
+			continue
+		}
+		if root.NFS == nil {
+			t.Errorf("resolve(%q) = %+v, want NFS root", tt.rootPath, root)
+			continue
+		}
+		source, _, data, err := root.NFS.MountArgs(leaseAddrs(l))
+		if err != nil || source != tt.wantSource || data != tt.wantData {
+			t.Errorf("MountArgs of %q = %q, %q, %v, want %q, %q", tt.rootPath, source, data, err, tt.wantSource, tt.wantData)
+		}
+	}
+
+	if root, err := (&Root{DHCP: true}).resolve(lease("dhcp")); err == nil {
+		t.Errorf("resolve(dhcp) = %+v, want error", root)
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package netroot
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/u-root/u-root/pkg/cmdline"
+)
+
+func TestParseIP(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want *IPConfig
+		err  bool
+	}{
+		{in: "dhcp", want: &IPConfig{Method: DHCP}},
+		{in: "off", want: &IPConfig{Method: Off}},
+		{in: "eth1:dhcp6", want: &IPConfig{Method: DHCP6, Device: "eth1"}},
+		{
+			in: "10.0.0.5:10.0.0.1:10.0.0.254:255.255.255.0:node5:eth0:off:10.0.0.53",
+			want: &IPConfig{
+				Method:   Static,
+				Client:   net.ParseIP("10.0.0.5"),
+				Server:   net.ParseIP("10.0.0.1"),
+				Gateway:  net.ParseIP("10.0.0.254"),
+				Netmask:  net.CIDRMask(24, 32),
+				Hostname: "node5",
+				Device:   "eth0",
+				DNS:      []net.IP{net.ParseIP("10.0.0.53")},
+			},
+		},
+		{
+			in: "10.0.0.5::10.0.0.254:255.255.255.0:node5:eth0:off",
+			want: &IPConfig{
+				Method:   Static,
+				Client:   net.ParseIP("10.0.0.5"),
+				Gateway:  net.ParseIP("10.0.0.254"),
+				Netmask:  net.CIDRMask(24, 32),
+				Hostname: "node5",
+				Device:   "eth0",
+			},
+		},
+		{
+			in: "10.0.0.5::::node5:eth0",
+			want: &IPConfig{
+				Method:   Static,
+				Client:   net.ParseIP("10.0.0.5"),
+				Hostname: "node5",
+				Device:   "eth0",
+			},
+		},
+		{
+			in: "[fd00::5]:[fd00::1]:::::none",
+			want: &IPConfig{
+				Method: Static,
+				Client: net.ParseIP("fd00::5"),
+				Server: net.ParseIP("fd00::1"),
+			},
+		},
+		{in: ":::::eth0:dhcp", want: &IPConfig{Method: DHCP, Device: "eth0"}},
+		{in: ":::::eth0:off", want: &IPConfig{Method: Off, Device: "eth0"}},
+		{in: ":10.0.0.1", want: &IPConfig{Method: DHCP, Server: net.ParseIP("10.0.0.1")}},
+		{in: "10.0.0.300", err: true},
+		{in: "10.0.0.5::::::magic", err: true},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseIP(tt.in)
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseIP(%q) = %v, want error %t", tt.in, err, tt.err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseIP(%q) mismatch (-want +got):\n%s", tt.in, diff)
+			}
+		})
+	}
+}
+
+func TestParseRoot(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want *Root
+		err  error
+	}{
+		{in: "dhcp", want: &Root{DHCP: true}},
+		{
+			in:   "nfs:10.0.0.1:/export/root",
+			want: &Root{NFS: &NFSRoot{Server: "10.0.0.1", Path: "/export/root", Version: 3}},
+		},
+		{
+			in:   "nfs4:[fd00::1]:/export/root:vers=4.2,tcp",
+			want: &Root{NFS: &NFSRoot{Server: "fd00::1", Path: "/export/root", Version: 4, Options: []string{"vers=4.2", "tcp"}}},
+		},
+		{
+			in:   "nfs:/export/%s",
+			want: &Root{NFS: &NFSRoot{Path: "/export/%s", Version: 3}},
+		},
+		{
+			// DHCP root path.
+			in:   "nfs.example.com:/export/root,ro",
+			want: &Root{NFS: &NFSRoot{Server: "nfs.example.com", Path: "/export/root", Version: 3, Options: []string{"ro"}}},
+		},
+		{
+			in: "iscsi:192.168.1.1::3261::iqn.2026-01.com.example:root",
+			want: &Root{ISCSI: &ISCSIRoot{
+				Target: &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 3261},
+				Volume: "iqn.2026-01.com.example:root",
+			}},
+		},
+		{in: "/dev/sda1", err: ErrNotNetRoot},
+		{in: "nfs:export", err: errors.New("not absolute")},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRoot(tt.in)
+			if (err != nil) != (tt.err != nil) || errors.Is(tt.err, ErrNotNetRoot) && !errors.Is(err, ErrNotNetRoot) {
+				t.Fatalf("ParseRoot(%q) = %v, want %v", tt.in, err, tt.err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseRoot(%q) mismatch (-want +got):\n%s", tt.in, diff)
+			}
+		})
+	}
+}
+
+func TestFromCmdline(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args map[string]string
+		want *Config
+		err  error
+	}{
+		{
+			name: "kernel nfsroot",
+			args: map[string]string{"root": "/dev/nfs", "nfsroot": "10.0.0.1:/srv/%s,tcp", "ip": "dhcp", "ro": ""},
+			want: &Config{
+				IP:       &IPConfig{Method: DHCP},
+				Root:     &Root{NFS: &NFSRoot{Server: "10.0.0.1", Path: "/srv/%s", Version: 3, Options: []string{"tcp"}}},
+				Init:     DefaultInit,
+				ReadOnly: true,
+			},
+		},
+		{
+			name: "kernel default nfsroot",
+			args: map[string]string{"root": "/dev/nfs"},
+			want: &Config{
+				IP:   &IPConfig{Method: DHCP},
+				Root: &Root{NFS: &NFSRoot{Path: "/tftpboot/%s", Version: 3}},
+				Init: DefaultInit,
+			},
+		},
+		{
+			name: "dracut iscsi",
+			args: map[string]string{
+				"netroot":            "iscsi:192.168.1.1::::iqn.2026-01.com.example:root",
+				"root":               "/dev/sda2",
+				"rd.iscsi.initiator": "iqn.2026-01.com.example:node5",
+				"init":               "/lib/systemd/systemd",
+				"rootflags":          "noatime",
+			},
+			want: &Config{
+				IP: &IPConfig{Method: DHCP},
+				Root: &Root{ISCSI: &ISCSIRoot{
+					Target: &net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 3260},
+					Volume: "iqn.2026-01.com.example:root",
+					Device: "/dev/sda2",
+				}},
+				Init:      "/lib/systemd/systemd",
+				Initiator: "iqn.2026-01.com.example:node5",
+				Data:      "noatime",
+			},
+		},
+		{
+			name: "local root",
+			args: map[string]string{"root": "/dev/sda1"},
+			err:  ErrNotNetRoot,
+		},
+		{
+			name: "no root",
+			args: map[string]string{},
+			err:  ErrNotNetRoot,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromCmdline(&cmdline.CmdLine{AsMap: tt.args})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("FromCmdline = %v, want %v", err, tt.err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("FromCmdline mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMountArgs(t *testing.T) {
+	client := net.ParseIP("10.0.0.5")
+	server := net.ParseIP("10.0.0.1")
+	for _, tt := range []struct {
+		root       string
+		wantSource string
+		wantFSType string
+		wantData   string
+	}{
+		{
+			root:       "nfs:/srv/%s",
+			wantSource: "10.0.0.1:/srv/10.0.0.5",
+			wantFSType: "nfs",
+			wantData:   "vers=3,nolock,addr=10.0.0.1",
+		},
+		{
+			root:       "nfs:10.0.0.2:/srv:tcp,lock",
+			wantSource: "10.0.0.2:/srv",
+			wantFSType: "nfs",
+			wantData:   "tcp,lock,vers=3,addr=10.0.0.2",
+		},
+		{
+			root:       "nfs4:[fd00::1]:/srv",
+			wantSource: "[fd00::1]:/srv",
+			wantFSType: "nfs4",
+			wantData:   "addr=fd00::1,clientaddr=10.0.0.5",
+		},
+	} {
+		t.Run(tt.root, func(t *testing.T) {
+			r, err := ParseRoot(tt.root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			source, fstype, data, err := r.NFS.MountArgs(client, server)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if source != tt.wantSource || fstype != tt.wantFSType || data != tt.wantData {
+				t.Errorf("MountArgs = %q, %q, %q, want %q, %q, %q", source, fstype, data, tt.wantSource, tt.wantFSType, tt.wantData)
+			}
+		})
+	}
+
+	n := &NFSRoot{Path: "/srv"}
+	if _, _, _, err := n.MountArgs(client, nil); err == nil || !strings.Contains(err.Error(), "no NFS server") {
+		t.Errorf("MountArgs without server = %v, want error", err)
+	}
+	n = &NFSRoot{Path: "/srv/%s"}
+	if _, _, _, err := n.MountArgs(nil, server); err == nil || !strings.Contains(err.Error(), "client address") {
+		t.Errorf("MountArgs of %s without client = %v, want error", n.Path, err)
+	}
+}
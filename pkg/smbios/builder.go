@@ -0,0 +1,125 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Structure is an SMBIOS structure that can be serialized by a Builder.
+//
+// BIOSInfo, SystemInfo, BaseboardInfo, ChassisInfo, ProcessorInfo,
+// PhysicalMemoryArray, MemoryDevice and EndOfTable derive their header from
+// their fields. OEMStrings and GroupAssociation must have their Length set.
+// A *Table, e.g. one read from the running system, is copied verbatim.
+type Structure interface {
+	toTable() (*Table, error)
+}
+
+func (t *Table) toTable() (*Table, error) {
+	return &Table{
+		Header:  t.Header,
+		data:    append([]byte(nil), t.data...),
+		strings: append([]string(nil), t.strings...),
+	}, nil
+}
+
+// maxHandle is the largest handle that can be assigned to a structure,
+// DSP0134 6.1.2 reserves 0xFF00 and above.
+const maxHandle = 0xfeff
+
+// Builder assembles a new set of SMBIOS structures, e.g. to describe the
+// machine to a kexec'd kernel.
+type Builder struct {
+	major, minor, docRev uint8
+
+	tables []*Table
+}
+
+// NewBuilder returns a Builder for tables conforming to SMBIOS version
+// major.minor.docRev. Only 3.x versions can be described by the 64-bit entry
+// point that the Builder emits.
+func NewBuilder(major, minor, docRev uint8) (*Builder, error) {
+	if major < 3 {
+		return nil, fmt.Errorf("SMBIOS version %d.%d has no 64-bit entry point", major, minor)
+	}
+	return &Builder{major: major, minor: minor, docRev: docRev}, nil
+}
+
+// Add serializes s with its string set, assigns it the next free handle and
+// returns that handle so that other structures can refer to it.
+//
+// An End-of-Table structure is added by Marshal and need not be added here.
+func (b *Builder) Add(s Structure) (uint16, error) {
+	if len(b.tables) > maxHandle {
+		return 0, errors.New("out of handles")
+	}
+	t, err := s.toTable()
+	if err != nil {
+		return 0, err
+	}
+	if int(t.Length) != len(t.data) {
+		return 0, fmt.Errorf("%s: header length %d does not match the %d bytes of data", t.Type, t.Length, len(t.data))
+	}
+	if t.Type == TableTypeEndOfTable {
+		return 0, errors.New("End-of-Table structure is added by Marshal")
+	}
+	t.Handle = uint16(len(b.tables))
+	binary.LittleEndian.PutUint16(t.data[2:4], t.Handle)
+	b.tables = append(b.tables, t)
+	return t.Handle, nil
+}
+
+// Info returns the structures added so far, terminated by an End-of-Table
+// structure, along with a 64-bit entry point for them. The entry point's
+// structure table address is tableAddr.
+func (b *Builder) Info(tableAddr uint64) (*Info, error) {
+	eot, err := (&EndOfTable{}).toTable()
+	if err != nil {
+		return nil, err
+	}
+	eot.Handle = uint16(len(b.tables))
+	binary.LittleEndian.PutUint16(eot.data[2:4], eot.Handle)
+
+	e := &Entry64{
+		Length:             0x18,
+		SMBIOSMajorVersion: b.major,
+		SMBIOSMinorVersion: b.minor,
+		SMBIOSDocRev:       b.docRev,
+		Revision:           1, // Entry point revision 3.0.
+		StructTableAddr:    tableAddr,
+	}
+	copy(e.Anchor[:], "_SM3_")
+	return &Info{
+		Entry64: e,
+		Tables:  append(append([]*Table(nil), b.tables...), eot),
+	}, nil
+}
+
+// Marshal returns the 64-bit entry point and the structure table, with the
+// entry point referring to the table at physical address tableAddr.
+func (b *Builder) Marshal(tableAddr uint64) (entry, tables []byte, err error) {
+	i, err := b.Info(tableAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return i.Marshal()
+}
+
+// Image returns the 64-bit entry point followed by the structure table, laid
+// out to be loaded at physical address addr, e.g. as a kexec segment. The
+// entry point is at addr; the table follows it, 16-byte aligned.
+func (b *Builder) Image(addr uint64) ([]byte, error) {
+	const tableOffset = 0x20
+	entry, tables, err := b.Marshal(addr + tableOffset)
+	if err != nil {
+		return nil, err
+	}
+	img := make([]byte, tableOffset, tableOffset+len(tables))
+	copy(img, entry)
+	return append(img, tables...), nil
+}
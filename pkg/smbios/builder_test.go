@@ -0,0 +1,273 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	b, err := NewBuilder(3, 2, 0)
+	if err != nil {
+		t.Fatalf("NewBuilder() = %v", err)
+	}
+
+	bios := &BIOSInfo{
+		Vendor:                 "u-root",
+		Version:                "1.0",
+		StartingAddressSegment: 0xe800,
+		ReleaseDate:            "10/17/2026",
+		ROMSize:                0xff,
+		Characteristics:        BIOSCharacteristicsPCIIsSupported,
+		CharacteristicsExt2:    BIOSCharacteristicsExt2UEFISpecificationIsSupported,
+		SystemBIOSMajorRelease: 1,
+		ExtendedROMSize:        16,
+	}
+	sys := &SystemInfo{
+		Manufacturer: "u-root",
+		ProductName:  "kexec",
+		Version:      "1.0",
+		SerialNumber: "0123",
+		UUID:         UUID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		WakeupType:   WakeupTypePowerSwitch,
+		SKUNumber:    "SKU",
+		Family:       "Linux",
+	}
+	chassis := &ChassisInfo{
+		Manufacturer:                  "u-root",
+		Type:                          ChassisTypeRackMountChassis,
+		BootupState:                   ChassisStateSafe,
+		PowerSupplyState:              ChassisStateSafe,
+		ThermalState:                  ChassisStateSafe,
+		SecurityStatus:                ChassisSecurityStatusNone,
+		Height:                        1,
+		ContainedElementCount:         1,
+		ContainedElementsRecordLength: 3,
+		ContainedElements:             []ChassisContainedElement{{Type: 0x82, Min: 1, Max: 2}},
+		SKUNumber:                     "SKU",
+	}
+	array := &PhysicalMemoryArray{
+		Location:              PhysicalMemoryArrayLocationSystemBoardOrMotherboard,
+		Use:                   PhysicalMemoryArrayUseSystemMemory,
+		ErrorCorrection:       PhysicalMemoryArrayECCNone,
+		MaximumCapacity:       16 * 1024 * 1024,
+		ErrorInfoHandle:       0xfffe,
+		NumberOfMemoryDevices: 1,
+	}
+	cpu := &ProcessorInfo{
+		SocketDesignation: "CPU0",
+		Type:              ProcessorTypeCentralProcessor,
+		Family:            0xfe,
+		Manufacturer:      "u-root",
+		Status:            0x41,
+		Upgrade:           ProcessorUpgradeNone,
+		L1CacheHandle:     0xffff,
+		L2CacheHandle:     0xffff,
+		L3CacheHandle:     0xffff,
+		CoreCount:         4,
+		CoreEnabled:       4,
+		ThreadCount:       8,
+		Family2:           ProcessorFamilyOther,
+	}
+
+	for _, s := range []Structure{bios, sys, chassis, array, cpu} {
+		if _, err := b.Add(s); err != nil {
+			t.Fatalf("Add(%T) = %v", s, err)
+		}
+	}
+	board := &BaseboardInfo{
+		Manufacturer:                   "u-root",
+		Product:                        "kexec",
+		Version:                        "1.0",
+		SerialNumber:                   "0123",
+		AssetTag:                       "none",
+		BoardFeatures:                  BoardFeaturesIsHotSwappable,
+		LocationInChassis:              "slot",
+		ChassisHandle:                  2,
+		BoardType:                      BoardTypeMotherboardIncludesProcessorMemoryAndIO,
+		NumberOfContainedObjectHandles: 1,
+		ContainedObjectHandles:         []uint16{4},
+	}
+	dimm := &MemoryDevice{
+		PhysicalMemoryArrayHandle: 3,
+		MemoryErrorInfoHandle:     0xfffe,
+		TotalWidth:                64,
+		DataWidth:                 64,
+		Size:                      0x7fff,
+		FormFactor:                MemoryDeviceFormFactorDIMM,
+		DeviceLocator:             "DIMM0",
+		Type:                      MemoryDeviceTypeDDR4,
+		ExtendedSize:              64 * 1024,
+		PartNumber:                "DIMM",
+	}
+	for i, s := range []Structure{board, dimm} {
+		h, err := b.Add(s)
+		if err != nil {
+			t.Fatalf("Add(%T) = %v", s, err)
+		}
+		if want := uint16(5 + i); h != want {
+			t.Errorf("Add(%T) = handle %d, want %d", s, h, want)
+		}
+	}
+
+	const addr = 0x7f000000
+	img, err := b.Image(addr)
+	if err != nil {
+		t.Fatalf("Image() = %v", err)
+	}
+	info, err := ParseInfo(img[:0x18], img[0x20:])
+	if err != nil {
+		t.Fatalf("ParseInfo() = %v", err)
+	}
+	if info.Entry64 == nil {
+		t.Fatalf("ParseInfo() = %v, want a 64-bit entry point", info)
+	}
+	if got, want := info.Entry64.StructTableAddr, uint64(addr+0x20); got != want {
+		t.Errorf("StructTableAddr = %#x, want %#x", got, want)
+	}
+	if got, want := int(info.Entry64.StructMaxSize), len(img)-0x20; got != want {
+		t.Errorf("StructMaxSize = %d, want %d", got, want)
+	}
+	if got, want := len(info.Tables), 8; got != want {
+		t.Fatalf("got %d tables, want %d", got, want)
+	}
+	for i, tbl := range info.Tables {
+		if tbl.Handle != uint16(i) {
+			t.Errorf("table %d has handle %d", i, tbl.Handle)
+		}
+	}
+
+	gotBIOS, err := info.GetBIOSInfo()
+	if err != nil {
+		t.Fatalf("GetBIOSInfo() = %v", err)
+	}
+	if gotBIOS.Len() != 0x1a || gotBIOS.Vendor != bios.Vendor || gotBIOS.ReleaseDate != bios.ReleaseDate ||
+		gotBIOS.GetROMSizeBytes() != 16*1024*1024 || gotBIOS.CharacteristicsExt2 != bios.CharacteristicsExt2 {
+		t.Errorf("GetBIOSInfo() = %v", gotBIOS)
+	}
+	gotSys, err := info.GetSystemInfo()
+	if err != nil {
+		t.Fatalf("GetSystemInfo() = %v", err)
+	}
+	gotSys.Header = sys.Header
+	if !reflect.DeepEqual(gotSys, sys) {
+		t.Errorf("GetSystemInfo() = %+v, want %+v", gotSys, sys)
+	}
+	gotBoard, err := info.GetBaseboardInfo()
+	if err != nil || len(gotBoard) != 1 {
+		t.Fatalf("GetBaseboardInfo() = %v, %v", gotBoard, err)
+	}
+	gotBoard[0].Header = board.Header
+	if !reflect.DeepEqual(gotBoard[0], board) {
+		t.Errorf("GetBaseboardInfo() = %+v, want %+v", gotBoard[0], board)
+	}
+	gotChassis, err := info.GetChassisInfo()
+	if err != nil || len(gotChassis) != 1 {
+		t.Fatalf("GetChassisInfo() = %v, %v", gotChassis, err)
+	}
+	if c := gotChassis[0]; c.SKUNumber != "SKU" || !reflect.DeepEqual(c.ContainedElements, chassis.ContainedElements) || c.Height != 1 {
+		t.Errorf("GetChassisInfo() = %v", c)
+	}
+	gotCPU, err := info.GetProcessorInfo()
+	if err != nil || len(gotCPU) != 1 {
+		t.Fatalf("GetProcessorInfo() = %v, %v", gotCPU, err)
+	}
+	if c := gotCPU[0]; c.Len() != 0x30 || c.SocketDesignation != "CPU0" || c.GetFamily() != ProcessorFamilyOther || c.ThreadCount != 8 {
+		t.Errorf("GetProcessorInfo() = %v", c)
+	}
+	gotArrays, err := info.GetPhysicalMemoryArrays()
+	if err != nil || len(gotArrays) != 1 {
+		t.Fatalf("GetPhysicalMemoryArrays() = %v, %v", gotArrays, err)
+	}
+	if got := gotArrays[0].GetMaximumCapacityBytes(); got != 16<<30 {
+		t.Errorf("GetMaximumCapacityBytes() = %d, want %d", got, 16<<30)
+	}
+	gotDIMMs, err := info.GetMemoryDevices()
+	if err != nil || len(gotDIMMs) != 1 {
+		t.Fatalf("GetMemoryDevices() = %v, %v", gotDIMMs, err)
+	}
+	if d := gotDIMMs[0]; d.Len() != 0x54 || d.GetSizeBytes() != 64<<30 || d.DeviceLocator != "DIMM0" ||
+		d.PartNumber != "DIMM" || d.BankLocator != "Not Specified" || d.PhysicalMemoryArrayHandle != 3 {
+		t.Errorf("GetMemoryDevices() = %v", d)
+	}
+	if eot := info.Tables[7]; eot.Type != TableTypeEndOfTable || eot.Length != 4 {
+		t.Errorf("last table = %v, want End-of-Table", eot)
+	}
+}
+
+func TestBuilderRawTable(t *testing.T) {
+	b, err := NewBuilder(3, 0, 0)
+	if err != nil {
+		t.Fatalf("NewBuilder() = %v", err)
+	}
+	raw := &Table{
+		Header:  Header{Type: 0xc0, Length: 6, Handle: 0x1234},
+		data:    []byte{0xc0, 6, 0x34, 0x12, 1, 0},
+		strings: []string{"OEM"},
+	}
+	if _, err := b.Add(raw); err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+	if raw.Handle != 0x1234 || raw.data[2] != 0x34 {
+		t.Errorf("Add() modified the table it was given")
+	}
+	_, tables, err := b.Marshal(0x1000)
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	want := []byte{0xc0, 6, 0, 0, 1, 0, 'O', 'E', 'M', 0, 0, 127, 4, 1, 0, 0, 0}
+	if !reflect.DeepEqual(tables, want) {
+		t.Errorf("Marshal() = % x, want % x", tables, want)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	if _, err := NewBuilder(2, 8, 0); err == nil {
+		t.Errorf("NewBuilder(2, 8, 0) = nil, want error")
+	}
+	b, err := NewBuilder(3, 0, 0)
+	if err != nil {
+		t.Fatalf("NewBuilder() = %v", err)
+	}
+	for _, tt := range []struct {
+		s    Structure
+		want string
+	}{
+		{&EndOfTable{}, "added by Marshal"},
+		{&OEMStrings{Count: 1, Strings: []string{"a"}}, "invalid length"},
+		{&SystemInfo{Manufacturer: "a\x00b"}, "NUL"},
+		{&ChassisInfo{ContainedElementCount: 1}, "contained elements"},
+		{&Table{Header: Header{Type: 0xc0, Length: 5}, data: []byte{0xc0, 5, 0, 0}}, "does not match"},
+	} {
+		if _, err := b.Add(tt.s); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Add(%T) = %v, want error containing %q", tt.s, err, tt.want)
+		}
+	}
+}
+
+func TestTableEncoderStrings(t *testing.T) {
+	var e tableEncoder
+	e.data = make([]byte, 4)
+	for i := range 0xff {
+		if err := e.putString("s"); err != nil {
+			t.Fatalf("putString(%d) = %v", i, err)
+		}
+	}
+	if err := e.putString("s"); err == nil {
+		t.Errorf("putString() = nil, want error for the 256th string")
+	}
+	if err := e.putString(""); err != nil {
+		t.Errorf("putString(\"\") = %v", err)
+	}
+	if _, err := e.table(TableType(0xc0)); err == nil {
+		t.Errorf("table() = nil, want error for an oversized table")
+	}
+	if got := binary.LittleEndian.Uint16(e.data[4:]); got != 0x0201 {
+		t.Errorf("string indices = %#x, want 0x0201", got)
+	}
+}
@@ -156,6 +156,19 @@ func (i *Info) GetCacheInfo() ([]*CacheInfo, error) {
 	return res, nil
 }
 
+// GetPortConnectorInfo returns all the Port Connector Info (type 8) tables present.
+func (i *Info) GetPortConnectorInfo() ([]*PortConnectorInfo, error) {
+	var res []*PortConnectorInfo
+	for _, t := range i.GetTablesByType(TableTypePortConnectorInfo) {
+		pi, err := ParsePortConnectorInfo(t)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, pi)
+	}
+	return res, nil
+}
+
 // GetSystemSlots returns all the System Slots (type 9) tables present.
 func (i *Info) GetSystemSlots() ([]*SystemSlots, error) {
 	var res []*SystemSlots
@@ -169,6 +182,42 @@ func (i *Info) GetSystemSlots() ([]*SystemSlots, error) {
 	return res, nil
 }
 
+// GetOEMStrings returns all the OEM Strings (type 11) tables present.
+func (i *Info) GetOEMStrings() ([]*OEMStrings, error) {
+	var res []*OEMStrings
+	for _, t := range i.GetTablesByType(TableTypeOEMStrings) {
+		oem, err := ParseOEMStrings(t)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, oem)
+	}
+	return res, nil
+}
+
+// GetBIOSLanguageInfo returns the BIOS Language Info (type 13) table, if present.
+func (i *Info) GetBIOSLanguageInfo() (*BIOSLanguageInfo, error) {
+	bt := i.GetTablesByType(TableTypeBIOSLanguageInfo)
+	if len(bt) == 0 {
+		return nil, ErrTableNotFound
+	}
+	// There can only be one of these.
+	return ParseBIOSLanguageInfo(bt[0])
+}
+
+// GetPhysicalMemoryArrays returns all the Physical Memory Array (type 16) tables present.
+func (i *Info) GetPhysicalMemoryArrays() ([]*PhysicalMemoryArray, error) {
+	var res []*PhysicalMemoryArray
+	for _, t := range i.GetTablesByType(TableTypePhysicalMemoryArray) {
+		pa, err := ParsePhysicalMemoryArray(t)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, pa)
+	}
+	return res, nil
+}
+
 // GetMemoryDevices returns all the Memory Device (type 17) tables present.
 func (i *Info) GetMemoryDevices() ([]*MemoryDevice, error) {
 	var res []*MemoryDevice
@@ -182,6 +231,29 @@ func (i *Info) GetMemoryDevices() ([]*MemoryDevice, error) {
 	return res, nil
 }
 
+// GetMemoryArrayMappedAddresses returns all the Memory Array Mapped Address (type 19) tables present.
+func (i *Info) GetMemoryArrayMappedAddresses() ([]*MemoryArrayMappedAddress, error) {
+	var res []*MemoryArrayMappedAddress
+	for _, t := range i.GetTablesByType(TableTypeMemoryArrayMappedAddress) {
+		ma, err := ParseMemoryArrayMappedAddress(t)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ma)
+	}
+	return res, nil
+}
+
+// GetSystemBootInfo returns the System Boot Info (type 32) table, if present.
+func (i *Info) GetSystemBootInfo() (*SystemBootInfo, error) {
+	bt := i.GetTablesByType(TableTypeSystemBootInfo)
+	if len(bt) == 0 {
+		return nil, ErrTableNotFound
+	}
+	// There can only be one of these.
+	return ParseSystemBootInfo(bt[0])
+}
+
 // GetIPMIDeviceInfo returns all the IPMI Device Info (type 38) tables present.
 func (i *Info) GetIPMIDeviceInfo() ([]*IPMIDeviceInfo, error) {
 	var res []*IPMIDeviceInfo
@@ -195,6 +267,19 @@ func (i *Info) GetIPMIDeviceInfo() ([]*IPMIDeviceInfo, error) {
 	return res, nil
 }
 
+// GetOnboardDevices returns all the Onboard Device (type 41) tables present.
+func (i *Info) GetOnboardDevices() ([]*OnboardDevice, error) {
+	var res []*OnboardDevice
+	for _, t := range i.GetTablesByType(TableTypeOnboardDevice) {
+		d, err := ParseOnboardDevice(t)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
 // GetTPMDevices returns all the TPM Device (type 43) tables present.
 func (i *Info) GetTPMDevices() ([]*TPMDevice, error) {
 	var res []*TPMDevice
@@ -261,6 +261,36 @@ func RemoveSystemSlotInfo(slotDesignation string) OverrideOpt {
 	}
 }
 
+// ReplaceOEMStrings returns override options that replace the strings of all OEM Strings (type 11) tables.
+// Strings must not be empty, since an empty string would end the string set of the table.
+func ReplaceOEMStrings(oemStrings []string) OverrideOpt {
+	return func(tables []*Table) ([]*Table, error) {
+		if len(oemStrings) > 0xff {
+			return nil, fmt.Errorf("too many OEM strings: %d", len(oemStrings))
+		}
+		var result []*Table
+		for _, t := range tables {
+			if t.Type != TableTypeOEMStrings {
+				result = append(result, t)
+				continue
+			}
+			oem, err := ParseOEMStrings(t)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse OEM strings: %w", err)
+			}
+			oem.Length = 5
+			oem.Count = uint8(len(oemStrings))
+			oem.Strings = slices.Clone(oemStrings)
+			oemT, err := oem.toTable()
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert OEM strings to table: %w", err)
+			}
+			result = append(result, oemT)
+		}
+		return result, nil
+	}
+}
+
 // Modify modifies SMBIOS tables in system memory given override options
 func (m *Modifier) Modify(opts ...OverrideOpt) error {
 	entry, tables, err := m.Info.Marshal(opts...)
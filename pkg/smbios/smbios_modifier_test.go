@@ -637,3 +637,38 @@ func TestRemoveSystemSlotInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestReplaceOEMStrings(t *testing.T) {
+	oldTables := []*Table{
+		{
+			Header:  Header{Type: TableTypeOEMStrings, Length: 5, Handle: 0x1a},
+			data:    []byte{11, 5, 0x1a, 0, 2},
+			strings: []string{"Default string", "Another"},
+		},
+		{
+			Header:  Header{Type: TableTypeSystemBootInfo, Length: 11, Handle: 0x1b},
+			data:    []byte{32, 11, 0x1b, 0, 0, 0, 0, 0, 0, 0, 0},
+			strings: nil,
+		},
+	}
+	wantTables := []*Table{
+		{
+			Header:  Header{Type: TableTypeOEMStrings, Length: 5, Handle: 0x1a},
+			data:    []byte{11, 5, 0x1a, 0, 3},
+			strings: []string{"asset=1234", "rack=B7", "slot=12"},
+		},
+		oldTables[1],
+	}
+
+	newTables, err := ReplaceOEMStrings([]string{"asset=1234", "rack=B7", "slot=12"})(oldTables)
+	if err != nil {
+		t.Fatalf("ReplaceOEMStrings should pass but returned error: %v", err)
+	}
+	if !reflect.DeepEqual(newTables, wantTables) {
+		t.Errorf("ReplaceOEMStrings returned %+v, want %+v", newTables, wantTables)
+	}
+
+	if _, err := ReplaceOEMStrings([]string{"ok", ""})(oldTables); err == nil {
+		t.Errorf("ReplaceOEMStrings with an empty string should fail")
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// tableEncoder accumulates the formatted area and the string set of a table.
+// It is the inverse of parseStruct.
+type tableEncoder struct {
+	data    []byte
+	strings []string
+}
+
+// putString appends the index of s in the string set, adding s to the set.
+// Empty strings are encoded as index 0, i.e. "not specified".
+func (e *tableEncoder) putString(s string) error {
+	if s == "" {
+		e.data = append(e.data, 0)
+		return nil
+	}
+	if strings.IndexByte(s, 0) >= 0 {
+		return fmt.Errorf("string %q contains a NUL byte", s)
+	}
+	if len(e.strings) == 0xff {
+		return fmt.Errorf("too many strings")
+	}
+	e.strings = append(e.strings, s)
+	e.data = append(e.data, byte(len(e.strings)))
+	return nil
+}
+
+// putStruct appends the fields of the struct sp points to, honoring the same
+// tags as parseStruct.
+func (e *tableEncoder) putStruct(sp any) error {
+	sv, ok := sp.(reflect.Value)
+	if !ok {
+		sv = reflect.Indirect(reflect.ValueOf(sp))
+	}
+	svtn := sv.Type().Name()
+	for i := 0; i < sv.NumField(); i++ {
+		f := sv.Type().Field(i)
+		fv := sv.Field(i)
+		ignore := false
+		for tag := range strings.SplitSeq(f.Tag.Get(fieldTagKey), ",") {
+			tp := strings.Split(tag, "=")
+			switch tp[0] {
+			case "-":
+				ignore = true
+			case "skip":
+				numBytes, _ := strconv.Atoi(tp[1])
+				e.data = append(e.data, make([]byte, numBytes)...)
+			}
+		}
+		if ignore {
+			continue
+		}
+		switch fv.Kind() {
+		case reflect.Uint8:
+			e.data = append(e.data, uint8(fv.Uint()))
+		case reflect.Uint16:
+			e.data = binary.LittleEndian.AppendUint16(e.data, uint16(fv.Uint()))
+		case reflect.Uint32:
+			e.data = binary.LittleEndian.AppendUint32(e.data, uint32(fv.Uint()))
+		case reflect.Uint64:
+			e.data = binary.LittleEndian.AppendUint64(e.data, fv.Uint())
+		case reflect.String:
+			if err := e.putString(fv.String()); err != nil {
+				return fmt.Errorf("%s.%s: %w", svtn, f.Name, err)
+			}
+		case reflect.Array:
+			if fv.Type().Elem().Kind() != reflect.Uint8 {
+				return fmt.Errorf("%s.%s: unsupported type %s", svtn, f.Name, fv.Type())
+			}
+			for j := 0; j < fv.Len(); j++ {
+				e.data = append(e.data, uint8(fv.Index(j).Uint()))
+			}
+		case reflect.Struct:
+			if err := e.putStruct(fv); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%s.%s: unsupported type %s", svtn, f.Name, fv.Kind())
+		}
+	}
+	return nil
+}
+
+// table returns the encoded table with the given type. The length in the
+// header is set to the size of the formatted area.
+func (e *tableEncoder) table(tt TableType) (*Table, error) {
+	if len(e.data) < 4 {
+		return nil, fmt.Errorf("table too short: %d bytes", len(e.data))
+	}
+	if len(e.data) > 0xff {
+		return nil, fmt.Errorf("table too long: %d bytes", len(e.data))
+	}
+	e.data[0] = uint8(tt)
+	e.data[1] = uint8(len(e.data))
+	t := &Table{data: e.data, strings: e.strings}
+	if err := t.Header.Parse(t.data); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// marshalStruct encodes a structure whose fields are all handled by
+// tableEncoder.putStruct.
+func marshalStruct(tt TableType, sp any) (*Table, error) {
+	var e tableEncoder
+	if err := e.putStruct(sp); err != nil {
+		return nil, err
+	}
+	return e.table(tt)
+}
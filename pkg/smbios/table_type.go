@@ -14,19 +14,26 @@ type TableType uint8
 
 // Supported table types.
 const (
-	TableTypeBIOSInfo         TableType = 0
-	TableTypeSystemInfo       TableType = 1
-	TableTypeBaseboardInfo    TableType = 2
-	TableTypeChassisInfo      TableType = 3
-	TableTypeProcessorInfo    TableType = 4
-	TableTypeCacheInfo        TableType = 7
-	TableTypeSystemSlots      TableType = 9
-	TableTypeGroupAssociation TableType = 14
-	TableTypeMemoryDevice     TableType = 17
-	TableTypeIPMIDeviceInfo   TableType = 38
-	TableTypeTPMDevice        TableType = 43
-	TableTypeInactive         TableType = 126
-	TableTypeEndOfTable       TableType = 127
+	TableTypeBIOSInfo                 TableType = 0
+	TableTypeSystemInfo               TableType = 1
+	TableTypeBaseboardInfo            TableType = 2
+	TableTypeChassisInfo              TableType = 3
+	TableTypeProcessorInfo            TableType = 4
+	TableTypeCacheInfo                TableType = 7
+	TableTypePortConnectorInfo        TableType = 8
+	TableTypeSystemSlots              TableType = 9
+	TableTypeOEMStrings               TableType = 11
+	TableTypeBIOSLanguageInfo         TableType = 13
+	TableTypeGroupAssociation         TableType = 14
+	TableTypePhysicalMemoryArray      TableType = 16
+	TableTypeMemoryDevice             TableType = 17
+	TableTypeMemoryArrayMappedAddress TableType = 19
+	TableTypeSystemBootInfo           TableType = 32
+	TableTypeIPMIDeviceInfo           TableType = 38
+	TableTypeOnboardDevice            TableType = 41
+	TableTypeTPMDevice                TableType = 43
+	TableTypeInactive                 TableType = 126
+	TableTypeEndOfTable               TableType = 127
 )
 
 func (t TableType) String() string {
@@ -43,14 +50,28 @@ func (t TableType) String() string {
 		return "Processor Information"
 	case TableTypeCacheInfo:
 		return "Cache Information"
+	case TableTypePortConnectorInfo:
+		return "Port Connector Information"
+	case TableTypeOEMStrings:
+		return "OEM Strings"
+	case TableTypeBIOSLanguageInfo:
+		return "BIOS Language Information"
 	case TableTypeGroupAssociation:
 		return "Group Associations"
 	case TableTypeSystemSlots:
 		return "System Slots"
+	case TableTypePhysicalMemoryArray:
+		return "Physical Memory Array"
 	case TableTypeMemoryDevice:
 		return "Memory Device"
+	case TableTypeMemoryArrayMappedAddress:
+		return "Memory Array Mapped Address"
+	case TableTypeSystemBootInfo:
+		return "System Boot Information"
 	case TableTypeIPMIDeviceInfo:
 		return "IPMI Device Information"
+	case TableTypeOnboardDevice:
+		return "Onboard Device"
 	case TableTypeTPMDevice:
 		return "TPM Device"
 	case TableTypeInactive:
@@ -80,12 +101,26 @@ func ParseTypedTable(t *Table) (fmt.Stringer, error) {
 		return ParseProcessorInfo(t)
 	case TableTypeCacheInfo: // 7
 		return ParseCacheInfo(t)
+	case TableTypePortConnectorInfo: // 8
+		return ParsePortConnectorInfo(t)
 	case TableTypeSystemSlots: // 9
 		return ParseSystemSlots(t)
+	case TableTypeOEMStrings: // 11
+		return ParseOEMStrings(t)
+	case TableTypeBIOSLanguageInfo: // 13
+		return ParseBIOSLanguageInfo(t)
+	case TableTypePhysicalMemoryArray: // 16
+		return ParsePhysicalMemoryArray(t)
 	case TableTypeMemoryDevice: // 17
 		return NewMemoryDevice(t)
+	case TableTypeMemoryArrayMappedAddress: // 19
+		return ParseMemoryArrayMappedAddress(t)
+	case TableTypeSystemBootInfo: // 32
+		return ParseSystemBootInfo(t)
 	case TableTypeIPMIDeviceInfo: // 38
 		return ParseIPMIDeviceInfo(t)
+	case TableTypeOnboardDevice: // 41
+		return ParseOnboardDevice(t)
 	case TableTypeTPMDevice: // 43
 		return NewTPMDevice(t)
 	case TableTypeInactive: // 126
@@ -30,6 +30,19 @@ type BIOSInfo struct {
 	ExtendedROMSize                        uint16                  // 18h
 }
 
+// MarshalBinary encodes the BIOSInfo content into a binary
+func (bi *BIOSInfo) MarshalBinary() ([]byte, error) {
+	t, err := bi.toTable()
+	if err != nil {
+		return nil, err
+	}
+	return t.MarshalBinary()
+}
+
+func (bi *BIOSInfo) toTable() (*Table, error) {
+	return marshalStruct(TableTypeBIOSInfo, bi)
+}
+
 // ParseBIOSInfo parses a generic Table into BIOSInfo.
 func ParseBIOSInfo(t *Table) (*BIOSInfo, error) {
 	return parseBIOSInfo(parseStruct, t)
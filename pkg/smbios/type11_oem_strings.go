@@ -0,0 +1,84 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OEMStrings is defined in DSP0134 7.12.
+type OEMStrings struct {
+	Header
+	Count   uint8    // 04h
+	Strings []string `smbios:"-"`
+}
+
+// MarshalBinary encodes the OEMStrings content into a binary
+func (oem *OEMStrings) MarshalBinary() ([]byte, error) {
+	t, err := oem.toTable()
+	if err != nil {
+		return nil, err
+	}
+	return t.MarshalBinary()
+}
+
+func (oem *OEMStrings) toTable() (*Table, error) {
+	if int(oem.Count) != len(oem.Strings) {
+		return nil, fmt.Errorf("count %d does not match the number of OEM strings %d", oem.Count, len(oem.Strings))
+	}
+	if oem.Length != 5 {
+		return nil, fmt.Errorf("invalid length %d, want 5", oem.Length)
+	}
+	for i, s := range oem.Strings {
+		// An empty string would terminate the string set.
+		if s == "" {
+			return nil, fmt.Errorf("OEM string %d is empty", i+1)
+		}
+	}
+	h, err := oem.Header.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	d := append(h, oem.Count)
+	t := &Table{
+		Header:  oem.Header,
+		data:    d,
+		strings: oem.Strings,
+	}
+	return t, nil
+}
+
+// ParseOEMStrings parses a generic Table into OEMStrings.
+func ParseOEMStrings(t *Table) (*OEMStrings, error) {
+	if t.Type != TableTypeOEMStrings {
+		return nil, fmt.Errorf("invalid table type %d", t.Type)
+	}
+	if t.Len() < 0x5 {
+		return nil, errors.New("required fields missing")
+	}
+	oem := &OEMStrings{Header: t.Header}
+	if _, err := parseStruct(t, 0 /* off */, false /* complete */, oem); err != nil {
+		return nil, err
+	}
+	oem.Strings = slices.Clone(t.strings[:min(int(oem.Count), len(t.strings))])
+	return oem, nil
+}
+
+func (oem *OEMStrings) String() string {
+	lines := []string{
+		oem.Header.String(),
+	}
+	for i := range int(oem.Count) {
+		s := "<BAD INDEX>"
+		if i < len(oem.Strings) {
+			s = oem.Strings[i]
+		}
+		lines = append(lines, fmt.Sprintf("String %d: %s", i+1, s))
+	}
+	return strings.Join(lines, "\n\t")
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOEMStrings(t *testing.T) {
+	table := &Table{
+		Header:  Header{Type: TableTypeOEMStrings, Length: 5, Handle: 0x2b},
+		data:    []byte{11, 5, 0x2b, 0, 3},
+		strings: []string{"Intel SandyBridge/Patsburg/Romley", "Supermicro motherboard-X9 Series "},
+	}
+	oem, err := ParseOEMStrings(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"Intel SandyBridge/Patsburg/Romley", "Supermicro motherboard-X9 Series "}, oem.Strings); diff != "" {
+		t.Errorf("Strings: (-want +got)\n%s", diff)
+	}
+	// The third string is missing.
+	want := `Handle 0x002B, DMI type 11, 5 bytes
+OEM Strings
+	String 1: Intel SandyBridge/Patsburg/Romley
+	String 2: Supermicro motherboard-X9 Series 
+	String 3: <BAD INDEX>`
+	if got := oem.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// Marshalling fixes nothing up: count and strings must agree.
+	if _, err := oem.MarshalBinary(); err == nil {
+		t.Errorf("MarshalBinary() with a count of 3 and 2 strings should fail")
+	}
+	oem.Count = 2
+	b, err := oem.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantB := joinBytesT(t, 11, 5, 0x2b, 0, 2,
+		"Intel SandyBridge/Patsburg/Romley", 0,
+		"Supermicro motherboard-X9 Series ", 0,
+		0)
+	if diff := cmp.Diff(wantB, b); diff != "" {
+		t.Errorf("MarshalBinary(): (-want +got)\n%s", diff)
+	}
+}
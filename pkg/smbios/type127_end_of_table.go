@@ -13,6 +13,19 @@ type EndOfTable struct {
 	Table
 }
 
+// MarshalBinary encodes the EndOfTable content into a binary
+func (eot *EndOfTable) MarshalBinary() ([]byte, error) {
+	t, err := eot.toTable()
+	if err != nil {
+		return nil, err
+	}
+	return t.MarshalBinary()
+}
+
+func (eot *EndOfTable) toTable() (*Table, error) {
+	return marshalStruct(TableTypeEndOfTable, eot)
+}
+
 // NewEndOfTable parses a generic Table into EndOfTable.
 func NewEndOfTable(t *Table) (*EndOfTable, error) {
 	if t.Type != TableTypeEndOfTable {
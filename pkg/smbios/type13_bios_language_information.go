@@ -0,0 +1,66 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// BIOSLanguageInfo is defined in DSP0134 7.14.
+type BIOSLanguageInfo struct {
+	Header
+	InstallableLanguages uint8                 // 04h
+	Flags                BIOSLanguageInfoFlags // 05h
+	CurrentLanguage      string                `smbios:"skip=15"` // 15h
+	Languages            []string              `smbios:"-"`
+}
+
+// BIOSLanguageInfoFlags is defined in DSP0134 7.14.
+type BIOSLanguageInfoFlags uint8
+
+// BIOSLanguageInfoFlags fields are defined in DSP0134 7.14.
+const (
+	BIOSLanguageInfoFlagsAbbreviated BIOSLanguageInfoFlags = 1 << 0 // Current Language strings use the abbreviated format
+)
+
+// ParseBIOSLanguageInfo parses a generic Table into BIOSLanguageInfo.
+func ParseBIOSLanguageInfo(t *Table) (*BIOSLanguageInfo, error) {
+	if t.Type != TableTypeBIOSLanguageInfo {
+		return nil, fmt.Errorf("invalid table type %d", t.Type)
+	}
+	if t.Len() < 0x16 {
+		return nil, errors.New("required fields missing")
+	}
+	li := &BIOSLanguageInfo{Header: t.Header}
+	if _, err := parseStruct(t, 0 /* off */, false /* complete */, li); err != nil {
+		return nil, err
+	}
+	li.Languages = slices.Clone(t.strings[:min(int(li.InstallableLanguages), len(t.strings))])
+	return li, nil
+}
+
+func (li *BIOSLanguageInfo) String() string {
+	format := "Long"
+	if li.Flags&BIOSLanguageInfoFlagsAbbreviated != 0 {
+		format = "Abbreviated"
+	}
+	lines := []string{
+		li.Header.String(),
+		fmt.Sprintf("Language Description Format: %s", format),
+		fmt.Sprintf("Installable Languages: %d", li.InstallableLanguages),
+	}
+	for i := range int(li.InstallableLanguages) {
+		s := "<BAD INDEX>"
+		if i < len(li.Languages) {
+			s = li.Languages[i]
+		}
+		lines = append(lines, "\t"+s)
+	}
+	lines = append(lines, fmt.Sprintf("Currently Installed Language: %s", li.CurrentLanguage))
+	return strings.Join(lines, "\n\t")
+}
@@ -0,0 +1,48 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import "testing"
+
+func TestBIOSLanguageInfo(t *testing.T) {
+	data := make([]byte, 0x16)
+	copy(data, []byte{13, 0x16, 0x28, 0, 3, 0})
+	data[0x15] = 2
+	table := &Table{
+		Header:  Header{Type: TableTypeBIOSLanguageInfo, Length: 0x16, Handle: 0x28},
+		data:    data,
+		strings: []string{"en|US|iso8859-1", "de|DE|iso8859-1"},
+	}
+	li, err := ParseBIOSLanguageInfo(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if li.CurrentLanguage != "de|DE|iso8859-1" || len(li.Languages) != 2 {
+		t.Errorf("ParseBIOSLanguageInfo() = %+v", li)
+	}
+	want := `Handle 0x0028, DMI type 13, 22 bytes
+BIOS Language Information
+	Language Description Format: Long
+	Installable Languages: 3
+		en|US|iso8859-1
+		de|DE|iso8859-1
+		<BAD INDEX>
+	Currently Installed Language: de|DE|iso8859-1`
+	if got := li.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	li.Flags = BIOSLanguageInfoFlagsAbbreviated
+	li.InstallableLanguages = 1
+	want = `Handle 0x0028, DMI type 13, 22 bytes
+BIOS Language Information
+	Language Description Format: Abbreviated
+	Installable Languages: 1
+		en|US|iso8859-1
+	Currently Installed Language: de|DE|iso8859-1`
+	if got := li.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Much of this is auto-generated. If adding a new type, see README for instructions.
+
+// PhysicalMemoryArray is defined in DSP0134 7.17.
+type PhysicalMemoryArray struct {
+	Header
+	Location                PhysicalMemoryArrayLocation // 04h
+	Use                     PhysicalMemoryArrayUse      // 05h
+	ErrorCorrection         PhysicalMemoryArrayECC      // 06h
+	MaximumCapacity         uint32                      // 07h
+	ErrorInfoHandle         uint16                      // 0Bh
+	NumberOfMemoryDevices   uint16                      // 0Dh
+	ExtendedMaximumCapacity uint64                      // 0Fh
+}
+
+// MarshalBinary encodes the PhysicalMemoryArray content into a binary
+func (pa *PhysicalMemoryArray) MarshalBinary() ([]byte, error) {
+	t, err := pa.toTable()
+	if err != nil {
+		return nil, err
+	}
+	return t.MarshalBinary()
+}
+
+func (pa *PhysicalMemoryArray) toTable() (*Table, error) {
+	return marshalStruct(TableTypePhysicalMemoryArray, pa)
+}
+
+// ParsePhysicalMemoryArray parses a generic Table into PhysicalMemoryArray.
+func ParsePhysicalMemoryArray(t *Table) (*PhysicalMemoryArray, error) {
+	if t.Type != TableTypePhysicalMemoryArray {
+		return nil, fmt.Errorf("invalid table type %d", t.Type)
+	}
+	if t.Len() < 0xf {
+		return nil, errors.New("required fields missing")
+	}
+	pa := &PhysicalMemoryArray{Header: t.Header}
+	if _, err := parseStruct(t, 0 /* off */, false /* complete */, pa); err != nil {
+		return nil, err
+	}
+	return pa, nil
+}
+
+// GetMaximumCapacityBytes returns the maximum memory capacity of the array,
+// in bytes, or 0 if unknown.
+func (pa *PhysicalMemoryArray) GetMaximumCapacityBytes() uint64 {
+	if pa.MaximumCapacity == 0x80000000 {
+		if pa.Length < 0x17 {
+			return 0
+		}
+		return pa.ExtendedMaximumCapacity
+	}
+	return uint64(pa.MaximumCapacity) * 1024
+}
+
+func (pa *PhysicalMemoryArray) String() string {
+	capStr := "Unknown"
+	if c := pa.GetMaximumCapacityBytes(); c != 0 {
+		capStr = kmgt(c)
+	}
+
+	ehStr := ""
+	switch pa.ErrorInfoHandle {
+	case 0xffff:
+		ehStr = "No Error"
+	case 0xfffe:
+		ehStr = "Not Provided"
+	default:
+		ehStr = fmt.Sprintf("0x%04X", pa.ErrorInfoHandle)
+	}
+
+	lines := []string{
+		pa.Header.String(),
+		fmt.Sprintf("Location: %s", pa.Location),
+		fmt.Sprintf("Use: %s", pa.Use),
+		fmt.Sprintf("Error Correction Type: %s", pa.ErrorCorrection),
+		fmt.Sprintf("Maximum Capacity: %s", capStr),
+		fmt.Sprintf("Error Information Handle: %s", ehStr),
+		fmt.Sprintf("Number Of Devices: %d", pa.NumberOfMemoryDevices),
+	}
+	return strings.Join(lines, "\n\t")
+}
+
+// PhysicalMemoryArrayLocation is defined in DSP0134 7.17.1.
+type PhysicalMemoryArrayLocation uint8
+
+// PhysicalMemoryArrayLocation values are defined in DSP0134 7.17.1.
+const (
+	PhysicalMemoryArrayLocationOther                    PhysicalMemoryArrayLocation = 0x01 // Other
+	PhysicalMemoryArrayLocationUnknown                  PhysicalMemoryArrayLocation = 0x02 // Unknown
+	PhysicalMemoryArrayLocationSystemBoardOrMotherboard PhysicalMemoryArrayLocation = 0x03 // System board or motherboard
+	PhysicalMemoryArrayLocationISAAddonCard             PhysicalMemoryArrayLocation = 0x04 // ISA add-on card
+	PhysicalMemoryArrayLocationEISAAddonCard            PhysicalMemoryArrayLocation = 0x05 // EISA add-on card
+	PhysicalMemoryArrayLocationPCIAddonCard             PhysicalMemoryArrayLocation = 0x06 // PCI add-on card
+	PhysicalMemoryArrayLocationMCAAddonCard             PhysicalMemoryArrayLocation = 0x07 // MCA add-on card
+	PhysicalMemoryArrayLocationPCMCIAAddonCard          PhysicalMemoryArrayLocation = 0x08 // PCMCIA add-on card
+	PhysicalMemoryArrayLocationProprietaryAddonCard     PhysicalMemoryArrayLocation = 0x09 // Proprietary add-on card
+	PhysicalMemoryArrayLocationNuBus                    PhysicalMemoryArrayLocation = 0x0a // NuBus
+	PhysicalMemoryArrayLocationPC98C20AddonCard         PhysicalMemoryArrayLocation = 0xa0 // PC-98/C20 add-on card
+	PhysicalMemoryArrayLocationPC98C24AddonCard         PhysicalMemoryArrayLocation = 0xa1 // PC-98/C24 add-on card
+	PhysicalMemoryArrayLocationPC98EAddonCard           PhysicalMemoryArrayLocation = 0xa2 // PC-98/E add-on card
+	PhysicalMemoryArrayLocationPC98LocalBusAddonCard    PhysicalMemoryArrayLocation = 0xa3 // PC-98/Local bus add-on card
+	PhysicalMemoryArrayLocationCXLAddonCard             PhysicalMemoryArrayLocation = 0xa4 // CXL add-on card
+)
+
+func (v PhysicalMemoryArrayLocation) String() string {
+	names := map[PhysicalMemoryArrayLocation]string{
+		PhysicalMemoryArrayLocationOther:                    "Other",
+		PhysicalMemoryArrayLocationUnknown:                  "Unknown",
+		PhysicalMemoryArrayLocationSystemBoardOrMotherboard: "System Board Or Motherboard",
+		PhysicalMemoryArrayLocationISAAddonCard:             "ISA Add-on Card",
+		PhysicalMemoryArrayLocationEISAAddonCard:            "EISA Add-on Card",
+		PhysicalMemoryArrayLocationPCIAddonCard:             "PCI Add-on Card",
+		PhysicalMemoryArrayLocationMCAAddonCard:             "MCA Add-on Card",
+		PhysicalMemoryArrayLocationPCMCIAAddonCard:          "PCMCIA Add-on Card",
+		PhysicalMemoryArrayLocationProprietaryAddonCard:     "Proprietary Add-on Card",
+		PhysicalMemoryArrayLocationNuBus:                    "NuBus",
+		PhysicalMemoryArrayLocationPC98C20AddonCard:         "PC-98/C20 Add-on Card",
+		PhysicalMemoryArrayLocationPC98C24AddonCard:         "PC-98/C24 Add-on Card",
+		PhysicalMemoryArrayLocationPC98EAddonCard:           "PC-98/E Add-on Card",
+		PhysicalMemoryArrayLocationPC98LocalBusAddonCard:    "PC-98/Local Bus Add-on Card",
+		PhysicalMemoryArrayLocationCXLAddonCard:             "CXL Add-on Card",
+	}
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("%#x", uint8(v))
+}
+
+// PhysicalMemoryArrayUse is defined in DSP0134 7.17.2.
+type PhysicalMemoryArrayUse uint8
+
+// PhysicalMemoryArrayUse values are defined in DSP0134 7.17.2.
+const (
+	PhysicalMemoryArrayUseOther          PhysicalMemoryArrayUse = 0x01 // Other
+	PhysicalMemoryArrayUseUnknown        PhysicalMemoryArrayUse = 0x02 // Unknown
+	PhysicalMemoryArrayUseSystemMemory   PhysicalMemoryArrayUse = 0x03 // System memory
+	PhysicalMemoryArrayUseVideoMemory    PhysicalMemoryArrayUse = 0x04 // Video memory
+	PhysicalMemoryArrayUseFlashMemory    PhysicalMemoryArrayUse = 0x05 // Flash memory
+	PhysicalMemoryArrayUseNonvolatileRAM PhysicalMemoryArrayUse = 0x06 // Non-volatile RAM
+	PhysicalMemoryArrayUseCacheMemory    PhysicalMemoryArrayUse = 0x07 // Cache memory
+)
+
+func (v PhysicalMemoryArrayUse) String() string {
+	names := map[PhysicalMemoryArrayUse]string{
+		PhysicalMemoryArrayUseOther:          "Other",
+		PhysicalMemoryArrayUseUnknown:        "Unknown",
+		PhysicalMemoryArrayUseSystemMemory:   "System Memory",
+		PhysicalMemoryArrayUseVideoMemory:    "Video Memory",
+		PhysicalMemoryArrayUseFlashMemory:    "Flash Memory",
+		PhysicalMemoryArrayUseNonvolatileRAM: "Non-volatile RAM",
+		PhysicalMemoryArrayUseCacheMemory:    "Cache Memory",
+	}
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("%#x", uint8(v))
+}
+
+// PhysicalMemoryArrayECC is defined in DSP0134 7.17.3.
+type PhysicalMemoryArrayECC uint8
+
+// PhysicalMemoryArrayECC values are defined in DSP0134 7.17.3.
+const (
+	PhysicalMemoryArrayECCOther        PhysicalMemoryArrayECC = 0x01 // Other
+	PhysicalMemoryArrayECCUnknown      PhysicalMemoryArrayECC = 0x02 // Unknown
+	PhysicalMemoryArrayECCNone         PhysicalMemoryArrayECC = 0x03 // None
+	PhysicalMemoryArrayECCParity       PhysicalMemoryArrayECC = 0x04 // Parity
+	PhysicalMemoryArrayECCSinglebitECC PhysicalMemoryArrayECC = 0x05 // Single-bit ECC
+	PhysicalMemoryArrayECCMultibitECC  PhysicalMemoryArrayECC = 0x06 // Multi-bit ECC
+	PhysicalMemoryArrayECCCRC          PhysicalMemoryArrayECC = 0x07 // CRC
+)
+
+func (v PhysicalMemoryArrayECC) String() string {
+	names := map[PhysicalMemoryArrayECC]string{
+		PhysicalMemoryArrayECCOther:        "Other",
+		PhysicalMemoryArrayECCUnknown:      "Unknown",
+		PhysicalMemoryArrayECCNone:         "None",
+		PhysicalMemoryArrayECCParity:       "Parity",
+		PhysicalMemoryArrayECCSinglebitECC: "Single-bit ECC",
+		PhysicalMemoryArrayECCMultibitECC:  "Multi-bit ECC",
+		PhysicalMemoryArrayECCCRC:          "CRC",
+	}
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("%#x", uint8(v))
+}
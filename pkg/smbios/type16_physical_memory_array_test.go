@@ -0,0 +1,69 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import "testing"
+
+func TestPhysicalMemoryArray(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{
+			name: "2.1",
+			data: []byte{16, 15, 0x24, 0, 3, 3, 3, 0, 0, 0, 1, 0xfe, 0xff, 4, 0},
+			want: `Handle 0x0024, DMI type 16, 15 bytes
+Physical Memory Array
+	Location: System Board Or Motherboard
+	Use: System Memory
+	Error Correction Type: None
+	Maximum Capacity: 16 GB
+	Error Information Handle: Not Provided
+	Number Of Devices: 4`,
+		},
+		{
+			name: "extended capacity",
+			data: []byte{
+				16, 23, 0x09, 0, 3, 3, 6, 0, 0, 0, 0x80, 0x08, 0, 8, 0,
+				0, 0, 0, 0, 0, 0x04, 0, 0,
+			},
+			want: `Handle 0x0009, DMI type 16, 23 bytes
+Physical Memory Array
+	Location: System Board Or Motherboard
+	Use: System Memory
+	Error Correction Type: Multi-bit ECC
+	Maximum Capacity: 4 TB
+	Error Information Handle: 0x0008
+	Number Of Devices: 8`,
+		},
+		{
+			name: "unknown capacity",
+			data: []byte{16, 15, 0x09, 0, 1, 2, 0x10, 0, 0, 0, 0x80, 0xff, 0xff, 1, 0},
+			want: `Handle 0x0009, DMI type 16, 15 bytes
+Physical Memory Array
+	Location: Other
+	Use: Unknown
+	Error Correction Type: 0x10
+	Maximum Capacity: Unknown
+	Error Information Handle: No Error
+	Number Of Devices: 1`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &Table{
+				Header: Header{Type: TableTypePhysicalMemoryArray, Length: tt.data[1], Handle: uint16(tt.data[2])},
+				data:   tt.data,
+			}
+			pa, err := ParsePhysicalMemoryArray(table)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pa.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -72,6 +72,19 @@ var MemoryDeviceManufacturer = map[string]uint16{
 	"Viking":   0x4001,
 }
 
+// MarshalBinary encodes the MemoryDevice content into a binary
+func (md *MemoryDevice) MarshalBinary() ([]byte, error) {
+	t, err := md.toTable()
+	if err != nil {
+		return nil, err
+	}
+	return t.MarshalBinary()
+}
+
+func (md *MemoryDevice) toTable() (*Table, error) {
+	return marshalStruct(TableTypeMemoryDevice, md)
+}
+
 // NewMemoryDevice parses a generic Table into MemoryDevice.
 func NewMemoryDevice(t *Table) (*MemoryDevice, error) {
 	if t.Type != TableTypeMemoryDevice {
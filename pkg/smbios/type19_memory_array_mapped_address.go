@@ -0,0 +1,79 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MemoryArrayMappedAddress is defined in DSP0134 7.20.
+type MemoryArrayMappedAddress struct {
+	Header
+	StartingAddress         uint32 // 04h
+	EndingAddress           uint32 // 08h
+	MemoryArrayHandle       uint16 // 0Ch
+	PartitionWidth          uint8  // 0Eh
+	ExtendedStartingAddress uint64 // 0Fh
+	ExtendedEndingAddress   uint64 // 17h
+}
+
+// ParseMemoryArrayMappedAddress parses a generic Table into MemoryArrayMappedAddress.
+func ParseMemoryArrayMappedAddress(t *Table) (*MemoryArrayMappedAddress, error) {
+	if t.Type != TableTypeMemoryArrayMappedAddress {
+		return nil, fmt.Errorf("invalid table type %d", t.Type)
+	}
+	if t.Len() < 0xf {
+		return nil, errors.New("required fields missing")
+	}
+	ma := &MemoryArrayMappedAddress{Header: t.Header}
+	if _, err := parseStruct(t, 0 /* off */, false /* complete */, ma); err != nil {
+		return nil, err
+	}
+	return ma, nil
+}
+
+// extended returns whether the address range is in the extended fields.
+func (ma *MemoryArrayMappedAddress) extended() bool {
+	return ma.Length >= 0x1f && ma.StartingAddress == 0xffffffff
+}
+
+// GetStartBytes returns the physical address of the first byte of the range.
+func (ma *MemoryArrayMappedAddress) GetStartBytes() uint64 {
+	if ma.extended() {
+		return ma.ExtendedStartingAddress
+	}
+	return uint64(ma.StartingAddress) * 1024
+}
+
+// GetEndBytes returns the physical address of the last byte of the range.
+func (ma *MemoryArrayMappedAddress) GetEndBytes() uint64 {
+	if ma.extended() {
+		return ma.ExtendedEndingAddress
+	}
+	return uint64(ma.EndingAddress)*1024 + 1023
+}
+
+func (ma *MemoryArrayMappedAddress) String() string {
+	start, end := ma.GetStartBytes(), ma.GetEndBytes()
+	sizeStr := "Invalid"
+	if end > start {
+		sizeStr = kmgt(end - start + 1)
+	}
+	addrFmt := "0x%011X"
+	if ma.extended() {
+		addrFmt = "0x%016X"
+	}
+	lines := []string{
+		ma.Header.String(),
+		fmt.Sprintf("Starting Address: "+addrFmt, start),
+		fmt.Sprintf("Ending Address: "+addrFmt, end),
+		fmt.Sprintf("Range Size: %s", sizeStr),
+		fmt.Sprintf("Physical Array Handle: 0x%04X", ma.MemoryArrayHandle),
+		fmt.Sprintf("Partition Width: %d", ma.PartitionWidth),
+	}
+	return strings.Join(lines, "\n\t")
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import "testing"
+
+func TestMemoryArrayMappedAddress(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		data       []byte
+		start, end uint64
+		want       string
+	}{
+		{
+			name:  "2.1",
+			data:  []byte{19, 15, 0x29, 0, 0, 0, 0, 0, 0xff, 0xff, 0x1f, 0, 0x24, 0, 1},
+			start: 0,
+			end:   2<<30 - 1,
+			want: `Handle 0x0029, DMI type 19, 15 bytes
+Memory Array Mapped Address
+	Starting Address: 0x00000000000
+	Ending Address: 0x0007FFFFFFF
+	Range Size: 2 GB
+	Physical Array Handle: 0x0024
+	Partition Width: 1`,
+		},
+		{
+			name: "2.7",
+			data: []byte{
+				19, 31, 0x0b, 0, 0, 0, 0x40, 0, 0xff, 0xff, 0x1f, 0x08, 0x09, 0, 8,
+				0, 0, 0, 0, 0, 0, 0, 0,
+				0, 0, 0, 0, 0, 0, 0, 0,
+			},
+			start: 4 << 30,
+			end:   130<<30 - 1,
+			want: `Handle 0x000B, DMI type 19, 31 bytes
+Memory Array Mapped Address
+	Starting Address: 0x00100000000
+	Ending Address: 0x0207FFFFFFF
+	Range Size: 126 GB
+	Physical Array Handle: 0x0009
+	Partition Width: 8`,
+		},
+		{
+			name: "extended",
+			data: []byte{
+				19, 31, 0x0b, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x09, 0, 8,
+				0, 0, 0, 0, 0, 0x01, 0, 0,
+				0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0, 0,
+			},
+			start: 1 << 40,
+			end:   2<<40 - 1,
+			want: `Handle 0x000B, DMI type 19, 31 bytes
+Memory Array Mapped Address
+	Starting Address: 0x0000010000000000
+	Ending Address: 0x000001FFFFFFFFFF
+	Range Size: 1 TB
+	Physical Array Handle: 0x0009
+	Partition Width: 8`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &Table{
+				Header: Header{Type: TableTypeMemoryArrayMappedAddress, Length: tt.data[1], Handle: uint16(tt.data[2])},
+				data:   tt.data,
+			}
+			ma, err := ParseMemoryArrayMappedAddress(table)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ma.GetStartBytes() != tt.start || ma.GetEndBytes() != tt.end {
+				t.Errorf("range = %#x-%#x, want %#x-%#x", ma.GetStartBytes(), ma.GetEndBytes(), tt.start, tt.end)
+			}
+			if got := ma.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -55,68 +55,7 @@ func joinBytes(args ...any) ([]byte, error) {
 }
 
 func (si *SystemInfo) toTable() (*Table, error) {
-	h, err := si.Header.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
-
-	var d []byte
-	var tableStr []string
-	id := byte(1)
-
-	d = append(d, h...)
-	if si.Manufacturer != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, si.Manufacturer)
-	} else {
-		d = append(d, 0)
-	}
-	if si.ProductName != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, si.ProductName)
-	} else {
-		d = append(d, 0)
-	}
-	if si.Version != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, si.Version)
-	} else {
-		d = append(d, 0)
-	}
-	if si.SerialNumber != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, si.SerialNumber)
-	} else {
-		d = append(d, 0)
-	}
-
-	d = append(d, si.UUID[:]...)
-	d = append(d, byte(si.WakeupType))
-
-	if si.SKUNumber != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, si.SKUNumber)
-	} else {
-		d = append(d, 0)
-	}
-	if si.Family != "" {
-		d = append(d, id)
-		tableStr = append(tableStr, si.Family)
-	} else {
-		d = append(d, 0)
-	}
-
-	t := &Table{
-		Header:  si.Header,
-		data:    d,
-		strings: tableStr,
-	}
-	return t, nil
+	return marshalStruct(TableTypeSystemInfo, si)
 }
 
 // ParseSystemInfo parses a generic Table into SystemInfo.
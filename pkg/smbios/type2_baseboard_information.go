@@ -39,84 +39,17 @@ func (bi *BaseboardInfo) MarshalBinary() ([]byte, error) {
 }
 
 func (bi *BaseboardInfo) toTable() (*Table, error) {
-	h, err := bi.Header.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
-
-	var d []byte
-	var tableStr []string
-	id := byte(1)
-
-	d = append(d, h...)
-	if bi.Manufacturer != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, bi.Manufacturer)
-	} else {
-		d = append(d, 0)
-	}
-	if bi.Product != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, bi.Product)
-	} else {
-		d = append(d, 0)
-	}
-	if bi.Version != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, bi.Version)
-	} else {
-		d = append(d, 0)
-	}
-	if bi.SerialNumber != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, bi.SerialNumber)
-	} else {
-		d = append(d, 0)
-	}
-	if bi.AssetTag != "" {
-		d = append(d, id)
-		id++
-		tableStr = append(tableStr, bi.AssetTag)
-	} else {
-		d = append(d, 0)
-	}
-
-	d = append(d, byte(bi.BoardFeatures))
-
-	if bi.LocationInChassis != "" {
-		d = append(d, id)
-		tableStr = append(tableStr, bi.LocationInChassis)
-	} else {
-		d = append(d, 0)
-	}
-
-	chb := make([]byte, 2)
-	binary.LittleEndian.PutUint16(chb, bi.ChassisHandle)
-	d = append(d, chb...)
-
-	d = append(d, byte(bi.BoardType))
-	d = append(d, bi.NumberOfContainedObjectHandles)
-
 	if bi.NumberOfContainedObjectHandles != uint8(len(bi.ContainedObjectHandles)) {
 		return nil, fmt.Errorf("invalid number of contained object handles, NumberOfContainedObjectHandles: %d, len of ContainedObjectHandles: %d", bi.NumberOfContainedObjectHandles, len(bi.ContainedObjectHandles))
 	}
-
-	for _, coh := range bi.ContainedObjectHandles {
-		cohb := make([]byte, 2)
-		binary.LittleEndian.PutUint16(cohb, coh)
-		d = append(d, cohb...)
+	var e tableEncoder
+	if err := e.putStruct(bi); err != nil {
+		return nil, err
 	}
-
-	t := &Table{
-		Header:  bi.Header,
-		data:    d,
-		strings: tableStr,
+	for _, coh := range bi.ContainedObjectHandles {
+		e.data = binary.LittleEndian.AppendUint16(e.data, coh)
 	}
-	return t, nil
+	return e.table(TableTypeBaseboardInfo)
 }
 
 // ParseBaseboardInfo parses a generic Table into BaseboardInfo.
@@ -0,0 +1,47 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSystemBootInfo(t *testing.T) {
+	table := &Table{
+		Header: Header{Type: TableTypeSystemBootInfo, Length: 20, Handle: 0x0c},
+		data:   []byte{32, 20, 0x0c, 0, 0, 0, 0, 0, 0, 0, 0x80, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+	}
+	bi, err := ParseSystemBootInfo(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(bi.BootStatusData, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Errorf("BootStatusData = %v", bi.BootStatusData)
+	}
+	want := `Handle 0x000C, DMI type 32, 20 bytes
+System Boot Information
+	Status: OEM-specific`
+	if got := bi.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSystemBootStatusString(t *testing.T) {
+	for _, tt := range []struct {
+		v    SystemBootStatus
+		want string
+	}{
+		{SystemBootStatusNoErrors, "No errors detected"},
+		{SystemBootStatusWatchdogTimerExpired, "System watchdog timer expired"},
+		{0x09, outOfSpec},
+		{0xbf, "OEM-specific"},
+		{0xc0, "Product-specific"},
+	} {
+		if got := tt.v.String(); got != tt.want {
+			t.Errorf("SystemBootStatus(%#x).String() = %q, want %q", uint8(tt.v), got, tt.want)
+		}
+	}
+}
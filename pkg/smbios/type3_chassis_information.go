@@ -42,6 +42,35 @@ type ChassisContainedElement struct {
 	Max  uint8              // 02h
 }
 
+// MarshalBinary encodes the ChassisInfo content into a binary
+func (si *ChassisInfo) MarshalBinary() ([]byte, error) {
+	t, err := si.toTable()
+	if err != nil {
+		return nil, err
+	}
+	return t.MarshalBinary()
+}
+
+func (si *ChassisInfo) toTable() (*Table, error) {
+	if int(si.ContainedElementCount) != len(si.ContainedElements) {
+		return nil, fmt.Errorf("invalid number of contained elements, ContainedElementCount: %d, len of ContainedElements: %d", si.ContainedElementCount, len(si.ContainedElements))
+	}
+	if si.ContainedElementCount > 0 && si.ContainedElementsRecordLength != 3 {
+		return nil, fmt.Errorf("invalid contained element record length %d, want 3", si.ContainedElementsRecordLength)
+	}
+	var e tableEncoder
+	if err := e.putStruct(si); err != nil {
+		return nil, err
+	}
+	for _, ce := range si.ContainedElements {
+		e.data = append(e.data, byte(ce.Type), ce.Min, ce.Max)
+	}
+	if err := e.putString(si.SKUNumber); err != nil {
+		return nil, fmt.Errorf("ChassisInfo.SKUNumber: %w", err)
+	}
+	return e.table(TableTypeChassisInfo)
+}
+
 // ParseChassisInfo parses a generic Table into ChassisInfo.
 func ParseChassisInfo(t *Table) (*ChassisInfo, error) {
 	return parseChassisInfo(parseStruct, t)
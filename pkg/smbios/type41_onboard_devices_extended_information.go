@@ -0,0 +1,113 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// OnboardDevice is defined in DSP0134 7.42.
+type OnboardDevice struct {
+	Header
+	ReferenceDesignation string            // 04h
+	DeviceType           OnboardDeviceType // 05h
+	DeviceTypeInstance   uint8             // 06h
+	SegmentGroupNumber   uint16            // 07h
+	BusNumber            uint8             // 09h
+	DeviceFunctionNumber uint8             // 0Ah
+}
+
+// ParseOnboardDevice parses a generic Table into OnboardDevice.
+func ParseOnboardDevice(t *Table) (*OnboardDevice, error) {
+	if t.Type != TableTypeOnboardDevice {
+		return nil, fmt.Errorf("invalid table type %d", t.Type)
+	}
+	if t.Len() < 0xb {
+		return nil, errors.New("required fields missing")
+	}
+	od := &OnboardDevice{Header: t.Header}
+	if _, err := parseStruct(t, 0 /* off */, false /* complete */, od); err != nil {
+		return nil, err
+	}
+	return od, nil
+}
+
+func (od *OnboardDevice) String() string {
+	status := "Disabled"
+	if od.DeviceType.Enabled() {
+		status = "Enabled"
+	}
+	lines := []string{
+		od.Header.String(),
+		fmt.Sprintf("Reference Designation: %s", od.ReferenceDesignation),
+		fmt.Sprintf("Type: %s", od.DeviceType),
+		fmt.Sprintf("Status: %s", status),
+		fmt.Sprintf("Type Instance: %d", od.DeviceTypeInstance),
+	}
+	if od.SegmentGroupNumber != 0xffff || od.BusNumber != 0xff || od.DeviceFunctionNumber != 0xff {
+		lines = append(lines, fmt.Sprintf("Bus Address: %04x:%02x:%02x.%x",
+			od.SegmentGroupNumber, od.BusNumber, od.DeviceFunctionNumber>>3, od.DeviceFunctionNumber&7))
+	}
+	return strings.Join(lines, "\n\t")
+}
+
+// OnboardDeviceType is defined in DSP0134 7.42.2. Bit 7 is the device
+// status, bits 6:0 the type.
+type OnboardDeviceType uint8
+
+// OnboardDeviceType values are defined in DSP0134 7.42.2.
+const (
+	OnboardDeviceTypeOther          OnboardDeviceType = 0x01 // Other
+	OnboardDeviceTypeUnknown        OnboardDeviceType = 0x02 // Unknown
+	OnboardDeviceTypeVideo          OnboardDeviceType = 0x03 // Video
+	OnboardDeviceTypeSCSIController OnboardDeviceType = 0x04 // SCSI Controller
+	OnboardDeviceTypeEthernet       OnboardDeviceType = 0x05 // Ethernet
+	OnboardDeviceTypeTokenRing      OnboardDeviceType = 0x06 // Token Ring
+	OnboardDeviceTypeSound          OnboardDeviceType = 0x07 // Sound
+	OnboardDeviceTypePATAController OnboardDeviceType = 0x08 // PATA Controller
+	OnboardDeviceTypeSATAController OnboardDeviceType = 0x09 // SATA Controller
+	OnboardDeviceTypeSASController  OnboardDeviceType = 0x0a // SAS Controller
+	OnboardDeviceTypeWirelessLAN    OnboardDeviceType = 0x0b // Wireless LAN
+	OnboardDeviceTypeBluetooth      OnboardDeviceType = 0x0c // Bluetooth
+	OnboardDeviceTypeWWAN           OnboardDeviceType = 0x0d // WWAN
+	OnboardDeviceTypeEMMC           OnboardDeviceType = 0x0e // eMMC (embedded Multi-Media Controller)
+	OnboardDeviceTypeNVMeController OnboardDeviceType = 0x0f // NVMe Controller
+	OnboardDeviceTypeUFSController  OnboardDeviceType = 0x10 // UFS Controller
+
+	// OnboardDeviceTypeEnabled is set if the device is enabled.
+	OnboardDeviceTypeEnabled OnboardDeviceType = 1 << 7
+)
+
+// Enabled returns whether the device is enabled.
+func (v OnboardDeviceType) Enabled() bool {
+	return v&OnboardDeviceTypeEnabled != 0
+}
+
+func (v OnboardDeviceType) String() string {
+	names := map[OnboardDeviceType]string{
+		OnboardDeviceTypeOther:          "Other",
+		OnboardDeviceTypeUnknown:        "Unknown",
+		OnboardDeviceTypeVideo:          "Video",
+		OnboardDeviceTypeSCSIController: "SCSI Controller",
+		OnboardDeviceTypeEthernet:       "Ethernet",
+		OnboardDeviceTypeTokenRing:      "Token Ring",
+		OnboardDeviceTypeSound:          "Sound",
+		OnboardDeviceTypePATAController: "PATA Controller",
+		OnboardDeviceTypeSATAController: "SATA Controller",
+		OnboardDeviceTypeSASController:  "SAS Controller",
+		OnboardDeviceTypeWirelessLAN:    "Wireless LAN",
+		OnboardDeviceTypeBluetooth:      "Bluetooth",
+		OnboardDeviceTypeWWAN:           "WWAN",
+		OnboardDeviceTypeEMMC:           "eMMC (embedded Multi-Media Controller)",
+		OnboardDeviceTypeNVMeController: "NVMe Controller",
+		OnboardDeviceTypeUFSController:  "UFS Controller",
+	}
+	if name, ok := names[v&^OnboardDeviceTypeEnabled]; ok {
+		return name
+	}
+	return fmt.Sprintf("%#x", uint8(v&^OnboardDeviceTypeEnabled))
+}
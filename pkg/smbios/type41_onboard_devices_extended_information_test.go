@@ -0,0 +1,63 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import "testing"
+
+func TestOnboardDevice(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{
+			name: "enabled",
+			data: []byte{41, 11, 0x4b, 0, 1, 0x85, 1, 0, 0, 3, 0},
+			want: `Handle 0x004B, DMI type 41, 11 bytes
+Onboard Device
+	Reference Designation: Onboard LAN
+	Type: Ethernet
+	Status: Enabled
+	Type Instance: 1
+	Bus Address: 0000:03:00.0`,
+		},
+		{
+			name: "disabled without bus address",
+			data: []byte{41, 11, 0x4d, 0, 1, 0x07, 2, 0xff, 0xff, 0xff, 0xff},
+			want: `Handle 0x004D, DMI type 41, 11 bytes
+Onboard Device
+	Reference Designation: Onboard LAN
+	Type: Sound
+	Status: Disabled
+	Type Instance: 2`,
+		},
+		{
+			name: "function",
+			data: []byte{41, 11, 0x4e, 0, 1, 0x89, 1, 1, 0, 0x10, 0x03},
+			want: `Handle 0x004E, DMI type 41, 11 bytes
+Onboard Device
+	Reference Designation: Onboard LAN
+	Type: SATA Controller
+	Status: Enabled
+	Type Instance: 1
+	Bus Address: 0001:10:00.3`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &Table{
+				Header:  Header{Type: TableTypeOnboardDevice, Length: tt.data[1], Handle: uint16(tt.data[2])},
+				data:    tt.data,
+				strings: []string{"Onboard LAN"},
+			}
+			od, err := ParseOnboardDevice(table)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := od.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -43,6 +43,19 @@ type ProcessorInfo struct {
 	ThreadCount2      uint16                   // 2Eh
 }
 
+// MarshalBinary encodes the ProcessorInfo content into a binary
+func (pi *ProcessorInfo) MarshalBinary() ([]byte, error) {
+	t, err := pi.toTable()
+	if err != nil {
+		return nil, err
+	}
+	return t.MarshalBinary()
+}
+
+func (pi *ProcessorInfo) toTable() (*Table, error) {
+	return marshalStruct(TableTypeProcessorInfo, pi)
+}
+
 // ParseProcessorInfo parses a generic Table into ProcessorInfo.
 func ParseProcessorInfo(t *Table) (*ProcessorInfo, error) {
 	return parseProcessorInfo(parseStruct, t)
@@ -0,0 +1,244 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Much of this is auto-generated. If adding a new type, see README for instructions.
+
+// PortConnectorInfo is defined in DSP0134 7.9.
+type PortConnectorInfo struct {
+	Header
+	InternalReferenceDesignator string        // 04h
+	InternalConnectorType       ConnectorType // 05h
+	ExternalReferenceDesignator string        // 06h
+	ExternalConnectorType       ConnectorType // 07h
+	PortType                    PortType      // 08h
+}
+
+// ParsePortConnectorInfo parses a generic Table into PortConnectorInfo.
+func ParsePortConnectorInfo(t *Table) (*PortConnectorInfo, error) {
+	if t.Type != TableTypePortConnectorInfo {
+		return nil, fmt.Errorf("invalid table type %d", t.Type)
+	}
+	if t.Len() < 0x9 {
+		return nil, errors.New("required fields missing")
+	}
+	pi := &PortConnectorInfo{Header: t.Header}
+	if _, err := parseStruct(t, 0 /* off */, false /* complete */, pi); err != nil {
+		return nil, err
+	}
+	return pi, nil
+}
+
+func (pi *PortConnectorInfo) String() string {
+	lines := []string{
+		pi.Header.String(),
+		fmt.Sprintf("Internal Reference Designator: %s", pi.InternalReferenceDesignator),
+		fmt.Sprintf("Internal Connector Type: %s", pi.InternalConnectorType),
+		fmt.Sprintf("External Reference Designator: %s", pi.ExternalReferenceDesignator),
+		fmt.Sprintf("External Connector Type: %s", pi.ExternalConnectorType),
+		fmt.Sprintf("Port Type: %s", pi.PortType),
+	}
+	return strings.Join(lines, "\n\t")
+}
+
+// ConnectorType is defined in DSP0134 7.9.2.
+type ConnectorType uint8
+
+// ConnectorType values are defined in DSP0134 7.9.2.
+const (
+	ConnectorTypeNone                       ConnectorType = 0x00 // None
+	ConnectorTypeCentronics                 ConnectorType = 0x01 // Centronics
+	ConnectorTypeMiniCentronics             ConnectorType = 0x02 // Mini Centronics
+	ConnectorTypeProprietary                ConnectorType = 0x03 // Proprietary
+	ConnectorTypeDB25PinMale                ConnectorType = 0x04 // DB-25 pin male
+	ConnectorTypeDB25PinFemale              ConnectorType = 0x05 // DB-25 pin female
+	ConnectorTypeDB15PinMale                ConnectorType = 0x06 // DB-15 pin male
+	ConnectorTypeDB15PinFemale              ConnectorType = 0x07 // DB-15 pin female
+	ConnectorTypeDB9PinMale                 ConnectorType = 0x08 // DB-9 pin male
+	ConnectorTypeDB9PinFemale               ConnectorType = 0x09 // DB-9 pin female
+	ConnectorTypeRJ11                       ConnectorType = 0x0a // RJ-11
+	ConnectorTypeRJ45                       ConnectorType = 0x0b // RJ-45
+	ConnectorType50PinMiniSCSI              ConnectorType = 0x0c // 50-pin MiniSCSI
+	ConnectorTypeMiniDIN                    ConnectorType = 0x0d // Mini-DIN
+	ConnectorTypeMicroDIN                   ConnectorType = 0x0e // Micro-DIN
+	ConnectorTypePS2                        ConnectorType = 0x0f // PS/2
+	ConnectorTypeInfrared                   ConnectorType = 0x10 // Infrared
+	ConnectorTypeHPHIL                      ConnectorType = 0x11 // HP-HIL
+	ConnectorTypeAccessBusUSB               ConnectorType = 0x12 // Access Bus (USB)
+	ConnectorTypeSSASCSI                    ConnectorType = 0x13 // SSA SCSI
+	ConnectorTypeCircularDIN8Male           ConnectorType = 0x14 // Circular DIN-8 male
+	ConnectorTypeCircularDIN8Female         ConnectorType = 0x15 // Circular DIN-8 female
+	ConnectorTypeOnBoardIDE                 ConnectorType = 0x16 // On Board IDE
+	ConnectorTypeOnBoardFloppy              ConnectorType = 0x17 // On Board Floppy
+	ConnectorType9PinDualInline             ConnectorType = 0x18 // 9-pin Dual Inline (pin 10 cut)
+	ConnectorType25PinDualInline            ConnectorType = 0x19 // 25-pin Dual Inline (pin 26 cut)
+	ConnectorType50PinDualInline            ConnectorType = 0x1a // 50-pin Dual Inline
+	ConnectorType68PinDualInline            ConnectorType = 0x1b // 68-pin Dual Inline
+	ConnectorTypeOnBoardSoundInputFromCDROM ConnectorType = 0x1c // On Board Sound Input from CD-ROM
+	ConnectorTypeMiniCentronicsType14       ConnectorType = 0x1d // Mini-Centronics Type-14
+	ConnectorTypeMiniCentronicsType26       ConnectorType = 0x1e // Mini-Centronics Type-26
+	ConnectorTypeMiniJackHeadphones         ConnectorType = 0x1f // Mini-jack (headphones)
+	ConnectorTypeBNC                        ConnectorType = 0x20 // BNC
+	ConnectorType1394                       ConnectorType = 0x21 // 1394
+	ConnectorTypeSASSATAPlugReceptacle      ConnectorType = 0x22 // SAS/SATA Plug Receptacle
+	ConnectorTypeUSBTypeCReceptacle         ConnectorType = 0x23 // USB Type-C Receptacle
+	ConnectorTypePC98                       ConnectorType = 0xa0 // PC-98
+	ConnectorTypePC98Hireso                 ConnectorType = 0xa1 // PC-98Hireso
+	ConnectorTypePCH98                      ConnectorType = 0xa2 // PC-H98
+	ConnectorTypePC98Note                   ConnectorType = 0xa3 // PC-98Note
+	ConnectorTypePC98Full                   ConnectorType = 0xa4 // PC-98Full
+	ConnectorTypeOther                      ConnectorType = 0xff // Other
+)
+
+func (v ConnectorType) String() string {
+	names := map[ConnectorType]string{
+		ConnectorTypeNone:                       "None",
+		ConnectorTypeCentronics:                 "Centronics",
+		ConnectorTypeMiniCentronics:             "Mini Centronics",
+		ConnectorTypeProprietary:                "Proprietary",
+		ConnectorTypeDB25PinMale:                "DB-25 male",
+		ConnectorTypeDB25PinFemale:              "DB-25 female",
+		ConnectorTypeDB15PinMale:                "DB-15 male",
+		ConnectorTypeDB15PinFemale:              "DB-15 female",
+		ConnectorTypeDB9PinMale:                 "DB-9 male",
+		ConnectorTypeDB9PinFemale:               "DB-9 female",
+		ConnectorTypeRJ11:                       "RJ-11",
+		ConnectorTypeRJ45:                       "RJ-45",
+		ConnectorType50PinMiniSCSI:              "50 Pin MiniSCSI",
+		ConnectorTypeMiniDIN:                    "Mini DIN",
+		ConnectorTypeMicroDIN:                   "Micro DIN",
+		ConnectorTypePS2:                        "PS/2",
+		ConnectorTypeInfrared:                   "Infrared",
+		ConnectorTypeHPHIL:                      "HP-HIL",
+		ConnectorTypeAccessBusUSB:               "Access Bus (USB)",
+		ConnectorTypeSSASCSI:                    "SSA SCSI",
+		ConnectorTypeCircularDIN8Male:           "Circular DIN-8 male",
+		ConnectorTypeCircularDIN8Female:         "Circular DIN-8 female",
+		ConnectorTypeOnBoardIDE:                 "On Board IDE",
+		ConnectorTypeOnBoardFloppy:              "On Board Floppy",
+		ConnectorType9PinDualInline:             "9 Pin Dual Inline (pin 10 cut)",
+		ConnectorType25PinDualInline:            "25 Pin Dual Inline (pin 26 cut)",
+		ConnectorType50PinDualInline:            "50 Pin Dual Inline",
+		ConnectorType68PinDualInline:            "68 Pin Dual Inline",
+		ConnectorTypeOnBoardSoundInputFromCDROM: "On Board Sound Input From CD-ROM",
+		ConnectorTypeMiniCentronicsType14:       "Mini Centronics Type-14",
+		ConnectorTypeMiniCentronicsType26:       "Mini Centronics Type-26",
+		ConnectorTypeMiniJackHeadphones:         "Mini Jack (headphones)",
+		ConnectorTypeBNC:                        "BNC",
+		ConnectorType1394:                       "IEEE 1394",
+		ConnectorTypeSASSATAPlugReceptacle:      "SAS/SATA Plug Receptacle",
+		ConnectorTypeUSBTypeCReceptacle:         "USB Type-C Receptacle",
+		ConnectorTypePC98:                       "PC-98",
+		ConnectorTypePC98Hireso:                 "PC-98 Hireso",
+		ConnectorTypePCH98:                      "PC-H98",
+		ConnectorTypePC98Note:                   "PC-98 Note",
+		ConnectorTypePC98Full:                   "PC-98 Full",
+		ConnectorTypeOther:                      "Other",
+	}
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("%#x", uint8(v))
+}
+
+// PortType is defined in DSP0134 7.9.3.
+type PortType uint8
+
+// PortType values are defined in DSP0134 7.9.3.
+const (
+	PortTypeNone                       PortType = 0x00 // None
+	PortTypeParallelPortXTATCompatible PortType = 0x01 // Parallel Port XT/AT Compatible
+	PortTypeParallelPortPS2            PortType = 0x02 // Parallel Port PS/2
+	PortTypeParallelPortECP            PortType = 0x03 // Parallel Port ECP
+	PortTypeParallelPortEPP            PortType = 0x04 // Parallel Port EPP
+	PortTypeParallelPortECPEPP         PortType = 0x05 // Parallel Port ECP/EPP
+	PortTypeSerialPortXTATCompatible   PortType = 0x06 // Serial Port XT/AT Compatible
+	PortTypeSerialPort16450Compatible  PortType = 0x07 // Serial Port 16450 Compatible
+	PortTypeSerialPort16550Compatible  PortType = 0x08 // Serial Port 16550 Compatible
+	PortTypeSerialPort16550ACompatible PortType = 0x09 // Serial Port 16550A Compatible
+	PortTypeSCSIPort                   PortType = 0x0a // SCSI Port
+	PortTypeMIDIPort                   PortType = 0x0b // MIDI Port
+	PortTypeJoyStickPort               PortType = 0x0c // Joy Stick Port
+	PortTypeKeyboardPort               PortType = 0x0d // Keyboard Port
+	PortTypeMousePort                  PortType = 0x0e // Mouse Port
+	PortTypeSSASCSI                    PortType = 0x0f // SSA SCSI
+	PortTypeUSB                        PortType = 0x10 // USB
+	PortTypeFireWire                   PortType = 0x11 // FireWire (IEEE P1394)
+	PortTypePCMCIATypeI                PortType = 0x12 // PCMCIA Type I
+	PortTypePCMCIATypeII               PortType = 0x13 // PCMCIA Type II
+	PortTypePCMCIATypeIII              PortType = 0x14 // PCMCIA Type III
+	PortTypeCardbus                    PortType = 0x15 // Cardbus
+	PortTypeAccessBusPort              PortType = 0x16 // Access Bus Port
+	PortTypeSCSIII                     PortType = 0x17 // SCSI II
+	PortTypeSCSIWide                   PortType = 0x18 // SCSI Wide
+	PortTypePC98                       PortType = 0x19 // PC-98
+	PortTypePC98Hireso                 PortType = 0x1a // PC-98-Hireso
+	PortTypePCH98                      PortType = 0x1b // PC-H98
+	PortTypeVideoPort                  PortType = 0x1c // Video Port
+	PortTypeAudioPort                  PortType = 0x1d // Audio Port
+	PortTypeModemPort                  PortType = 0x1e // Modem Port
+	PortTypeNetworkPort                PortType = 0x1f // Network Port
+	PortTypeSATA                       PortType = 0x20 // SATA
+	PortTypeSAS                        PortType = 0x21 // SAS
+	PortTypeMFDP                       PortType = 0x22 // MFDP (Multi-Function Display Port)
+	PortTypeThunderbolt                PortType = 0x23 // Thunderbolt
+	PortType8251Compatible             PortType = 0xa0 // 8251 Compatible
+	PortType8251FIFOCompatible         PortType = 0xa1 // 8251 FIFO Compatible
+	PortTypeOther                      PortType = 0xff // Other
+)
+
+func (v PortType) String() string {
+	names := map[PortType]string{
+		PortTypeNone:                       "None",
+		PortTypeParallelPortXTATCompatible: "Parallel Port XT/AT Compatible",
+		PortTypeParallelPortPS2:            "Parallel Port PS/2",
+		PortTypeParallelPortECP:            "Parallel Port ECP",
+		PortTypeParallelPortEPP:            "Parallel Port EPP",
+		PortTypeParallelPortECPEPP:         "Parallel Port ECP/EPP",
+		PortTypeSerialPortXTATCompatible:   "Serial Port XT/AT Compatible",
+		PortTypeSerialPort16450Compatible:  "Serial Port 16450 Compatible",
+		PortTypeSerialPort16550Compatible:  "Serial Port 16550 Compatible",
+		PortTypeSerialPort16550ACompatible: "Serial Port 16550A Compatible",
+		PortTypeSCSIPort:                   "SCSI Port",
+		PortTypeMIDIPort:                   "MIDI Port",
+		PortTypeJoyStickPort:               "Joystick Port",
+		PortTypeKeyboardPort:               "Keyboard Port",
+		PortTypeMousePort:                  "Mouse Port",
+		PortTypeSSASCSI:                    "SSA SCSI",
+		PortTypeUSB:                        "USB",
+		PortTypeFireWire:                   "Firewire (IEEE P1394)",
+		PortTypePCMCIATypeI:                "PCMCIA Type I",
+		PortTypePCMCIATypeII:               "PCMCIA Type II",
+		PortTypePCMCIATypeIII:              "PCMCIA Type III",
+		PortTypeCardbus:                    "Cardbus",
+		PortTypeAccessBusPort:              "Access Bus Port",
+		PortTypeSCSIII:                     "SCSI II",
+		PortTypeSCSIWide:                   "SCSI Wide",
+		PortTypePC98:                       "PC-98",
+		PortTypePC98Hireso:                 "PC-98 Hireso",
+		PortTypePCH98:                      "PC-H98",
+		PortTypeVideoPort:                  "Video Port",
+		PortTypeAudioPort:                  "Audio Port",
+		PortTypeModemPort:                  "Modem Port",
+		PortTypeNetworkPort:                "Network Port",
+		PortTypeSATA:                       "SATA",
+		PortTypeSAS:                        "SAS",
+		PortTypeMFDP:                       "MFDP (Multi-Function Display Port)",
+		PortTypeThunderbolt:                "Thunderbolt",
+		PortType8251Compatible:             "8251 Compatible",
+		PortType8251FIFOCompatible:         "8251 FIFO Compatible",
+		PortTypeOther:                      "Other",
+	}
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return fmt.Sprintf("%#x", uint8(v))
+}
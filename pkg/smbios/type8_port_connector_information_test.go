@@ -0,0 +1,56 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package smbios
+
+import "testing"
+
+func TestParsePortConnectorInfo(t *testing.T) {
+	table := &Table{
+		Header:  Header{Type: TableTypePortConnectorInfo, Length: 9, Handle: 0x0c},
+		data:    []byte{8, 9, 0x0c, 0, 1, 0x00, 2, 0x0f, 0x0e},
+		strings: []string{"J1A1", "PS2Mouse"},
+	}
+	pi, err := ParsePortConnectorInfo(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Handle 0x000C, DMI type 8, 9 bytes
+Port Connector Information
+	Internal Reference Designator: J1A1
+	Internal Connector Type: None
+	External Reference Designator: PS2Mouse
+	External Connector Type: PS/2
+	Port Type: Mouse Port`
+	if got := pi.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	table.Type = TableTypeSystemSlots
+	if _, err := ParsePortConnectorInfo(table); err == nil {
+		t.Errorf("ParsePortConnectorInfo() on a type 9 table should fail")
+	}
+	if _, err := ParsePortConnectorInfo(&Table{Header: Header{Type: TableTypePortConnectorInfo}, data: []byte{8, 4, 0, 0}}); err == nil {
+		t.Errorf("ParsePortConnectorInfo() on a short table should fail")
+	}
+}
+
+func TestConnectorAndPortTypeString(t *testing.T) {
+	for _, tt := range []struct {
+		got  string
+		want string
+	}{
+		{ConnectorTypeAccessBusUSB.String(), "Access Bus (USB)"},
+		{ConnectorTypeDB9PinMale.String(), "DB-9 male"},
+		{ConnectorTypeOther.String(), "Other"},
+		{ConnectorType(0x50).String(), "0x50"},
+		{PortTypeSerialPort16550ACompatible.String(), "Serial Port 16550A Compatible"},
+		{PortType8251FIFOCompatible.String(), "8251 FIFO Compatible"},
+		{PortType(0x50).String(), "0x50"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("String() = %q, want %q", tt.got, tt.want)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strings"
 
 	"github.com/florianl/go-tc"
 	"github.com/florianl/go-tc/core"
@@ -145,7 +146,8 @@ const QdiscHelp = `Usage: tc qdisc [ add | del | replace | change | show ] dev S
 	tc qdisc show [ dev STRING ] [ QDISC_ID ]
 
 Where:
-	QDISC_KIND := { codel | qfq | htb | hfsc }
+	QDISC_KIND := { codel | netem | tbf | fq_codel | fq | sfq | pfifo | bfifo |
+			qfq | mqprio | prio | htb | hfsc }
 	OPTIONS := ... try tc qdisc add <desired QDISC_KIND> help
 	QDISC_ID := { root | ingress | handle QHANDLE | parent CLASSID }
 `
@@ -169,19 +171,265 @@ func (t *Trafficctl) ShowQdisc(stdout io.Writer, args *Args) error {
 				RenderClassID(qdisc.Handle, false),
 				RenderClassID(qdisc.Parent, true),
 			)
-			if qdisc.Kind == "htb" {
-				htb := qdisc.Htb
-				if htb.Init != nil {
-					fmt.Fprintf(stdout, " r2q %d default 0x%x", htb.Init.Rate2Quantum, htb.Init.Defcls)
+			fmt.Fprintf(stdout, "%s\n", RenderQdiscOptions(&qdisc))
+		}
+	}
+	return nil
+}
+
+// RenderQdiscOptions returns the kind specific options of qdisc the way
+// `tc qdisc show` prints them, starting with a space if there are any.
+func RenderQdiscOptions(qdisc *tc.Object) string {
+	var b strings.Builder
+	switch qdisc.Kind {
+	case "htb":
+		if htb := qdisc.Htb; htb != nil {
+			if htb.Init != nil {
+				fmt.Fprintf(&b, " r2q %d default 0x%x", htb.Init.Rate2Quantum, htb.Init.Defcls)
+			}
+			if htb.DirectQlen != nil {
+				fmt.Fprintf(&b, " direct_qlen %d", *htb.DirectQlen)
+			}
+		}
+	case "netem":
+		if qdisc.Netem != nil {
+			renderNetem(&b, qdisc.Netem)
+		}
+	case "tbf":
+		if tbf := qdisc.Tbf; tbf != nil && tbf.Parms != nil {
+			rate := uint64(tbf.Parms.Rate.Rate)
+			fmt.Fprintf(&b, " rate %s", RenderRate(rate))
+			if burst, err := CalcXMitSize(rate, tbf.Parms.Buffer); err == nil {
+				fmt.Fprintf(&b, " burst %s", RenderSize(uint64(burst)))
+			}
+			if peak := uint64(tbf.Parms.PeakRate.Rate); peak != 0 {
+				fmt.Fprintf(&b, " peakrate %s", RenderRate(peak))
+				if mtu, err := CalcXMitSize(peak, tbf.Parms.Mtu); err == nil {
+					fmt.Fprintf(&b, " minburst %s", RenderSize(uint64(mtu)))
+				}
+			}
+			fmt.Fprintf(&b, " limit %s", RenderSize(uint64(tbf.Parms.Limit)))
+		}
+	case "fq_codel":
+		if qdisc.FqCodel != nil {
+			renderFqCodel(&b, qdisc.FqCodel)
+		}
+	case "fq":
+		if qdisc.Fq != nil {
+			renderFq(&b, qdisc.Fq)
+		}
+	case "sfq":
+		if sfq := qdisc.Sfq; sfq != nil {
+			fmt.Fprintf(&b, " limit %dp quantum %s depth %d divisor %d",
+				sfq.V0.Limit, RenderSize(uint64(sfq.V0.Quantum)), sfq.Depth, sfq.V0.Divisor)
+			if sfq.V0.PerturbPeriod != 0 {
+				fmt.Fprintf(&b, " perturb %dsec", sfq.V0.PerturbPeriod)
+			}
+			if sfq.Headdrop != 0 {
+				b.WriteString(" headdrop")
+			}
+		}
+	case "pfifo":
+		if qdisc.Pfifo != nil {
+			fmt.Fprintf(&b, " limit %dp", qdisc.Pfifo.Limit)
+		}
+	case "bfifo":
+		if qdisc.Bfifo != nil {
+			fmt.Fprintf(&b, " limit %s", RenderSize(uint64(qdisc.Bfifo.Limit)))
+		}
+	case "prio":
+		if prio := qdisc.Prio; prio != nil {
+			fmt.Fprintf(&b, " bands %d priomap", prio.Bands)
+			for _, p := range prio.PrioMap {
+				fmt.Fprintf(&b, " %d", p)
+			}
+		}
+	case "red":
+		if red := qdisc.Red; red != nil && red.Parms != nil {
+			fmt.Fprintf(&b, " limit %s min %s max %s",
+				RenderSize(uint64(red.Parms.Limit)),
+				RenderSize(uint64(red.Parms.QthMin)),
+				RenderSize(uint64(red.Parms.QthMax)))
+			for _, f := range []struct {
+				flag uint8
+				name string
+			}{
+				{redECN, "ecn"},
+				{redHardDrop, "harddrop"},
+				{redAdaptive, "adaptive"},
+			} {
+				if red.Parms.Flags&f.flag != 0 {
+					fmt.Fprintf(&b, " %s", f.name)
+				}
+			}
+		}
+	case "mqprio":
+		if mq := qdisc.MqPrio; mq != nil && mq.Opt != nil {
+			fmt.Fprintf(&b, " tc %d map", mq.Opt.NumTc)
+			for _, p := range mq.Opt.PrioTcMap {
+				fmt.Fprintf(&b, " %d", p)
+			}
+			b.WriteString(" queues:")
+			for i := 0; i < int(mq.Opt.NumTc) && i < len(mq.Opt.Count); i++ {
+				first := mq.Opt.Offset[i]
+				last := first
+				if mq.Opt.Count[i] > 0 {
+					last = first + mq.Opt.Count[i] - 1
 				}
-				if htb.DirectQlen != nil {
-					fmt.Fprintf(stdout, " direct_qlen %d", *htb.DirectQlen)
+				fmt.Fprintf(&b, "(%d:%d)", first, last)
+				if i+1 < int(mq.Opt.NumTc) {
+					b.WriteString(" ")
 				}
 			}
-			fmt.Fprintf(stdout, "\n")
 		}
 	}
-	return nil
+	return b.String()
+}
+
+func renderNetem(b *strings.Builder, netem *tc.Netem) {
+	fmt.Fprintf(b, " limit %d", netem.Qopt.Limit)
+
+	corr := tc.NetemCorr{}
+	if netem.Corr != nil {
+		corr = *netem.Corr
+	}
+	if netem.Latency64 != nil && *netem.Latency64 != 0 {
+		fmt.Fprintf(b, " delay %s", RenderTime(uint64(*netem.Latency64)/1000))
+		if netem.Jitter64 != nil && *netem.Jitter64 != 0 {
+			fmt.Fprintf(b, " %s", RenderTime(uint64(*netem.Jitter64)/1000))
+			if corr.Delay != 0 {
+				fmt.Fprintf(b, " %s", RenderPercent(corr.Delay))
+			}
+		}
+	}
+	for _, p := range []struct {
+		name       string
+		prob, corr uint32
+	}{
+		{"loss", netem.Qopt.Loss, corr.Loss},
+		{"duplicate", netem.Qopt.Duplicate, corr.Dup},
+	} {
+		if p.prob != 0 {
+			fmt.Fprintf(b, " %s %s", p.name, RenderPercent(p.prob))
+			if p.corr != 0 {
+				fmt.Fprintf(b, " %s", RenderPercent(p.corr))
+			}
+		}
+	}
+	if r := netem.Reorder; r != nil && r.Probability != 0 {
+		fmt.Fprintf(b, " reorder %s", RenderPercent(r.Probability))
+		if r.Correlation != 0 {
+			fmt.Fprintf(b, " %s", RenderPercent(r.Correlation))
+		}
+	}
+	if c := netem.Corrupt; c != nil && c.Probability != 0 {
+		fmt.Fprintf(b, " corrupt %s", RenderPercent(c.Probability))
+		if c.Correlation != 0 {
+			fmt.Fprintf(b, " %s", RenderPercent(c.Correlation))
+		}
+	}
+	if r := netem.Rate; r != nil && r.Rate != 0 {
+		rate := uint64(r.Rate)
+		if netem.Rate64 != nil {
+			rate = max(rate, *netem.Rate64)
+		}
+		fmt.Fprintf(b, " rate %s", RenderRate(rate))
+		if r.PacketOverhead != 0 {
+			fmt.Fprintf(b, " packetoverhead %d", r.PacketOverhead)
+		}
+		if r.CellSize != 0 {
+			fmt.Fprintf(b, " cellsize %d", r.CellSize)
+		}
+		if r.CellOverhead != 0 {
+			fmt.Fprintf(b, " celloverhead %d", r.CellOverhead)
+		}
+	}
+	if netem.Ecn != nil && *netem.Ecn != 0 {
+		b.WriteString(" ecn")
+	}
+	if netem.Qopt.Gap != 0 {
+		fmt.Fprintf(b, " gap %d", netem.Qopt.Gap)
+	}
+}
+
+func renderFqCodel(b *strings.Builder, f *tc.FqCodel) {
+	if f.Limit != nil {
+		fmt.Fprintf(b, " limit %dp", *f.Limit)
+	}
+	if f.Flows != nil {
+		fmt.Fprintf(b, " flows %d", *f.Flows)
+	}
+	if f.Quantum != nil {
+		fmt.Fprintf(b, " quantum %d", *f.Quantum)
+	}
+	if f.Target != nil {
+		fmt.Fprintf(b, " target %s", RenderTime(uint64(*f.Target)))
+	}
+	if f.CEThreshold != nil {
+		fmt.Fprintf(b, " ce_threshold %s", RenderTime(uint64(*f.CEThreshold)))
+	}
+	if f.Interval != nil {
+		fmt.Fprintf(b, " interval %s", RenderTime(uint64(*f.Interval)))
+	}
+	if f.MemoryLimit != nil {
+		fmt.Fprintf(b, " memory_limit %s", RenderSize(uint64(*f.MemoryLimit)))
+	}
+	if f.ECN != nil && *f.ECN != 0 {
+		b.WriteString(" ecn")
+	}
+	if f.DropBatchSize != nil {
+		fmt.Fprintf(b, " drop_batch %d", *f.DropBatchSize)
+	}
+}
+
+func renderFq(b *strings.Builder, f *tc.Fq) {
+	if f.PLimit != nil {
+		fmt.Fprintf(b, " limit %dp", *f.PLimit)
+	}
+	if f.FlowPLimit != nil {
+		fmt.Fprintf(b, " flow_limit %dp", *f.FlowPLimit)
+	}
+	if f.BucketsLog != nil {
+		fmt.Fprintf(b, " buckets %d", uint64(1)<<*f.BucketsLog)
+	}
+	if f.OrphanMask != nil {
+		fmt.Fprintf(b, " orphan_mask %d", *f.OrphanMask)
+	}
+	if f.RateEnable != nil && *f.RateEnable == 0 {
+		b.WriteString(" nopacing")
+	}
+	if f.Quantum != nil {
+		fmt.Fprintf(b, " quantum %s", RenderSize(uint64(*f.Quantum)))
+	}
+	if f.InitQuantum != nil {
+		fmt.Fprintf(b, " initial_quantum %s", RenderSize(uint64(*f.InitQuantum)))
+	}
+	// The kernel reports an unlimited rate as ~0.
+	if f.FlowMaxRate != nil && *f.FlowMaxRate != maxUint32 {
+		fmt.Fprintf(b, " maxrate %s", RenderRate(uint64(*f.FlowMaxRate)))
+	}
+	if f.LowRateThreshold != nil {
+		fmt.Fprintf(b, " low_rate_threshold %s", RenderRate(uint64(*f.LowRateThreshold)))
+	}
+	if f.FlowRefillDelay != nil {
+		fmt.Fprintf(b, " refill_delay %s", RenderTime(uint64(*f.FlowRefillDelay)))
+	}
+	if f.TimerSlack != nil {
+		fmt.Fprintf(b, " timer_slack %s", RenderTime(uint64(*f.TimerSlack)/1000))
+	}
+	if f.Horizon != nil {
+		fmt.Fprintf(b, " horizon %s", RenderTime(uint64(*f.Horizon)))
+	}
+	if f.HorizonDrop != nil {
+		if *f.HorizonDrop != 0 {
+			b.WriteString(" horizon_drop")
+		} else {
+			b.WriteString(" horizon_cap")
+		}
+	}
+	if f.CEThreshold != nil {
+		fmt.Fprintf(b, " ce_threshold %s", RenderTime(uint64(*f.CEThreshold)))
+	}
 }
 
 // AddQdisc implements the functionality of `tc qdisc add ... `
@@ -254,10 +502,8 @@ func (t *Trafficctl) ReplaceQdisc(stdout io.Writer, args *Args) error {
 		Parent:  *args.parent,
 	}
 	obj := &tc.Object{
-		Msg: msg,
-		Attribute: tc.Attribute{
-			Kind: args.obj.Kind,
-		},
+		Msg:       msg,
+		Attribute: args.obj.Attribute,
 	}
 
 	if err := t.Tc.Qdisc().Replace(obj); err != nil {
@@ -279,10 +525,8 @@ func (t *Trafficctl) ChangeQdisc(stdout io.Writer, args *Args) error {
 		Parent:  *args.parent,
 	}
 	obj := &tc.Object{
-		Msg: msg,
-		Attribute: tc.Attribute{
-			Kind: args.obj.Kind,
-		},
+		Msg:       msg,
+		Attribute: args.obj.Attribute,
 	}
 
 	if err := t.Tc.Qdisc().Change(obj); err != nil {
@@ -298,17 +542,17 @@ func supportetQdisc(qd string) func(io.Writer, []string) (*tc.Object, error) {
 		"cake":       nil,
 		"choke":      nil,
 		"codel":      ParseCodelArgs,
-		"pfifo":      nil,
-		"qfifo":      nil,
-		"fq":         nil,
-		"fq_codel":   nil,
+		"pfifo":      ParsePFIFOArgs,
+		"bfifo":      ParseBFIFOArgs,
+		"fq":         ParseFqArgs,
+		"fq_codel":   ParseFqCodelArgs,
 		"fq_pie":     nil,
 		"gred":       nil,
 		"hhf":        nil,
 		"ingress":    nil,
-		"mqprio":     nil,
+		"mqprio":     ParseMQPrioArgs,
 		"multiq":     nil,
-		"netem":      nil,
+		"netem":      ParseNetemArgs,
 		"pfifo_fast": nil,
 		"pie":        nil,
 		// QFQ is listed as Classfull QDisk in man page of tc, but tc implementation
@@ -316,8 +560,8 @@ func supportetQdisc(qd string) func(io.Writer, []string) (*tc.Object, error) {
 		"qfq": ParseQFQArgs,
 		"red": nil,
 		"sfb": nil,
-		"sfq": nil,
-		"tbf": nil,
+		"sfq": ParseSFQArgs,
+		"tbf": ParseTBFArgs,
 	}
 
 	ret := supported[qd]
@@ -334,6 +578,7 @@ func supportetQdiscClassfull(qd string) func(io.Writer, []string) (*tc.Object, e
 		// Classful qdiscs
 		"cbs":      nil, // (not supported for adding byt go-tc library)
 		"htb":      ParseHTBQDiscArgs,
+		"prio":     ParsePrioArgs,
 		"hfsc":     ParseHFSCQDiscArgs,
 		"hfscqopt": nil, // (not supported for adding byt go-tc library)
 		"dsmark":   nil, // (not supported for adding byt go-tc library)
@@ -9,7 +9,10 @@ import (
 	"errors"
 	"strconv"
 	"testing"
+	"time"
 
+	"github.com/florianl/go-tc"
+	"github.com/google/go-cmp/cmp"
 	trafficctl "github.com/u-root/u-root/pkg/tc"
 )
 
@@ -523,3 +526,601 @@ func TestParseHFSCQdiscArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNetemArgs(t *testing.T) {
+	latency := int64(100 * time.Millisecond)
+	jitter := int64(10 * time.Millisecond)
+	on := uint32(1)
+	rate64 := uint64(40 * 1000 * 1000 * 1000 / 8)
+
+	for _, tt := range []struct {
+		name string
+		args []string
+		exp  *tc.Netem
+		err  error
+	}{
+		{
+			name: "noArgs",
+			args: []string{},
+			exp:  &tc.Netem{Qopt: tc.NetemQopt{Limit: 1000}},
+		},
+		{
+			name: "all",
+			args: []string{
+				"delay", "100ms", "10ms", "25%",
+				"loss", "random", "1%",
+				"duplicate", "1%", "50%",
+				"reorder", "25%",
+				"corrupt", "0.5%",
+				"rate", "1mbit",
+				"ecn",
+				"limit", "2000",
+			},
+			exp: &tc.Netem{
+				Qopt: tc.NetemQopt{
+					Limit:     2000,
+					Loss:      42949673,
+					Duplicate: 42949673,
+					Gap:       1,
+				},
+				Corr:      &tc.NetemCorr{Delay: 1073741824, Dup: 2147483648},
+				Reorder:   &tc.NetemReorder{Probability: 1073741824},
+				Corrupt:   &tc.NetemCorrupt{Probability: 21474836},
+				Rate:      &tc.NetemRate{Rate: 125000},
+				Ecn:       &on,
+				Latency64: &latency,
+				Jitter64:  &jitter,
+			},
+		},
+		{
+			name: "rate64",
+			args: []string{"rate", "40gbit", "14", "64", "-2"},
+			exp: &tc.Netem{
+				Qopt:   tc.NetemQopt{Limit: 1000},
+				Rate:   &tc.NetemRate{Rate: 0xFFFF_FFFF, PacketOverhead: 14, CellSize: 64, CellOverhead: -2},
+				Rate64: &rate64,
+			},
+		},
+		{
+			name: "reorderWithoutDelay",
+			args: []string{"reorder", "25%"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "gapWithoutReorder",
+			args: []string{"delay", "10ms", "gap", "5"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "lossNoPercent",
+			args: []string{"loss", "1"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "lossTooLarge",
+			args: []string{"loss", "101%"},
+			err:  trafficctl.ErrOutOfBounds,
+		},
+		{
+			name: "lossState",
+			args: []string{"loss", "state", "1%"},
+			err:  trafficctl.ErrNotImplemented,
+		},
+		{
+			name: "delayMissing",
+			args: []string{"delay"},
+			err:  trafficctl.ErrNotEnoughArgs,
+		},
+		{
+			name: "invalid",
+			args: []string{"slot", "10ms"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "help",
+			args: []string{"help"},
+			err:  trafficctl.ErrExitAfterHelp,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+			obj, err := trafficctl.ParseNetemArgs(&outBuf, tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseNetemArgs(%q) = %v, not %v", tt.args, err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.exp, obj.Netem); diff != "" {
+				t.Errorf("ParseNetemArgs(%q) mismatch (-want +got):\n%s", tt.args, diff)
+			}
+		})
+	}
+}
+
+func TestParseTBFArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		args  []string
+		limit uint32
+		err   error
+	}{
+		{
+			name:  "limit",
+			args:  []string{"rate", "1mbit", "burst", "32k", "limit", "10k"},
+			limit: 10 * 1024,
+		},
+		{
+			name:  "latency",
+			args:  []string{"rate", "1mbit", "burst", "32k", "latency", "400ms"},
+			limit: 125000*4/10 + 32*1024,
+		},
+		{
+			name: "peakrate",
+			args: []string{"rate", "1mbit", "burst", "32k", "latency", "400ms", "peakrate", "2mbit", "mtu", "1514"},
+			// The smaller of the rate and peak rate limits wins.
+			limit: 125000*4/10 + 32*1024,
+		},
+		{
+			name: "noRate",
+			args: []string{"burst", "32k", "limit", "10k"},
+			err:  trafficctl.ErrNotEnoughArgs,
+		},
+		{
+			name: "limitAndLatency",
+			args: []string{"rate", "1mbit", "burst", "32k", "limit", "10k", "latency", "50ms"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "peakrateWithoutMTU",
+			args: []string{"rate", "1mbit", "burst", "32k", "limit", "10k", "peakrate", "2mbit"},
+			err:  trafficctl.ErrNotEnoughArgs,
+		},
+		{
+			name: "invalid",
+			args: []string{"foo", "bar"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "help",
+			args: []string{"help"},
+			err:  trafficctl.ErrExitAfterHelp,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+			obj, err := trafficctl.ParseTBFArgs(&outBuf, tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseTBFArgs(%q) = %v, not %v", tt.args, err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if obj.Tbf.Parms.Limit != tt.limit {
+				t.Errorf("ParseTBFArgs(%q) limit = %d, not %d", tt.args, obj.Tbf.Parms.Limit, tt.limit)
+			}
+			if obj.Tbf.Burst == nil || *obj.Tbf.Burst != 32*1024 {
+				t.Errorf("ParseTBFArgs(%q) burst = %v, not %d", tt.args, obj.Tbf.Burst, 32*1024)
+			}
+		})
+	}
+}
+
+func TestParseFqCodelArgs(t *testing.T) {
+	u32 := func(v uint32) *uint32 { return &v }
+	for _, tt := range []struct {
+		name string
+		args []string
+		exp  *tc.FqCodel
+		err  error
+	}{
+		{
+			name: "noArgs",
+			args: []string{},
+			exp:  &tc.FqCodel{ECN: u32(1)},
+		},
+		{
+			name: "all",
+			args: []string{
+				"limit", "1024", "flows", "128", "target", "5ms", "interval", "100ms",
+				"quantum", "1514", "noecn", "ce_threshold", "1ms", "memory_limit", "32m", "drop_batch", "64",
+			},
+			exp: &tc.FqCodel{
+				Limit:         u32(1024),
+				Flows:         u32(128),
+				Target:        u32(5000),
+				Interval:      u32(100000),
+				Quantum:       u32(1514),
+				ECN:           u32(0),
+				CEThreshold:   u32(1000),
+				MemoryLimit:   u32(32 * 1024 * 1024),
+				DropBatchSize: u32(64),
+			},
+		},
+		{
+			name: "limitInvalid",
+			args: []string{"limit", "-1"},
+			err:  strconv.ErrSyntax,
+		},
+		{
+			name: "invalid",
+			args: []string{"foo"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "help",
+			args: []string{"help"},
+			err:  trafficctl.ErrExitAfterHelp,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+			obj, err := trafficctl.ParseFqCodelArgs(&outBuf, tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseFqCodelArgs(%q) = %v, not %v", tt.args, err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.exp, obj.FqCodel); diff != "" {
+				t.Errorf("ParseFqCodelArgs(%q) mismatch (-want +got):\n%s", tt.args, diff)
+			}
+		})
+	}
+}
+
+func TestParseFqArgs(t *testing.T) {
+	u32 := func(v uint32) *uint32 { return &v }
+	u8 := func(v uint8) *uint8 { return &v }
+	for _, tt := range []struct {
+		name string
+		args []string
+		exp  *tc.Fq
+		err  error
+	}{
+		{
+			name: "noArgs",
+			args: []string{},
+			exp:  &tc.Fq{PLimit: u32(10000)},
+		},
+		{
+			name: "all",
+			args: []string{
+				"limit", "100", "flow_limit", "10", "quantum", "3028", "initial_quantum", "15140",
+				"maxrate", "8mbit", "buckets", "1024", "nopacing", "refill_delay", "40ms",
+				"low_rate_threshold", "560kbit", "orphan_mask", "1023", "timer_slack", "10us",
+				"ce_threshold", "2ms", "horizon", "10s", "horizon_drop",
+			},
+			exp: &tc.Fq{
+				PLimit:           u32(100),
+				FlowPLimit:       u32(10),
+				Quantum:          u32(3028),
+				InitQuantum:      u32(15140),
+				RateEnable:       u32(0),
+				FlowMaxRate:      u32(1000000),
+				BucketsLog:       u32(10),
+				FlowRefillDelay:  u32(40000),
+				OrphanMask:       u32(1023),
+				LowRateThreshold: u32(70000),
+				CEThreshold:      u32(2000),
+				TimerSlack:       u32(10000),
+				Horizon:          u32(10000000),
+				HorizonDrop:      u8(1),
+			},
+		},
+		{
+			name: "bucketsNotPowerOf2",
+			args: []string{"buckets", "1000"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "invalid",
+			args: []string{"foo"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "help",
+			args: []string{"help"},
+			err:  trafficctl.ErrExitAfterHelp,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+			obj, err := trafficctl.ParseFqArgs(&outBuf, tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseFqArgs(%q) = %v, not %v", tt.args, err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.exp, obj.Fq); diff != "" {
+				t.Errorf("ParseFqArgs(%q) mismatch (-want +got):\n%s", tt.args, diff)
+			}
+		})
+	}
+}
+
+func TestParseSFQArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []string
+		exp  *tc.Sfq
+		err  error
+	}{
+		{
+			name: "noArgs",
+			args: []string{},
+			exp:  &tc.Sfq{},
+		},
+		{
+			name: "all",
+			args: []string{"limit", "127", "perturb", "10", "quantum", "1514", "divisor", "1024", "flows", "128", "depth", "64", "headdrop"},
+			exp: &tc.Sfq{
+				V0:       tc.SfqQopt{Quantum: 1514, PerturbPeriod: 10, Limit: 127, Divisor: 1024, Flows: 128},
+				Depth:    64,
+				Headdrop: 1,
+			},
+		},
+		{
+			name: "invalid",
+			args: []string{"redflowlimit", "100"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "help",
+			args: []string{"help"},
+			err:  trafficctl.ErrExitAfterHelp,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+			obj, err := trafficctl.ParseSFQArgs(&outBuf, tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseSFQArgs(%q) = %v, not %v", tt.args, err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.exp, obj.Sfq); diff != "" {
+				t.Errorf("ParseSFQArgs(%q) mismatch (-want +got):\n%s", tt.args, diff)
+			}
+		})
+	}
+}
+
+func TestParseFIFOArgs(t *testing.T) {
+	var outBuf bytes.Buffer
+	obj, err := trafficctl.ParsePFIFOArgs(&outBuf, []string{"limit", "100"})
+	if err != nil || obj.Kind != "pfifo" || obj.Pfifo.Limit != 100 {
+		t.Errorf("ParsePFIFOArgs(limit 100) = %+v, %v", obj, err)
+	}
+
+	obj, err = trafficctl.ParseBFIFOArgs(&outBuf, []string{"limit", "10k"})
+	if err != nil || obj.Kind != "bfifo" || obj.Bfifo.Limit != 10*1024 {
+		t.Errorf("ParseBFIFOArgs(limit 10k) = %+v, %v", obj, err)
+	}
+
+	obj, err = trafficctl.ParsePFIFOArgs(&outBuf, []string{})
+	if err != nil || obj.Pfifo.Limit != 1000 {
+		t.Errorf("ParsePFIFOArgs() = %+v, %v", obj, err)
+	}
+
+	if _, err := trafficctl.ParseBFIFOArgs(&outBuf, []string{"foo"}); !errors.Is(err, trafficctl.ErrInvalidArg) {
+		t.Errorf("ParseBFIFOArgs(foo) = %v, not %v", err, trafficctl.ErrInvalidArg)
+	}
+}
+
+func TestParsePrioArgs(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []string
+		exp  *tc.Prio
+		err  error
+	}{
+		{
+			name: "noArgs",
+			args: []string{},
+			exp:  &tc.Prio{Bands: 3, PrioMap: [16]uint8{1, 2, 2, 2, 1, 2, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1}},
+		},
+		{
+			name: "priomap",
+			args: []string{"bands", "2", "priomap", "1", "1", "0", "0"},
+			exp:  &tc.Prio{Bands: 2, PrioMap: [16]uint8{1, 1}},
+		},
+		{
+			name: "bandsTooSmall",
+			args: []string{"bands", "1"},
+			err:  trafficctl.ErrOutOfBounds,
+		},
+		{
+			name: "priomapOutOfBands",
+			args: []string{"bands", "2", "priomap", "3"},
+			err:  trafficctl.ErrOutOfBounds,
+		},
+		{
+			name: "help",
+			args: []string{"help"},
+			err:  trafficctl.ErrExitAfterHelp,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+			obj, err := trafficctl.ParsePrioArgs(&outBuf, tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParsePrioArgs(%q) = %v, not %v", tt.args, err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.exp, obj.Prio); diff != "" {
+				t.Errorf("ParsePrioArgs(%q) mismatch (-want +got):\n%s", tt.args, diff)
+			}
+		})
+	}
+}
+
+func TestParseMQPrioArgs(t *testing.T) {
+	u16 := func(v uint16) *uint16 { return &v }
+	for _, tt := range []struct {
+		name string
+		args []string
+		exp  *tc.MqPrio
+		err  error
+	}{
+		{
+			name: "noArgs",
+			args: []string{},
+			exp: &tc.MqPrio{Opt: &tc.MqPrioQopt{
+				NumTc:     8,
+				PrioTcMap: [16]uint8{0, 1, 2, 3, 4, 5, 6, 7},
+				Hw:        1,
+			}},
+		},
+		{
+			name: "all",
+			args: []string{"num_tc", "2", "map", "0", "0", "1", "1", "queues", "1@0", "3@1", "hw", "0", "mode", "channel", "shaper", "dcb"},
+			exp: &tc.MqPrio{
+				Opt: &tc.MqPrioQopt{
+					NumTc:     2,
+					PrioTcMap: [16]uint8{0, 0, 1, 1},
+					Count:     [16]uint16{1, 3},
+					Offset:    [16]uint16{0, 1},
+				},
+				Mode:   u16(1),
+				Shaper: u16(0),
+			},
+		},
+		{
+			name: "mapOutOfRange",
+			args: []string{"num_tc", "2", "map", "0", "2"},
+			err:  trafficctl.ErrOutOfBounds,
+		},
+		{
+			name: "badQueue",
+			args: []string{"queues", "1@x"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "badMode",
+			args: []string{"mode", "foo"},
+			err:  trafficctl.ErrInvalidArg,
+		},
+		{
+			name: "help",
+			args: []string{"help"},
+			err:  trafficctl.ErrExitAfterHelp,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var outBuf bytes.Buffer
+			obj, err := trafficctl.ParseMQPrioArgs(&outBuf, tt.args)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseMQPrioArgs(%q) = %v, not %v", tt.args, err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.exp, obj.MqPrio); diff != "" {
+				t.Errorf("ParseMQPrioArgs(%q) mismatch (-want +got):\n%s", tt.args, diff)
+			}
+		})
+	}
+}
+
+func TestRenderQdiscOptions(t *testing.T) {
+	u32 := func(v uint32) *uint32 { return &v }
+	latency := int64(100 * time.Millisecond)
+	jitter := int64(10 * time.Millisecond)
+
+	for _, tt := range []struct {
+		name string
+		obj  tc.Object
+		exp  string
+	}{
+		{
+			name: "netem",
+			obj: tc.Object{Attribute: tc.Attribute{Kind: "netem", Netem: &tc.Netem{
+				Qopt:      tc.NetemQopt{Limit: 1000, Loss: 42949673, Gap: 1},
+				Corr:      &tc.NetemCorr{Delay: 1073741824},
+				Reorder:   &tc.NetemReorder{Probability: 1073741824},
+				Corrupt:   &tc.NetemCorrupt{Probability: 21474836},
+				Rate:      &tc.NetemRate{Rate: 125000},
+				Latency64: &latency,
+				Jitter64:  &jitter,
+			}}},
+			exp: " limit 1000 delay 100ms 10ms 25% loss 1% reorder 25% corrupt 0.5% rate 1Mbit gap 1",
+		},
+		{
+			name: "fq_codel",
+			obj: tc.Object{Attribute: tc.Attribute{Kind: "fq_codel", FqCodel: &tc.FqCodel{
+				Limit: u32(10240), Flows: u32(1024), Quantum: u32(1514), Target: u32(5000),
+				Interval: u32(100000), MemoryLimit: u32(32 << 20), ECN: u32(1), DropBatchSize: u32(64),
+			}}},
+			exp: " limit 10240p flows 1024 quantum 1514 target 5ms interval 100ms memory_limit 32Mb ecn drop_batch 64",
+		},
+		{
+			name: "fq",
+			obj: tc.Object{Attribute: tc.Attribute{Kind: "fq", Fq: &tc.Fq{
+				PLimit: u32(10000), FlowPLimit: u32(100), BucketsLog: u32(10), Quantum: u32(3028),
+				FlowMaxRate: u32(0xFFFF_FFFF), RateEnable: u32(1), FlowRefillDelay: u32(40000),
+			}}},
+			exp: " limit 10000p flow_limit 100p buckets 1024 quantum 3028b refill_delay 40ms",
+		},
+		{
+			name: "sfq",
+			obj: tc.Object{Attribute: tc.Attribute{Kind: "sfq", Sfq: &tc.Sfq{
+				V0:    tc.SfqQopt{Quantum: 1514, PerturbPeriod: 10, Limit: 127, Divisor: 1024},
+				Depth: 127,
+			}}},
+			exp: " limit 127p quantum 1514b depth 127 divisor 1024 perturb 10sec",
+		},
+		{
+			name: "pfifo",
+			obj:  tc.Object{Attribute: tc.Attribute{Kind: "pfifo", Pfifo: &tc.FifoOpt{Limit: 1000}}},
+			exp:  " limit 1000p",
+		},
+		{
+			name: "bfifo",
+			obj:  tc.Object{Attribute: tc.Attribute{Kind: "bfifo", Bfifo: &tc.FifoOpt{Limit: 10240}}},
+			exp:  " limit 10Kb",
+		},
+		{
+			name: "prio",
+			obj: tc.Object{Attribute: tc.Attribute{Kind: "prio", Prio: &tc.Prio{
+				Bands: 3, PrioMap: [16]uint8{1, 2, 2, 2, 1, 2, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1},
+			}}},
+			exp: " bands 3 priomap 1 2 2 2 1 2 0 0 1 1 1 1 1 1 1 1",
+		},
+		{
+			name: "red",
+			obj: tc.Object{Attribute: tc.Attribute{Kind: "red", Red: &tc.Red{
+				Parms: &tc.RedQOpt{Limit: 400000, QthMin: 30000, QthMax: 90000, Flags: 1},
+			}}},
+			exp: " limit 400000b min 30000b max 90000b ecn",
+		},
+		{
+			name: "mqprio",
+			obj: tc.Object{Attribute: tc.Attribute{Kind: "mqprio", MqPrio: &tc.MqPrio{Opt: &tc.MqPrioQopt{
+				NumTc: 2, PrioTcMap: [16]uint8{0, 0, 1, 1}, Count: [16]uint16{1, 3}, Offset: [16]uint16{0, 1},
+			}}}},
+			exp: " tc 2 map 0 0 1 1 0 0 0 0 0 0 0 0 0 0 0 0 queues:(0:0) (1:3)",
+		},
+		{
+			name: "htb",
+			obj: tc.Object{Attribute: tc.Attribute{Kind: "htb", Htb: &tc.Htb{
+				Init: &tc.HtbGlob{Rate2Quantum: 10, Defcls: 0x30},
+			}}},
+			exp: " r2q 10 default 0x30",
+		},
+		{
+			name: "ingress",
+			obj:  tc.Object{Attribute: tc.Attribute{Kind: "ingress"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trafficctl.RenderQdiscOptions(&tt.obj); got != tt.exp {
+				t.Errorf("RenderQdiscOptions() = %q, not %q", got, tt.exp)
+			}
+		})
+	}
+}
@@ -7,7 +7,9 @@ package trafficctl
 import (
 	"fmt"
 	"io"
+	"math/bits"
 	"strconv"
+	"strings"
 
 	"github.com/florianl/go-tc"
 )
@@ -204,3 +206,815 @@ func ParseHFSCQDiscArgs(stdout io.Writer, args []string) (*tc.Object, error) {
 
 	return ret, nil
 }
+
+// qdiscArg returns the value following the keyword at args[i].
+func qdiscArg(args []string, i int) (string, error) {
+	if i+1 >= len(args) {
+		return "", fmt.Errorf("%w: %s requires a value", ErrNotEnoughArgs, args[i])
+	}
+	return args[i+1], nil
+}
+
+// parseUint32Arg parses the value following the keyword at args[i] as uint32.
+func parseUint32Arg(args []string, i int) (uint32, error) {
+	val, err := qdiscArg(args, i)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(val, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(n), nil
+}
+
+// isPercent reports whether args[i] exists and is an optional percentage,
+// e.g. a correlation.
+func isPercent(args []string, i int) bool {
+	return i < len(args) && strings.HasSuffix(args[i], "%")
+}
+
+// isTime reports whether args[i] exists and is an optional time value.
+func isTime(args []string, i int) bool {
+	if i >= len(args) {
+		return false
+	}
+	_, err := parseTime(args[i])
+	return err == nil
+}
+
+const NetemHelp = `Usage: ... netem [ limit PACKETS ]
+		 [ delay TIME [ JITTER [ CORRELATION ] ] ]
+		 [ loss [ random ] PERCENT [ CORRELATION ] ]
+		 [ duplicate PERCENT [ CORRELATION ] ]
+		 [ corrupt PERCENT [ CORRELATION ] ]
+		 [ reorder PERCENT [ CORRELATION ] [ gap DISTANCE ] ]
+		 [ rate RATE [ PACKETOVERHEAD [ CELLSIZE [ CELLOVERHEAD ] ] ] ]
+		 [ ecn ]
+`
+
+// ParseNetemArgs parses a []string from the commandline for the netem qdisc
+// via `tc qdisc ... netem ...` and returns an *tc.Object accordingly.
+func ParseNetemArgs(out io.Writer, args []string) (*tc.Object, error) {
+	netem := &tc.Netem{
+		Qopt: tc.NetemQopt{
+			Limit: 1000,
+		},
+	}
+	corr := tc.NetemCorr{}
+	var latency uint32
+
+	for i := 0; i < len(args); i++ {
+		var err error
+		switch args[i] {
+		case "limit":
+			netem.Qopt.Limit, err = parseUint32Arg(args, i)
+			i++
+		case "delay", "latency":
+			var val string
+			if val, err = qdiscArg(args, i); err != nil {
+				return nil, err
+			}
+			if latency, err = parseTime(val); err != nil {
+				return nil, err
+			}
+			i++
+			ns := int64(latency) * 1000
+			netem.Latency64 = &ns
+			if isTime(args, i+1) {
+				jitter, _ := parseTime(args[i+1])
+				jns := int64(jitter) * 1000
+				netem.Jitter64 = &jns
+				i++
+				if isPercent(args, i+1) {
+					corr.Delay, err = ParsePercent(args[i+1])
+					i++
+				}
+			}
+		case "loss", "drop":
+			if i+1 < len(args) && args[i+1] == "random" {
+				i++
+			}
+			if i+1 < len(args) && (args[i+1] == "state" || args[i+1] == "gemodel") {
+				return nil, fmt.Errorf("loss %s: %w", args[i+1], ErrNotImplemented)
+			}
+			var val string
+			if val, err = qdiscArg(args, i); err != nil {
+				return nil, err
+			}
+			if netem.Qopt.Loss, err = ParsePercent(val); err != nil {
+				return nil, err
+			}
+			i++
+			if isPercent(args, i+1) {
+				corr.Loss, err = ParsePercent(args[i+1])
+				i++
+			}
+		case "duplicate":
+			var val string
+			if val, err = qdiscArg(args, i); err != nil {
+				return nil, err
+			}
+			if netem.Qopt.Duplicate, err = ParsePercent(val); err != nil {
+				return nil, err
+			}
+			i++
+			if isPercent(args, i+1) {
+				corr.Dup, err = ParsePercent(args[i+1])
+				i++
+			}
+		case "corrupt":
+			var val string
+			if val, err = qdiscArg(args, i); err != nil {
+				return nil, err
+			}
+			netem.Corrupt = &tc.NetemCorrupt{}
+			if netem.Corrupt.Probability, err = ParsePercent(val); err != nil {
+				return nil, err
+			}
+			i++
+			if isPercent(args, i+1) {
+				netem.Corrupt.Correlation, err = ParsePercent(args[i+1])
+				i++
+			}
+		case "reorder":
+			var val string
+			if val, err = qdiscArg(args, i); err != nil {
+				return nil, err
+			}
+			netem.Reorder = &tc.NetemReorder{}
+			if netem.Reorder.Probability, err = ParsePercent(val); err != nil {
+				return nil, err
+			}
+			i++
+			if isPercent(args, i+1) {
+				netem.Reorder.Correlation, err = ParsePercent(args[i+1])
+				i++
+			}
+		case "gap":
+			netem.Qopt.Gap, err = parseUint32Arg(args, i)
+			i++
+		case "rate":
+			var val string
+			if val, err = qdiscArg(args, i); err != nil {
+				return nil, err
+			}
+			var rate uint64
+			if rate, err = ParseRate(val); err != nil {
+				return nil, err
+			}
+			i++
+			netem.Rate = &tc.NetemRate{}
+			if rate >= maxUint32 {
+				netem.Rate.Rate = maxUint32
+				netem.Rate64 = &rate
+			} else {
+				netem.Rate.Rate = uint32(rate)
+			}
+			// Packet overhead, cell size and cell overhead are optional and
+			// positional.
+			for _, field := range []*int32{&netem.Rate.PacketOverhead, &netem.Rate.CellSize, &netem.Rate.CellOverhead} {
+				if i+1 >= len(args) {
+					break
+				}
+				n, perr := strconv.ParseInt(args[i+1], 10, 32)
+				if perr != nil {
+					break
+				}
+				*field = int32(n)
+				i++
+			}
+		case "ecn":
+			on := uint32(1)
+			netem.Ecn = &on
+		case "help":
+			fmt.Fprint(out, NetemHelp)
+			return nil, ErrExitAfterHelp
+		default:
+			return nil, fmt.Errorf("%w: netem: %s", ErrInvalidArg, args[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if netem.Reorder != nil && netem.Reorder.Probability != 0 {
+		if latency == 0 {
+			return nil, fmt.Errorf("%w: reordering not possible without specifying some delay", ErrInvalidArg)
+		}
+		if netem.Qopt.Gap == 0 {
+			netem.Qopt.Gap = 1
+		}
+	} else if netem.Qopt.Gap != 0 {
+		return nil, fmt.Errorf("%w: gap specified without reorder probability", ErrInvalidArg)
+	}
+
+	if corr != (tc.NetemCorr{}) {
+		netem.Corr = &corr
+	}
+
+	ret := &tc.Object{}
+	ret.Kind = "netem"
+	ret.Netem = netem
+	return ret, nil
+}
+
+const TBFHelp = `Usage: ... tbf limit BYTES burst BYTES rate KBPS [ mtu BYTES ]
+	[ peakrate KBPS ] [ latency TIME ] [ mpu BYTES ]
+	[ overhead BYTES ] [ linklayer TYPE ]
+`
+
+// ParseTBFArgs parses a []string from the commandline for the tbf qdisc
+// via `tc qdisc ... tbf ...` and returns an *tc.Object accordingly.
+func ParseTBFArgs(out io.Writer, args []string) (*tc.Object, error) {
+	opt := &tc.TbfQopt{}
+	var rate, peakrate, burst, mtu, limit uint64
+	var latency uint32
+	var mpu, overhead uint16
+	var linkLayer uint8 = 1
+
+	for i := 0; i < len(args); i = i + 2 {
+		if args[i] == "help" {
+			fmt.Fprint(out, TBFHelp)
+			return nil, ErrExitAfterHelp
+		}
+		val, err := qdiscArg(args, i)
+		if err != nil {
+			return nil, err
+		}
+		switch args[i] {
+		case "rate":
+			rate, err = ParseRate(val)
+		case "peakrate":
+			peakrate, err = ParseRate(val)
+		case "burst", "buffer", "maxburst":
+			burst, err = ParseSize(val)
+		case "mtu", "minburst":
+			mtu, err = ParseSize(val)
+		case "limit":
+			limit, err = ParseSize(val)
+		case "latency":
+			latency, err = parseTime(val)
+		case "mpu":
+			var m uint64
+			m, err = strconv.ParseUint(val, 10, 16)
+			mpu = uint16(m)
+		case "overhead":
+			var o uint64
+			o, err = strconv.ParseUint(val, 10, 16)
+			overhead = uint16(o)
+		case "linklayer":
+			linkLayer, err = ParseLinkLayer(val)
+		default:
+			return nil, fmt.Errorf("%w: tbf: %s", ErrInvalidArg, args[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rate == 0 || burst == 0 {
+		return nil, fmt.Errorf("%w: tbf: rate and burst are required", ErrNotEnoughArgs)
+	}
+	if (limit == 0) == (latency == 0) {
+		return nil, fmt.Errorf("%w: tbf: exactly one of limit or latency is required", ErrInvalidArg)
+	}
+	if peakrate != 0 && mtu == 0 {
+		return nil, fmt.Errorf("%w: tbf: peakrate requires mtu", ErrNotEnoughArgs)
+	}
+
+	if latency != 0 {
+		limit = rate*uint64(latency)/TimeUnitsPerSecs + burst
+		if peakrate != 0 {
+			if l := peakrate*uint64(latency)/TimeUnitsPerSecs + mtu; l < limit {
+				limit = l
+			}
+		}
+	}
+	opt.Limit = uint32(min(limit, maxUint32))
+
+	var err error
+	opt.Rate = rateSpec(rate, mpu, overhead, linkLayer)
+	if opt.Buffer, err = CalcXMitTime(rate, uint32(burst)); err != nil {
+		return nil, err
+	}
+	tbf := &tc.Tbf{
+		Parms: opt,
+		Burst: ptrTo(uint32(burst)),
+	}
+	if peakrate != 0 {
+		opt.PeakRate = rateSpec(peakrate, mpu, overhead, linkLayer)
+		if opt.Mtu, err = CalcXMitTime(peakrate, uint32(mtu)); err != nil {
+			return nil, err
+		}
+		tbf.Pburst = ptrTo(uint32(mtu))
+	}
+
+	ret := &tc.Object{}
+	ret.Kind = "tbf"
+	ret.Tbf = tbf
+	return ret, nil
+}
+
+// rateSpec returns a tc.RateSpec for rate in bytes per second. The cell log
+// matches the rate table go-tc generates for the default MTU of 2047.
+func rateSpec(rate uint64, mpu, overhead uint16, linkLayer uint8) tc.RateSpec {
+	const linkLayerMask = 0x0F
+	spec := tc.RateSpec{
+		Rate:      uint32(min(rate, maxUint32)),
+		Mpu:       mpu,
+		Overhead:  overhead,
+		Linklayer: linkLayer & linkLayerMask,
+	}
+	for (2047 >> uint32(spec.CellLog)) > 255 {
+		spec.CellLog++
+	}
+	return spec
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+const FqCodelHelp = `Usage: ... fq_codel [ limit PACKETS ] [ flows NUMBER ]
+		    [ memory_limit BYTES ]
+		    [ target TIME ] [ interval TIME ]
+		    [ quantum BYTES ] [ [no]ecn ]
+		    [ ce_threshold TIME ] [ drop_batch SIZE ]
+`
+
+// ParseFqCodelArgs parses a []string from the commandline for the fq_codel
+// qdisc via `tc qdisc ... fq_codel ...` and returns an *tc.Object accordingly.
+func ParseFqCodelArgs(out io.Writer, args []string) (*tc.Object, error) {
+	fqCodel := &tc.FqCodel{}
+	for i := 0; i < len(args); i = i + 2 {
+		var val uint32
+		var err error
+		switch args[i] {
+		case "limit":
+			val, err = parseUint32Arg(args, i)
+			fqCodel.Limit = &val
+		case "flows":
+			val, err = parseUint32Arg(args, i)
+			fqCodel.Flows = &val
+		case "quantum":
+			val, err = parseUint32Arg(args, i)
+			fqCodel.Quantum = &val
+		case "drop_batch":
+			val, err = parseUint32Arg(args, i)
+			fqCodel.DropBatchSize = &val
+		case "memory_limit":
+			var s string
+			var sz uint64
+			if s, err = qdiscArg(args, i); err == nil {
+				sz, err = ParseSize(s)
+				val = uint32(min(sz, maxUint32))
+			}
+			fqCodel.MemoryLimit = &val
+		case "target", "interval", "ce_threshold":
+			var s string
+			if s, err = qdiscArg(args, i); err == nil {
+				val, err = parseTime(s)
+			}
+			switch args[i] {
+			case "target":
+				fqCodel.Target = &val
+			case "interval":
+				fqCodel.Interval = &val
+			default:
+				fqCodel.CEThreshold = &val
+			}
+		case "ecn":
+			fqCodel.ECN = ptrTo(uint32(1))
+			i--
+		case "noecn":
+			fqCodel.ECN = ptrTo(uint32(0))
+			i--
+		case "help":
+			fmt.Fprint(out, FqCodelHelp)
+			return nil, ErrExitAfterHelp
+		default:
+			return nil, fmt.Errorf("%w: fq_codel: %s", ErrInvalidArg, args[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// go-tc refuses to add a qdisc without options, so send the kernel's
+	// default of ecn if nothing else was given.
+	if *fqCodel == (tc.FqCodel{}) {
+		fqCodel.ECN = ptrTo(uint32(1))
+	}
+
+	ret := &tc.Object{}
+	ret.Kind = "fq_codel"
+	ret.FqCodel = fqCodel
+	return ret, nil
+}
+
+const FqHelp = `Usage: ... fq [ limit PACKETS ] [ flow_limit PACKETS ]
+	      [ quantum BYTES ] [ initial_quantum BYTES ]
+	      [ maxrate RATE ] [ buckets NUMBER ]
+	      [ [no]pacing ] [ refill_delay TIME ]
+	      [ low_rate_threshold RATE ]
+	      [ orphan_mask MASK ] [ timer_slack TIME ]
+	      [ ce_threshold TIME ]
+	      [ horizon TIME ] [ horizon_{cap|drop} ]
+`
+
+// ParseFqArgs parses a []string from the commandline for the fq qdisc via
+// `tc qdisc ... fq ...` and returns an *tc.Object accordingly.
+func ParseFqArgs(out io.Writer, args []string) (*tc.Object, error) {
+	fq := &tc.Fq{}
+	for i := 0; i < len(args); i = i + 2 {
+		var val uint32
+		var s string
+		var err error
+		switch args[i] {
+		case "limit":
+			val, err = parseUint32Arg(args, i)
+			fq.PLimit = &val
+		case "flow_limit":
+			val, err = parseUint32Arg(args, i)
+			fq.FlowPLimit = &val
+		case "orphan_mask":
+			val, err = parseUint32Arg(args, i)
+			fq.OrphanMask = &val
+		case "buckets":
+			if val, err = parseUint32Arg(args, i); err == nil {
+				if val == 0 || val&(val-1) != 0 {
+					return nil, fmt.Errorf("%w: fq: buckets must be a power of 2", ErrInvalidArg)
+				}
+				fq.BucketsLog = ptrTo(uint32(bits.TrailingZeros32(val)))
+			}
+		case "quantum", "initial_quantum":
+			var sz uint64
+			if s, err = qdiscArg(args, i); err == nil {
+				sz, err = ParseSize(s)
+				val = uint32(min(sz, maxUint32))
+			}
+			if args[i] == "quantum" {
+				fq.Quantum = &val
+			} else {
+				fq.InitQuantum = &val
+			}
+		case "maxrate", "low_rate_threshold":
+			var rate uint64
+			if s, err = qdiscArg(args, i); err == nil {
+				rate, err = ParseRate(s)
+				val = uint32(min(rate, maxUint32))
+			}
+			if args[i] == "maxrate" {
+				fq.FlowMaxRate = &val
+			} else {
+				fq.LowRateThreshold = &val
+			}
+		case "refill_delay", "ce_threshold", "horizon":
+			if s, err = qdiscArg(args, i); err == nil {
+				val, err = parseTime(s)
+			}
+			switch args[i] {
+			case "refill_delay":
+				fq.FlowRefillDelay = &val
+			case "ce_threshold":
+				fq.CEThreshold = &val
+			default:
+				fq.Horizon = &val
+			}
+		case "timer_slack":
+			// The kernel expects the timer slack in nanoseconds.
+			if s, err = qdiscArg(args, i); err == nil {
+				val, err = parseTime(s)
+				val *= 1000
+			}
+			fq.TimerSlack = &val
+		case "pacing":
+			fq.RateEnable = ptrTo(uint32(1))
+			i--
+		case "nopacing":
+			fq.RateEnable = ptrTo(uint32(0))
+			i--
+		case "horizon_cap":
+			fq.HorizonDrop = ptrTo(uint8(0))
+			i--
+		case "horizon_drop":
+			fq.HorizonDrop = ptrTo(uint8(1))
+			i--
+		case "help":
+			fmt.Fprint(out, FqHelp)
+			return nil, ErrExitAfterHelp
+		default:
+			return nil, fmt.Errorf("%w: fq: %s", ErrInvalidArg, args[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// go-tc refuses to add a qdisc without options, so send the kernel's
+	// default packet limit if nothing else was given.
+	if fq.PLimit == nil && fq.FlowPLimit == nil && fq.Quantum == nil &&
+		fq.InitQuantum == nil && fq.RateEnable == nil && fq.FlowMaxRate == nil &&
+		fq.BucketsLog == nil && fq.FlowRefillDelay == nil && fq.OrphanMask == nil &&
+		fq.LowRateThreshold == nil && fq.CEThreshold == nil && fq.TimerSlack == nil &&
+		fq.Horizon == nil && fq.HorizonDrop == nil {
+		fq.PLimit = ptrTo(uint32(10000))
+	}
+
+	ret := &tc.Object{}
+	ret.Kind = "fq"
+	ret.Fq = fq
+	return ret, nil
+}
+
+const SFQHelp = `Usage: ... sfq [ limit NUMBER ] [ perturb SECS ] [ quantum BYTES ]
+	       [ divisor NUMBER ] [ flows NUMBER ] [ depth NUMBER ]
+	       [ headdrop ]
+`
+
+// ParseSFQArgs parses a []string from the commandline for the sfq qdisc via
+// `tc qdisc ... sfq ...` and returns an *tc.Object accordingly.
+func ParseSFQArgs(out io.Writer, args []string) (*tc.Object, error) {
+	sfq := &tc.Sfq{}
+	for i := 0; i < len(args); i = i + 2 {
+		var err error
+		switch args[i] {
+		case "limit":
+			sfq.V0.Limit, err = parseUint32Arg(args, i)
+		case "perturb":
+			var val string
+			var p int64
+			if val, err = qdiscArg(args, i); err == nil {
+				p, err = strconv.ParseInt(strings.TrimSuffix(val, "sec"), 10, 32)
+				sfq.V0.PerturbPeriod = int32(p)
+			}
+		case "quantum":
+			var val string
+			var sz uint64
+			if val, err = qdiscArg(args, i); err == nil {
+				sz, err = ParseSize(val)
+				sfq.V0.Quantum = uint32(min(sz, maxUint32))
+			}
+		case "divisor":
+			sfq.V0.Divisor, err = parseUint32Arg(args, i)
+		case "flows":
+			sfq.V0.Flows, err = parseUint32Arg(args, i)
+		case "depth":
+			sfq.Depth, err = parseUint32Arg(args, i)
+		case "headdrop":
+			sfq.Headdrop = 1
+			i--
+		case "help":
+			fmt.Fprint(out, SFQHelp)
+			return nil, ErrExitAfterHelp
+		default:
+			return nil, fmt.Errorf("%w: sfq: %s", ErrInvalidArg, args[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := &tc.Object{}
+	ret.Kind = "sfq"
+	ret.Sfq = sfq
+	return ret, nil
+}
+
+const FIFOHelp = `Usage: ... [pb]fifo [ limit NUMBER ]
+`
+
+// Default limits of pfifo and bfifo, the usual txqueuelen of 1000 packets.
+// The kernel would fall back to the device's txqueuelen, but go-tc cannot
+// add a fifo without options.
+const (
+	defaultPFIFOLimit = 1000
+	defaultBFIFOLimit = 1000 * 1514
+)
+
+// ParsePFIFOArgs parses a []string from the commandline for the pfifo qdisc
+// via `tc qdisc ... pfifo ...` and returns an *tc.Object accordingly.
+func ParsePFIFOArgs(out io.Writer, args []string) (*tc.Object, error) {
+	return parseFIFOArgs(out, "pfifo", args)
+}
+
+// ParseBFIFOArgs parses a []string from the commandline for the bfifo qdisc
+// via `tc qdisc ... bfifo ...` and returns an *tc.Object accordingly.
+func ParseBFIFOArgs(out io.Writer, args []string) (*tc.Object, error) {
+	return parseFIFOArgs(out, "bfifo", args)
+}
+
+func parseFIFOArgs(out io.Writer, kind string, args []string) (*tc.Object, error) {
+	opt := &tc.FifoOpt{Limit: defaultPFIFOLimit}
+	if kind == "bfifo" {
+		opt.Limit = defaultBFIFOLimit
+	}
+
+	for i := 0; i < len(args); i = i + 2 {
+		switch args[i] {
+		case "limit":
+			val, err := qdiscArg(args, i)
+			if err != nil {
+				return nil, err
+			}
+			var limit uint64
+			if kind == "bfifo" {
+				limit, err = ParseSize(val)
+			} else {
+				limit, err = strconv.ParseUint(val, 10, 32)
+			}
+			if err != nil {
+				return nil, err
+			}
+			opt.Limit = uint32(min(limit, maxUint32))
+		case "help":
+			fmt.Fprint(out, FIFOHelp)
+			return nil, ErrExitAfterHelp
+		default:
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidArg, kind, args[i])
+		}
+	}
+
+	ret := &tc.Object{}
+	ret.Kind = kind
+	if kind == "bfifo" {
+		ret.Bfifo = opt
+	} else {
+		ret.Pfifo = opt
+	}
+	return ret, nil
+}
+
+const PrioHelp = `Usage: ... prio [ bands NUMBER ] [ priomap P1 P2 ... P16 ]
+`
+
+// defaultPrioMap maps the 16 Linux packet priorities to the bands of prio
+// and mqprio, as in the kernel.
+var defaultPrioMap = [16]uint8{1, 2, 2, 2, 1, 2, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1}
+
+// ParsePrioArgs parses a []string from the commandline for the prio qdisc via
+// `tc qdisc ... prio ...` and returns an *tc.Object accordingly.
+func ParsePrioArgs(out io.Writer, args []string) (*tc.Object, error) {
+	prio := &tc.Prio{
+		Bands:   3,
+		PrioMap: defaultPrioMap,
+	}
+	for i := 0; i < len(args); i++ {
+		var err error
+		switch args[i] {
+		case "bands":
+			prio.Bands, err = parseUint32Arg(args, i)
+			i++
+		case "priomap":
+			var n int
+			n, err = parsePrioMap(args[i+1:], &prio.PrioMap)
+			i += n
+		case "help":
+			fmt.Fprint(out, PrioHelp)
+			return nil, ErrExitAfterHelp
+		default:
+			return nil, fmt.Errorf("%w: prio: %s", ErrInvalidArg, args[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if prio.Bands < 2 || prio.Bands > 16 {
+		return nil, fmt.Errorf("%w: prio: bands must be between 2 and 16", ErrOutOfBounds)
+	}
+	for _, b := range prio.PrioMap {
+		if uint32(b) >= prio.Bands {
+			return nil, fmt.Errorf("%w: prio: priomap refers to band %d of %d", ErrOutOfBounds, b, prio.Bands)
+		}
+	}
+
+	ret := &tc.Object{}
+	ret.Kind = "prio"
+	ret.Prio = prio
+	return ret, nil
+}
+
+// parsePrioMap parses up to 16 leading numbers of args into m and returns how
+// many it consumed. Priorities that are not given map to band 0.
+func parsePrioMap(args []string, m *[16]uint8) (int, error) {
+	*m = [16]uint8{}
+	n := 0
+	for ; n < len(args) && n < len(m); n++ {
+		b, err := strconv.ParseUint(args[n], 10, 4)
+		if err != nil {
+			break
+		}
+		m[n] = uint8(b)
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("%w: priomap requires at least one band", ErrNotEnoughArgs)
+	}
+	return n, nil
+}
+
+// RED flags from include/uapi/linux/pkt_sched.h.
+const (
+	redECN      = 1
+	redHardDrop = 2
+	redAdaptive = 4
+)
+
+const MQPrioHelp = `Usage: ... mqprio [ num_tc NUMBER ] [ map P0 P1 ... ]
+		  [ queues count1@offset1 count2@offset2 ... ]
+		  [ hw 1|0 ] [ mode dcb|channel ] [ shaper dcb|bw_rlimit ]
+`
+
+// ParseMQPrioArgs parses a []string from the commandline for the mqprio qdisc
+// via `tc qdisc ... mqprio ...` and returns an *tc.Object accordingly.
+func ParseMQPrioArgs(out io.Writer, args []string) (*tc.Object, error) {
+	opt := &tc.MqPrioQopt{
+		NumTc:     8,
+		PrioTcMap: [16]uint8{0, 1, 2, 3, 4, 5, 6, 7},
+		Hw:        1,
+	}
+	mqprio := &tc.MqPrio{Opt: opt}
+
+	for i := 0; i < len(args); i++ {
+		var err error
+		switch args[i] {
+		case "num_tc":
+			var n uint32
+			if n, err = parseUint32Arg(args, i); err == nil && (n < 1 || n > 16) {
+				err = fmt.Errorf("%w: mqprio: num_tc must be between 1 and 16", ErrOutOfBounds)
+			}
+			opt.NumTc = uint8(n)
+			i++
+		case "map":
+			var n int
+			n, err = parsePrioMap(args[i+1:], &opt.PrioTcMap)
+			i += n
+		case "queues":
+			n := 0
+			for ; i+1 < len(args) && n < len(opt.Count); n++ {
+				c, o, ok := strings.Cut(args[i+1], "@")
+				if !ok {
+					break
+				}
+				count, cerr := strconv.ParseUint(c, 10, 16)
+				offset, oerr := strconv.ParseUint(o, 10, 16)
+				if cerr != nil || oerr != nil {
+					return nil, fmt.Errorf("%w: mqprio: invalid queue %q", ErrInvalidArg, args[i+1])
+				}
+				opt.Count[n] = uint16(count)
+				opt.Offset[n] = uint16(offset)
+				i++
+			}
+			if n == 0 {
+				err = fmt.Errorf("%w: mqprio: queues requires count@offset", ErrNotEnoughArgs)
+			}
+		case "hw":
+			var hw uint32
+			if hw, err = parseUint32Arg(args, i); err == nil && hw > 1 {
+				err = fmt.Errorf("%w: mqprio: hw must be 0 or 1", ErrInvalidArg)
+			}
+			opt.Hw = uint8(hw)
+			i++
+		case "mode", "shaper":
+			var val string
+			if val, err = qdiscArg(args, i); err != nil {
+				return nil, err
+			}
+			var v uint16
+			switch {
+			case args[i] == "mode" && val == "dcb", args[i] == "shaper" && val == "dcb":
+				v = 0
+			case args[i] == "mode" && val == "channel", args[i] == "shaper" && val == "bw_rlimit":
+				v = 1
+			default:
+				return nil, fmt.Errorf("%w: mqprio: invalid %s %q", ErrInvalidArg, args[i], val)
+			}
+			if args[i] == "mode" {
+				mqprio.Mode = &v
+			} else {
+				mqprio.Shaper = &v
+			}
+			i++
+		case "help":
+			fmt.Fprint(out, MQPrioHelp)
+			return nil, ErrExitAfterHelp
+		default:
+			return nil, fmt.Errorf("%w: mqprio: %s", ErrInvalidArg, args[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, class := range opt.PrioTcMap {
+		if class >= opt.NumTc {
+			return nil, fmt.Errorf("%w: mqprio: map refers to traffic class %d of %d", ErrOutOfBounds, class, opt.NumTc)
+		}
+	}
+
+	ret := &tc.Object{}
+	ret.Kind = "mqprio"
+	ret.MqPrio = mqprio
+	return ret, nil
+}
@@ -7,6 +7,7 @@ package trafficctl_test
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -229,3 +230,102 @@ func TestFilter(t *testing.T) {
 		t.Errorf("DeleteFilter() = %v, not nil", err)
 	}
 }
+
+func TestQdiscKinds(t *testing.T) {
+	guest.SkipIfNotInVM(t)
+
+	rtnl, err := tc.Open(&tc.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rtnl.Close()
+
+	tctl := &trafficctl.Trafficctl{Tc: rtnl}
+
+	// mqprio needs a multiqueue device, so it is only covered by the
+	// parser tests.
+	for _, tt := range []struct {
+		name   string
+		args   []string
+		change []string
+		expOut string
+	}{
+		{
+			name:   "netem",
+			args:   []string{"netem", "delay", "100ms", "10ms", "25%", "loss", "1%", "duplicate", "1%", "corrupt", "0.1%", "reorder", "25%", "50%", "rate", "1mbit"},
+			change: []string{"netem", "delay", "200ms", "loss", "5%"},
+			expOut: "qdisc netem 1: root limit 1000 delay 200ms loss 5%",
+		},
+		{
+			name:   "tbf",
+			args:   []string{"tbf", "rate", "1mbit", "burst", "32k", "latency", "400ms"},
+			expOut: "qdisc tbf 1: root rate 1Mbit",
+		},
+		{
+			name:   "fq_codel",
+			args:   []string{"fq_codel", "limit", "1024", "target", "5ms", "interval", "100ms", "ecn"},
+			expOut: "qdisc fq_codel 1: root limit 1024p",
+		},
+		{
+			name:   "fq",
+			args:   []string{"fq", "limit", "1000", "flow_limit", "50"},
+			expOut: "qdisc fq 1: root limit 1000p flow_limit 50p",
+		},
+		{
+			name:   "sfq",
+			args:   []string{"sfq", "perturb", "10"},
+			expOut: "qdisc sfq 1: root",
+		},
+		{
+			name:   "pfifo",
+			args:   []string{"pfifo", "limit", "100"},
+			expOut: "qdisc pfifo 1: root limit 100p",
+		},
+		{
+			name:   "bfifo",
+			args:   []string{"bfifo", "limit", "10k"},
+			expOut: "qdisc bfifo 1: root limit 10Kb",
+		},
+		{
+			name:   "prio",
+			args:   []string{"prio", "bands", "4"},
+			expOut: "qdisc prio 1: root bands 4",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var outbuf bytes.Buffer
+			qdiscArgs := func(args []string) *trafficctl.Args {
+				t.Helper()
+				a, err := trafficctl.ParseQdiscArgs(&outbuf, append([]string{"dev", DummyInterface0, "root", "handle", "1:"}, args...))
+				if err != nil {
+					t.Fatalf("ParseQDiscArgs(%q) = %v, not nil", args, err)
+				}
+				return a
+			}
+
+			args := qdiscArgs(tt.args)
+			if err := tctl.AddQdisc(&outbuf, args); err != nil {
+				t.Fatalf("AddQdisc() = %v, not nil", err)
+			}
+			defer func() {
+				if err := tctl.DeleteQdisc(&outbuf, args); err != nil {
+					t.Errorf("DeleteQdisc() = %v, not nil", err)
+				}
+			}()
+
+			if tt.change != nil {
+				if err := tctl.ChangeQdisc(&outbuf, qdiscArgs(tt.change)); err != nil {
+					t.Fatalf("ChangeQdisc() = %v, not nil", err)
+				}
+			}
+
+			var showbuf bytes.Buffer
+			if err := tctl.ShowQdisc(&showbuf, args); err != nil {
+				t.Fatalf("ShowQdisc() = %v, not nil", err)
+			}
+			if !strings.Contains(showbuf.String(), tt.expOut) {
+				t.Errorf("ShowQdisc() = %q, want it to contain %q", showbuf.String(), tt.expOut)
+			}
+		})
+	}
+}
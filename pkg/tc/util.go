@@ -259,6 +259,67 @@ func ParseRate(arg string) (uint64, error) {
 	return 0, ErrInvalidArg
 }
 
+// ParsePercent takes a string of the form `12.5%` and returns the equivalent
+// probability scaled to the uint32 range, as used by netem.
+func ParsePercent(s string) (uint32, error) {
+	str, ok := strings.CutSuffix(s, "%")
+	if !ok {
+		return 0, fmt.Errorf("%w: %q is not a percentage", ErrInvalidArg, s)
+	}
+	p, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p < 0 || p > 100 {
+		return 0, ErrOutOfBounds
+	}
+	return uint32(math.Round(p / 100 * maxUint32)), nil
+}
+
+// RenderPercent is the inverse of ParsePercent.
+func RenderPercent(p uint32) string {
+	return strconv.FormatFloat(float64(p)/maxUint32*100, 'g', 6, 64) + "%"
+}
+
+// RenderTime takes a time in microseconds and returns it in the format
+// `tc` prints times in, e.g. `100ms`.
+func RenderTime(usecs uint64) string {
+	t := float64(usecs)
+	switch {
+	case usecs >= TimeUnitsPerSecs:
+		return strconv.FormatFloat(t/TimeUnitsPerSecs, 'g', 3, 64) + "s"
+	case usecs >= TimeUnitsPerSecs/1000:
+		return strconv.FormatFloat(t/(TimeUnitsPerSecs/1000), 'g', 3, 64) + "ms"
+	default:
+		return fmt.Sprintf("%dus", usecs)
+	}
+}
+
+// RenderRate takes a rate in bytes per second and returns it in bits per
+// second with a decimal unit, e.g. `10Mbit`.
+func RenderRate(bytesPerSec uint64) string {
+	units := []string{"bit", "Kbit", "Mbit", "Gbit", "Tbit"}
+	r := bytesPerSec * 8
+	i := 0
+	for ; i < len(units)-1 && r >= 1000 && r%1000 == 0; i++ {
+		r /= 1000
+	}
+	return fmt.Sprintf("%d%s", r, units[i])
+}
+
+// RenderSize takes a size in bytes and returns it with a binary unit, e.g.
+// `32Kb`.
+func RenderSize(sz uint64) string {
+	switch {
+	case sz >= 1024*1024 && sz%(1024*1024) == 0:
+		return fmt.Sprintf("%dMb", sz/(1024*1024))
+	case sz >= 1024 && sz%1024 == 0:
+		return fmt.Sprintf("%dKb", sz/1024)
+	default:
+		return fmt.Sprintf("%db", sz)
+	}
+}
+
 // GetHz reads the psched rate from /proc/net/psched and returns it.
 func GetHz() (int, error) {
 	const HZdef = 100
@@ -238,3 +238,56 @@ func TestRenderProto(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePercent(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		exp   uint32
+		err   error
+	}{
+		{input: "0%", exp: 0},
+		{input: "1%", exp: 42949673},
+		{input: "0.5%", exp: 21474836},
+		{input: "100%", exp: 0xFFFF_FFFF},
+		{input: "101%", err: trafficctl.ErrOutOfBounds},
+		{input: "1", err: trafficctl.ErrInvalidArg},
+		{input: "x%", err: strconv.ErrSyntax},
+	} {
+		t.Run(tt.input, func(t *testing.T) {
+			ret, err := trafficctl.ParsePercent(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ParsePercent(%q) = %v, not %v", tt.input, err, tt.err)
+			}
+			if ret != tt.exp {
+				t.Errorf("ParsePercent(%q) = %d, not %d", tt.input, ret, tt.exp)
+			}
+			if err == nil {
+				if s := trafficctl.RenderPercent(ret); s != tt.input {
+					t.Errorf("RenderPercent(%d) = %s, not %s", ret, s, tt.input)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderUnits(t *testing.T) {
+	for _, tt := range []struct {
+		got string
+		exp string
+	}{
+		{got: trafficctl.RenderTime(50), exp: "50us"},
+		{got: trafficctl.RenderTime(100000), exp: "100ms"},
+		{got: trafficctl.RenderTime(1500000), exp: "1.5s"},
+		{got: trafficctl.RenderRate(125), exp: "1Kbit"},
+		{got: trafficctl.RenderRate(125000), exp: "1Mbit"},
+		{got: trafficctl.RenderRate(1250), exp: "10Kbit"},
+		{got: trafficctl.RenderRate(1), exp: "8bit"},
+		{got: trafficctl.RenderSize(1514), exp: "1514b"},
+		{got: trafficctl.RenderSize(32 * 1024), exp: "32Kb"},
+		{got: trafficctl.RenderSize(2 * 1024 * 1024), exp: "2Mb"},
+	} {
+		if tt.got != tt.exp {
+			t.Errorf("got %s, not %s", tt.got, tt.exp)
+		}
+	}
+}
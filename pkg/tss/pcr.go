@@ -5,6 +5,7 @@
 package tss
 
 import (
+	"crypto"
 	"fmt"
 	"io"
 
@@ -13,6 +14,15 @@ import (
 	"github.com/google/go-tpm/tpmutil"
 )
 
+// pcrBankAlgs maps the hash algorithms of the PCR banks we can read to the
+// corresponding TPM 2.0 algorithm IDs.
+var pcrBankAlgs = map[crypto.Hash]tpm2.Algorithm{
+	crypto.SHA1:   tpm2.AlgSHA1,
+	crypto.SHA256: tpm2.AlgSHA256,
+	crypto.SHA384: tpm2.AlgSHA384,
+	crypto.SHA512: tpm2.AlgSHA512,
+}
+
 func extendPCR12(rwc io.ReadWriter, pcrIndex uint32, hash [20]byte) error {
 	if _, err := tpm.PcrExtend(rwc, pcrIndex, hash); err != nil {
 		return err
@@ -13,7 +13,6 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/google/go-tpm/legacy/tpm2"
 	tpmutil "github.com/google/go-tpm/tpmutil"
 )
 
@@ -67,28 +66,40 @@ func (t *TPM) Close() error {
 
 // ReadPCRs reads all PCRs into the PCR structure
 func (t *TPM) ReadPCRs() ([]PCR, error) {
+	switch t.Version {
+	case TPMVersion12:
+		return t.ReadPCRBank(crypto.SHA1)
+	case TPMVersion20:
+		return t.ReadPCRBank(crypto.SHA256)
+	default:
+		return nil, fmt.Errorf("unsupported TPM version: %x", t.Version)
+	}
+}
+
+// ReadPCRBank reads all PCRs of the bank using hash algorithm alg.
+// TPM 1.2 devices only have a SHA1 bank.
+func (t *TPM) ReadPCRBank(alg crypto.Hash) ([]PCR, error) {
 	var PCRs map[uint32][]byte
 	var err error
-	var alg crypto.Hash
 
 	switch t.Version {
 	case TPMVersion12:
-		PCRs, err = readAllPCRs12(t.RWC)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read PCRs: %w", err)
+		if alg != crypto.SHA1 {
+			return nil, fmt.Errorf("TPM 1.2 has no %v PCR bank", alg)
 		}
-		alg = crypto.SHA1
-
+		PCRs, err = readAllPCRs12(t.RWC)
 	case TPMVersion20:
-		PCRs, err = readAllPCRs20(t.RWC, tpm2.AlgSHA256)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read PCRs: %w", err)
+		tpmAlg, ok := pcrBankAlgs[alg]
+		if !ok {
+			return nil, fmt.Errorf("unsupported PCR bank %v", alg)
 		}
-		alg = crypto.SHA1
-
+		PCRs, err = readAllPCRs20(t.RWC, tpmAlg)
 	default:
 		return nil, fmt.Errorf("unsupported TPM version: %x", t.Version)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %v PCRs: %w", alg, err)
+	}
 
 	out := make([]PCR, len(PCRs))
 	for index, digest := range PCRs {
@@ -0,0 +1,234 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtlog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+
+	"github.com/rekby/gpt"
+)
+
+// ErrEventTooShort is returned when an event is shorter than its
+// structure requires.
+var ErrEventTooShort = errors.New("event data too short")
+
+// VariableEvent is a decoded UEFI_VARIABLE_DATA structure, as measured by
+// EV_EFI_VARIABLE_DRIVER_CONFIG, EV_EFI_VARIABLE_BOOT and
+// EV_EFI_VARIABLE_AUTHORITY events. See [4] section 10.2.3.
+type VariableEvent struct {
+	VendorGUID gpt.Guid
+	Name       string
+	Data       []byte
+}
+
+// String implements fmt.Stringer.
+func (v *VariableEvent) String() string {
+	return fmt.Sprintf("%s %s (%d bytes)", v.VendorGUID, v.Name, len(v.Data))
+}
+
+// ImageLoadEvent is a decoded UEFI_IMAGE_LOAD_EVENT structure, as measured
+// by EV_EFI_BOOT_SERVICES_APPLICATION, EV_EFI_BOOT_SERVICES_DRIVER and
+// EV_EFI_RUNTIME_SERVICES_DRIVER events. See [4] section 10.2.3.
+type ImageLoadEvent struct {
+	LocationInMemory uint64
+	LengthInMemory   uint64
+	LinkTimeAddress  uint64
+	// DevicePath is the raw EFI_DEVICE_PATH_PROTOCOL of the image.
+	DevicePath []byte
+}
+
+// String implements fmt.Stringer.
+func (i *ImageLoadEvent) String() string {
+	return fmt.Sprintf("image at %#x, %d bytes", i.LocationInMemory, i.LengthInMemory)
+}
+
+// GPTEvent is a decoded UEFI_GPT_DATA structure, as measured by
+// EV_EFI_GPT_EVENT events. See [4] section 10.2.3.
+type GPTEvent struct {
+	Header     gpt.Header
+	Partitions []gpt.Partition
+}
+
+// String implements fmt.Stringer.
+func (g *GPTEvent) String() string {
+	return fmt.Sprintf("disk %s, %d partitions", g.Header.DiskGUID, len(g.Partitions))
+}
+
+// DecodeEvent decodes the event data of e into a structured form.
+//
+// It returns a *VariableEvent, *ImageLoadEvent or *GPTEvent, depending on
+// the event type. For all other event types it returns nil and no error.
+func DecodeEvent(e PCREvent) (any, error) {
+	switch EFILogID(e.PcrEventType()) {
+	case EvEFIVariableDriverConfig, EvEFIVariableBoot, EvEFIVariableAuthority:
+		return DecodeVariableEvent(e.EventData())
+	case EvEFIRuntimeServicesDriver, EvEFIBootServicesDriver, EvEFIBootServicesApplication:
+		return DecodeImageLoadEvent(e.EventData())
+	case EvEFIGPTEvent:
+		return DecodeGPTEvent(e.EventData())
+	}
+	return nil, nil
+}
+
+// DecodeVariableEvent decodes a UEFI_VARIABLE_DATA structure.
+func DecodeVariableEvent(data []byte) (*VariableEvent, error) {
+	var v VariableEvent
+	var hdr struct {
+		VendorGUID         gpt.Guid
+		UnicodeNameLength  uint64
+		VariableDataLength uint64
+	}
+	r := bytes.NewReader(data)
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return nil, fmt.Errorf("%w: variable header: %w", ErrEventTooShort, err)
+	}
+	// The lengths come from the event log and are checked one at a time,
+	// as adding them up could overflow.
+	if hdr.UnicodeNameLength > uint64(r.Len())/2 {
+		return nil, fmt.Errorf("%w: variable needs %d UTF-16 characters of name, have %d bytes",
+			ErrEventTooShort, hdr.UnicodeNameLength, r.Len())
+	}
+	if hdr.VariableDataLength > uint64(r.Len())-2*hdr.UnicodeNameLength {
+		return nil, fmt.Errorf("%w: variable needs %d bytes of data, have %d",
+			ErrEventTooShort, hdr.VariableDataLength, uint64(r.Len())-2*hdr.UnicodeNameLength)
+	}
+
+	name := make([]uint16, hdr.UnicodeNameLength)
+	if err := binary.Read(r, binary.LittleEndian, name); err != nil {
+		return nil, err
+	}
+	v.VendorGUID = hdr.VendorGUID
+	v.Name = string(utf16.Decode(name))
+	v.Data = make([]byte, hdr.VariableDataLength)
+	if _, err := r.Read(v.Data); err != nil && hdr.VariableDataLength > 0 {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// DecodeImageLoadEvent decodes a UEFI_IMAGE_LOAD_EVENT structure.
+func DecodeImageLoadEvent(data []byte) (*ImageLoadEvent, error) {
+	var hdr struct {
+		LocationInMemory   uint64
+		LengthInMemory     uint64
+		LinkTimeAddress    uint64
+		LengthOfDevicePath uint64
+	}
+	r := bytes.NewReader(data)
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return nil, fmt.Errorf("%w: image load header: %w", ErrEventTooShort, err)
+	}
+	if hdr.LengthOfDevicePath > uint64(r.Len()) {
+		return nil, fmt.Errorf("%w: device path needs %d bytes, have %d", ErrEventTooShort, hdr.LengthOfDevicePath, r.Len())
+	}
+
+	i := &ImageLoadEvent{
+		LocationInMemory: hdr.LocationInMemory,
+		LengthInMemory:   hdr.LengthInMemory,
+		LinkTimeAddress:  hdr.LinkTimeAddress,
+		DevicePath:       make([]byte, hdr.LengthOfDevicePath),
+	}
+	copy(i.DevicePath, data[len(data)-r.Len():])
+	return i, nil
+}
+
+// gptHeaderSize is the size of UEFI_PARTITION_TABLE_HEADER without the
+// reserved space that pads it to a full block.
+const gptHeaderSize = 92
+
+// DecodeGPTEvent decodes a UEFI_GPT_DATA structure.
+func DecodeGPTEvent(data []byte) (*GPTEvent, error) {
+	var hdr struct {
+		Signature               [8]byte
+		Revision                uint32
+		Size                    uint32
+		CRC                     uint32
+		Reserved                uint32
+		HeaderStartLBA          uint64
+		HeaderCopyStartLBA      uint64
+		FirstUsableLBA          uint64
+		LastUsableLBA           uint64
+		DiskGUID                gpt.Guid
+		PartitionsTableStartLBA uint64
+		PartitionsArrLen        uint32
+		PartitionEntrySize      uint32
+		PartitionsCRC           uint32
+	}
+	r := bytes.NewReader(data)
+	if err := binary.Read(r, binary.LittleEndian, &hdr); err != nil {
+		return nil, fmt.Errorf("%w: gpt header: %w", ErrEventTooShort, err)
+	}
+	// The header is measured with its full Size, which may exceed the
+	// fields defined above.
+	if hdr.Size > gptHeaderSize {
+		if _, err := r.Seek(int64(hdr.Size-gptHeaderSize), io.SeekCurrent); err != nil {
+			return nil, err
+		}
+	}
+
+	var n uint64
+	if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+		return nil, fmt.Errorf("%w: number of partitions: %w", ErrEventTooShort, err)
+	}
+	var part struct {
+		Type          gpt.PartType
+		ID            gpt.Guid
+		FirstLBA      uint64
+		LastLBA       uint64
+		Flags         gpt.Flags
+		PartNameUTF16 [72]byte
+	}
+	entrySize := uint64(binary.Size(part))
+	if uint64(hdr.PartitionEntrySize) > entrySize {
+		entrySize = uint64(hdr.PartitionEntrySize)
+	}
+	if n > uint64(r.Len())/entrySize {
+		return nil, fmt.Errorf("%w: %d partitions of %d bytes, have %d bytes", ErrEventTooShort, n, entrySize, r.Len())
+	}
+
+	g := &GPTEvent{
+		Header: gpt.Header{
+			Signature:               hdr.Signature,
+			Revision:                hdr.Revision,
+			Size:                    hdr.Size,
+			CRC:                     hdr.CRC,
+			Reserved:                hdr.Reserved,
+			HeaderStartLBA:          hdr.HeaderStartLBA,
+			HeaderCopyStartLBA:      hdr.HeaderCopyStartLBA,
+			FirstUsableLBA:          hdr.FirstUsableLBA,
+			LastUsableLBA:           hdr.LastUsableLBA,
+			DiskGUID:                hdr.DiskGUID,
+			PartitionsTableStartLBA: hdr.PartitionsTableStartLBA,
+			PartitionsArrLen:        hdr.PartitionsArrLen,
+			PartitionEntrySize:      hdr.PartitionEntrySize,
+			PartitionsCRC:           hdr.PartitionsCRC,
+		},
+	}
+	for range n {
+		start := r.Len()
+		if err := binary.Read(r, binary.LittleEndian, &part); err != nil {
+			return nil, err
+		}
+		trailing := make([]byte, entrySize-uint64(start-r.Len()))
+		if _, err := r.Read(trailing); err != nil && len(trailing) > 0 {
+			return nil, err
+		}
+		g.Partitions = append(g.Partitions, gpt.Partition{
+			Type:          part.Type,
+			Id:            part.ID,
+			FirstLBA:      part.FirstLBA,
+			LastLBA:       part.LastLBA,
+			Flags:         part.Flags,
+			PartNameUTF16: part.PartNameUTF16,
+			TrailingBytes: trailing,
+		})
+	}
+	return g, nil
+}
@@ -186,6 +186,10 @@ func (e *TcgPcrEvent) Digests() *[]PCRDigestValue {
 	return &d
 }
 
+func (e *TcgPcrEvent) EventData() []byte {
+	return e.event
+}
+
 func (e *TcgPcrEvent) String() string {
 	var b strings.Builder
 
@@ -280,6 +284,10 @@ func (e *TcgPcrEvent2) Digests() *[]PCRDigestValue {
 	return &d
 }
 
+func (e *TcgPcrEvent2) EventData() []byte {
+	return e.event
+}
+
 func (e *TcgPcrEvent2) String() string {
 	var b strings.Builder
 
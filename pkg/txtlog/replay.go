@@ -0,0 +1,330 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtlog
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha1"   // Register SHA1 for crypto.Hash.New.
+	_ "crypto/sha256" // Register SHA256 for crypto.Hash.New.
+	_ "crypto/sha512" // Register SHA384 and SHA512 for crypto.Hash.New.
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	tss "github.com/u-root/u-root/pkg/tss"
+)
+
+var (
+	// ErrUnsupportedAlg is returned when replaying a bank whose hash
+	// algorithm is not implemented.
+	ErrUnsupportedAlg = errors.New("unsupported hash algorithm")
+
+	// ErrMissingDigest is returned when an event has no digest for the
+	// bank being replayed.
+	ErrMissingDigest = errors.New("event has no digest for bank")
+)
+
+// startupLocalitySignature identifies the EV_NO_ACTION event that records
+// the locality PCR 0 was reset in, see [4] section 9.4.5.3.
+const startupLocalitySignature = "StartupLocality\x00"
+
+// Hash returns the crypto.Hash corresponding to a.
+func (a IAlgHash) Hash() (crypto.Hash, error) {
+	switch a {
+	case TPMAlgSha:
+		return crypto.SHA1, nil
+	case TPMAlgSha256:
+		return crypto.SHA256, nil
+	case TPMAlgSha384:
+		return crypto.SHA384, nil
+	case TPMAlgSha512:
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("%w: %#x", ErrUnsupportedAlg, uint16(a))
+}
+
+// String implements fmt.Stringer.
+func (a IAlgHash) String() string {
+	switch a {
+	case TPMAlgSha:
+		return "SHA1"
+	case TPMAlgSha256:
+		return "SHA256"
+	case TPMAlgSha384:
+		return "SHA384"
+	case TPMAlgSha512:
+		return "SHA512"
+	case TPMAlgSm3s256:
+		return "SM3_256"
+	}
+	return fmt.Sprintf("IAlgHash(%#x)", uint16(a))
+}
+
+// Banks returns the hash algorithms the events in the log carry digests
+// for, in ascending order of algorithm ID.
+func (l *PCRLog) Banks() []IAlgHash {
+	var algs []IAlgHash
+	for _, e := range l.PcrList {
+		for _, d := range *e.Digests() {
+			if !slices.Contains(algs, d.DigestAlg) {
+				algs = append(algs, d.DigestAlg)
+			}
+		}
+	}
+	slices.Sort(algs)
+	return algs
+}
+
+// PCRBank maps PCR indices to PCR values of one hash algorithm.
+type PCRBank map[int][]byte
+
+func eventDigest(e PCREvent, alg IAlgHash) ([]byte, bool) {
+	for _, d := range *e.Digests() {
+		if d.DigestAlg == alg {
+			return d.Digest, true
+		}
+	}
+	return nil, false
+}
+
+// replay extends the events in l into a fresh bank. visit, if not nil, is
+// called with the PCR value before and after every extended event.
+func replay(l *PCRLog, alg IAlgHash, visit func(i int, e PCREvent, before, after []byte)) (PCRBank, error) {
+	h, err := alg.Hash()
+	if err != nil {
+		return nil, err
+	}
+	bank := PCRBank{}
+	pcr := func(i int) []byte {
+		if _, ok := bank[i]; !ok {
+			bank[i] = make([]byte, h.Size())
+		}
+		return bank[i]
+	}
+
+	for i, e := range l.PcrList {
+		if BIOSLogID(e.PcrEventType()) == EvNoAction {
+			// EV_NO_ACTION events are not extended, but one
+			// tells us the initial value of PCR 0.
+			data := e.EventData()
+			if e.PcrIndex() == 0 && len(data) > len(startupLocalitySignature) &&
+				string(data[:len(startupLocalitySignature)]) == startupLocalitySignature {
+				pcr(0)[h.Size()-1] = data[len(startupLocalitySignature)]
+			}
+			continue
+		}
+
+		d, ok := eventDigest(e, alg)
+		if !ok {
+			return nil, fmt.Errorf("event %d (%s): %w %v", i, e.PcrEventName(), ErrMissingDigest, alg)
+		}
+		before := pcr(e.PcrIndex())
+		hh := h.New()
+		hh.Write(before)
+		hh.Write(d)
+		bank[e.PcrIndex()] = hh.Sum(nil)
+		if visit != nil {
+			visit(i, e, before, bank[e.PcrIndex()])
+		}
+	}
+	return bank, nil
+}
+
+// Replay returns the PCR values the events in l produce in the bank of
+// hash algorithm alg, starting from reset PCRs.
+//
+// Only PCRs that have at least one event, or whose initial value is set
+// by a StartupLocality event, are part of the result.
+func Replay(l *PCRLog, alg IAlgHash) (PCRBank, error) {
+	return replay(l, alg, nil)
+}
+
+// Mismatch describes a PCR whose value in the TPM differs from the value
+// replayed from the event log.
+type Mismatch struct {
+	PCR      int
+	Alg      IAlgHash
+	Replayed []byte
+	TPM      []byte
+
+	// EventIndex is the index in PCRLog.PcrList of the first event that
+	// could not be reconciled with the TPM, and Event is that event. If
+	// no event can be blamed, e.g. because the TPM was extended with
+	// something that is not in the log, EventIndex is -1 and Event is
+	// nil.
+	EventIndex int
+	Event      PCREvent
+
+	// Reason explains why Event was blamed.
+	Reason string
+}
+
+// String implements fmt.Stringer.
+func (m *Mismatch) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "PCR %d (%v): log replays to %x, TPM has %x", m.PCR, m.Alg, m.Replayed, m.TPM)
+	if m.Event != nil {
+		fmt.Fprintf(&b, "; first divergent event %d (%s): %s", m.EventIndex, m.Event.PcrEventName(), m.Reason)
+	} else {
+		fmt.Fprintf(&b, "; %s", m.Reason)
+	}
+	return b.String()
+}
+
+// Verify replays the bank of hash algorithm alg and compares the result
+// to the PCR values pcrs read from a TPM, e.g. by tss.TPM.ReadPCRBank.
+//
+// PCRs without events in the log are not compared. The returned mismatches
+// are sorted by the position of the blamed event in the log, so the first
+// one is where the log and the TPM diverge first.
+func Verify(l *PCRLog, alg IAlgHash, pcrs []tss.PCR) ([]Mismatch, error) {
+	h, err := alg.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	// history records the value of each PCR before each of its events.
+	type step struct {
+		index  int
+		event  PCREvent
+		before []byte
+	}
+	history := map[int][]step{}
+	bank, err := replay(l, alg, func(i int, e PCREvent, before, _ []byte) {
+		history[e.PcrIndex()] = append(history[e.PcrIndex()], step{i, e, before})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []Mismatch
+	for _, p := range pcrs {
+		replayed, ok := bank[p.Index]
+		if !ok {
+			continue
+		}
+		if p.DigestAlg != h {
+			return nil, fmt.Errorf("PCR %d is from the %v bank, want %v", p.Index, p.DigestAlg, h)
+		}
+		if bytes.Equal(replayed, p.Digest) {
+			continue
+		}
+
+		m := Mismatch{
+			PCR:        p.Index,
+			Alg:        alg,
+			Replayed:   replayed,
+			TPM:        p.Digest,
+			EventIndex: -1,
+			Reason:     "TPM was extended with measurements that are not in the log",
+		}
+		steps := history[p.Index]
+		// If the TPM stopped at an intermediate value, the events after
+		// it were logged but never measured.
+		for _, s := range steps {
+			if bytes.Equal(s.before, p.Digest) {
+				m.EventIndex, m.Event = s.index, s.event
+				m.Reason = "event is in the log but was not extended into the TPM"
+				break
+			}
+		}
+		// Otherwise, blame the first event whose digest does not
+		// match its own data.
+		if m.Event == nil {
+			for _, s := range steps {
+				if err := checkEventDigest(s.event, alg); err != nil {
+					m.EventIndex, m.Event = s.index, s.event
+					m.Reason = err.Error()
+					break
+				}
+			}
+		}
+		ms = append(ms, m)
+	}
+
+	slices.SortStableFunc(ms, func(a, b Mismatch) int {
+		// Mismatches without a blamed event (-1) go last.
+		ai, bi := uint(a.EventIndex), uint(b.EventIndex)
+		if ai != bi {
+			if ai < bi {
+				return -1
+			}
+			return 1
+		}
+		return a.PCR - b.PCR
+	})
+	return ms, nil
+}
+
+// VerifyTPM verifies every bank present in l against the PCRs of t.
+func VerifyTPM(l *PCRLog, t *tss.TPM) ([]Mismatch, error) {
+	var ms []Mismatch
+	for _, alg := range l.Banks() {
+		h, err := alg.Hash()
+		if err != nil {
+			return nil, err
+		}
+		pcrs, err := t.ReadPCRBank(h)
+		if err != nil {
+			return nil, err
+		}
+		m, err := Verify(l, alg, pcrs)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m...)
+	}
+	return ms, nil
+}
+
+// checkEventDigest checks the digest of events whose digest is defined to
+// be the hash of the event data. It returns nil for events it cannot check.
+func checkEventDigest(e PCREvent, alg IAlgHash) error {
+	d, ok := eventDigest(e, alg)
+	if !ok {
+		return nil
+	}
+	h, err := alg.Hash()
+	if err != nil {
+		return err
+	}
+	sum := func(b []byte) []byte {
+		hh := h.New()
+		hh.Write(b)
+		return hh.Sum(nil)
+	}
+
+	data := e.EventData()
+	var candidates [][]byte
+	switch t := e.PcrEventType(); {
+	case BIOSLogID(t) == EvSeparator, BIOSLogID(t) == EvAction,
+		EFILogID(t) == EvEFIAction, EFILogID(t) == EvEFIGPTEvent,
+		EFILogID(t) == EvEFIVariableDriverConfig:
+		candidates = append(candidates, sum(data))
+	case EFILogID(t) == EvEFIVariableBoot, EFILogID(t) == EvEFIVariableAuthority:
+		// Depending on the firmware, either the whole
+		// UEFI_VARIABLE_DATA or only the variable contents are
+		// measured.
+		candidates = append(candidates, sum(data))
+		if v, err := DecodeVariableEvent(data); err == nil {
+			candidates = append(candidates, sum(v.Data))
+		}
+	default:
+		return nil
+	}
+
+	for _, c := range candidates {
+		if bytes.Equal(c, d) {
+			return nil
+		}
+	}
+	desc := e.PcrEventData()
+	if decoded, err := DecodeEvent(e); err == nil && decoded != nil {
+		desc = fmt.Sprint(decoded)
+	}
+	return fmt.Errorf("digest %x does not match event data %q", d, stripControlSequences(desc))
+}
@@ -0,0 +1,316 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package txtlog
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/rekby/gpt"
+	tss "github.com/u-root/u-root/pkg/tss"
+)
+
+// newEvent returns an agile event measuring data into pcr in the SHA1 and
+// SHA256 banks.
+func newEvent(pcr uint32, typ uint32, data []byte) *TcgPcrEvent2 {
+	s1 := sha1.Sum(data)
+	s256 := sha256.Sum256(data)
+	return &TcgPcrEvent2{
+		pcrIndex:  pcr,
+		eventType: typ,
+		digests: LDigestValues{
+			count: 2,
+			digests: []THA{
+				{hashAlg: TPMAlgSha, digest: IHA{hash: s1[:]}},
+				{hashAlg: TPMAlgSha256, digest: IHA{hash: s256[:]}},
+			},
+		},
+		eventSize: uint32(len(data)),
+		event:     data,
+	}
+}
+
+func extend(h crypto.Hash, pcr []byte, events ...*TcgPcrEvent2) []byte {
+	alg := TPMAlgSha
+	if h == crypto.SHA256 {
+		alg = TPMAlgSha256
+	}
+	for _, e := range events {
+		d, _ := eventDigest(e, alg)
+		hh := h.New()
+		hh.Write(pcr)
+		hh.Write(d)
+		pcr = hh.Sum(nil)
+	}
+	return pcr
+}
+
+func variableData(name string, data []byte) []byte {
+	var b bytes.Buffer
+	guid := gpt.Guid{0x61, 0xdf, 0xe4, 0x8b, 0xca, 0x93, 0xd2, 0x11, 0xaa, 0x0d, 0x00, 0xe0, 0x98, 0x03, 0x2b, 0x8c}
+	u := utf16.Encode([]rune(name))
+	binary.Write(&b, binary.LittleEndian, guid)
+	binary.Write(&b, binary.LittleEndian, uint64(len(u)))
+	binary.Write(&b, binary.LittleEndian, uint64(len(data)))
+	binary.Write(&b, binary.LittleEndian, u)
+	b.Write(data)
+	return b.Bytes()
+}
+
+func testLog() (*PCRLog, []*TcgPcrEvent2) {
+	locality := append([]byte(startupLocalitySignature), 3)
+	events := []*TcgPcrEvent2{
+		{pcrIndex: 0, eventType: uint32(EvNoAction), event: locality, eventSize: uint32(len(locality))},
+		newEvent(0, uint32(EvSCRTMVersion), []byte("1.0")),
+		newEvent(7, uint32(EvEFIVariableDriverConfig), variableData("SecureBoot", []byte{1})),
+		newEvent(0, uint32(EvSeparator), []byte{0, 0, 0, 0}),
+		newEvent(7, uint32(EvSeparator), []byte{0, 0, 0, 0}),
+		newEvent(4, uint32(EvEFIBootServicesApplication), make([]byte, 32)),
+	}
+	l := &PCRLog{Firmware: Uefi}
+	for _, e := range events {
+		l.PcrList = append(l.PcrList, e)
+	}
+	return l, events
+}
+
+func TestReplay(t *testing.T) {
+	l, events := testLog()
+
+	if got, want := l.Banks(), []IAlgHash{TPMAlgSha, TPMAlgSha256}; !equalAlgs(got, want) {
+		t.Errorf("Banks() = %v, want %v", got, want)
+	}
+
+	bank, err := Replay(l, TPMAlgSha256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcr0 := make([]byte, sha256.Size)
+	pcr0[sha256.Size-1] = 3
+	want := PCRBank{
+		0: extend(crypto.SHA256, pcr0, events[1], events[3]),
+		4: extend(crypto.SHA256, make([]byte, sha256.Size), events[5]),
+		7: extend(crypto.SHA256, make([]byte, sha256.Size), events[2], events[4]),
+	}
+	if len(bank) != len(want) {
+		t.Fatalf("Replay() = %d PCRs, want %d", len(bank), len(want))
+	}
+	for i, w := range want {
+		if !bytes.Equal(bank[i], w) {
+			t.Errorf("Replay() PCR %d = %x, want %x", i, bank[i], w)
+		}
+	}
+
+	if _, err := Replay(l, TPMAlgSha384); !errors.Is(err, ErrMissingDigest) {
+		t.Errorf("Replay(SHA384) = %v, want %v", err, ErrMissingDigest)
+	}
+	if _, err := Replay(l, TPMAlgSm3s256); !errors.Is(err, ErrUnsupportedAlg) {
+		t.Errorf("Replay(SM3) = %v, want %v", err, ErrUnsupportedAlg)
+	}
+}
+
+func equalAlgs(a, b []IAlgHash) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func tpmPCRs(h crypto.Hash, bank PCRBank) []tss.PCR {
+	var pcrs []tss.PCR
+	for i := range 24 {
+		d, ok := bank[i]
+		if !ok {
+			d = make([]byte, h.Size())
+		}
+		pcrs = append(pcrs, tss.PCR{Index: i, Digest: d, DigestAlg: h})
+	}
+	return pcrs
+}
+
+func TestVerify(t *testing.T) {
+	l, events := testLog()
+	good, err := Replay(l, TPMAlgSha)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("match", func(t *testing.T) {
+		ms, err := Verify(l, TPMAlgSha, tpmPCRs(crypto.SHA1, good))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ms) != 0 {
+			t.Errorf("Verify() = %v, want no mismatches", ms)
+		}
+	})
+
+	t.Run("unmeasured event", func(t *testing.T) {
+		// The TPM never saw the separator in PCR 7.
+		bank := PCRBank{0: good[0], 4: good[4], 7: extend(crypto.SHA1, make([]byte, sha1.Size), events[2])}
+		ms, err := Verify(l, TPMAlgSha, tpmPCRs(crypto.SHA1, bank))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ms) != 1 || ms[0].PCR != 7 || ms[0].EventIndex != 4 {
+			t.Errorf("Verify() = %v, want PCR 7 blaming event 4", ms)
+		}
+	})
+
+	t.Run("tampered event", func(t *testing.T) {
+		// The log claims SecureBoot=1 but the digest is for SecureBoot=0.
+		tampered, tevents := testLog()
+		tevents[2].event = variableData("SecureBoot", []byte{0})
+		ms, err := Verify(tampered, TPMAlgSha, tpmPCRs(crypto.SHA1, PCRBank{0: good[0], 4: good[4], 7: []byte("not the real value!!")}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ms) != 1 || ms[0].EventIndex != 2 {
+			t.Fatalf("Verify() = %v, want PCR 7 blaming event 2", ms)
+		}
+		v, ok := mustDecode(t, ms[0].Event).(*VariableEvent)
+		if !ok || v.Name != "SecureBoot" {
+			t.Errorf("DecodeEvent() = %v, want SecureBoot variable", v)
+		}
+	})
+
+	t.Run("sorted by event", func(t *testing.T) {
+		bank := PCRBank{0: []byte("bogus"), 4: []byte("bogus"), 7: extend(crypto.SHA1, make([]byte, sha1.Size), events[2])}
+		ms, err := Verify(l, TPMAlgSha, tpmPCRs(crypto.SHA1, bank))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ms) != 3 {
+			t.Fatalf("Verify() = %v, want 3 mismatches", ms)
+		}
+		if ms[0].PCR != 7 || ms[1].EventIndex != -1 || ms[2].EventIndex != -1 {
+			t.Errorf("Verify() = %v, want PCR 7 first and unexplained mismatches last", ms)
+		}
+	})
+
+	t.Run("wrong bank", func(t *testing.T) {
+		if _, err := Verify(l, TPMAlgSha, tpmPCRs(crypto.SHA256, good)); err == nil {
+			t.Error("Verify() with SHA256 PCRs for the SHA1 bank succeeded, want error")
+		}
+	})
+}
+
+func mustDecode(t *testing.T, e PCREvent) any {
+	t.Helper()
+	v, err := DecodeEvent(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestDecodeEvent(t *testing.T) {
+	var img bytes.Buffer
+	binary.Write(&img, binary.LittleEndian, []uint64{0x1000, 0x2000, 0, 4})
+	img.Write([]byte{0x7f, 0xff, 0x04, 0x00})
+
+	var gptData bytes.Buffer
+	hdr := make([]byte, gptHeaderSize)
+	copy(hdr, "EFI PART")
+	binary.LittleEndian.PutUint32(hdr[12:], gptHeaderSize)
+	copy(hdr[56:], []byte{1, 2, 3, 4})
+	binary.LittleEndian.PutUint32(hdr[84:], 128)
+	gptData.Write(hdr)
+	binary.Write(&gptData, binary.LittleEndian, uint64(1))
+	part := make([]byte, 128)
+	binary.LittleEndian.PutUint64(part[32:], 2048)
+	copy(part[56:], []byte{'E', 0, 'S', 0, 'P', 0})
+	gptData.Write(part)
+
+	for _, tt := range []struct {
+		name  string
+		event PCREvent
+		check func(t *testing.T, v any)
+	}{
+		{
+			name:  "variable",
+			event: newEvent(7, uint32(EvEFIVariableBoot), variableData("BootOrder", []byte{1, 0})),
+			check: func(t *testing.T, v any) {
+				ve := v.(*VariableEvent)
+				if ve.Name != "BootOrder" || !bytes.Equal(ve.Data, []byte{1, 0}) {
+					t.Errorf("got %+v", ve)
+				}
+				if got, want := ve.VendorGUID.String(), "8BE4DF61-93CA-11D2-AA0D-00E098032B8C"; got != want {
+					t.Errorf("VendorGUID = %s, want %s", got, want)
+				}
+			},
+		},
+		{
+			name:  "image load",
+			event: newEvent(4, uint32(EvEFIBootServicesApplication), img.Bytes()),
+			check: func(t *testing.T, v any) {
+				ie := v.(*ImageLoadEvent)
+				if ie.LocationInMemory != 0x1000 || ie.LengthInMemory != 0x2000 || !bytes.Equal(ie.DevicePath, []byte{0x7f, 0xff, 0x04, 0x00}) {
+					t.Errorf("got %+v", ie)
+				}
+			},
+		},
+		{
+			name:  "gpt",
+			event: newEvent(5, uint32(EvEFIGPTEvent), gptData.Bytes()),
+			check: func(t *testing.T, v any) {
+				ge := v.(*GPTEvent)
+				if string(ge.Header.Signature[:]) != "EFI PART" || len(ge.Partitions) != 1 {
+					t.Fatalf("got %+v", ge)
+				}
+				if p := ge.Partitions[0]; p.FirstLBA != 2048 || p.Name() != "ESP" {
+					t.Errorf("partition = %+v", p)
+				}
+			},
+		},
+		{
+			name:  "other",
+			event: newEvent(0, uint32(EvSeparator), []byte{0, 0, 0, 0}),
+			check: func(t *testing.T, v any) {
+				if v != nil {
+					t.Errorf("got %v, want nil", v)
+				}
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, mustDecode(t, tt.event))
+		})
+	}
+
+	if _, err := DecodeEvent(newEvent(7, uint32(EvEFIVariableBoot), []byte{1, 2, 3})); !errors.Is(err, ErrEventTooShort) {
+		t.Errorf("DecodeEvent(short) = %v, want %v", err, ErrEventTooShort)
+	}
+
+	// Lengths whose sum or product overflows must not pass the checks.
+	oversized := func(name, data uint64) []byte {
+		b := make([]byte, 32, 36)
+		binary.LittleEndian.PutUint64(b[16:], name)
+		binary.LittleEndian.PutUint64(b[24:], data)
+		return append(b, 'a', 0, 'b', 0)
+	}
+	manyParts := bytes.Clone(gptData.Bytes()[:gptHeaderSize+8])
+	binary.LittleEndian.PutUint64(manyParts[gptHeaderSize:], 1<<57)
+	for _, e := range []*TcgPcrEvent2{
+		newEvent(7, uint32(EvEFIVariableBoot), oversized(1<<63, 0)),
+		newEvent(7, uint32(EvEFIVariableBoot), oversized(1, 1<<64-1)),
+		newEvent(5, uint32(EvEFIGPTEvent), manyParts),
+	} {
+		if _, err := DecodeEvent(e); !errors.Is(err, ErrEventTooShort) {
+			t.Errorf("DecodeEvent(%x) = %v, want %v", e.EventData(), err, ErrEventTooShort)
+		}
+	}
+}
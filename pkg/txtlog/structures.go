@@ -186,6 +186,7 @@ type PCREvent interface {
 	PcrEventName() string
 	PcrEventData() string
 	Digests() *[]PCRDigestValue
+	EventData() []byte
 	String() string
 }
 
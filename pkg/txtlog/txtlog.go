@@ -44,21 +44,42 @@ var HashAlgoToSize = map[IAlgHash]IAlgHashSize{
 	TPMAlgSm3s256: TPMAlgSm3s256Size,
 }
 
+// ParseLog parses the event log at DefaultTCPABinaryLog.
 func ParseLog(firmware FirmwareType, tpmSpec tss.TPMVersion) (*PCRLog, error) {
+	file, err := os.Open(DefaultTCPABinaryLog)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseLogReader(file, firmware, tpmSpec)
+}
+
+// ParseLogData parses an event log held in memory, e.g. the one returned by
+// tss.TPM.MeasurementLog.
+func ParseLogData(data []byte, firmware FirmwareType, tpmSpec tss.TPMVersion) (*PCRLog, error) {
+	return ParseLogReader(bytes.NewReader(data), firmware, tpmSpec)
+}
+
+// ParseLogReader parses an event log from handle.
+func ParseLogReader(handle io.ReadSeeker, firmware FirmwareType, tpmSpec tss.TPMVersion) (*PCRLog, error) {
 	var pcrLog *PCRLog
 	var err error
 
 	switch tpmSpec {
 	case tss.TPMVersion12:
-		pcrLog, err = readTPM1Log(firmware)
+		pcrLog, err = readTPM1Log(handle, firmware)
 		if err != nil {
 			return nil, err
 		}
 	case tss.TPMVersion20:
-		pcrLog, err = readTPM2Log(firmware)
+		pcrLog, err = readTPM2Log(handle, firmware)
 		if err != nil {
 			// Kernel eventlog workaround does not export agile measurement log..
-			pcrLog, err = readTPM1Log(firmware)
+			if _, err := handle.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+			pcrLog, err = readTPM1Log(handle, firmware)
 			if err != nil {
 				return nil, err
 			}
@@ -80,20 +101,12 @@ func DumpLog(tcpaLog *PCRLog) error {
 	return nil
 }
 
-func readTPM1Log(firmware FirmwareType) (*PCRLog, error) {
+func readTPM1Log(file io.ReadSeeker, firmware FirmwareType) (*PCRLog, error) {
 	var pcrLog PCRLog
 
-	file, err := os.Open(DefaultTCPABinaryLog)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
 	pcrLog.Firmware = firmware
 
 	if firmware == "TXT" {
-		var pcrLog PCRLog
-
 		container, err := readTxtEventLogContainer(file)
 		if err != nil {
 			return nil, err
@@ -139,15 +152,10 @@ func readTPM1Log(firmware FirmwareType) (*PCRLog, error) {
 	return &pcrLog, nil
 }
 
-func readTPM2Log(firmware FirmwareType) (*PCRLog, error) {
+func readTPM2Log(file io.Reader, firmware FirmwareType) (*PCRLog, error) {
 	var pcrLog PCRLog
 	var pcrEvent *TcgPcrEvent
-
-	file, err := os.Open(DefaultTCPABinaryLog)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+	var err error
 
 	pcrLog.Firmware = firmware
 
@@ -22,7 +22,8 @@ func ArgsToGoArgs(args []string) []string {
 			out = append(out, f[1:])
 			continue
 		}
-		if strings.HasPrefix(f, "-") {
+		// A lone "-" conventionally means stdin or stdout and is not a flag.
+		if strings.HasPrefix(f, "-") && f != "-" {
 			fs := strings.SplitSeq(f[1:], "")
 			for ff := range fs {
 				out = append(out, "-"+ff)
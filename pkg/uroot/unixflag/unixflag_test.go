@@ -25,6 +25,7 @@ func TestArgsToGoArgs(t *testing.T) {
 		{name: "-long --short etc", args: []string{"-long", "--short", "etc"}, out: []string{"-l", "-o", "-n", "-g", "-short", "etc"}},
 		{name: "-long --short etc -long ", args: []string{"-long", "--short", "etc", "-long"}, out: []string{"-l", "-o", "-n", "-g", "-short", "etc", "-long"}},
 		{name: "-aux", args: []string{"-aux"}, out: []string{"-a", "-u", "-x"}},
+		{name: "-f - -n", args: []string{"-f", "-", "-n"}, out: []string{"-f", "-", "-n"}},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			out := unixflag.ArgsToGoArgs(tt.args)
@@ -0,0 +1,250 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vpd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// The VPD 2.0 binary format, as implemented by the Chrome OS vpd tool and
+// coreboot's drivers/vpd.
+//
+// A VPD region starts with a google_vpd_info header, followed by a list of
+// type-length-value encoded key/value pairs:
+//
+//	+------+---------+-----+-----------+-------+
+//	| type | key len | key | value len | value | ...
+//	+------+---------+-----+-----------+-------+
+//
+// Lengths are variable-length integers: 7 bits per byte, most significant
+// group first, with the top bit set on every byte but the last.
+//
+// References:
+// * https://chromium.googlesource.com/chromiumos/platform/vpd/+/HEAD/lib/lib_vpd.c
+// * https://github.com/coreboot/coreboot/blob/main/src/drivers/vpd/vpd_tables.h
+
+// Entry types.
+const (
+	typeTerminator         = 0x00
+	typeString             = 0x01
+	typeInfo               = 0xfe
+	typeImplicitTerminator = 0xff
+)
+
+const (
+	// infoMagic is the TLV header of the google_vpd_info structure: an
+	// info entry with the 9 byte key "\x01gVpdInfo" and a 4 byte value,
+	// the size of the VPD data that follows.
+	infoMagic = "\xfe\x09\x01gVpdInfo\x04"
+	infoSize  = len(infoMagic) + 4
+
+	// legacyOffset is where VPD 2.0 data starts in RO_VPD regions that
+	// begin with an SMBIOS entry point (VPD 1.x layout).
+	legacyOffset = 0x600
+)
+
+var (
+	// ErrNoVPD is returned when a region does not contain VPD.
+	ErrNoVPD = errors.New("no VPD info header found")
+
+	// ErrTooLarge is returned when encoded VPD does not fit its region.
+	ErrTooLarge = errors.New("VPD does not fit in region")
+)
+
+// Entry is a single VPD key/value pair.
+type Entry struct {
+	Key   string
+	Value []byte
+}
+
+// Blob is the ordered list of key/value pairs in a VPD region.
+type Blob struct {
+	Entries []Entry
+}
+
+// Get returns the value of key. Like Reader.Get, it returns an error
+// matching os.ErrNotExist if key is not set.
+func (b *Blob) Get(key string) ([]byte, error) {
+	for _, e := range b.Entries {
+		if e.Key == key {
+			return e.Value, nil
+		}
+	}
+	return nil, fmt.Errorf("VPD key %q: %w", key, os.ErrNotExist)
+}
+
+// Set sets key to value, replacing an existing value in place or appending
+// a new entry.
+func (b *Blob) Set(key string, value []byte) {
+	for i, e := range b.Entries {
+		if e.Key == key {
+			b.Entries[i].Value = value
+			return
+		}
+	}
+	b.Entries = append(b.Entries, Entry{Key: key, Value: value})
+}
+
+// Delete removes key.
+func (b *Blob) Delete(key string) error {
+	for i, e := range b.Entries {
+		if e.Key == key {
+			b.Entries = append(b.Entries[:i], b.Entries[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("VPD key %q: %w", key, os.ErrNotExist)
+}
+
+// Map returns the key/value pairs as a map, like Reader.GetAll.
+func (b *Blob) Map() map[string][]byte {
+	m := make(map[string][]byte, len(b.Entries))
+	for _, e := range b.Entries {
+		m[e.Key] = e.Value
+	}
+	return m
+}
+
+func decodeLen(buf []byte) (int, int, error) {
+	var l uint64
+	for i, c := range buf {
+		l = l<<7 | uint64(c&0x7f)
+		if l > uint64(len(buf)) {
+			return 0, 0, fmt.Errorf("length %d exceeds data: %w", l, io.ErrUnexpectedEOF)
+		}
+		if c&0x80 == 0 {
+			return int(l), i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("truncated length: %w", io.ErrUnexpectedEOF)
+}
+
+func encodeLen(l int) []byte {
+	b := []byte{byte(l & 0x7f)}
+	for l >>= 7; l > 0; l >>= 7 {
+		b = append([]byte{byte(l&0x7f) | 0x80}, b...)
+	}
+	return b
+}
+
+// Decode decodes the key/value pairs of a VPD blob, without the info
+// header. Decoding stops at a terminator or at the end of data.
+func Decode(data []byte) (*Blob, error) {
+	b := &Blob{}
+	for i := 0; i < len(data); {
+		typ := data[i]
+		i++
+		if typ == typeTerminator || typ == typeImplicitTerminator {
+			break
+		}
+
+		var kv [2][]byte
+		for j := range kv {
+			l, n, err := decodeLen(data[i:])
+			if err != nil {
+				return nil, fmt.Errorf("entry at %#x: %w", i-1, err)
+			}
+			i += n
+			if i+l > len(data) {
+				return nil, fmt.Errorf("entry at %#x: %w", i-1, io.ErrUnexpectedEOF)
+			}
+			kv[j] = data[i : i+l]
+			i += l
+		}
+		if typ != typeString {
+			// Skip info and unknown entries, like the vpd tool.
+			continue
+		}
+		b.Entries = append(b.Entries, Entry{Key: string(kv[0]), Value: bytes.Clone(kv[1])})
+	}
+	return b, nil
+}
+
+// Encode encodes the key/value pairs of b, without the info header and
+// terminator.
+func (b *Blob) Encode() []byte {
+	var buf bytes.Buffer
+	for _, e := range b.Entries {
+		buf.WriteByte(typeString)
+		buf.Write(encodeLen(len(e.Key)))
+		buf.WriteString(e.Key)
+		buf.Write(encodeLen(len(e.Value)))
+		buf.Write(e.Value)
+	}
+	return buf.Bytes()
+}
+
+// findInfo returns the offset and size of the VPD data in a region.
+func findInfo(r io.ReaderAt, size int64) (int64, int64, error) {
+	for _, off := range []int64{0, legacyOffset} {
+		if off+int64(infoSize) > size {
+			break
+		}
+		var hdr [infoSize]byte
+		if _, err := r.ReadAt(hdr[:], off); err != nil {
+			return 0, 0, err
+		}
+		if string(hdr[:len(infoMagic)]) != infoMagic {
+			continue
+		}
+		l := int64(binary.LittleEndian.Uint32(hdr[len(infoMagic):]))
+		start := off + int64(infoSize)
+		if start+l > size {
+			return 0, 0, fmt.Errorf("VPD info claims %d bytes, region has %d: %w", l, size-start, io.ErrUnexpectedEOF)
+		}
+		return start, l, nil
+	}
+	return 0, 0, ErrNoVPD
+}
+
+// ReadBlob decodes the VPD in a region of the given size.
+func ReadBlob(r io.ReaderAt, size int64) (*Blob, error) {
+	off, l, err := findInfo(r, size)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, l)
+	if _, err := r.ReadAt(data, off); err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// Eraser is implemented by storage that must be erased before it is
+// written, such as *flash.Flash.
+type Eraser interface {
+	EraseAt(n int64, off int64) (int64, error)
+}
+
+// WriteBlob encodes b with an info header into a region of the given size.
+// The rest of the region is filled with 0xff, like erased flash.
+//
+// If w implements Eraser, the region is erased first, so size and the
+// region's offset must be aligned to the erase size.
+func WriteBlob(w io.WriterAt, size int64, b *Blob) error {
+	data := b.Encode()
+	if int64(infoSize+len(data)+1) > size {
+		return fmt.Errorf("%d bytes of VPD, region has %d: %w", infoSize+len(data)+1, size, ErrTooLarge)
+	}
+
+	buf := bytes.Repeat([]byte{0xff}, int(size))
+	copy(buf, infoMagic)
+	binary.LittleEndian.PutUint32(buf[len(infoMagic):], uint32(len(data)+1))
+	copy(buf[infoSize:], data)
+	buf[infoSize+len(data)] = typeTerminator
+
+	if e, ok := w.(Eraser); ok {
+		if _, err := e.EraseAt(size, 0); err != nil {
+			return fmt.Errorf("erasing VPD region: %w", err)
+		}
+	}
+	_, err := w.WriteAt(buf, 0)
+	return err
+}
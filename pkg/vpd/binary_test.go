@@ -0,0 +1,280 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vpd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/flash/fmap"
+)
+
+func TestLen(t *testing.T) {
+	for _, tt := range []struct {
+		l   int
+		enc []byte
+	}{
+		{0, []byte{0x00}},
+		{0x7f, []byte{0x7f}},
+		{0x80, []byte{0x81, 0x00}},
+		{0x3fff, []byte{0xff, 0x7f}},
+		{0x4000, []byte{0x81, 0x80, 0x00}},
+	} {
+		if got := encodeLen(tt.l); !bytes.Equal(got, tt.enc) {
+			t.Errorf("encodeLen(%#x) = %x, want %x", tt.l, got, tt.enc)
+		}
+		// Pad the buffer so the decoded length fits.
+		buf := append(bytes.Clone(tt.enc), make([]byte, tt.l)...)
+		l, n, err := decodeLen(buf)
+		if err != nil || l != tt.l || n != len(tt.enc) {
+			t.Errorf("decodeLen(%x) = %#x, %d, %v, want %#x, %d, nil", tt.enc, l, n, err, tt.l, len(tt.enc))
+		}
+	}
+
+	if _, _, err := decodeLen([]byte{0x81}); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("decodeLen(truncated) = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	long := strings.Repeat("x", 200)
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want *Blob
+		err  error
+	}{
+		{
+			name: "empty",
+			data: []byte{typeTerminator},
+			want: &Blob{},
+		},
+		{
+			name: "pairs",
+			data: []byte("\x01\x04key1\x06value1\x01\x07binary1\x03a\x00b\x00\xff\xff"),
+			want: &Blob{Entries: []Entry{
+				{Key: "key1", Value: []byte("value1")},
+				{Key: "binary1", Value: []byte("a\x00b")},
+			}},
+		},
+		{
+			name: "long value",
+			data: append([]byte("\x01\x01k\x81\x48"), append([]byte(long), 0xff)...),
+			want: &Blob{Entries: []Entry{{Key: "k", Value: []byte(long)}}},
+		},
+		{
+			name: "skips info",
+			data: []byte("\xfe\x09\x01gVpdInfo\x04\x00\x00\x00\x00\x01\x01k\x01v"),
+			want: &Blob{Entries: []Entry{{Key: "k", Value: []byte("v")}}},
+		},
+		{
+			name: "truncated",
+			data: []byte("\x01\x04key1\x06val"),
+			err:  io.ErrUnexpectedEOF,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Decode() = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlob(t *testing.T) {
+	b := &Blob{}
+	b.Set("a", []byte("1"))
+	b.Set("b", []byte("2"))
+	b.Set("a", []byte("3"))
+	if err := b.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete("b"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Delete(missing) = %v, want %v", err, os.ErrNotExist)
+	}
+	if _, err := b.Get("b"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Get(missing) = %v, want %v", err, os.ErrNotExist)
+	}
+	if want := map[string][]byte{"a": []byte("3")}; !reflect.DeepEqual(b.Map(), want) {
+		t.Errorf("Map() = %q, want %q", b.Map(), want)
+	}
+
+	got, err := Decode(b.Encode())
+	if err != nil || !reflect.DeepEqual(got, b) {
+		t.Errorf("Decode(Encode()) = %+v, %v, want %+v, nil", got, err, b)
+	}
+}
+
+// memImage is an in-memory firmware image. If eraseSize is not zero, it
+// behaves like flash: writes can only clear bits and erases must be
+// aligned.
+type memImage struct {
+	data      []byte
+	eraseSize int64
+}
+
+func (m *memImage) ReadAt(p []byte, off int64) (int, error) {
+	if off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *memImage) WriteAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > int64(len(m.data)) {
+		return 0, io.ErrShortWrite
+	}
+	for i, b := range p {
+		if m.eraseSize != 0 {
+			m.data[off+int64(i)] &= b
+		} else {
+			m.data[off+int64(i)] = b
+		}
+	}
+	return len(p), nil
+}
+
+type flashImage struct {
+	memImage
+}
+
+func (f *flashImage) EraseAt(n int64, off int64) (int64, error) {
+	if n%f.eraseSize != 0 || off%f.eraseSize != 0 {
+		return 0, errors.New("unaligned erase")
+	}
+	copy(f.data[off:off+n], bytes.Repeat([]byte{0xff}, int(n)))
+	return n, nil
+}
+
+// newImage builds a 64 KiB image with an FMAP at 0x8000 and 4 KiB RO_VPD
+// and RW_VPD areas.
+func newImage() []byte {
+	img := bytes.Repeat([]byte{0xff}, 0x10000)
+	areas := []fmap.AreaHeader{
+		{Offset: 0, Size: 0x8000},
+		{Offset: 0x8000, Size: 0x1000},
+		{Offset: 0x9000, Size: 0x1000, Flags: fmap.AreaRO},
+		{Offset: 0xa000, Size: 0x1000},
+	}
+	copy(areas[0].Name[:], "SI_ALL")
+	copy(areas[1].Name[:], "FMAP")
+	copy(areas[2].Name[:], ROVPDRegion)
+	copy(areas[3].Name[:], RWVPDRegion)
+	h := fmap.Header{VerMajor: fmap.MajorVersion, Size: 0x10000, NAreas: uint16(len(areas))}
+	copy(h.Signature[:], fmap.Signature)
+	copy(h.Name[:], "FLASH")
+
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, h)
+	binary.Write(&b, binary.LittleEndian, areas)
+	copy(img[0x8000:], b.Bytes())
+	return img
+}
+
+func TestImage(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		img  io.ReaderAt
+	}{
+		{"file", &memImage{data: newImage()}},
+		{"flash", &flashImage{memImage{data: newImage(), eraseSize: 0x1000}}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			i, err := NewImage(tt.img, 0x10000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := i.GetAll(false); !errors.Is(err, ErrNoVPD) {
+				t.Errorf("GetAll(blank) = %v, want %v", err, ErrNoVPD)
+			}
+
+			if err := i.Set("Boot0001", []byte(`{"type":"localboot"}`), false); err != nil {
+				t.Fatal(err)
+			}
+			if err := i.Set("serial_number", []byte("1234"), true); err != nil {
+				t.Fatal(err)
+			}
+			if err := i.Set("Boot0002", []byte("x"), false); err != nil {
+				t.Fatal(err)
+			}
+			if err := i.Delete("Boot0002", false); err != nil {
+				t.Fatal(err)
+			}
+
+			rw, err := i.GetAll(false)
+			if want := map[string][]byte{"Boot0001": []byte(`{"type":"localboot"}`)}; err != nil || !reflect.DeepEqual(rw, want) {
+				t.Errorf("GetAll(false) = %q, %v, want %q, nil", rw, err, want)
+			}
+			v, err := i.Get("serial_number", true)
+			if err != nil || string(v) != "1234" {
+				t.Errorf("Get(serial_number) = %q, %v, want 1234, nil", v, err)
+			}
+			if _, err := i.Get("serial_number", false); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Get(serial_number, RW) = %v, want %v", err, os.ErrNotExist)
+			}
+
+			if err := i.Clear(false); err != nil {
+				t.Fatal(err)
+			}
+			if rw, err := i.GetAll(false); err != nil || len(rw) != 0 {
+				t.Errorf("GetAll(false) after Clear = %q, %v, want empty", rw, err)
+			}
+		})
+	}
+}
+
+func TestImageLayout(t *testing.T) {
+	m := &memImage{data: newImage()}
+	i, err := NewImage(m, int64(len(m.data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Set("k", []byte("v"), false); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte("\xfe\x09\x01gVpdInfo\x04\x06\x00\x00\x00\x01\x01k\x01v\x00\xff")
+	if got := m.data[0xa000 : 0xa000+len(want)]; !bytes.Equal(got, want) {
+		t.Errorf("RW_VPD = %q, want %q", got, want)
+	}
+	if !bytes.Equal(m.data[0x9000:0xa000], bytes.Repeat([]byte{0xff}, 0x1000)) {
+		t.Errorf("RO_VPD was modified")
+	}
+
+	// VPD 1.x images keep the VPD 2.0 data after an SMBIOS entry point.
+	legacy := make([]byte, 0x1000)
+	copy(legacy, "_SM_")
+	copy(legacy[legacyOffset:], want)
+	b, err := ReadBlob(bytes.NewReader(legacy), int64(len(legacy)))
+	if err != nil || !reflect.DeepEqual(b.Map(), map[string][]byte{"k": []byte("v")}) {
+		t.Errorf("ReadBlob(legacy) = %+v, %v", b, err)
+	}
+
+	big := &Blob{Entries: []Entry{{Key: "k", Value: make([]byte, 0x1000)}}}
+	if err := i.WriteBlob(big, false); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("WriteBlob(too large) = %v, want %v", err, ErrTooLarge)
+	}
+
+	ro, err := NewImage(bytes.NewReader(m.data), int64(len(m.data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ro.Set("k", nil, false); !errors.Is(err, ErrReadOnlyImage) {
+		t.Errorf("Set() on read-only image = %v, want %v", err, ErrReadOnlyImage)
+	}
+}
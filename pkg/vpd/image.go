@@ -0,0 +1,159 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package vpd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/u-root/u-root/pkg/flash/fmap"
+)
+
+// FMAP area names of the VPD regions.
+const (
+	ROVPDRegion = "RO_VPD"
+	RWVPDRegion = "RW_VPD"
+)
+
+// ErrReadOnlyImage is returned when writing to an Image that was opened
+// without an io.WriterAt.
+var ErrReadOnlyImage = errors.New("image is not writable")
+
+// Image accesses the VPD regions of a firmware image directly, without
+// flashrom or the kernel's sysfs interface. The image can be a file or a
+// flash chip such as *flash.Flash.
+type Image struct {
+	r    io.ReaderAt
+	w    io.WriterAt
+	FMap *fmap.FMap
+}
+
+// NewImage returns an Image for the firmware image r of the given size. If
+// r also implements io.WriterAt, the VPD can be modified.
+func NewImage(r io.ReaderAt, size int64) (*Image, error) {
+	f, err := fmap.Read(r, size)
+	if err != nil {
+		return nil, err
+	}
+	i := &Image{r: r, FMap: f}
+	if w, ok := r.(io.WriterAt); ok {
+		i.w = w
+	}
+	return i, nil
+}
+
+func (i *Image) area(readOnly bool) (*fmap.AreaHeader, error) {
+	if readOnly {
+		return i.FMap.Area(ROVPDRegion)
+	}
+	return i.FMap.Area(RWVPDRegion)
+}
+
+// Blob decodes the RO_VPD or RW_VPD region.
+func (i *Image) Blob(readOnly bool) (*Blob, error) {
+	a, err := i.area(readOnly)
+	if err != nil {
+		return nil, err
+	}
+	return ReadBlob(io.NewSectionReader(i.r, int64(a.Offset), int64(a.Size)), int64(a.Size))
+}
+
+// regionWriter writes to a region of w.
+type regionWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (r *regionWriter) WriteAt(p []byte, off int64) (int, error) {
+	return r.w.WriteAt(p, r.off+off)
+}
+
+// regionEraser writes to and erases a region of storage that needs erasing.
+type regionEraser struct {
+	regionWriter
+	e Eraser
+}
+
+func (r *regionEraser) EraseAt(n int64, off int64) (int64, error) {
+	return r.e.EraseAt(n, r.off+off)
+}
+
+// WriteBlob replaces the contents of the RO_VPD or RW_VPD region with b.
+func (i *Image) WriteBlob(b *Blob, readOnly bool) error {
+	if i.w == nil {
+		return ErrReadOnlyImage
+	}
+	a, err := i.area(readOnly)
+	if err != nil {
+		return err
+	}
+	var w io.WriterAt = &regionWriter{w: i.w, off: int64(a.Offset)}
+	if e, ok := i.w.(Eraser); ok {
+		w = &regionEraser{regionWriter{w: i.w, off: int64(a.Offset)}, e}
+	}
+	return WriteBlob(w, int64(a.Size), b)
+}
+
+// Get reads a VPD variable by name, like Reader.Get. Variables missing
+// from the region, or a region without VPD, yield an error matching
+// os.ErrNotExist.
+func (i *Image) Get(key string, readOnly bool) ([]byte, error) {
+	b, err := i.Blob(readOnly)
+	if errors.Is(err, ErrNoVPD) {
+		return nil, fmt.Errorf("VPD key %q: %w: %w", key, os.ErrNotExist, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.Get(key)
+}
+
+// GetAll reads all the VPD variables of a region, like Reader.GetAll.
+func (i *Image) GetAll(readOnly bool) (map[string][]byte, error) {
+	b, err := i.Blob(readOnly)
+	if err != nil {
+		return nil, err
+	}
+	return b.Map(), nil
+}
+
+// Modify applies f to the blob of a region and writes the result back with a
+// single write, so several changes cost one erase and write of the region.
+// A region without VPD is treated as empty.
+func (i *Image) Modify(readOnly bool, f func(b *Blob) error) error {
+	b, err := i.Blob(readOnly)
+	if errors.Is(err, ErrNoVPD) {
+		b, err = &Blob{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := f(b); err != nil {
+		return err
+	}
+	return i.WriteBlob(b, readOnly)
+}
+
+// Set sets a VPD variable, like Reader.Set.
+func (i *Image) Set(key string, value []byte, readOnly bool) error {
+	return i.Modify(readOnly, func(b *Blob) error {
+		b.Set(key, value)
+		return nil
+	})
+}
+
+// Delete removes a VPD variable.
+func (i *Image) Delete(key string, readOnly bool) error {
+	return i.Modify(readOnly, func(b *Blob) error {
+		return b.Delete(key)
+	})
+}
+
+// Clear re-formats a region with no variables, like `vpd -O`.
+func (i *Image) Clear(readOnly bool) error {
+	return i.WriteBlob(&Blob{}, readOnly)
+}
@@ -18,7 +18,7 @@ import (
 
 var dryRun = false
 
-func add(entrytype string, args []string) error {
+func add(image, entrytype string, args []string) error {
 	var (
 		entry  systembooter.Booter
 		vpdDir string
@@ -53,7 +53,7 @@ func add(entrytype string, args []string) error {
 		fmt.Println(string(b))
 		return nil
 	}
-	return addBootEntry(entry, vpdDir)
+	return addBootEntry(entry, vpdDir, image)
 }
 
 func parseLocalbootFlags(method string, args []string) (*systembooter.LocalBooter, string, error) {
@@ -125,18 +125,27 @@ func parseNetbootFlags(method, mac string, args []string) (*systembooter.NetBoot
 	return cfg, flagVpdDir, nil
 }
 
-func addBootEntry(cfg systembooter.Booter, vpdDir string) error {
+func addBootEntry(cfg systembooter.Booter, vpdDir, image string) error {
 	data, err := json.Marshal(cfg)
 	if err != nil {
 		return err
 	}
+	if image != "" {
+		return withImage(image, true, func(img *vpd.Image) error {
+			return addBootEntryTo(img, data)
+		})
+	}
 	vpdReader := vpd.NewReader()
 	vpdReader.VpdDir = vpdDir
+	return addBootEntryTo(vpdReader, data)
+}
+
+func addBootEntryTo(store vpdStore, data []byte) error {
 	for i := 1; i < vpd.MaxBootEntry; i++ {
 		key := fmt.Sprintf("Boot%04d", i)
-		if _, err := vpdReader.Get(key, false); err != nil {
-			if os.IsNotExist(err) {
-				if err := vpdReader.Set(key, data, false); err != nil {
+		if _, err := store.Get(key, false); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				if err := store.Set(key, data, false); err != nil {
 					return err
 				}
 				return nil
@@ -147,14 +156,27 @@ func addBootEntry(cfg systembooter.Booter, vpdDir string) error {
 	return errors.New("maximum number of boot entries already set")
 }
 
-func set(key string, value string) error {
+func set(image, key, value string) error {
+	if image != "" {
+		return withImage(image, true, func(img *vpd.Image) error {
+			return img.Set(key, []byte(value), false)
+		})
+	}
 	return vpd.FlashromRWVpdSet(key, []byte(value), false)
 }
 
-func remove(key string) error {
+func remove(image, key string) error {
+	if image != "" {
+		return withImage(image, true, func(img *vpd.Image) error {
+			return img.Delete(key, false)
+		})
+	}
 	return vpd.FlashromRWVpdSet(key, []byte("dummy"), true)
 }
 
-func dump() error {
+func dump(image string) error {
+	if image != "" {
+		return withImage(image, false, dumpImage)
+	}
 	return vpd.FlashromVpdDump()
 }
@@ -109,55 +109,55 @@ func TestParseLocalbootWithFlags(t *testing.T) {
 }
 
 func TestFailGracefullyMissingArg(t *testing.T) {
-	err := add("localboot", []string{})
+	err := add("", "localboot", []string{})
 	if err.Error() != "you need to provide method" {
 		t.Error("error message should be: you need to provide method")
 	}
 
-	err = add("localboot", []string{"path"})
+	err = add("", "localboot", []string{"path"})
 	if err.Error() != "you need to pass DeviceGUID and Kernel path" {
 		t.Error("error message should be: you need to pass DeviceGUID and Kernel path")
 	}
 
-	err = add("localboot", []string{"path", "device"})
+	err = add("", "localboot", []string{"path", "device"})
 	if err.Error() != "you need to pass DeviceGUID and Kernel path" {
 		t.Error("error message should be: you need to pass DeviceGUID and Kernel path")
 	}
 
-	err = add("netboot", []string{})
+	err = add("", "netboot", []string{})
 	if err.Error() != "you need to pass method and MAC address" {
 		t.Error("error message should be: you need to pass method and MAC address")
 	}
 
-	err = add("netboot", []string{"dhcpv6"})
+	err = add("", "netboot", []string{"dhcpv6"})
 	if err.Error() != "you need to pass method and MAC address" {
 		t.Error("error message should be: you need to pass method and MAC address")
 	}
 }
 
 func TestFailGracefullyBadMACAddress(t *testing.T) {
-	err := add("netboot", []string{"dhcpv6", "test"})
+	err := add("", "netboot", []string{"dhcpv6", "test"})
 	if err.Error() != "address test: invalid MAC address" {
 		t.Errorf(`err.Error() = %q, want "error message should be: address test: invalid MAC address"`, err.Error())
 	}
 }
 
 func TestFailGracefullyBadNetworkType(t *testing.T) {
-	err := add("netboot", []string{"not-valid", "test"})
+	err := add("", "netboot", []string{"not-valid", "test"})
 	if err.Error() != "method needs to be either dhcpv4 or dhcpv6" {
 		t.Errorf(`err.Error() = %q, want "error message should be: method needs to be either dhcpv4 or dhcpv6"`, err.Error())
 	}
 }
 
 func TestFailGracefullyBadLocalbootType(t *testing.T) {
-	err := add("localboot", []string{"not-valid"})
+	err := add("", "localboot", []string{"not-valid"})
 	if err.Error() != "method needs to be grub or path" {
 		t.Errorf(`err.Error() = %q, want "error message: method needs to be grub or path"`, err.Error())
 	}
 }
 
 func TestFailGracefullyUnknownEntryType(t *testing.T) {
-	err := add("test", []string{})
+	err := add("", "test", []string{})
 	if err.Error() != "unknown entry type" {
 		t.Errorf(`err.Error() = %q, want "unknown entry type"`, err.Error())
 	}
@@ -171,7 +171,7 @@ func TestAddBootEntry(t *testing.T) {
 
 	if err := addBootEntry(&systembooter.LocalBooter{
 		Method: "grub",
-	}, vpdDir); err != nil {
+	}, vpdDir, ""); err != nil {
 		t.Errorf(`addBootEntry(&systembooter.LocalBooter{"grub"}, %q) = %v, want nil`, vpdDir, err)
 	}
 
@@ -199,7 +199,7 @@ func TestAddBootEntryMultiple(t *testing.T) {
 	for i := 1; i < 5; i++ {
 		if err := addBootEntry(&systembooter.LocalBooter{
 			Method: "grub",
-		}, vpdDir); err != nil {
+		}, vpdDir, ""); err != nil {
 			t.Errorf(`addBootEntry(&systembooter.LocalBooter{Method: "grub"}, %q) = %v, want nil`, vpdDir, err)
 		}
 		file, err := os.ReadFile(path.Join(vpdDir, "rw", fmt.Sprintf("Boot%04d", i)))
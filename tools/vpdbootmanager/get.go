@@ -5,6 +5,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -24,35 +25,42 @@ func NewGetter() *Getter {
 type Getter struct {
 	R   *vpd.Reader
 	Out io.Writer
+
+	// Image, if set, is read instead of R.
+	Image *vpd.Image
 }
 
 // Print prints VPD variables. If `key` is an empty string, it will print all
 // the variables it finds. A variable can exist as both read-write and
 // read-only. In that case a "RO" or "RW" string will also be printed.
 func (g *Getter) Print(key string) error {
+	var store vpdStore = g.R
+	if g.Image != nil {
+		store = g.Image
+	}
 	allVars := make(map[bool]map[string][]byte)
 	if key == "" {
 		// read-only variables first
-		rovars, err := g.R.GetAll(true)
+		rovars, err := getAll(store, true)
 		if err != nil {
 			return fmt.Errorf("failed to read RO variables: %w", err)
 		}
 		allVars[true] = rovars
 
 		// then read-write variables
-		rwvars, err := g.R.GetAll(false)
+		rwvars, err := getAll(store, false)
 		if err != nil {
 			return fmt.Errorf("failed to read RW variables: %w", err)
 		}
 		allVars[false] = rwvars
 	} else {
 		// first the read-only var
-		value, errRO := g.R.Get(key, true)
+		value, errRO := store.Get(key, true)
 		if errRO == nil {
 			allVars[true] = map[string][]byte{key: value}
 		}
 		// then the read-write var
-		value, errRW := g.R.Get(key, false)
+		value, errRW := store.Get(key, false)
 		if errRW == nil {
 			allVars[false] = map[string][]byte{key: value}
 		}
@@ -60,7 +68,7 @@ func (g *Getter) Print(key string) error {
 		if len(allVars[true])+len(allVars[false]) == 0 {
 			fmt.Fprintf(g.Out, "No variable named '%s' found\n", key)
 			// if the variable is simply not set, return without error,
-			if os.IsNotExist(errRO) && os.IsNotExist(errRW) {
+			if errors.Is(errRO, os.ErrNotExist) && errors.Is(errRW, os.ErrNotExist) {
 				return nil
 			}
 			// otherwise print one or both errors.
@@ -0,0 +1,95 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/u-root/u-root/pkg/vpd"
+)
+
+// vpdStore is implemented by *vpd.Reader and *vpd.Image.
+type vpdStore interface {
+	Get(key string, readOnly bool) ([]byte, error)
+	GetAll(readOnly bool) (map[string][]byte, error)
+	Set(key string, value []byte, readOnly bool) error
+}
+
+// openImage opens the firmware image at path, given by the global -image
+// flag, for reading its RO_VPD and RW_VPD regions instead of sysfs and
+// flashrom. The image is only opened for writing if write is set. The
+// caller must close the returned file.
+func openImage(path string, write bool) (*vpd.Image, *os.File, error) {
+	mode := os.O_RDONLY
+	if write {
+		mode = os.O_RDWR
+	}
+	f, err := os.OpenFile(path, mode, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	img, err := vpd.NewImage(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, f, nil
+}
+
+// withImage calls fn with the image at path.
+func withImage(path string, write bool, fn func(img *vpd.Image) error) error {
+	img, f, err := openImage(path, write)
+	if err != nil {
+		return err
+	}
+	if err := fn(img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func dumpImage(img *vpd.Image) error {
+	for _, region := range []struct {
+		name     string
+		readOnly bool
+	}{
+		{vpd.ROVPDRegion, true},
+		{vpd.RWVPDRegion, false},
+	} {
+		fmt.Printf("%s values:\n", region.name)
+		vars, err := getAll(img, region.readOnly)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", region.name, err)
+		}
+		keys := make([]string, 0, len(vars))
+		for k := range vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("%q=%q\n", k, vars[k])
+		}
+	}
+	return nil
+}
+
+// getAll reads all variables of a region. An erased image region holds no
+// VPD at all, which is the same as holding no variables.
+func getAll(store vpdStore, readOnly bool) (map[string][]byte, error) {
+	vars, err := store.GetAll(readOnly)
+	if errors.Is(err, vpd.ErrNoVPD) {
+		return map[string][]byte{}, nil
+	}
+	return vars, err
+}
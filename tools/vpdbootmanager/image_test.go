@@ -0,0 +1,90 @@
+// Copyright 2026 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/u-root/u-root/pkg/boot/systembooter"
+	"github.com/u-root/u-root/pkg/flash/fmap"
+	"github.com/u-root/u-root/pkg/vpd"
+)
+
+func writeImage(t *testing.T) string {
+	t.Helper()
+	img := bytes.Repeat([]byte{0xff}, 0x4000)
+	areas := []fmap.AreaHeader{
+		{Offset: 0x1000, Size: 0x1000},
+		{Offset: 0x2000, Size: 0x1000},
+	}
+	copy(areas[0].Name[:], vpd.ROVPDRegion)
+	copy(areas[1].Name[:], vpd.RWVPDRegion)
+	h := fmap.Header{VerMajor: fmap.MajorVersion, Size: uint32(len(img)), NAreas: uint16(len(areas))}
+	copy(h.Signature[:], fmap.Signature)
+
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, h)
+	binary.Write(&b, binary.LittleEndian, areas)
+	copy(img, b.Bytes())
+
+	p := filepath.Join(t.TempDir(), "bios.bin")
+	if err := os.WriteFile(p, img, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestImage(t *testing.T) {
+	p := writeImage(t)
+	for _, args := range [][]string{
+		{"-image", p, "set", "systemboot_log_level", "6"},
+		{"-image", p, "set", "tmp", "1"},
+		{"-image", p, "delete", "tmp"},
+		{"-image", p, "add", "localboot", "grub"},
+	} {
+		if err := cli(args); err != nil {
+			t.Fatalf("cli(%v) = %v, want nil", args, err)
+		}
+	}
+
+	img, f, err := openImage(p, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	vars, err := img.GetAll(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vars) != 2 || string(vars["systemboot_log_level"]) != "6" {
+		t.Errorf("RW_VPD = %q, want systemboot_log_level and Boot0001", vars)
+	}
+	var out systembooter.LocalBooter
+	if err := json.Unmarshal(vars["Boot0001"], &out); err != nil || out.Method != "grub" {
+		t.Errorf("Boot0001 = %q, %v, want grub localboot entry", vars["Boot0001"], err)
+	}
+
+	var buf bytes.Buffer
+	g := &Getter{Out: &buf, Image: img}
+	if err := g.Print("systemboot_log_level"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "systemboot_log_level(RW) => 6\n"; got != want {
+		t.Errorf("Print() = %q, want %q", got, want)
+	}
+	for _, args := range [][]string{
+		{"-image=" + p, "get", "systemboot_log_level"},
+		{"-image", p, "dump"},
+	} {
+		if err := cli(args); err != nil {
+			t.Errorf("cli(%v) = %v, want nil", args, err)
+		}
+	}
+}
@@ -5,8 +5,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
+
+	"github.com/u-root/u-root/pkg/vpd"
 )
 
 func getUsage(progname string) string {
@@ -38,6 +42,8 @@ Flags for localboot:
 Global flags:
 
 -vpd-dir - VPD dir to use
+-image - firmware image file to edit instead of sysfs and flashrom, must come
+         before the action, e.g. -image bios.bin set key value
 
 `, progname, progname, progname, progname, progname)
 }
@@ -51,22 +57,35 @@ func main() {
 }
 
 func cli(args []string) error {
+	fs := flag.NewFlagSet("vpdbootmanager", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	image := fs.String("image", "", "firmware image file to edit instead of sysfs and flashrom")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
 	if len(args) < 1 {
 		return fmt.Errorf("you need to provide action")
 	}
 	switch args[0] {
 	case "add":
-		return add(args[1], args[2:])
+		return add(*image, args[1], args[2:])
 	case "get":
 		varname := ""
 		if len(args) > 1 {
 			varname = args[1]
 		}
 		getter := NewGetter()
+		if *image != "" {
+			return withImage(*image, false, func(img *vpd.Image) error {
+				getter.Image = img
+				return getter.Print(varname)
+			})
+		}
 		return getter.Print(varname)
 	case "set":
 		if len(args) == 3 {
-			err := set(args[1], args[2])
+			err := set(*image, args[1], args[2])
 			if err == nil {
 				fmt.Println("Successfully set, it will take effect after reboot")
 			}
@@ -74,14 +93,14 @@ func cli(args []string) error {
 		}
 	case "delete":
 		if len(args) == 2 {
-			err := remove(args[1])
+			err := remove(*image, args[1])
 			if err == nil {
 				fmt.Println("Successfully deleted, it will take effect after reboot")
 			}
 			return err
 		}
 	case "dump":
-		return dump()
+		return dump(*image)
 	}
 	return fmt.Errorf("unrecognized action")
 }